@@ -0,0 +1,385 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The benchmark command feeds synthetic Ingresses through the Ingress reconciler running
+// against fake clients, and reports the reconciles per second, the writes to the API server
+// and the allocations of a first pass creating the resources of the Ingresses, and of a
+// second pass finding them up to date. The readiness of the Ingresses is not probed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	virtualserviceinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/virtualservice"
+	"knative.dev/net-istio/pkg/reconciler/ingress"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	istiotesting "knative.dev/net-istio/pkg/reconciler/testing"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
+	netconfig "knative.dev/networking/pkg/config"
+	filteredFactory "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+	rtesting "knative.dev/pkg/reconciler/testing"
+	"knative.dev/pkg/system"
+
+	// Inject the fakes.
+	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/destinationrule/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/gateway/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/virtualservice/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/security/v1beta1/authorizationpolicy/fake"
+	fakenetworkingclient "knative.dev/networking/pkg/client/injection/client/fake"
+	_ "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress/fake"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/endpoints/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/filtered/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
+	_ "knative.dev/pkg/client/injection/kube/informers/factory/filtered/fake"
+)
+
+var (
+	ingresses  = flag.Int("ingresses", 1000, "The number of synthetic Ingresses to reconcile.")
+	namespaces = flag.Int("namespaces", 10, "The number of namespaces the Ingresses are spread over.")
+	workers    = flag.Int("workers", 1, "The number of Ingresses reconciled concurrently.")
+	cpuProfile = flag.String("cpuprofile", "", "The file to write the CPU profile of the reconciles to.")
+	memProfile = flag.String("memprofile", "", "The file to write the allocation profile of the reconciles to.")
+	logLevel   = flag.String("log-level", "error", "The level of the logs of the reconciler.")
+)
+
+// writeVerbs are the verbs of the actions counted as writes to the API server.
+var writeVerbs = map[string]bool{
+	"create": true,
+	"update": true,
+	"patch":  true,
+	"delete": true,
+}
+
+// writeCounter counts the writes of the fake clients by verb and resource.
+type writeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (w *writeCounter) react(action ktesting.Action) (bool, kruntime.Object, error) {
+	if writeVerbs[action.GetVerb()] {
+		key := action.GetVerb() + " " + action.GetResource().Resource
+		if sub := action.GetSubresource(); sub != "" {
+			key += "/" + sub
+		}
+		w.mu.Lock()
+		w.counts[key]++
+		w.mu.Unlock()
+	}
+	return false, nil, nil
+}
+
+func (w *writeCounter) reset() map[string]int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	counts := w.counts
+	w.counts = map[string]int{}
+	return counts
+}
+
+func main() {
+	flag.Parse()
+	if *ingresses < 1 || *namespaces < 1 || *workers < 1 {
+		log.Fatal("-ingresses, -namespaces and -workers must be positive")
+	}
+	if os.Getenv(system.NamespaceEnvKey) == "" {
+		os.Setenv(system.NamespaceEnvKey, "knative-serving")
+	}
+
+	logger, _ := logging.NewLogger("", *logLevel)
+	ctx, cancel := context.WithCancel(logging.WithLogger(context.Background(), logger))
+	defer cancel()
+	ctx = controller.WithEventRecorder(ctx, &record.FakeRecorder{})
+	ctx = filteredFactory.WithSelectors(ctx, networking.CertificateUIDLabelKey)
+	ctx = injection.WithConfig(ctx, &rest.Config{})
+	ctx, informers := injection.Fake.SetupInformers(ctx, injection.GetConfig(ctx))
+
+	for _, name := range []string{config.KnativeIngressGateway, config.KnativeLocalGateway} {
+		gw := istiotesting.NewGateway(system.Namespace(), name)
+		if _, err := fakeistioclient.Get(ctx).NetworkingV1beta1().Gateways(gw.Namespace).Create(ctx, gw, metav1.CreateOptions{}); err != nil {
+			log.Fatal("Failed to create the gateway: ", err)
+		}
+	}
+
+	cmw := &configmap.ManualWatcher{Namespace: system.Namespace()}
+	impl := ingress.NewControllerWithStatusManager(ctx, cmw, istiotesting.AlwaysReady())
+	cmw.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: config.IstioConfigName},
+		Data: map[string]string{
+			"gateway." + config.KnativeIngressGateway:     "istio-ingressgateway.istio-system.svc.cluster.local",
+			"local-gateway." + config.KnativeLocalGateway: "knative-local-gateway.istio-system.svc.cluster.local",
+		},
+	})
+	cmw.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: netconfig.ConfigMapName},
+	})
+	if err := cmw.Start(ctx.Done()); err != nil {
+		log.Fatal("Failed to start the ConfigMap watcher: ", err)
+	}
+
+	// The Ingresses are created before the informers start, as the watches of the fake clients
+	// can only buffer a hundred events.
+	keys, err := createIngresses(ctx, *ingresses, *namespaces)
+	if err != nil {
+		log.Fatal("Failed to create the Ingresses: ", err)
+	}
+
+	waitInformers, err := rtesting.RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		log.Fatal("Failed to start the informers: ", err)
+	}
+	defer waitInformers()
+
+	// The reconciler only reconciles the Ingresses of the buckets it leads.
+	if la, ok := impl.Reconciler.(reconciler.LeaderAware); ok {
+		la.Promote(reconciler.UniversalBucket(), func(reconciler.Bucket, types.NamespacedName) {})
+	}
+
+	// The fake clients replace the whole Ingresses on the updates of their status, while the
+	// API server only updates the status and keeps the finalizers added by the reconciler.
+	networkingClient := fakenetworkingclient.Get(ctx)
+	networkingClient.PrependReactor("update", "ingresses", func(action ktesting.Action) (bool, kruntime.Object, error) {
+		update := action.(ktesting.UpdateAction)
+		if update.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		ing := update.GetObject().(*v1alpha1.Ingress)
+		cur, err := networkingClient.Tracker().Get(action.GetResource(), ing.Namespace, ing.Name)
+		if err != nil {
+			return false, nil, nil
+		}
+		ing.Finalizers = cur.(*v1alpha1.Ingress).Finalizers
+		return false, nil, nil
+	})
+
+	counter := &writeCounter{counts: map[string]int{}}
+	fakekubeclient.Get(ctx).PrependReactor("*", "*", counter.react)
+	fakeistioclient.Get(ctx).PrependReactor("*", "*", counter.react)
+	networkingClient.PrependReactor("*", "*", counter.react)
+
+	if err := waitForIngresses(ctx, len(keys)); err != nil {
+		log.Fatal("Failed to wait for the Ingresses: ", err)
+	}
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			log.Fatal("Failed to create the CPU profile: ", err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatal("Failed to start the CPU profile: ", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PASS\tRECONCILES\tERRORS\tDURATION\tRECONCILES/S\tALLOCS/RECONCILE\tBYTES/RECONCILE\tWRITES")
+	var writes []map[string]int
+	for _, pass := range []string{"create", "steady"} {
+		res := runPass(ctx, impl.Reconciler, keys, *workers)
+		writes = append(writes, counter.reset())
+		fmt.Fprintf(w, "%s\t%d\t%d\t%v\t%.1f\t%d\t%d\t%d\n", pass, len(keys), res.errors,
+			res.duration.Round(time.Millisecond), float64(len(keys))/res.duration.Seconds(),
+			res.mallocs/uint64(len(keys)), res.bytes/uint64(len(keys)), total(writes[len(writes)-1]))
+
+		// The next pass must see the resources written by this one.
+		if err := waitForCaches(ctx, len(keys)); err != nil {
+			log.Fatal("Failed to wait for the caches: ", err)
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "WRITE\tCREATE PASS\tSTEADY PASS")
+	for _, key := range writeKeys(writes...) {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", key, writes[0][key], writes[1][key])
+	}
+	w.Flush()
+
+	if *memProfile != "" {
+		f, err := os.Create(*memProfile)
+		if err != nil {
+			log.Fatal("Failed to create the allocation profile: ", err)
+		}
+		defer f.Close()
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			log.Fatal("Failed to write the allocation profile: ", err)
+		}
+	}
+}
+
+// createIngresses creates the synthetic Ingresses, each routing a public and a cluster-local
+// host to its own service.
+func createIngresses(ctx context.Context, count, namespaces int) ([]string, error) {
+	keys := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		ns, name := fmt.Sprintf("ns-%d", i%namespaces), fmt.Sprintf("ingress-%d", i)
+		ing := istiotesting.NewIngress(ns, name, istiotesting.WithIngressRules(
+			istiotesting.NewIngressRule(v1alpha1.IngressVisibilityExternalIP,
+				[]string{name + "." + ns + ".example.com"}, ns, name, 80),
+			istiotesting.NewIngressRule(v1alpha1.IngressVisibilityClusterLocal,
+				[]string{name + "." + ns, name + "." + ns + ".svc", name + "." + ns + ".svc.cluster.local"}, ns, name, 80),
+		))
+		ing.Spec.HTTPOption = v1alpha1.HTTPOptionEnabled
+		if _, err := fakenetworkingclient.Get(ctx).NetworkingV1alpha1().Ingresses(ns).Create(ctx, ing, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+		keys = append(keys, ns+"/"+name)
+	}
+	return keys, nil
+}
+
+// waitForIngresses waits for the informer to see the Ingresses.
+func waitForIngresses(ctx context.Context, count int) error {
+	lister := ingressinformer.Get(ctx).Lister()
+	return wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Minute, true, func(context.Context) (bool, error) {
+		ings, err := lister.List(labels.Everything())
+		return len(ings) == count, err
+	})
+}
+
+type passResult struct {
+	errors   int
+	duration time.Duration
+	mallocs  uint64
+	bytes    uint64
+}
+
+// runPass reconciles all the keys once with the given number of workers.
+func runPass(ctx context.Context, r controller.Reconciler, keys []string, workers int) passResult {
+	queue := make(chan string)
+	var errs atomic.Int64
+	var wg sync.WaitGroup
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				if err := r.Reconcile(ctx, key); err != nil {
+					logging.FromContext(ctx).Errorw("Failed to reconcile "+key, "error", err)
+					errs.Add(1)
+				}
+			}
+		}()
+	}
+	for _, key := range keys {
+		queue <- key
+	}
+	close(queue)
+	wg.Wait()
+
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return passResult{
+		errors:   int(errs.Load()),
+		duration: duration,
+		mallocs:  after.Mallocs - before.Mallocs,
+		bytes:    after.TotalAlloc - before.TotalAlloc,
+	}
+}
+
+// waitForCaches waits for the informers to see the Ingresses ready and their VirtualServices.
+func waitForCaches(ctx context.Context, count int) error {
+	ingressLister := ingressinformer.Get(ctx).Lister()
+	vsLister := virtualserviceinformer.Get(ctx).Lister()
+	return wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Minute, true, func(context.Context) (bool, error) {
+		ings, err := ingressLister.List(labels.Everything())
+		if err != nil {
+			return false, err
+		}
+		for _, ing := range ings {
+			if !ing.IsReady() {
+				return false, nil
+			}
+		}
+		vss, err := vsLister.List(labels.Everything())
+		if err != nil {
+			return false, err
+		}
+		created, err := fakeistioclient.Get(ctx).Tracker().List(
+			schema.GroupVersionResource{Group: v1beta1.SchemeGroupVersion.Group, Version: v1beta1.SchemeGroupVersion.Version, Resource: "virtualservices"},
+			schema.GroupVersionKind{Group: v1beta1.SchemeGroupVersion.Group, Version: v1beta1.SchemeGroupVersion.Version, Kind: "VirtualService"},
+			"")
+		if err != nil {
+			return false, err
+		}
+		return len(vss) == len(created.(*v1beta1.VirtualServiceList).Items), nil
+	})
+}
+
+func total(counts map[string]int) int {
+	n := 0
+	for _, c := range counts {
+		n += c
+	}
+	return n
+}
+
+// writeKeys returns the sorted keys of all the counts.
+func writeKeys(counts ...map[string]int) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, c := range counts {
+		for key := range c {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The compatibility command verifies that net-istio works with the Istio installed in the
+// cluster of the current kubeconfig: it runs the checks of the features most sensitive to
+// the Istio version and profile, i.e. TLS, HTTP redirection, DomainMappings and
+// system-internal-tls, and writes a JSON report of their results along with the version
+// of istiod. Running it against clusters with different Istio versions and profiles
+// builds the compatibility matrix of net-istio.
+//
+// It accepts the flags of the conformance command, e.g. -ingressClass, -kubeconfig and
+// -test.v.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/networking/test/conformance/ingress"
+	pkgTest "knative.dev/pkg/test"
+)
+
+var (
+	checkNames = flag.String("checks", "", "A comma-separated list of the checks to run. Empty means all of them.")
+	profile    = flag.String("profile", "", "The Istio profile of the cluster, recorded in the report.")
+	reportPath = flag.String("report", "compatibility.json", "The path of the JSON report.")
+
+	istioNamespace   = flag.String("istio-namespace", "istio-system", "The namespace of istiod.")
+	systemNamespace  = flag.String("system-namespace", "knative-serving", "The namespace of config-network.")
+	gatewayNamespace = flag.String("gateway-namespace", "",
+		"The namespace of the Service of the gateway the tests reach the Ingresses through. Empty means istio-system.")
+	gateway = flag.String("gateway", "",
+		"The name of the Service of the gateway the tests reach the Ingresses through. Empty means istio-ingressgateway.")
+)
+
+// check is a feature whose compatibility is verified.
+type check struct {
+	name string
+	run  func(*testing.T)
+
+	// precondition returns why the cluster is not set up for the check, if it is not.
+	precondition func(context.Context, kubernetes.Interface) error
+}
+
+var checks = []check{{
+	name: "tls",
+	run:  ingress.TestIngressTLS,
+}, {
+	name: "redirect",
+	run:  ingress.TestHTTPOption,
+}, {
+	// DomainMappings are exposed through Ingresses rewriting their hosts into the
+	// cluster-local hosts of the mapped services.
+	name: "domainmapping",
+	run:  ingress.TestRewriteHost,
+}, {
+	// The backends of the tests serve TLS with the certificate of UPSTREAM_TLS_CERT.
+	name:         "internal-tls",
+	run:          ingress.TestBasics,
+	precondition: systemInternalTLSEnabled,
+}}
+
+// Report is the machine-readable result of the compatibility checks.
+type Report struct {
+	IstioVersion string    `json:"istioVersion"`
+	Profile      string    `json:"profile,omitempty"`
+	Started      time.Time `json:"started"`
+	Passed       bool      `json:"passed"`
+	Checks       []Result  `json:"checks"`
+}
+
+// Result is the result of a compatibility check.
+type Result struct {
+	Name string `json:"name"`
+	// Status is one of passed, failed and skipped.
+	Status string `json:"status"`
+	// Reason is why the check was skipped.
+	Reason          string  `json:"reason,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+func main() {
+	testing.Init()
+	flag.Parse()
+
+	// The test clients look the gateway up through these variables.
+	if *gatewayNamespace != "" {
+		os.Setenv("GATEWAY_NAMESPACE_OVERRIDE", *gatewayNamespace)
+	}
+	if *gateway != "" {
+		os.Setenv("GATEWAY_OVERRIDE", *gateway)
+	}
+
+	selected, err := selectChecks(*checkNames)
+	if err != nil {
+		log.Fatal(err)
+	}
+	testing.Main(regexp.MatchString, []testing.InternalTest{{
+		Name: "TestIstioCompatibility",
+		F: func(t *testing.T) {
+			runChecks(t, selected)
+		},
+	}}, nil, nil)
+}
+
+func selectChecks(names string) ([]check, error) {
+	if names == "" {
+		return checks, nil
+	}
+	var ret []check
+	for _, name := range strings.Split(names, ",") {
+		i := slices.IndexFunc(checks, func(c check) bool { return c.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown check %q", name)
+		}
+		ret = append(ret, checks[i])
+	}
+	return ret, nil
+}
+
+// runChecks runs the checks one after the other, so that their durations compare across
+// clusters, and writes the report once they are done.
+func runChecks(t *testing.T, selected []check) {
+	ctx := context.Background()
+	cfg, err := pkgTest.Flags.GetRESTConfig()
+	if err != nil {
+		t.Fatal("Failed to get the REST config:", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		t.Fatal("Failed to create the Kubernetes client:", err)
+	}
+
+	report := &Report{
+		IstioVersion: istioVersion(ctx, kubeClient),
+		Profile:      *profile,
+		Started:      time.Now().UTC(),
+		Passed:       true,
+	}
+	for _, c := range selected {
+		result := Result{Name: c.name}
+		start := time.Now()
+		if c.precondition != nil {
+			if err := c.precondition(ctx, kubeClient); err != nil {
+				result.Reason = err.Error()
+			}
+		}
+		passed := t.Run(c.name, func(t *testing.T) {
+			if result.Reason != "" {
+				t.Skip(result.Reason)
+			}
+			c.run(t)
+		})
+		result.DurationSeconds = time.Since(start).Seconds()
+
+		switch {
+		case result.Reason != "":
+			result.Status = "skipped"
+		case !passed:
+			result.Status = "failed"
+			report.Passed = false
+		default:
+			result.Status = "passed"
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatal("Failed to marshal the report:", err)
+	}
+	if err := os.WriteFile(*reportPath, append(b, '\n'), 0o644); err != nil {
+		t.Fatal("Failed to write the report:", err)
+	}
+	t.Log("Wrote the report to", *reportPath)
+}
+
+// istioVersion returns the tag of the image of istiod, or unknown.
+func istioVersion(ctx context.Context, kubeClient kubernetes.Interface) string {
+	deployment, err := kubeClient.AppsV1().Deployments(*istioNamespace).Get(ctx, "istiod", metav1.GetOptions{})
+	if err != nil {
+		return "unknown"
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if i := strings.LastIndex(container.Image, ":"); i >= 0 && container.Name == "discovery" {
+			return container.Image[i+1:]
+		}
+	}
+	return "unknown"
+}
+
+func systemInternalTLSEnabled(ctx context.Context, kubeClient kubernetes.Interface) error {
+	if os.Getenv("UPSTREAM_TLS_CERT") == "" {
+		return errors.New("UPSTREAM_TLS_CERT is not set, see DEVELOPMENT.md")
+	}
+	cm, err := kubeClient.CoreV1().ConfigMaps(*systemNamespace).Get(ctx, netconfig.ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", netconfig.ConfigMapName, err)
+	}
+	cfg, err := netconfig.NewConfigFromConfigMap(cm)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", netconfig.ConfigMapName, err)
+	}
+	if !cfg.SystemInternalTLSEnabled() {
+		return errors.New("system-internal-tls is not enabled in " + netconfig.ConfigMapName)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The compile command reads the KIngresses, the config-istio and config-network
+// ConfigMaps, the certificate Secrets, the gateway Services and the existing Gateways
+// from the manifests and directories passed as arguments, and writes the Istio resources
+// of the KIngresses to stdout, e.g.
+//
+//	go run ./cmd/compile config/ ingresses/ > istio.yaml
+//
+// The copies of the certificate Secrets are left out unless -secrets is passed, as they
+// hold private keys.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/net-istio/pkg/compile"
+	"knative.dev/net-istio/pkg/export"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/pkg/system"
+)
+
+var withSecrets = flag.Bool("secrets", false, "Whether to write the copies of the certificate Secrets in the namespaces of the gateways.")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("Pass the manifests or directories of manifests to compile")
+	}
+	if os.Getenv(system.NamespaceEnvKey) == "" {
+		os.Setenv(system.NamespaceEnvKey, "knative-serving")
+	}
+
+	inputs := &compile.Inputs{}
+	for _, path := range flag.Args() {
+		if err := read(inputs, path); err != nil {
+			log.Fatal(err)
+		}
+	}
+	objs, err := compile.Compile(context.Background(), inputs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !*withSecrets {
+		kept := objs[:0]
+		for _, obj := range objs {
+			if obj.GroupVersionKind != corev1.SchemeGroupVersion.WithKind("Secret") {
+				kept = append(kept, obj)
+			}
+		}
+		objs = kept
+	}
+
+	// The version tells the changes of the compiler from the changes of the inputs.
+	fmt.Printf("# Compiled by net-istio, translation version %d.\n", resources.TranslationVersion)
+	if err := export.WriteManifest(os.Stdout, objs); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// read adds the resources of the manifest, or of the manifests of the directory, to the
+// inputs.
+func read(inputs *compile.Inputs, path string) error {
+	return filepath.WalkDir(path, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := inputs.Read(f); err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return nil
+	})
+}
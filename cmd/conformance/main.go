@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The conformance command runs the KIngress conformance suites of knative networking
+// against a live cluster running net-istio, without a checkout of the test sources. It
+// accepts the flags of the suites, e.g. -ingressClass, -enable-alpha, -enable-beta and
+// -skip-tests, those of the Knative test clients, e.g. -kubeconfig and -ingressendpoint,
+// and the flags of go test prefixed with test., e.g. -test.v and -test.parallel.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"knative.dev/networking/test/conformance/ingress"
+)
+
+var (
+	gatewayNamespace = flag.String("gateway-namespace", "",
+		"The namespace of the Service of the gateway the tests reach the Ingresses through. Empty means istio-system.")
+	gateway = flag.String("gateway", "",
+		"The name of the Service of the gateway the tests reach the Ingresses through. Empty means istio-ingressgateway.")
+	iterations = flag.Int("iterations", 1, "The number of times the suites are run in parallel.")
+)
+
+func main() {
+	testing.Init()
+	flag.Parse()
+	if *iterations < 1 {
+		log.Fatalf("-iterations must be positive, was: %d", *iterations)
+	}
+
+	// The test clients look the gateway up through these variables.
+	if *gatewayNamespace != "" {
+		os.Setenv("GATEWAY_NAMESPACE_OVERRIDE", *gatewayNamespace)
+	}
+	if *gateway != "" {
+		os.Setenv("GATEWAY_OVERRIDE", *gateway)
+	}
+
+	testing.Main(regexp.MatchString, []testing.InternalTest{{
+		Name: "TestIngressConformance",
+		F:    runConformance,
+	}}, nil, nil)
+}
+
+func runConformance(t *testing.T) {
+	for i := 0; i < *iterations; i++ {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+			ingress.RunConformance(t)
+		})
+	}
+}
@@ -17,16 +17,46 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
 	"istio.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-istio/pkg/reconciler/catchall"
+	"knative.dev/net-istio/pkg/reconciler/chaos"
+	"knative.dev/net-istio/pkg/reconciler/fips"
 	"knative.dev/net-istio/pkg/reconciler/informerfiltering"
 	"knative.dev/net-istio/pkg/reconciler/ingress"
+	"knative.dev/net-istio/pkg/reconciler/networkpolicy"
+	"knative.dev/net-istio/pkg/reconciler/observer"
+	"knative.dev/net-istio/pkg/reconciler/peerauthentication"
+	"knative.dev/net-istio/pkg/reconciler/preflight"
+	"knative.dev/net-istio/pkg/reconciler/remotecluster"
 	"knative.dev/net-istio/pkg/reconciler/serverlessservice"
+	"knative.dev/net-istio/pkg/reconciler/shutdown"
+	"knative.dev/net-istio/pkg/reconciler/sidecar"
+	"knative.dev/net-istio/pkg/reconciler/snapshot"
+	"knative.dev/net-istio/pkg/reconciler/tuning"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
 	"knative.dev/pkg/signals"
 
 	// This defines the shared main for injected controllers.
 	"knative.dev/pkg/injection/sharedmain"
 )
 
+// disabledControllersEnv is a comma-separated list of controllers that should not be started.
+// For example, installations that do not enable mesh pod addressability can disable the
+// "serverlessservice" controller. The informers of the kinds only watched by the
+// peerauthentication, sidecar and networkpolicy controllers are built by the controllers
+// themselves, so the PeerAuthentications, Sidecars and NetworkPolicies are not watched
+// when their controller is disabled. The informers shared with the Ingress controller
+// are always started.
+const disabledControllersEnv = "DISABLED_CONTROLLERS"
+
 func main() {
 	// Allow unknown fields in Istio API client. This is to be more
 	// resilient to clusters containing malformed resources.
@@ -34,6 +64,98 @@ func main() {
 	v1beta1.GatewayUnmarshaler.AllowUnknownFields = true
 	v1beta1.DestinationRuleUnmarshaler.AllowUnknownFields = true
 
-	ctx := informerfiltering.GetContextWithFilteringLabelSelector(signals.NewContext())
-	sharedmain.MainWithContext(ctx, "net-istio-controller", ingress.NewController, serverlessservice.NewController)
+	// An invalid ENABLE_FIPS_MODE must not fall back to the legacy names and TLS settings.
+	if err := fips.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// The reconcilers are drained before the controllers are stopped and the leases released.
+	ctx, err := shutdown.NewContext(signals.NewContext())
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx = informerfiltering.GetContextWithFilteringLabelSelector(ctx)
+	if ctx, err = tuning.GetContextWithResyncPeriod(ctx); err != nil {
+		log.Fatal(err)
+	}
+	if ctx, err = tuning.GetContextWithRateLimiter(ctx); err != nil {
+		log.Fatal(err)
+	}
+	if ctx, err = tuning.GetContextWithGlobalResyncBudget(ctx); err != nil {
+		log.Fatal(err)
+	}
+	if ctx, err = tuning.GetContextWithNamespaceOwnership(ctx); err != nil {
+		log.Fatal(err)
+	}
+	// The observer mode and the emergency freeze have to replace the injected clients
+	// before the control plane replaces the Istio client.
+	observer.RegisterClients()
+	// The faults of the chaos builds are injected under the observer mode, like the
+	// failures of the API server.
+	if err := chaos.RegisterClients(); err != nil {
+		log.Fatal(err)
+	}
+	if ctx, err = remotecluster.GetContextWithControlPlane(ctx); err != nil {
+		log.Fatal(err)
+	}
+	// The readiness of the controller reflects the preflight checks of the Ingress
+	// controller, so its probes replace the ones of sharedmain.
+	status := preflight.NewStatus()
+	ctx = preflight.WithStatus(sharedmain.WithHealthProbesDisabled(ctx), status)
+	go func() {
+		if err := status.ServeProbes(ctx); err != nil {
+			log.Fatal("Failed to serve the probes: ", err)
+		}
+	}()
+	// The snapshots of the caches of the Ingress controller are served for debugging.
+	dumper := snapshot.NewDumper()
+	ctx = snapshot.WithDumper(ctx, dumper)
+	go func() {
+		if err := dumper.Serve(ctx); err != nil {
+			log.Print("Failed to serve the snapshots: ", err)
+		}
+	}()
+	sharedmain.MainWithContext(ctx, "net-istio-controller", enabledControllers(disabledControllers(os.Getenv(disabledControllersEnv)),
+		injection.NamedControllerConstructor{Name: "ingress", ControllerConstructor: ingress.NewController},
+		injection.NamedControllerConstructor{Name: "serverlessservice", ControllerConstructor: serverlessservice.NewController},
+		injection.NamedControllerConstructor{Name: "peerauthentication", ControllerConstructor: peerauthentication.NewController},
+		injection.NamedControllerConstructor{Name: "sidecar", ControllerConstructor: sidecar.NewController},
+		injection.NamedControllerConstructor{Name: "networkpolicy", ControllerConstructor: networkpolicy.NewController},
+		injection.NamedControllerConstructor{Name: "catchall", ControllerConstructor: catchall.NewController},
+	)...)
+}
+
+// disabledControllers returns the names of the controllers in the comma-separated list,
+// ignoring the blanks around them and the empty entries.
+func disabledControllers(v string) sets.Set[string] {
+	disabled := sets.New[string]()
+	for _, name := range strings.Split(v, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			disabled.Insert(name)
+		}
+	}
+	return disabled
+}
+
+// enabledControllers returns the constructors of the controllers that aren't disabled.
+func enabledControllers(disabled sets.Set[string], ctors ...injection.NamedControllerConstructor) []injection.ControllerConstructor {
+	enabled := make([]injection.ControllerConstructor, 0, len(ctors))
+	for _, ctor := range ctors {
+		if disabled.Has(ctor.Name) {
+			log.Printf("Disabling controller %s", ctor.Name)
+			continue
+		}
+		enabled = append(enabled, drained(ctor.ControllerConstructor))
+	}
+	return enabled
+}
+
+// drained returns the constructor with the reconciler of the controllers it builds drained
+// on shutdown.
+func drained(ctor injection.ControllerConstructor) injection.ControllerConstructor {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		impl := ctor(ctx, cmw)
+		impl.Reconciler = shutdown.WithDrain(ctx, impl.Reconciler)
+		return impl
+	}
 }
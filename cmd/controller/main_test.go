@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+)
+
+func TestDisabledControllers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  sets.Set[string]
+	}{{
+		name: "unset",
+		want: sets.New[string](),
+	}, {
+		name:  "single",
+		value: "serverlessservice",
+		want:  sets.New("serverlessservice"),
+	}, {
+		name:  "list with blanks and empty entries",
+		value: " serverlessservice, ,sidecar,",
+		want:  sets.New("serverlessservice", "sidecar"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := disabledControllers(test.value)
+			if !got.Equal(test.want) {
+				t.Errorf("disabledControllers(%q) = %v, want: %v", test.value, sets.List(got), sets.List(test.want))
+			}
+		})
+	}
+}
+
+func TestEnabledControllers(t *testing.T) {
+	named := func(name string) injection.NamedControllerConstructor {
+		return injection.NamedControllerConstructor{
+			Name: name,
+			ControllerConstructor: func(context.Context, configmap.Watcher) *controller.Impl {
+				return &controller.Impl{Name: name}
+			},
+		}
+	}
+	ctors := []injection.NamedControllerConstructor{named("ingress"), named("serverlessservice"), named("sidecar")}
+
+	tests := []struct {
+		name     string
+		disabled sets.Set[string]
+		want     []string
+	}{{
+		name:     "none disabled",
+		disabled: sets.New[string](),
+		want:     []string{"ingress", "serverlessservice", "sidecar"},
+	}, {
+		name:     "some disabled",
+		disabled: sets.New("serverlessservice", "unknown"),
+		want:     []string{"ingress", "sidecar"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, ctor := range enabledControllers(test.disabled, ctors...) {
+				got = append(got, ctor(context.Background(), nil).Name)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Error("Enabled controllers (-want, +got):", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The dev command sets up a local development environment of net-istio: it creates a kind
+// cluster, installs the Istio version pinned in third_party and the Knative networking CRDs,
+// and runs the controller locally against the cluster, rebuilding and restarting it whenever
+// its Go sources change. It must be run from the root of the repository, with kind and
+// kubectl on the PATH.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	clusterName  = flag.String("cluster", "net-istio-dev", "The name of the kind cluster.")
+	istioProfile = flag.String("istio-profile", "istio-kind-no-mesh", "The profile of third_party/istio-latest to install.")
+	skipSetup    = flag.Bool("skip-setup", false, "Run the controller against the existing cluster without installing anything.")
+	pollInterval = flag.Duration("poll-interval", time.Second, "How often the Go sources are checked for changes.")
+)
+
+const systemNamespace = "knative-serving"
+
+// watchedPaths are the paths whose Go sources the controller is built from.
+var watchedPaths = []string{"cmd/controller", "pkg", "go.mod"}
+
+// manifests are installed in order, after Istio.
+var manifests = []string{
+	"test/config/100-serving-namespace.yaml",
+	"test/config/300-ingress.yaml",
+	"test/config/300-serverlessservice.yaml",
+	"test/config/config-logging.yaml",
+	"test/config/config-network.yaml",
+	"config/202-gateway.yaml",
+	"config/203-local-gateway.yaml",
+	"config/400-config-istio.yaml",
+}
+
+func main() {
+	flag.Parse()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if _, err := os.Stat("go.mod"); err != nil {
+		log.Fatal("The dev command must be run from the root of the repository")
+	}
+	dir, err := os.MkdirTemp("", "net-istio-dev")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if !*skipSetup {
+		if err := setUp(ctx, kubeconfig); err != nil {
+			log.Fatal(err)
+		}
+	} else if err := writeKubeconfig(ctx, kubeconfig); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := runWithReload(ctx, filepath.Join(dir, "controller"), kubeconfig); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
+
+// setUp creates the cluster unless it exists, and installs Istio and the Knative resources
+// the controller needs.
+func setUp(ctx context.Context, kubeconfig string) error {
+	out, err := exec.CommandContext(ctx, "kind", "get", "clusters").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list the kind clusters, is kind installed? %w", err)
+	}
+	if !slices.Contains(strings.Fields(string(out)), *clusterName) {
+		log.Printf("Creating the kind cluster %s", *clusterName)
+		if err := run(ctx, "kind", "create", "cluster", "--name", *clusterName, "--wait", "5m"); err != nil {
+			return fmt.Errorf("failed to create the kind cluster: %w", err)
+		}
+	}
+	if err := writeKubeconfig(ctx, kubeconfig); err != nil {
+		return err
+	}
+	kubectl := func(args ...string) error {
+		return run(ctx, "kubectl", append([]string{"--kubeconfig", kubeconfig}, args...)...)
+	}
+
+	log.Printf("Installing Istio (%s)", *istioProfile)
+	istio := filepath.Join("third_party", "istio-latest", *istioProfile, "istio.yaml")
+	// The custom resources of the manifest fail to apply until their CRDs are established.
+	if err := kubectl("apply", "-f", istio); err != nil {
+		if err := kubectl("wait", "--for=condition=Established", "crd", "--all", "--timeout=2m"); err != nil {
+			return fmt.Errorf("failed to wait for the Istio CRDs: %w", err)
+		}
+		if err := kubectl("apply", "-f", istio); err != nil {
+			return fmt.Errorf("failed to install Istio: %w", err)
+		}
+	}
+	if err := kubectl("wait", "--for=condition=Available", "deployment", "--all", "-n", "istio-system", "--timeout=5m"); err != nil {
+		return fmt.Errorf("failed to wait for Istio: %w", err)
+	}
+
+	log.Print("Installing the Knative networking resources")
+	for _, m := range manifests {
+		if err := kubectl("apply", "-f", m); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", m, err)
+		}
+	}
+	return nil
+}
+
+func writeKubeconfig(ctx context.Context, kubeconfig string) error {
+	out, err := exec.CommandContext(ctx, "kind", "get", "kubeconfig", "--name", *clusterName).Output()
+	if err != nil {
+		return fmt.Errorf("failed to get the kubeconfig of the kind cluster %s: %w", *clusterName, err)
+	}
+	return os.WriteFile(kubeconfig, out, 0o600)
+}
+
+// runWithReload runs the controller, and rebuilds and restarts it when its sources change.
+// The running controller is only stopped once the changed sources build.
+func runWithReload(ctx context.Context, binary, kubeconfig string) error {
+	var controller *exec.Cmd
+	var exited chan error
+	stop := func() {
+		if controller == nil {
+			return
+		}
+		controller.Process.Signal(syscall.SIGTERM)
+		<-exited
+		controller = nil
+	}
+	defer stop()
+
+	var built time.Time
+	ticker := time.NewTicker(*pollInterval)
+	defer ticker.Stop()
+	for {
+		modified, err := lastModified(watchedPaths...)
+		if err != nil {
+			return err
+		}
+		if modified.After(built) {
+			built = time.Now()
+			log.Print("Building the controller")
+			if err := run(ctx, "go", "build", "-o", binary, "./cmd/controller"); err != nil {
+				log.Print("Failed to build the controller, keeping the previous build running: ", err)
+			} else {
+				stop()
+				log.Print("Starting the controller")
+				controller = exec.Command(binary, "--kubeconfig", kubeconfig)
+				controller.Env = append(os.Environ(), "SYSTEM_NAMESPACE="+systemNamespace)
+				controller.Stdout, controller.Stderr = os.Stdout, os.Stderr
+				if err := controller.Start(); err != nil {
+					return fmt.Errorf("failed to start the controller: %w", err)
+				}
+				exited = make(chan error, 1)
+				go func(cmd *exec.Cmd, exited chan<- error) {
+					exited <- cmd.Wait()
+				}(controller, exited)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-exited:
+			// The controller is restarted once the sources are fixed.
+			log.Print("The controller exited: ", err)
+			controller = nil
+			exited = nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// lastModified returns the last modification time of the Go sources and modules under the paths.
+func lastModified(paths ...string) (time.Time, error) {
+	var last time.Time
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, ".mod") {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.ModTime().After(last) {
+				last = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return last, nil
+}
+
+func run(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The explain command prints why a KIngress of the cluster is not Ready, by walking the
+// decision points of its reconciliation, e.g. whether its certificate Secrets, Gateways
+// and VirtualServices exist and are up to date. It exits with 1 when the KIngress is not
+// Ready, so that scripts can wait on it.
+//
+//	explain -namespace=default hello
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"k8s.io/client-go/kubernetes"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	"knative.dev/net-istio/pkg/explain"
+	networkingclientset "knative.dev/networking/pkg/client/clientset/versioned"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
+)
+
+var namespace = flag.String("namespace", "default", "The namespace of the KIngress.")
+
+func main() {
+	cfg := injection.ParseAndGetRESTConfigOrDie()
+	ctx := signals.NewContext()
+	// The configuration of the controller is read from its namespace.
+	if os.Getenv(system.NamespaceEnvKey) == "" {
+		os.Setenv(system.NamespaceEnvKey, "knative-serving")
+	}
+	if flag.NArg() != 1 {
+		log.Fatal("Usage: explain [-namespace=<namespace>] <kingress>")
+	}
+
+	e, err := explain.Explain(ctx, explain.Clients{
+		Kube:       kubernetes.NewForConfigOrDie(cfg),
+		Istio:      istioclientset.NewForConfigOrDie(cfg),
+		Networking: networkingclientset.NewForConfigOrDie(cfg),
+	}, *namespace, flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := e.Write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	if !e.Ready() {
+		os.Exit(1)
+	}
+}
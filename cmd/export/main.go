@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The export command writes a manifest of every resource net-istio manages in the
+// cluster to stdout, ordered so that it can be applied as is. The resources keep
+// their owner references: restoring them into another cluster requires mapping
+// those to the UIDs of the restored Knative resources, as backup tools do.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"k8s.io/client-go/kubernetes"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	"knative.dev/net-istio/pkg/export"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/signals"
+)
+
+var namespace = flag.String("namespace", "", "The namespace to export the resources of. Empty means all namespaces.")
+
+func main() {
+	cfg := injection.ParseAndGetRESTConfigOrDie()
+	ctx := signals.NewContext()
+
+	objs, err := export.Collect(ctx, kubernetes.NewForConfigOrDie(cfg), istioclientset.NewForConfigOrDie(cfg), *namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := export.WriteManifest(os.Stdout, objs); err != nil {
+		log.Fatal(err)
+	}
+}
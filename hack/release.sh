@@ -23,6 +23,7 @@ source $(dirname $0)/../vendor/knative.dev/hack/release.sh
 declare -A COMPONENTS
 COMPONENTS=(
   ["net-istio.yaml"]="config"
+  ["net-istio-statefulset.yaml"]="config/statefulset"
 )
 readonly COMPONENTS
 
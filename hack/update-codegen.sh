@@ -32,7 +32,7 @@ group "Knative Codegen"
 # Knative Injection (for istio)
 ${KNATIVE_CODEGEN_PKG}/hack/generate-knative.sh "injection" \
   knative.dev/net-istio/pkg/client/istio istio.io/client-go/pkg/apis \
-  "networking:v1beta1" \
+  "networking:v1alpha3,v1beta1 security:v1beta1 telemetry:v1alpha1 extensions:v1alpha1" \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
 
 group "Kubernetes Codegen"
@@ -40,7 +40,7 @@ group "Kubernetes Codegen"
 # Generate our own client for istio (otherwise injection won't work)
 ${CODEGEN_PKG}/generate-groups.sh "client,informer,lister" \
   knative.dev/net-istio/pkg/client/istio istio.io/client-go/pkg/apis \
-  "networking:v1beta1" \
+  "networking:v1alpha3,v1beta1 security:v1beta1 telemetry:v1alpha1 extensions:v1alpha1" \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
 
 group "Deepcopy Gen"
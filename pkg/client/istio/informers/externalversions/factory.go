@@ -28,8 +28,11 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 	versioned "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	extensions "knative.dev/net-istio/pkg/client/istio/informers/externalversions/extensions"
 	internalinterfaces "knative.dev/net-istio/pkg/client/istio/informers/externalversions/internalinterfaces"
 	networking "knative.dev/net-istio/pkg/client/istio/informers/externalversions/networking"
+	security "knative.dev/net-istio/pkg/client/istio/informers/externalversions/security"
+	telemetry "knative.dev/net-istio/pkg/client/istio/informers/externalversions/telemetry"
 )
 
 // SharedInformerOption defines the functional option type for SharedInformerFactory.
@@ -253,9 +256,24 @@ type SharedInformerFactory interface {
 	// client.
 	InformerFor(obj runtime.Object, newFunc internalinterfaces.NewInformerFunc) cache.SharedIndexInformer
 
+	Extensions() extensions.Interface
 	Networking() networking.Interface
+	Security() security.Interface
+	Telemetry() telemetry.Interface
+}
+
+func (f *sharedInformerFactory) Extensions() extensions.Interface {
+	return extensions.New(f, f.namespace, f.tweakListOptions)
 }
 
 func (f *sharedInformerFactory) Networking() networking.Interface {
 	return networking.New(f, f.namespace, f.tweakListOptions)
 }
+
+func (f *sharedInformerFactory) Security() security.Interface {
+	return security.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Telemetry() telemetry.Interface {
+	return telemetry.New(f, f.namespace, f.tweakListOptions)
+}
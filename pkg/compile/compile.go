@@ -0,0 +1,305 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compile renders the Istio resources of KIngresses offline, from manifests
+// rather than from a cluster, e.g. for GitOps pipelines pre-rendering them.
+//
+// The resources are the ones of resources.Translate: the copies of the certificate
+// Secrets, the Gateways and the VirtualServices. The resources depending on the state of
+// the cluster beyond the inputs, e.g. the DestinationRules and AuthorizationPolicies of
+// the backend services, are left to the controller.
+package compile
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"knative.dev/net-istio/pkg/export"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/hooks"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	secretGVK         = corev1.SchemeGroupVersion.WithKind("Secret")
+	gatewayGVK        = v1beta1.SchemeGroupVersion.WithKind("Gateway")
+	virtualServiceGVK = v1beta1.SchemeGroupVersion.WithKind("VirtualService")
+)
+
+// Inputs are the resources the Istio resources are compiled from.
+type Inputs struct {
+	// Ingresses are the KIngresses to compile.
+	Ingresses []*v1alpha1.Ingress
+
+	// ConfigMaps hold the config-istio and config-network ConfigMaps of the system
+	// namespace. The defaults are used for the missing ones.
+	ConfigMaps []*corev1.ConfigMap
+
+	// Secrets hold the certificates referenced by the TLS of the Ingresses.
+	Secrets []*corev1.Secret
+
+	// Services hold the Services of the ingress gateways of config-istio.
+	Services []*corev1.Service
+
+	// Gateways are the Gateways that exist besides the compiled ones, e.g. the shared
+	// knative-ingress-gateway and knative-local-gateway.
+	Gateways []*v1beta1.Gateway
+}
+
+// Read adds the resources of the multi-document YAML or JSON manifest to the inputs.
+// The resources of other kinds are ignored, so that whole directories of manifests can
+// be read.
+func (in *Inputs) Read(r io.Reader) error {
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return err
+		}
+
+		var obj interface{}
+		switch typeMeta.GroupVersionKind() {
+		case v1alpha1.SchemeGroupVersion.WithKind("Ingress"):
+			ing := &v1alpha1.Ingress{}
+			in.Ingresses, obj = append(in.Ingresses, ing), ing
+		case corev1.SchemeGroupVersion.WithKind("ConfigMap"):
+			cm := &corev1.ConfigMap{}
+			in.ConfigMaps, obj = append(in.ConfigMaps, cm), cm
+		case secretGVK:
+			secret := &corev1.Secret{}
+			in.Secrets, obj = append(in.Secrets, secret), secret
+		case corev1.SchemeGroupVersion.WithKind("Service"):
+			svc := &corev1.Service{}
+			in.Services, obj = append(in.Services, svc), svc
+		case gatewayGVK:
+			gateway := &v1beta1.Gateway{}
+			in.Gateways, obj = append(in.Gateways, gateway), gateway
+		default:
+			continue
+		}
+		if err := yaml.Unmarshal(doc, obj); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", typeMeta.Kind, err)
+		}
+	}
+}
+
+// Compile returns the Istio resources of the Ingresses, ordered by kind, namespace and
+// name, so that the same inputs always compile into the same manifest. The resources
+// shared by several Ingresses, i.e. the Gateways and Secrets of wildcard certificates,
+// are returned once, and the VirtualServices may only reference the compiled Gateways
+// and the Gateways of the inputs. The hooks attached to the context mutate the compiled
+// resources, like they do in the controller.
+func Compile(ctx context.Context, in *Inputs) ([]export.Object, error) {
+	cfg, err := newConfig(in.ConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	ctx = config.ToContext(ctx, cfg)
+
+	secrets := make(map[string]*corev1.Secret, len(in.Secrets))
+	for _, secret := range in.Secrets {
+		secrets[secret.Namespace+"/"+secret.Name] = secret
+	}
+	services := make(map[string]*corev1.Service, len(in.Services))
+	for _, svc := range in.Services {
+		services[svc.Namespace+"/"+svc.Name] = svc
+	}
+
+	c := &compilation{objects: map[schema.GroupVersionKind]map[string]metav1.Object{}}
+	for _, ing := range in.Ingresses {
+		ing = ing.DeepCopy()
+		ing.SetDefaults(ctx)
+		if err := c.add(ctx, ing, secrets, services); err != nil {
+			return nil, fmt.Errorf("failed to compile Ingress %s/%s: %w", ing.Namespace, ing.Name, err)
+		}
+	}
+
+	// The VirtualServices must only reference Gateways that exist once the manifest is
+	// applied.
+	gateways := sets.KeySet(c.objects[gatewayGVK])
+	for _, gateway := range in.Gateways {
+		gateways.Insert(gateway.Namespace + "/" + gateway.Name)
+	}
+	for _, obj := range c.objects[virtualServiceGVK] {
+		vs := obj.(*v1beta1.VirtualService)
+		for _, gateway := range vs.Spec.Gateways {
+			if gateway != "mesh" && !gateways.Has(gateway) {
+				return nil, fmt.Errorf("VirtualService %s/%s references the Gateway %s, which is neither compiled nor an input", vs.Namespace, vs.Name, gateway)
+			}
+		}
+	}
+
+	var ret []export.Object
+	for _, gvk := range []schema.GroupVersionKind{secretGVK, gatewayGVK, virtualServiceGVK} {
+		keys := sets.List(sets.KeySet(c.objects[gvk]))
+		for _, key := range keys {
+			obj, err := export.NewObject(gvk, c.objects[gvk][key])
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s %s: %w", gvk.Kind, key, err)
+			}
+			ret = append(ret, obj)
+		}
+	}
+	return ret, nil
+}
+
+// compilation collects the compiled resources by kind and namespace/name.
+type compilation struct {
+	objects map[schema.GroupVersionKind]map[string]metav1.Object
+}
+
+// add compiles the Ingress, reading the certificate Secrets and gateway Services it
+// references from the inputs.
+func (c *compilation) add(ctx context.Context, ing *v1alpha1.Ingress, secrets map[string]*corev1.Secret, services map[string]*corev1.Service) error {
+	// Like the controller, refuse the certificates the clients would reject.
+	for _, visibility := range resources.TLSVisibilities(ctx, ing) {
+		if err := resources.ValidateCertificateHosts(ing.GetIngressTLSForVisibility(visibility), secrets); err != nil {
+			return err
+		}
+	}
+
+	gatewayServices := map[v1alpha1.IngressVisibility][]*corev1.Service{}
+	for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+		if !resources.UsesGatewayServices(ctx, ing, visibility) {
+			continue
+		}
+		metas, err := resources.GetGatewaySvcNameNamespacesForVisibility(ctx, ing, visibility)
+		if err != nil {
+			return err
+		}
+		for _, meta := range metas {
+			svc, ok := services[meta.Namespace+"/"+meta.Name]
+			if !ok {
+				return fmt.Errorf("missing the gateway Service %s/%s", meta.Namespace, meta.Name)
+			}
+			gatewayServices[visibility] = append(gatewayServices[visibility], svc)
+		}
+	}
+
+	translation, err := resources.Translate(ctx, ing, gatewayServices, secrets)
+	if err != nil {
+		return err
+	}
+	// The hooks of the builds of the controller also apply to the compiled resources.
+	h := hooks.FromContext(ctx)
+	if err := h.MutateVirtualServices(ctx, ing, translation.VirtualServices); err != nil {
+		return err
+	}
+	for _, gateways := range [][]*v1beta1.Gateway{
+		translation.WildcardGateways,
+		translation.Gateways[v1alpha1.IngressVisibilityExternalIP],
+		translation.Gateways[v1alpha1.IngressVisibilityClusterLocal],
+	} {
+		if err := h.MutateGateways(ctx, ing, gateways); err != nil {
+			return err
+		}
+	}
+	for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+		for _, secret := range translation.Secrets[visibility] {
+			if err := c.insert(secretGVK, secret); err != nil {
+				return err
+			}
+		}
+		for _, gateway := range translation.Gateways[visibility] {
+			if err := c.insert(gatewayGVK, gateway); err != nil {
+				return err
+			}
+		}
+	}
+	for _, gateway := range translation.WildcardGateways {
+		if err := c.insert(gatewayGVK, gateway); err != nil {
+			return err
+		}
+	}
+	for _, vs := range translation.VirtualServices {
+		if err := c.insert(virtualServiceGVK, vs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insert adds the resource, unless an identical one was compiled for another Ingress.
+func (c *compilation) insert(gvk schema.GroupVersionKind, obj metav1.Object) error {
+	// The API server rejects the owner references without UID, i.e. to the inputs that
+	// were not read from a cluster.
+	var owners []metav1.OwnerReference
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.UID != "" {
+			owners = append(owners, owner)
+		}
+	}
+	obj.SetOwnerReferences(owners)
+
+	key := obj.GetNamespace() + "/" + obj.GetName()
+	if c.objects[gvk] == nil {
+		c.objects[gvk] = map[string]metav1.Object{}
+	}
+	if existing, ok := c.objects[gvk][key]; ok {
+		if !reflect.DeepEqual(existing, obj) {
+			return fmt.Errorf("%s %s is compiled differently for several Ingresses", gvk.Kind, key)
+		}
+		return nil
+	}
+	c.objects[gvk][key] = obj
+	return nil
+}
+
+// newConfig returns the configuration of the ConfigMaps of the system namespace.
+func newConfig(configMaps []*corev1.ConfigMap) (*config.Config, error) {
+	istio, network := &corev1.ConfigMap{}, &corev1.ConfigMap{}
+	for _, cm := range configMaps {
+		if cm.Namespace != "" && cm.Namespace != system.Namespace() {
+			continue
+		}
+		switch cm.Name {
+		case config.IstioConfigName:
+			istio = cm
+		case netconfig.ConfigMapName:
+			network = cm
+		}
+	}
+
+	cfg := &config.Config{}
+	var err error
+	if cfg.Istio, err = config.NewIstioFromConfigMap(istio); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", config.IstioConfigName, err)
+	}
+	if cfg.Network, err = netconfig.NewConfigFromConfigMap(network); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", netconfig.ConfigMapName, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compile
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/net-istio/pkg/export"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"sigs.k8s.io/yaml"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+const gateways = `
+apiVersion: networking.istio.io/v1beta1
+kind: Gateway
+metadata:
+  name: knative-ingress-gateway
+  namespace: knative-testing
+spec:
+  selector:
+    istio: ingressgateway
+---
+apiVersion: networking.istio.io/v1beta1
+kind: Gateway
+metadata:
+  name: knative-local-gateway
+  namespace: knative-testing
+spec:
+  selector:
+    istio: ingressgateway
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: istio-ingressgateway
+  namespace: istio-system
+spec:
+  selector:
+    istio: ingressgateway
+---
+# Ignored.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: net-istio-controller
+  namespace: knative-testing
+`
+
+func ingress(name, host string, tls bool) string {
+	ing := `
+apiVersion: networking.internal.knative.dev/v1alpha1
+kind: Ingress
+metadata:
+  name: ` + name + `
+  namespace: ns
+spec:
+  rules:
+  - hosts: [` + host + `]
+    visibility: ExternalIP
+    http:
+      paths:
+      - splits:
+        - serviceName: ` + name + `
+          serviceNamespace: ns
+          servicePort: 80
+`
+	if tls {
+		ing += `
+  tls:
+  - hosts: [` + host + `]
+    secretName: wildcard
+    secretNamespace: ns
+`
+	}
+	return ing
+}
+
+func read(t *testing.T, docs ...string) *Inputs {
+	t.Helper()
+	in := &Inputs{}
+	if err := in.Read(strings.NewReader(strings.Join(docs, "\n---\n"))); err != nil {
+		t.Fatal("Read() =", err)
+	}
+	return in
+}
+
+func keys(objs []export.Object) []string {
+	ret := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		ret = append(ret, obj.GroupVersionKind.Kind+" "+obj.Namespace+"/"+obj.Name)
+	}
+	return ret
+}
+
+func TestCompile(t *testing.T) {
+	in := read(t, gateways, ingress("foo", "foo.example.com", false), ingress("bar", "bar.example.com", false))
+	if len(in.Ingresses) != 2 || len(in.Gateways) != 2 || len(in.Services) != 1 {
+		t.Fatalf("Read() = %d Ingresses, %d Gateways, %d Services, want: 2, 2, 1", len(in.Ingresses), len(in.Gateways), len(in.Services))
+	}
+
+	objs, err := Compile(context.Background(), in)
+	if err != nil {
+		t.Fatal("Compile() =", err)
+	}
+	want := []string{
+		"VirtualService ns/bar-ingress",
+		"VirtualService ns/foo-ingress",
+	}
+	if got := keys(objs); !cmp.Equal(got, want) {
+		t.Error("Compile (-want, +got):", cmp.Diff(want, got))
+	}
+
+	// The same inputs compile into the same manifest.
+	var first, second bytes.Buffer
+	if err := export.WriteManifest(&first, objs); err != nil {
+		t.Fatal("WriteManifest() =", err)
+	}
+	objs, err = Compile(context.Background(), read(t, gateways, ingress("bar", "bar.example.com", false), ingress("foo", "foo.example.com", false)))
+	if err != nil {
+		t.Fatal("Compile() =", err)
+	}
+	if err := export.WriteManifest(&second, objs); err != nil {
+		t.Fatal("WriteManifest() =", err)
+	}
+	if diff := cmp.Diff(first.String(), second.String()); diff != "" {
+		t.Error("Compile of the reordered inputs (-first, +second):", diff)
+	}
+}
+
+func TestCompileSharedWildcardGateway(t *testing.T) {
+	secret, err := resources.GenerateCertificate([]string{"*.example.com"}, "wildcard", "ns")
+	if err != nil {
+		t.Fatal("GenerateCertificate() =", err)
+	}
+	secret.APIVersion, secret.Kind = "v1", "Secret"
+	b, err := yaml.Marshal(secret)
+	if err != nil {
+		t.Fatal("Marshal() =", err)
+	}
+
+	objs, err := Compile(context.Background(), read(t, gateways, string(b),
+		ingress("foo", "foo.example.com", true), ingress("bar", "bar.example.com", true)))
+	if err != nil {
+		t.Fatal("Compile() =", err)
+	}
+	var wildcardGateways, secrets int
+	for _, obj := range objs {
+		switch {
+		case obj.GroupVersionKind == gatewayGVK && obj.Name == resources.WildcardGatewayName("wildcard", "istio-system", "istio-ingressgateway"):
+			wildcardGateways++
+		case obj.GroupVersionKind == secretGVK:
+			secrets++
+		}
+	}
+	if wildcardGateways != 1 || secrets != 1 {
+		t.Errorf("Compile() = %d wildcard Gateways, %d Secrets, want: 1, 1 shared by the Ingresses", wildcardGateways, secrets)
+	}
+}
+
+func TestCompileMissingGateway(t *testing.T) {
+	in := read(t, ingress("foo", "foo.example.com", false))
+	if _, err := Compile(context.Background(), in); err == nil || !strings.Contains(err.Error(), "knative-testing/knative-ingress-gateway") {
+		t.Errorf("Compile() = %v, want an error for the missing knative-ingress-gateway", err)
+	}
+}
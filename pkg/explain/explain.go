@@ -0,0 +1,379 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package explain diagnoses why an Ingress is not Ready, by walking the decision points
+// of its reconciliation against the resources of the cluster: its ingress class and
+// conditions, the configuration, the certificate Secrets, the Gateways, the
+// VirtualServices and the endpoints of the gateways its readiness is probed on.
+//
+// The resources are generated like the controller does, through resources.Translate and
+// the hooks attached to the context, so that the differences with the ones of the
+// cluster point at the reconciliations that failed or did not happen yet.
+package explain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/hooks"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkingclientset "knative.dev/networking/pkg/client/clientset/versioned"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/system"
+)
+
+// Clients are the clients of the cluster the Ingress is explained against.
+type Clients struct {
+	Kube       kubernetes.Interface
+	Istio      istioclientset.Interface
+	Networking networkingclientset.Interface
+}
+
+// Finding is the outcome of a decision point of the reconciliation of an Ingress.
+type Finding struct {
+	// Check is the decision point, e.g. "Secret istio-system/cert".
+	Check string
+	// OK is whether the decision point lets the Ingress become Ready.
+	OK bool
+	// Detail is what was found.
+	Detail string
+}
+
+// Explanation lists the findings about an Ingress, in the order of its reconciliation.
+type Explanation struct {
+	Ingress  *v1alpha1.Ingress
+	Findings []Finding
+}
+
+// Explain returns the findings about the Ingress of the given namespace and name. It
+// only returns an error when the Ingress can't be read, the failures of the other
+// decision points are findings.
+func Explain(ctx context.Context, c Clients, namespace, name string) (*Explanation, error) {
+	ing, err := c.Networking.NetworkingV1alpha1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Ingress %s/%s: %w", namespace, name, err)
+	}
+	e := &Explanation{Ingress: ing}
+	e.explainStatus()
+
+	cfg, err := e.config(ctx, c)
+	if err != nil {
+		e.add("Configuration", false, "%v", err)
+		return e, nil
+	}
+	ctx = config.ToContext(ctx, cfg)
+
+	secrets := e.explainSecrets(ctx, c)
+	gatewayServices := e.explainGateways(ctx, c)
+	if gatewayServices == nil && (resources.UsesGatewayServices(ctx, ing, v1alpha1.IngressVisibilityExternalIP) ||
+		resources.UsesGatewayServices(ctx, ing, v1alpha1.IngressVisibilityClusterLocal)) {
+		// The resources can't be generated without the Services of the gateways.
+		return e, nil
+	}
+	translation, err := resources.Translate(ctx, ing, gatewayServices, secrets)
+	if err != nil {
+		e.add("Translation", false, "%v", err)
+		return e, nil
+	}
+	h := hooks.FromContext(ctx)
+	if err := h.MutateVirtualServices(ctx, ing, translation.VirtualServices); err != nil {
+		e.add("Hooks", false, "%v", err)
+		return e, nil
+	}
+	e.explainTranslation(ctx, c, translation)
+	return e, nil
+}
+
+// Ready returns whether the Ingress is Ready.
+func (e *Explanation) Ready() bool {
+	return e.Ingress.IsReady()
+}
+
+// Write writes the findings in a human-readable form, the failing ones first.
+func (e *Explanation) Write(w io.Writer) error {
+	state := "is not Ready"
+	if e.Ready() {
+		state = "is Ready"
+	}
+	if _, err := fmt.Fprintf(w, "Ingress %s/%s %s.\n", e.Ingress.Namespace, e.Ingress.Name, state); err != nil {
+		return err
+	}
+	for _, ok := range []bool{false, true} {
+		for _, f := range e.Findings {
+			if f.OK != ok {
+				continue
+			}
+			mark := "FAIL"
+			if f.OK {
+				mark = "ok"
+			}
+			if _, err := fmt.Fprintf(w, "  %-4s  %s: %s\n", mark, f.Check, f.Detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Explanation) add(check string, ok bool, format string, args ...interface{}) {
+	e.Findings = append(e.Findings, Finding{Check: check, OK: ok, Detail: fmt.Sprintf(format, args...)})
+}
+
+// explainStatus explains whether the controller reconciled the Ingress and the
+// conditions it reported.
+func (e *Explanation) explainStatus() {
+	ing := e.Ingress
+	// The Ingresses without class are reconciled by the controller of the default class.
+	if class := ing.Annotations[networking.IngressClassAnnotationKey]; class == "" || class == netconfig.IstioIngressClassName {
+		e.add("Ingress class", true, "the Ingress is reconciled by net-istio")
+	} else {
+		e.add("Ingress class", false, "the Ingress is of class %q, net-istio only reconciles %q", class, netconfig.IstioIngressClassName)
+	}
+
+	if ing.Status.ObservedGeneration != ing.Generation {
+		e.add("Observed generation", false, "the controller observed the generation %d of the Ingress, not the generation %d yet",
+			ing.Status.ObservedGeneration, ing.Generation)
+	} else {
+		e.add("Observed generation", true, "the controller observed the generation %d", ing.Generation)
+	}
+
+	if len(ing.Status.Conditions) == 0 {
+		e.add("Conditions", false, "the controller did not report any condition yet")
+	}
+	for _, cond := range ing.Status.Conditions {
+		detail := string(cond.Status)
+		if cond.Reason != "" {
+			detail += " (" + cond.Reason + ")"
+		}
+		if cond.Message != "" {
+			detail += ": " + cond.Message
+		}
+		e.add("Condition "+string(cond.Type), cond.IsTrue(), "%s", detail)
+	}
+}
+
+// config returns the configuration of the controller, with the defaults of the missing
+// ConfigMaps.
+func (e *Explanation) config(ctx context.Context, c Clients) (*config.Config, error) {
+	get := func(name string) (*corev1.ConfigMap, error) {
+		cm, err := c.Kube.CoreV1().ConfigMaps(system.Namespace()).Get(ctx, name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			return &corev1.ConfigMap{}, nil
+		}
+		return cm, err
+	}
+	istio, err := get(config.IstioConfigName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", config.IstioConfigName, err)
+	}
+	network, err := get(netconfig.ConfigMapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", netconfig.ConfigMapName, err)
+	}
+
+	cfg := &config.Config{}
+	if cfg.Istio, err = config.NewIstioFromConfigMap(istio); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", config.IstioConfigName, err)
+	}
+	if cfg.Network, err = netconfig.NewConfigFromConfigMap(network); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", netconfig.ConfigMapName, err)
+	}
+	return cfg, nil
+}
+
+// explainSecrets explains whether the certificate Secrets the TLS of the Ingress
+// references exist and cover its hosts, and returns the existing ones.
+func (e *Explanation) explainSecrets(ctx context.Context, c Clients) map[string]*corev1.Secret {
+	secrets := map[string]*corev1.Secret{}
+	for _, visibility := range resources.TLSVisibilities(ctx, e.Ingress) {
+		ingressTLS := e.Ingress.GetIngressTLSForVisibility(visibility)
+		for _, tls := range ingressTLS {
+			check := "Secret " + tls.SecretNamespace + "/" + tls.SecretName
+			secret, err := c.Kube.CoreV1().Secrets(tls.SecretNamespace).Get(ctx, tls.SecretName, metav1.GetOptions{})
+			if err != nil {
+				e.add(check, false, "failed to get the certificate of the hosts %v: %v", tls.Hosts, err)
+				continue
+			}
+			secrets[tls.SecretNamespace+"/"+tls.SecretName] = secret
+			if err := resources.ValidateCertificateHosts([]v1alpha1.IngressTLS{tls}, secrets); err != nil {
+				e.add(check, false, "%v", err)
+			} else {
+				e.add(check, true, "the certificate covers the hosts %v", tls.Hosts)
+			}
+		}
+	}
+	return secrets
+}
+
+// explainGateways explains whether the Gateways of the configuration the Ingress is
+// exposed on exist, and whether their Services have ready endpoints to probe, and
+// returns the Services by visibility, or nil when one of them is missing.
+func (e *Explanation) explainGateways(ctx context.Context, c Clients) map[v1alpha1.IngressVisibility][]*corev1.Service {
+	if denied, err := resources.DeniedGateways(ctx, e.Ingress); err != nil {
+		e.add("Gateways", false, "%v", err)
+	} else if len(denied) > 0 {
+		e.add("Gateways", false, "the gateways %v are dedicated to other namespaces than %s", denied, e.Ingress.Namespace)
+	}
+
+	gateways, err := resources.QualifiedGatewayNamesFromContext(ctx, e.Ingress)
+	if err != nil {
+		e.add("Gateways", false, "%v", err)
+		return nil
+	}
+	services := make(map[v1alpha1.IngressVisibility][]*corev1.Service, 2)
+	missing := false
+	for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+		if !hasRules(e.Ingress, visibility) {
+			continue
+		}
+		for _, qualifiedName := range sets.List(gateways[visibility]) {
+			e.explainGateway(ctx, c, qualifiedName, nil)
+		}
+
+		metas, err := resources.GetGatewaySvcNameNamespacesForVisibility(ctx, e.Ingress, visibility)
+		if err != nil {
+			e.add("Gateway Services", false, "%v", err)
+			return nil
+		}
+		for _, meta := range metas {
+			svc, ok := e.explainGatewayService(ctx, c, meta)
+			if !ok {
+				missing = true
+				continue
+			}
+			services[visibility] = append(services[visibility], svc)
+		}
+	}
+	if missing {
+		return nil
+	}
+	return services
+}
+
+// explainGatewayService explains whether the Service of a gateway exists and has ready
+// endpoints to probe, and returns it.
+func (e *Explanation) explainGatewayService(ctx context.Context, c Clients, meta metav1.ObjectMeta) (*corev1.Service, bool) {
+	check := "Gateway Service " + meta.Namespace + "/" + meta.Name
+	svc, err := c.Kube.CoreV1().Services(meta.Namespace).Get(ctx, meta.Name, metav1.GetOptions{})
+	if err != nil {
+		e.add(check, false, "failed to get the Service: %v", err)
+		return nil, false
+	}
+
+	// The readiness of the Ingress is probed on the pods of the gateways.
+	endpoints, err := c.Kube.CoreV1().Endpoints(meta.Namespace).Get(ctx, meta.Name, metav1.GetOptions{})
+	if err != nil {
+		e.add(check, false, "failed to get the endpoints to probe: %v", err)
+		return svc, true
+	}
+	ready := 0
+	for _, subset := range endpoints.Subsets {
+		ready += len(subset.Addresses)
+	}
+	if ready == 0 {
+		e.add(check, false, "no ready endpoint, the probes of the Ingress can't succeed")
+	} else {
+		e.add(check, true, "%d ready endpoints to probe", ready)
+	}
+	return svc, true
+}
+
+// explainGateway explains whether the Gateway of the qualified name exists and, if it is
+// generated, whether it is up to date.
+func (e *Explanation) explainGateway(ctx context.Context, c Clients, qualifiedName string, desired *v1beta1.Gateway) {
+	namespace, name, _ := strings.Cut(qualifiedName, "/")
+	check := "Gateway " + qualifiedName
+	existing, err := c.Istio.NetworkingV1beta1().Gateways(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err != nil:
+		e.add(check, false, "failed to get the Gateway: %v", err)
+	case desired == nil:
+		e.add(check, true, "the Gateway exists")
+	case !kaccessor.SpecHashMatches(existing, &existing.Spec, desired):
+		e.add(check, false, "the Gateway differs from the generated one, the controller did not update it yet")
+	default:
+		e.add(check, true, "the Gateway is up to date")
+	}
+}
+
+// explainTranslation explains whether the resources generated for the Ingress exist and
+// are up to date.
+func (e *Explanation) explainTranslation(ctx context.Context, c Clients, t *resources.Translation) {
+	h := hooks.FromContext(ctx)
+	for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+		for _, secret := range t.Secrets[visibility] {
+			check := "Secret " + secret.Namespace + "/" + secret.Name
+			if _, err := c.Kube.CoreV1().Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{}); err != nil {
+				e.add(check, false, "failed to get the copy of the certificate for the gateways: %v", err)
+			} else {
+				e.add(check, true, "the copy of the certificate for the gateways exists")
+			}
+		}
+	}
+
+	var gateways []*v1beta1.Gateway
+	gateways = append(gateways, t.WildcardGateways...)
+	gateways = append(gateways, t.Gateways[v1alpha1.IngressVisibilityExternalIP]...)
+	gateways = append(gateways, t.Gateways[v1alpha1.IngressVisibilityClusterLocal]...)
+	if err := h.MutateGateways(ctx, e.Ingress, gateways); err != nil {
+		e.add("Hooks", false, "%v", err)
+		return
+	}
+	for _, gateway := range gateways {
+		// The spec hash of the generated Gateways is recorded when they are applied.
+		gateway.Annotations = kaccessor.WithSpecHash(gateway.Annotations, &gateway.Spec)
+		e.explainGateway(ctx, c, gateway.Namespace+"/"+gateway.Name, gateway)
+	}
+
+	for _, desired := range t.VirtualServices {
+		check := "VirtualService " + desired.Namespace + "/" + desired.Name
+		existing, err := c.Istio.NetworkingV1beta1().VirtualServices(desired.Namespace).Get(ctx, desired.Name, metav1.GetOptions{})
+		switch {
+		case err != nil:
+			e.add(check, false, "failed to get the VirtualService: %v", err)
+		case !metav1.IsControlledBy(existing, e.Ingress):
+			e.add(check, false, "the VirtualService is not owned by the Ingress")
+		case !kaccessor.SpecHashMatches(existing, &existing.Spec, desired):
+			e.add(check, false, "the VirtualService differs from the generated one, the controller did not update it yet")
+		default:
+			e.add(check, true, "the VirtualService is up to date")
+		}
+	}
+}
+
+// hasRules returns whether the Ingress has rules of the visibility, which are exposed
+// on the Gateways of the visibility.
+func hasRules(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility) bool {
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility == visibility {
+			return true
+		}
+	}
+	return false
+}
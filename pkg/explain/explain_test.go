@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	istiofake "knative.dev/net-istio/pkg/client/istio/clientset/versioned/fake"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkingfake "knative.dev/networking/pkg/client/clientset/versioned/fake"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+var ing = &v1alpha1.Ingress{
+	ObjectMeta: metav1.ObjectMeta{
+		Namespace:  "test-ns",
+		Name:       "ingress",
+		UID:        "8a7e9a9d-fbc1-4b5c-8d11-3d6b6a8e2f5c",
+		Generation: 2,
+	},
+	Spec: v1alpha1.IngressSpec{
+		Rules: []v1alpha1.IngressRule{{
+			Hosts:      []string{"foo.example.com"},
+			Visibility: v1alpha1.IngressVisibilityExternalIP,
+			HTTP: &v1alpha1.HTTPIngressRuleValue{
+				Paths: []v1alpha1.HTTPIngressPath{{
+					Splits: []v1alpha1.IngressBackendSplit{{
+						IngressBackend: v1alpha1.IngressBackend{ServiceNamespace: "test-ns", ServiceName: "foo"},
+						Percent:        100,
+					}},
+				}},
+			},
+		}},
+	},
+	Status: v1alpha1.IngressStatus{
+		Status: duckv1.Status{
+			ObservedGeneration: 2,
+			Conditions: duckv1.Conditions{{
+				Type:   v1alpha1.IngressConditionLoadBalancerReady,
+				Status: corev1.ConditionUnknown,
+				Reason: "Uninitialized",
+			}, {
+				Type:   apis.ConditionReady,
+				Status: corev1.ConditionUnknown,
+			}},
+		},
+	},
+}
+
+func failing(e *Explanation) []string {
+	var ret []string
+	for _, f := range e.Findings {
+		if !f.OK {
+			ret = append(ret, f.Check)
+		}
+	}
+	return ret
+}
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+	gatewayService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-ingressgateway"}}
+	clients := Clients{
+		Kube:       kubefake.NewSimpleClientset(gatewayService),
+		Istio:      istiofake.NewSimpleClientset(),
+		Networking: networkingfake.NewSimpleClientset(ing),
+	}
+	// The fake clientset tracks the Gateways it is seeded with as "gatewaies", so they
+	// are created through the client instead.
+	if _, err := clients.Istio.NetworkingV1beta1().Gateways(system.Namespace()).Create(ctx, &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: config.KnativeIngressGateway},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create Gateway:", err)
+	}
+
+	e, err := Explain(ctx, clients, ing.Namespace, ing.Name)
+	if err != nil {
+		t.Fatal("Explain() =", err)
+	}
+	want := []string{
+		"Condition LoadBalancerReady",
+		"Condition Ready",
+		"Gateway Service istio-system/istio-ingressgateway",
+		"VirtualService test-ns/ingress-ingress",
+	}
+	if got := failing(e); !cmp.Equal(got, want) {
+		t.Error("Failing findings (-want, +got):", cmp.Diff(want, got))
+	}
+
+	var b bytes.Buffer
+	if err := e.Write(&b); err != nil {
+		t.Fatal("Write() =", err)
+	}
+	if got, want := b.String(), "Ingress test-ns/ingress is not Ready.\n  FAIL  Condition LoadBalancerReady: Unknown (Uninitialized)\n"; !strings.HasPrefix(got, want) {
+		t.Errorf("Write() = %q, want the prefix %q", got, want)
+	}
+
+	// The gateway pods become ready and the controller creates the VirtualService.
+	if _, err := clients.Kube.CoreV1().Endpoints(gatewayService.Namespace).Create(ctx, &corev1.Endpoints{
+		ObjectMeta: gatewayService.ObjectMeta,
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create Endpoints:", err)
+	}
+	gateways := map[v1alpha1.IngressVisibility]sets.Set[string]{
+		v1alpha1.IngressVisibilityExternalIP: sets.New(system.Namespace() + "/" + config.KnativeIngressGateway),
+	}
+	vses, err := resources.MakeVirtualServices(ing.DeepCopy(), gateways)
+	if err != nil {
+		t.Fatal("MakeVirtualServices() =", err)
+	}
+	for _, vs := range vses {
+		if _, err := clients.Istio.NetworkingV1beta1().VirtualServices(vs.Namespace).Create(ctx, vs, metav1.CreateOptions{}); err != nil {
+			t.Fatal("Failed to create VirtualService:", err)
+		}
+	}
+	if e, err = Explain(ctx, clients, ing.Namespace, ing.Name); err != nil {
+		t.Fatal("Explain() =", err)
+	}
+	want = []string{"Condition LoadBalancerReady", "Condition Ready"}
+	if got := failing(e); !cmp.Equal(got, want) {
+		t.Error("Failing findings (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
+func TestExplainMissingIngress(t *testing.T) {
+	clients := Clients{
+		Kube:       kubefake.NewSimpleClientset(),
+		Istio:      istiofake.NewSimpleClientset(),
+		Networking: networkingfake.NewSimpleClientset(),
+	}
+	if _, err := Explain(context.Background(), clients, "test-ns", "missing"); err == nil {
+		t.Error("Explain() = nil, want an error for the missing Ingress")
+	}
+}
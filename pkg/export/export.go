@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export collects the resources net-istio manages into a manifest, e.g. to
+// back them up or to bootstrap a standby cluster.
+//
+// The Secrets net-istio mirrors into the namespaces of the gateways are left out:
+// they hold private keys, and the controller recreates them from the Secrets the
+// Ingresses reference.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	catchallresources "knative.dev/net-istio/pkg/reconciler/catchall/resources"
+	networkpolicyresources "knative.dev/net-istio/pkg/reconciler/networkpolicy/resources"
+	peerauthenticationresources "knative.dev/net-istio/pkg/reconciler/peerauthentication/resources"
+	sidecarresources "knative.dev/net-istio/pkg/reconciler/sidecar/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	"sigs.k8s.io/yaml"
+)
+
+// managedLabelKeys are the labels net-istio attaches to the resources it manages
+// that are not owned by a Knative resource.
+var managedLabelKeys = []string{
+	networking.IngressLabelKey,
+	catchallresources.ManagedLabelKey,
+	networkpolicyresources.ManagedLabelKey,
+	peerauthenticationresources.ManagedLabelKey,
+	sidecarresources.ManagedLabelKey,
+}
+
+// Object is a managed resource, as it is written to the manifest.
+type Object struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+
+	// Content holds the fields of the resource, without its status and the
+	// metadata set by the API server.
+	Content map[string]interface{}
+}
+
+// kind lists the resources of a kind in the given namespace.
+type kind struct {
+	gvk  schema.GroupVersionKind
+	list func(ctx context.Context, c clients, namespace string) ([]metav1.Object, error)
+}
+
+type clients struct {
+	kube  kubernetes.Interface
+	istio istioclientset.Interface
+}
+
+// kinds are the kinds of the resources net-istio manages, in the order in which
+// they are written, so that resources come after the ones they refer to.
+var kinds = []kind{{
+	gvk: schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.kube.NetworkingV1().NetworkPolicies(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]metav1.Object, 0, len(l.Items))
+		for i := range l.Items {
+			ret = append(ret, &l.Items[i])
+		}
+		return ret, nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "PeerAuthentication"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.SecurityV1beta1().PeerAuthentications(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "AuthorizationPolicy"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.SecurityV1beta1().AuthorizationPolicies(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "RequestAuthentication"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.SecurityV1beta1().RequestAuthentications(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "Sidecar"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.NetworkingV1beta1().Sidecars(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "DestinationRule"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.NetworkingV1beta1().DestinationRules(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "Gateway"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.NetworkingV1beta1().Gateways(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.NetworkingV1beta1().VirtualServices(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1alpha3", Kind: "EnvoyFilter"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.NetworkingV1alpha3().EnvoyFilters(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "extensions.istio.io", Version: "v1alpha1", Kind: "WasmPlugin"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.ExtensionsV1alpha1().WasmPlugins(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}, {
+	gvk: schema.GroupVersionKind{Group: "telemetry.istio.io", Version: "v1alpha1", Kind: "Telemetry"},
+	list: func(ctx context.Context, c clients, ns string) ([]metav1.Object, error) {
+		l, err := c.istio.TelemetryV1alpha1().Telemetries(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return objects(l.Items), nil
+	},
+}}
+
+func objects[T metav1.Object](items []T) []metav1.Object {
+	ret := make([]metav1.Object, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+// Collect returns the resources managed by net-istio in the namespace, or in all
+// namespaces when it is empty, ordered by kind, namespace and name.
+func Collect(ctx context.Context, kubeClient kubernetes.Interface, istioClient istioclientset.Interface, namespace string) ([]Object, error) {
+	c := clients{kube: kubeClient, istio: istioClient}
+
+	var ret []Object
+	for _, k := range kinds {
+		items, err := k.list(ctx, c, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", k.gvk.Kind, err)
+		}
+		objs := make([]Object, 0, len(items))
+		for _, item := range items {
+			if !IsManaged(item) {
+				continue
+			}
+			obj, err := NewObject(k.gvk, item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export %s %s/%s: %w", k.gvk.Kind, item.GetNamespace(), item.GetName(), err)
+			}
+			objs = append(objs, obj)
+		}
+		sort.Slice(objs, func(i, j int) bool {
+			if objs[i].Namespace != objs[j].Namespace {
+				return objs[i].Namespace < objs[j].Namespace
+			}
+			return objs[i].Name < objs[j].Name
+		})
+		ret = append(ret, objs...)
+	}
+	return ret, nil
+}
+
+// IsManaged returns whether the resource is managed by net-istio, i.e. whether it is
+// owned by a Knative networking resource or carries one of the labels of net-istio.
+func IsManaged(obj metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err == nil && gv.Group == networking.GroupName {
+			return true
+		}
+	}
+	for _, key := range managedLabelKeys {
+		if _, ok := obj.GetLabels()[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NewObject converts the resource into an Object, keeping its labels, annotations and
+// owner references, and dropping its status and the metadata set by the API server.
+func NewObject(gvk schema.GroupVersionKind, item metav1.Object) (Object, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return Object{}, err
+	}
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(b, &content); err != nil {
+		return Object{}, err
+	}
+
+	content["apiVersion"], content["kind"] = gvk.GroupVersion().String(), gvk.Kind
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+			delete(metadata, field)
+		}
+	}
+
+	return Object{
+		GroupVersionKind: gvk,
+		Namespace:        item.GetNamespace(),
+		Name:             item.GetName(),
+		Content:          content,
+	}, nil
+}
+
+// WriteManifest writes the objects as a multi-document YAML manifest.
+func WriteManifest(w io.Writer, objs []Object) error {
+	for _, obj := range objs {
+		b, err := yaml.Marshal(obj.Content)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s/%s: %w", obj.GroupVersionKind.Kind, obj.Namespace, obj.Name, err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	istiofake "knative.dev/net-istio/pkg/client/istio/clientset/versioned/fake"
+	catchallresources "knative.dev/net-istio/pkg/reconciler/catchall/resources"
+	networkpolicyresources "knative.dev/net-istio/pkg/reconciler/networkpolicy/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+var ing = &v1alpha1.Ingress{
+	ObjectMeta: metav1.ObjectMeta{
+		Namespace: "test-ns",
+		Name:      "ingress",
+		UID:       "8a7e9a9d-fbc1-4b5c-8d11-3d6b6a8e2f5c",
+	},
+}
+
+func virtualService(namespace, name string, owned bool) *v1beta1.VirtualService {
+	vs := &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			ResourceVersion: "42",
+			UID:             "uid",
+		},
+		Spec: istiov1beta1.VirtualService{
+			Hosts:    []string{"foo.example.com"},
+			Gateways: []string{"knative-serving/knative-ingress-gateway"},
+		},
+	}
+	if owned {
+		vs.Labels = map[string]string{networking.IngressLabelKey: ing.Name}
+		vs.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
+	}
+	return vs
+}
+
+func TestCollect(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(&netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      networkpolicyresources.NetworkPolicyName,
+			Labels:    map[string]string{networkpolicyresources.ManagedLabelKey: "true"},
+		},
+	}, &netv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "user-policy",
+		},
+	})
+	istioClient := istiofake.NewSimpleClientset(
+		virtualService("test-ns", "ingress-mesh", true),
+		virtualService("test-ns", "ingress-ingress", true),
+		virtualService("other-ns", "ingress-ingress", true),
+		virtualService("test-ns", "user-vs", false),
+		catchallresources.MakeVirtualService("knative-serving", []string{"knative-serving/knative-ingress-gateway"}, 404, ""),
+	)
+	// The fake clientset tracks the Gateways it is seeded with as "gatewaies", so they
+	// are created through the client instead.
+	if _, err := istioClient.NetworkingV1beta1().Gateways("test-ns").Create(context.Background(), &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "test-ns",
+			Name:            "ingress-gateway",
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+		},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Failed to create Gateway:", err)
+	}
+
+	objs, err := Collect(context.Background(), kubeClient, istioClient, "")
+	if err != nil {
+		t.Fatal("Collect() =", err)
+	}
+	got := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		got = append(got, obj.GroupVersionKind.Kind+" "+obj.Namespace+"/"+obj.Name)
+	}
+	want := []string{
+		"NetworkPolicy test-ns/knative-serving",
+		"Gateway test-ns/ingress-gateway",
+		"VirtualService knative-serving/knative-catch-all",
+		"VirtualService other-ns/ingress-ingress",
+		"VirtualService test-ns/ingress-ingress",
+		"VirtualService test-ns/ingress-mesh",
+	}
+	if !cmp.Equal(got, want) {
+		t.Error("Collect() (-want, +got):", cmp.Diff(want, got))
+	}
+
+	objs, err = Collect(context.Background(), kubeClient, istioClient, "other-ns")
+	if err != nil {
+		t.Fatal("Collect() =", err)
+	}
+	if len(objs) != 1 || objs[0].Namespace != "other-ns" {
+		t.Errorf("Collect(other-ns) = %v, want the VirtualService of other-ns", objs)
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	istioClient := istiofake.NewSimpleClientset(virtualService("test-ns", "ingress-ingress", true))
+	objs, err := Collect(context.Background(), kubefake.NewSimpleClientset(), istioClient, "")
+	if err != nil {
+		t.Fatal("Collect() =", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteManifest(&buf, objs); err != nil {
+		t.Fatal("WriteManifest() =", err)
+	}
+	want := `---
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  labels:
+    networking.internal.knative.dev/ingress: ingress
+  name: ingress-ingress
+  namespace: test-ns
+  ownerReferences:
+  - apiVersion: networking.internal.knative.dev/v1alpha1
+    blockOwnerDeletion: true
+    controller: true
+    kind: Ingress
+    name: ingress
+    uid: 8a7e9a9d-fbc1-4b5c-8d11-3d6b6a8e2f5c
+spec:
+  gateways:
+  - knative-serving/knative-ingress-gateway
+  hosts:
+  - foo.example.com
+`
+	if got := buf.String(); got != want {
+		t.Error("WriteManifest() (-want, +got):", cmp.Diff(want, got))
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/redact"
+	"knative.dev/net-istio/pkg/reconciler/secretmetadata"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/kmeta"
 )
@@ -41,23 +43,24 @@ type SecretAccessor interface {
 func ReconcileSecret(ctx context.Context, owner kmeta.Accessor, desired *corev1.Secret, accessor SecretAccessor) (*corev1.Secret, error) {
 	recorder := controller.GetEventRecorder(ctx)
 	if recorder == nil {
-		return nil, fmt.Errorf("recoder for reconciling Secret %s/%s is not created", desired.Namespace, desired.Name)
+		return nil, fmt.Errorf("recoder for reconciling %v is not created", redact.Secret(desired))
 	}
-	secret, err := accessor.GetSecretLister().Secrets(desired.Namespace).Get(desired.Name)
+	secret, err := secretmetadata.WithContext(ctx, accessor.GetSecretLister()).Secrets(desired.Namespace).Get(desired.Name)
 	if apierrs.IsNotFound(err) {
 		secret, err = accessor.GetKubeClient().CoreV1().Secrets(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
 		if err != nil {
+			err = redact.Error(err, desired)
 			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
-				"Failed to create Secret %s/%s: %v", desired.Namespace, desired.Name, err)
-			return nil, fmt.Errorf("failed to create Secret: %w", err)
+				"Failed to create %v: %v", redact.Secret(desired), err)
+			return nil, fmt.Errorf("failed to create %v: %w", redact.Secret(desired), err)
 		}
-		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created Secret %s/%s", desired.Namespace, desired.Name)
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created %v", redact.Secret(desired))
 	} else if err != nil {
-		return nil, fmt.Errorf("failed to get Secret: %w", err)
+		return nil, fmt.Errorf("failed to get %v: %w", redact.Secret(desired), err)
 	} else if owner != nil && !metav1.IsControlledBy(secret, owner) {
 		// Return an error with NotControlledBy information.
 		return nil, kaccessor.NewAccessorError(
-			fmt.Errorf("owner: %s with Type %T does not own Secret: %s", owner.GetName(), owner, secret.Name),
+			fmt.Errorf("owner: %s with Type %T does not own %v", owner.GetName(), owner, redact.Secret(secret)),
 			kaccessor.NotOwnResource)
 	} else if !equality.Semantic.DeepEqual(secret.Data, desired.Data) || !equality.Semantic.DeepEqual(secret.Labels, desired.Labels) {
 		// Don't modify the informers copy
@@ -66,10 +69,11 @@ func ReconcileSecret(ctx context.Context, owner kmeta.Accessor, desired *corev1.
 		deepCopy.Labels = desired.Labels
 		secret, err = accessor.GetKubeClient().CoreV1().Secrets(deepCopy.Namespace).Update(ctx, deepCopy, metav1.UpdateOptions{})
 		if err != nil {
-			recorder.Eventf(owner, corev1.EventTypeWarning, "UpdateFailed", "Failed to update Secret %s/%s: %v", desired.Namespace, desired.Name, err)
-			return nil, fmt.Errorf("failed to update Secret: %w", err)
+			err = redact.Error(err, desired)
+			recorder.Eventf(owner, corev1.EventTypeWarning, "UpdateFailed", "Failed to update %v: %v", redact.Secret(desired), err)
+			return nil, fmt.Errorf("failed to update %v: %w", redact.Secret(desired), err)
 		}
-		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated Secret %s/%s", deepCopy.Namespace, deepCopy.Name)
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated %v", redact.Secret(desired))
 	}
 	return secret, nil
 }
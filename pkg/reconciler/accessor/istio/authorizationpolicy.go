@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/security/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+)
+
+// AuthorizationPolicyAccessor is an interface for accessing AuthorizationPolicy.
+type AuthorizationPolicyAccessor interface {
+	GetIstioClient() istioclientset.Interface
+	GetAuthorizationPolicyLister() istiolisters.AuthorizationPolicyLister
+}
+
+func authorizationPolicyIsDifferent(current, desired *v1beta1.AuthorizationPolicy) bool {
+	if !cmp.Equal(current.Labels, desired.Labels) || !cmp.Equal(current.Annotations, desired.Annotations) {
+		return true
+	}
+	if kaccessor.SpecHashMatches(current, &current.Spec, desired) {
+		return false
+	}
+	return !cmp.Equal(&current.Spec, &desired.Spec, protocmp.Transform())
+}
+
+// ReconcileAuthorizationPolicy reconciles AuthorizationPolicy to the desired status.
+func ReconcileAuthorizationPolicy(ctx context.Context, owner kmeta.Accessor, desired *v1beta1.AuthorizationPolicy,
+	apAccessor AuthorizationPolicyAccessor) (*v1beta1.AuthorizationPolicy, error) {
+
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		return nil, fmt.Errorf("recorder for reconciling AuthorizationPolicy %s/%s is not created", desired.Namespace, desired.Name)
+	}
+	ns := desired.Namespace
+	name := desired.Name
+	ap, err := apAccessor.GetAuthorizationPolicyLister().AuthorizationPolicies(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		ap, err = apAccessor.GetIstioClient().SecurityV1beta1().AuthorizationPolicies(ns).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
+				"Failed to create AuthorizationPolicy %s/%s: %v", ns, name, err)
+			return nil, fmt.Errorf("failed to create AuthorizationPolicy: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %q", desired.Name)
+	} else if err != nil {
+		return nil, err
+	} else if !metav1.IsControlledBy(ap, owner) {
+		// Return an error with NotControlledBy information.
+		return nil, kaccessor.NewAccessorError(
+			fmt.Errorf("owner: %s with Type %T does not own AuthorizationPolicy: %q", owner.GetName(), owner, name),
+			kaccessor.NotOwnResource)
+	} else if authorizationPolicyIsDifferent(ap, desired) {
+		// Don't modify the informers copy
+		existing := ap.DeepCopy()
+		existing.Spec = *desired.Spec.DeepCopy()
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		ap, err = apAccessor.GetIstioClient().SecurityV1beta1().AuthorizationPolicies(ns).Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update AuthorizationPolicy: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated AuthorizationPolicy %s/%s", ns, name)
+	}
+	return ap, nil
+}
+
+// ReconcileGatewayAuthorizationPolicy reconciles an AuthorizationPolicy generated next to
+// the gateways, in another namespace than its owner, to the desired status. Unlike with
+// ReconcileAuthorizationPolicy, the ones of the owner are told apart by their labels
+// matching owned.
+func ReconcileGatewayAuthorizationPolicy(ctx context.Context, owner kmeta.Accessor, desired *v1beta1.AuthorizationPolicy,
+	owned labels.Selector, apAccessor AuthorizationPolicyAccessor) (*v1beta1.AuthorizationPolicy, error) {
+
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		return nil, fmt.Errorf("recorder for reconciling AuthorizationPolicy %s/%s is not created", desired.Namespace, desired.Name)
+	}
+	ns := desired.Namespace
+	name := desired.Name
+	ap, err := apAccessor.GetAuthorizationPolicyLister().AuthorizationPolicies(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		ap, err = apAccessor.GetIstioClient().SecurityV1beta1().AuthorizationPolicies(ns).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
+				"Failed to create AuthorizationPolicy %s/%s: %v", ns, name, err)
+			return nil, fmt.Errorf("failed to create AuthorizationPolicy: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", ns, name)
+	} else if err != nil {
+		return nil, err
+	} else if !owned.Matches(labels.Set(ap.Labels)) {
+		// Return an error with NotControlledBy information.
+		return nil, kaccessor.NewAccessorError(
+			fmt.Errorf("owner: %s with Type %T does not own AuthorizationPolicy: %s/%s", owner.GetName(), owner, ns, name),
+			kaccessor.NotOwnResource)
+	} else if !kaccessor.SpecHashMatches(ap, &ap.Spec, desired) {
+		// Don't modify the informers copy
+		existing := ap.DeepCopy()
+		existing.Spec = *desired.Spec.DeepCopy()
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		ap, err = apAccessor.GetIstioClient().SecurityV1beta1().AuthorizationPolicies(ns).Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update AuthorizationPolicy: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated AuthorizationPolicy %s/%s", ns, name)
+	}
+	return ap, nil
+}
@@ -40,9 +40,13 @@ type DestinationRuleAccessor interface {
 }
 
 func destionationRuleIsDifferent(current, desired *v1beta1.DestinationRule) bool {
-	return !cmp.Equal(&current.Spec, &desired.Spec, protocmp.Transform()) ||
-		!cmp.Equal(current.Labels, desired.Labels) ||
-		!cmp.Equal(current.Annotations, desired.Annotations)
+	if !cmp.Equal(current.Labels, desired.Labels) || !cmp.Equal(current.Annotations, desired.Annotations) {
+		return true
+	}
+	if kaccessor.SpecHashMatches(current, &current.Spec, desired) {
+		return false
+	}
+	return !cmp.Equal(&current.Spec, &desired.Spec, protocmp.Transform())
 }
 
 // ReconcileDestinationRule reconciles DestinationRule to the desired status.
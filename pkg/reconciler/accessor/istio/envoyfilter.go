@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1alpha3"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+)
+
+// EnvoyFilterAccessor is an interface for accessing EnvoyFilter.
+type EnvoyFilterAccessor interface {
+	GetIstioClient() istioclientset.Interface
+	GetEnvoyFilterLister() istiolisters.EnvoyFilterLister
+}
+
+// ReconcileEnvoyFilter reconciles EnvoyFilter to the desired status. The EnvoyFilters are
+// generated next to the gateways, in another namespace than their owner, so the
+// ones of the owner are told apart by their labels matching owned.
+func ReconcileEnvoyFilter(ctx context.Context, owner kmeta.Accessor, desired *v1alpha3.EnvoyFilter,
+	owned labels.Selector, efAccessor EnvoyFilterAccessor) (*v1alpha3.EnvoyFilter, error) {
+
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		return nil, fmt.Errorf("recorder for reconciling EnvoyFilter %s/%s is not created", desired.Namespace, desired.Name)
+	}
+	ns := desired.Namespace
+	name := desired.Name
+	ef, err := efAccessor.GetEnvoyFilterLister().EnvoyFilters(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		ef, err = efAccessor.GetIstioClient().NetworkingV1alpha3().EnvoyFilters(ns).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
+				"Failed to create EnvoyFilter %s/%s: %v", ns, name, err)
+			return nil, fmt.Errorf("failed to create EnvoyFilter: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created EnvoyFilter %s/%s", ns, name)
+	} else if err != nil {
+		return nil, err
+	} else if !owned.Matches(labels.Set(ef.Labels)) {
+		// Return an error with NotControlledBy information.
+		return nil, kaccessor.NewAccessorError(
+			fmt.Errorf("owner: %s with Type %T does not own EnvoyFilter: %s/%s", owner.GetName(), owner, ns, name),
+			kaccessor.NotOwnResource)
+	} else if !kaccessor.SpecHashMatches(ef, &ef.Spec, desired) {
+		// Don't modify the informers copy
+		existing := ef.DeepCopy()
+		existing.Spec = *desired.Spec.DeepCopy()
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		ef, err = efAccessor.GetIstioClient().NetworkingV1alpha3().EnvoyFilters(ns).Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update EnvoyFilter: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated EnvoyFilter %s/%s", ns, name)
+	}
+	return ef, nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
+	fakeefinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1alpha3/envoyfilter/fake"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1alpha3"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	gatewayResourceLabels = map[string]string{"owner": ownerObj.Name}
+
+	originEnvoyFilter = &v1alpha3.EnvoyFilter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ef",
+			Namespace: "istio-system",
+			Labels:    gatewayResourceLabels,
+		},
+		Spec: istiov1alpha3.EnvoyFilter{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"istio": "origin"}},
+		},
+	}
+
+	desiredEnvoyFilter = &v1alpha3.EnvoyFilter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ef",
+			Namespace: "istio-system",
+			Labels:    gatewayResourceLabels,
+		},
+		Spec: istiov1alpha3.EnvoyFilter{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"istio": "ingressgateway"}},
+		},
+	}
+
+	notOwnedEnvoyFilter = &v1alpha3.EnvoyFilter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ef",
+			Namespace: "istio-system",
+		},
+		Spec: istiov1alpha3.EnvoyFilter{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"istio": "origin"}},
+		},
+	}
+)
+
+type FakeEnvoyFilterAccessor struct {
+	client   istioclientset.Interface
+	efLister istiolisters.EnvoyFilterLister
+}
+
+func (f *FakeEnvoyFilterAccessor) GetIstioClient() istioclientset.Interface {
+	return f.client
+}
+
+func (f *FakeEnvoyFilterAccessor) GetEnvoyFilterLister() istiolisters.EnvoyFilterLister {
+	return f.efLister
+}
+
+func TestReconcileEnvoyFilter_Create(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	efInformer := fakeefinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeEnvoyFilterAccessor{
+		client:   istio,
+		efLister: efInformer.Lister(),
+	}
+
+	h := NewHooks()
+	h.OnCreate(&istio.Fake, "envoyfilters", func(obj runtime.Object) HookResult {
+		got := obj.(*v1alpha3.EnvoyFilter)
+		if diff := cmp.Diff(got, desiredEnvoyFilter, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected EnvoyFilter (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileEnvoyFilter(ctx, ownerObj, desiredEnvoyFilter, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile EnvoyFilter:", err)
+	}
+}
+
+func TestReconcileEnvoyFilter_Update(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	efInformer := fakeefinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeEnvoyFilterAccessor{
+		client:   istio,
+		efLister: efInformer.Lister(),
+	}
+
+	istio.NetworkingV1alpha3().EnvoyFilters(originEnvoyFilter.Namespace).Create(ctx, originEnvoyFilter, metav1.CreateOptions{})
+	efInformer.Informer().GetIndexer().Add(originEnvoyFilter)
+
+	h := NewHooks()
+	h.OnUpdate(&istio.Fake, "envoyfilters", func(obj runtime.Object) HookResult {
+		got := obj.(*v1alpha3.EnvoyFilter)
+		if diff := cmp.Diff(got, desiredEnvoyFilter, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected EnvoyFilter (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileEnvoyFilter(ctx, ownerObj, desiredEnvoyFilter, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile EnvoyFilter:", err)
+	}
+}
+
+func TestReconcileEnvoyFilter_NotOwnedFailure(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	efInformer := fakeefinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeEnvoyFilterAccessor{
+		client:   istio,
+		efLister: efInformer.Lister(),
+	}
+
+	istio.NetworkingV1alpha3().EnvoyFilters(originEnvoyFilter.Namespace).Create(ctx, notOwnedEnvoyFilter, metav1.CreateOptions{})
+	efInformer.Informer().GetIndexer().Add(notOwnedEnvoyFilter)
+
+	_, err = ReconcileEnvoyFilter(ctx, ownerObj, desiredEnvoyFilter, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+	if err == nil {
+		t.Error("Expected to get error when calling ReconcileEnvoyFilter, but got no error.")
+	}
+	if !kaccessor.IsNotOwned(err) {
+		t.Error("Expected to get NotOwnedError but got", err)
+	}
+}
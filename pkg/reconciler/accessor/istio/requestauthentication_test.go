@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
+	fakerainformer "knative.dev/net-istio/pkg/client/istio/injection/informers/security/v1beta1/requestauthentication/fake"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/security/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	originRequestAuthentication = &v1beta1.RequestAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra",
+			Namespace: "istio-system",
+			Labels:    gatewayResourceLabels,
+		},
+		Spec: istiov1beta1.RequestAuthentication{
+			JwtRules: []*istiov1beta1.JWTRule{{Issuer: "https://origin.example.com"}},
+		},
+	}
+
+	desiredRequestAuthentication = &v1beta1.RequestAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra",
+			Namespace: "istio-system",
+			Labels:    gatewayResourceLabels,
+		},
+		Spec: istiov1beta1.RequestAuthentication{
+			JwtRules: []*istiov1beta1.JWTRule{{Issuer: "https://issuer.example.com"}},
+		},
+	}
+
+	notOwnedRequestAuthentication = &v1beta1.RequestAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ra",
+			Namespace: "istio-system",
+		},
+		Spec: istiov1beta1.RequestAuthentication{
+			JwtRules: []*istiov1beta1.JWTRule{{Issuer: "https://origin.example.com"}},
+		},
+	}
+)
+
+type FakeRequestAuthenticationAccessor struct {
+	client   istioclientset.Interface
+	raLister istiolisters.RequestAuthenticationLister
+}
+
+func (f *FakeRequestAuthenticationAccessor) GetIstioClient() istioclientset.Interface {
+	return f.client
+}
+
+func (f *FakeRequestAuthenticationAccessor) GetRequestAuthenticationLister() istiolisters.RequestAuthenticationLister {
+	return f.raLister
+}
+
+func TestReconcileRequestAuthentication_Create(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	raInformer := fakerainformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeRequestAuthenticationAccessor{
+		client:   istio,
+		raLister: raInformer.Lister(),
+	}
+
+	h := NewHooks()
+	h.OnCreate(&istio.Fake, "requestauthentications", func(obj runtime.Object) HookResult {
+		got := obj.(*v1beta1.RequestAuthentication)
+		if diff := cmp.Diff(got, desiredRequestAuthentication, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected RequestAuthentication (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileRequestAuthentication(ctx, ownerObj, desiredRequestAuthentication, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile RequestAuthentication:", err)
+	}
+}
+
+func TestReconcileRequestAuthentication_Update(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	raInformer := fakerainformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeRequestAuthenticationAccessor{
+		client:   istio,
+		raLister: raInformer.Lister(),
+	}
+
+	istio.SecurityV1beta1().RequestAuthentications(originRequestAuthentication.Namespace).Create(ctx, originRequestAuthentication, metav1.CreateOptions{})
+	raInformer.Informer().GetIndexer().Add(originRequestAuthentication)
+
+	h := NewHooks()
+	h.OnUpdate(&istio.Fake, "requestauthentications", func(obj runtime.Object) HookResult {
+		got := obj.(*v1beta1.RequestAuthentication)
+		if diff := cmp.Diff(got, desiredRequestAuthentication, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected RequestAuthentication (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileRequestAuthentication(ctx, ownerObj, desiredRequestAuthentication, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile RequestAuthentication:", err)
+	}
+}
+
+func TestReconcileRequestAuthentication_NotOwnedFailure(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	raInformer := fakerainformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeRequestAuthenticationAccessor{
+		client:   istio,
+		raLister: raInformer.Lister(),
+	}
+
+	istio.SecurityV1beta1().RequestAuthentications(originRequestAuthentication.Namespace).Create(ctx, notOwnedRequestAuthentication, metav1.CreateOptions{})
+	raInformer.Informer().GetIndexer().Add(notOwnedRequestAuthentication)
+
+	_, err = ReconcileRequestAuthentication(ctx, ownerObj, desiredRequestAuthentication, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+	if err == nil {
+		t.Error("Expected to get error when calling ReconcileRequestAuthentication, but got no error.")
+	}
+	if !kaccessor.IsNotOwned(err) {
+		t.Error("Expected to get NotOwnedError but got", err)
+	}
+}
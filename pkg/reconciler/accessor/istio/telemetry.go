@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"istio.io/client-go/pkg/apis/telemetry/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/telemetry/v1alpha1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+)
+
+// TelemetryAccessor is an interface for accessing Telemetry.
+type TelemetryAccessor interface {
+	GetIstioClient() istioclientset.Interface
+	GetTelemetryLister() istiolisters.TelemetryLister
+}
+
+func telemetryIsDifferent(current, desired *v1alpha1.Telemetry) bool {
+	if !cmp.Equal(current.Labels, desired.Labels) || !cmp.Equal(current.Annotations, desired.Annotations) {
+		return true
+	}
+	if kaccessor.SpecHashMatches(current, &current.Spec, desired) {
+		return false
+	}
+	return !cmp.Equal(&current.Spec, &desired.Spec, protocmp.Transform())
+}
+
+// ReconcileTelemetry reconciles Telemetry to the desired status.
+func ReconcileTelemetry(ctx context.Context, owner kmeta.Accessor, desired *v1alpha1.Telemetry,
+	tAccessor TelemetryAccessor) (*v1alpha1.Telemetry, error) {
+
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		return nil, fmt.Errorf("recorder for reconciling Telemetry %s/%s is not created", desired.Namespace, desired.Name)
+	}
+	ns := desired.Namespace
+	name := desired.Name
+	t, err := tAccessor.GetTelemetryLister().Telemetries(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		t, err = tAccessor.GetIstioClient().TelemetryV1alpha1().Telemetries(ns).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
+				"Failed to create Telemetry %s/%s: %v", ns, name, err)
+			return nil, fmt.Errorf("failed to create Telemetry: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created Telemetry %q", desired.Name)
+	} else if err != nil {
+		return nil, err
+	} else if !metav1.IsControlledBy(t, owner) {
+		// Return an error with NotControlledBy information.
+		return nil, kaccessor.NewAccessorError(
+			fmt.Errorf("owner: %s with Type %T does not own Telemetry: %q", owner.GetName(), owner, name),
+			kaccessor.NotOwnResource)
+	} else if telemetryIsDifferent(t, desired) {
+		// Don't modify the informers copy
+		existing := t.DeepCopy()
+		existing.Spec = *desired.Spec.DeepCopy()
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		t, err = tAccessor.GetIstioClient().TelemetryV1alpha1().Telemetries(ns).Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update Telemetry: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated Telemetry %s/%s", ns, name)
+	}
+	return t, nil
+}
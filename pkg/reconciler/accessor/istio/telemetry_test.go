@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+	Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	istiov1alpha1 "istio.io/api/telemetry/v1alpha1"
+	"istio.io/client-go/pkg/apis/telemetry/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
+	faketelemetryinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/telemetry/v1alpha1/telemetry/fake"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/telemetry/v1alpha1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	originTelemetry = &v1alpha1.Telemetry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "telemetry",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: istiov1alpha1.Telemetry{
+			AccessLogging: []*istiov1alpha1.AccessLogging{{
+				Disabled: wrapperspb.Bool(false),
+			}},
+		},
+	}
+
+	desiredTelemetry = &v1alpha1.Telemetry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "telemetry",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{ownerRef},
+		},
+		Spec: istiov1alpha1.Telemetry{
+			AccessLogging: []*istiov1alpha1.AccessLogging{{
+				Disabled: wrapperspb.Bool(true),
+			}},
+		},
+	}
+
+	notOwnedTelemetry = &v1alpha1.Telemetry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "telemetry",
+			Namespace: "default",
+		},
+		Spec: istiov1alpha1.Telemetry{
+			AccessLogging: []*istiov1alpha1.AccessLogging{{
+				Disabled: wrapperspb.Bool(false),
+			}},
+		},
+	}
+)
+
+type FakeTelemetryAccessor struct {
+	client          istioclientset.Interface
+	telemetryLister istiolisters.TelemetryLister
+}
+
+func (f *FakeTelemetryAccessor) GetIstioClient() istioclientset.Interface {
+	return f.client
+}
+
+func (f *FakeTelemetryAccessor) GetTelemetryLister() istiolisters.TelemetryLister {
+	return f.telemetryLister
+}
+
+func TestReconcileTelemetry_Create(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	telemetryInformer := faketelemetryinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeTelemetryAccessor{
+		client:          istio,
+		telemetryLister: telemetryInformer.Lister(),
+	}
+
+	h := NewHooks()
+	h.OnCreate(&istio.Fake, "telemetries", func(obj runtime.Object) HookResult {
+		got := obj.(*v1alpha1.Telemetry)
+		if diff := cmp.Diff(got, desiredTelemetry, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected Telemetry (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileTelemetry(ctx, ownerObj, desiredTelemetry, accessor)
+
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile Telemetry:", err)
+	}
+}
+
+func TestReconcileTelemetry_Update(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	telemetryInformer := faketelemetryinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeTelemetryAccessor{
+		client:          istio,
+		telemetryLister: telemetryInformer.Lister(),
+	}
+
+	istio.TelemetryV1alpha1().Telemetries(origin.Namespace).Create(ctx, originTelemetry, metav1.CreateOptions{})
+	telemetryInformer.Informer().GetIndexer().Add(originTelemetry)
+
+	h := NewHooks()
+	h.OnUpdate(&istio.Fake, "telemetries", func(obj runtime.Object) HookResult {
+		got := obj.(*v1alpha1.Telemetry)
+		if diff := cmp.Diff(got, desiredTelemetry, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected Telemetry (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileTelemetry(ctx, ownerObj, desiredTelemetry, accessor)
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile Telemetry:", err)
+	}
+}
+
+func TestReconcileTelemetry_NotOwnedFailure(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	telemetryInformer := faketelemetryinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeTelemetryAccessor{
+		client:          istio,
+		telemetryLister: telemetryInformer.Lister(),
+	}
+
+	istio.TelemetryV1alpha1().Telemetries(origin.Namespace).Create(ctx, notOwnedTelemetry, metav1.CreateOptions{})
+	telemetryInformer.Informer().GetIndexer().Add(notOwnedTelemetry)
+
+	_, err = ReconcileTelemetry(ctx, ownerObj, desiredTelemetry, accessor)
+	if err == nil {
+		t.Error("Expected to get error when calling ReconcileTelemetry, but got no error.")
+	}
+	if !kaccessor.IsNotOwned(err) {
+		t.Error("Expected to get NotOwnedError but got", err)
+	}
+}
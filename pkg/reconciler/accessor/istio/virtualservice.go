@@ -41,9 +41,15 @@ type VirtualServiceAccessor interface {
 }
 
 func hasDesiredDiff(current, desired *v1beta1.VirtualService) bool {
-	return !cmp.Equal(current.Spec.DeepCopy(), desired.Spec.DeepCopy(), protocmp.Transform()) ||
-		!cmp.Equal(current.Labels, desired.Labels) ||
-		!cmp.Equal(current.Annotations, desired.Annotations)
+	if !cmp.Equal(current.Labels, desired.Labels) || !cmp.Equal(current.Annotations, desired.Annotations) {
+		return true
+	}
+	// A match of the spec hash means the spec was applied and not changed out
+	// of band since, so the expensive comparison can be skipped.
+	if kaccessor.SpecHashMatches(current, &current.Spec, desired) {
+		return false
+	}
+	return !cmp.Equal(current.Spec.DeepCopy(), desired.Spec.DeepCopy(), protocmp.Transform())
 }
 
 // ReconcileVirtualService reconciles VirtualService to the desired status.
@@ -157,6 +157,40 @@ func TestReconcileVirtualService_Update(t *testing.T) {
 	}
 }
 
+func TestReconcileVirtualService_SpecDrift(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+
+	hashed := desired.DeepCopy()
+	hashed.Annotations = kaccessor.WithSpecHash(nil, &hashed.Spec)
+	// Only the spec was changed out of band, the annotations still carry the
+	// hash of the applied spec.
+	drifted := hashed.DeepCopy()
+	drifted.Spec.Hosts = []string{"drifted.example.com"}
+
+	istioClient := fakeistioclient.Get(ctx)
+	accessor, waitInformers := setup(ctx, []*v1beta1.VirtualService{drifted}, istioClient, t)
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	h := NewHooks()
+	h.OnUpdate(&istioClient.Fake, "virtualservices", func(obj runtime.Object) HookResult {
+		got := obj.(*v1beta1.VirtualService)
+		if diff := cmp.Diff(got, hashed, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected VirtualService (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileVirtualService(ctx, ownerObj, hashed, accessor)
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to repair the VirtualService:", err)
+	}
+}
+
 func TestReconcileVirtualService_NotOwnedFailure(t *testing.T) {
 	ctx, _ := SetupFakeContext(t)
 	ctx, cancel := context.WithCancel(ctx)
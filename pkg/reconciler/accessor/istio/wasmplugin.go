@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+
+	"istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/extensions/v1alpha1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
+)
+
+// WasmPluginAccessor is an interface for accessing WasmPlugin.
+type WasmPluginAccessor interface {
+	GetIstioClient() istioclientset.Interface
+	GetWasmPluginLister() istiolisters.WasmPluginLister
+}
+
+// ReconcileWasmPlugin reconciles WasmPlugin to the desired status. The WasmPlugins are
+// generated next to the gateways, in another namespace than their owner, so the
+// ones of the owner are told apart by their labels matching owned.
+func ReconcileWasmPlugin(ctx context.Context, owner kmeta.Accessor, desired *v1alpha1.WasmPlugin,
+	owned labels.Selector, wpAccessor WasmPluginAccessor) (*v1alpha1.WasmPlugin, error) {
+
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		return nil, fmt.Errorf("recorder for reconciling WasmPlugin %s/%s is not created", desired.Namespace, desired.Name)
+	}
+	ns := desired.Namespace
+	name := desired.Name
+	wp, err := wpAccessor.GetWasmPluginLister().WasmPlugins(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		wp, err = wpAccessor.GetIstioClient().ExtensionsV1alpha1().WasmPlugins(ns).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CreationFailed",
+				"Failed to create WasmPlugin %s/%s: %v", ns, name, err)
+			return nil, fmt.Errorf("failed to create WasmPlugin: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Created", "Created WasmPlugin %s/%s", ns, name)
+	} else if err != nil {
+		return nil, err
+	} else if !owned.Matches(labels.Set(wp.Labels)) {
+		// Return an error with NotControlledBy information.
+		return nil, kaccessor.NewAccessorError(
+			fmt.Errorf("owner: %s with Type %T does not own WasmPlugin: %s/%s", owner.GetName(), owner, ns, name),
+			kaccessor.NotOwnResource)
+	} else if !kaccessor.SpecHashMatches(wp, &wp.Spec, desired) {
+		// Don't modify the informers copy
+		existing := wp.DeepCopy()
+		existing.Spec = *desired.Spec.DeepCopy()
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		wp, err = wpAccessor.GetIstioClient().ExtensionsV1alpha1().WasmPlugins(ns).Update(ctx, existing, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update WasmPlugin: %w", err)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "Updated", "Updated WasmPlugin %s/%s", ns, name)
+	}
+	return wp, nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1alpha1 "istio.io/api/extensions/v1alpha1"
+	"istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
+	fakewpinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/extensions/v1alpha1/wasmplugin/fake"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/extensions/v1alpha1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	originWasmPlugin = &v1alpha1.WasmPlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wp",
+			Namespace: "istio-system",
+			Labels:    gatewayResourceLabels,
+		},
+		Spec: istiov1alpha1.WasmPlugin{
+			Url: "oci://example.com/origin:v1",
+		},
+	}
+
+	desiredWasmPlugin = &v1alpha1.WasmPlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wp",
+			Namespace: "istio-system",
+			Labels:    gatewayResourceLabels,
+		},
+		Spec: istiov1alpha1.WasmPlugin{
+			Url: "oci://example.com/plugin:v2",
+		},
+	}
+
+	notOwnedWasmPlugin = &v1alpha1.WasmPlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wp",
+			Namespace: "istio-system",
+		},
+		Spec: istiov1alpha1.WasmPlugin{
+			Url: "oci://example.com/origin:v1",
+		},
+	}
+)
+
+type FakeWasmPluginAccessor struct {
+	client   istioclientset.Interface
+	wpLister istiolisters.WasmPluginLister
+}
+
+func (f *FakeWasmPluginAccessor) GetIstioClient() istioclientset.Interface {
+	return f.client
+}
+
+func (f *FakeWasmPluginAccessor) GetWasmPluginLister() istiolisters.WasmPluginLister {
+	return f.wpLister
+}
+
+func TestReconcileWasmPlugin_Create(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	wpInformer := fakewpinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeWasmPluginAccessor{
+		client:   istio,
+		wpLister: wpInformer.Lister(),
+	}
+
+	h := NewHooks()
+	h.OnCreate(&istio.Fake, "wasmplugins", func(obj runtime.Object) HookResult {
+		got := obj.(*v1alpha1.WasmPlugin)
+		if diff := cmp.Diff(got, desiredWasmPlugin, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected WasmPlugin (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileWasmPlugin(ctx, ownerObj, desiredWasmPlugin, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile WasmPlugin:", err)
+	}
+}
+
+func TestReconcileWasmPlugin_Update(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	wpInformer := fakewpinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeWasmPluginAccessor{
+		client:   istio,
+		wpLister: wpInformer.Lister(),
+	}
+
+	istio.ExtensionsV1alpha1().WasmPlugins(originWasmPlugin.Namespace).Create(ctx, originWasmPlugin, metav1.CreateOptions{})
+	wpInformer.Informer().GetIndexer().Add(originWasmPlugin)
+
+	h := NewHooks()
+	h.OnUpdate(&istio.Fake, "wasmplugins", func(obj runtime.Object) HookResult {
+		got := obj.(*v1alpha1.WasmPlugin)
+		if diff := cmp.Diff(got, desiredWasmPlugin, protocmp.Transform()); diff != "" {
+			t.Log("Unexpected WasmPlugin (-want, +got):", diff)
+			return HookIncomplete
+		}
+		return HookComplete
+	})
+
+	ReconcileWasmPlugin(ctx, ownerObj, desiredWasmPlugin, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+	if err := h.WaitForHooks(3 * time.Second); err != nil {
+		t.Error("Failed to Reconcile WasmPlugin:", err)
+	}
+}
+
+func TestReconcileWasmPlugin_NotOwnedFailure(t *testing.T) {
+	ctx, cancel, informers := SetupFakeContextWithCancel(t)
+
+	istio := fakeistioclient.Get(ctx)
+	wpInformer := fakewpinformer.Get(ctx)
+
+	waitInformers, err := RunAndSyncInformers(ctx, informers...)
+	if err != nil {
+		t.Fatal("Failed to start informers")
+	}
+	defer func() {
+		cancel()
+		waitInformers()
+	}()
+
+	accessor := &FakeWasmPluginAccessor{
+		client:   istio,
+		wpLister: wpInformer.Lister(),
+	}
+
+	istio.ExtensionsV1alpha1().WasmPlugins(originWasmPlugin.Namespace).Create(ctx, notOwnedWasmPlugin, metav1.CreateOptions{})
+	wpInformer.Informer().GetIndexer().Add(notOwnedWasmPlugin)
+
+	_, err = ReconcileWasmPlugin(ctx, ownerObj, desiredWasmPlugin, labels.SelectorFromSet(gatewayResourceLabels), accessor)
+	if err == nil {
+		t.Error("Expected to get error when calling ReconcileWasmPlugin, but got no error.")
+	}
+	if !kaccessor.IsNotOwned(err) {
+		t.Error("Expected to get NotOwnedError but got", err)
+	}
+}
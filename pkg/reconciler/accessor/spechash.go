@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"google.golang.org/protobuf/proto"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SpecHashAnnotationKey is the annotation carrying the hash of the spec
+// that was last applied to a generated resource.
+const SpecHashAnnotationKey = "networking.knative.dev/spec-hash"
+
+// SpecHash returns a stable hash of the given spec. An empty string is
+// returned if the spec cannot be serialized.
+func SpecHash(spec proto.Message) string {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// WithSpecHash returns a copy of annotations with the spec hash set.
+func WithSpecHash(annotations map[string]string, spec proto.Message) map[string]string {
+	h := SpecHash(spec)
+	if h == "" {
+		return annotations
+	}
+	ret := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		ret[k] = v
+	}
+	ret[SpecHashAnnotationKey] = h
+	return ret
+}
+
+// SpecHashMatches reports whether the desired object carries a spec hash, the
+// current object was last applied with the same one and the current spec still
+// hashes to it, i.e. it was not changed out of band since. When it does, the
+// specs are equal and the current object doesn't need to be rewritten.
+func SpecHashMatches(current metav1.Object, currentSpec proto.Message, desired metav1.Object) bool {
+	h := desired.GetAnnotations()[SpecHashAnnotationKey]
+	return h != "" && current.GetAnnotations()[SpecHashAnnotationKey] == h && SpecHash(currentSpec) == h
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accessor
+
+import (
+	"testing"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSpecHash(t *testing.T) {
+	spec := &istiov1beta1.VirtualService{Hosts: []string{"foo.example.com"}, Gateways: []string{"gw"}}
+	same := &istiov1beta1.VirtualService{Hosts: []string{"foo.example.com"}, Gateways: []string{"gw"}}
+	other := &istiov1beta1.VirtualService{Hosts: []string{"bar.example.com"}, Gateways: []string{"gw"}}
+
+	if got := SpecHash(spec); got == "" {
+		t.Fatal("SpecHash() returned an empty hash")
+	}
+	if SpecHash(spec) != SpecHash(same) {
+		t.Error("SpecHash() differs for equal specs")
+	}
+	if SpecHash(spec) == SpecHash(other) {
+		t.Error("SpecHash() is equal for different specs")
+	}
+}
+
+func TestWithSpecHash(t *testing.T) {
+	spec := &istiov1beta1.VirtualService{Hosts: []string{"foo.example.com"}}
+	annotations := map[string]string{"foo": "bar"}
+
+	got := WithSpecHash(annotations, spec)
+	if got[SpecHashAnnotationKey] != SpecHash(spec) {
+		t.Errorf("%s = %q, want = %q", SpecHashAnnotationKey, got[SpecHashAnnotationKey], SpecHash(spec))
+	}
+	if got["foo"] != "bar" {
+		t.Error("WithSpecHash() dropped existing annotations")
+	}
+	if _, ok := annotations[SpecHashAnnotationKey]; ok {
+		t.Error("WithSpecHash() modified the input annotations")
+	}
+}
+
+func TestSpecHashMatches(t *testing.T) {
+	applied := &istiov1beta1.VirtualService{Hosts: []string{"foo.example.com"}}
+	drifted := &istiov1beta1.VirtualService{Hosts: []string{"bar.example.com"}}
+	withHash := func(h string) *metav1.ObjectMeta {
+		if h == "" {
+			return &metav1.ObjectMeta{}
+		}
+		return &metav1.ObjectMeta{Annotations: map[string]string{SpecHashAnnotationKey: h}}
+	}
+
+	cases := []struct {
+		name        string
+		current     string
+		currentSpec *istiov1beta1.VirtualService
+		desired     string
+		want        bool
+	}{{
+		name:        "same hash",
+		current:     SpecHash(applied),
+		currentSpec: applied,
+		desired:     SpecHash(applied),
+		want:        true,
+	}, {
+		name:        "different hash",
+		current:     SpecHash(drifted),
+		currentSpec: drifted,
+		desired:     SpecHash(applied),
+	}, {
+		name:        "spec changed out of band",
+		current:     SpecHash(applied),
+		currentSpec: drifted,
+		desired:     SpecHash(applied),
+	}, {
+		name:        "current without hash",
+		currentSpec: applied,
+		desired:     SpecHash(applied),
+	}, {
+		name:        "desired without hash",
+		current:     SpecHash(applied),
+		currentSpec: applied,
+	}, {
+		name:        "neither has a hash",
+		currentSpec: applied,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SpecHashMatches(withHash(tc.current), tc.currentSpec, withHash(tc.desired)); got != tc.want {
+				t.Errorf("SpecHashMatches() = %v, want = %v", got, tc.want)
+			}
+		})
+	}
+}
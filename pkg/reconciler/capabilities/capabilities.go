@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package capabilities detects which of the optional Istio APIs are served by the
+// cluster, so that the features relying on them can be gated accordingly. The APIs
+// are detected when the controller starts, so it must be restarted to pick up the
+// ones installed afterwards.
+package capabilities
+
+import (
+	"fmt"
+	"sync"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+)
+
+// The optional Istio APIs features are gated on.
+var (
+	EnvoyFilter           = schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "envoyfilters"}
+	RequestAuthentication = schema.GroupVersionResource{Group: "security.istio.io", Version: "v1beta1", Resource: "requestauthentications"}
+	Telemetry             = schema.GroupVersionResource{Group: "telemetry.istio.io", Version: "v1alpha1", Resource: "telemetries"}
+	WasmPlugin            = schema.GroupVersionResource{Group: "extensions.istio.io", Version: "v1alpha1", Resource: "wasmplugins"}
+)
+
+var optionalAPIs = []schema.GroupVersionResource{EnvoyFilter, RequestAuthentication, Telemetry, WasmPlugin}
+
+// Detector detects which of the optional Istio APIs are served.
+type Detector struct {
+	discovery discovery.DiscoveryInterface
+
+	mu        sync.RWMutex
+	supported sets.Set[schema.GroupVersionResource]
+}
+
+// NewDetector creates a Detector using the given discovery client.
+func NewDetector(discovery discovery.DiscoveryInterface) *Detector {
+	return &Detector{
+		discovery: discovery,
+		supported: sets.New[schema.GroupVersionResource](),
+	}
+}
+
+// Refresh detects the served APIs.
+func (d *Detector) Refresh() error {
+	supported := sets.New[schema.GroupVersionResource]()
+	for _, gvr := range optionalAPIs {
+		resources, err := d.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to discover %s: %w", gvr.GroupVersion(), err)
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == gvr.Resource {
+				supported.Insert(gvr)
+			}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.supported = supported
+	return nil
+}
+
+// Supports returns whether the given API was served when last detected.
+func (d *Detector) Supports(gvr schema.GroupVersionResource) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.supported.Has(gvr)
+}
+
+// Supported returns the optional APIs that were served when last detected.
+func (d *Detector) Supported() sets.Set[schema.GroupVersionResource] {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.supported.Clone()
+}
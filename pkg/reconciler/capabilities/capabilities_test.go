@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capabilities
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+func TestDetector(t *testing.T) {
+	fake := &fakediscovery.FakeDiscovery{Fake: &clientgotesting.Fake{}}
+	fake.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "networking.istio.io/v1alpha3",
+		APIResources: []metav1.APIResource{{Name: "envoyfilters"}, {Name: "gateways"}},
+	}, {
+		// The group version is served, but without the resource.
+		GroupVersion: "security.istio.io/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "authorizationpolicies"}},
+	}, {
+		GroupVersion: "telemetry.istio.io/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "telemetries"}},
+	}}
+
+	d := NewDetector(fake)
+	if d.Supports(EnvoyFilter) {
+		t.Error("Supports(EnvoyFilter) = true before detection")
+	}
+	if err := d.Refresh(); err != nil {
+		t.Fatal("Refresh() =", err)
+	}
+
+	want := sets.New(EnvoyFilter, Telemetry)
+	for _, gvr := range optionalAPIs {
+		if got := d.Supports(gvr); got != want.Has(gvr) {
+			t.Errorf("Supports(%v) = %v, want %v", gvr, got, want.Has(gvr))
+		}
+	}
+	if got := d.Supported(); !got.Equal(want) {
+		t.Errorf("Supported() = %v, want %v", got, want)
+	}
+}
+
+type failingDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (failingDiscovery) ServerResourcesForGroupVersion(string) (*metav1.APIResourceList, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestDetectorError(t *testing.T) {
+	d := NewDetector(failingDiscovery{&fakediscovery.FakeDiscovery{Fake: &clientgotesting.Fake{}}})
+	d.supported = sets.New(Telemetry)
+	if err := d.Refresh(); err == nil {
+		t.Error("Refresh() = nil, wanted an error")
+	}
+	// The previous detection is kept.
+	if !d.Supports(Telemetry) {
+		t.Error("Supports(Telemetry) = false after a failed detection")
+	}
+}
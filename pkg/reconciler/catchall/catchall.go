@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catchall
+
+import (
+	"context"
+	"fmt"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/catchall/resources"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// reconciler maintains the catch-all VirtualService of a single namespace.
+type reconciler struct {
+	istioClientSet istioclientset.Interface
+
+	virtualServiceLister istiolisters.VirtualServiceLister
+
+	configStore pkgreconciler.ConfigStore
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+
+// Reconcile converges the catch-all VirtualService of the namespace in the key to the
+// configured response.
+func (r *reconciler) Reconcile(ctx context.Context, key string) error {
+	ctx = r.configStore.ToContext(ctx)
+	logger := logging.FromContext(ctx)
+
+	// The keys are namespaces, which are cluster scoped.
+	_, namespace, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Errorw("Invalid resource key", key)
+		return nil
+	}
+
+	vs, err := r.virtualServiceLister.VirtualServices(namespace).Get(resources.VirtualServiceName)
+	if apierrs.IsNotFound(err) {
+		vs = nil
+	} else if err != nil {
+		return err
+	}
+	if vs != nil && vs.Labels[resources.ManagedLabelKey] != "true" {
+		// Never take over VirtualServices created by users.
+		logger.Warnf("VirtualService %s/%s is not managed by net-istio, skipping", namespace, vs.Name)
+		return nil
+	}
+
+	desired := desiredVirtualService(config.FromContext(ctx).Istio, namespace)
+	switch {
+	case desired == nil && vs == nil:
+		return nil
+	case desired == nil:
+		if err := r.istioClientSet.NetworkingV1beta1().VirtualServices(namespace).Delete(ctx, vs.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete VirtualService: %w", err)
+		}
+		return nil
+	case vs == nil:
+		if _, err := r.istioClientSet.NetworkingV1beta1().VirtualServices(namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create VirtualService: %w", err)
+		}
+		return nil
+	}
+
+	if kaccessor.SpecHashMatches(vs, &vs.Spec, desired) {
+		return nil
+	}
+	// Don't modify the informers copy
+	existing := vs.DeepCopy()
+	existing.Spec = *desired.Spec.DeepCopy()
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	if _, err := r.istioClientSet.NetworkingV1beta1().VirtualServices(namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update VirtualService: %w", err)
+	}
+	return nil
+}
+
+// desiredVirtualService returns the catch-all VirtualService of the configured Gateways of
+// the namespace, or nil if none is configured.
+func desiredVirtualService(istio *config.Istio, namespace string) *v1beta1.VirtualService {
+	if istio.CatchAllStatus == 0 {
+		return nil
+	}
+	gateways := sets.New[string]()
+	for _, gw := range append(istio.IngressGateways, istio.LocalGateways...) {
+		if gw.Namespace == namespace {
+			gateways.Insert(gw.QualifiedName())
+		}
+	}
+	if gateways.Len() == 0 {
+		return nil
+	}
+	return resources.MakeVirtualService(namespace, sets.List(gateways), istio.CatchAllStatus, istio.CatchAllBody)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catchall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	// Inject our fakes
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/net-istio/pkg/reconciler/catchall/resources"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	defaultCmpOpts = []cmp.Option{protocmp.Transform()}
+
+	gateways = []string{
+		"knative-serving/" + config.KnativeIngressGateway,
+		"knative-serving/" + config.KnativeLocalGateway,
+	}
+)
+
+type cfgKey struct{}
+
+func withConfig(status int, body string) context.Context {
+	cfg := &config.Config{
+		Istio: &config.Istio{
+			IngressGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeIngressGateway,
+				ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+			}},
+			LocalGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeLocalGateway,
+				ServiceURL: "knative-local-gateway.istio-system.svc.cluster.local",
+			}},
+			CatchAllStatus: status,
+			CatchAllBody:   body,
+		},
+		Network: &netconfig.Config{},
+	}
+	return context.WithValue(context.Background(), cfgKey{}, cfg)
+}
+
+func unmanaged(vs *v1beta1.VirtualService) *v1beta1.VirtualService {
+	vs.Labels = nil
+	return vs
+}
+
+func deleteAction(namespace string) clientgotesting.DeleteActionImpl {
+	return clientgotesting.DeleteActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Namespace: namespace,
+			Verb:      "delete",
+			Resource:  v1beta1.SchemeGroupVersion.WithResource("virtualservices"),
+		},
+		Name: resources.VirtualServiceName,
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	table := TableTest{{
+		Name: "disabled",
+		Key:  "knative-serving",
+		Ctx:  withConfig(0, ""),
+	}, {
+		Name: "disabled removes the VirtualService",
+		Key:  "knative-serving",
+		Ctx:  withConfig(0, ""),
+		Objects: []runtime.Object{
+			resources.MakeVirtualService("knative-serving", gateways, 404, ""),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteAction("knative-serving")},
+	}, {
+		Name: "create for the gateways",
+		Key:  "knative-serving",
+		Ctx:  withConfig(418, "I'm a teapot"),
+		WantCreates: []runtime.Object{
+			resources.MakeVirtualService("knative-serving", gateways, 418, "I'm a teapot"),
+		},
+	}, {
+		Name: "update the response",
+		Key:  "knative-serving",
+		Ctx:  withConfig(418, "I'm a teapot"),
+		Objects: []runtime.Object{
+			resources.MakeVirtualService("knative-serving", gateways, 404, ""),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: resources.MakeVirtualService("knative-serving", gateways, 418, "I'm a teapot"),
+		}},
+	}, {
+		Name: "up to date",
+		Key:  "knative-serving",
+		Ctx:  withConfig(404, ""),
+		Objects: []runtime.Object{
+			resources.MakeVirtualService("knative-serving", gateways, 404, ""),
+		},
+	}, {
+		Name: "do not take over user VirtualServices",
+		Key:  "knative-serving",
+		Ctx:  withConfig(404, ""),
+		Objects: []runtime.Object{
+			unmanaged(resources.MakeVirtualService("knative-serving", gateways, 503, "")),
+		},
+	}, {
+		Name: "remove from namespaces without gateways",
+		Key:  "old-gateways",
+		Ctx:  withConfig(404, ""),
+		Objects: []runtime.Object{
+			resources.MakeVirtualService("old-gateways", []string{"old-gateways/gateway"}, 404, ""),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteAction("old-gateways")},
+	}}
+
+	for i := range table {
+		// The keys are namespaces, which the table test cannot validate against.
+		table[i].SkipNamespaceValidation = true
+		table[i].CmpOpts = defaultCmpOpts
+	}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &reconciler{
+			istioClientSet:       istioclient.Get(ctx),
+			virtualServiceLister: listers.GetVirtualServiceLister(),
+			configStore: &testConfigStore{
+				config: ctx.Value(cfgKey{}).(*config.Config),
+			},
+		}
+	}))
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
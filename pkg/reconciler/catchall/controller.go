@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catchall
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	virtualserviceinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/virtualservice"
+	"knative.dev/net-istio/pkg/reconciler/catchall/resources"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// NewController initializes the controller maintaining the catch-all VirtualServices of
+// the gateways. The workqueue is keyed by the namespace of the Gateways.
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+
+	logger := logging.FromContext(ctx)
+	virtualServiceInformer := virtualserviceinformer.Get(ctx)
+
+	r := &reconciler{
+		istioClientSet:       istioclient.Get(ctx),
+		virtualServiceLister: virtualServiceInformer.Lister(),
+	}
+	impl := controller.NewContext(ctx, r, controller.ControllerOptions{
+		WorkQueueName: "CatchAllVirtualServices",
+		Logger:        logger,
+	})
+
+	configStore := config.NewStore(logger.Named("config-store"), func(_ string, value interface{}) {
+		// The managed VirtualServices cover the namespaces whose VirtualService needs to
+		// be removed, e.g. as the Gateways moved.
+		vses, _ := virtualServiceInformer.Lister().List(labels.SelectorFromSet(labels.Set{resources.ManagedLabelKey: "true"}))
+		for _, vs := range vses {
+			impl.EnqueueNamespaceOf(vs)
+		}
+		if istio, ok := value.(*config.Istio); ok {
+			for _, gw := range append(istio.IngressGateways, istio.LocalGateways...) {
+				impl.EnqueueKey(types.NamespacedName{Name: gw.Namespace})
+			}
+		}
+	})
+	configStore.WatchConfigs(cmw)
+	r.configStore = configStore
+
+	virtualServiceInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.LabelFilterFunc(resources.ManagedLabelKey, "true", false),
+		Handler:    controller.HandleAll(impl.EnqueueNamespaceOf),
+	})
+
+	return impl
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking"
+)
+
+const (
+	// ManagedLabelKey is the label attached to the catch-all VirtualServices maintained
+	// by net-istio. VirtualServices without it are never modified.
+	ManagedLabelKey = networking.GroupName + "/catch-all"
+
+	// VirtualServiceName is the name of the catch-all VirtualService maintained in the
+	// namespaces of the Gateways.
+	VirtualServiceName = "knative-catch-all"
+)
+
+// MakeVirtualService creates a VirtualService responding with the given status and body
+// to the requests received by the given Gateways of the namespace whose host no other
+// VirtualService matches, as Istio prefers the most specific host.
+func MakeVirtualService(namespace string, gateways []string, status int, body string) *v1beta1.VirtualService {
+	response := &istiov1beta1.HTTPDirectResponse{
+		Status: uint32(status),
+	}
+	if body != "" {
+		response.Body = &istiov1beta1.HTTPBody{
+			Specifier: &istiov1beta1.HTTPBody_String_{String_: body},
+		}
+	}
+	vs := &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      VirtualServiceName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedLabelKey: "true",
+			},
+		},
+		Spec: istiov1beta1.VirtualService{
+			Hosts:    []string{"*"},
+			Gateways: gateways,
+			Http: []*istiov1beta1.HTTPRoute{{
+				Name:           "catch-all",
+				DirectResponse: response,
+			}},
+		},
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+	return vs
+}
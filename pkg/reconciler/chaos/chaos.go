@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package chaos injects faults into the requests of the controller to the Istio APIs, so
+// that the e2e tests can verify how the reconcilers retry, back off and report their
+// conditions when the API server conflicts, throttles or times out.
+//
+// The faults are only injected by the controllers built with the "chaos" build tag, and
+// configured through ISTIO_API_FAULTS, e.g. "conflict=0.1,throttle=0.05,timeout=0.05" to
+// fail 10% of the updates with conflicts and 5% of all the requests with each of 429 and
+// 504 responses.
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	"knative.dev/net-istio/pkg/reconciler/observer"
+	"knative.dev/pkg/injection"
+)
+
+// FaultsEnv configures the faults injected into the requests to the Istio APIs.
+const FaultsEnv = "ISTIO_API_FAULTS"
+
+// istioPathPrefixes are the prefixes of the paths of the Istio APIs.
+var istioPathPrefixes = []string{
+	"/apis/networking.istio.io/",
+	"/apis/security.istio.io/",
+	"/apis/telemetry.istio.io/",
+	"/apis/extensions.istio.io/",
+}
+
+// Faults are the probabilities of the requests to the Istio APIs to fail.
+type Faults struct {
+	// Conflict is the probability of the updates and patches to fail with a 409 Conflict.
+	Conflict float64
+	// Throttle is the probability of the requests to fail with a 429 Too Many Requests.
+	Throttle float64
+	// Timeout is the probability of the requests to fail with a 504 Gateway Timeout.
+	Timeout float64
+}
+
+// ParseFaults parses faults formatted as comma-separated kind=probability pairs.
+func ParseFaults(s string) (Faults, error) {
+	var f Faults
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return Faults{}, fmt.Errorf("fault %q must be formatted as kind=probability", pair)
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return Faults{}, fmt.Errorf("failed to parse the probability of %q: %w", pair, err)
+		}
+		if p < 0 || p > 1 {
+			return Faults{}, fmt.Errorf("the probability of %q must be between 0 and 1, was: %v", pair, p)
+		}
+		switch strings.TrimSpace(kind) {
+		case "conflict":
+			f.Conflict = p
+		case "throttle":
+			f.Throttle = p
+		case "timeout":
+			f.Timeout = p
+		default:
+			return Faults{}, fmt.Errorf("unknown fault %q, must be one of conflict, throttle or timeout", kind)
+		}
+	}
+	if sum := f.Conflict + f.Throttle + f.Timeout; sum > 1 {
+		return Faults{}, fmt.Errorf("the probabilities of the faults must not add up to more than 1, was: %v", sum)
+	}
+	return f, nil
+}
+
+// RegisterClients makes the injected Istio client fail its requests as configured through
+// ISTIO_API_FAULTS. It fails when the faults are configured but the controller was not
+// built with the "chaos" build tag.
+//
+// This must be called after observer.RegisterClients, and before the injected clients are
+// set up.
+func RegisterClients() error {
+	val := os.Getenv(FaultsEnv)
+	if val == "" {
+		return nil
+	}
+	if !buildEnabled {
+		return fmt.Errorf("%s requires a controller built with the chaos build tag", FaultsEnv)
+	}
+	faults, err := ParseFaults(val)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", FaultsEnv, err)
+	}
+
+	// Clients are set up in registration order, so this replaces the Istio client.
+	injection.Default.RegisterClient(func(ctx context.Context, cfg *rest.Config) context.Context {
+		cfg = faults.WrapConfig(observer.WrapConfig(cfg))
+		return context.WithValue(ctx, istioclient.Key{}, istioclientset.NewForConfigOrDie(cfg))
+	})
+	return nil
+}
+
+// WrapConfig returns a copy of the config failing its requests to the Istio APIs.
+func (f Faults) WrapConfig(cfg *rest.Config) *rest.Config {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &faultTransport{next: rt, faults: f, rand: rand.Float64}
+	})
+	return cfg
+}
+
+// faultTransport fails the requests to the Istio APIs at random.
+type faultTransport struct {
+	next   http.RoundTripper
+	faults Faults
+	rand   func() float64
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIstioAPI(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	r := t.rand()
+	var status *apierrs.StatusError
+	switch {
+	case r < t.faults.Conflict:
+		if req.Method == http.MethodPut || req.Method == http.MethodPatch {
+			status = apierrs.NewConflict(resourceOf(req), "", errors.New("injected by the chaos build of the controller"))
+		}
+	case r < t.faults.Conflict+t.faults.Throttle:
+		status = apierrs.NewTooManyRequests("injected by the chaos build of the controller", 1)
+	case r < t.faults.Conflict+t.faults.Throttle+t.faults.Timeout:
+		status = apierrs.NewTimeoutError("injected by the chaos build of the controller", 1)
+	}
+	if status == nil {
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return statusResponse(req, status)
+}
+
+// statusResponse returns the response of the API server failing the request with the status.
+func statusResponse(req *http.Request, status *apierrs.StatusError) (*http.Response, error) {
+	s := status.Status()
+	s.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Status"}
+	body, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if s.Details != nil && s.Details.RetryAfterSeconds > 0 {
+		header.Set("Retry-After", strconv.Itoa(int(s.Details.RetryAfterSeconds)))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.Code, http.StatusText(int(s.Code))),
+		StatusCode:    int(s.Code),
+		Proto:         req.Proto,
+		ProtoMajor:    req.ProtoMajor,
+		ProtoMinor:    req.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func isIstioAPI(req *http.Request) bool {
+	for _, prefix := range istioPathPrefixes {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceOf returns the group and resource of a request to
+// /apis/<group>/<version>/[namespaces/<namespace>/]<resource>/...
+func resourceOf(req *http.Request) schema.GroupResource {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/apis/"), "/")
+	if len(parts) < 3 {
+		return schema.GroupResource{}
+	}
+	gr := schema.GroupResource{Group: parts[0], Resource: parts[2]}
+	if parts[2] == "namespaces" && len(parts) >= 5 {
+		gr.Resource = parts[4]
+	}
+	return gr
+}
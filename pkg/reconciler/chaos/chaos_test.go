@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+)
+
+func TestParseFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Faults
+		wantErr bool
+	}{{
+		name: "empty",
+	}, {
+		name:  "all",
+		value: "conflict=0.1, throttle=0.05,timeout=0.2",
+		want:  Faults{Conflict: 0.1, Throttle: 0.05, Timeout: 0.2},
+	}, {
+		name:  "one",
+		value: "throttle=1",
+		want:  Faults{Throttle: 1},
+	}, {
+		name:    "unknown kind",
+		value:   "crash=0.1",
+		wantErr: true,
+	}, {
+		name:    "missing probability",
+		value:   "conflict",
+		wantErr: true,
+	}, {
+		name:    "invalid probability",
+		value:   "conflict=often",
+		wantErr: true,
+	}, {
+		name:    "probability above 1",
+		value:   "timeout=1.5",
+		wantErr: true,
+	}, {
+		name:    "negative probability",
+		value:   "timeout=-0.1",
+		wantErr: true,
+	}, {
+		name:    "probabilities adding up to more than 1",
+		value:   "conflict=0.5,throttle=0.3,timeout=0.3",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFaults(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFaults() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Error("ParseFaults() (-want, +got):", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestRegisterClientsRequiresBuildTag(t *testing.T) {
+	t.Setenv(FaultsEnv, "conflict=0.1")
+	if err := RegisterClients(); (err != nil) == buildEnabled {
+		t.Errorf("RegisterClients() = %v, with the chaos build tag: %v", err, buildEnabled)
+	}
+}
+
+func TestFaultTransport(t *testing.T) {
+	faults := Faults{Conflict: 0.1, Throttle: 0.1, Timeout: 0.1}
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		rand     float64
+		wantCode int
+	}{{
+		name:     "conflict on update",
+		method:   http.MethodPut,
+		path:     "/apis/networking.istio.io/v1beta1/namespaces/ns/virtualservices/vs",
+		rand:     0.05,
+		wantCode: http.StatusConflict,
+	}, {
+		name:     "no conflict on get",
+		method:   http.MethodGet,
+		path:     "/apis/networking.istio.io/v1beta1/namespaces/ns/virtualservices/vs",
+		rand:     0.05,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "throttle",
+		method:   http.MethodGet,
+		path:     "/apis/security.istio.io/v1beta1/authorizationpolicies",
+		rand:     0.15,
+		wantCode: http.StatusTooManyRequests,
+	}, {
+		name:     "timeout",
+		method:   http.MethodPost,
+		path:     "/apis/networking.istio.io/v1beta1/namespaces/ns/gateways",
+		rand:     0.25,
+		wantCode: http.StatusGatewayTimeout,
+	}, {
+		name:     "no fault",
+		method:   http.MethodPut,
+		path:     "/apis/networking.istio.io/v1beta1/namespaces/ns/gateways/gw",
+		rand:     0.35,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "not an Istio API",
+		method:   http.MethodPut,
+		path:     "/apis/networking.internal.knative.dev/v1alpha1/namespaces/ns/ingresses/ing",
+		rand:     0.05,
+		wantCode: http.StatusOK,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &faultTransport{
+				next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK, Request: req}, nil
+				}),
+				faults: faults,
+				rand:   func() float64 { return tt.rand },
+			}
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal("RoundTrip() =", err)
+			}
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("StatusCode = %d, want: %d", resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFaultsWrapConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unexpected request to the API server:", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	cfg := Faults{Conflict: 1}.WrapConfig(&rest.Config{Host: server.URL})
+	client := istioclientset.NewForConfigOrDie(cfg)
+	_, err := client.NetworkingV1beta1().VirtualServices("ns").Update(context.Background(), &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs"},
+	}, metav1.UpdateOptions{})
+	if !apierrs.IsConflict(err) {
+		t.Error("Update() =", err, ", want a conflict")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
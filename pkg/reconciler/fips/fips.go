@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fips implements the FIPS-compatible mode of net-istio, in which the
+// checksums embedded in the names of the generated resources only rely on
+// FIPS-approved algorithms.
+//
+// The mode is enabled by building with the "fips" build tag, or at runtime
+// through ENABLE_FIPS_MODE. Enabling it changes the names of some generated
+// resources, which the reconcilers migrate from the legacy naming scheme, and
+// restricts the TLS connections of the status prober to the FIPS-approved
+// versions, cipher suites and curves.
+//
+// The mode does not cover the names derived through kmeta.ChildName, which
+// embed an MD5 digest when the name of the Ingress and their suffix are longer
+// than 63 characters. The digest is only used as a checksum and not for any
+// security purpose. These are the names of:
+//   - the VirtualServices, AuthorizationPolicies and Telemetries of the Ingresses,
+//   - the auto-passthrough Gateways,
+//   - the EnvoyFilters of the rate limits,
+//   - the RequestAuthentications and AuthorizationPolicies generated next to the
+//     gateways for the JWTs, the IP access rules and the external authorization.
+package fips
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"hash/adler32"
+	"os"
+	"strconv"
+)
+
+// EnableFIPSModeEnv enables the FIPS-compatible mode at runtime.
+const EnableFIPSModeEnv = "ENABLE_FIPS_MODE"
+
+// Enabled returns whether the FIPS-compatible mode is enabled, either at build
+// time or through ENABLE_FIPS_MODE. An invalid ENABLE_FIPS_MODE is rejected at
+// startup by Validate.
+func Enabled() bool {
+	enabled, _ := enabledFromEnv()
+	return buildEnabled || enabled
+}
+
+// Validate returns an error if ENABLE_FIPS_MODE is set to an invalid value.
+func Validate() error {
+	_, err := enabledFromEnv()
+	return err
+}
+
+func enabledFromEnv() (bool, error) {
+	enable := os.Getenv(EnableFIPSModeEnv)
+	if enable == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(enable)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s=%q: %w", EnableFIPSModeEnv, enable, err)
+	}
+	return b, nil
+}
+
+// TLSConfig returns the base configuration of the TLS connections opened by
+// net-istio. In FIPS mode, it restricts them to TLS 1.2, whose cipher suites can
+// be configured, with the FIPS-approved ECDHE and AES-GCM cipher suites and the
+// P-256 and P-384 curves.
+func TLSConfig() *tls.Config {
+	if !Enabled() {
+		return &tls.Config{}
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		MaxVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		},
+		CurvePreferences: []tls.CurveID{tls.CurveP256, tls.CurveP384},
+	}
+}
+
+// Checksum returns the 32 bit checksum of the data embedded in the names of the
+// generated resources: the first four bytes of its SHA-256 digest in FIPS mode,
+// and its Adler-32 checksum otherwise.
+func Checksum(data []byte) uint32 {
+	if !Enabled() {
+		return LegacyChecksum(data)
+	}
+	sum := sha256.Sum256(data)
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+// LegacyChecksum returns the Adler-32 checksum of the data, which the names of
+// resources generated outside of FIPS mode embed. It is only meant to find those
+// resources when migrating them.
+func LegacyChecksum(data []byte) uint32 {
+	return adler32.Checksum(data)
+}
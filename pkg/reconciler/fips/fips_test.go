@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/tls"
+	"hash/adler32"
+	"slices"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want bool
+	}{{
+		name: "unset",
+		want: buildEnabled,
+	}, {
+		name: "enabled",
+		env:  "true",
+		want: true,
+	}, {
+		name: "disabled",
+		env:  "false",
+		want: buildEnabled,
+	}, {
+		name: "invalid",
+		env:  "yes please",
+		want: buildEnabled,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnableFIPSModeEnv, tt.env)
+			if got := Enabled(); got != tt.want {
+				t.Errorf("Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name: "enabled",
+		env:  "true",
+	}, {
+		name: "disabled",
+		env:  "0",
+	}, {
+		name:    "invalid",
+		env:     "yes please",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnableFIPSModeEnv, tt.env)
+			if err := Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Setenv(EnableFIPSModeEnv, "false")
+	if got := TLSConfig(); !buildEnabled && (got.MinVersion != 0 || got.CipherSuites != nil || got.CurvePreferences != nil) {
+		t.Errorf("TLSConfig() = %+v outside of FIPS mode, want the Go defaults", got)
+	}
+
+	t.Setenv(EnableFIPSModeEnv, "true")
+	got := TLSConfig()
+	if got.MinVersion != tls.VersionTLS12 || got.MaxVersion != tls.VersionTLS12 {
+		t.Errorf("TLSConfig() versions = [%x, %x], want TLS 1.2", got.MinVersion, got.MaxVersion)
+	}
+	for _, id := range got.CipherSuites {
+		suite := cipherSuite(id)
+		if suite == nil || suite.Insecure || !slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			t.Errorf("TLSConfig() cipher suite = %s, want a secure TLS 1.2 cipher suite", tls.CipherSuiteName(id))
+		}
+	}
+	if len(got.CipherSuites) == 0 {
+		t.Error("TLSConfig() cipher suites = the Go defaults in FIPS mode")
+	}
+	for _, curve := range got.CurvePreferences {
+		if curve != tls.CurveP256 && curve != tls.CurveP384 {
+			t.Errorf("TLSConfig() curve = %v, want P-256 or P-384", curve)
+		}
+	}
+	if len(got.CurvePreferences) == 0 {
+		t.Error("TLSConfig() curves = the Go defaults in FIPS mode")
+	}
+}
+
+func cipherSuite(id uint16) *tls.CipherSuite {
+	for _, suite := range tls.CipherSuites() {
+		if suite.ID == id {
+			return suite
+		}
+	}
+	return nil
+}
+
+func TestChecksum(t *testing.T) {
+	data := []byte("istio-system/istio-ingressgateway")
+
+	t.Setenv(EnableFIPSModeEnv, "false")
+	if !buildEnabled && Checksum(data) != adler32.Checksum(data) {
+		t.Errorf("Checksum() = %d, want the Adler-32 checksum %d", Checksum(data), adler32.Checksum(data))
+	}
+	if LegacyChecksum(data) != adler32.Checksum(data) {
+		t.Errorf("LegacyChecksum() = %d, want %d", LegacyChecksum(data), adler32.Checksum(data))
+	}
+
+	t.Setenv(EnableFIPSModeEnv, "true")
+	got := Checksum(data)
+	if got == adler32.Checksum(data) {
+		t.Error("Checksum() = the Adler-32 checksum in FIPS mode")
+	}
+	if again := Checksum(data); again != got {
+		t.Errorf("Checksum() = %d, then %d", got, again)
+	}
+	if other := Checksum([]byte("istio-system/knative-local-gateway")); other == got {
+		t.Errorf("Checksum() = %d for different data", other)
+	}
+}
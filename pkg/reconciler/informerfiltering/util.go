@@ -21,11 +21,15 @@ import (
 	"os"
 	"strconv"
 
+	"knative.dev/net-istio/pkg/reconciler/secretmetadata"
 	"knative.dev/networking/pkg/apis/networking"
 	filteredFactory "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
 )
 
-const EnableSecretInformerFilteringByCertUIDEnv = "ENABLE_SECRET_INFORMER_FILTERING_BY_CERT_UID"
+const (
+	EnableSecretInformerFilteringByCertUIDEnv = "ENABLE_SECRET_INFORMER_FILTERING_BY_CERT_UID"
+	EnableSecretMetadataOnlyInformerEnv       = "ENABLE_SECRET_METADATA_ONLY_INFORMER"
+)
 
 // ShouldFilterByCertificateUID allows to choose whether to apply filtering on certificate related secrets
 // when list by informers in this component. If not set or set to false no filtering is applied and instead informers
@@ -38,10 +42,26 @@ func ShouldFilterByCertificateUID() bool {
 	return false
 }
 
+// ShouldWatchSecretMetadataOnly allows to choose whether Secrets are watched through a metadata-only informer.
+// When enabled, the full Secrets are only fetched when they are read, instead of keeping the content of every
+// watched Secret in memory.
+func ShouldWatchSecretMetadataOnly() bool {
+	if enable := os.Getenv(EnableSecretMetadataOnlyInformerEnv); enable != "" {
+		b, _ := strconv.ParseBool(enable)
+		return b
+	}
+	return false
+}
+
 // GetContextWithFilteringLabelSelector returns the passed context with the proper label key selector added to it.
 func GetContextWithFilteringLabelSelector(ctx context.Context) context.Context {
+	selector := "" // Allow all
 	if ShouldFilterByCertificateUID() {
-		return filteredFactory.WithSelectors(ctx, networking.CertificateUIDLabelKey)
+		selector = networking.CertificateUIDLabelKey
+	}
+	if ShouldWatchSecretMetadataOnly() {
+		// The metadata-only informer replaces the full Secret informer.
+		return secretmetadata.WithSelector(filteredFactory.WithSelectors(ctx), selector)
 	}
-	return filteredFactory.WithSelectors(ctx, "") // Allow all
+	return filteredFactory.WithSelectors(ctx, selector)
 }
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-istio/pkg/reconciler/fips"
+)
+
+const (
+	// featureGateKeyPrefix is the prefix of the configmap keys rolling out changes of
+	// the behavior of the controller to a share of the Ingresses.
+	featureGateKeyPrefix = "feature-gate."
+
+	// PatchGatewayUpdates is the feature gate updating the servers of Gateways with
+	// JSON patches, which preserve the fields of the Gateways unknown to the controller,
+	// rather than replacing the whole Gateways.
+	PatchGatewayUpdates = "patch-gateway-updates"
+)
+
+// knownFeatureGates are the feature gates the controller implements.
+var knownFeatureGates = sets.New(PatchGatewayUpdates)
+
+// FeatureGate is the percentage of the Ingresses a feature is enabled for.
+type FeatureGate int
+
+const (
+	// FeatureGateDisabled disables a feature for all the Ingresses.
+	FeatureGateDisabled FeatureGate = 0
+	// FeatureGateEnabled enables a feature for all the Ingresses.
+	FeatureGateEnabled FeatureGate = 100
+)
+
+// EnabledFor returns whether the feature is enabled for the object. The objects are
+// spread evenly, and independently for each feature, over the percentages, so that
+// raising the percentage of a feature only ever enables it for more objects.
+func (g FeatureGate) EnabledFor(feature string, obj metav1.Object) bool {
+	switch {
+	case g <= FeatureGateDisabled:
+		return false
+	case g >= FeatureGateEnabled:
+		return true
+	}
+	bucket := fips.Checksum([]byte(feature+"/"+obj.GetNamespace()+"/"+obj.GetName())) % 100
+	return int(bucket) < int(g)
+}
+
+// FeatureEnabled returns whether the feature is enabled for the object.
+func (i Istio) FeatureEnabled(feature string, obj metav1.Object) bool {
+	return i.FeatureGates[feature].EnabledFor(feature, obj)
+}
+
+// parseFeatureGates parses the feature gates of the configmap, each of which is either
+// "enabled", "disabled" or the percentage of the Ingresses to enable it for, e.g. "25%".
+func parseFeatureGates(data map[string]string) (map[string]FeatureGate, error) {
+	var ret map[string]FeatureGate
+	for key, value := range data {
+		if !strings.HasPrefix(key, featureGateKeyPrefix) {
+			continue
+		}
+		if ret == nil {
+			ret = make(map[string]FeatureGate, len(knownFeatureGates))
+		}
+		feature := strings.TrimPrefix(key, featureGateKeyPrefix)
+		if !knownFeatureGates.Has(feature) {
+			return nil, fmt.Errorf("unknown feature gate %q, must be one of %v", feature, sets.List(knownFeatureGates))
+		}
+
+		switch value = strings.TrimSpace(value); value {
+		case "enabled":
+			ret[feature] = FeatureGateEnabled
+		case "disabled", "":
+			ret[feature] = FeatureGateDisabled
+		default:
+			percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+			if err != nil || percent < 0 || percent > 100 {
+				return nil, fmt.Errorf("%s must be enabled, disabled or a percentage between 0%% and 100%%, was: %q", key, value)
+			}
+			ret[feature] = FeatureGate(percent)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+)
+
+func TestFeatureGates(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    map[string]FeatureGate
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"feature-gate.patch-gateway-updates": "enabled"},
+		want: map[string]FeatureGate{PatchGatewayUpdates: FeatureGateEnabled},
+	}, {
+		name: "disabled",
+		data: map[string]string{"feature-gate.patch-gateway-updates": "disabled"},
+		want: map[string]FeatureGate{PatchGatewayUpdates: FeatureGateDisabled},
+	}, {
+		name: "percentage",
+		data: map[string]string{"feature-gate.patch-gateway-updates": "25%"},
+		want: map[string]FeatureGate{PatchGatewayUpdates: 25},
+	}, {
+		name:    "percentage out of range",
+		data:    map[string]string{"feature-gate.patch-gateway-updates": "101%"},
+		wantErr: true,
+	}, {
+		name:    "invalid value",
+		data:    map[string]string{"feature-gate.patch-gateway-updates": "sometimes"},
+		wantErr: true,
+	}, {
+		name:    "unknown feature",
+		data:    map[string]string{"feature-gate.unknown": "enabled"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, istio.FeatureGates); diff != "" {
+				t.Error("FeatureGates (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestFeatureGateEnabledFor(t *testing.T) {
+	objs := make([]*metav1.ObjectMeta, 0, 1000)
+	for i := 0; i < cap(objs); i++ {
+		objs = append(objs, &metav1.ObjectMeta{Namespace: "test-ns", Name: fmt.Sprint("ingress-", i)})
+	}
+	enabled := func(gate FeatureGate) map[string]bool {
+		ret := map[string]bool{}
+		for _, obj := range objs {
+			if gate.EnabledFor(PatchGatewayUpdates, obj) {
+				ret[obj.Name] = true
+			}
+		}
+		return ret
+	}
+
+	if got := len(enabled(FeatureGateDisabled)); got != 0 {
+		t.Errorf("Disabled feature enabled for %d objects", got)
+	}
+	if got := len(enabled(FeatureGateEnabled)); got != len(objs) {
+		t.Errorf("Enabled feature enabled for %d objects, want %d", got, len(objs))
+	}
+
+	quarter, half := enabled(25), enabled(50)
+	// The objects are spread roughly evenly over the percentages.
+	if got := len(quarter); got < 150 || got > 350 {
+		t.Errorf("25%% feature enabled for %d objects, want about 250", got)
+	}
+	if got := len(half); got < 400 || got > 600 {
+		t.Errorf("50%% feature enabled for %d objects, want about 500", got)
+	}
+	// Raising the percentage keeps the feature enabled for the same objects.
+	for name := range quarter {
+		if !half[name] {
+			t.Errorf("Feature enabled for %s at 25%% but not at 50%%", name)
+		}
+	}
+}
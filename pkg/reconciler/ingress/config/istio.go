@@ -19,11 +19,16 @@ package config
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/net-istio/pkg/reconciler/tuning"
+	cm "knative.dev/pkg/configmap"
 	"knative.dev/pkg/network"
 	"knative.dev/pkg/system"
 	"sigs.k8s.io/yaml"
@@ -57,6 +62,101 @@ const (
 
 	// IstioNamespace is the namespace containing Istio
 	IstioNamespace = "istio-system"
+
+	// reconcileConcurrencyKey is the configmap key to configure how many sub-resources
+	// of a single Ingress are reconciled in parallel.
+	reconcileConcurrencyKey = "reconcile-concurrency"
+
+	// ambientModeKey is the configmap key to enable support for Istio ambient mode.
+	ambientModeKey = "ambient-mode"
+
+	// enableAuthorizationPoliciesKey is the configmap key to enable generating
+	// AuthorizationPolicies that restrict access to Knative workloads.
+	enableAuthorizationPoliciesKey = "enable-authorization-policies"
+
+	// peerAuthenticationModeKey is the configmap key to configure the mTLS mode of
+	// the PeerAuthentications maintained for Knative namespaces and gateways.
+	peerAuthenticationModeKey = "peer-authentication-mode"
+
+	// enableSidecarResourcesKey is the configmap key to enable generating Sidecars
+	// that limit the egress hosts of the proxies in Knative namespaces.
+	enableSidecarResourcesKey = "enable-sidecar-resources"
+
+	// remoteClusterSecretsKey is the configmap key listing the Secrets holding the
+	// kubeconfigs of the remote clusters the generated resources are programmed into.
+	remoteClusterSecretsKey = "remote-cluster-secrets"
+
+	// autoPassthroughGatewayKey is the configmap key to configure the gateway exposing
+	// cluster-local Ingresses through SNI-based routing.
+	autoPassthroughGatewayKey = "auto-passthrough-gateway"
+
+	// enableSecurityHeadersKey is the configmap key to enable adding security response
+	// headers such as HSTS to the routes generated for Ingresses.
+	enableSecurityHeadersKey = "enable-security-headers"
+
+	// enableTrafficTagsKey is the configmap key to enable tagging the requests routed by
+	// the gateways with the Ingress and the Knative Route that admitted them.
+	enableTrafficTagsKey = "enable-traffic-tags"
+
+	// enableNamespaceScopedMeshKey is the configmap key to enable restricting the mesh
+	// VirtualServices to the sidecars of the namespaces of their Ingresses.
+	enableNamespaceScopedMeshKey = "enable-namespace-scoped-mesh"
+
+	// enableNetworkPoliciesKey is the configmap key to enable generating NetworkPolicies
+	// that restrict which pods can reach the queue-proxies of Knative workloads.
+	enableNetworkPoliciesKey = "enable-network-policies"
+
+	// secretNamespacesKey is the configmap key listing the namespaces from which the
+	// TLS secrets referenced by Ingresses may be mirrored into the gateway namespaces.
+	secretNamespacesKey = "secret-namespaces"
+
+	// enableMigrationHandoverKey is the configmap key to enable cleaning up the resources
+	// of the Ingresses moved to another ingress class once it serves them.
+	enableMigrationHandoverKey = "enable-migration-handover"
+
+	// probePodsPerZoneKey is the configmap key to configure how many pods of each zone
+	// of a gateway are probed, rather than all of them.
+	probePodsPerZoneKey = "probe-pods-per-zone"
+
+	// reportZoneReadinessKey is the configmap key to enable reporting the zones of the
+	// gateway pods the readiness of the Ingresses was probed in.
+	reportZoneReadinessKey = "report-zone-readiness"
+
+	// emergencyFreezeKey is the configmap key to halt all the writes of the controller
+	// during an incident, while it keeps reconciling and reporting the drift.
+	emergencyFreezeKey = "emergency-freeze"
+
+	// forceFinalizeAfterKey is the configmap key to configure how long an Ingress may fail
+	// to be cleaned up in deletion before the failing cleanup steps are skipped.
+	forceFinalizeAfterKey = "force-finalize-after"
+
+	// catchAllStatusKey and catchAllBodyKey are the configmap keys to configure the response
+	// of the route installed on the gateways for the hosts no Ingress matches.
+	catchAllStatusKey = "catch-all-status"
+	catchAllBodyKey   = "catch-all-body"
+
+	// retryAttemptsKey, retryOnKey and retryPerTryTimeoutKey are the configmap keys to
+	// configure the retries of the routes generated for Ingresses.
+	retryAttemptsKey      = "retry-attempts"
+	retryOnKey            = "retry-on"
+	retryPerTryTimeoutKey = "retry-per-try-timeout"
+
+	// grpcTimeoutHeaderKey is the configmap key to configure whether the gateways forward
+	// the grpc-timeout header of the gRPC requests to the backends.
+	grpcTimeoutHeaderKey = "grpc-timeout-header"
+
+	// corsExposeHeadersKey and corsAllowCredentialsKey are the configmap keys to configure
+	// the defaults merged into the CORS policies of the Ingresses.
+	corsExposeHeadersKey    = "cors-expose-headers"
+	corsAllowCredentialsKey = "cors-allow-credentials"
+
+	// The configmap keys tuning the controller while it runs. They override the
+	// corresponding WORKQUEUE_* and GLOBAL_RESYNC_BUDGET variables of the controller.
+	workqueueBaseDelayKey = "workqueue-base-delay"
+	workqueueMaxDelayKey  = "workqueue-max-delay"
+	workqueueQPSKey       = "workqueue-qps"
+	workqueueBurstKey     = "workqueue-burst"
+	globalResyncBudgetKey = "global-resync-budget"
 )
 
 func defaultIngressGateways() []Gateway {
@@ -81,6 +181,9 @@ type Gateway struct {
 	Name          string
 	ServiceURL    string                `json:"service"`
 	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// Namespaces restricts the gateway to the Ingresses of the given namespaces,
+	// regardless of the labels of the Ingresses. Empty means any namespace.
+	Namespaces []string `json:"namespaces,omitempty"`
 }
 
 // QualifiedName returns gateway name in '{namespace}/{name}' format.
@@ -88,6 +191,19 @@ func (g Gateway) QualifiedName() string {
 	return g.Namespace + "/" + g.Name
 }
 
+// AllowsNamespace returns whether the Ingresses of the namespace may use the gateway.
+func (g Gateway) AllowsNamespace(namespace string) bool {
+	if len(g.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range g.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func (g Gateway) Validate() error {
 	if g.Namespace == "" {
 		return fmt.Errorf("missing namespace")
@@ -109,6 +225,17 @@ func (g Gateway) Validate() error {
 		return fmt.Errorf("failed to create selector from label selector: %w", err)
 	}
 
+	if len(g.Namespaces) > 0 && g.LabelSelector == nil {
+		// The default gateway must be usable by every Ingress.
+		return fmt.Errorf("namespaces can only be restricted for gateways with a labelSelector")
+	}
+
+	for _, ns := range g.Namespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %v", ns, errs)
+		}
+	}
+
 	return nil
 }
 
@@ -120,9 +247,198 @@ type Istio struct {
 
 	// LocalGateways specifies the gateway urls for public & private Ingress.
 	LocalGateways []Gateway
+
+	// ReconcileConcurrency specifies how many independent sub-resources (secrets,
+	// gateways, VirtualServices, DestinationRules) of a single Ingress are
+	// reconciled in parallel. Zero or one means they are reconciled serially.
+	ReconcileConcurrency int
+
+	// AmbientMode specifies that Knative runs on an Istio ambient mesh, where
+	// workloads have no sidecars and traffic between them is secured by ztunnel.
+	// The resources that only configure the sidecars are then not created.
+	AmbientMode bool
+
+	// EnableAuthorizationPolicies specifies that AuthorizationPolicies are created
+	// which only allow the configured gateways and the activator to reach the
+	// backends of an Ingress.
+	EnableAuthorizationPolicies bool
+
+	// PeerAuthenticationMode specifies the mTLS mode (STRICT or PERMISSIVE) of the
+	// PeerAuthentications maintained for namespaces hosting Knative services and
+	// for the gateways. Empty means no PeerAuthentications are maintained.
+	PeerAuthenticationMode string
+
+	// EnableSidecarResources specifies that a Sidecar is maintained for every namespace
+	// hosting Knative services, which limits the egress hosts of its proxies to the
+	// gateways, the Knative system namespace and the namespace itself.
+	EnableSidecarResources bool
+
+	// RemoteClusterSecrets specifies the Secrets in the Knative system namespace holding
+	// the kubeconfigs of the remote clusters of an Istio multi-primary deployment. The
+	// VirtualServices and Gateways generated for Ingresses are also programmed into them.
+	RemoteClusterSecrets sets.Set[string]
+
+	// AutoPassthroughGateway specifies the service URL of the gateway, e.g. an east-west
+	// gateway, on which the cluster-local hosts of Ingresses are exposed in AUTO_PASSTHROUGH
+	// mode, routing on the SNI without terminating TLS. Empty means none.
+	AutoPassthroughGateway string
+
+	// EnableSecurityHeaders specifies that security response headers, such as
+	// Strict-Transport-Security and X-Content-Type-Options, are set on the routes
+	// generated for Ingresses. Ingresses can override it with an annotation.
+	EnableSecurityHeaders bool
+
+	// EnableTrafficTags specifies that the routes of the gateways set request headers naming
+	// the Ingress and the Knative Route that admitted the requests, so that the logs and the
+	// metrics of the backends can attribute them. Ingresses can override it with an annotation.
+	EnableTrafficTags bool
+
+	// EnableNamespaceScopedMesh specifies that the mesh VirtualServices are only exported to
+	// the namespaces of their Ingresses, and also route the short names of their hosts, so
+	// that the cluster-local routes of a tenant can't be reached from the other namespaces.
+	EnableNamespaceScopedMesh bool
+
+	// EnableNetworkPolicies specifies that a NetworkPolicy is maintained for every namespace
+	// hosting Knative services, which only lets the gateways and the activator reach the
+	// queue-proxy ports of its Knative pods.
+	EnableNetworkPolicies bool
+
+	// SecretNamespaces specifies the namespaces of the TLS secrets that Ingresses may
+	// reference, and that are thus mirrored into the gateway namespaces. Ingresses
+	// referencing secrets in any other namespace are failed. Empty means any namespace.
+	SecretNamespaces sets.Set[string]
+
+	// EnableMigrationHandover specifies that the resources of the Ingresses moved to another
+	// ingress class are cleaned up as soon as the replacing implementation reports them ready
+	// on its own load balancer. Otherwise they keep serving until the Ingresses are deleted.
+	EnableMigrationHandover bool
+
+	// ProbePodsPerZone specifies how many pods of each zone of a gateway are probed before
+	// an Ingress is marked ready. Zero means all the pods of the gateway are probed.
+	ProbePodsPerZone int
+
+	// ReportZoneReadiness specifies that the zones of the gateway pods the readiness of an
+	// Ingress was probed in are reported in its GatewayZonesReady condition.
+	ReportZoneReadiness bool
+
+	// EmergencyFreeze specifies that the controller must not change anything in the cluster.
+	// Its writes are sent as dry runs and reported as drift instead, except for the status
+	// of the Ingresses, the Events and the leader election Leases.
+	EmergencyFreeze bool
+
+	// ForceFinalizeAfter specifies how long an Ingress may fail to be cleaned up in deletion,
+	// e.g. as a shared gateway it was exposed on was deleted, before the failing cleanup steps
+	// are skipped and reported in events. Zero means the Ingress is never forcibly finalized.
+	ForceFinalizeAfter time.Duration
+
+	// CatchAllStatus specifies the HTTP status returned by a route installed on the gateways
+	// for the hosts no Ingress matches, instead of the implicit 404 of the gateways, and
+	// CatchAllBody the body of its responses. Zero means no such route is installed.
+	CatchAllStatus int
+	CatchAllBody   string
+
+	// RetryAttempts specifies how many times the routes generated for Ingresses retry a
+	// request, on the comma-separated conditions of RetryOn, each try timing out after
+	// RetryPerTryTimeout, except for the gRPC requests. Zero means the requests are not
+	// retried. Empty conditions and zero timeouts keep the defaults of Istio. Ingresses can
+	// override them with an annotation.
+	RetryAttempts      int
+	RetryOn            string
+	RetryPerTryTimeout time.Duration
+
+	// GRPCTimeoutHeader specifies whether the routes of the gateways forward the grpc-timeout
+	// header, which bounds the deadline of the gRPC requests, to the backends, either
+	// GRPCTimeoutHeaderPropagate or GRPCTimeoutHeaderStrip. Empty means it is propagated.
+	GRPCTimeoutHeader string
+
+	// CORSExposeHeaders specifies the response headers the browsers expose to the scripts
+	// of the origins allowed by the CORS policies of the Ingresses, besides their own, and
+	// CORSAllowCredentials whether the policies which don't say otherwise allow credentials.
+	// They don't apply to the Ingresses without CORS policies.
+	CORSExposeHeaders    sets.Set[string]
+	CORSAllowCredentials bool
+
+	// FeatureGates specifies the percentage of the Ingresses each change of the behavior
+	// of the controller is rolled out to, keyed by the name of the feature.
+	FeatureGates map[string]FeatureGate
+
+	// Tuning specifies the rate limiting of the work queue of the controller and the budget
+	// of its global resyncs, which are applied without restarting it. Unset values keep the
+	// ones configured in the environment of the controller.
+	Tuning tuning.Settings
 }
 
 func (i Istio) Validate() error {
+	if i.ReconcileConcurrency < 0 {
+		return fmt.Errorf("%s must not be negative, was: %d", reconcileConcurrencyKey, i.ReconcileConcurrency)
+	}
+
+	switch i.PeerAuthenticationMode {
+	case "", "STRICT", "PERMISSIVE":
+	default:
+		return fmt.Errorf("%s must be one of STRICT or PERMISSIVE, was: %q", peerAuthenticationModeKey, i.PeerAuthenticationMode)
+	}
+
+	if i.AutoPassthroughGateway != "" {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimSuffix(i.AutoPassthroughGateway, ".")); len(errs) > 0 {
+			return fmt.Errorf("invalid %s: %v", autoPassthroughGatewayKey, errs)
+		}
+		if strings.Count(i.AutoPassthroughGateway, ".") < 2 {
+			return fmt.Errorf("%s must be the service URL of the gateway, was: %q", autoPassthroughGatewayKey, i.AutoPassthroughGateway)
+		}
+	}
+
+	if i.ProbePodsPerZone < 0 {
+		return fmt.Errorf("%s must not be negative, was: %d", probePodsPerZoneKey, i.ProbePodsPerZone)
+	}
+
+	if i.ForceFinalizeAfter < 0 {
+		return fmt.Errorf("%s must not be negative, was: %v", forceFinalizeAfterKey, i.ForceFinalizeAfter)
+	}
+
+	if i.CatchAllStatus != 0 && (i.CatchAllStatus < 200 || i.CatchAllStatus > 599) {
+		return fmt.Errorf("%s must be an HTTP status between 200 and 599, was: %d", catchAllStatusKey, i.CatchAllStatus)
+	}
+	if i.CatchAllStatus == 0 && i.CatchAllBody != "" {
+		return fmt.Errorf("%s requires %s", catchAllBodyKey, catchAllStatusKey)
+	}
+
+	if i.RetryAttempts < 0 {
+		return fmt.Errorf("%s must not be negative, was: %d", retryAttemptsKey, i.RetryAttempts)
+	}
+	if err := ValidateRetryOn(i.RetryOn); err != nil {
+		return fmt.Errorf("invalid %s: %w", retryOnKey, err)
+	}
+	if i.RetryPerTryTimeout < 0 {
+		return fmt.Errorf("%s must not be negative, was: %v", retryPerTryTimeoutKey, i.RetryPerTryTimeout)
+	}
+	if i.RetryAttempts == 0 && (i.RetryOn != "" || i.RetryPerTryTimeout != 0) {
+		return fmt.Errorf("%s and %s require %s", retryOnKey, retryPerTryTimeoutKey, retryAttemptsKey)
+	}
+
+	switch i.GRPCTimeoutHeader {
+	case "", GRPCTimeoutHeaderPropagate, GRPCTimeoutHeaderStrip:
+	default:
+		return fmt.Errorf("%s must be one of %s or %s, was: %q", grpcTimeoutHeaderKey,
+			GRPCTimeoutHeaderPropagate, GRPCTimeoutHeaderStrip, i.GRPCTimeoutHeader)
+	}
+
+	for name := range i.CORSExposeHeaders {
+		if errs := validation.IsHTTPHeaderName(name); len(errs) > 0 {
+			return fmt.Errorf("invalid header %q in %s: %v", name, corsExposeHeadersKey, errs)
+		}
+	}
+
+	if err := i.validateTuning(); err != nil {
+		return err
+	}
+
+	for ns := range i.SecretNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q in %s: %v", ns, secretNamespacesKey, errs)
+		}
+	}
+
 	for _, gtw := range i.IngressGateways {
 		if err := gtw.Validate(); err != nil {
 			return fmt.Errorf("invalid gateway %s: %w", gtw.QualifiedName(), err)
@@ -138,6 +454,75 @@ func (i Istio) Validate() error {
 	return nil
 }
 
+const (
+	// GRPCTimeoutHeaderPropagate forwards the grpc-timeout header to the backends.
+	GRPCTimeoutHeaderPropagate = "propagate"
+	// GRPCTimeoutHeaderStrip removes the grpc-timeout header at the gateways, so that the
+	// clients outside of the cluster can't bound the work of the backends.
+	GRPCTimeoutHeaderStrip = "strip"
+)
+
+// retryConditions are the conditions of the retries of Envoy that Istio supports, besides
+// the HTTP status codes.
+var retryConditions = sets.New(
+	"5xx", "gateway-error", "reset", "connect-failure", "envoy-ratelimited", "retriable-4xx",
+	"refused-stream", "retriable-status-codes", "retriable-headers", "cancelled",
+	"deadline-exceeded", "internal", "resource-exhausted", "unavailable",
+)
+
+// ValidateRetryOn returns an error unless the comma-separated retry conditions are either
+// supported by Istio or HTTP status codes.
+func ValidateRetryOn(retryOn string) error {
+	if retryOn == "" {
+		return nil
+	}
+	for _, condition := range strings.Split(retryOn, ",") {
+		condition = strings.TrimSpace(condition)
+		if retryConditions.Has(condition) {
+			continue
+		}
+		if code, err := strconv.Atoi(condition); err == nil && code >= 100 && code <= 599 {
+			continue
+		}
+		return fmt.Errorf("unsupported retry condition %q", condition)
+	}
+	return nil
+}
+
+func (i Istio) validateTuning() error {
+	for key, d := range map[string]time.Duration{
+		workqueueBaseDelayKey: i.Tuning.RateLimiterBaseDelay,
+		workqueueMaxDelayKey:  i.Tuning.RateLimiterMaxDelay,
+		globalResyncBudgetKey: i.Tuning.GlobalResyncBudget,
+	} {
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative, was: %v", key, d)
+		}
+	}
+	for key, n := range map[string]int{
+		workqueueQPSKey:   i.Tuning.RateLimiterQPS,
+		workqueueBurstKey: i.Tuning.RateLimiterBurst,
+	} {
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative, was: %d", key, n)
+		}
+	}
+	if i.Tuning.RateLimiterMaxDelay != 0 && i.Tuning.RateLimiterBaseDelay > i.Tuning.RateLimiterMaxDelay {
+		return fmt.Errorf("%s (%v) must not exceed %s (%v)", workqueueBaseDelayKey, i.Tuning.RateLimiterBaseDelay,
+			workqueueMaxDelayKey, i.Tuning.RateLimiterMaxDelay)
+	}
+	return nil
+}
+
+// ReconcileConcurrencyLimit returns the effective number of sub-resources
+// that may be reconciled in parallel.
+func (i Istio) ReconcileConcurrencyLimit() int {
+	if i.ReconcileConcurrency < 1 {
+		return 1
+	}
+	return i.ReconcileConcurrency
+}
+
 // DefaultExternalGateways returns the external gateway without any label selector
 func (i Istio) DefaultExternalGateways() []Gateway {
 	return defaultGateways(i.IngressGateways)
@@ -187,6 +572,49 @@ func NewIstioFromConfigMap(configMap *corev1.ConfigMap) (*Istio, error) {
 		defaultValues(ret)
 	}
 
+	if err := cm.Parse(configMap.Data,
+		cm.AsInt(reconcileConcurrencyKey, &ret.ReconcileConcurrency),
+		cm.AsBool(ambientModeKey, &ret.AmbientMode),
+		cm.AsBool(enableAuthorizationPoliciesKey, &ret.EnableAuthorizationPolicies),
+		cm.AsString(peerAuthenticationModeKey, &ret.PeerAuthenticationMode),
+		cm.AsBool(enableSidecarResourcesKey, &ret.EnableSidecarResources),
+		cm.AsStringSet(remoteClusterSecretsKey, &ret.RemoteClusterSecrets),
+		cm.AsString(autoPassthroughGatewayKey, &ret.AutoPassthroughGateway),
+		cm.AsBool(enableSecurityHeadersKey, &ret.EnableSecurityHeaders),
+		cm.AsBool(enableTrafficTagsKey, &ret.EnableTrafficTags),
+		cm.AsBool(enableNamespaceScopedMeshKey, &ret.EnableNamespaceScopedMesh),
+		cm.AsBool(enableNetworkPoliciesKey, &ret.EnableNetworkPolicies),
+		cm.AsStringSet(secretNamespacesKey, &ret.SecretNamespaces),
+		cm.AsBool(enableMigrationHandoverKey, &ret.EnableMigrationHandover),
+		cm.AsInt(probePodsPerZoneKey, &ret.ProbePodsPerZone),
+		cm.AsBool(reportZoneReadinessKey, &ret.ReportZoneReadiness),
+		cm.AsBool(emergencyFreezeKey, &ret.EmergencyFreeze),
+		cm.AsDuration(forceFinalizeAfterKey, &ret.ForceFinalizeAfter),
+		cm.AsInt(catchAllStatusKey, &ret.CatchAllStatus),
+		cm.AsString(catchAllBodyKey, &ret.CatchAllBody),
+		cm.AsInt(retryAttemptsKey, &ret.RetryAttempts),
+		cm.AsString(retryOnKey, &ret.RetryOn),
+		cm.AsDuration(retryPerTryTimeoutKey, &ret.RetryPerTryTimeout),
+		cm.AsString(grpcTimeoutHeaderKey, &ret.GRPCTimeoutHeader),
+		cm.AsStringSet(corsExposeHeadersKey, &ret.CORSExposeHeaders),
+		cm.AsBool(corsAllowCredentialsKey, &ret.CORSAllowCredentials),
+		cm.AsDuration(workqueueBaseDelayKey, &ret.Tuning.RateLimiterBaseDelay),
+		cm.AsDuration(workqueueMaxDelayKey, &ret.Tuning.RateLimiterMaxDelay),
+		cm.AsInt(workqueueQPSKey, &ret.Tuning.RateLimiterQPS),
+		cm.AsInt(workqueueBurstKey, &ret.Tuning.RateLimiterBurst),
+		cm.AsDuration(globalResyncBudgetKey, &ret.Tuning.GlobalResyncBudget),
+	); err != nil {
+		return nil, fmt.Errorf("failed to parse configmap: %w", err)
+	}
+	// An empty value is split into a single empty name.
+	ret.RemoteClusterSecrets.Delete("")
+	ret.SecretNamespaces.Delete("")
+	ret.CORSExposeHeaders.Delete("")
+
+	if ret.FeatureGates, err = parseFeatureGates(configMap.Data); err != nil {
+		return nil, fmt.Errorf("failed to parse configmap: %w", err)
+	}
+
 	err = ret.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
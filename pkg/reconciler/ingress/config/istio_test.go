@@ -19,10 +19,13 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-istio/pkg/reconciler/tuning"
 	"knative.dev/pkg/system"
 
 	. "knative.dev/pkg/configmap/testing"
@@ -589,6 +592,76 @@ func TestGatewayConfiguration(t *testing.T) {
 			},
 		},
 		wantErr: true,
+	}, {
+		name: "gateway dedicated to namespaces",
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      IstioConfigName,
+			},
+			Data: map[string]string{
+				"external-gateways": replaceTabs(`
+				- namespace: "default"
+				  name: "default"
+				  service: "default.default.svc.cluster.local"
+				- namespace: "tenant-a"
+				  name: "gateway"
+				  service: "istio-gateway.tenant-a.svc.cluster.local"
+				  labelSelector:
+					matchLabels:
+					  tenant: "a"
+				  namespaces: ["tenant-a"]`),
+			},
+		},
+		wantIstio: &Istio{
+			IngressGateways: []Gateway{{
+				Namespace:  "default",
+				Name:       "default",
+				ServiceURL: "default.default.svc.cluster.local",
+			}, {
+				Namespace:     "tenant-a",
+				Name:          "gateway",
+				ServiceURL:    "istio-gateway.tenant-a.svc.cluster.local",
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+				Namespaces:    []string{"tenant-a"},
+			}},
+			LocalGateways: defaultLocalGateways(),
+		},
+	}, {
+		name: "default gateway dedicated to namespaces",
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      IstioConfigName,
+			},
+			Data: map[string]string{
+				"external-gateways": replaceTabs(`
+				- namespace: "default"
+				  name: "default"
+				  service: "default.default.svc.cluster.local"
+				  namespaces: ["tenant-a"]`),
+			},
+		},
+		wantErr: true,
+	}, {
+		name: "gateway dedicated to an invalid namespace",
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      IstioConfigName,
+			},
+			Data: map[string]string{
+				"external-gateways": replaceTabs(`
+				- namespace: "tenant-a"
+				  name: "gateway"
+				  service: "istio-gateway.tenant-a.svc.cluster.local"
+				  labelSelector:
+					matchLabels:
+					  tenant: "a"
+				  namespaces: ["Tenant_A"]`),
+			},
+		},
+		wantErr: true,
 	}}
 
 	for _, tt := range gatewayConfigTests {
@@ -605,6 +678,957 @@ func TestGatewayConfiguration(t *testing.T) {
 	}
 }
 
+func TestReconcileConcurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]string
+		wantErr   bool
+		wantLimit int
+	}{{
+		name:      "default",
+		wantLimit: 1,
+	}, {
+		name:      "serial",
+		data:      map[string]string{"reconcile-concurrency": "0"},
+		wantLimit: 1,
+	}, {
+		name:      "parallel",
+		data:      map[string]string{"reconcile-concurrency": "8"},
+		wantLimit: 8,
+	}, {
+		name:    "negative",
+		data:    map[string]string{"reconcile-concurrency": "-1"},
+		wantErr: true,
+	}, {
+		name:    "not a number",
+		data:    map[string]string{"reconcile-concurrency": "many"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := istio.ReconcileConcurrencyLimit(); got != tt.wantLimit {
+				t.Errorf("ReconcileConcurrencyLimit() = %d, want %d", got, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestAmbientMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"ambient-mode": "true"},
+		want: true,
+	}, {
+		name: "disabled",
+		data: map[string]string{"ambient-mode": "false"},
+	}, {
+		name:    "not a bool",
+		data:    map[string]string{"ambient-mode": "sometimes"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.AmbientMode != tt.want {
+				t.Errorf("AmbientMode = %v, want %v", istio.AmbientMode, tt.want)
+			}
+		})
+	}
+}
+
 func replaceTabs(s string) string {
 	return strings.ReplaceAll(s, "\t", "    ")
 }
+
+func TestEnableAuthorizationPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-authorization-policies": "true"},
+		want: true,
+	}, {
+		name:    "not a bool",
+		data:    map[string]string{"enable-authorization-policies": "maybe"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableAuthorizationPolicies != tt.want {
+				t.Errorf("EnableAuthorizationPolicies = %v, want %v", istio.EnableAuthorizationPolicies, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeerAuthenticationMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    string
+	}{{
+		name: "default",
+	}, {
+		name: "strict",
+		data: map[string]string{"peer-authentication-mode": "STRICT"},
+		want: "STRICT",
+	}, {
+		name: "permissive",
+		data: map[string]string{"peer-authentication-mode": "PERMISSIVE"},
+		want: "PERMISSIVE",
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"peer-authentication-mode": "DISABLE"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.PeerAuthenticationMode != tt.want {
+				t.Errorf("PeerAuthenticationMode = %q, want %q", istio.PeerAuthenticationMode, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableSidecarResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-sidecar-resources": "true"},
+		want: true,
+	}, {
+		name:    "not a bool",
+		data:    map[string]string{"enable-sidecar-resources": "yes please"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableSidecarResources != tt.want {
+				t.Errorf("EnableSidecarResources = %v, want %v", istio.EnableSidecarResources, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteClusterSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want sets.Set[string]
+	}{{
+		name: "default",
+	}, {
+		name: "empty",
+		data: map[string]string{"remote-cluster-secrets": ""},
+	}, {
+		name: "remote clusters",
+		data: map[string]string{"remote-cluster-secrets": "cluster-b, cluster-c"},
+		want: sets.New("cluster-b", "cluster-c"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if err != nil {
+				t.Fatal("NewIstioFromConfigMap() =", err)
+			}
+			if !istio.RemoteClusterSecrets.Equal(tt.want) {
+				t.Errorf("RemoteClusterSecrets = %v, want %v", sets.List(istio.RemoteClusterSecrets), sets.List(tt.want))
+			}
+		})
+	}
+}
+
+func TestAutoPassthroughGateway(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    string
+	}{{
+		name: "default",
+	}, {
+		name: "east-west gateway",
+		data: map[string]string{"auto-passthrough-gateway": "istio-eastwestgateway.istio-system.svc.cluster.local"},
+		want: "istio-eastwestgateway.istio-system.svc.cluster.local",
+	}, {
+		name:    "not a service URL",
+		data:    map[string]string{"auto-passthrough-gateway": "istio-eastwestgateway"},
+		wantErr: true,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"auto-passthrough-gateway": "Istio_EastWest.istio-system.svc"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.AutoPassthroughGateway != tt.want {
+				t.Errorf("AutoPassthroughGateway = %q, want %q", istio.AutoPassthroughGateway, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-security-headers": "true"},
+		want: true,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"enable-security-headers": "always"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableSecurityHeaders != tt.want {
+				t.Errorf("EnableSecurityHeaders = %v, want %v", istio.EnableSecurityHeaders, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableTrafficTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-traffic-tags": "true"},
+		want: true,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"enable-traffic-tags": "always"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableTrafficTags != tt.want {
+				t.Errorf("EnableTrafficTags = %v, want %v", istio.EnableTrafficTags, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableNamespaceScopedMesh(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-namespace-scoped-mesh": "true"},
+		want: true,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"enable-namespace-scoped-mesh": "always"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableNamespaceScopedMesh != tt.want {
+				t.Errorf("EnableNamespaceScopedMesh = %v, want %v", istio.EnableNamespaceScopedMesh, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableNetworkPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-network-policies": "true"},
+		want: true,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"enable-network-policies": "always"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableNetworkPolicies != tt.want {
+				t.Errorf("EnableNetworkPolicies = %v, want %v", istio.EnableNetworkPolicies, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    sets.Set[string]
+	}{{
+		name: "default",
+	}, {
+		name: "empty",
+		data: map[string]string{"secret-namespaces": ""},
+	}, {
+		name: "namespaces",
+		data: map[string]string{"secret-namespaces": "tenant-a, tenant-b"},
+		want: sets.New("tenant-a", "tenant-b"),
+	}, {
+		name:    "invalid namespace",
+		data:    map[string]string{"secret-namespaces": "tenant-a,Tenant_B"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !istio.SecretNamespaces.Equal(tt.want) {
+				t.Errorf("SecretNamespaces = %v, want %v", sets.List(istio.SecretNamespaces), sets.List(tt.want))
+			}
+		})
+	}
+}
+
+func TestEnableMigrationHandover(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "enabled",
+		data: map[string]string{"enable-migration-handover": "true"},
+		want: true,
+	}, {
+		name:    "not a bool",
+		data:    map[string]string{"enable-migration-handover": "yes please"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EnableMigrationHandover != tt.want {
+				t.Errorf("EnableMigrationHandover = %v, want %v", istio.EnableMigrationHandover, tt.want)
+			}
+		})
+	}
+}
+
+func TestTuning(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    tuning.Settings
+	}{{
+		name: "default",
+	}, {
+		name: "all set",
+		data: map[string]string{
+			"workqueue-base-delay": "10ms",
+			"workqueue-max-delay":  "5m",
+			"workqueue-qps":        "50",
+			"workqueue-burst":      "500",
+			"global-resync-budget": "2m",
+		},
+		want: tuning.Settings{
+			RateLimiterBaseDelay: 10 * time.Millisecond,
+			RateLimiterMaxDelay:  5 * time.Minute,
+			RateLimiterQPS:       50,
+			RateLimiterBurst:     500,
+			GlobalResyncBudget:   2 * time.Minute,
+		},
+	}, {
+		name:    "not a duration",
+		data:    map[string]string{"global-resync-budget": "slowly"},
+		wantErr: true,
+	}, {
+		name:    "negative qps",
+		data:    map[string]string{"workqueue-qps": "-1"},
+		wantErr: true,
+	}, {
+		name: "base delay above max delay",
+		data: map[string]string{
+			"workqueue-base-delay": "1m",
+			"workqueue-max-delay":  "1s",
+		},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.Tuning != tt.want {
+				t.Errorf("Tuning = %+v, want %+v", istio.Tuning, tt.want)
+			}
+		})
+	}
+}
+
+func TestZoneAwareProbing(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        map[string]string
+		wantErr     bool
+		wantPerZone int
+		wantReport  bool
+	}{{
+		name: "default",
+	}, {
+		name: "set",
+		data: map[string]string{
+			"probe-pods-per-zone":   "2",
+			"report-zone-readiness": "true",
+		},
+		wantPerZone: 2,
+		wantReport:  true,
+	}, {
+		name:    "negative pods per zone",
+		data:    map[string]string{"probe-pods-per-zone": "-1"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.ProbePodsPerZone != tt.wantPerZone {
+				t.Errorf("ProbePodsPerZone = %d, want %d", istio.ProbePodsPerZone, tt.wantPerZone)
+			}
+			if istio.ReportZoneReadiness != tt.wantReport {
+				t.Errorf("ReportZoneReadiness = %v, want %v", istio.ReportZoneReadiness, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestEmergencyFreeze(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    bool
+	}{{
+		name: "default",
+	}, {
+		name: "frozen",
+		data: map[string]string{"emergency-freeze": "true"},
+		want: true,
+	}, {
+		name:    "not a bool",
+		data:    map[string]string{"emergency-freeze": "freeze"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.EmergencyFreeze != tt.want {
+				t.Errorf("EmergencyFreeze = %v, want %v", istio.EmergencyFreeze, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatchAll(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       map[string]string
+		wantErr    bool
+		wantStatus int
+		wantBody   string
+	}{{
+		name: "default",
+	}, {
+		name:       "status and body",
+		data:       map[string]string{"catch-all-status": "418", "catch-all-body": "I'm a teapot"},
+		wantStatus: 418,
+		wantBody:   "I'm a teapot",
+	}, {
+		name:       "status only",
+		data:       map[string]string{"catch-all-status": "404"},
+		wantStatus: 404,
+	}, {
+		name:    "status out of range",
+		data:    map[string]string{"catch-all-status": "99"},
+		wantErr: true,
+	}, {
+		name:    "not a status",
+		data:    map[string]string{"catch-all-status": "teapot"},
+		wantErr: true,
+	}, {
+		name:    "body without status",
+		data:    map[string]string{"catch-all-body": "not found"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.CatchAllStatus != tt.wantStatus || istio.CatchAllBody != tt.wantBody {
+				t.Errorf("CatchAllStatus, CatchAllBody = %d, %q, want %d, %q", istio.CatchAllStatus, istio.CatchAllBody, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestForceFinalizeAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    time.Duration
+	}{{
+		name: "default",
+	}, {
+		name: "set",
+		data: map[string]string{"force-finalize-after": "1h"},
+		want: time.Hour,
+	}, {
+		name:    "negative",
+		data:    map[string]string{"force-finalize-after": "-1h"},
+		wantErr: true,
+	}, {
+		name:    "not a duration",
+		data:    map[string]string{"force-finalize-after": "soon"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.ForceFinalizeAfter != tt.want {
+				t.Errorf("ForceFinalizeAfter = %v, want %v", istio.ForceFinalizeAfter, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name              string
+		data              map[string]string
+		wantErr           bool
+		wantAttempts      int
+		wantOn            string
+		wantPerTryTimeout time.Duration
+	}{{
+		name: "default",
+	}, {
+		name:              "retries",
+		data:              map[string]string{"retry-attempts": "3", "retry-on": "5xx, reset,503", "retry-per-try-timeout": "2s"},
+		wantAttempts:      3,
+		wantOn:            "5xx, reset,503",
+		wantPerTryTimeout: 2 * time.Second,
+	}, {
+		name:         "attempts only",
+		data:         map[string]string{"retry-attempts": "2"},
+		wantAttempts: 2,
+	}, {
+		name:    "negative attempts",
+		data:    map[string]string{"retry-attempts": "-1"},
+		wantErr: true,
+	}, {
+		name:    "unsupported condition",
+		data:    map[string]string{"retry-attempts": "3", "retry-on": "5xx,sometimes"},
+		wantErr: true,
+	}, {
+		name:    "status out of range",
+		data:    map[string]string{"retry-attempts": "3", "retry-on": "99"},
+		wantErr: true,
+	}, {
+		name:    "negative timeout",
+		data:    map[string]string{"retry-attempts": "3", "retry-per-try-timeout": "-1s"},
+		wantErr: true,
+	}, {
+		name:    "conditions without attempts",
+		data:    map[string]string{"retry-on": "5xx"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.RetryAttempts != tt.wantAttempts || istio.RetryOn != tt.wantOn || istio.RetryPerTryTimeout != tt.wantPerTryTimeout {
+				t.Errorf("RetryAttempts, RetryOn, RetryPerTryTimeout = %d, %q, %v, want %d, %q, %v", istio.RetryAttempts, istio.RetryOn,
+					istio.RetryPerTryTimeout, tt.wantAttempts, tt.wantOn, tt.wantPerTryTimeout)
+			}
+		})
+	}
+}
+
+func TestGRPCTimeoutHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+		want    string
+	}{{
+		name: "default",
+	}, {
+		name: "propagate",
+		data: map[string]string{"grpc-timeout-header": "propagate"},
+		want: GRPCTimeoutHeaderPropagate,
+	}, {
+		name: "strip",
+		data: map[string]string{"grpc-timeout-header": "strip"},
+		want: GRPCTimeoutHeaderStrip,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"grpc-timeout-header": "cap"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if istio.GRPCTimeoutHeader != tt.want {
+				t.Errorf("GRPCTimeoutHeader = %q, want %q", istio.GRPCTimeoutHeader, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name                 string
+		data                 map[string]string
+		wantErr              bool
+		wantExposeHeaders    sets.Set[string]
+		wantAllowCredentials bool
+	}{{
+		name: "default",
+	}, {
+		name: "empty",
+		data: map[string]string{"cors-expose-headers": ""},
+	}, {
+		name: "valid",
+		data: map[string]string{
+			"cors-expose-headers":    "X-Request-Id, Server-Timing",
+			"cors-allow-credentials": "true",
+		},
+		wantExposeHeaders:    sets.New("X-Request-Id", "Server-Timing"),
+		wantAllowCredentials: true,
+	}, {
+		name:    "invalid header",
+		data:    map[string]string{"cors-expose-headers": "X-Request-Id, Server Timing"},
+		wantErr: true,
+	}, {
+		name:    "invalid credentials",
+		data:    map[string]string{"cors-allow-credentials": "sometimes"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			istio, err := NewIstioFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      IstioConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIstioFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(sets.List(tt.wantExposeHeaders), sets.List(istio.CORSExposeHeaders)); diff != "" {
+				t.Error("CORSExposeHeaders (-want, +got):", diff)
+			}
+			if istio.CORSAllowCredentials != tt.wantAllowCredentials {
+				t.Errorf("CORSAllowCredentials = %v, want %v", istio.CORSAllowCredentials, tt.wantAllowCredentials)
+			}
+		})
+	}
+}
@@ -20,14 +20,29 @@ import (
 	"context"
 
 	"go.uber.org/zap"
-	v1 "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	istioinformers "knative.dev/net-istio/pkg/client/istio/informers/externalversions"
 	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
 	destinationruleinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/destinationrule"
 	gatewayinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/gateway"
 	virtualserviceinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/virtualservice"
+	authorizationpolicyinformer "knative.dev/net-istio/pkg/client/istio/injection/informers/security/v1beta1/authorizationpolicy"
+	"knative.dev/net-istio/pkg/reconciler/capabilities"
+	"knative.dev/net-istio/pkg/reconciler/fips"
+	"knative.dev/net-istio/pkg/reconciler/informerfiltering"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/hooks"
+	"knative.dev/net-istio/pkg/reconciler/ingress/probing"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/observer"
+	"knative.dev/net-istio/pkg/reconciler/preflight"
+	"knative.dev/net-istio/pkg/reconciler/remotecluster"
+	"knative.dev/net-istio/pkg/reconciler/secretmetadata"
+	"knative.dev/net-istio/pkg/reconciler/snapshot"
+	"knative.dev/net-istio/pkg/reconciler/tuning"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkingclient "knative.dev/networking/pkg/client/injection/client"
 	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
 	netconfig "knative.dev/networking/pkg/config"
@@ -43,14 +58,35 @@ import (
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
 	"knative.dev/pkg/reconciler"
+	"knative.dev/pkg/system"
 
 	v1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
-const controllerAgentName = "istio-ingress-controller"
+const (
+	controllerAgentName = "istio-ingress-controller"
+
+	// eventSourceName is the source of the events of the reconciler, the one given by the
+	// generated constructor of the controller.
+	eventSourceName = "ingress-controller"
+
+	// workQueueName is the name of the work queue, the one given by the generated
+	// constructor of the controller, which its metrics are reported under.
+	workQueueName = "knative.dev.net-istio.pkg.reconciler.ingress.Reconciler"
+
+	virtualServiceByIngressIndex = "virtualServiceByIngress"
+)
 
 type ingressOption func(*Reconciler)
 
@@ -62,6 +98,19 @@ func NewController(
 	return newControllerWithOptions(ctx, cmw)
 }
 
+// NewControllerWithStatusManager is NewController with the readiness of the Ingresses
+// reported by the given status manager instead of the prober of the gateways, e.g. to
+// drive the reconciler against fake clients.
+func NewControllerWithStatusManager(
+	ctx context.Context,
+	cmw configmap.Watcher,
+	statusManager status.Manager,
+) *controller.Impl {
+	return newControllerWithOptions(ctx, cmw, func(r *Reconciler) {
+		r.statusManager = statusManager
+	})
+}
+
 // AnnotateLoggerWithName names the logger in the context with the supplied name
 //
 // This is a stop gap until the generated reconcilers can do this
@@ -84,36 +133,121 @@ func newControllerWithOptions(
 	logger := logging.FromContext(ctx)
 	virtualServiceInformer := virtualserviceinformer.Get(ctx)
 	destinationRuleInformer := destinationruleinformer.Get(ctx)
+	authorizationPolicyInformer := authorizationpolicyinformer.Get(ctx)
 	gatewayInformer := gatewayinformer.Get(ctx)
-	secretInformer := getSecretInformer(ctx)
+	secretInformer, secretLister := getSecretInformerAndLister(ctx)
 	serviceInformer := serviceinformer.Get(ctx)
 	ingressInformer := ingressinformer.Get(ctx)
 
+	if err := virtualServiceInformer.Informer().AddIndexers(cache.Indexers{
+		virtualServiceByIngressIndex: indexVirtualServiceByIngress,
+	}); err != nil {
+		logger.Fatalw("Failed to add the VirtualService index", zap.Error(err))
+	}
+
 	c := &Reconciler{
-		kubeclient:            kubeclient.Get(ctx),
-		istioClientSet:        istioclient.Get(ctx),
-		virtualServiceLister:  virtualServiceInformer.Lister(),
-		destinationRuleLister: destinationRuleInformer.Lister(),
-		gatewayLister:         gatewayInformer.Lister(),
-		secretLister:          secretInformer.Lister(),
-		svcLister:             serviceInformer.Lister(),
+		kubeclient:                kubeclient.Get(ctx),
+		hooks:                     hooks.FromContext(ctx),
+		istioClientSet:            istioclient.Get(ctx),
+		virtualServiceLister:      virtualServiceInformer.Lister(),
+		virtualServiceIndexer:     virtualServiceInformer.Informer().GetIndexer(),
+		destinationRuleLister:     destinationRuleInformer.Lister(),
+		authorizationPolicyLister: authorizationPolicyInformer.Lister(),
+		remoteClusters:            remotecluster.NewProvider(kubeclient.Get(ctx)),
+		gatewayLister:             gatewayInformer.Lister(),
+		secretLister:              secretLister,
+		svcLister:                 serviceInformer.Lister(),
+		istioAPIBackoff:           newIstioAPIBackoff(),
 	}
-	myFilterFunc := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, netconfig.IstioIngressClassName, true)
 
-	impl := ingressreconciler.NewImpl(ctx, c, netconfig.IstioIngressClassName, func(impl *controller.Impl) controller.Options {
-		configsToResync := []interface{}{
-			&config.Istio{},
-			&netconfig.Config{},
+	// The optional Istio APIs are only watched, and so the features relying on them
+	// only enabled, when the mesh serves them at startup. The controller must be
+	// restarted to pick up the ones installed afterwards.
+	detector := capabilities.NewDetector(istioclient.Get(ctx).Discovery())
+	if err := detector.Refresh(); err != nil {
+		logger.Fatalw("Failed to detect the served Istio APIs", zap.Error(err))
+	}
+	optionalFactory := istioinformers.NewSharedInformerFactory(istioclient.Get(ctx), controller.GetResyncPeriod(ctx))
+	var envoyFilterInformer, requestAuthenticationInformer, telemetryInformer, wasmPluginInformer cache.SharedIndexInformer
+	if detector.Supports(capabilities.EnvoyFilter) {
+		informer := optionalFactory.Networking().V1alpha3().EnvoyFilters()
+		envoyFilterInformer, c.envoyFilterLister = informer.Informer(), informer.Lister()
+	}
+	if detector.Supports(capabilities.RequestAuthentication) {
+		informer := optionalFactory.Security().V1beta1().RequestAuthentications()
+		requestAuthenticationInformer, c.requestAuthenticationLister = informer.Informer(), informer.Lister()
+	}
+	if detector.Supports(capabilities.Telemetry) {
+		informer := optionalFactory.Telemetry().V1alpha1().Telemetries()
+		telemetryInformer, c.telemetryLister = informer.Informer(), informer.Lister()
+	}
+	if detector.Supports(capabilities.WasmPlugin) {
+		informer := optionalFactory.Extensions().V1alpha1().WasmPlugins()
+		wasmPluginInformer, c.wasmPluginLister = informer.Informer(), informer.Lister()
+	}
+	// The EnvoyFilter templates and the WasmPlugin registrations live in the system
+	// namespace, whose ConfigMaps are the only ones watched.
+	systemFactory := informers.NewSharedInformerFactoryWithOptions(kubeclient.Get(ctx), controller.GetResyncPeriod(ctx),
+		informers.WithNamespace(system.Namespace()))
+	var configMapInformer cache.SharedIndexInformer
+	if c.envoyFilterLister != nil || c.wasmPluginLister != nil {
+		informer := systemFactory.Core().V1().ConfigMaps()
+		configMapInformer, c.configMapLister = informer.Informer(), informer.Lister()
+	}
+
+	// The controller is only ready once the gateways, the Istio APIs and the permissions it
+	// relies on are verified, so that a broken installation is reported once at startup.
+	if preflightStatus := preflight.FromContext(ctx); preflightStatus != nil {
+		controlPlaneKubeClient := kubeclient.Get(ctx)
+		if cp := remotecluster.ControlPlaneFromContext(ctx); cp != nil {
+			controlPlaneKubeClient = cp.KubeClient
 		}
-		resyncIngressesOnConfigChange := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
-			impl.FilteredGlobalResync(myFilterFunc, ingressInformer.Informer())
-		})
-		configStore := config.NewStore(logger.Named("config-store"), resyncIngressesOnConfigChange)
-		configStore.WatchConfigs(cmw)
-		return controller.Options{
-			ConfigStore:       configStore,
-			PromoteFilterFunc: myFilterFunc,
+		preflightStatus.Start(ctx, preflight.Checks(kubeclient.Get(ctx), istioclient.Get(ctx), controlPlaneKubeClient)...)
+	}
+
+	myFilterFunc := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, netconfig.IstioIngressClassName, true)
+
+	// Global resyncs are spread over the budget, so that large installations do not reconcile
+	// all their Ingresses at once against the API server, the Istio webhook and the prober.
+	// The budget, like the rate limiting of the work queue, is taken from the keys of
+	// config-istio, then from the WORKQUEUE_* and GLOBAL_RESYNC_BUDGET variables of the
+	// controller, then from the defaults.
+	dynamic := tuning.NewDynamic(ctx)
+
+	var impl *controller.Impl
+	var lastIstio *config.Istio
+	configsToResync := []interface{}{
+		&config.Istio{},
+		&netconfig.Config{},
+	}
+	resyncIngressesOnConfigChange := configmap.TypeFilter(configsToResync...)(func(_ string, value interface{}) {
+		if istio, ok := value.(*config.Istio); ok {
+			// The freeze applies to the writes of all the controllers of the process.
+			observer.SetFrozen(ctx, istio.EmergencyFreeze)
+			if err := dynamic.Update(istio.Tuning); err != nil {
+				logger.Errorw("Failed to tune the controller", zap.Error(err))
+			}
+			// Tuning the controller does not change the resources of the Ingresses.
+			prev := lastIstio
+			lastIstio = istio
+			if prev != nil && onlyTuningChanged(prev, istio) {
+				return
+			}
 		}
+		pacedGlobalResync(impl, myFilterFunc, ingressInformer.Informer(), dynamic.GlobalResyncBudget())
+	})
+	configStore := config.NewStore(logger.Named("config-store"), resyncIngressesOnConfigChange)
+	configStore.WatchConfigs(cmw)
+
+	// The generated constructor of the controller always uses the default rate limiter of
+	// the work queue, so the controller is built around the generated reconciler instead.
+	rec := ingressreconciler.NewReconciler(ctx, logger, networkingclient.Get(ctx), ingressInformer.Lister(),
+		newEventRecorder(ctx), c, netconfig.IstioIngressClassName, controller.Options{ConfigStore: configStore})
+	rec = withFilteredPromotion(tuning.WithNamespaceOwnership(ctx, rec), ingressInformer.Informer().GetStore(), myFilterFunc)
+	impl = controller.NewContext(ctx, withPacedPromotion(rec, dynamic.GlobalResyncBudget), controller.ControllerOptions{
+		WorkQueueName: workQueueName,
+		Logger:        logger.With(zap.String(logkey.Kind, "networking.internal.knative.dev.Ingress")),
+		RateLimiter:   dynamic,
 	})
 
 	ingressInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
@@ -126,40 +260,127 @@ func newControllerWithOptions(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	// Rebuild the resources of all the ready Ingresses at once when the Istio resources
+	// are wiped, e.g. by reinstalling the Istio CRDs.
+	readoption := newReadoptionDetector(logger.Named("readoption"), ingressInformer.Lister(),
+		virtualServiceInformer.Informer().GetIndexer(), myFilterFunc, impl.EnqueueKey)
+	virtualServiceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: readoption.Trigger,
+	})
+	go readoption.Run(ctx)
+
+	// Clean up the resources of the Ingresses moved to another ingress class once the
+	// replacing implementation serves them.
+	handover := newHandover(logger.Named("handover"), ingressInformer.Lister(),
+		virtualServiceInformer.Informer().GetIndexer(), myFilterFunc, configStore.ToContext, c.handOver)
+	ingressInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool { return !myFilterFunc(obj) },
+		Handler:    controller.HandleAll(handover.Enqueue),
+	})
+	go handover.Run(ctx)
+
 	destinationRuleInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: controller.FilterController(&v1alpha1.Ingress{}),
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
-	endpointsInformer := endpointsinformer.Get(ctx)
-	podInformer := podinformer.Get(ctx)
+	authorizationPolicyInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&v1alpha1.Ingress{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	if telemetryInformer != nil {
+		telemetryInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: controller.FilterController(&v1alpha1.Ingress{}),
+			Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+		})
+	}
+
+	// EnvoyFilters, WasmPlugins, and the RequestAuthentications and AuthorizationPolicies
+	// enforcing JWTs, live next to the gateways, so they cannot be owned by their Ingress.
+	gatewayResourceHandler := cache.FilteringResourceEventHandler{
+		FilterFunc: reconciler.LabelExistsFilterFunc(resources.IngressNamespaceLabelKey),
+		Handler:    controller.HandleAll(impl.EnqueueLabelOfNamespaceScopedResource(resources.IngressNamespaceLabelKey, networking.IngressLabelKey)),
+	}
+	for _, informer := range []cache.SharedIndexInformer{envoyFilterInformer, wasmPluginInformer, requestAuthenticationInformer} {
+		if informer != nil {
+			informer.AddEventHandler(gatewayResourceHandler)
+		}
+	}
+	authorizationPolicyInformer.Informer().AddEventHandler(gatewayResourceHandler)
+
+	// The gateways, with the Secrets they serve and the endpoints being probed, live in the
+	// cluster running istiod, which is not necessarily this one.
+	endpointsLister := endpointsinformer.Get(ctx).Lister()
+	gatewayServiceLister := serviceInformer.Lister()
+	podInformer := podinformer.Get(ctx).Informer()
+	nodeClient := kubeclient.Get(ctx)
+	var gatewaySecretInformer cache.SharedIndexInformer
+	var controlPlaneFactory informers.SharedInformerFactory
+	if cp := remotecluster.ControlPlaneFromContext(ctx); cp != nil {
+		c.controlPlane, controlPlaneFactory, gatewaySecretInformer = newControlPlaneClients(ctx, cp)
+		endpointsLister = controlPlaneFactory.Core().V1().Endpoints().Lister()
+		gatewayServiceLister = c.controlPlane.svcLister
+		podInformer = controlPlaneFactory.Core().V1().Pods().Informer()
+		nodeClient = cp.KubeClient
+	}
+
 	resyncOnIngressReady := func(ing *v1alpha1.Ingress) {
 		impl.EnqueueKey(types.NamespacedName{Namespace: ing.GetNamespace(), Name: ing.GetName()})
 	}
-	statusProber := status.NewProber(
+	probeTargetLister := newGatewayPodTargetLister(
+		logger.Named("probe-lister"),
+		gatewayInformer.Lister(),
+		endpointsLister,
+		gatewayServiceLister,
+		newZoneLookup(nodeClient, ctx.Done()))
+	c.listProbeZones = probeTargetLister.ListProbeZones
+	statusProber := probing.NewProber(
 		logger.Named("status-manager"),
-		NewProbeTargetLister(
-			logger.Named("probe-lister"),
-			gatewayInformer.Lister(),
-			endpointsInformer.Lister(),
-			serviceInformer.Lister()),
-		resyncOnIngressReady)
+		probeTargetLister,
+		resyncOnIngressReady,
+		fips.TLSConfig())
 	c.statusManager = statusProber
 	statusProber.Start(ctx.Done())
 
-	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		// Cancel probing when a Pod is deleted
 		DeleteFunc: statusProber.CancelPodProbing,
 	})
 
-	c.tracker = impl.Tracker
+	trackedReferences := snapshot.NewTracker(impl.Tracker)
+	c.tracker = trackedReferences
+	if dumper := snapshot.FromContext(ctx); dumper != nil {
+		dumper.SetCaches(&snapshot.Caches{
+			Ingresses:       ingressInformer.Lister(),
+			Gateways:        gatewayInformer.Lister(),
+			VirtualServices: virtualServiceInformer.Lister(),
+			Tracker:         trackedReferences,
+		})
+	}
 
-	secretInformer.Informer().AddEventHandler(controller.HandleAll(
+	secretInformer.AddEventHandler(controller.HandleAll(
 		controller.EnsureTypeMeta(
 			c.tracker.OnChanged,
 			corev1.SchemeGroupVersion.WithKind("Secret"),
 		),
 	))
+	if configMapInformer != nil {
+		configMapInformer.AddEventHandler(controller.HandleAll(
+			controller.EnsureTypeMeta(
+				c.tracker.OnChanged,
+				corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+			),
+		))
+	}
+	if gatewaySecretInformer != nil {
+		gatewaySecretInformer.AddEventHandler(controller.HandleAll(
+			controller.EnsureTypeMeta(
+				c.tracker.OnChanged,
+				corev1.SchemeGroupVersion.WithKind("Secret"),
+			),
+		))
+	}
 
 	gatewayInformer.Informer().AddEventHandler(controller.HandleAll(
 		controller.EnsureTypeMeta(
@@ -168,6 +389,23 @@ func newControllerWithOptions(
 		),
 	))
 
+	// The ports of the backends given by name are resolved through their Services.
+	serviceInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(
+			c.tracker.OnChanged,
+			corev1.SchemeGroupVersion.WithKind("Service"),
+		),
+	))
+
+	// Repair the Gateways edited or deleted out of band right away rather than on the next
+	// resync: the per-Ingress Gateways are owned by their Ingress, while the servers of the
+	// shared Gateways are attributed to their Ingress by their port names.
+	gatewayInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.FilterController(&v1alpha1.Ingress{}),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+	gatewayInformer.Informer().AddEventHandler(serverOwnersHandler(impl.EnqueueKey))
+
 	ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		// Cancel probing when a Ingress is deleted
 		DeleteFunc: combineFunc(
@@ -176,12 +414,84 @@ func newControllerWithOptions(
 		),
 	})
 
+	// These informers are not injected, so sharedmain does not start them.
+	optionalFactory.Start(ctx.Done())
+	systemFactory.Start(ctx.Done())
+	for informer, synced := range optionalFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logger.Fatalf("Failed to sync the %v informer", informer)
+		}
+	}
+	for informer, synced := range systemFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logger.Fatalf("Failed to sync the %v informer", informer)
+		}
+	}
+	if controlPlaneFactory != nil {
+		controlPlaneFactory.Start(ctx.Done())
+		go gatewaySecretInformer.Run(ctx.Done())
+		if !cache.WaitForCacheSync(ctx.Done(), gatewaySecretInformer.HasSynced) {
+			logger.Fatal("Failed to sync the Secrets of the Istio control plane cluster")
+		}
+		for informer, synced := range controlPlaneFactory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				logger.Fatalf("Failed to sync the %v informer of the Istio control plane cluster", informer)
+			}
+		}
+	}
+
 	for _, opt := range opts {
 		opt(c)
 	}
 	return impl
 }
 
+// indexVirtualServiceByIngress indexes VirtualServices by the namespaced name
+// of the Ingress they were created for.
+func indexVirtualServiceByIngress(obj interface{}) ([]string, error) {
+	vs, ok := obj.(*v1beta1.VirtualService)
+	if !ok {
+		return nil, nil
+	}
+	ing, ok := vs.Labels[networking.IngressLabelKey]
+	if !ok {
+		return nil, nil
+	}
+	return []string{vs.Namespace + "/" + ing}, nil
+}
+
+// serverOwnersHandler enqueues the Ingresses whose servers were added, removed or changed
+// on an updated Gateway, and the Ingresses with servers on a deleted Gateway.
+func serverOwnersHandler(enqueue func(types.NamespacedName)) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldGateway, ok := oldObj.(*v1beta1.Gateway)
+			if !ok {
+				return
+			}
+			newGateway, ok := newObj.(*v1beta1.Gateway)
+			if !ok {
+				return
+			}
+			for _, key := range resources.ChangedServerOwners(oldGateway.Spec.Servers, newGateway.Spec.Servers) {
+				enqueue(key)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			gateway, ok := obj.(*v1beta1.Gateway)
+			if !ok {
+				return
+			}
+			for _, key := range resources.ChangedServerOwners(gateway.Spec.Servers, nil) {
+				enqueue(key)
+			}
+		},
+	}
+}
+
 func combineFunc(functions ...func(interface{})) func(interface{}) {
 	return func(obj interface{}) {
 		for _, f := range functions {
@@ -190,7 +500,64 @@ func combineFunc(functions ...func(interface{})) func(interface{}) {
 	}
 }
 
-func getSecretInformer(ctx context.Context) v1.SecretInformer {
+// newControlPlaneClients returns the clients and listers of the cluster running istiod,
+// along with the informer factory and the Secret informer backing them, which still have
+// to be started.
+func newControlPlaneClients(ctx context.Context, cp *remotecluster.ControlPlane) (*controlPlaneClients, informers.SharedInformerFactory, cache.SharedIndexInformer) {
+	resync := controller.GetResyncPeriod(ctx)
+	factory := informers.NewSharedInformerFactory(cp.KubeClient, resync)
+	secretInformer := coreinformers.NewFilteredSecretInformer(cp.KubeClient, metav1.NamespaceAll, resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, func(opts *metav1.ListOptions) {
+			if informerfiltering.ShouldFilterByCertificateUID() {
+				opts.LabelSelector = networking.CertificateUIDLabelKey
+			}
+		})
+	return &controlPlaneClients{
+		kubeclient:   cp.KubeClient,
+		secretLister: corev1listers.NewSecretLister(secretInformer.GetIndexer()),
+		svcLister:    factory.Core().V1().Services().Lister(),
+	}, factory, secretInformer
+}
+
+func getSecretInformerAndLister(ctx context.Context) (cache.SharedIndexInformer, corev1listers.SecretLister) {
+	if secretmetadata.IsEnabled(ctx) {
+		informer := secretmetadata.Get(ctx).Informer()
+		lister := secretmetadata.NewLister(ctx, informer.GetIndexer(), kubeclient.Get(ctx))
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: lister.Invalidate,
+			DeleteFunc: lister.Forget,
+		})
+		return informer, lister
+	}
 	untyped := ctx.Value(filteredFactory.LabelKey{}) // This should always be not nil and have exactly one selector
-	return secretfilteredinformer.Get(ctx, untyped.([]string)[0])
+	informer := secretfilteredinformer.Get(ctx, untyped.([]string)[0])
+	return informer.Informer(), informer.Lister()
+}
+
+// newEventRecorder returns the event recorder of the context, or one recording the events
+// to the API server, like the generated constructor of the controller.
+func newEventRecorder(ctx context.Context) record.EventRecorder {
+	if recorder := controller.GetEventRecorder(ctx); recorder != nil {
+		return recorder
+	}
+	broadcaster := record.NewBroadcaster()
+	watches := []watch.Interface{
+		broadcaster.StartLogging(logging.FromContext(ctx).Named("event-broadcaster").Infof),
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclient.Get(ctx).CoreV1().Events("")}),
+	}
+	go func() {
+		<-ctx.Done()
+		for _, w := range watches {
+			w.Stop()
+		}
+	}()
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: eventSourceName})
+}
+
+// onlyTuningChanged returns whether the configurations only differ in the tuning of the
+// controller.
+func onlyTuningChanged(prev, cur *config.Istio) bool {
+	prev, cur = prev.DeepCopy(), cur.DeepCopy()
+	prev.Tuning, cur.Tuning = tuning.Settings{}, tuning.Settings{}
+	return equality.Semantic.DeepEqual(prev, cur)
 }
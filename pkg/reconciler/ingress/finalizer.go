@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// stuckFinalizerAfter is how long an Ingress may fail to be finalized before it is
+	// reported as stuck in deletion.
+	stuckFinalizerAfter = 5 * time.Minute
+
+	finalizerStuck = "FinalizerStuck"
+	cleanupSkipped = "CleanupSkipped"
+	forceFinalized = "ForceFinalized"
+)
+
+// cleanupStep is a step of the finalization of an Ingress.
+type cleanupStep struct {
+	// what describes what the step cleans up, in the events reporting it failed.
+	what string
+	run  func() error
+}
+
+// cleanupSteps returns the steps cleaning up what the Ingress left outside of the resources
+// it owns, which are garbage collected along with it.
+func (r *Reconciler) cleanupSteps(ctx context.Context, ing *v1alpha1.Ingress) []cleanupStep {
+	istiocfg := config.FromContext(ctx).Istio
+	var steps []cleanupStep
+	for _, gws := range [][]config.Gateway{istiocfg.IngressGateways, istiocfg.LocalGateways} {
+		for _, gw := range gws {
+			gw := gw
+			steps = append(steps, cleanupStep{
+				what: "the servers of Gateway " + gw.QualifiedName(),
+				run: func() error {
+					return r.reconcileIngressServers(ctx, ing, gw, []*istiov1beta1.Server{})
+				},
+			})
+		}
+	}
+	steps = append(steps, cleanupStep{
+		what: "the EnvoyFilters",
+		run: func() error {
+			return r.deleteStaleEnvoyFilters(ctx, ing, sets.New[string]())
+		},
+	}, cleanupStep{
+		what: "the gateway authorization",
+		run: func() error {
+			return r.deleteStaleGatewayAuthorization(ctx, ing, sets.New[string]())
+		},
+	}, cleanupStep{
+		what: "the WasmPlugins",
+		run: func() error {
+			return r.deleteStaleWasmPlugins(ctx, ing, sets.New[string]())
+		},
+	})
+	if r.controlPlane != nil {
+		// The Istio resources are not garbage collected along with the Ingress, as it
+		// lives in another cluster.
+		steps = append(steps, cleanupStep{
+			what: "the Gateways and VirtualServices of the control plane",
+			run: func() error {
+				return deleteIngressIstioResources(ctx, r.istioClientSet, ing, sets.New[string](), sets.New[string]())
+			},
+		})
+	}
+	return append(steps, cleanupStep{
+		what: "the remote clusters",
+		run: func() error {
+			return r.reconcileRemoteClusters(ctx, ing, &remoteResources{})
+		},
+	}, cleanupStep{
+		what: "the certificate Secrets",
+		run: func() error {
+			return r.cleanupCertificateSecrets(ctx, ing)
+		},
+	})
+}
+
+// forceFinalize returns whether the Ingress has been in deletion for longer than the
+// force-finalize-after delay, in which case the steps failing to clean it up are skipped.
+func forceFinalize(ctx context.Context, ing *v1alpha1.Ingress) bool {
+	after := config.FromContext(ctx).Istio.ForceFinalizeAfter
+	return after > 0 && deletingFor(ing) >= after
+}
+
+func deletingFor(ing *v1alpha1.Ingress) time.Duration {
+	if ing.DeletionTimestamp == nil {
+		return 0
+	}
+	return time.Since(ing.DeletionTimestamp.Time)
+}
+
+// finalizeFailed reports the Ingress as stuck in deletion once it has failed to be
+// finalized for long enough, and returns the error failing it.
+func finalizeFailed(ctx context.Context, ing *v1alpha1.Ingress, step cleanupStep, err error) error {
+	if deletingFor(ing) >= stuckFinalizerAfter {
+		hint := "Set force-finalize-after in config-istio to skip the cleanup steps failing for longer than it."
+		if after := config.FromContext(ctx).Istio.ForceFinalizeAfter; after > 0 {
+			hint = "The failing cleanup steps are skipped after " + after.String() + " in deletion."
+		}
+		controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, finalizerStuck,
+			"Failed to clean up %s since the deletion at %s: %v. %s", step.what, deletedAt(ing), err, hint)
+	}
+	return err
+}
+
+// reportForceFinalized reports the cleanup steps skipped to finalize the Ingress.
+func reportForceFinalized(ctx context.Context, ing *v1alpha1.Ingress, skipped []string) {
+	logging.FromContext(ctx).Warnw("Finalized the Ingress skipping the failed cleanup steps", "skipped", skipped)
+	controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, forceFinalized,
+		"Removed the finalizer of the Ingress in deletion since %s, leaving behind %s",
+		deletedAt(ing), strings.Join(skipped, ", "))
+}
+
+func deletedAt(ing *v1alpha1.Ingress) string {
+	return ing.DeletionTimestamp.UTC().Format(time.RFC3339)
+}
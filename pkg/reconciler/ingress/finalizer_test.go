@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/pkg/system"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func TestReconcile_StuckFinalizer(t *testing.T) {
+	gatewayNotFound := `failed to get Gateway: gateway.networking.istio.io "` + config.KnativeIngressGateway + `" not found`
+	gatewayServers := "the servers of Gateway " + system.Namespace() + "/" + config.KnativeIngressGateway
+
+	stuck := TableTest{{
+		Name:                    "report the Ingress stuck in deletion as the shared gateway was deleted",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "FinalizerStuck",
+				"Failed to clean up %s since the deletion at 2001-09-09T01:46:40Z: %s. "+
+					"Set force-finalize-after in config-istio to skip the cleanup steps failing for longer than it.",
+				gatewayServers, gatewayNotFound),
+			Eventf(corev1.EventTypeWarning, "InternalError", gatewayNotFound),
+		},
+		WantErr: true,
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}}
+	stuck.Test(t, MakeFactory(externalDomainTLSReconciler))
+
+	forced := TableTest{{
+		Name:                    "skip the cleanup of the deleted shared gateway after force-finalize-after",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ""),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "CleanupSkipped", "Skipped cleaning up %s: %s", gatewayServers, gatewayNotFound),
+			Eventf(corev1.EventTypeWarning, "ForceFinalized",
+				"Removed the finalizer of the Ingress in deletion since 2001-09-09T01:46:40Z, leaving behind %s", gatewayServers),
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}}
+	cfg := externalDomainTLSConfig()
+	cfg.Istio.ForceFinalizeAfter = time.Hour
+	forced.Test(t, MakeFactory(externalDomainTLSReconcilerWithConfig(cfg)))
+}
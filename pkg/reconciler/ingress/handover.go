@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+)
+
+// handover cleans up the resources of the Ingresses moved to another ingress class once
+// the replacing implementation serves them, so that the move causes no downtime. The
+// generated reconciler ignores these Ingresses, so they are processed on their own queue.
+type handover struct {
+	logger                *zap.SugaredLogger
+	ingressLister         networkinglisters.IngressLister
+	virtualServiceIndexer cache.Indexer
+	filter                func(interface{}) bool
+	// toContext attaches the configuration to the context.
+	toContext func(context.Context) context.Context
+	cleanup   func(context.Context, *v1alpha1.Ingress) error
+
+	queue workqueue.RateLimitingInterface
+}
+
+func newHandover(logger *zap.SugaredLogger, ingressLister networkinglisters.IngressLister, virtualServiceIndexer cache.Indexer,
+	filter func(interface{}) bool, toContext func(context.Context) context.Context, cleanup func(context.Context, *v1alpha1.Ingress) error) *handover {
+	return &handover{
+		logger:                logger,
+		ingressLister:         ingressLister,
+		virtualServiceIndexer: virtualServiceIndexer,
+		filter:                filter,
+		toContext:             toContext,
+		cleanup:               cleanup,
+		queue: workqueue.NewRateLimitingQueueWithConfig(workqueue.DefaultControllerRateLimiter(),
+			workqueue.RateLimitingQueueConfig{Name: "IngressHandover"}),
+	}
+}
+
+// Enqueue schedules the handover of the Ingress, e.g. when its status changes.
+func (h *handover) Enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		h.logger.Warnw("Failed to get the key of the Ingress", zap.Error(err))
+		return
+	}
+	h.queue.Add(key)
+}
+
+// Run processes the handovers until the context is done.
+func (h *handover) Run(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		h.queue.ShutDown()
+	}()
+	for {
+		item, shutdown := h.queue.Get()
+		if shutdown {
+			return
+		}
+		key := item.(string)
+		if err := h.process(ctx, key); err != nil {
+			h.logger.Warnw("Failed to hand over Ingress "+key, zap.Error(err))
+			h.queue.AddRateLimited(key)
+		} else {
+			h.queue.Forget(key)
+		}
+		h.queue.Done(item)
+	}
+}
+
+// process cleans up the resources of the Ingress when it moved to another ingress class
+// whose implementation reports it ready.
+func (h *handover) process(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil
+	}
+	ing, err := h.ingressLister.Ingresses(namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		// The resources owned by the Ingress are garbage collected along with it.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if h.filter(ing) || ing.DeletionTimestamp != nil {
+		return nil
+	}
+	nn := types.NamespacedName{Namespace: namespace, Name: name}
+	if vses, err := h.virtualServiceIndexer.ByIndex(virtualServiceByIngressIndex, nn.String()); err != nil || len(vses) == 0 {
+		// Nothing left to clean up.
+		return err
+	}
+
+	ctx = h.toContext(ctx)
+	istio := config.FromContext(ctx).Istio
+	if !istio.EnableMigrationHandover || !isServedByReplacement(ing, istio) {
+		return nil
+	}
+	h.logger.Infof("Ingress %s is served by its new ingress class %q, cleaning up its Istio resources",
+		key, ing.GetAnnotations()[networking.IngressClassAnnotationKey])
+	return h.cleanup(ctx, ing)
+}
+
+// isServedByReplacement returns whether the implementation of the new ingress class of
+// the Ingress reports it ready, on load balancers other than the configured gateways.
+func isServedByReplacement(ing *v1alpha1.Ingress, istio *config.Istio) bool {
+	if !ing.IsReady() || ing.Status.ObservedGeneration != ing.Generation {
+		return false
+	}
+
+	gatewayURLs := sets.New[string]()
+	for _, gws := range [][]config.Gateway{istio.IngressGateways, istio.LocalGateways} {
+		for _, gw := range gws {
+			gatewayURLs.Insert(gw.ServiceURL)
+		}
+	}
+	if istio.AutoPassthroughGateway != "" {
+		gatewayURLs.Insert(istio.AutoPassthroughGateway)
+	}
+
+	// Only the load balancers of the replacement tell that it serves the Ingress, as
+	// the mesh-only ones are reported by the Istio implementation too.
+	replaced := false
+	for _, lb := range []*v1alpha1.LoadBalancerStatus{ing.Status.PublicLoadBalancer, ing.Status.PrivateLoadBalancer} {
+		if lb == nil {
+			continue
+		}
+		for _, lbi := range lb.Ingress {
+			if gatewayURLs.Has(lbi.DomainInternal) {
+				return false
+			}
+			if !lbi.MeshOnly {
+				replaced = true
+			}
+		}
+	}
+	return replaced
+}
+
+// handOver deletes the resources programmed for the Ingress, which is now served by the
+// implementation of another ingress class.
+func (r *Reconciler) handOver(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if err := r.FinalizeKind(ctx, ing); err != nil {
+		return err
+	}
+	if r.controlPlane != nil {
+		// FinalizeKind already deleted them from the control plane cluster.
+		return nil
+	}
+	return deleteIngressIstioResources(ctx, r.istioClientSet, ing, sets.New[string](), sets.New[string]())
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/reconciler"
+)
+
+const replacementClass = "gateway-api.ingress.networking.knative.dev"
+
+func handoverIngress(class string, lb *v1alpha1.LoadBalancerStatus) *v1alpha1.Ingress {
+	ing := readoptionIngress("ing", lb != nil)
+	ing.Annotations = map[string]string{networking.IngressClassAnnotationKey: class}
+	ing.Status.PublicLoadBalancer = lb
+	return ing
+}
+
+func handoverLB(domains ...string) *v1alpha1.LoadBalancerStatus {
+	lb := &v1alpha1.LoadBalancerStatus{}
+	for _, domain := range domains {
+		lb.Ingress = append(lb.Ingress, v1alpha1.LoadBalancerIngressStatus{DomainInternal: domain})
+	}
+	return lb
+}
+
+func TestHandover(t *testing.T) {
+	istioGateway := ReconcilerTestConfig().Istio.IngressGateways[0].ServiceURL
+
+	tests := []struct {
+		name        string
+		ing         *v1alpha1.Ingress
+		noVSes      bool
+		disabled    bool
+		wantCleanup bool
+	}{{
+		name:        "served by the replacement",
+		ing:         handoverIngress(replacementClass, handoverLB("envoy-gateway.envoy-gateway-system.svc.cluster.local")),
+		wantCleanup: true,
+	}, {
+		name:     "handover disabled",
+		ing:      handoverIngress(replacementClass, handoverLB("envoy-gateway.envoy-gateway-system.svc.cluster.local")),
+		disabled: true,
+	}, {
+		name: "still Istio class",
+		ing:  handoverIngress(netconfig.IstioIngressClassName, handoverLB("envoy-gateway.envoy-gateway-system.svc.cluster.local")),
+	}, {
+		name: "replacement not ready yet",
+		ing:  handoverIngress(replacementClass, nil),
+	}, {
+		name: "still reported on the Istio gateway",
+		ing:  handoverIngress(replacementClass, handoverLB(istioGateway)),
+	}, {
+		name: "only reported mesh only",
+		ing: handoverIngress(replacementClass, &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{{MeshOnly: true}},
+		}),
+	}, {
+		name:   "already cleaned up",
+		ing:    handoverIngress(replacementClass, handoverLB("envoy-gateway.envoy-gateway-system.svc.cluster.local")),
+		noVSes: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ingressIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			ingressIndexer.Add(test.ing)
+			vsIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+				virtualServiceByIngressIndex: indexVirtualServiceByIngress,
+			})
+			if !test.noVSes {
+				vsIndexer.Add(readoptionVirtualService(test.ing.Name))
+			}
+
+			cfg := ReconcilerTestConfig()
+			cfg.Istio.EnableMigrationHandover = !test.disabled
+			cleanedUp := false
+			h := newHandover(logtesting.TestLogger(t), networkinglisters.NewIngressLister(ingressIndexer), vsIndexer,
+				reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, netconfig.IstioIngressClassName, true),
+				func(ctx context.Context) context.Context { return config.ToContext(ctx, cfg) },
+				func(context.Context, *v1alpha1.Ingress) error {
+					cleanedUp = true
+					return nil
+				})
+
+			if err := h.process(context.Background(), testNS+"/"+test.ing.Name); err != nil {
+				t.Fatal("process() =", err)
+			}
+			if cleanedUp != test.wantCleanup {
+				t.Errorf("Cleaned up = %v, want %v", cleanedUp, test.wantCleanup)
+			}
+		})
+	}
+}
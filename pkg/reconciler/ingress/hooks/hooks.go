@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooks lets the builds of net-istio mutate the VirtualServices and Gateways
+// generated for the Ingresses before they are applied, e.g. to add the labels of a
+// platform, without patching the reconciler. The hooks are attached to the context the
+// controller is started with, e.g. in a copy of cmd/controller:
+//
+//	ctx = hooks.WithHooks(ctx, hooks.Funcs{
+//		VirtualService: func(_ context.Context, _ *v1alpha1.Ingress, vs *v1beta1.VirtualService) error {
+//			vs.Labels["platform.example.com/team"] = "networking"
+//			return nil
+//		},
+//	})
+//
+// The hooks run on every reconciliation and must be deterministic: the resources they
+// return are compared with the ones of the cluster, which are updated on any difference.
+package hooks
+
+import (
+	"context"
+	"slices"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+// Hook mutates the Istio resources generated for an Ingress. An error fails the
+// reconciliation of the Ingress, which is retried.
+type Hook interface {
+	// MutateVirtualService mutates a VirtualService of the Ingress.
+	MutateVirtualService(ctx context.Context, ing *v1alpha1.Ingress, vs *v1beta1.VirtualService) error
+
+	// MutateGateway mutates a Gateway the Ingress is exposed on. The Gateways of the
+	// wildcard certificates are shared by the Ingresses referencing them, so their
+	// mutations must not depend on the Ingress.
+	MutateGateway(ctx context.Context, ing *v1alpha1.Ingress, gateway *v1beta1.Gateway) error
+}
+
+// Funcs implements Hook with functions. The nil ones leave the resources unchanged.
+type Funcs struct {
+	VirtualService func(ctx context.Context, ing *v1alpha1.Ingress, vs *v1beta1.VirtualService) error
+	Gateway        func(ctx context.Context, ing *v1alpha1.Ingress, gateway *v1beta1.Gateway) error
+}
+
+var _ Hook = Funcs{}
+
+// MutateVirtualService implements Hook.
+func (f Funcs) MutateVirtualService(ctx context.Context, ing *v1alpha1.Ingress, vs *v1beta1.VirtualService) error {
+	if f.VirtualService == nil {
+		return nil
+	}
+	return f.VirtualService(ctx, ing, vs)
+}
+
+// MutateGateway implements Hook.
+func (f Funcs) MutateGateway(ctx context.Context, ing *v1alpha1.Ingress, gateway *v1beta1.Gateway) error {
+	if f.Gateway == nil {
+		return nil
+	}
+	return f.Gateway(ctx, ing, gateway)
+}
+
+// Hooks are hooks running in order.
+type Hooks []Hook
+
+type hooksKey struct{}
+
+// WithHooks returns the passed context with the hooks attached, after the ones already
+// attached to it.
+func WithHooks(ctx context.Context, hooks ...Hook) context.Context {
+	// Clipped so that the contexts the passed one is derived from keep their hooks.
+	return context.WithValue(ctx, hooksKey{}, append(slices.Clip(FromContext(ctx)), hooks...))
+}
+
+// FromContext returns the hooks attached to the context.
+func FromContext(ctx context.Context) Hooks {
+	hooks, _ := ctx.Value(hooksKey{}).(Hooks)
+	return hooks
+}
+
+// MutateVirtualServices runs the hooks on the VirtualServices of the Ingress.
+func (h Hooks) MutateVirtualServices(ctx context.Context, ing *v1alpha1.Ingress, vses []*v1beta1.VirtualService) error {
+	if len(h) == 0 {
+		return nil
+	}
+	for _, vs := range vses {
+		for _, hook := range h {
+			if err := hook.MutateVirtualService(ctx, ing, vs); err != nil {
+				return err
+			}
+		}
+		// The spec hash of the VirtualServices is recorded when they are generated.
+		vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+	}
+	return nil
+}
+
+// MutateGateways runs the hooks on the Gateways the Ingress is exposed on.
+func (h Hooks) MutateGateways(ctx context.Context, ing *v1alpha1.Ingress, gateways []*v1beta1.Gateway) error {
+	for _, gateway := range gateways {
+		for _, hook := range h {
+			if err := hook.MutateGateway(ctx, ing, gateway); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+var ing = &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ing"}}
+
+func label(value string) Funcs {
+	return Funcs{
+		VirtualService: func(_ context.Context, _ *v1alpha1.Ingress, vs *v1beta1.VirtualService) error {
+			vs.Labels["hook"] += value
+			return nil
+		},
+		Gateway: func(_ context.Context, _ *v1alpha1.Ingress, gateway *v1beta1.Gateway) error {
+			gateway.Labels = map[string]string{"hook": value}
+			return nil
+		},
+	}
+}
+
+func TestWithHooks(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); len(got) != 0 {
+		t.Errorf("FromContext() = %v, want no hooks", got)
+	}
+
+	parent := WithHooks(ctx, label("a"))
+	first := WithHooks(parent, label("b"))
+	second := WithHooks(parent, label("c"))
+	vs := &v1beta1.VirtualService{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}}}
+	if err := FromContext(first).MutateVirtualServices(ctx, ing, []*v1beta1.VirtualService{vs}); err != nil {
+		t.Fatal("MutateVirtualServices() =", err)
+	}
+	if got, want := vs.Labels["hook"], "ab"; got != want {
+		t.Errorf("Hooks ran as %q, want: %q", got, want)
+	}
+	if got := len(FromContext(second)); got != 2 {
+		t.Errorf("FromContext() = %d hooks, want: 2", got)
+	}
+}
+
+func TestMutateVirtualServices(t *testing.T) {
+	vs := &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}},
+		Spec:       istiov1beta1.VirtualService{Hosts: []string{"foo.example.com"}},
+	}
+	vs.Annotations = kaccessor.WithSpecHash(nil, &vs.Spec)
+	before := vs.Annotations[kaccessor.SpecHashAnnotationKey]
+
+	hooks := Hooks{Funcs{
+		VirtualService: func(_ context.Context, _ *v1alpha1.Ingress, vs *v1beta1.VirtualService) error {
+			vs.Spec.Hosts = append(vs.Spec.Hosts, "bar.example.com")
+			return nil
+		},
+	}}
+	if err := hooks.MutateVirtualServices(context.Background(), ing, []*v1beta1.VirtualService{vs}); err != nil {
+		t.Fatal("MutateVirtualServices() =", err)
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == before {
+		t.Error("The spec hash was not updated with the mutated spec")
+	}
+
+	want := errors.New("platform labels are not configured")
+	hooks = Hooks{Funcs{
+		VirtualService: func(context.Context, *v1alpha1.Ingress, *v1beta1.VirtualService) error {
+			return want
+		},
+	}}
+	if err := hooks.MutateVirtualServices(context.Background(), ing, []*v1beta1.VirtualService{vs}); !errors.Is(err, want) {
+		t.Errorf("MutateVirtualServices() = %v, want: %v", err, want)
+	}
+}
+
+func TestMutateGateways(t *testing.T) {
+	gateway := &v1beta1.Gateway{}
+	// The nil functions of Funcs leave the resources unchanged.
+	hooks := Hooks{Funcs{}, label("a")}
+	if err := hooks.MutateGateways(context.Background(), ing, []*v1beta1.Gateway{gateway}); err != nil {
+		t.Fatal("MutateGateways() =", err)
+	}
+	if got, want := gateway.Labels["hook"], "a"; got != want {
+		t.Errorf("Labels[hook] = %q, want: %q", got, want)
+	}
+}
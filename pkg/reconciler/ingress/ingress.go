@@ -20,15 +20,26 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/protobuf/testing/protocmp"
 	pkgnetwork "knative.dev/pkg/network"
 
 	istiov1beta1 "istio.io/api/networking/v1beta1"
+	extensionsv1alpha1 "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	securityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	telemetryv1alpha1 "istio.io/client-go/pkg/apis/telemetry/v1alpha1"
+	extensionslisters "knative.dev/net-istio/pkg/client/istio/listers/extensions/v1alpha1"
+	istiov1alpha3listers "knative.dev/net-istio/pkg/client/istio/listers/networking/v1alpha3"
 	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	securitylisters "knative.dev/net-istio/pkg/client/istio/listers/security/v1beta1"
+	telemetrylisters "knative.dev/net-istio/pkg/client/istio/listers/telemetry/v1alpha1"
 	pkgreconciler "knative.dev/pkg/reconciler"
 	"knative.dev/pkg/tracker"
 
@@ -36,54 +47,94 @@ import (
 	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
 	coreaccessor "knative.dev/net-istio/pkg/reconciler/accessor/core"
 	istioaccessor "knative.dev/net-istio/pkg/reconciler/accessor/istio"
+	"knative.dev/net-istio/pkg/reconciler/fips"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/hooks"
 	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/secretmetadata"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
 	netconfig "knative.dev/networking/pkg/config"
 	"knative.dev/networking/pkg/status"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 const (
 	virtualServiceNotReconciled = "ReconcileVirtualServiceFailed"
 	notReconciledReason         = "ReconcileIngressFailed"
 	notReconciledMessage        = "Ingress reconciliation failed"
+	istioFeatureUnsupported     = "IstioFeatureUnsupported"
+	certificateHostNotCovered   = "CertificateHostNotCovered"
+	secretNamespaceNotAllowed   = "SecretNamespaceNotAllowed"
+	gatewayNotAllowed           = "GatewayNotAllowed"
 )
 
 // Reconciler implements the control loop for the Ingress resources.
 type Reconciler struct {
 	kubeclient kubernetes.Interface
 
-	istioClientSet        istioclientset.Interface
-	virtualServiceLister  istiolisters.VirtualServiceLister
-	destinationRuleLister istiolisters.DestinationRuleLister
-	gatewayLister         istiolisters.GatewayLister
-	secretLister          corev1listers.SecretLister
-	svcLister             corev1listers.ServiceLister
+	istioClientSet              istioclientset.Interface
+	virtualServiceLister        istiolisters.VirtualServiceLister
+	virtualServiceIndexer       cache.Indexer
+	destinationRuleLister       istiolisters.DestinationRuleLister
+	authorizationPolicyLister   securitylisters.AuthorizationPolicyLister
+	requestAuthenticationLister securitylisters.RequestAuthenticationLister
+	envoyFilterLister           istiov1alpha3listers.EnvoyFilterLister
+	telemetryLister             telemetrylisters.TelemetryLister
+	wasmPluginLister            extensionslisters.WasmPluginLister
+	gatewayLister               istiolisters.GatewayLister
+	secretLister                corev1listers.SecretLister
+	svcLister                   corev1listers.ServiceLister
+	// configMapLister lists the ConfigMaps of the system namespace, which hold the
+	// EnvoyFilter templates and the WasmPlugin registrations. Nil when neither
+	// EnvoyFilters nor WasmPlugins are served.
+	configMapLister corev1listers.ConfigMapLister
 
 	tracker tracker.Interface
 
 	statusManager status.Manager
+
+	// listProbeZones lists the zones of the gateway pods probed for an Ingress, reported
+	// with report-zone-readiness. Nil means they are not reported.
+	listProbeZones func(context.Context, *v1alpha1.Ingress) ([]string, error)
+
+	remoteClusters remoteClusterProvider
+	controlPlane   *controlPlaneClients
+
+	// istioAPIBackoff delays the reconciliations of the Ingresses while the Istio APIs
+	// are unavailable.
+	istioAPIBackoff *istioAPIBackoff
+
+	// hooks mutate the generated VirtualServices and Gateways before they are applied.
+	hooks hooks.Hooks
 }
 
 var (
-	_ ingressreconciler.Interface           = (*Reconciler)(nil)
-	_ ingressreconciler.Finalizer           = (*Reconciler)(nil)
-	_ coreaccessor.SecretAccessor           = (*Reconciler)(nil)
-	_ istioaccessor.VirtualServiceAccessor  = (*Reconciler)(nil)
-	_ istioaccessor.DestinationRuleAccessor = (*Reconciler)(nil)
+	_ ingressreconciler.Interface                 = (*Reconciler)(nil)
+	_ ingressreconciler.Finalizer                 = (*Reconciler)(nil)
+	_ coreaccessor.SecretAccessor                 = (*Reconciler)(nil)
+	_ istioaccessor.VirtualServiceAccessor        = (*Reconciler)(nil)
+	_ istioaccessor.DestinationRuleAccessor       = (*Reconciler)(nil)
+	_ istioaccessor.AuthorizationPolicyAccessor   = (*Reconciler)(nil)
+	_ istioaccessor.TelemetryAccessor             = (*Reconciler)(nil)
+	_ istioaccessor.EnvoyFilterAccessor           = (*Reconciler)(nil)
+	_ istioaccessor.RequestAuthenticationAccessor = (*Reconciler)(nil)
+	_ istioaccessor.WasmPluginAccessor            = (*Reconciler)(nil)
 )
 
 // ReconcileKind compares the actual state with the desired, and attempts to
@@ -91,8 +142,19 @@ var (
 // with the current status of the resource.
 func (r *Reconciler) ReconcileKind(ctx context.Context, ingress *v1alpha1.Ingress) pkgreconciler.Event {
 	logger := logging.FromContext(ctx)
+	key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	previous := ingress.Status.DeepCopy()
 
 	reconcileErr := r.reconcileIngress(ctx, ingress)
+	if isIstioAPIUnavailable(reconcileErr) {
+		// Retrying right away would only add load to the struggling API server, and
+		// failing the Ingress would not help as its resources keep serving.
+		logger.Warnw("The Istio APIs are unavailable", zap.Error(reconcileErr))
+		markIstioAPIUnavailable(ctx, ingress, previous, reconcileErr)
+		return controller.NewRequeueAfter(r.istioAPIBackoff.next(key))
+	}
+	r.istioAPIBackoff.reset(key)
+	markIstioAPIAvailable(ingress)
 	if reconcileErr != nil {
 		logger.Errorw("Failed to reconcile Ingress: ", zap.Error(reconcileErr))
 		ingress.Status.MarkIngressNotReady(notReconciledReason, notReconciledMessage)
@@ -111,122 +173,123 @@ func (r *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 	ing.SetDefaults(ctx)
 
 	ing.Status.InitializeConditions()
-	logger.Infof("Reconciling ingress: %#v", ing)
+	// Only identify the Ingress rather than dumping it, so that nothing it carries
+	// ends up in the logs.
+	logger.Infof("Reconciling ingress %s/%s at generation %d", ing.Namespace, ing.Name, ing.Generation)
+
+	markConfigurationSkipped(ing, append(skipUnroutableRules(ing), skippedTLS(ctx, ing)...))
+
+	if unsupported := r.unsupportedFeatures(ing); len(unsupported) > 0 {
+		err := fmt.Errorf("the mesh does not serve the Istio APIs required by %s, the controller must be restarted once they are installed",
+			strings.Join(unsupported, ", "))
+		ing.Status.MarkLoadBalancerFailed(istioFeatureUnsupported, err.Error())
+		// The served APIs are only detected when the controller starts, and it reconciles
+		// all the Ingresses again when restarted.
+		return controller.NewPermanentError(err)
+	}
 
-	defaultGateways, err := resources.GatewaysFromContext(ctx, ing)
+	if err := r.resolveServicePorts(ing); err != nil {
+		return err
+	}
+	aliases, err := resources.ParseHostAliases(ing)
 	if err != nil {
 		return err
 	}
-
-	gatewayNames := map[v1alpha1.IngressVisibility]sets.Set[string]{
-		v1alpha1.IngressVisibilityClusterLocal: sets.New[string](),
-		v1alpha1.IngressVisibilityExternalIP:   sets.New[string](),
+	resources.WithHostAliases(ing, aliases)
+	if err := validateSecretNamespaces(ctx, ing); err != nil {
+		return err
+	}
+	if err := validateGatewayNamespaces(ctx, ing); err != nil {
+		return err
 	}
 
-	for _, gateway := range defaultGateways[v1alpha1.IngressVisibilityClusterLocal] {
-		gatewayNames[v1alpha1.IngressVisibilityClusterLocal].Insert(gateway.QualifiedName())
+	defaultGateways, err := resources.GatewaysFromContext(ctx, ing)
+	if err != nil {
+		return err
 	}
 
-	externalIngressGateways := []*v1beta1.Gateway{}
-	if shouldReconcileExternalDomainTLS(ing) {
-		originSecrets, err := resources.GetSecrets(ing, v1alpha1.IngressVisibilityExternalIP, r.secretLister)
+	originSecrets := map[string]*corev1.Secret{}
+	for _, visibility := range resources.TLSVisibilities(ctx, ing) {
+		secrets, err := resources.GetSecrets(ing, visibility, secretmetadata.WithContext(ctx, r.secretLister))
 		if err != nil {
 			return err
 		}
-		nonWildcardSecrets, wildcardSecrets, err := resources.CategorizeSecrets(originSecrets)
-		if err != nil {
-			return err
-		}
-		targetNonwildcardSecrets, err := resources.MakeSecrets(ctx, nonWildcardSecrets, ing)
-		if err != nil {
-			return err
-		}
-		targetWildcardSecrets, err := resources.MakeWildcardSecrets(ctx, wildcardSecrets, ing)
-		if err != nil {
-			return err
+		if visibility == v1alpha1.IngressVisibilityExternalIP {
+			resources.WithCoveredHostAliases(ing, aliases, secrets)
 		}
-		targetSecrets := make([]*corev1.Secret, 0, len(targetNonwildcardSecrets)+len(targetWildcardSecrets))
-		targetSecrets = append(targetSecrets, targetNonwildcardSecrets...)
-		targetSecrets = append(targetSecrets, targetWildcardSecrets...)
-		if err := r.reconcileCertSecrets(ctx, ing, targetSecrets); err != nil {
-			return err
-		}
-
-		nonWildcardIngressTLS := resources.GetNonWildcardIngressTLS(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP), nonWildcardSecrets)
-		externalIngressGateways, err = resources.MakeIngressTLSGateways(ctx, ing, v1alpha1.IngressVisibilityExternalIP,
-			nonWildcardIngressTLS, nonWildcardSecrets, r.svcLister)
-		if err != nil {
-			return err
-		}
-
-		// For Ingress TLS referencing wildcard certificates, we reconcile a separate Gateway
-		// that will be shared by other Ingresses that reference the
-		// same wildcard host. We need to handle wildcard certificate specially because Istio does
-		// not fully support multiple TLS Servers (or Gateways) share the same certificate.
-		// https://istio.io/docs/ops/common-problems/network-issues/
-		desiredWildcardGateways, err := resources.MakeWildcardTLSGateways(ctx, ing, wildcardSecrets, r.svcLister)
-		if err != nil {
+		if err := r.validateCertificateHosts(ing, visibility, secrets); err != nil {
 			return err
 		}
-		if err := r.reconcileWildcardGateways(ctx, desiredWildcardGateways, ing); err != nil {
-			return err
+		for key, secret := range secrets {
+			originSecrets[key] = secret
 		}
-		gatewayNames[v1alpha1.IngressVisibilityExternalIP].Insert(resources.GetQualifiedGatewayNames(desiredWildcardGateways)...)
 	}
-
-	cfg := config.FromContext(ctx)
-	clusterLocalIngressGateways := []*v1beta1.Gateway{}
-	if cfg.Network.ClusterLocalDomainTLS == netconfig.EncryptionEnabled && shouldReconcileClusterLocalDomainTLS(ing) {
-		originSecrets, err := resources.GetSecrets(ing, v1alpha1.IngressVisibilityClusterLocal, r.secretLister)
-		if err != nil {
-			return err
-		}
-		targetSecrets, err := resources.MakeSecrets(ctx, originSecrets, ing)
-		if err != nil {
-			return err
-		}
-		if err = r.reconcileCertSecrets(ctx, ing, targetSecrets); err != nil {
-			return err
+	gatewayServices := map[v1alpha1.IngressVisibility][]*corev1.Service{}
+	for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+		if !resources.UsesGatewayServices(ctx, ing, visibility) {
+			continue
 		}
-		clusterLocalIngressGateways, err = resources.MakeIngressTLSGateways(ctx, ing, v1alpha1.IngressVisibilityClusterLocal,
-			ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityClusterLocal), originSecrets, r.svcLister)
-		if err != nil {
+		if gatewayServices[visibility], err = r.gatewayServices(ctx, ing, visibility); err != nil {
 			return err
 		}
 	}
-
-	if shouldReconcileHTTPServer(ing) {
-		httpServer := resources.MakeHTTPServer(ing.Spec.HTTPOption, getPublicHosts(ing))
-		if len(externalIngressGateways) == 0 {
-			var err error
-			if externalIngressGateways, err = resources.MakeExternalIngressGateways(ctx, ing, []*istiov1beta1.Server{httpServer}, r.svcLister); err != nil {
-				return err
-			}
-		} else {
-			// add HTTP Server into ingressGateways.
-			for i := range externalIngressGateways {
-				externalIngressGateways[i].Spec.Servers = append(externalIngressGateways[i].Spec.Servers, httpServer)
-			}
+	translation, err := resources.Translate(ctx, ing, gatewayServices, originSecrets)
+	if err != nil {
+		return err
+	}
+	if err := r.hooks.MutateVirtualServices(ctx, ing, translation.VirtualServices); err != nil {
+		return fmt.Errorf("failed to run the hooks of the VirtualServices: %w", err)
+	}
+	for _, gateways := range [][]*v1beta1.Gateway{
+		translation.WildcardGateways,
+		translation.Gateways[v1alpha1.IngressVisibilityExternalIP],
+		translation.Gateways[v1alpha1.IngressVisibilityClusterLocal],
+	} {
+		if err := r.hooks.MutateGateways(ctx, ing, gateways); err != nil {
+			return fmt.Errorf("failed to run the hooks of the Gateways: %w", err)
 		}
-	} else {
-		// Otherwise, we fall back to the default global Gateways for HTTP behavior.
-		// We need this for the backward compatibility.
-		defaultGlobalHTTPGateways := defaultGateways[v1alpha1.IngressVisibilityExternalIP]
+	}
 
-		for _, gateway := range defaultGlobalHTTPGateways {
-			gatewayNames[v1alpha1.IngressVisibilityExternalIP].Insert(gateway.QualifiedName())
-		}
+	remote := &remoteResources{}
+	externalSecrets := translation.Secrets[v1alpha1.IngressVisibilityExternalIP]
+	if err := r.reconcileCertSecrets(ctx, ing, externalSecrets); err != nil {
+		return err
+	}
+	if err := r.reconcileWildcardGateways(ctx, translation.WildcardGateways, ing); err != nil {
+		return err
+	}
+	if err := r.deleteLegacyWildcardGateways(ctx, translation.WildcardGateways, translation.LegacyWildcardGateways); err != nil {
+		return err
+	}
+	clusterLocalSecrets := translation.Secrets[v1alpha1.IngressVisibilityClusterLocal]
+	if err := r.reconcileCertSecrets(ctx, ing, clusterLocalSecrets); err != nil {
+		return err
 	}
+	remote.secrets = append(remote.secrets, externalSecrets...)
+	remote.secrets = append(remote.secrets, clusterLocalSecrets...)
+	remote.gateways = append(remote.gateways, translation.WildcardGateways...)
 
-	if err := r.reconcileIngressGateways(ctx, externalIngressGateways); err != nil {
+	externalIngressGateways := translation.Gateways[v1alpha1.IngressVisibilityExternalIP]
+	if err := r.reconcileIngressGateways(ctx, ing, externalIngressGateways); err != nil {
+		return err
+	}
+	clusterLocalIngressGateways := translation.Gateways[v1alpha1.IngressVisibilityClusterLocal]
+	if err := r.reconcileIngressGateways(ctx, ing, clusterLocalIngressGateways); err != nil {
+		return err
+	}
+	if err := r.deleteLegacyGateways(ctx, ing); err != nil {
 		return err
 	}
-	gatewayNames[v1alpha1.IngressVisibilityExternalIP].Insert(resources.GetQualifiedGatewayNames(externalIngressGateways)...)
+	remote.gateways = append(remote.gateways, externalIngressGateways...)
+	remote.gateways = append(remote.gateways, clusterLocalIngressGateways...)
 
-	if err := r.reconcileIngressGateways(ctx, clusterLocalIngressGateways); err != nil {
+	passthroughGateway, err := r.reconcileAutoPassthroughGateway(ctx, ing)
+	if err != nil {
 		return err
 	}
-	gatewayNames[v1alpha1.IngressVisibilityClusterLocal].Insert(resources.GetQualifiedGatewayNames(clusterLocalIngressGateways)...)
+	if passthroughGateway != nil {
+		remote.gateways = append(remote.gateways, passthroughGateway)
+	}
 
 	if config.FromContext(ctx).Network.SystemInternalTLSEnabled() {
 		logger.Info("reconciling DestinationRules for system-internal-tls")
@@ -235,20 +298,49 @@ func (r *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 		}
 	}
 
-	vses, err := resources.MakeVirtualServices(ing, gatewayNames)
-	if err != nil {
+	if err := r.reconcileAuthorizationPolicies(ctx, ing); err != nil {
 		return err
 	}
 
+	if err := r.reconcileTelemetries(ctx, ing); err != nil {
+		return err
+	}
+
+	vses := translation.VirtualServices
 	logger.Info("Creating/Updating VirtualServices")
 	if err := r.reconcileVirtualServices(ctx, ing, vses); err != nil {
 		ing.Status.MarkLoadBalancerFailed(virtualServiceNotReconciled, err.Error())
 		return err
 	}
 
+	if err := r.reconcileEnvoyFilters(ctx, ing); err != nil {
+		return err
+	}
+
+	if err := r.reconcileGatewayAuthorization(ctx, ing); err != nil {
+		return err
+	}
+
+	if err := r.reconcileWasmPlugins(ctx, ing); err != nil {
+		return err
+	}
+
+	remote.virtualServices = vses
+	if err := r.reconcileRemoteClusters(ctx, ing, remote); err != nil {
+		ing.Status.MarkLoadBalancerFailed(remoteClusterNotReconciled, err.Error())
+		return err
+	}
+
 	// Update status
 	ing.Status.MarkNetworkConfigured()
 
+	if len(ing.Spec.Rules) == 0 {
+		// The resources of the former rules are removed, but there is nothing to probe,
+		// and nothing to expose once the probes would succeed.
+		ing.Status.MarkLoadBalancerFailed(noRulesReason, noRulesMessage)
+		return nil
+	}
+
 	var ready bool
 	if ing.IsReady() {
 		// When the kingress has already been marked Ready for this generation,
@@ -263,6 +355,10 @@ func (r *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 		logger.Debug("Kingress is ready, skipping probe.")
 		ready = true
 	} else {
+		// The probes are sent to the gateways and routed to the backends, which echo
+		// their hash. With system-internal-tls they go through the DestinationRules,
+		// reconciled above, so they only succeed once the gateways complete their TLS
+		// handshakes with the backends.
 		readyStatus, err := r.statusManager.IsReady(ctx, ing)
 		if err != nil {
 			return fmt.Errorf("failed to probe Ingress %s/%s: %w", ing.GetNamespace(), ing.GetName(), err)
@@ -270,6 +366,8 @@ func (r *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 		ready = readyStatus
 	}
 
+	r.reportGatewayZones(ctx, ing, ready)
+
 	if ready {
 		publicGatewayURL := gatewayServiceURL(defaultGateways[v1alpha1.IngressVisibilityExternalIP])
 		publicLbs := getLBStatus(publicGatewayURL)
@@ -287,62 +385,300 @@ func (r *Reconciler) reconcileIngress(ctx context.Context, ing *v1alpha1.Ingress
 	return nil
 }
 
-func getPublicHosts(ing *v1alpha1.Ingress) []string {
-	hosts := sets.New[string]()
-	for _, rule := range ing.Spec.Rules {
-		if rule.Visibility == v1alpha1.IngressVisibilityExternalIP {
-			hosts.Insert(rule.Hosts...)
+// reportGatewayZones reports the zones of the gateway pods the readiness of the Ingress is
+// probed in, when configured with report-zone-readiness.
+func (r *Reconciler) reportGatewayZones(ctx context.Context, ing *v1alpha1.Ingress, ready bool) {
+	if r.listProbeZones == nil || !config.FromContext(ctx).Istio.ReportZoneReadiness {
+		clearGatewayZones(ing)
+		return
+	}
+	zones, err := r.listProbeZones(ctx, ing)
+	if err != nil {
+		// The zones are informative, so they are not worth failing the reconciliation.
+		logging.FromContext(ctx).Warnw("Failed to list the zones of the gateway pods", zap.Error(err))
+		return
+	}
+	markGatewayZones(ing, zones, ready)
+}
+
+// validateCertificateHosts fails the Ingress when the certificates it references do not cover
+// its hosts, as clients would otherwise only discover the mismatch during the TLS handshakes.
+func (r *Reconciler) validateCertificateHosts(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility, originSecrets map[string]*corev1.Secret) error {
+	err := resources.ValidateCertificateHosts(ing.GetIngressTLSForVisibility(visibility), originSecrets)
+	if err == nil {
+		return nil
+	}
+	// Retrying is pointless until the certificates are renewed, which the tracker notices.
+	for _, secret := range originSecrets {
+		r.tracker.TrackReference(resources.SecretRef(secret.Namespace, secret.Name), ing)
+	}
+	ing.Status.MarkLoadBalancerFailed(certificateHostNotCovered, err.Error())
+	return controller.NewPermanentError(err)
+}
+
+// validateSecretNamespaces fails the Ingress when it references TLS secrets outside of the
+// namespaces allowed by the configuration, before any of them is mirrored to the gateways.
+func validateSecretNamespaces(ctx context.Context, ing *v1alpha1.Ingress) error {
+	allowed := config.FromContext(ctx).Istio.SecretNamespaces
+	if allowed.Len() == 0 {
+		return nil
+	}
+	denied := sets.New[string]()
+	for _, tls := range ing.Spec.TLS {
+		if !allowed.Has(tls.SecretNamespace) {
+			denied.Insert(tls.SecretNamespace + "/" + tls.SecretName)
 		}
 	}
-	return sets.List(hosts)
+	if denied.Len() == 0 {
+		return nil
+	}
+	// Retrying is pointless until either the Ingress or the configuration changes,
+	// both of which trigger a new reconciliation.
+	err := fmt.Errorf("the secrets %s are not in the namespaces allowed to hold TLS secrets",
+		strings.Join(sets.List(denied), ", "))
+	ing.Status.MarkLoadBalancerFailed(secretNamespaceNotAllowed, err.Error())
+	return controller.NewPermanentError(err)
+}
+
+// validateGatewayNamespaces fails the Ingress when its labels select gateways dedicated to
+// other namespaces. Such gateways are never programmed for the Ingress.
+func validateGatewayNamespaces(ctx context.Context, ing *v1alpha1.Ingress) error {
+	denied, err := resources.DeniedGateways(ctx, ing)
+	if err != nil || len(denied) == 0 {
+		return err
+	}
+	err = fmt.Errorf("the gateways %s are dedicated to other namespaces than %s",
+		strings.Join(denied, ", "), ing.Namespace)
+	ing.Status.MarkLoadBalancerFailed(gatewayNotAllowed, err.Error())
+	return controller.NewPermanentError(err)
 }
 
 func (r *Reconciler) reconcileCertSecrets(ctx context.Context, ing *v1alpha1.Ingress, desiredSecrets []*corev1.Secret) error {
+	g, ctx := newReconcileGroup(ctx)
 	for _, certSecret := range desiredSecrets {
+		certSecret := certSecret
 		// We track the origin and desired secrets so that desired secrets could be synced accordingly when the origin TLS certificate
 		// secret is refreshed.
 		r.tracker.TrackReference(resources.SecretRef(certSecret.Namespace, certSecret.Name), ing)
 		r.tracker.TrackReference(resources.ExtractOriginSecretRef(certSecret), ing)
-		if _, err := coreaccessor.ReconcileSecret(ctx, nil, certSecret, r); err != nil {
+		g.Go(func() error {
+			_, err := coreaccessor.ReconcileSecret(ctx, nil, certSecret, r)
 			return err
-		}
+		})
 	}
-	return nil
+	return g.Wait()
 }
 
 func (r *Reconciler) reconcileWildcardGateways(ctx context.Context, gateways []*v1beta1.Gateway, ing *v1alpha1.Ingress) error {
 	for _, gateway := range gateways {
 		r.tracker.TrackReference(resources.GatewayRef(gateway), ing)
-		if err := r.reconcileSystemGeneratedGateway(ctx, gateway); err != nil {
-			return err
-		}
 	}
-	return nil
+	return r.reconcileSharedGateways(ctx, gateways, ing)
 }
 
-func (r *Reconciler) reconcileIngressGateways(ctx context.Context, gateways []*v1beta1.Gateway) error {
+// reconcileIngressGateways reconciles the Gateways dedicated to the Ingress, and marks the
+// ones whose names collide with the Gateways of another Ingress as not owned.
+func (r *Reconciler) reconcileIngressGateways(ctx context.Context, ing *v1alpha1.Ingress, gateways []*v1beta1.Gateway) error {
+	var statusMu sync.Mutex
+	g, ctx := newReconcileGroup(ctx)
 	for _, gateway := range gateways {
-		if err := r.reconcileSystemGeneratedGateway(ctx, gateway); err != nil {
+		gateway := gateway
+		g.Go(func() error {
+			err := r.reconcileSystemGeneratedGateway(ctx, gateway, nil)
+			if kaccessor.IsNotOwned(err) {
+				statusMu.Lock()
+				defer statusMu.Unlock()
+				ing.Status.MarkResourceNotOwned("Gateway", gateway.Name)
+			}
 			return err
-		}
+		})
 	}
-	return nil
+	return g.Wait()
+}
+
+// reconcileSharedGateways reconciles Gateways shared by several Ingresses, attributing
+// their edits to the given Ingress. Nil means the Gateways belong to a single Ingress.
+func (r *Reconciler) reconcileSharedGateways(ctx context.Context, gateways []*v1beta1.Gateway, ing *v1alpha1.Ingress) error {
+	g, ctx := newReconcileGroup(ctx)
+	for _, gateway := range gateways {
+		gateway := gateway
+		g.Go(func() error {
+			return r.reconcileSystemGeneratedGateway(ctx, gateway, ing)
+		})
+	}
+	return g.Wait()
 }
 
-func (r *Reconciler) reconcileSystemGeneratedGateway(ctx context.Context, desired *v1beta1.Gateway) error {
+func (r *Reconciler) reconcileSystemGeneratedGateway(ctx context.Context, desired *v1beta1.Gateway, sharedBy *v1alpha1.Ingress) error {
+	// The servers of the system generated Gateways are only complete at this
+	// point, so this is where the spec hash is recorded.
+	desired = desired.DeepCopy()
+	desired.Annotations = kaccessor.WithSpecHash(desired.Annotations, &desired.Spec)
+
 	existing, err := r.gatewayLister.Gateways(desired.Namespace).Get(desired.Name)
 	if apierrs.IsNotFound(err) {
-		if _, err := r.istioClientSet.NetworkingV1beta1().Gateways(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+		created, err := r.istioClientSet.NetworkingV1beta1().Gateways(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
 			return err
 		}
+		if sharedBy != nil {
+			r.recordGatewayEdit(ctx, sharedBy, created, nil, desired.Spec.Servers)
+		}
 	} else if err != nil {
 		return err
-	} else if !cmp.Equal(existing.Spec.DeepCopy(), desired.Spec.DeepCopy(), protocmp.Transform()) {
+	} else if owner := metav1.GetControllerOf(desired); sharedBy == nil && owner != nil && !isControlledBy(existing, owner) {
+		// The names of the Gateways are truncated, so they may collide with the ones of
+		// another Ingress, see names.Gateway.
+		return kaccessor.NewAccessorError(
+			fmt.Errorf("owner: %s does not own Gateway: %s/%s", owner.Name, existing.Namespace, existing.Name),
+			kaccessor.NotOwnResource)
+	} else if !kaccessor.SpecHashMatches(existing, &existing.Spec, desired) || !hasLabels(existing, desired.Labels) {
 		deepCopy := existing.DeepCopy()
 		deepCopy.Spec = *desired.Spec.DeepCopy()
-		if _, err := r.istioClientSet.NetworkingV1beta1().Gateways(desired.Namespace).Update(ctx, deepCopy, metav1.UpdateOptions{}); err != nil {
+		deepCopy.Labels = kmeta.UnionMaps(deepCopy.Labels, desired.Labels)
+		deepCopy.Annotations = kmeta.UnionMaps(deepCopy.Annotations, desired.Annotations)
+		updated, err := r.istioClientSet.NetworkingV1beta1().Gateways(desired.Namespace).Update(ctx, deepCopy, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		if sharedBy != nil {
+			r.recordGatewayEdit(ctx, sharedBy, updated, existing.Spec.Servers, desired.Spec.Servers)
+		}
+	}
+	return nil
+}
+
+// isControlledBy returns whether the object is controlled by the owner of the reference.
+func isControlledBy(obj metav1.Object, owner *metav1.OwnerReference) bool {
+	controller := metav1.GetControllerOf(obj)
+	return controller != nil && controller.UID == owner.UID
+}
+
+// hasLabels returns whether the object carries the labels, e.g. the ones added by the hooks.
+func hasLabels(obj metav1.Object, labels map[string]string) bool {
+	for k, v := range labels {
+		if value, ok := obj.GetLabels()[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// recordGatewayEdit records an event with the summary of the changes to the servers of
+// a shared Gateway on both the Gateway and the Ingress triggering them, which lets the
+// operators of multi-tenant clusters find out which Ingress changed the Gateway.
+func (r *Reconciler) recordGatewayEdit(ctx context.Context, ing *v1alpha1.Ingress, gateway *v1beta1.Gateway, before, after []*istiov1beta1.Server) {
+	summary := resources.DescribeServerChanges(before, after)
+	if summary == "" {
+		return
+	}
+	recorder := controller.GetEventRecorder(ctx)
+	recorder.Eventf(ing, corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s: %s",
+		gateway.Namespace, gateway.Name, summary)
+	// The events refer to their object through its kind, which the objects of the
+	// listers and clients do not have.
+	gateway = gateway.DeepCopy()
+	gateway.SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind("Gateway"))
+	recorder.Eventf(gateway, corev1.EventTypeNormal, "Updated", "Ingress %s/%s %s",
+		ing.Namespace, ing.Name, summary)
+}
+
+// reconcileAutoPassthroughGateway exposes the cluster-local hosts of the Ingress on the
+// configured AUTO_PASSTHROUGH gateway, and removes the Gateway once it is not needed.
+func (r *Reconciler) reconcileAutoPassthroughGateway(ctx context.Context, ing *v1alpha1.Ingress) (*v1beta1.Gateway, error) {
+	serviceURL := config.FromContext(ctx).Istio.AutoPassthroughGateway
+	hosts := resources.AutoPassthroughHosts(ing)
+	if serviceURL == "" || len(hosts) == 0 {
+		return nil, r.deleteAutoPassthroughGateway(ctx, ing)
+	}
+
+	meta, err := resources.GetGatewaySvcNameNamespace(config.Gateway{ServiceURL: serviceURL})
+	if err != nil {
+		return nil, err
+	}
+	svc, err := r.gatewayServiceLister().Services(meta.Namespace).Get(meta.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auto passthrough gateway service: %w", err)
+	}
+	gateway := resources.MakeAutoPassthroughGateway(ing, hosts, svc)
+	if err := r.hooks.MutateGateways(ctx, ing, []*v1beta1.Gateway{gateway}); err != nil {
+		return nil, fmt.Errorf("failed to run the hooks of the Gateways: %w", err)
+	}
+	if err := r.reconcileSystemGeneratedGateway(ctx, gateway, nil); err != nil {
+		return nil, err
+	}
+	return gateway, nil
+}
+
+// deleteLegacyGateways deletes the Gateways of the Ingress named after the legacy naming
+// scheme, once their replacements are created in FIPS-compatible mode.
+func (r *Reconciler) deleteLegacyGateways(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if !fips.Enabled() {
+		return nil
+	}
+	// The Gateways of both visibilities were named after the Services of the ingress gateways.
+	gatewayServices, err := r.gatewayServices(ctx, ing, v1alpha1.IngressVisibilityExternalIP)
+	if err != nil {
+		return err
+	}
+	for _, name := range sets.List(resources.LegacyGatewayNames(ing, gatewayServices)) {
+		gateway, err := r.gatewayLister.Gateways(ing.Namespace).Get(name)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(gateway, ing) {
+			continue
+		}
+		if err := r.istioClientSet.NetworkingV1beta1().Gateways(gateway.Namespace).Delete(ctx, gateway.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete legacy Gateway: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteLegacyWildcardGateways deletes the wildcard Gateways named after the legacy naming
+// scheme, once their replacements are created in FIPS-compatible mode. The legacy Gateways
+// are only deleted when they are owned by the same certificate Secret as their replacement.
+func (r *Reconciler) deleteLegacyWildcardGateways(ctx context.Context, gateways []*v1beta1.Gateway, legacy map[string]string) error {
+	if !fips.Enabled() {
+		return nil
+	}
+	for _, desired := range gateways {
+		name, ok := legacy[desired.Name]
+		if !ok || name == desired.Name {
+			continue
+		}
+		gateway, err := r.gatewayLister.Gateways(desired.Namespace).Get(name)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
 			return err
 		}
+		owner, desiredOwner := metav1.GetControllerOf(gateway), metav1.GetControllerOf(desired)
+		if owner == nil || desiredOwner == nil || owner.UID != desiredOwner.UID {
+			continue
+		}
+		if err := r.istioClientSet.NetworkingV1beta1().Gateways(gateway.Namespace).Delete(ctx, gateway.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete legacy wildcard Gateway: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) deleteAutoPassthroughGateway(ctx context.Context, ing *v1alpha1.Ingress) error {
+	gateway, err := r.gatewayLister.Gateways(ing.Namespace).Get(resources.AutoPassthroughGatewayName(ing))
+	if apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(gateway, ing) {
+		return nil
+	}
+	if err := r.istioClientSet.NetworkingV1beta1().Gateways(gateway.Namespace).Delete(ctx, gateway.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Gateway: %w", err)
 	}
 	return nil
 }
@@ -351,19 +687,31 @@ func (r *Reconciler) reconcileVirtualServices(ctx context.Context, ing *v1alpha1
 	desired []*v1beta1.VirtualService) error {
 	// First, create all needed VirtualServices.
 	kept := sets.New[string]()
+	// The status of the Ingress is not safe for concurrent use.
+	var statusMu sync.Mutex
+	g, gctx := newReconcileGroup(ctx)
 	for _, d := range desired {
 		if d.GetAnnotations()[networking.IngressClassAnnotationKey] != netconfig.IstioIngressClassName {
 			// We do not create resources that do not have istio ingress class annotation.
 			// As a result, obsoleted resources will be cleaned up.
 			continue
 		}
-		if _, err := istioaccessor.ReconcileVirtualService(ctx, ing, d, r); err != nil {
-			if kaccessor.IsNotOwned(err) {
-				ing.Status.MarkResourceNotOwned("VirtualService", d.Name)
-			}
-			return err
-		}
+		d := d
 		kept.Insert(d.Name)
+		g.Go(func() error {
+			if _, err := istioaccessor.ReconcileVirtualService(gctx, ing, d, r); err != nil {
+				if kaccessor.IsNotOwned(err) {
+					statusMu.Lock()
+					defer statusMu.Unlock()
+					ing.Status.MarkResourceNotOwned("VirtualService", d.Name)
+				}
+				return err
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
 	}
 
 	// Now, remove the extra ones.
@@ -372,42 +720,83 @@ func (r *Reconciler) reconcileVirtualServices(ctx context.Context, ing *v1alpha1
 		resources.RouteLabelKey:    ing.GetLabels()[resources.RouteLabelKey], // VS created before 0.12
 	}
 	for k, v := range selectors {
-		vses, err := r.virtualServiceLister.VirtualServices(ing.GetNamespace()).List(
-			labels.SelectorFromSet(labels.Set{k: v}))
+		vses, err := r.listVirtualServices(ing.GetNamespace(), k, v)
 		if err != nil {
 			return fmt.Errorf("failed to list VirtualServices: %w", err)
 		}
 
-		// Sort the virtual services by name to get a stable deletion order.
-		sort.Slice(vses, func(i, j int) bool {
-			return vses[i].Name < vses[j].Name
-		})
-
+		// Only keep the stale ones around, so that the sort below stays cheap
+		// even if a lot of VirtualServices match.
+		var stale []*v1beta1.VirtualService
 		for _, vs := range vses {
-			n, ns := vs.Name, vs.Namespace
-			if kept.Has(n) {
+			if kept.Has(vs.Name) {
 				continue
 			}
 			if !metav1.IsControlledBy(vs, ing) {
 				// We shouldn't remove resources not controlled by us.
 				continue
 			}
+			stale = append(stale, vs)
+		}
+
+		// Sort the virtual services by name to get a stable deletion order.
+		sort.Slice(stale, func(i, j int) bool {
+			return stale[i].Name < stale[j].Name
+		})
+
+		for _, vs := range stale {
+			n, ns := vs.Name, vs.Namespace
 			if err = r.istioClientSet.NetworkingV1beta1().VirtualServices(ns).Delete(ctx, n, metav1.DeleteOptions{}); err != nil {
 				return fmt.Errorf("failed to delete VirtualService: %w", err)
 			}
+			// The VirtualServices have both labels, don't delete them twice.
+			kept.Insert(n)
 		}
 	}
 	return nil
 }
 
+// listVirtualServices returns the VirtualServices in the given namespace that
+// carry the given label. Lookups by Ingress use the informer index when it is
+// available, to avoid scanning every VirtualService of the namespace.
+func (r *Reconciler) listVirtualServices(namespace, key, value string) ([]*v1beta1.VirtualService, error) {
+	if r.virtualServiceIndexer == nil || key != networking.IngressLabelKey {
+		return r.virtualServiceLister.VirtualServices(namespace).List(labels.SelectorFromSet(labels.Set{key: value}))
+	}
+	objs, err := r.virtualServiceIndexer.ByIndex(virtualServiceByIngressIndex, namespace+"/"+value)
+	if err != nil {
+		return nil, err
+	}
+	vses := make([]*v1beta1.VirtualService, 0, len(objs))
+	for _, obj := range objs {
+		vses = append(vses, obj.(*v1beta1.VirtualService))
+	}
+	return vses, nil
+}
+
 func (r *Reconciler) reconcileDestinationRules(ctx context.Context, ing *v1alpha1.Ingress) error {
+	conn, err := resources.ParseUpstreamConnection(ing)
+	if err != nil {
+		return err
+	}
 	var drs = sets.New[string]()
+	desired := []*v1beta1.DestinationRule{}
 	for _, rule := range ing.Spec.Rules {
 		for _, path := range rule.HTTP.Paths {
-			// Currently DomainMappings point to the cluster local domain on the local gateway.
-			// As there is no encryption there yet (https://github.com/knative/serving/issues/13472),
-			// we cannot use upstream TLS here, so we need to skip it for DomainMappings
+			// DomainMappings point to the cluster local domain on the local gateway, which only
+			// terminates TLS with cluster-local-domain-tls (https://github.com/knative/serving/issues/13472),
+			// so they fall back to plaintext without it.
 			if path.RewriteHost != "" {
+				if !resources.DomainMappingsEncrypted(config.FromContext(ctx)) {
+					continue
+				}
+				for _, split := range path.Splits {
+					hostname := pkgnetwork.GetServiceHostname(split.ServiceName, split.ServiceNamespace)
+					if !drs.Has(hostname) {
+						desired = append(desired, resources.MakeDomainMappingDestinationRule(hostname, path.RewriteHost, ing))
+						drs.Insert(hostname)
+					}
+				}
 				continue
 			}
 
@@ -429,55 +818,586 @@ func (r *Reconciler) reconcileDestinationRules(ctx context.Context, ing *v1alpha
 				// skip duplicate entries, as we only need one DR per unique upstream k8s service
 				if !drs.Has(hostname) {
 					dr := resources.MakeInternalEncryptionDestinationRule(hostname, ing, http2)
-					if _, err := istioaccessor.ReconcileDestinationRule(ctx, ing, dr, r); err != nil {
-						return fmt.Errorf("failed to reconcile DestinationRule: %w", err)
-					}
+					resources.WithUpstreamConnection(dr, conn)
+					desired = append(desired, dr)
 					drs.Insert(hostname)
 				}
 			}
 		}
 	}
 
-	return nil
+	g, ctx := newReconcileGroup(ctx)
+	for _, dr := range desired {
+		dr := dr
+		g.Go(func() error {
+			if _, err := istioaccessor.ReconcileDestinationRule(ctx, ing, dr, r); err != nil {
+				return fmt.Errorf("failed to reconcile DestinationRule: %w", err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
 }
 
-func (r *Reconciler) FinalizeKind(ctx context.Context, ing *v1alpha1.Ingress) pkgreconciler.Event {
-	logger := logging.FromContext(ctx)
+// reconcileAuthorizationPolicies restricts access to the backends of the Ingress to the
+// configured gateways and the activator. If AuthorizationPolicies are disabled, the
+// ones previously created for the Ingress are removed.
+func (r *Reconciler) reconcileAuthorizationPolicies(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if r.authorizationPolicyLister == nil {
+		return nil
+	}
+
 	istiocfg := config.FromContext(ctx).Istio
-	logger.Info("Cleaning up Gateway Servers")
-	for _, gws := range [][]config.Gateway{istiocfg.IngressGateways, istiocfg.LocalGateways} {
-		for _, gw := range gws {
-			if err := r.reconcileIngressServers(ctx, ing, gw, []*istiov1beta1.Server{}); err != nil {
-				return err
+	desired := []*securityv1beta1.AuthorizationPolicy{}
+	if istiocfg.EnableAuthorizationPolicies {
+		gatewayNamespaces, err := resources.GetGatewayServiceNamespaces(append(istiocfg.IngressGateways, istiocfg.LocalGateways...))
+		if err != nil {
+			return fmt.Errorf("failed to get gateway namespaces: %w", err)
+		}
+		// The activator runs in the Knative system namespace.
+		sourceNamespaces := sets.List(sets.New(gatewayNamespaces...).Insert(system.Namespace()))
+
+		svcs, err := r.localBackendServices(ing)
+		if err != nil {
+			return err
+		}
+		for _, svc := range svcs {
+			if ap := resources.MakeAuthorizationPolicy(ing, svc, sourceNamespaces); ap != nil {
+				desired = append(desired, ap)
 			}
 		}
 	}
 
-	return r.cleanupCertificateSecrets(ctx, ing)
+	kept := sets.New[string]()
+	g, gctx := newReconcileGroup(ctx)
+	for _, ap := range desired {
+		ap := ap
+		kept.Insert(ap.Name)
+		g.Go(func() error {
+			if _, err := istioaccessor.ReconcileAuthorizationPolicy(gctx, ing, ap, r); err != nil {
+				return fmt.Errorf("failed to reconcile AuthorizationPolicy: %w", err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	aps, err := r.authorizationPolicyLister.AuthorizationPolicies(ing.Namespace).List(
+		labels.SelectorFromSet(labels.Set{networking.IngressLabelKey: ing.Name}))
+	if err != nil {
+		return fmt.Errorf("failed to list AuthorizationPolicies: %w", err)
+	}
+	for _, ap := range aps {
+		if kept.Has(ap.Name) || !metav1.IsControlledBy(ap, ing) {
+			continue
+		}
+		if err := r.istioClientSet.SecurityV1beta1().AuthorizationPolicies(ap.Namespace).Delete(ctx, ap.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete AuthorizationPolicy: %w", err)
+		}
+	}
+	return nil
 }
 
-func (r *Reconciler) cleanupCertificateSecrets(ctx context.Context, ing *v1alpha1.Ingress) error {
-	if !shouldReconcileExternalDomainTLS(ing) && !shouldReconcileClusterLocalDomainTLS(ing) {
+// reconcileTelemetries applies the observability settings of the Ingress annotations
+// to its backends, and removes the Telemetries that are no longer needed.
+func (r *Reconciler) reconcileTelemetries(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if r.telemetryLister == nil {
 		return nil
 	}
 
-	errs := []error{}
-	for _, tls := range ing.Spec.TLS {
-		nameNamespaces, err := resources.GetIngressGatewaySvcNameNamespaces(ctx, ing)
+	desired := []*telemetryv1alpha1.Telemetry{}
+	if resources.HasTelemetryAnnotations(ing) {
+		svcs, err := r.localBackendServices(ing)
 		if err != nil {
-			errs = append(errs, err)
-			continue
+			return err
 		}
-		for _, nameNamespace := range nameNamespaces {
-			secrets, err := r.GetSecretLister().Secrets(nameNamespace.Namespace).List(labels.SelectorFromSet(
-				resources.MakeTargetSecretLabels(tls.SecretName, tls.SecretNamespace)))
+		for _, svc := range svcs {
+			t, err := resources.MakeTelemetry(ing, svc)
 			if err != nil {
-				errs = append(errs, err)
+				return err
+			}
+			if t != nil {
+				desired = append(desired, t)
+			}
+		}
+	}
+
+	kept := sets.New[string]()
+	g, gctx := newReconcileGroup(ctx)
+	for _, t := range desired {
+		t := t
+		kept.Insert(t.Name)
+		g.Go(func() error {
+			if _, err := istioaccessor.ReconcileTelemetry(gctx, ing, t, r); err != nil {
+				return fmt.Errorf("failed to reconcile Telemetry: %w", err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	ts, err := r.telemetryLister.Telemetries(ing.Namespace).List(
+		labels.SelectorFromSet(labels.Set{networking.IngressLabelKey: ing.Name}))
+	if err != nil {
+		return fmt.Errorf("failed to list Telemetries: %w", err)
+	}
+	for _, t := range ts {
+		if kept.Has(t.Name) || !metav1.IsControlledBy(t, ing) {
+			continue
+		}
+		if err := r.istioClientSet.TelemetryV1alpha1().Telemetries(t.Namespace).Delete(ctx, t.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete Telemetry: %w", err)
+		}
+	}
+	return nil
+}
+
+// localBackendServices returns the Services the Ingress splits traffic to which are in
+// the namespace of the Ingress. Owner references cannot cross namespaces, so only the
+// resources of those backends can be managed for the Ingress.
+func (r *Reconciler) localBackendServices(ing *v1alpha1.Ingress) ([]*corev1.Service, error) {
+	seen := sets.New[string]()
+	svcs := []*corev1.Service{}
+	for _, rule := range ing.Spec.Rules {
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				if split.ServiceNamespace != ing.Namespace || seen.Has(split.ServiceName) {
+					continue
+				}
+				seen.Insert(split.ServiceName)
+
+				svc, err := r.svcLister.Services(split.ServiceNamespace).Get(split.ServiceName)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get service: %w", err)
+				}
+				svcs = append(svcs, svc)
+			}
+		}
+	}
+	return svcs, nil
+}
+
+func (r *Reconciler) FinalizeKind(ctx context.Context, ing *v1alpha1.Ingress) pkgreconciler.Event {
+	logger := logging.FromContext(ctx)
+	// The Ingress is going away, so its failures are no longer needed.
+	r.istioAPIBackoff.reset(types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name})
+	force := forceFinalize(ctx, ing)
+	var skipped []string
+	for _, step := range r.cleanupSteps(ctx, ing) {
+		logger.Info("Cleaning up " + step.what)
+		err := step.run()
+		if err == nil {
+			continue
+		}
+		if !force {
+			return finalizeFailed(ctx, ing, step, err)
+		}
+		// The Ingress is stuck in deletion, e.g. as a shared gateway it was exposed on was
+		// deleted, so what can't be cleaned up is left behind rather than the Ingress.
+		controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, cleanupSkipped,
+			"Skipped cleaning up %s: %v", step.what, err)
+		skipped = append(skipped, step.what)
+	}
+	if len(skipped) > 0 {
+		reportForceFinalized(ctx, ing, skipped)
+	}
+	return nil
+}
+
+// reconcileEnvoyFilters instantiates the EnvoyFilter template referenced by the Ingress and
+// the rate limit it requests for each of its gateways, and removes the EnvoyFilters that are
+// no longer needed.
+func (r *Reconciler) reconcileEnvoyFilters(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if r.envoyFilterLister == nil {
+		return nil
+	}
+
+	desired := []*v1alpha3.EnvoyFilter{}
+	template := ing.GetAnnotations()[resources.EnvoyFilterTemplateAnnotationKey]
+	if template != "" || resources.HasRateLimitAnnotations(ing) {
+		gateways, err := resources.GatewaysFromContext(ctx, ing)
+		if err != nil {
+			return err
+		}
+
+		if template != "" {
+			// Templates are registered by operators in the system namespace, as they can
+			// modify the gateways shared by all Ingresses. Their edits are applied right
+			// away, and the Ingresses referencing them before they exist are retried once
+			// they are created.
+			r.tracker.TrackReference(resources.ConfigMapRef(system.Namespace(), template), ing)
+			cm, err := r.configMapLister.ConfigMaps(system.Namespace()).Get(template)
+			if err != nil {
+				return fmt.Errorf("failed to get EnvoyFilter template %q: %w", template, err)
+			}
+			for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+				if !hasRulesForVisibility(ing, visibility) {
+					continue
+				}
+				for _, gw := range gateways[visibility] {
+					svc, err := r.gatewayService(gw)
+					if err != nil {
+						return err
+					}
+					ef, err := resources.MakeEnvoyFilter(ing, visibility, svc, cm.Data[resources.EnvoyFilterTemplateKey])
+					if err != nil {
+						return err
+					}
+					desired = append(desired, ef)
+				}
+			}
+		}
+
+		// Rate limits protect the services at the edge, so they only apply to the
+		// hosts exposed on the external gateways.
+		if resources.HasRateLimitAnnotations(ing) && resources.IsIngressPublic(ing) {
+			for _, gw := range gateways[v1alpha1.IngressVisibilityExternalIP] {
+				svc, err := r.gatewayService(gw)
+				if err != nil {
+					return err
+				}
+				ef, err := resources.MakeRateLimitEnvoyFilter(ing, svc, resources.PublicHosts(ing))
+				if err != nil {
+					return err
+				}
+				desired = append(desired, ef)
+			}
+		}
+	}
+
+	owned := labels.SelectorFromSet(resources.GatewayResourceLabels(ing))
+	kept := sets.New[string]()
+	for _, ef := range desired {
+		key := ef.Namespace + "/" + ef.Name
+		if kept.Has(key) {
+			continue
+		}
+		kept.Insert(key)
+		if _, err := istioaccessor.ReconcileEnvoyFilter(ctx, ing, ef, owned, r); err != nil {
+			if kaccessor.IsNotOwned(err) {
+				ing.Status.MarkResourceNotOwned("EnvoyFilter", key)
+			}
+			return err
+		}
+	}
+	return r.deleteStaleEnvoyFilters(ctx, ing, kept)
+}
+
+// gatewayService returns the Service backing the given gateway.
+func (r *Reconciler) gatewayService(gateway config.Gateway) (*corev1.Service, error) {
+	meta, err := resources.GetGatewaySvcNameNamespace(gateway)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := r.gatewayServiceLister().Services(meta.Namespace).Get(meta.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gateway service: %w", err)
+	}
+	return svc, nil
+}
+
+// deleteStaleEnvoyFilters removes the EnvoyFilters of the Ingress which are not kept. As
+// they live next to the gateways, they are looked up in all gateway namespaces.
+func (r *Reconciler) deleteStaleEnvoyFilters(ctx context.Context, ing *v1alpha1.Ingress, kept sets.Set[string]) error {
+	if r.envoyFilterLister == nil {
+		return nil
+	}
+
+	istiocfg := config.FromContext(ctx).Istio
+	namespaces, err := resources.GetGatewayServiceNamespaces(append(istiocfg.IngressGateways, istiocfg.LocalGateways...))
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		efs, err := r.envoyFilterLister.EnvoyFilters(ns).List(labels.SelectorFromSet(resources.GatewayResourceLabels(ing)))
+		if err != nil {
+			return fmt.Errorf("failed to list EnvoyFilters: %w", err)
+		}
+		for _, ef := range efs {
+			if kept.Has(ef.Namespace + "/" + ef.Name) {
+				continue
+			}
+			if err := r.istioClientSet.NetworkingV1alpha3().EnvoyFilters(ef.Namespace).Delete(ctx, ef.Name, metav1.DeleteOptions{}); err != nil {
+				return fmt.Errorf("failed to delete EnvoyFilter: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileGatewayAuthorization requires the requests to the hosts of the Ingress to carry a
+// valid JWT, to come from the allowed source IPs and to be authorized by the external
+// authorization provider on each of its gateways, and removes the resources that are no
+// longer needed.
+func (r *Reconciler) reconcileGatewayAuthorization(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if r.authorizationPolicyLister == nil {
+		return nil
+	}
+
+	ras := []*securityv1beta1.RequestAuthentication{}
+	aps := []*securityv1beta1.AuthorizationPolicy{}
+	requireJWT := resources.HasJWTAnnotations(ing) && r.requestAuthenticationLister != nil
+	extAuthz := ing.Annotations[resources.ExtAuthzProviderAnnotationKey] != ""
+	if requireJWT || resources.HasIPAccessAnnotations(ing) || extAuthz {
+		gateways, err := r.gatewayServiceHosts(ctx, ing)
+		if err != nil {
+			return err
+		}
+		for _, gw := range gateways {
+			if requireJWT {
+				ra, err := resources.MakeRequestAuthentication(ing, gw.svc)
+				if err != nil {
+					return err
+				}
+				ap, err := resources.MakeJWTAuthorizationPolicy(ing, gw.svc, sets.List(gw.hosts))
+				if err != nil {
+					return err
+				}
+				ras = append(ras, ra)
+				aps = append(aps, ap)
+			}
+			if resources.HasIPAccessAnnotations(ing) {
+				ap, err := resources.MakeIPAccessAuthorizationPolicy(ing, gw.svc, sets.List(gw.hosts))
+				if err != nil {
+					return err
+				}
+				aps = append(aps, ap)
+			}
+			if extAuthz {
+				aps = append(aps, resources.MakeExtAuthzAuthorizationPolicy(ing, gw.svc, sets.List(gw.hosts)))
+			}
+		}
+	}
+
+	owned := labels.SelectorFromSet(resources.GatewayResourceLabels(ing))
+	kept := sets.New[string]()
+	for _, ra := range ras {
+		key := ra.Namespace + "/" + ra.Name
+		kept.Insert(key)
+		if _, err := istioaccessor.ReconcileRequestAuthentication(ctx, ing, ra, owned, r); err != nil {
+			if kaccessor.IsNotOwned(err) {
+				ing.Status.MarkResourceNotOwned("RequestAuthentication", key)
+			}
+			return err
+		}
+	}
+	for _, ap := range aps {
+		key := ap.Namespace + "/" + ap.Name
+		kept.Insert(key)
+		if _, err := istioaccessor.ReconcileGatewayAuthorizationPolicy(ctx, ing, ap, owned, r); err != nil {
+			if kaccessor.IsNotOwned(err) {
+				ing.Status.MarkResourceNotOwned("AuthorizationPolicy", key)
+			}
+			return err
+		}
+	}
+	return r.deleteStaleGatewayAuthorization(ctx, ing, kept)
+}
+
+// deleteStaleGatewayAuthorization removes the RequestAuthentications and AuthorizationPolicies
+// generated for the Ingress next to its gateways which are not kept.
+func (r *Reconciler) deleteStaleGatewayAuthorization(ctx context.Context, ing *v1alpha1.Ingress, kept sets.Set[string]) error {
+	if r.authorizationPolicyLister == nil {
+		return nil
+	}
+
+	istiocfg := config.FromContext(ctx).Istio
+	namespaces, err := resources.GetGatewayServiceNamespaces(append(istiocfg.IngressGateways, istiocfg.LocalGateways...))
+	if err != nil {
+		return err
+	}
+	selector := labels.SelectorFromSet(resources.GatewayResourceLabels(ing))
+	for _, ns := range namespaces {
+		if r.requestAuthenticationLister != nil {
+			ras, err := r.requestAuthenticationLister.RequestAuthentications(ns).List(selector)
+			if err != nil {
+				return fmt.Errorf("failed to list RequestAuthentications: %w", err)
+			}
+			for _, ra := range ras {
+				if kept.Has(ra.Namespace + "/" + ra.Name) {
+					continue
+				}
+				if err := r.istioClientSet.SecurityV1beta1().RequestAuthentications(ra.Namespace).Delete(ctx, ra.Name, metav1.DeleteOptions{}); err != nil {
+					return fmt.Errorf("failed to delete RequestAuthentication: %w", err)
+				}
+			}
+		}
+
+		aps, err := r.authorizationPolicyLister.AuthorizationPolicies(ns).List(selector)
+		if err != nil {
+			return fmt.Errorf("failed to list AuthorizationPolicies: %w", err)
+		}
+		for _, ap := range aps {
+			if kept.Has(ap.Namespace + "/" + ap.Name) {
+				continue
+			}
+			if err := r.istioClientSet.SecurityV1beta1().AuthorizationPolicies(ap.Namespace).Delete(ctx, ap.Name, metav1.DeleteOptions{}); err != nil {
+				return fmt.Errorf("failed to delete AuthorizationPolicy: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileWasmPlugins attaches the WasmPlugin registered under the name referenced by the
+// Ingress to each of its gateways, and removes the WasmPlugins that are no longer needed.
+func (r *Reconciler) reconcileWasmPlugins(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if r.wasmPluginLister == nil {
+		return nil
+	}
+
+	desired := []*extensionsv1alpha1.WasmPlugin{}
+	if name := ing.GetAnnotations()[resources.WasmPluginAnnotationKey]; name != "" {
+		// Plugins are registered by operators in the system namespace, as they run
+		// in the gateways shared by all Ingresses. Like the EnvoyFilter templates,
+		// their registrations are tracked.
+		r.tracker.TrackReference(resources.ConfigMapRef(system.Namespace(), name), ing)
+		cm, err := r.configMapLister.ConfigMaps(system.Namespace()).Get(name)
+		if err != nil {
+			return fmt.Errorf("failed to get WasmPlugin registration %q: %w", name, err)
+		}
+		gateways, err := r.gatewayServiceHosts(ctx, ing)
+		if err != nil {
+			return err
+		}
+		for _, gw := range gateways {
+			wp, err := resources.MakeWasmPlugin(ing, gw.svc, cm.Data[resources.WasmPluginSpecKey])
+			if err != nil {
+				return err
+			}
+			desired = append(desired, wp)
+		}
+	}
+
+	owned := labels.SelectorFromSet(resources.GatewayResourceLabels(ing))
+	kept := sets.New[string]()
+	for _, wp := range desired {
+		key := wp.Namespace + "/" + wp.Name
+		kept.Insert(key)
+		if _, err := istioaccessor.ReconcileWasmPlugin(ctx, ing, wp, owned, r); err != nil {
+			if kaccessor.IsNotOwned(err) {
+				ing.Status.MarkResourceNotOwned("WasmPlugin", key)
+			}
+			return err
+		}
+	}
+	return r.deleteStaleWasmPlugins(ctx, ing, kept)
+}
+
+// deleteStaleWasmPlugins removes the WasmPlugins of the Ingress which are not kept. As
+// they live next to the gateways, they are looked up in all gateway namespaces.
+func (r *Reconciler) deleteStaleWasmPlugins(ctx context.Context, ing *v1alpha1.Ingress, kept sets.Set[string]) error {
+	if r.wasmPluginLister == nil {
+		return nil
+	}
+
+	istiocfg := config.FromContext(ctx).Istio
+	namespaces, err := resources.GetGatewayServiceNamespaces(append(istiocfg.IngressGateways, istiocfg.LocalGateways...))
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		wps, err := r.wasmPluginLister.WasmPlugins(ns).List(labels.SelectorFromSet(resources.GatewayResourceLabels(ing)))
+		if err != nil {
+			return fmt.Errorf("failed to list WasmPlugins: %w", err)
+		}
+		for _, wp := range wps {
+			if kept.Has(wp.Namespace + "/" + wp.Name) {
 				continue
 			}
-			for _, secret := range secrets {
-				if err := r.GetKubeClient().CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil {
+			if err := r.istioClientSet.ExtensionsV1alpha1().WasmPlugins(wp.Namespace).Delete(ctx, wp.Name, metav1.DeleteOptions{}); err != nil {
+				return fmt.Errorf("failed to delete WasmPlugin: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// gatewayHosts is a gateway Service along with the hosts of an Ingress it exposes.
+type gatewayHosts struct {
+	svc   *corev1.Service
+	hosts sets.Set[string]
+}
+
+// gatewayServiceHosts returns the Services of the gateways exposing the Ingress, along
+// with the hosts of the Ingress exposed by each of them.
+func (r *Reconciler) gatewayServiceHosts(ctx context.Context, ing *v1alpha1.Ingress) ([]*gatewayHosts, error) {
+	gateways, err := resources.GatewaysFromContext(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*gatewayHosts{}
+	result := []*gatewayHosts{}
+	for _, rule := range ing.Spec.Rules {
+		for _, gw := range gateways[rule.Visibility] {
+			meta, err := resources.GetGatewaySvcNameNamespace(gw)
+			if err != nil {
+				return nil, err
+			}
+			key := meta.Namespace + "/" + meta.Name
+			gh, ok := byKey[key]
+			if !ok {
+				svc, err := r.gatewayServiceLister().Services(meta.Namespace).Get(meta.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get gateway service: %w", err)
+				}
+				gh = &gatewayHosts{svc: svc, hosts: sets.New[string]()}
+				byKey[key] = gh
+				result = append(result, gh)
+			}
+			gh.hosts.Insert(rule.Hosts...)
+		}
+	}
+	return result, nil
+}
+
+func hasRulesForVisibility(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility) bool {
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reconciler) cleanupCertificateSecrets(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if !resources.ShouldReconcileExternalDomainTLS(ing) && !resources.ShouldReconcileClusterLocalDomainTLS(ing) {
+		return nil
+	}
+
+	errs := []error{}
+	for _, tls := range ing.Spec.TLS {
+		namespaces, err := certSecretNamespaces(ctx, ing)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		selectors := []labels.Selector{labels.SelectorFromSet(resources.MakeTargetSecretLabels(tls.SecretName, tls.SecretNamespace))}
+		if fips.Enabled() {
+			// The Secrets copied before the FIPS-compatible mode was enabled may still have the legacy labels.
+			selectors = append(selectors, labels.SelectorFromSet(resources.MakeLegacyTargetSecretLabels(tls.SecretName, tls.SecretNamespace)))
+		}
+		for _, namespace := range namespaces {
+			deleted := sets.New[string]()
+			for _, selector := range selectors {
+				secrets, err := r.GetSecretLister().Secrets(namespace).List(selector)
+				if err != nil {
 					errs = append(errs, err)
+					continue
+				}
+				for _, secret := range secrets {
+					if deleted.Has(secret.Name) {
+						continue
+					}
+					deleted.Insert(secret.Name)
+					if err := r.GetKubeClient().CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil {
+						errs = append(errs, err)
+					}
 				}
 			}
 		}
@@ -485,6 +1405,22 @@ func (r *Reconciler) cleanupCertificateSecrets(ctx context.Context, ing *v1alpha
 	return errors.NewAggregate(errs)
 }
 
+// certSecretNamespaces returns the namespaces the certificates of the Ingress are copied
+// to, the ones of the Services of the gateways of both visibilities.
+func certSecretNamespaces(ctx context.Context, ing *v1alpha1.Ingress) ([]string, error) {
+	namespaces := sets.New[string]()
+	for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+		metas, err := resources.GetGatewaySvcNameNamespacesForVisibility(ctx, ing, visibility)
+		if err != nil {
+			return nil, err
+		}
+		for _, meta := range metas {
+			namespaces.Insert(meta.Namespace)
+		}
+	}
+	return sets.List(namespaces), nil
+}
+
 func (r *Reconciler) reconcileIngressServers(ctx context.Context, ing *v1alpha1.Ingress, gw config.Gateway, desired []*istiov1beta1.Server) error {
 	gateway, err := r.gatewayLister.Gateways(gw.Namespace).Get(gw.Name)
 	if err != nil {
@@ -503,21 +1439,41 @@ func (r *Reconciler) reconcileGateway(ctx context.Context, ing *v1alpha1.Ingress
 
 	deepCopy := gateway.DeepCopy()
 	deepCopy = resources.UpdateGateway(deepCopy, desired, existing)
-	if _, err := r.istioClientSet.NetworkingV1beta1().Gateways(deepCopy.Namespace).Update(ctx, deepCopy, metav1.UpdateOptions{}); err != nil {
+	updated, err := r.updateGateway(ctx, ing, gateway, deepCopy)
+	if err != nil {
 		return fmt.Errorf("failed to update Gateway: %w", err)
 	}
-	controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal,
-		"Updated", "Updated Gateway %s/%s", gateway.Namespace, gateway.Name)
+	r.recordGatewayEdit(ctx, ing, updated, gateway.Spec.Servers, deepCopy.Spec.Servers)
 	return nil
 }
 
-// GetKubeClient returns the client to access k8s resources.
+// updateGateway writes the servers of the updated Gateway, either by replacing the whole
+// Gateway or, when the feature is rolled out to the Ingress, by patching its servers.
+func (r *Reconciler) updateGateway(ctx context.Context, ing *v1alpha1.Ingress, gateway, updated *v1beta1.Gateway) (*v1beta1.Gateway, error) {
+	client := r.istioClientSet.NetworkingV1beta1().Gateways(updated.Namespace)
+	if !config.FromContext(ctx).Istio.FeatureEnabled(config.PatchGatewayUpdates, ing) {
+		return client.Update(ctx, updated, metav1.UpdateOptions{})
+	}
+	patch, err := resources.MakeGatewayServersPatch(gateway, updated)
+	if err != nil {
+		return nil, err
+	}
+	return client.Patch(ctx, updated.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+}
+
+// GetKubeClient returns the client to access the k8s resources next to the gateways.
 func (r *Reconciler) GetKubeClient() kubernetes.Interface {
+	if r.controlPlane != nil {
+		return r.controlPlane.kubeclient
+	}
 	return r.kubeclient
 }
 
-// GetSecretLister returns the lister for Secret.
+// GetSecretLister returns the lister for the Secrets next to the gateways.
 func (r *Reconciler) GetSecretLister() corev1listers.SecretLister {
+	if r.controlPlane != nil {
+		return r.controlPlane.secretLister
+	}
 	return r.secretLister
 }
 
@@ -535,6 +1491,39 @@ func (r *Reconciler) GetDestinationRuleLister() istiolisters.DestinationRuleList
 	return r.destinationRuleLister
 }
 
+// GetAuthorizationPolicyLister returns the lister for AuthorizationPolicy.
+func (r *Reconciler) GetAuthorizationPolicyLister() securitylisters.AuthorizationPolicyLister {
+	return r.authorizationPolicyLister
+}
+
+// GetTelemetryLister returns the lister for Telemetry.
+func (r *Reconciler) GetTelemetryLister() telemetrylisters.TelemetryLister {
+	return r.telemetryLister
+}
+
+// GetEnvoyFilterLister returns the lister for EnvoyFilter.
+func (r *Reconciler) GetEnvoyFilterLister() istiov1alpha3listers.EnvoyFilterLister {
+	return r.envoyFilterLister
+}
+
+// GetRequestAuthenticationLister returns the lister for RequestAuthentication.
+func (r *Reconciler) GetRequestAuthenticationLister() securitylisters.RequestAuthenticationLister {
+	return r.requestAuthenticationLister
+}
+
+// GetWasmPluginLister returns the lister for WasmPlugin.
+func (r *Reconciler) GetWasmPluginLister() extensionslisters.WasmPluginLister {
+	return r.wasmPluginLister
+}
+
+// newReconcileGroup returns an errgroup bounded by the configured reconcile concurrency,
+// used to reconcile sub-resources of an Ingress that do not depend on each other.
+func newReconcileGroup(ctx context.Context) (*errgroup.Group, context.Context) {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(config.FromContext(ctx).Istio.ReconcileConcurrencyLimit())
+	return g, gctx
+}
+
 func gatewayServiceURL(gateways []config.Gateway) string {
 	if len(gateways) == 0 {
 		return ""
@@ -557,26 +1546,30 @@ func getLBStatus(gatewayServiceURL string) []v1alpha1.LoadBalancerIngressStatus
 	}
 }
 
-func shouldReconcileExternalDomainTLS(ing *v1alpha1.Ingress) bool {
-	return isIngressPublic(ing) && len(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP)) > 0
-}
-
-func shouldReconcileClusterLocalDomainTLS(ing *v1alpha1.Ingress) bool {
-	return len(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityClusterLocal)) > 0
-}
-
-func shouldReconcileHTTPServer(ing *v1alpha1.Ingress) bool {
-	// We will create an Ingress specific HTTPServer when
-	// 1. external-domain-tls is enabled as in this case users want us to fully handle the TLS/HTTP behavior,
-	// 2. HTTPOption is set to Redirected as we don't have default HTTP server supporting HTTP redirection.
-	return isIngressPublic(ing) && (ing.Spec.HTTPOption == v1alpha1.HTTPOptionRedirected || len(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP)) > 0)
-}
-
-func isIngressPublic(ing *v1alpha1.Ingress) bool {
-	for _, rule := range ing.Spec.Rules {
-		if rule.Visibility == v1alpha1.IngressVisibilityExternalIP {
-			return true
+// unsupportedFeatures returns the annotations of the Ingress requesting features that rely on
+// optional Istio APIs which were not served when the controller started.
+func (r *Reconciler) unsupportedFeatures(ing *v1alpha1.Ingress) []string {
+	var unsupported []string
+	if r.envoyFilterLister == nil {
+		if ing.Annotations[resources.EnvoyFilterTemplateAnnotationKey] != "" {
+			unsupported = append(unsupported, resources.EnvoyFilterTemplateAnnotationKey)
+		}
+		if resources.HasRateLimitAnnotations(ing) {
+			unsupported = append(unsupported, resources.RateLimitAnnotationKey)
 		}
 	}
-	return false
+	if resources.HasJWTAnnotations(ing) && r.requestAuthenticationLister == nil {
+		unsupported = append(unsupported, resources.JWTIssuerAnnotationKey)
+	}
+	if r.telemetryLister == nil {
+		for _, key := range []string{resources.AccessLoggingAnnotationKey, resources.TracingSamplingAnnotationKey} {
+			if ing.Annotations[key] != "" {
+				unsupported = append(unsupported, key)
+			}
+		}
+	}
+	if ing.Annotations[resources.WasmPluginAnnotationKey] != "" && r.wasmPluginLister == nil {
+		unsupported = append(unsupported, resources.WasmPluginAnnotationKey)
+	}
+	return unsupported
 }
@@ -18,8 +18,8 @@ package ingress
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -27,9 +27,14 @@ import (
 	// Inject our fakes
 	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
 	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/extensions/v1alpha1/wasmplugin/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1alpha3/envoyfilter/fake"
 	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/destinationrule/fake"
 	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/gateway/fake"
 	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/networking/v1beta1/virtualservice/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/security/v1beta1/authorizationpolicy/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/security/v1beta1/requestauthentication/fake"
+	_ "knative.dev/net-istio/pkg/client/istio/injection/informers/telemetry/v1alpha1/telemetry/fake"
 	fakenetworkingclient "knative.dev/networking/pkg/client/injection/client/fake"
 	fakeingressclient "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress/fake"
 	"knative.dev/networking/pkg/ingress"
@@ -50,20 +55,32 @@ import (
 	"google.golang.org/protobuf/testing/protocmp"
 
 	istiov1beta1 "istio.io/api/networking/v1beta1"
+	extensionsv1alpha1 "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	securityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	telemetryv1alpha1 "istio.io/client-go/pkg/apis/telemetry/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/fips"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/hooks"
 	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/redact"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
@@ -101,7 +118,7 @@ var (
 )
 
 var (
-	nonWildcardCert, _ = resources.GenerateCertificate([]string{"host-1.example.com"}, "secret0", "istio-system")
+	nonWildcardCert, _ = resources.GenerateCertificate([]string{"host-1.example.com", "host-tls.example.com", "host-tls.test-ns.svc.cluster.local"}, "secret0", "istio-system")
 	wildcardCert, _    = resources.GenerateCertificate([]string{"*.example.com"}, "secret0", "istio-system")
 	selector           = map[string]string{
 		"istio": "ingress",
@@ -328,6 +345,23 @@ var (
 	defaultCmpOptsList = []cmp.Option{protocmp.Transform()}
 )
 
+func readyIngressStatus() v1alpha1.IngressStatus {
+	return v1alpha1.IngressStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:   v1alpha1.IngressConditionLoadBalancerReady,
+				Status: corev1.ConditionTrue,
+			}, {
+				Type:   v1alpha1.IngressConditionNetworkConfigured,
+				Status: corev1.ConditionTrue,
+			}, {
+				Type:   v1alpha1.IngressConditionReady,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+}
+
 func TestReconcile(t *testing.T) {
 	table := TableTest{{
 		Name: "bad workqueue key",
@@ -341,6 +375,58 @@ func TestReconcile(t *testing.T) {
 			addAnnotations(ing("no-virtualservice-yet"),
 				map[string]string{networking.IngressClassAnnotationKey: "fake-controller"}),
 		},
+	}, {
+		Name: "ingress without hosts is not exposed",
+		Objects: []runtime.Object{
+			ingressWithoutHosts("no-hosts", v1alpha1.IngressStatus{}),
+			resources.MakeMeshVirtualService(insertProbe(ing("no-hosts")), gateways),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS,
+				Verb:      "delete",
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("virtualservices"),
+			},
+			Name: "no-hosts-mesh",
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithoutHosts("no-hosts",
+				v1alpha1.IngressStatus{
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     configurationSkipped,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityInfo,
+							Message:  "Skipped rule 0 has no hosts; rule 1 has no hosts.",
+						}, {
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+							Reason:   noRulesReason,
+							Message:  noRulesMessage,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+							Reason:   noRulesReason,
+							Message:  noRulesMessage,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "no-hosts"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("no-hosts", "ingresses.networking.internal.knative.dev"),
+		},
+		Key:     "test-ns/no-hosts",
+		CmpOpts: defaultCmpOptsList,
 	}, {
 		Name:    "observed generation is updated when error is encountered in reconciling, and ingress ready status is unknown",
 		WantErr: true,
@@ -416,6 +502,59 @@ func TestReconcile(t *testing.T) {
 		},
 		Key:     "test-ns/reconcile-failed",
 		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:    "ready ingress keeps its status while the Istio webhooks are down",
+		WantErr: true,
+		WithReactors: []clientgotesting.ReactionFunc{
+			func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				if !action.Matches("update", "virtualservices") {
+					return false, nil, nil
+				}
+				return true, nil, apierrs.NewInternalError(errors.New(`failed calling webhook "validation.istio.io": connection refused`))
+			},
+		},
+		Objects: []runtime.Object{
+			ingressWithStatus("reconcile-failed", readyIngressStatus()),
+			&v1beta1.VirtualService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "reconcile-failed-ingress",
+					Namespace: testNS,
+					Labels: map[string]string{
+						networking.IngressLabelKey: "reconcile-failed",
+					},
+					OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing("reconcile-failed"))},
+				},
+				Spec: istiov1beta1.VirtualService{},
+			},
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-failed")), gateways),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: resources.MakeIngressVirtualService(insertProbe(ing("reconcile-failed")), makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-failed", func() v1alpha1.IngressStatus {
+				status := readyIngressStatus()
+				status.Conditions = append(duckv1.Conditions{{
+					Type:     istioAPIAvailable,
+					Status:   corev1.ConditionFalse,
+					Severity: apis.ConditionSeverityInfo,
+					Reason:   istioAPIUnavailable,
+					Message:  `failed to update VirtualService: Internal error occurred: failed calling webhook "validation.istio.io": connection refused`,
+				}}, status.Conditions...)
+				return status
+			}()),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-failed"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-failed-mesh"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-failed", "ingresses.networking.internal.knative.dev"),
+		},
+		Key:     "test-ns/reconcile-failed",
+		CmpOpts: defaultCmpOptsList,
 	}, {
 		Name: "reconcile VirtualService to match desired one",
 		Objects: []runtime.Object{
@@ -512,6 +651,26 @@ func TestReconcile(t *testing.T) {
 		},
 		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(0)},
 		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name: "repair the VirtualService whose spec was changed out of band",
+		Key:  "test-ns/ingress-drifted",
+		Objects: []runtime.Object{
+			basicReconciledIngress("ingress-drifted"),
+			resources.MakeMeshVirtualService(insertProbe(ing("ingress-drifted")), makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			func() *v1beta1.VirtualService {
+				// The annotations still carry the hash of the generated spec.
+				vs := resources.MakeIngressVirtualService(insertProbe(ing("ingress-drifted")), makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil))
+				vs.Spec.Http = nil
+				return vs
+			}(),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: resources.MakeIngressVirtualService(insertProbe(ing("ingress-drifted")), makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated VirtualService %s/%s", "test-ns", "ingress-drifted-ingress"),
+		},
+		CmpOpts: defaultCmpOptsList,
 	}}
 
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
@@ -520,6 +679,7 @@ func TestReconcile(t *testing.T) {
 			istioClientSet:       istioclient.Get(ctx),
 			virtualServiceLister: listers.GetVirtualServiceLister(),
 			gatewayLister:        listers.GetGatewayLister(),
+			svcLister:            listers.GetK8sServiceLister(),
 			statusManager:        ctx.Value(FakeStatusManagerKey).(status.Manager),
 		}
 
@@ -709,57 +869,27 @@ func TestReconcile_EnableSystemInternalTLS(t *testing.T) {
 		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
 		Key:            "test-ns/reconcile-virtualservice",
 		CmpOpts:        defaultCmpOptsList,
-	},
-	}
-
-	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
-		r := &Reconciler{
-			kubeclient:            kubeclient.Get(ctx),
-			istioClientSet:        istioclient.Get(ctx),
-			virtualServiceLister:  listers.GetVirtualServiceLister(),
-			destinationRuleLister: listers.GetDestinationRuleLister(),
-			gatewayLister:         listers.GetGatewayLister(),
-			svcLister:             listers.GetK8sServiceLister(),
-			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
-		}
-
-		testConfig := ReconcilerTestConfig()
-		testConfig.Network.SystemInternalTLS = netconfig.EncryptionEnabled
-		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
-			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
-				ConfigStore: &testConfigStore{
-					config: testConfig,
-				}})
-	}))
-}
-
-func TestReconcile_ExternalDomainTLS(t *testing.T) {
-	table := TableTest{{
-		Name:                    "create Ingress Gateway to match newly created Ingress",
+	}, {
+		Name:                    "create DestinationRules for a DomainMapping",
 		SkipNamespaceValidation: true,
 		Objects: []runtime.Object{
-			ingressWithTLS("reconciling-ingress", externalIngressTLS),
-			originSecret("istio-system", "secret0"),
-			ingressService,
+			domainMappingIngWithStatus("reconcile-virtualservice", v1alpha1.IngressStatus{}),
+			ingressServiceHTTP1,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
 		},
 		WantCreates: []runtime.Object{
-			// The newly created per-Ingress Gateway.
-			gateway(externalIngressTLSGatewayName, testNS, []*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer},
-				withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
-			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
-		},
-		WantPatches: []clientgotesting.PatchActionImpl{
-			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+			resources.MakeDomainMappingDestinationRule("test-service.test-ns.svc.cluster.local", domainMappingTarget, ing("reconcile-virtualservice")),
+			withDomainMappingEncryption(resources.MakeMeshVirtualService(insertProbe(domainMappingIngWithStatus("reconcile-virtualservice", v1alpha1.IngressStatus{})), gateways)),
+			withDomainMappingEncryption(resources.MakeIngressVirtualService(insertProbe(domainMappingIngWithStatus("reconcile-virtualservice", v1alpha1.IngressStatus{})),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil))),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ingressWithTLSAndStatus("reconciling-ingress",
-				externalIngressTLS,
+			Object: domainMappingIngWithStatus("reconcile-virtualservice",
 				v1alpha1.IngressStatus{
 					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
 						Ingress: []v1alpha1.LoadBalancerIngressStatus{
-							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+							{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
 						},
 					},
 					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
@@ -786,47 +916,88 @@ func TestReconcile_ExternalDomainTLS(t *testing.T) {
 			),
 		}},
 		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
-			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
-			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created DestinationRule %q", "test-service.test-ns.svc.cluster.local"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
 		},
-		Key:     "test-ns/reconciling-ingress",
-		CmpOpts: defaultCmpOptsList,
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
 	}, {
-		Name:                    "Update Ingress Gateway to match Ingress",
+		Name:                    "repair the DestinationRule whose spec was changed out of band",
 		SkipNamespaceValidation: true,
+		Key:                     "test-ns/ingress-drifted",
 		Objects: []runtime.Object{
-			ingressWithTLS("reconciling-ingress", externalIngressTLS),
-			// The existing Ingress gateway does not have HTTPS server.
-			gateway(externalIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{}, withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-			originSecret("istio-system", "secret0"),
-			ingressService,
-		},
-		WantCreates: []runtime.Object{
-			gateway(externalIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{}, withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-
-			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
-			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
+			basicReconciledIngress("ingress-drifted"),
+			ingressServiceHTTP1,
+			resources.MakeMeshVirtualService(insertProbe(ing("ingress-drifted")), makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			resources.MakeIngressVirtualService(insertProbe(ing("ingress-drifted")), makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			func() *v1beta1.DestinationRule {
+				// The annotations still carry the hash of the generated spec.
+				dr := resources.MakeInternalEncryptionDestinationRule("test-service.test-ns.svc.cluster.local", ing("ingress-drifted"), false)
+				dr.Spec.TrafficPolicy.Tls = nil
+				return dr
+			}(),
 		},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: gateway(externalIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
+			Object: resources.MakeInternalEncryptionDestinationRule("test-service.test-ns.svc.cluster.local", ing("ingress-drifted"), false),
 		}},
-		WantPatches: []clientgotesting.PatchActionImpl{
-			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated DestinationRule %s/%s", "test-ns", "test-service.test-ns.svc.cluster.local"),
+		},
+		CmpOpts: defaultCmpOptsList,
+	},
+	}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		testConfig := ReconcilerTestConfig()
+		testConfig.Network.SystemInternalTLS = netconfig.EncryptionEnabled
+		// The local gateways terminate the TLS of the targets of the DomainMappings.
+		testConfig.Network.ClusterLocalDomainTLS = netconfig.EncryptionEnabled
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: testConfig,
+				}})
+	}))
+}
+
+func TestReconcile_AmbientMode(t *testing.T) {
+	table := TableTest{{
+		Name:                    "DestinationRules are still created on an ambient mesh",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			ingressServiceHTTP1,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeInternalEncryptionDestinationRule("test-service.test-ns.svc.cluster.local", ing("reconcile-virtualservice"), false),
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ingressWithTLSAndStatus("reconciling-ingress",
-				externalIngressTLS,
+			Object: ingressWithStatus("reconcile-virtualservice",
 				v1alpha1.IngressStatus{
 					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
 						Ingress: []v1alpha1.LoadBalancerIngressStatus{
-							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+							{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
 						},
 					},
 					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
@@ -853,46 +1024,2151 @@ func TestReconcile_ExternalDomainTLS(t *testing.T) {
 			),
 		}},
 		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
-			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
-			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
-		},
-		Key:     "test-ns/reconciling-ingress",
-		CmpOpts: defaultCmpOptsList,
-	}, {
-		Name:                    "new Ingress using wildcard certificate",
-		SkipNamespaceValidation: true,
-		Objects: []runtime.Object{
-			ingressWithTLS("reconciling-ingress", externalIngressTLS),
-			wildcardCert,
-			ingressService,
-		},
-		WantCreates: []runtime.Object{
-			wildcardGateway(resources.WildcardGatewayName(wildcardCert.Name, ingressService.Namespace, ingressService.Name), "istio-system",
-				[]*istiov1beta1.Server{wildcardTLSServer}, selector),
-			// The newly created per-Ingress Gateway.
-			gateway(externalIngressTLSGatewayName, testNS, []*istiov1beta1.Server{ingressHTTPServer},
-				withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-
-			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
-			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"istio-system/" + resources.WildcardGatewayName(wildcardCert.Name, ingressService.Namespace, ingressService.Name),
-				"test-ns/" + externalIngressTLSGatewayName}, nil)),
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created DestinationRule %q", "test-service.test-ns.svc.cluster.local"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
 		},
 		WantPatches: []clientgotesting.PatchActionImpl{
-			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
 		},
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ingressWithTLSAndStatus("reconciling-ingress",
-				externalIngressTLS,
-				v1alpha1.IngressStatus{
-					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
-						Ingress: []v1alpha1.LoadBalancerIngressStatus{
-							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
-						},
-					},
-					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
-						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		testConfig := ReconcilerTestConfig()
+		testConfig.Network.SystemInternalTLS = netconfig.EncryptionEnabled
+		testConfig.Istio.AmbientMode = true
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: testConfig,
+				}})
+	}))
+}
+
+func TestReconcile_AuthorizationPolicies(t *testing.T) {
+	revisionService := func(name string) *corev1.Service {
+		svc := ingressServiceHTTP1.DeepCopy()
+		svc.Name = name
+		svc.Labels = map[string]string{resources.RevisionLabelKey: "test-revision"}
+		return svc
+	}
+	sourceNamespaces := []string{"istio-system", system.Namespace()}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "create AuthorizationPolicy for the backend",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			revisionService("test-service"),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeAuthorizationPolicy(ing("reconcile-virtualservice"), revisionService("test-service"), sourceNamespaces),
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %q", "reconcile-virtualservice-test-service"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "delete stale AuthorizationPolicy",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			revisionService("test-service"),
+			resources.MakeAuthorizationPolicy(ing("reconcile-virtualservice"), revisionService("test-service"), sourceNamespaces),
+			resources.MakeAuthorizationPolicy(ing("reconcile-virtualservice"), revisionService("old-service"), sourceNamespaces),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS,
+				Verb:      "delete",
+				Resource:  securityv1beta1.SchemeGroupVersion.WithResource("authorizationpolicies"),
+			},
+			Name: "reconcile-virtualservice-old-service",
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:                kubeclient.Get(ctx),
+			istioClientSet:            istioclient.Get(ctx),
+			virtualServiceLister:      listers.GetVirtualServiceLister(),
+			destinationRuleLister:     listers.GetDestinationRuleLister(),
+			authorizationPolicyLister: listers.GetAuthorizationPolicyLister(),
+			gatewayLister:             listers.GetGatewayLister(),
+			svcLister:                 listers.GetK8sServiceLister(),
+			statusManager:             ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		testConfig := ReconcilerTestConfig()
+		testConfig.Istio.EnableAuthorizationPolicies = true
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: testConfig,
+				}})
+	}))
+}
+
+func TestReconcile_Telemetries(t *testing.T) {
+	revisionService := func(name string) *corev1.Service {
+		svc := ingressServiceHTTP1.DeepCopy()
+		svc.Name = name
+		svc.Labels = map[string]string{resources.RevisionLabelKey: "test-revision"}
+		return svc
+	}
+	withAccessLogging := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.AccessLoggingAnnotationKey] = "true"
+		return ing
+	}
+	telemetry := func(ing *v1alpha1.Ingress, svc *corev1.Service) *telemetryv1alpha1.Telemetry {
+		t, err := resources.MakeTelemetry(ing, svc)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "create Telemetry for the backend",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withAccessLogging(ing("reconcile-virtualservice")),
+			revisionService("test-service"),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			telemetry(withAccessLogging(ing("reconcile-virtualservice")), revisionService("test-service")),
+			resources.MakeMeshVirtualService(insertProbe(withAccessLogging(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withAccessLogging(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withAccessLogging(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created Telemetry %q", "reconcile-virtualservice-test-service"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "delete Telemetry when the annotations are removed",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			revisionService("test-service"),
+			telemetry(withAccessLogging(ing("reconcile-virtualservice")), revisionService("test-service")),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS,
+				Verb:      "delete",
+				Resource:  telemetryv1alpha1.SchemeGroupVersion.WithResource("telemetries"),
+			},
+			Name: "reconcile-virtualservice-test-service",
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			telemetryLister:       listers.GetTelemetryLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_EnvoyFilters(t *testing.T) {
+	const patches = `
+- applyTo: VIRTUAL_HOST
+  match:
+    context: GATEWAY
+  patch:
+    operation: MERGE
+    value:
+      include_request_attempt_count: true
+`
+	template := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-template",
+			Namespace: system.Namespace(),
+		},
+		Data: map[string]string{resources.EnvoyFilterTemplateKey: patches},
+	}
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	withTemplate := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.EnvoyFilterTemplateAnnotationKey] = template.Name
+		return ing
+	}
+	envoyFilter := func(svc *corev1.Service) *v1alpha3.EnvoyFilter {
+		ef, err := resources.MakeEnvoyFilter(withTemplate(ing("reconcile-virtualservice")), v1alpha1.IngressVisibilityExternalIP, svc, patches)
+		if err != nil {
+			t.Fatal("MakeEnvoyFilter() =", err)
+		}
+		return ef
+	}
+	editedPatches := strings.ReplaceAll(patches, "include_request_attempt_count", "include_attempt_count_in_response")
+	editedTemplate := template.DeepCopy()
+	editedTemplate.Data[resources.EnvoyFilterTemplateKey] = editedPatches
+	editedEnvoyFilter := func(svc *corev1.Service) *v1alpha3.EnvoyFilter {
+		ef, err := resources.MakeEnvoyFilter(withTemplate(ing("reconcile-virtualservice")), v1alpha1.IngressVisibilityExternalIP, svc, editedPatches)
+		if err != nil {
+			t.Fatal("MakeEnvoyFilter() =", err)
+		}
+		return ef
+	}
+	withRateLimit := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.RateLimitAnnotationKey] = "100"
+		return ing
+	}
+	rateLimitFilter := func(svc *corev1.Service) *v1alpha3.EnvoyFilter {
+		ing := withRateLimit(ing("reconcile-virtualservice"))
+		ef, err := resources.MakeRateLimitEnvoyFilter(ing, svc, resources.PublicHosts(ing))
+		if err != nil {
+			t.Fatal("MakeRateLimitEnvoyFilter() =", err)
+		}
+		return ef
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "create EnvoyFilters from the template",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withTemplate(ing("reconcile-virtualservice")),
+			template,
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withTemplate(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withTemplate(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			envoyFilter(testIngressService),
+			envoyFilter(ingressService),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withTemplate(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created EnvoyFilter %s/%s", "istio-system", envoyFilter(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created EnvoyFilter %s/%s", "istio-system", envoyFilter(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "update EnvoyFilters when the template is edited",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withTemplate(ing("reconcile-virtualservice")),
+			editedTemplate,
+			ingressService,
+			testIngressService,
+			envoyFilter(testIngressService),
+			envoyFilter(ingressService),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withTemplate(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withTemplate(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: editedEnvoyFilter(testIngressService),
+		}, {
+			Object: editedEnvoyFilter(ingressService),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withTemplate(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated EnvoyFilter %s/%s", "istio-system", envoyFilter(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated EnvoyFilter %s/%s", "istio-system", envoyFilter(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "create rate limit EnvoyFilters",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withRateLimit(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withRateLimit(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withRateLimit(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			rateLimitFilter(testIngressService),
+			rateLimitFilter(ingressService),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withRateLimit(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created EnvoyFilter %s/%s", "istio-system", rateLimitFilter(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created EnvoyFilter %s/%s", "istio-system", rateLimitFilter(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "delete EnvoyFilters when the template is removed",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			envoyFilter(testIngressService),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  v1alpha3.SchemeGroupVersion.WithResource("envoyfilters"),
+			},
+			Name: envoyFilter(testIngressService).Name,
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			envoyFilterLister:     listers.GetEnvoyFilterLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			configMapLister:       listers.GetConfigMapLister(),
+			tracker:               &NullTracker{},
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_WasmPlugins(t *testing.T) {
+	const spec = `
+url: oci://ghcr.io/example/header-normalizer:v1
+phase: AUTHN
+`
+	template := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "header-normalizer",
+			Namespace: system.Namespace(),
+		},
+		Data: map[string]string{resources.WasmPluginSpecKey: spec},
+	}
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	withPlugin := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.WasmPluginAnnotationKey] = template.Name
+		return ing
+	}
+	wasmPlugin := func(svc *corev1.Service) *extensionsv1alpha1.WasmPlugin {
+		wp, err := resources.MakeWasmPlugin(withPlugin(ing("reconcile-virtualservice")), svc, spec)
+		if err != nil {
+			t.Fatal("MakeWasmPlugin() =", err)
+		}
+		return wp
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "attach the registered WasmPlugin to the gateways",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withPlugin(ing("reconcile-virtualservice")),
+			template,
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withPlugin(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withPlugin(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			wasmPlugin(testIngressService),
+			wasmPlugin(ingressService),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withPlugin(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created WasmPlugin %s/%s", "istio-system", wasmPlugin(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created WasmPlugin %s/%s", "istio-system", wasmPlugin(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "delete WasmPlugins when the annotation is removed",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			wasmPlugin(testIngressService),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  extensionsv1alpha1.SchemeGroupVersion.WithResource("wasmplugins"),
+			},
+			Name: wasmPlugin(testIngressService).Name,
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			wasmPluginLister:      listers.GetWasmPluginLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			configMapLister:       listers.GetConfigMapLister(),
+			tracker:               &NullTracker{},
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_AutoPassthroughGateway(t *testing.T) {
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	passthroughConfig := ReconcilerTestConfig()
+	passthroughConfig.Istio.AutoPassthroughGateway = pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")
+
+	passthroughGateway := resources.MakeAutoPassthroughGateway(ing("reconcile-virtualservice"),
+		[]string{"host-tls.test-ns.svc.cluster.local"}, ingressService)
+	withSpecHash()(passthroughGateway)
+
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "expose the cluster-local hosts on the auto passthrough gateway",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			passthroughGateway,
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "delete the auto passthrough gateway once disabled",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			passthroughGateway,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS,
+				Verb:      "delete",
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("gateways"),
+			},
+			Name: passthroughGateway.Name,
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	factory := func(cfg *config.Config) Factory {
+		return MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+			r := &Reconciler{
+				kubeclient:            kubeclient.Get(ctx),
+				istioClientSet:        istioclient.Get(ctx),
+				virtualServiceLister:  listers.GetVirtualServiceLister(),
+				destinationRuleLister: listers.GetDestinationRuleLister(),
+				gatewayLister:         listers.GetGatewayLister(),
+				svcLister:             listers.GetK8sServiceLister(),
+				statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+			}
+
+			return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+				listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+					ConfigStore: &testConfigStore{
+						config: cfg,
+					}})
+		})
+	}
+	table[:1].Test(t, factory(passthroughConfig))
+	table[1:].Test(t, factory(ReconcilerTestConfig()))
+}
+
+func TestReconcile_SecurityHeaders(t *testing.T) {
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	securityHeadersConfig := ReconcilerTestConfig()
+	securityHeadersConfig.Istio.EnableSecurityHeaders = true
+
+	withSecurityHeaders := func(enabled string) func(*v1alpha1.Ingress) *v1alpha1.Ingress {
+		return func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+			ing.Annotations[resources.SecurityHeadersAnnotationKey] = enabled
+			return ing
+		}
+	}
+	optedOut := withSecurityHeaders("false")
+	securedVirtualService := func(vs *v1beta1.VirtualService) *v1beta1.VirtualService {
+		resources.WithSecurityResponseHeaders(vs)
+		return vs
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "set the security headers on the routes",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			securedVirtualService(resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways)),
+			securedVirtualService(resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil))),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "Ingresses opt out of the security headers",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			optedOut(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(optedOut(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(optedOut(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: optedOut(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: securityHeadersConfig,
+				}})
+	}))
+}
+
+func TestReconcile_Hooks(t *testing.T) {
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	platformLabels := hooks.Hooks{hooks.Funcs{
+		VirtualService: func(_ context.Context, ing *v1alpha1.Ingress, vs *v1beta1.VirtualService) error {
+			vs.Labels["platform.example.com/owner"] = ing.Namespace
+			return nil
+		},
+	}}
+	labeledVirtualService := func(vs *v1beta1.VirtualService) *v1beta1.VirtualService {
+		vs.Labels["platform.example.com/owner"] = "test-ns"
+		return vs
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "the hooks mutate the VirtualServices",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			labeledVirtualService(resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways)),
+			labeledVirtualService(resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil))),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+			hooks:                 platformLabels,
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_RoutingRules(t *testing.T) {
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	withRoutingRules := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.RoutingRulesAnnotationKey] = `
+- headers:
+    x-plan: premium
+  serviceName: test-service`
+		return ing
+	}
+	routedVirtualService := func(vs *v1beta1.VirtualService) *v1beta1.VirtualService {
+		resources.WithRoutingRules(vs, testNS, []resources.RoutingRule{{
+			Headers:     map[string]string{"x-plan": "premium"},
+			ServiceName: "test-service",
+		}})
+		return vs
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "route the requests matching the rules to their backend",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withRoutingRules(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withRoutingRules(ing("reconcile-virtualservice"))), gateways),
+			routedVirtualService(resources.MakeIngressVirtualService(insertProbe(withRoutingRules(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil))),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withRoutingRules(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_UnsupportedFeatures(t *testing.T) {
+	withPlugin := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.WasmPluginAnnotationKey] = "header-normalizer"
+		return ing
+	}
+	failedStatus := v1alpha1.IngressStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Reason:   "IstioFeatureUnsupported",
+				Message:  "the mesh does not serve the Istio APIs required by networking.knative.dev/wasm-plugin, the controller must be restarted once they are installed",
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionUnknown,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Reason:   notReconciledReason,
+				Message:  notReconciledMessage,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "fail Ingresses requesting features the mesh does not serve",
+		SkipNamespaceValidation: true,
+		WantErr:                 true,
+		Objects: []runtime.Object{
+			withPlugin(ing("reconcile-virtualservice")),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withPlugin(ingressWithStatus("reconcile-virtualservice", failedStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "the mesh does not serve the Istio APIs required by networking.knative.dev/wasm-plugin, the controller must be restarted once they are installed"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		Key:     "test-ns/reconcile-virtualservice",
+		CmpOpts: defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_SecretNamespaces(t *testing.T) {
+	failedStatus := v1alpha1.IngressStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Reason:   secretNamespaceNotAllowed,
+				Message:  "the secrets istio-system/secret0 are not in the namespaces allowed to hold TLS secrets",
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionUnknown,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Reason:   notReconciledReason,
+				Message:  notReconciledMessage,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "fail Ingresses referencing secrets outside of the allowed namespaces",
+		SkipNamespaceValidation: true,
+		WantErr:                 true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			originSecret("istio-system", "secret0"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress", externalIngressTLS, failedStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "the secrets istio-system/secret0 are not in the namespaces allowed to hold TLS secrets"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			secretLister:          listers.GetSecretLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			tracker:               &NullTracker{},
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		cfg := ReconcilerTestConfig()
+		cfg.Istio.SecretNamespaces = sets.New("tenant-a")
+		cfg.Network.ExternalDomainTLS = true
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				}})
+	}))
+}
+
+func TestReconcile_DedicatedGateways(t *testing.T) {
+	withTenant := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Labels["tenant"] = "a"
+		return ing
+	}
+	failedStatus := v1alpha1.IngressStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Reason:   gatewayNotAllowed,
+				Message:  "the gateways tenant-a/dedicated are dedicated to other namespaces than test-ns",
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionUnknown,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionFalse,
+				Severity: apis.ConditionSeverityError,
+				Reason:   notReconciledReason,
+				Message:  notReconciledMessage,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "fail Ingresses selecting gateways dedicated to other namespaces",
+		SkipNamespaceValidation: true,
+		WantErr:                 true,
+		Objects: []runtime.Object{
+			withTenant(ing("reconcile-virtualservice")),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withTenant(ingressWithStatus("reconcile-virtualservice", failedStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "the gateways tenant-a/dedicated are dedicated to other namespaces than test-ns"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		Key:     "test-ns/reconcile-virtualservice",
+		CmpOpts: defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			statusManager:         ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		cfg := ReconcilerTestConfig()
+		cfg.Istio.IngressGateways = append(cfg.Istio.IngressGateways, config.Gateway{
+			Namespace:     "tenant-a",
+			Name:          "dedicated",
+			ServiceURL:    pkgnet.GetServiceHostname("istio-ingressgateway", "tenant-a"),
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			Namespaces:    []string{"tenant-a"},
+		})
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				}})
+	}))
+}
+
+func TestReconcile_GatewayAuthorization(t *testing.T) {
+	testIngressService := ingressService.DeepCopy()
+	testIngressService.Name = "test-ingressgateway"
+
+	withJWT := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.JWTIssuerAnnotationKey] = "https://issuer.example.com"
+		return ing
+	}
+	requestAuthentication := func(svc *corev1.Service) *securityv1beta1.RequestAuthentication {
+		ra, err := resources.MakeRequestAuthentication(withJWT(ing("reconcile-virtualservice")), svc)
+		if err != nil {
+			t.Fatal("MakeRequestAuthentication() =", err)
+		}
+		return ra
+	}
+	authorizationPolicy := func(svc *corev1.Service) *securityv1beta1.AuthorizationPolicy {
+		ing := withJWT(ing("reconcile-virtualservice"))
+		ap, err := resources.MakeJWTAuthorizationPolicy(ing, svc, resources.PublicHosts(ing))
+		if err != nil {
+			t.Fatal("MakeJWTAuthorizationPolicy() =", err)
+		}
+		return ap
+	}
+	withIPAccess := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.AllowedSourceRangesAnnotationKey] = "10.0.0.0/8"
+		ing.Annotations[resources.DeniedSourceRangesAnnotationKey] = "10.1.2.3"
+		return ing
+	}
+	ipAccessPolicy := func(svc *corev1.Service) *securityv1beta1.AuthorizationPolicy {
+		ing := withIPAccess(ing("reconcile-virtualservice"))
+		ap, err := resources.MakeIPAccessAuthorizationPolicy(ing, svc, resources.PublicHosts(ing))
+		if err != nil {
+			t.Fatal("MakeIPAccessAuthorizationPolicy() =", err)
+		}
+		return ap
+	}
+	withExtAuthz := func(ing *v1alpha1.Ingress) *v1alpha1.Ingress {
+		ing.Annotations[resources.ExtAuthzProviderAnnotationKey] = "oauth2-proxy"
+		return ing
+	}
+	extAuthzPolicy := func(svc *corev1.Service) *securityv1beta1.AuthorizationPolicy {
+		ing := withExtAuthz(ing("reconcile-virtualservice"))
+		return resources.MakeExtAuthzAuthorizationPolicy(ing, svc, resources.PublicHosts(ing))
+	}
+	readyStatus := v1alpha1.IngressStatus{
+		PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{DomainInternal: pkgnet.GetServiceHostname("test-ingressgateway", "istio-system")},
+			},
+		},
+		PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+			Ingress: []v1alpha1.LoadBalancerIngressStatus{
+				{MeshOnly: true},
+			},
+		},
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{{
+				Type:     v1alpha1.IngressConditionLoadBalancerReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionNetworkConfigured,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}, {
+				Type:     v1alpha1.IngressConditionReady,
+				Status:   corev1.ConditionTrue,
+				Severity: apis.ConditionSeverityError,
+			}},
+		},
+	}
+
+	table := TableTest{{
+		Name:                    "require JWTs on the gateways",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withJWT(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withJWT(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withJWT(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			requestAuthentication(testIngressService),
+			requestAuthentication(ingressService),
+			authorizationPolicy(testIngressService),
+			authorizationPolicy(ingressService),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withJWT(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created RequestAuthentication %s/%s", "istio-system", requestAuthentication(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created RequestAuthentication %s/%s", "istio-system", requestAuthentication(ingressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", "istio-system", authorizationPolicy(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", "istio-system", authorizationPolicy(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "stop requiring JWTs when the annotations are removed",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ing("reconcile-virtualservice"),
+			requestAuthentication(testIngressService),
+			authorizationPolicy(testIngressService),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(ing("reconcile-virtualservice")), gateways),
+			resources.MakeIngressVirtualService(insertProbe(ing("reconcile-virtualservice")),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  securityv1beta1.SchemeGroupVersion.WithResource("requestauthentications"),
+			},
+			Name: requestAuthentication(testIngressService).Name,
+		}, {
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  securityv1beta1.SchemeGroupVersion.WithResource("authorizationpolicies"),
+			},
+			Name: authorizationPolicy(testIngressService).Name,
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithStatus("reconcile-virtualservice", readyStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "restrict source IPs on the gateways",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withIPAccess(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withIPAccess(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withIPAccess(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			ipAccessPolicy(testIngressService),
+			ipAccessPolicy(ingressService),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withIPAccess(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", "istio-system", ipAccessPolicy(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", "istio-system", ipAccessPolicy(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "keep the IP restrictions when JWTs are no longer required",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withIPAccess(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			requestAuthentication(ingressService),
+			authorizationPolicy(ingressService),
+			ipAccessPolicy(ingressService),
+			ipAccessPolicy(testIngressService),
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withIPAccess(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withIPAccess(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  securityv1beta1.SchemeGroupVersion.WithResource("requestauthentications"),
+			},
+			Name: requestAuthentication(ingressService).Name,
+		}, {
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  securityv1beta1.SchemeGroupVersion.WithResource("authorizationpolicies"),
+			},
+			Name: authorizationPolicy(ingressService).Name,
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withIPAccess(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}, {
+		Name:                    "delegate the authorization to the external provider",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			withExtAuthz(ing("reconcile-virtualservice")),
+			ingressService,
+			testIngressService,
+			gateway("knative-ingress-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+			gateway("knative-test-gateway", system.Namespace(), []*istiov1beta1.Server{irrelevantServer1}),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeMeshVirtualService(insertProbe(withExtAuthz(ing("reconcile-virtualservice"))), gateways),
+			resources.MakeIngressVirtualService(insertProbe(withExtAuthz(ing("reconcile-virtualservice"))),
+				makeGatewayMap([]string{"knative-testing/knative-test-gateway", "knative-testing/" + config.KnativeIngressGateway}, nil)),
+			extAuthzPolicy(testIngressService),
+			extAuthzPolicy(ingressService),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: withExtAuthz(ingressWithStatus("reconcile-virtualservice", readyStatus)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconcile-virtualservice"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconcile-virtualservice-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", "istio-system", extAuthzPolicy(testIngressService).Name),
+			Eventf(corev1.EventTypeNormal, "Created", "Created AuthorizationPolicy %s/%s", "istio-system", extAuthzPolicy(ingressService).Name),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconcile-virtualservice", "ingresses.networking.internal.knative.dev"),
+		},
+		PostConditions: []func(*testing.T, *TableRow){proberCalledTimes(1)},
+		Key:            "test-ns/reconcile-virtualservice",
+		CmpOpts:        defaultCmpOptsList,
+	}}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &Reconciler{
+			kubeclient:                  kubeclient.Get(ctx),
+			istioClientSet:              istioclient.Get(ctx),
+			virtualServiceLister:        listers.GetVirtualServiceLister(),
+			destinationRuleLister:       listers.GetDestinationRuleLister(),
+			authorizationPolicyLister:   listers.GetAuthorizationPolicyLister(),
+			requestAuthenticationLister: listers.GetRequestAuthenticationLister(),
+			gatewayLister:               listers.GetGatewayLister(),
+			svcLister:                   listers.GetK8sServiceLister(),
+			statusManager:               ctx.Value(FakeStatusManagerKey).(status.Manager),
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: ReconcilerTestConfig(),
+				}})
+	}))
+}
+
+func TestReconcile_ExternalDomainTLS(t *testing.T) {
+	otherIngress := ingressWithTLS("other-ingress", externalIngressTLS)
+	otherIngress.UID = "other-ingress-uid"
+	uncoveredCert, _ := resources.GenerateCertificate([]string{"other.example.com"}, "secret1", "istio-system")
+	uncoveredCert.UID = "uid"
+	uncoveredIngressTLS := []v1alpha1.IngressTLS{{
+		Hosts:           []string{"host-tls.example.com"},
+		SecretName:      "secret1",
+		SecretNamespace: "istio-system",
+	}}
+	table := TableTest{{
+		Name:                    "fail when the certificate does not cover the hosts",
+		SkipNamespaceValidation: true,
+		WantErr:                 true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", uncoveredIngressTLS),
+			uncoveredCert,
+			ingressService,
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				uncoveredIngressTLS,
+				v1alpha1.IngressStatus{
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+							Reason:   certificateHostNotCovered,
+							Message:  "the certificate of secret istio-system/secret1 does not cover the host host-tls.example.com",
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionUnknown,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+							Reason:   notReconciledReason,
+							Message:  notReconciledMessage,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "the certificate of secret istio-system/secret1 does not cover the host host-tls.example.com"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "create Ingress Gateway to match newly created Ingress",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			originSecret("istio-system", "secret0"),
+			ingressService,
+		},
+		WantCreates: []runtime.Object{
+			// The newly created per-Ingress Gateway.
+			gateway(externalIngressTLSGatewayName, testNS, []*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{MeshOnly: true},
+						},
+					},
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "fail when the Ingress Gateway belongs to another Ingress",
+		SkipNamespaceValidation: true,
+		WantErr:                 true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			// The truncated name of the Gateway of another Ingress collides.
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{}, WithGatewayOwner(otherIngress),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			originSecret("istio-system", "secret0"),
+			ingressService,
+		},
+		WantCreates: []runtime.Object{
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{}, WithGatewayOwner(otherIngress),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionUnknown,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+							Reason:   "NotOwned",
+							Message:  fmt.Sprintf("There is an existing Gateway %q that we do not own.", externalIngressTLSGatewayName),
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+							Reason:   notReconciledReason,
+							Message:  notReconciledMessage,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "notowned: owner: reconciling-ingress does not own Gateway: %s/%s",
+				testNS, externalIngressTLSGatewayName),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "Update Ingress Gateway to match Ingress",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			// The existing Ingress gateway does not have HTTPS server.
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			originSecret("istio-system", "secret0"),
+			ingressService,
+		},
+		WantCreates: []runtime.Object{
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{MeshOnly: true},
+						},
+					},
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "Repair the Ingress Gateway whose spec was changed out of band",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			// The servers were removed, the annotations still carry the hash of the generated spec.
+			driftedGateway(gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash())),
+			originSecret("istio-system", "secret0"),
+			ingressService,
+		},
+		WantCreates: []runtime.Object{
+			driftedGateway(gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash())),
+
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{MeshOnly: true},
+						},
+					},
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "new Ingress using wildcard certificate",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			wildcardCert,
+			ingressService,
+		},
+		WantCreates: []runtime.Object{
+			wildcardGateway(resources.WildcardGatewayName(wildcardCert.Name, ingressService.Namespace, ingressService.Name), "istio-system",
+				[]*istiov1beta1.Server{wildcardTLSServer}, selector),
+			// The newly created per-Ingress Gateway.
+			gateway(externalIngressTLSGatewayName, testNS, []*istiov1beta1.Server{ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"istio-system/" + resources.WildcardGatewayName(wildcardCert.Name, ingressService.Namespace, ingressService.Name),
+				"test-ns/" + externalIngressTLSGatewayName}, nil)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{MeshOnly: true},
+						},
+					},
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway istio-system/%s: added servers https (*.example.com)",
+				resources.WildcardGatewayName(wildcardCert.Name, ingressService.Namespace, ingressService.Name)),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress added servers https (*.example.com)"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name: "No preinstalled Ingress service",
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			originSecret("istio-system", "secret0"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionUnknown,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionUnknown,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionUnknown,
+							Severity: apis.ConditionSeverityError,
+							Reason:   notReconciledReason,
+							Message:  notReconciledMessage,
+						}},
+					},
+				},
+			),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeWarning, "InternalError", "service \"istio-ingressgateway\" not found"),
+		},
+		// Error should be returned when there is no preinstalled gateways.
+		WantErr: true,
+		Key:     "test-ns/reconciling-ingress",
+	}, {
+		Name:                    "delete Ingress",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
+			// ingressHTTPRedirectServer should not be deleted when deleting ingress related TLS server..
+			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+		},
+		WantCreates: []runtime.Object{
+			// The creation of gateways are triggered when setting up the test.
+			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{ingressHTTPRedirectServer, irrelevantServer}),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ""),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s: removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)",
+				system.Namespace(), config.KnativeIngressGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)"),
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "delete ingress with leftover secrets",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
+			// ingressHTTPRedirectServer should not be deleted when deleting ingress related TLS server..
+			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+			targetSecret("istio-system", "targetSecret", resources.MakeTargetSecretLabels("secret0", "istio-system")),
+		},
+		WantCreates: []runtime.Object{
+			// The creation of gateways are triggered when setting up the test.
+			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{ingressHTTPRedirectServer, irrelevantServer}),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ""),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  corev1.SchemeGroupVersion.WithResource("secrets"),
+			},
+			Name: "targetSecret",
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s: removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)",
+				system.Namespace(), config.KnativeIngressGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)"),
+			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+		},
+		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
+	}, {
+		Name:                    "TLS Secret is not in the namespace of Istio gateway service",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving")),
+			// The namespace (`knative-serving`) of the origin secret is different
+			// from the namespace (`istio-system`) of Istio gateway service.
+			originSecret("knative-serving", "secret0"),
+			ingressService,
+		},
+		WantCreates: []runtime.Object{
+			// The newly created per-Ingress Gateway.
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{withCredentialName(deepCopy(externalIngressTLSServer), targetSecretName), ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
+
+			// The secret copy under istio-system.
+			targetSecret("istio-system", targetSecretName, map[string]string{
+				"networking.internal.knative.dev/certificate-uid": "",
+				networking.OriginSecretNameLabelKey:               "secret0",
+				networking.OriginSecretNamespaceLabelKey:          "knative-serving",
+			}),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
+				ingressTLSWithSecretNamespace("knative-serving"),
+				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
 							{MeshOnly: true},
 						},
 					},
@@ -916,39 +3192,97 @@ func TestReconcile_ExternalDomainTLS(t *testing.T) {
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created %v", redact.Secret(targetSecret("istio-system", targetSecretName, nil))),
 			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
 		},
 		Key:     "test-ns/reconciling-ingress",
 		CmpOpts: defaultCmpOptsList,
 	}, {
-		Name: "No preinstalled Ingress service",
+		Name:                    "Reconcile Target secret",
+		SkipNamespaceValidation: true,
 		Objects: []runtime.Object{
-			ingressWithTLS("reconciling-ingress", externalIngressTLS),
-			originSecret("istio-system", "secret0"),
+			ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving")),
+
+			// The newly created per-Ingress Gateway.
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{withCredentialName(deepCopy(externalIngressTLSServer), targetSecretName), ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			ingressService,
+
+			// The origin secret.
+			originSecret("knative-serving", "secret0"),
+
+			// The target secret that has the Data different from the origin secret. The Data should be reconciled.
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      targetSecretName,
+					Namespace: "istio-system",
+					Labels: map[string]string{
+						networking.OriginSecretNameLabelKey:      "secret0",
+						networking.OriginSecretNamespaceLabelKey: "knative-serving",
+					},
+				},
+				Data: map[string][]byte{
+					"wrong_data": []byte("wrongdata"),
+				},
+			},
+		},
+		WantCreates: []runtime.Object{
+			gateway(externalIngressTLSGatewayName, testNS,
+				[]*istiov1beta1.Server{withCredentialName(deepCopy(externalIngressTLSServer), targetSecretName), ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
 		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      targetSecretName,
+					Namespace: "istio-system",
+					Labels: map[string]string{
+						"networking.internal.knative.dev/certificate-uid": "",
+						networking.OriginSecretNameLabelKey:               "secret0",
+						networking.OriginSecretNamespaceLabelKey:          "knative-serving",
+					},
+				},
+				// The data is expected to be updated to the right one.
+				Data: nonWildcardCert.Data,
+			},
+		}},
 		WantPatches: []clientgotesting.PatchActionImpl{
 			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: ingressWithTLSAndStatus("reconciling-ingress",
-				externalIngressTLS,
+				ingressTLSWithSecretNamespace("knative-serving"),
 				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{MeshOnly: true},
+						},
+					},
 					Status: duckv1.Status{
 						Conditions: duckv1.Conditions{{
 							Type:     v1alpha1.IngressConditionLoadBalancerReady,
-							Status:   corev1.ConditionUnknown,
+							Status:   corev1.ConditionTrue,
 							Severity: apis.ConditionSeverityError,
 						}, {
 							Type:     v1alpha1.IngressConditionNetworkConfigured,
-							Status:   corev1.ConditionUnknown,
+							Status:   corev1.ConditionTrue,
 							Severity: apis.ConditionSeverityError,
 						}, {
 							Type:     v1alpha1.IngressConditionReady,
-							Status:   corev1.ConditionUnknown,
+							Status:   corev1.ConditionTrue,
 							Severity: apis.ConditionSeverityError,
-							Reason:   notReconciledReason,
-							Message:  notReconciledMessage,
 						}},
 					},
 				},
@@ -956,199 +3290,277 @@ func TestReconcile_ExternalDomainTLS(t *testing.T) {
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
-			Eventf(corev1.EventTypeWarning, "InternalError", "service \"istio-ingressgateway\" not found"),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated %v", redact.Secret(targetSecret("istio-system", targetSecretName, nil))),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
 		},
-		// Error should be returned when there is no preinstalled gateways.
-		WantErr: true,
 		Key:     "test-ns/reconciling-ingress",
+		CmpOpts: defaultCmpOptsList,
 	}, {
-		Name:                    "delete Ingress",
+		Name:                    "Reconcile with external-domain-tls but cluster local visibility, mesh only",
 		SkipNamespaceValidation: true,
 		Objects: []runtime.Object{
-			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
-			// ingressHTTPRedirectServer should not be deleted when deleting ingress related TLS server..
-			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+			ingressWithTLSClusterLocal("reconciling-ingress", externalIngressTLS),
+			originSecret("istio-system", "secret0"),
 		},
 		WantCreates: []runtime.Object{
-			// The creation of gateways are triggered when setting up the test.
-			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLSClusterLocal("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
 		},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{ingressHTTPRedirectServer, irrelevantServer}),
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: ingressWithTLSAndStatusClusterLocal("reconciling-ingress",
+				externalIngressTLS,
+				v1alpha1.IngressStatus{
+					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
+						},
+					},
+					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
+						Ingress: []v1alpha1.LoadBalancerIngressStatus{
+							{MeshOnly: true},
+						},
+					},
+					Status: duckv1.Status{
+						Conditions: duckv1.Conditions{{
+							Type:     configurationSkipped,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityInfo,
+							Message:  "Skipped the TLS of the cluster-local hosts, as cluster-local-domain-tls is not enabled.",
+						}, {
+							Type:     v1alpha1.IngressConditionLoadBalancerReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionNetworkConfigured,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.IngressConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				},
+			),
 		}},
-		WantPatches: []clientgotesting.PatchActionImpl{
-			patchAddFinalizerAction("reconciling-ingress", ""),
-		},
 		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s", system.Namespace(), config.KnativeIngressGateway),
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
 		},
 		Key:     "test-ns/reconciling-ingress",
 		CmpOpts: defaultCmpOptsList,
-	}, {
-		Name:                    "delete ingress with leftover secrets",
+	}}
+	table.Test(t, MakeFactory(externalDomainTLSReconciler))
+}
+
+func TestReconcile_PatchGatewayUpdates(t *testing.T) {
+	existing := gateway(config.KnativeIngressGateway, system.Namespace(),
+		[]*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}, WithGatewayResourceVersion("1"))
+	updated := gateway(config.KnativeIngressGateway, system.Namespace(),
+		[]*istiov1beta1.Server{ingressHTTPRedirectServer, irrelevantServer}, WithGatewayResourceVersion("1"))
+	patch, err := resources.MakeGatewayServersPatch(existing, updated)
+	if err != nil {
+		t.Fatal("MakeGatewayServersPatch() =", err)
+	}
+
+	table := TableTest{{
+		Name:                    "patch the servers of the Gateway when deleting the Ingress",
 		SkipNamespaceValidation: true,
 		Objects: []runtime.Object{
 			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
-			// ingressHTTPRedirectServer should not be deleted when deleting ingress related TLS server..
-			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
-			targetSecret("istio-system", "targetSecret", resources.MakeTargetSecretLabels("secret0", "istio-system")),
+			existing,
 		},
 		WantCreates: []runtime.Object{
 			// The creation of gateways are triggered when setting up the test.
-			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer, ingressHTTPRedirectServer}),
+			existing,
 		},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{ingressHTTPRedirectServer, irrelevantServer}),
-		}},
+		// The patches are listed by client, the ones of the Knative resources first.
 		WantPatches: []clientgotesting.PatchActionImpl{
 			patchAddFinalizerAction("reconciling-ingress", ""),
+			{Name: config.KnativeIngressGateway, PatchType: types.JSONPatchType, Patch: patch},
 		},
-		WantDeletes: []clientgotesting.DeleteActionImpl{{
-			ActionImpl: clientgotesting.ActionImpl{
-				Namespace: "istio-system",
-				Verb:      "delete",
-				Resource:  corev1.SchemeGroupVersion.WithResource("secrets"),
-			},
-			Name: "targetSecret",
-		}},
 		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s", system.Namespace(), config.KnativeIngressGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s: removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)",
+				system.Namespace(), config.KnativeIngressGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)"),
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
 		},
 		Key:     "test-ns/reconciling-ingress",
 		CmpOpts: defaultCmpOptsList,
-	}, {
-		Name:                    "TLS Secret is not in the namespace of Istio gateway service",
+	}}
+
+	cfg := externalDomainTLSConfig()
+	cfg.Istio.FeatureGates = map[string]config.FeatureGate{config.PatchGatewayUpdates: config.FeatureGateEnabled}
+	table.Test(t, MakeFactory(externalDomainTLSReconcilerWithConfig(cfg)))
+}
+
+// externalDomainTLSReconciler creates a Reconciler programming Gateways for the external TLS of Ingresses.
+func externalDomainTLSReconciler(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+	return externalDomainTLSReconcilerWithConfig(externalDomainTLSConfig())(ctx, listers, cmw)
+}
+
+// externalDomainTLSConfig is the configuration enabling the reconciliation of the Gateways.
+func externalDomainTLSConfig() *config.Config {
+	return &config.Config{
+		Istio: &config.Istio{
+			IngressGateways: []config.Gateway{{
+				Namespace:  system.Namespace(),
+				Name:       config.KnativeIngressGateway,
+				ServiceURL: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system"),
+			}},
+		},
+		Network: &netconfig.Config{
+			HTTPProtocol:      netconfig.HTTPDisabled,
+			ExternalDomainTLS: true,
+		},
+	}
+}
+
+// externalDomainTLSReconcilerWithConfig is externalDomainTLSReconciler with the given configuration.
+func externalDomainTLSReconcilerWithConfig(cfg *config.Config) Ctor {
+	return func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		CreateGateways(ctx, listers)
+
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			secretLister:          listers.GetSecretLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			tracker:               &NullTracker{},
+			statusManager: &fakestatusmanager.FakeStatusManager{
+				FakeIsReady: func(ctx context.Context, ing *v1alpha1.Ingress) (bool, error) {
+					return true, nil
+				},
+			},
+		}
+
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: cfg,
+				},
+			})
+	}
+}
+
+func TestReconcile_FinalizeResetsIstioAPIBackoff(t *testing.T) {
+	key := types.NamespacedName{Namespace: testNS, Name: "reconciling-ingress"}
+	backoff := newIstioAPIBackoff()
+	backoff.next(key)
+
+	table := TableTest{{
+		Name:                    "delete Ingress",
 		SkipNamespaceValidation: true,
 		Objects: []runtime.Object{
-			ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving")),
-			// The namespace (`knative-serving`) of the origin secret is different
-			// from the namespace (`istio-system`) of Istio gateway service.
-			originSecret("knative-serving", "secret0"),
-			ingressService,
+			ingressWithFinalizers("reconciling-ingress", externalIngressTLS, []string{ingressFinalizer}, &deletionTime),
+			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer}),
 		},
 		WantCreates: []runtime.Object{
-			// The newly created per-Ingress Gateway.
-			gateway(externalIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{withCredentialName(deepCopy(externalIngressTLSServer), targetSecretName), ingressHTTPServer},
-				withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-
-			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), externalIngressGateway),
-			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
-
-			// The secret copy under istio-system.
-			targetSecret("istio-system", targetSecretName, map[string]string{
-				"networking.internal.knative.dev/certificate-uid": "",
-				networking.OriginSecretNameLabelKey:               "secret0",
-				networking.OriginSecretNamespaceLabelKey:          "knative-serving",
-			}),
+			// The creation of gateways are triggered when setting up the test.
+			gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer, externalIngressTLSServer}),
 		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: gateway(config.KnativeIngressGateway, system.Namespace(), []*istiov1beta1.Server{irrelevantServer}),
+		}},
 		WantPatches: []clientgotesting.PatchActionImpl{
-			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+			patchAddFinalizerAction("reconciling-ingress", ""),
 		},
-		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ingressWithTLSAndStatus("reconciling-ingress",
-				ingressTLSWithSecretNamespace("knative-serving"),
-				v1alpha1.IngressStatus{
-					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
-						Ingress: []v1alpha1.LoadBalancerIngressStatus{
-							{DomainInternal: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system")},
-						},
-					},
-					PrivateLoadBalancer: &v1alpha1.LoadBalancerStatus{
-						Ingress: []v1alpha1.LoadBalancerIngressStatus{
-							{MeshOnly: true},
-						},
-					},
-					Status: duckv1.Status{
-						Conditions: duckv1.Conditions{{
-							Type:     v1alpha1.IngressConditionLoadBalancerReady,
-							Status:   corev1.ConditionTrue,
-							Severity: apis.ConditionSeverityError,
-						}, {
-							Type:     v1alpha1.IngressConditionNetworkConfigured,
-							Status:   corev1.ConditionTrue,
-							Severity: apis.ConditionSeverityError,
-						}, {
-							Type:     v1alpha1.IngressConditionReady,
-							Status:   corev1.ConditionTrue,
-							Severity: apis.ConditionSeverityError,
-						}},
-					},
-				},
-			),
-		}},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s: removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)",
+				system.Namespace(), config.KnativeIngressGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress removed servers test-ns/reconciling-ingress:0 (host-tls.example.com)"),
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
-			Eventf(corev1.EventTypeNormal, "Created", "Created Secret %s/%s", "istio-system", targetSecretName),
-			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
-			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
 		},
 		Key:     "test-ns/reconciling-ingress",
 		CmpOpts: defaultCmpOptsList,
-	}, {
-		Name:                    "Reconcile Target secret",
-		SkipNamespaceValidation: true,
-		Objects: []runtime.Object{
-			ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving")),
+	}}
 
-			// The newly created per-Ingress Gateway.
-			gateway(externalIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{withCredentialName(deepCopy(externalIngressTLSServer), targetSecretName), ingressHTTPServer},
-				withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-			ingressService,
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		CreateGateways(ctx, listers)
 
-			// The origin secret.
-			originSecret("knative-serving", "secret0"),
+		r := &Reconciler{
+			kubeclient:            kubeclient.Get(ctx),
+			istioClientSet:        istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+			gatewayLister:         listers.GetGatewayLister(),
+			secretLister:          listers.GetSecretLister(),
+			svcLister:             listers.GetK8sServiceLister(),
+			tracker:               &NullTracker{},
+			istioAPIBackoff:       backoff,
+		}
 
-			// The target secret that has the Data different from the origin secret. The Data should be reconciled.
-			&corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      targetSecretName,
-					Namespace: "istio-system",
-					Labels: map[string]string{
-						networking.OriginSecretNameLabelKey:      "secret0",
-						networking.OriginSecretNamespaceLabelKey: "knative-serving",
-					},
-				},
-				Data: map[string][]byte{
-					"wrong_data": []byte("wrongdata"),
+		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: externalDomainTLSConfig(),
 				},
-			},
+			})
+	}))
+
+	if got := len(backoff.failures); got != 0 {
+		t.Errorf("Got the failures of %d Ingresses after the finalization, want 0", got)
+	}
+}
+
+func TestReconcile_FIPSMigration(t *testing.T) {
+	t.Setenv(fips.EnableFIPSModeEnv, "true")
+
+	ing := ingressWithTLS("reconciling-ingress", externalIngressTLS)
+	gatewayName := resources.GatewayName(ing, v1alpha1.IngressVisibilityExternalIP, ingressService)
+	legacyGatewayName := externalIngressTLSGatewayName
+	if gatewayName == legacyGatewayName {
+		t.Fatalf("GatewayName() = %q in FIPS mode, want a different name", gatewayName)
+	}
+	wildcardGatewayName := resources.WildcardGatewayName(wildcardCert.Name, ingressService.Namespace, ingressService.Name)
+	legacyWildcardGatewayName := resources.LegacyWildcardGatewayNames(map[string]*corev1.Secret{"istio-system/secret0": wildcardCert},
+		[]*corev1.Service{ingressService})[wildcardGatewayName]
+	if wildcardGatewayName == legacyWildcardGatewayName {
+		t.Fatalf("WildcardGatewayName() = %q in FIPS mode, want a different name", wildcardGatewayName)
+	}
+
+	table := TableTest{{
+		Name:                    "replace the Gateways of the legacy naming scheme",
+		SkipNamespaceValidation: true,
+		Objects: []runtime.Object{
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			gateway(legacyGatewayName, testNS, []*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			originSecret("istio-system", "secret0"),
+			ingressService,
 		},
 		WantCreates: []runtime.Object{
-			gateway(externalIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{withCredentialName(deepCopy(externalIngressTLSServer), targetSecretName), ingressHTTPServer},
-				withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
-
-			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), externalIngressGateway),
-			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", ingressTLSWithSecretNamespace("knative-serving"))), makeGatewayMap([]string{"test-ns/" + externalIngressTLSGatewayName}, nil)),
+			// The Gateways of the objects are created by the test setup.
+			gateway(legacyGatewayName, testNS, []*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			gateway(gatewayName, testNS, []*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), makeGatewayMap([]string{"test-ns/" + gatewayName}, nil)),
 		},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      targetSecretName,
-					Namespace: "istio-system",
-					Labels: map[string]string{
-						"networking.internal.knative.dev/certificate-uid": "",
-						networking.OriginSecretNameLabelKey:               "secret0",
-						networking.OriginSecretNamespaceLabelKey:          "knative-serving",
-					},
-				},
-				// The data is expected to be updated to the right one.
-				Data: nonWildcardCert.Data,
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: testNS,
+				Verb:      "delete",
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("gateways"),
 			},
+			Name: legacyGatewayName,
 		}},
 		WantPatches: []clientgotesting.PatchActionImpl{
 			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: ingressWithTLSAndStatus("reconciling-ingress",
-				ingressTLSWithSecretNamespace("knative-serving"),
+				externalIngressTLS,
 				v1alpha1.IngressStatus{
 					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
 						Ingress: []v1alpha1.LoadBalancerIngressStatus{
@@ -1180,24 +3592,44 @@ func TestReconcile_ExternalDomainTLS(t *testing.T) {
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
-			Eventf(corev1.EventTypeNormal, "Updated", "Updated Secret %s/%s", "istio-system", targetSecretName),
 			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
 		},
 		Key:     "test-ns/reconciling-ingress",
 		CmpOpts: defaultCmpOptsList,
 	}, {
-		Name:                    "Reconcile with external-domain-tls but cluster local visibility, mesh only",
+		Name:                    "replace the wildcard Gateways of the legacy naming scheme",
 		SkipNamespaceValidation: true,
 		Objects: []runtime.Object{
-			ingressWithTLSClusterLocal("reconciling-ingress", externalIngressTLS),
-			originSecret("istio-system", "secret0"),
+			ingressWithTLS("reconciling-ingress", externalIngressTLS),
+			wildcardGateway(legacyWildcardGatewayName, "istio-system", []*istiov1beta1.Server{wildcardTLSServer}, selector),
+			wildcardCert,
+			ingressService,
 		},
 		WantCreates: []runtime.Object{
-			resources.MakeMeshVirtualService(insertProbe(ingressWithTLSClusterLocal("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			// The Gateways of the objects are created by the test setup.
+			wildcardGateway(legacyWildcardGatewayName, "istio-system", []*istiov1beta1.Server{wildcardTLSServer}, selector),
+			wildcardGateway(wildcardGatewayName, "istio-system", []*istiov1beta1.Server{wildcardTLSServer}, selector),
+			gateway(gatewayName, testNS, []*istiov1beta1.Server{ingressHTTPServer},
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
+			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)), externalIngressGateway),
+			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+				makeGatewayMap([]string{"istio-system/" + wildcardGatewayName, "test-ns/" + gatewayName}, nil)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "istio-system",
+				Verb:      "delete",
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("gateways"),
+			},
+			Name: legacyWildcardGatewayName,
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: ingressWithTLSAndStatusClusterLocal("reconciling-ingress",
+			Object: ingressWithTLSAndStatus("reconciling-ingress",
 				externalIngressTLS,
 				v1alpha1.IngressStatus{
 					PublicLoadBalancer: &v1alpha1.LoadBalancerStatus{
@@ -1230,61 +3662,15 @@ func TestReconcile_ExternalDomainTLS(t *testing.T) {
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway istio-system/%s: added servers https (*.example.com)", wildcardGatewayName),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress added servers https (*.example.com)"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-mesh"),
-		},
-		WantPatches: []clientgotesting.PatchActionImpl{
-			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
+			Eventf(corev1.EventTypeNormal, "Created", "Created VirtualService %q", "reconciling-ingress-ingress"),
 		},
 		Key:     "test-ns/reconciling-ingress",
 		CmpOpts: defaultCmpOptsList,
 	}}
-	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
-
-		// As we use a customized resource name for Gateway CRD (i.e. `gateways`), not the one
-		// originally generated by kubernetes code generator (i.e. `gatewaies`), we have to
-		// explicitly create gateways when setting up the test per suggestion
-		// https://github.com/knative/serving/blob/a6852fc3b6cdce72b99c5d578dd64f2e03dabb8b/vendor/k8s.io/client-go/testing/fixture.go#L292
-		gateways := getGatewaysFromObjects(listers.GetIstioObjects())
-		for _, gateway := range gateways {
-			fakeistioclient.Get(ctx).NetworkingV1beta1().Gateways(gateway.Namespace).Create(ctx, gateway, metav1.CreateOptions{})
-		}
-
-		r := &Reconciler{
-			kubeclient:            kubeclient.Get(ctx),
-			istioClientSet:        istioclient.Get(ctx),
-			virtualServiceLister:  listers.GetVirtualServiceLister(),
-			destinationRuleLister: listers.GetDestinationRuleLister(),
-			gatewayLister:         listers.GetGatewayLister(),
-			secretLister:          listers.GetSecretLister(),
-			svcLister:             listers.GetK8sServiceLister(),
-			tracker:               &NullTracker{},
-			statusManager: &fakestatusmanager.FakeStatusManager{
-				FakeIsReady: func(ctx context.Context, ing *v1alpha1.Ingress) (bool, error) {
-					return true, nil
-				},
-			},
-		}
-
-		return ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
-			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, netconfig.IstioIngressClassName, controller.Options{
-				ConfigStore: &testConfigStore{
-					// Enable reconciling gateway.
-					config: &config.Config{
-						Istio: &config.Istio{
-							IngressGateways: []config.Gateway{{
-								Namespace:  system.Namespace(),
-								Name:       config.KnativeIngressGateway,
-								ServiceURL: pkgnet.GetServiceHostname("istio-ingressgateway", "istio-system"),
-							}},
-						},
-						Network: &netconfig.Config{
-							HTTPProtocol:      netconfig.HTTPDisabled,
-							ExternalDomainTLS: true,
-						},
-					},
-				},
-			})
-	}))
+	table.Test(t, MakeFactory(externalDomainTLSReconciler))
 }
 
 func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
@@ -1298,8 +3684,8 @@ func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
 		},
 		WantCreates: []runtime.Object{
 			gateway(localIngressTLSGatewayName, testNS, []*istiov1beta1.Server{localIngressTLSServer},
-				withOwnerRef(ingressWithTLS("reconciling-ingress", localIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
+				WithGatewayOwner(ingressWithTLS("reconciling-ingress", localIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
 			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", localIngressTLS)), localIngressGateway),
 			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", localIngressTLS)),
 				makeGatewayMap([]string{"knative-testing/" + config.KnativeIngressGateway}, []string{"knative-testing/" + config.KnativeLocalGateway, "test-ns/" + localIngressTLSGatewayName})),
@@ -1356,15 +3742,15 @@ func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
 			ingressWithTLS("reconciling-ingress", localIngressTLS),
 			// The existing Ingress gateway does not have HTTPS server.
 			gateway(localIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{}, withOwnerRef(ingressWithTLS("reconciling-ingress", localIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
+				[]*istiov1beta1.Server{}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", localIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
 			originSecret("istio-system", "secret0"),
 			ingressService,
 		},
 		WantCreates: []runtime.Object{
 			gateway(localIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{}, withOwnerRef(ingressWithTLS("reconciling-ingress", localIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
+				[]*istiov1beta1.Server{}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", localIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
 
 			resources.MakeMeshVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", localIngressTLS)), localIngressGateway),
 			resources.MakeIngressVirtualService(insertProbe(ingressWithTLS("reconciling-ingress", localIngressTLS)),
@@ -1372,8 +3758,8 @@ func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
 		},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: gateway(localIngressTLSGatewayName, testNS,
-				[]*istiov1beta1.Server{localIngressTLSServer}, withOwnerRef(ingressWithTLS("reconciling-ingress", localIngressTLS)),
-				withLabels(gwLabels), withSelector(selector)),
+				[]*istiov1beta1.Server{localIngressTLSServer}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", localIngressTLS)),
+				WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash()),
 		}},
 		WantPatches: []clientgotesting.PatchActionImpl{
 			patchAddFinalizerAction("reconciling-ingress", ingressFinalizer),
@@ -1449,7 +3835,9 @@ func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
 			Name: "targetSecret",
 		}},
 		WantEvents: []string{
-			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s", system.Namespace(), config.KnativeLocalGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Gateway %s/%s: removed servers test-ns/reconciling-ingress:0 (host-tls.test-ns.svc.cluster.local)",
+				system.Namespace(), config.KnativeLocalGateway),
+			Eventf(corev1.EventTypeNormal, "Updated", "Ingress test-ns/reconciling-ingress removed servers test-ns/reconciling-ingress:0 (host-tls.test-ns.svc.cluster.local)"),
 			Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", "reconciling-ingress"),
 		},
 		Key:     "test-ns/reconciling-ingress",
@@ -1457,14 +3845,7 @@ func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
 	}}
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
 
-		// As we use a customized resource name for Gateway CRD (i.e. `gateways`), not the one
-		// originally generated by kubernetes code generator (i.e. `gatewaies`), we have to
-		// explicitly create gateways when setting up the test per suggestion
-		// https://github.com/knative/serving/blob/a6852fc3b6cdce72b99c5d578dd64f2e03dabb8b/vendor/k8s.io/client-go/testing/fixture.go#L292
-		gateways := getGatewaysFromObjects(listers.GetIstioObjects())
-		for _, gateway := range gateways {
-			fakeistioclient.Get(ctx).NetworkingV1beta1().Gateways(gateway.Namespace).Create(ctx, gateway, metav1.CreateOptions{})
-		}
+		CreateGateways(ctx, listers)
 
 		r := &Reconciler{
 			kubeclient:            kubeclient.Get(ctx),
@@ -1507,53 +3888,21 @@ func TestReconcile_ClusterLocalDomainTLS(t *testing.T) {
 	}))
 }
 
-func getGatewaysFromObjects(objects []runtime.Object) []*v1beta1.Gateway {
-	gateways := []*v1beta1.Gateway{}
-	for _, object := range objects {
-		if gateway, ok := object.(*v1beta1.Gateway); ok {
-			gateways = append(gateways, gateway)
-		}
-	}
-	sort.Slice(gateways, func(i, j int) bool {
-		return strings.Compare(gateways[i].Name, gateways[j].Name) == -1
-	})
-	return gateways
-}
-
-type GatewayOpt func(*v1beta1.Gateway)
-
 func gateway(name, namespace string, servers []*istiov1beta1.Server, opts ...GatewayOpt) *v1beta1.Gateway {
-	gw := &v1beta1.Gateway{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: istiov1beta1.Gateway{
-			Servers: servers,
-		},
-	}
-	for _, opt := range opts {
-		opt(gw)
-	}
-	return gw
-}
-
-func withOwnerRef(ing *v1alpha1.Ingress) GatewayOpt {
-	return func(gw *v1beta1.Gateway) {
-		gw.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
-	}
+	return NewGateway(namespace, name, append([]GatewayOpt{WithServers(servers...)}, opts...)...)
 }
 
-func withLabels(labels map[string]string) GatewayOpt {
+// withSpecHash records the hash of the spec. It must be the last option.
+func withSpecHash() GatewayOpt {
 	return func(gw *v1beta1.Gateway) {
-		gw.Labels = labels
+		gw.Annotations = kaccessor.WithSpecHash(gw.Annotations, &gw.Spec)
 	}
 }
 
-func withSelector(selector map[string]string) GatewayOpt {
-	return func(gw *v1beta1.Gateway) {
-		gw.Spec.Selector = selector
-	}
+// driftedGateway removes the servers of the Gateway, leaving its annotations alone.
+func driftedGateway(gw *v1beta1.Gateway) *v1beta1.Gateway {
+	gw.Spec.Servers = nil
+	return gw
 }
 
 func wildcardGateway(name, namespace string, servers []*istiov1beta1.Server, selector map[string]string) *v1beta1.Gateway {
@@ -1561,6 +3910,7 @@ func wildcardGateway(name, namespace string, servers []*istiov1beta1.Server, sel
 	gvk := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
 	gw.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(wildcardCert, gvk)}
 	gw.Spec.Selector = selector
+	withSpecHash()(gw)
 	return gw
 }
 
@@ -1687,6 +4037,25 @@ func ing(name string) *v1alpha1.Ingress {
 	return ingressWithStatus(name, v1alpha1.IngressStatus{})
 }
 
+const domainMappingTarget = "target.test-ns.svc.cluster.local"
+
+// domainMappingIngWithStatus returns the Ingress of a DomainMapping to domainMappingTarget.
+func domainMappingIngWithStatus(name string, status v1alpha1.IngressStatus) *v1alpha1.Ingress {
+	// The rules of ingressWithStatus are shared.
+	ing := ingressWithStatus(name, status).DeepCopy()
+	for _, rule := range ing.Spec.Rules {
+		for i := range rule.HTTP.Paths {
+			rule.HTTP.Paths[i].RewriteHost = domainMappingTarget
+		}
+	}
+	return ing
+}
+
+func withDomainMappingEncryption(vs *v1beta1.VirtualService) *v1beta1.VirtualService {
+	resources.WithDomainMappingEncryption(vs)
+	return vs
+}
+
 func ingWithMultipleSplitsWithStatus(name string, status v1alpha1.IngressStatus) *v1alpha1.Ingress {
 	ing := ingressWithStatus(name, status).DeepCopy()
 	split1 := ing.Spec.Rules[0].HTTP.Paths[0].Splits[0]
@@ -1746,6 +4115,14 @@ func ingressWithTLSClusterLocal(name string, tls []v1alpha1.IngressTLS) *v1alpha
 	return ci
 }
 
+func ingressWithoutHosts(name string, status v1alpha1.IngressStatus) *v1alpha1.Ingress {
+	ci := ingressWithStatus(name, status).DeepCopy()
+	for i := range ci.Spec.Rules {
+		ci.Spec.Rules[i].Hosts = nil
+	}
+	return ci
+}
+
 func ingressWithTLSAndStatus(name string, tls []v1alpha1.IngressTLS, status v1alpha1.IngressStatus) *v1alpha1.Ingress {
 	ci := ingressWithStatus(name, status)
 	ci.Spec.TLS = tls
@@ -1920,8 +4297,8 @@ func TestGlobalResyncOnUpdateNetwork(t *testing.T) {
 		createdGateway := obj.(*v1beta1.Gateway)
 		// The expected gateway should include the Istio TLS server.
 		expectedGateway := gateway(externalIngressTLSGatewayName, testNS,
-			[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-			withLabels(gwLabels), withSelector(selector))
+			[]*istiov1beta1.Server{externalIngressTLSServer, ingressHTTPServer}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+			WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash())
 		if diff := cmp.Diff(createdGateway, expectedGateway, protocmp.Transform()); diff != "" {
 			t.Log("Unexpected Gateway (-want, +got):", diff)
 			return HookIncomplete
@@ -1984,8 +4361,8 @@ func TestGlobalResyncOnUpdateNetwork(t *testing.T) {
 	// Create an Ingress gateway
 	ingressGatewayClient := istioClient.NetworkingV1beta1().Gateways(testNS)
 	ingressGateway := gateway(externalIngressTLSGatewayName, testNS,
-		[]*istiov1beta1.Server{}, withOwnerRef(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
-		withLabels(gwLabels), withSelector(selector))
+		[]*istiov1beta1.Server{}, WithGatewayOwner(ingressWithTLS("reconciling-ingress", externalIngressTLS)),
+		WithGatewayLabels(gwLabels), WithGatewaySelector(selector), withSpecHash())
 	if _, err := ingressGatewayClient.Create(ctx, ingressGateway, metav1.CreateOptions{}); err != nil {
 		t.Fatal("Error creating gateway:", err)
 	}
@@ -2030,10 +4407,51 @@ func makeGatewayMap(publicGateways []string, privateGateways []string) map[v1alp
 func proberCalledTimes(n int) func(*testing.T, *TableRow) {
 	return func(t *testing.T, tr *TableRow) {
 		// ensure that prober gets invoked the required number of times
-		statusManager := tr.Ctx.Value(FakeStatusManagerKey).(*fakestatusmanager.FakeStatusManager)
+		statusManager := tr.Ctx.Value(FakeStatusManagerKey).(*StatusManager)
 		callCount := statusManager.IsReadyCallCount(tr.Objects[0].(*v1alpha1.Ingress))
 		if callCount != n {
 			t.Errorf("statusManager.IsReady called %v times, wanted %v", callCount, n)
 		}
 	}
 }
+
+func TestListVirtualServices(t *testing.T) {
+	vs := func(name, ingress string) *v1beta1.VirtualService {
+		return &v1beta1.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testNS,
+				Labels:    map[string]string{networking.IngressLabelKey: ingress},
+			},
+		}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		cache.NamespaceIndex:         cache.MetaNamespaceIndexFunc,
+		virtualServiceByIngressIndex: indexVirtualServiceByIngress,
+	})
+	for _, obj := range []*v1beta1.VirtualService{vs("a-ingress", "a"), vs("a-mesh", "a"), vs("b-ingress", "b")} {
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal("Failed to add VirtualService:", err)
+		}
+	}
+	lister := istiolisters.NewVirtualServiceLister(indexer)
+
+	for _, r := range []*Reconciler{{
+		virtualServiceLister: lister,
+	}, {
+		virtualServiceLister:  lister,
+		virtualServiceIndexer: indexer,
+	}} {
+		got, err := r.listVirtualServices(testNS, networking.IngressLabelKey, "a")
+		if err != nil {
+			t.Fatal("listVirtualServices() =", err)
+		}
+		names := sets.New[string]()
+		for _, vs := range got {
+			names.Insert(vs.Name)
+		}
+		if want := sets.New("a-ingress", "a-mesh"); !names.Equal(want) {
+			t.Errorf("listVirtualServices() = %v, want = %v", sets.List(names), sets.List(want))
+		}
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/metrics"
+)
+
+const (
+	// istioAPIAvailable is the condition reporting whether the Istio APIs could be
+	// reached during the last reconciliation of the Ingress. It does not affect the
+	// readiness of the Ingress, as the previously programmed resources keep serving.
+	istioAPIAvailable apis.ConditionType = "IstioAPIAvailable"
+
+	istioAPIUnavailable = "IstioAPIUnavailable"
+
+	// istioAPIBaseBackoff and istioAPIMaxBackoff bound the delay before an Ingress is
+	// reconciled again while the Istio APIs are unavailable. The delays are jittered so
+	// that the Ingresses do not all hit the API server again at the same time.
+	istioAPIBaseBackoff = 5 * time.Second
+	istioAPIMaxBackoff  = 5 * time.Minute
+)
+
+var istioAPIUnavailableM = stats.Int64(
+	"istio_api_unavailable_count",
+	"Number of Ingress reconciliations that failed because the Istio APIs were unavailable",
+	stats.UnitDimensionless)
+
+func init() {
+	if err := metrics.RegisterResourceView(&view.View{
+		Description: istioAPIUnavailableM.Description(),
+		Measure:     istioAPIUnavailableM,
+		Aggregation: view.Count(),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// isIstioAPIUnavailable returns whether err reports that the Istio APIs cannot be
+// served, e.g. because the Istio webhooks are down or the Istio CRDs are missing,
+// rather than a problem with the requested change.
+func isIstioAPIUnavailable(err error) bool {
+	var apiStatus apierrs.APIStatus
+	if !errors.As(err, &apiStatus) {
+		return false
+	}
+	status := apiStatus.Status()
+	if strings.Contains(status.Message, "failed calling webhook") && strings.Contains(status.Message, "istio") {
+		return true
+	}
+	if status.Details == nil || !strings.HasSuffix(status.Details.Group, "istio.io") {
+		return false
+	}
+	switch {
+	case apierrs.IsServiceUnavailable(err), apierrs.IsTimeout(err), apierrs.IsServerTimeout(err):
+		return true
+	case apierrs.IsNotFound(err):
+		// The API server does not serve the resource at all when its CRD is missing.
+		return apierrs.HasStatusCause(err, metav1.CauseTypeUnexpectedServerResponse)
+	default:
+		return false
+	}
+}
+
+// istioAPIBackoff tracks how many consecutive reconciliations of each Ingress failed
+// because the Istio APIs were unavailable.
+type istioAPIBackoff struct {
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+func newIstioAPIBackoff() *istioAPIBackoff {
+	return &istioAPIBackoff{failures: map[types.NamespacedName]int{}}
+}
+
+// next records a failure for the Ingress and returns the jittered delay before it
+// should be reconciled again.
+func (b *istioAPIBackoff) next(key types.NamespacedName) time.Duration {
+	delay := istioAPIBaseBackoff
+	if b != nil {
+		b.mu.Lock()
+		failures := b.failures[key]
+		b.failures[key] = failures + 1
+		b.mu.Unlock()
+		for i := 0; i < failures && delay < istioAPIMaxBackoff; i++ {
+			delay *= 2
+		}
+	}
+	if delay > istioAPIMaxBackoff {
+		delay = istioAPIMaxBackoff
+	}
+	return wait.Jitter(delay, 0.5)
+}
+
+// reset forgets the failures of the Ingress.
+func (b *istioAPIBackoff) reset(key types.NamespacedName) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, key)
+}
+
+// markIstioAPIUnavailable reports that the Istio APIs could not be reached. An Ingress
+// that was ready keeps its previous status, as the resources programmed for it are
+// still in place, while any other Ingress is marked as not ready for that reason.
+func markIstioAPIUnavailable(ctx context.Context, ing *v1alpha1.Ingress, previous *v1alpha1.IngressStatus, err error) {
+	metrics.Record(ctx, istioAPIUnavailableM.M(1))
+	if previous.GetCondition(v1alpha1.IngressConditionReady).IsTrue() {
+		ing.Status = *previous.DeepCopy()
+	} else {
+		ing.Status.MarkIngressNotReady(istioAPIUnavailable, "The Istio APIs are unavailable: "+err.Error())
+	}
+	ing.GetConditionSet().Manage(&ing.Status).MarkFalse(istioAPIAvailable, istioAPIUnavailable, "%v", err)
+}
+
+// markIstioAPIAvailable clears the condition set by markIstioAPIUnavailable.
+func markIstioAPIAvailable(ing *v1alpha1.Ingress) {
+	ing.GetConditionSet().Manage(&ing.Status).ClearCondition(istioAPIAvailable)
+}
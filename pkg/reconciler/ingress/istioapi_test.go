@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestIsIstioAPIUnavailable(t *testing.T) {
+	virtualServices := schema.GroupResource{Group: v1beta1.SchemeGroupVersion.Group, Resource: "virtualservices"}
+	secrets := schema.GroupResource{Resource: "secrets"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "nil",
+	}, {
+		name: "not an API error",
+		err:  errors.New("inducing failure for update virtualservices"),
+	}, {
+		name: "webhook down",
+		err:  fmt.Errorf("failed to update VirtualService: %w", apierrs.NewInternalError(errors.New(`failed calling webhook "validation.istio.io": connection refused`))),
+		want: true,
+	}, {
+		name: "other webhook down",
+		err:  apierrs.NewInternalError(errors.New(`failed calling webhook "validation.example.com": connection refused`)),
+	}, {
+		name: "CRD missing",
+		err:  apierrs.NewGenericServerResponse(http.StatusNotFound, "get", virtualServices, "", "404 page not found", 0, true),
+		want: true,
+	}, {
+		name: "VirtualService missing",
+		err:  apierrs.NewNotFound(virtualServices, "foo"),
+	}, {
+		name: "Istio API server timeout",
+		err:  apierrs.NewServerTimeout(virtualServices, "create", 1),
+		want: true,
+	}, {
+		name: "Istio API service unavailable",
+		err:  apierrs.NewGenericServerResponse(http.StatusServiceUnavailable, "update", virtualServices, "foo", "", 0, true),
+		want: true,
+	}, {
+		name: "Kubernetes API server timeout",
+		err:  apierrs.NewServerTimeout(secrets, "create", 1),
+	}, {
+		name: "conflict",
+		err:  apierrs.NewConflict(virtualServices, "foo", errors.New("the object has been modified")),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isIstioAPIUnavailable(test.err); got != test.want {
+				t.Errorf("isIstioAPIUnavailable(%v) = %v, want: %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIstioAPIBackoff(t *testing.T) {
+	b := newIstioAPIBackoff()
+	key := types.NamespacedName{Namespace: "ns", Name: "ing"}
+
+	for i, base := range []int64{1, 2, 4, 8} {
+		delay := b.next(key)
+		if min, max := istioAPIBaseBackoff*time.Duration(base), istioAPIBaseBackoff*time.Duration(base)*3/2; delay < min || delay > max {
+			t.Errorf("next() #%d = %v, want between %v and %v", i, delay, min, max)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		if delay := b.next(key); delay > istioAPIMaxBackoff*3/2 {
+			t.Fatalf("next() = %v, want at most %v", delay, istioAPIMaxBackoff*3/2)
+		}
+	}
+
+	b.reset(key)
+	if delay := b.next(key); delay > istioAPIBaseBackoff*3/2 {
+		t.Errorf("next() after reset() = %v, want at most %v", delay, istioAPIBaseBackoff*3/2)
+	}
+
+	// The Reconcilers created by the tests have no backoff.
+	var none *istioAPIBackoff
+	none.reset(key)
+	if delay := none.next(key); delay > istioAPIBaseBackoff*3/2 {
+		t.Errorf("next() without backoff = %v, want at most %v", delay, istioAPIBaseBackoff*3/2)
+	}
+}
+
+func TestMarkIstioAPIUnavailable(t *testing.T) {
+	err := apierrs.NewInternalError(errors.New(`failed calling webhook "validation.istio.io": connection refused`))
+
+	ing := &v1alpha1.Ingress{}
+	ing.Status.InitializeConditions()
+	previous := ing.Status.DeepCopy()
+	ing.Status.MarkLoadBalancerFailed(virtualServiceNotReconciled, err.Error())
+
+	markIstioAPIUnavailable(context.Background(), ing, previous, err)
+	if got := ing.Status.GetCondition(v1alpha1.IngressConditionReady); got.IsTrue() || got.Reason != istioAPIUnavailable {
+		t.Errorf("Ready = %+v, want not ready with reason %s", got, istioAPIUnavailable)
+	}
+	if got := ing.Status.GetCondition(istioAPIAvailable); got == nil || got.Status != corev1.ConditionFalse {
+		t.Errorf("%s = %+v, want False", istioAPIAvailable, got)
+	}
+
+	markIstioAPIAvailable(ing)
+	if got := ing.Status.GetCondition(istioAPIAvailable); got != nil {
+		t.Errorf("%s = %+v, want cleared", istioAPIAvailable, got)
+	}
+}
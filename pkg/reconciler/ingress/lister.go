@@ -31,6 +31,7 @@ import (
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
 	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/ingress"
@@ -43,11 +44,23 @@ func NewProbeTargetLister(
 	gatewayLister istiolisters.GatewayLister,
 	endpointsLister corev1listers.EndpointsLister,
 	serviceLister corev1listers.ServiceLister) status.ProbeTargetLister {
+	return newGatewayPodTargetLister(logger, gatewayLister, endpointsLister, serviceLister, nil)
+}
+
+// newGatewayPodTargetLister creates a lister of the pods of the gateways to probe. Without
+// a zone lookup, all of them are probed regardless of probe-pods-per-zone.
+func newGatewayPodTargetLister(
+	logger *zap.SugaredLogger,
+	gatewayLister istiolisters.GatewayLister,
+	endpointsLister corev1listers.EndpointsLister,
+	serviceLister corev1listers.ServiceLister,
+	zones *zoneLookup) *gatewayPodTargetLister {
 	return &gatewayPodTargetLister{
 		logger:          logger,
 		gatewayLister:   gatewayLister,
 		endpointsLister: endpointsLister,
 		serviceLister:   serviceLister,
+		zones:           zones,
 	}
 }
 
@@ -57,13 +70,40 @@ type gatewayPodTargetLister struct {
 	gatewayLister   istiolisters.GatewayLister
 	endpointsLister corev1listers.EndpointsLister
 	serviceLister   corev1listers.ServiceLister
+	zones           *zoneLookup
 }
 
 func (l *gatewayPodTargetLister) ListProbeTargets(ctx context.Context, ing *v1alpha1.Ingress) ([]status.ProbeTarget, error) {
+	results, _, err := l.listProbeTargets(ctx, ing)
+	return results, err
+}
+
+// ListProbeZones returns the sorted zones of the gateway pods probed for the Ingress,
+// leaving out the pods of unknown zones.
+func (l *gatewayPodTargetLister) ListProbeZones(ctx context.Context, ing *v1alpha1.Ingress) ([]string, error) {
+	_, zones, err := l.listProbeTargets(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+	zones.Delete("")
+	return sets.List(zones), nil
+}
+
+func (l *gatewayPodTargetLister) listProbeTargets(ctx context.Context, ing *v1alpha1.Ingress) ([]status.ProbeTarget, sets.Set[string], error) {
 	results := []status.ProbeTarget{}
+	zones := sets.New[string]()
 	gatewayQualifiedNames, err := resources.QualifiedGatewayNamesFromContext(ctx, ing)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gateways for ingress: %w", err)
+		return nil, nil, fmt.Errorf("failed to get gateways for ingress: %w", err)
+	}
+
+	// The pods are picked per zone, and their zones reported, only when configured.
+	perZone, zoneOf := 0, func(string) string { return "" }
+	if cfg := config.FromContext(ctx).Istio; l.zones != nil && (cfg.ProbePodsPerZone > 0 || cfg.ReportZoneReadiness) {
+		if zoneOf, err = l.zones.zones(ctx); err != nil {
+			return nil, nil, fmt.Errorf("failed to look up the zones of the gateway pods: %w", err)
+		}
+		perZone = cfg.ProbePodsPerZone
 	}
 	hostsByGateway := ingress.HostsPerVisibility(ing, gatewayQualifiedNames)
 	gatewayNames := make([]string, 0, len(hostsByGateway))
@@ -76,11 +116,11 @@ func (l *gatewayPodTargetLister) ListProbeTargets(ctx context.Context, ing *v1al
 	for _, gatewayName := range gatewayNames {
 		gateway, err := l.getGateway(gatewayName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get Gateway %q: %w", gatewayName, err)
+			return nil, nil, fmt.Errorf("failed to get Gateway %q: %w", gatewayName, err)
 		}
-		targets, err := l.listGatewayTargets(gateway)
+		targets, err := l.listGatewayTargets(gateway, perZone, zoneOf, zones)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list the probing URLs of Gateway %q: %w", gatewayName, err)
+			return nil, nil, fmt.Errorf("failed to list the probing URLs of Gateway %q: %w", gatewayName, err)
 		}
 		if len(targets) == 0 {
 			continue
@@ -101,7 +141,7 @@ func (l *gatewayPodTargetLister) ListProbeTargets(ctx context.Context, ing *v1al
 			results = append(results, qualifiedTarget)
 		}
 	}
-	return results, nil
+	return results, zones, nil
 }
 
 func (l *gatewayPodTargetLister) getGateway(name string) (*v1beta1.Gateway, error) {
@@ -115,8 +155,9 @@ func (l *gatewayPodTargetLister) getGateway(name string) (*v1beta1.Gateway, erro
 	return l.gatewayLister.Gateways(namespace).Get(name)
 }
 
-// listGatewayPodsURLs returns a probe targets for a given Gateway.
-func (l *gatewayPodTargetLister) listGatewayTargets(gateway *v1beta1.Gateway) ([]status.ProbeTarget, error) {
+// listGatewayPodsURLs returns a probe targets for a given Gateway. When perZone is positive,
+// only that many pods of each zone are probed. The zones of the pods are inserted into zones.
+func (l *gatewayPodTargetLister) listGatewayTargets(gateway *v1beta1.Gateway, perZone int, zoneOf func(string) string, zones sets.Set[string]) ([]status.ProbeTarget, error) {
 	selector := labels.SelectorFromSet(gateway.Spec.Selector)
 
 	services, err := l.serviceLister.List(selector)
@@ -179,14 +220,11 @@ func (l *gatewayPodTargetLister) listGatewayTargets(gateway *v1beta1.Gateway) ([
 				continue
 			}
 			target := status.ProbeTarget{
-				PodIPs:  sets.New[string](),
+				PodIPs:  pickPerZone(sub.Addresses, perZone, zoneOf, zones),
 				PodPort: strconv.Itoa(int(portNumber)),
 				Port:    strconv.Itoa(int(server.Port.Number)),
 				URLs:    []*url.URL{tURL},
 			}
-			for _, addr := range sub.Addresses {
-				target.PodIPs.Insert(addr.IP)
-			}
 			targets = append(targets, target)
 		}
 	}
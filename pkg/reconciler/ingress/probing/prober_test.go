@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probing
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+	"knative.dev/net-istio/pkg/reconciler/fips"
+)
+
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		fips    bool
+		server  *tls.Config
+		wantErr bool
+	}{{
+		name:   "default settings",
+		server: &tls.Config{},
+	}, {
+		name:   "cipher suite outside of FIPS mode",
+		server: &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305}},
+	}, {
+		name:   "approved settings in FIPS mode",
+		fips:   true,
+		server: &tls.Config{},
+	}, {
+		name:    "TLS 1.3 in FIPS mode",
+		fips:    true,
+		server:  &tls.Config{MinVersion: tls.VersionTLS13},
+		wantErr: true,
+	}, {
+		name:    "cipher suite not approved in FIPS mode",
+		fips:    true,
+		server:  &tls.Config{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305}},
+		wantErr: true,
+	}, {
+		name:    "curve not approved in FIPS mode",
+		fips:    true,
+		server:  &tls.Config{CurvePreferences: []tls.CurveID{tls.X25519}},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.fips && fips.Enabled() {
+				t.Skip("FIPS mode is enabled at build time")
+			}
+			if tt.fips {
+				t.Setenv(fips.EnableFIPSModeEnv, "true")
+			}
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+			server.TLS = tt.server
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.StartTLS()
+			t.Cleanup(server.Close)
+			host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+			if err != nil {
+				t.Fatal("SplitHostPort() =", err)
+			}
+
+			prober := NewProber(zaptest.NewLogger(t).Sugar(), nil, nil, fips.TLSConfig())
+			client := &http.Client{Transport: prober.newTransport(host, port)}
+			resp, err := client.Get("https://gateway.example.com/healthz")
+			if err == nil {
+				resp.Body.Close()
+			}
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("Get() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+)
+
+const (
+	// readoptionCheckInterval is how often the VirtualServices of the ready Ingresses
+	// are counted, on top of whenever VirtualServices are deleted.
+	readoptionCheckInterval = time.Minute
+
+	// readoptionSettleDelay lets the informers observe all the deletions caused by the
+	// removal of a CRD before the VirtualServices are counted.
+	readoptionSettleDelay = 5 * time.Second
+
+	// minMassMissing is the number of ready Ingresses that must have lost their
+	// VirtualServices at once for all the ready Ingresses to be rebuilt. Fewer are
+	// rebuilt through their own deletion events.
+	minMassMissing = 2
+)
+
+// readoptionDetector rebuilds the resources of all the ready Ingresses when most of
+// them lost their VirtualServices at once, e.g. because the Istio CRDs were reinstalled,
+// rather than waiting for each of them to be resynced.
+type readoptionDetector struct {
+	logger                *zap.SugaredLogger
+	ingressLister         networkinglisters.IngressLister
+	virtualServiceIndexer cache.Indexer
+	filter                func(interface{}) bool
+	enqueue               func(types.NamespacedName)
+
+	trigger chan struct{}
+}
+
+func newReadoptionDetector(logger *zap.SugaredLogger, ingressLister networkinglisters.IngressLister,
+	virtualServiceIndexer cache.Indexer, filter func(interface{}) bool, enqueue func(types.NamespacedName)) *readoptionDetector {
+	return &readoptionDetector{
+		logger:                logger,
+		ingressLister:         ingressLister,
+		virtualServiceIndexer: virtualServiceIndexer,
+		filter:                filter,
+		enqueue:               enqueue,
+		trigger:               make(chan struct{}, 1),
+	}
+}
+
+// Trigger schedules a check, e.g. when a VirtualService is deleted.
+func (d *readoptionDetector) Trigger(interface{}) {
+	select {
+	case d.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run checks for missing VirtualServices until the context is done.
+func (d *readoptionDetector) Run(ctx context.Context) {
+	ticker := time.NewTicker(readoptionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.trigger:
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(readoptionSettleDelay):
+			}
+		}
+		d.check()
+	}
+}
+
+// check enqueues all the ready Ingresses when most of them have no VirtualService.
+func (d *readoptionDetector) check() {
+	ings, err := d.ingressLister.List(labels.Everything())
+	if err != nil {
+		d.logger.Warnw("Failed to list the Ingresses", zap.Error(err))
+		return
+	}
+
+	var ready []types.NamespacedName
+	missing := 0
+	for _, ing := range ings {
+		if !d.filter(ing) || !ing.IsReady() || ing.DeletionTimestamp != nil {
+			continue
+		}
+		key := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
+		ready = append(ready, key)
+		if vses, err := d.virtualServiceIndexer.ByIndex(virtualServiceByIngressIndex, key.String()); err == nil && len(vses) == 0 {
+			missing++
+		}
+	}
+	if !isMassMissing(missing, len(ready)) {
+		return
+	}
+
+	d.logger.Warnf("%d of the %d ready Ingresses have no VirtualService, rebuilding the resources of all of them", missing, len(ready))
+	for _, key := range ready {
+		d.enqueue(key)
+	}
+}
+
+// isMassMissing returns whether so many of the ready Ingresses lost their VirtualServices
+// that the Istio resources were likely wiped.
+func isMassMissing(missing, ready int) bool {
+	return missing >= minMassMissing && missing*2 >= ready
+}
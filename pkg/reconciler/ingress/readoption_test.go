@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func readoptionIngress(name string, ready bool) *v1alpha1.Ingress {
+	status := corev1.ConditionUnknown
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNS, Name: name},
+		Status: v1alpha1.IngressStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{{Type: apis.ConditionReady, Status: status}},
+			},
+		},
+	}
+}
+
+func readoptionVirtualService(ing string) *v1beta1.VirtualService {
+	return &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNS,
+			Name:      ing + "-ingress",
+			Labels:    map[string]string{networking.IngressLabelKey: ing},
+		},
+	}
+}
+
+func TestReadoptionDetector(t *testing.T) {
+	tests := []struct {
+		name      string
+		ingresses []*v1alpha1.Ingress
+		vses      []*v1beta1.VirtualService
+		want      []string
+	}{{
+		name: "nothing missing",
+		ingresses: []*v1alpha1.Ingress{
+			readoptionIngress("a", true),
+			readoptionIngress("b", true),
+		},
+		vses: []*v1beta1.VirtualService{readoptionVirtualService("a"), readoptionVirtualService("b")},
+	}, {
+		name: "single Ingress missing",
+		ingresses: []*v1alpha1.Ingress{
+			readoptionIngress("a", true),
+			readoptionIngress("b", true),
+			readoptionIngress("c", true),
+		},
+		vses: []*v1beta1.VirtualService{readoptionVirtualService("a"), readoptionVirtualService("b")},
+	}, {
+		name: "wiped",
+		ingresses: []*v1alpha1.Ingress{
+			readoptionIngress("a", true),
+			readoptionIngress("b", true),
+			readoptionIngress("c", true),
+			readoptionIngress("not-ready", false),
+		},
+		vses: []*v1beta1.VirtualService{readoptionVirtualService("a")},
+		want: []string{"test-ns/a", "test-ns/b", "test-ns/c"},
+	}, {
+		name: "not ready Ingresses are not counted",
+		ingresses: []*v1alpha1.Ingress{
+			readoptionIngress("a", true),
+			readoptionIngress("b", false),
+			readoptionIngress("c", false),
+		},
+		vses: []*v1beta1.VirtualService{readoptionVirtualService("a")},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ingressIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, ing := range test.ingresses {
+				ingressIndexer.Add(ing)
+			}
+			vsIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+				virtualServiceByIngressIndex: indexVirtualServiceByIngress,
+			})
+			for _, vs := range test.vses {
+				vsIndexer.Add(vs)
+			}
+
+			var got []string
+			d := newReadoptionDetector(logtesting.TestLogger(t), networkinglisters.NewIngressLister(ingressIndexer),
+				vsIndexer, func(interface{}) bool { return true }, func(key types.NamespacedName) {
+					got = append(got, key.String())
+				})
+			d.check()
+
+			if diff := cmp.Diff(test.want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Error("Enqueued Ingresses (-want, +got):", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/redact"
+	"knative.dev/net-istio/pkg/reconciler/remotecluster"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const remoteClusterNotReconciled = "ReconcileRemoteClusterFailed"
+
+// controlPlaneClients are the clients and listers of the cluster running istiod and the
+// gateways, when it is not the cluster running Knative.
+type controlPlaneClients struct {
+	kubeclient   kubernetes.Interface
+	secretLister corev1listers.SecretLister
+	svcLister    corev1listers.ServiceLister
+}
+
+// remoteClusterProvider returns the clients of the remote clusters registered
+// with the given Secrets.
+type remoteClusterProvider interface {
+	Clusters(ctx context.Context, secretNames []string) ([]*remotecluster.Cluster, error)
+}
+
+// remoteResources are the resources generated for an Ingress which are also
+// programmed into the remote clusters.
+type remoteResources struct {
+	secrets         []*corev1.Secret
+	gateways        []*v1beta1.Gateway
+	virtualServices []*v1beta1.VirtualService
+}
+
+// reconcileRemoteClusters programs the given resources into each of the configured
+// remote clusters, and removes the VirtualServices and Gateways of the Ingress that
+// are no longer needed from them.
+func (r *Reconciler) reconcileRemoteClusters(ctx context.Context, ing *v1alpha1.Ingress, desired *remoteResources) error {
+	names := config.FromContext(ctx).Istio.RemoteClusterSecrets
+	if r.remoteClusters == nil || names.Len() == 0 {
+		return nil
+	}
+
+	clusters, err := r.remoteClusters.Clusters(ctx, sets.List(names))
+	if err != nil {
+		return err
+	}
+	g, gctx := newReconcileGroup(ctx)
+	for _, cluster := range clusters {
+		cluster := cluster
+		g.Go(func() error {
+			if err := reconcileRemoteCluster(gctx, ing, cluster, desired); err != nil {
+				return fmt.Errorf("failed to reconcile remote cluster %q: %w", cluster.Name, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func reconcileRemoteCluster(ctx context.Context, ing *v1alpha1.Ingress, cluster *remotecluster.Cluster, desired *remoteResources) error {
+	for _, secret := range desired.secrets {
+		if err := reconcileRemoteSecret(ctx, cluster, secret); err != nil {
+			return err
+		}
+	}
+
+	keptGateways := sets.New[string]()
+	for _, gateway := range desired.gateways {
+		keptGateways.Insert(gateway.Namespace + "/" + gateway.Name)
+		if err := reconcileRemoteGateway(ctx, cluster, gateway); err != nil {
+			return err
+		}
+	}
+
+	keptVirtualServices := sets.New[string]()
+	for _, vs := range desired.virtualServices {
+		keptVirtualServices.Insert(vs.Namespace + "/" + vs.Name)
+		if err := reconcileRemoteVirtualService(ctx, cluster, vs); err != nil {
+			return err
+		}
+	}
+
+	// Owner references cannot cross clusters, so the Gateways and VirtualServices of
+	// the Ingress are deleted explicitly.
+	return deleteIngressIstioResources(ctx, cluster.IstioClient, ing, keptGateways, keptVirtualServices)
+}
+
+// deleteIngressIstioResources deletes the Gateways and VirtualServices labelled for the
+// Ingress that are not kept.
+func deleteIngressIstioResources(ctx context.Context, client istioclientset.Interface, ing *v1alpha1.Ingress, keptGateways, keptVirtualServices sets.Set[string]) error {
+	selector := labels.SelectorFromSet(labels.Set{networking.IngressLabelKey: ing.Name}).String()
+	gateways, err := client.NetworkingV1beta1().Gateways(ing.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list Gateways: %w", err)
+	}
+	for _, gateway := range gateways.Items {
+		if keptGateways.Has(gateway.Namespace + "/" + gateway.Name) {
+			continue
+		}
+		if err := client.NetworkingV1beta1().Gateways(gateway.Namespace).Delete(ctx, gateway.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Gateway: %w", err)
+		}
+	}
+
+	vses, err := client.NetworkingV1beta1().VirtualServices(ing.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list VirtualServices: %w", err)
+	}
+	for _, vs := range vses.Items {
+		if keptVirtualServices.Has(vs.Namespace + "/" + vs.Name) {
+			continue
+		}
+		if err := client.NetworkingV1beta1().VirtualServices(vs.Namespace).Delete(ctx, vs.Name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete VirtualService: %w", err)
+		}
+	}
+	return nil
+}
+
+func reconcileRemoteSecret(ctx context.Context, cluster *remotecluster.Cluster, desired *corev1.Secret) error {
+	client := cluster.KubeClient.CoreV1().Secrets(desired.Namespace)
+	secret, err := client.Get(ctx, desired.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if _, err := client.Create(ctx, remoteSecret(desired), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create %v: %w", redact.Secret(desired), redact.Error(err, desired))
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(secret.Data, desired.Data) && equality.Semantic.DeepEqual(secret.Labels, desired.Labels) {
+		return nil
+	}
+
+	existing := secret.DeepCopy()
+	existing.Data = desired.Data
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update %v: %w", redact.Secret(desired), redact.Error(err, desired))
+	}
+	return nil
+}
+
+func reconcileRemoteGateway(ctx context.Context, cluster *remotecluster.Cluster, desired *v1beta1.Gateway) error {
+	client := cluster.IstioClient.NetworkingV1beta1().Gateways(desired.Namespace)
+	gateway, err := client.Get(ctx, desired.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if _, err := client.Create(ctx, remoteGateway(desired), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create Gateway: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if cmp.Equal(&gateway.Spec, &desired.Spec, protocmp.Transform()) && equality.Semantic.DeepEqual(gateway.Labels, desired.Labels) {
+		return nil
+	}
+
+	existing := gateway.DeepCopy()
+	existing.Spec = *desired.Spec.DeepCopy()
+	existing.Labels = desired.Labels
+	if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update Gateway: %w", err)
+	}
+	return nil
+}
+
+func reconcileRemoteVirtualService(ctx context.Context, cluster *remotecluster.Cluster, desired *v1beta1.VirtualService) error {
+	client := cluster.IstioClient.NetworkingV1beta1().VirtualServices(desired.Namespace)
+	vs, err := client.Get(ctx, desired.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if _, err := client.Create(ctx, remoteVirtualService(desired), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create VirtualService: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if kaccessor.SpecHashMatches(vs, &vs.Spec, desired) && equality.Semantic.DeepEqual(vs.Labels, desired.Labels) {
+		return nil
+	}
+
+	existing := vs.DeepCopy()
+	existing.Spec = *desired.Spec.DeepCopy()
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	if _, err := client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update VirtualService: %w", err)
+	}
+	return nil
+}
+
+// remoteObjectMeta returns the metadata of the copy of a resource in a remote cluster,
+// where its owners do not exist.
+func remoteObjectMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+	}
+}
+
+func remoteSecret(secret *corev1.Secret) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: remoteObjectMeta(secret.ObjectMeta),
+		Data:       secret.Data,
+		Type:       secret.Type,
+	}
+}
+
+func remoteGateway(gateway *v1beta1.Gateway) *v1beta1.Gateway {
+	remote := &v1beta1.Gateway{ObjectMeta: remoteObjectMeta(gateway.ObjectMeta)}
+	gateway.Spec.DeepCopyInto(&remote.Spec)
+	return remote
+}
+
+func remoteVirtualService(vs *v1beta1.VirtualService) *v1beta1.VirtualService {
+	remote := &v1beta1.VirtualService{ObjectMeta: remoteObjectMeta(vs.ObjectMeta)}
+	vs.Spec.DeepCopyInto(&remote.Spec)
+	return remote
+}
+
+// gatewayServiceLister returns the lister of the gateway Services, which live in the
+// cluster running istiod.
+func (r *Reconciler) gatewayServiceLister() corev1listers.ServiceLister {
+	if r.controlPlane != nil {
+		return r.controlPlane.svcLister
+	}
+	return r.svcLister
+}
+
+// gatewayServices returns the Services of the gateways of the visibility the Ingress is exposed on.
+func (r *Reconciler) gatewayServices(ctx context.Context, ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility) ([]*corev1.Service, error) {
+	metas, err := resources.GetGatewaySvcNameNamespacesForVisibility(ctx, ing, visibility)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]*corev1.Service, len(metas))
+	for i, meta := range metas {
+		svc, err := r.gatewayServiceLister().Services(meta.Namespace).Get(meta.Name)
+		if err != nil {
+			return nil, err
+		}
+		services[i] = svc
+	}
+	return services, nil
+}
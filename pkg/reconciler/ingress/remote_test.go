@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	fakeistioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned/fake"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/remotecluster"
+	"knative.dev/networking/pkg/apis/networking"
+)
+
+type fakeRemoteClusters struct {
+	clusters []*remotecluster.Cluster
+	err      error
+}
+
+func (f *fakeRemoteClusters) Clusters(context.Context, []string) ([]*remotecluster.Cluster, error) {
+	return f.clusters, f.err
+}
+
+func remoteClusterContext(secrets ...string) context.Context {
+	return config.ToContext(context.Background(), &config.Config{
+		Istio: &config.Istio{RemoteClusterSecrets: sets.New(secrets...)},
+	})
+}
+
+func TestReconcileRemoteClusters(t *testing.T) {
+	ing := ing("reconcile-virtualservice")
+	ingLabels := map[string]string{networking.IngressLabelKey: ing.Name}
+	ownerRefs := []metav1.OwnerReference{{Name: ing.Name, Kind: "Ingress"}}
+
+	desired := &remoteResources{
+		secrets: []*corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "istio-system", OwnerReferences: ownerRefs},
+			Data:       map[string][]byte{"tls.crt": []byte("cert")},
+		}},
+		gateways: []*v1beta1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: ing.Namespace, Labels: ingLabels, OwnerReferences: ownerRefs},
+			Spec:       istiov1beta1.Gateway{Selector: map[string]string{"istio": "ingressgateway"}},
+		}},
+		virtualServices: []*v1beta1.VirtualService{{
+			ObjectMeta: metav1.ObjectMeta{Name: "vs", Namespace: ing.Namespace, Labels: ingLabels, OwnerReferences: ownerRefs},
+			Spec:       istiov1beta1.VirtualService{Hosts: []string{"foo.example.com"}},
+		}},
+	}
+
+	staleVS := &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: ing.Namespace, Labels: ingLabels},
+	}
+	outdatedGateway := &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway", Namespace: ing.Namespace, Labels: ingLabels},
+	}
+	cluster := &remotecluster.Cluster{
+		Name:        "cluster-b",
+		KubeClient:  fakekubeclientset.NewSimpleClientset(),
+		IstioClient: fakeistioclientset.NewSimpleClientset(staleVS, outdatedGateway),
+	}
+	r := &Reconciler{remoteClusters: &fakeRemoteClusters{clusters: []*remotecluster.Cluster{cluster}}}
+
+	ctx := remoteClusterContext("cluster-b")
+	if err := r.reconcileRemoteClusters(ctx, ing, desired); err != nil {
+		t.Fatal("reconcileRemoteClusters() =", err)
+	}
+
+	secret, err := cluster.KubeClient.CoreV1().Secrets("istio-system").Get(ctx, "secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get remote Secret:", err)
+	}
+	if len(secret.OwnerReferences) != 0 {
+		t.Errorf("Remote Secret OwnerReferences = %v, want none", secret.OwnerReferences)
+	}
+	gateway, err := cluster.IstioClient.NetworkingV1beta1().Gateways(ing.Namespace).Get(ctx, "gateway", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get remote Gateway:", err)
+	}
+	if got := gateway.Spec.Selector["istio"]; got != "ingressgateway" {
+		t.Errorf("Remote Gateway selector = %q, want ingressgateway", got)
+	}
+	vs, err := cluster.IstioClient.NetworkingV1beta1().VirtualServices(ing.Namespace).Get(ctx, "vs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("Failed to get remote VirtualService:", err)
+	}
+	if len(vs.OwnerReferences) != 0 {
+		t.Errorf("Remote VirtualService OwnerReferences = %v, want none", vs.OwnerReferences)
+	}
+	if _, err := cluster.IstioClient.NetworkingV1beta1().VirtualServices(ing.Namespace).Get(ctx, "stale", metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Error("Stale remote VirtualService was not deleted:", err)
+	}
+
+	// Finalizing removes the Gateways and VirtualServices, but leaves the Secret.
+	if err := r.reconcileRemoteClusters(ctx, ing, &remoteResources{}); err != nil {
+		t.Fatal("reconcileRemoteClusters() =", err)
+	}
+	if _, err := cluster.IstioClient.NetworkingV1beta1().Gateways(ing.Namespace).Get(ctx, "gateway", metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Error("Remote Gateway was not deleted:", err)
+	}
+	if _, err := cluster.IstioClient.NetworkingV1beta1().VirtualServices(ing.Namespace).Get(ctx, "vs", metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Error("Remote VirtualService was not deleted:", err)
+	}
+	if _, err := cluster.KubeClient.CoreV1().Secrets("istio-system").Get(ctx, "secret", metav1.GetOptions{}); err != nil {
+		t.Error("Failed to get remote Secret:", err)
+	}
+}
+
+func TestReconcileRemoteClustersDisabled(t *testing.T) {
+	r := &Reconciler{remoteClusters: &fakeRemoteClusters{err: errors.New("boom")}}
+	if err := r.reconcileRemoteClusters(remoteClusterContext(), ing("foo"), &remoteResources{}); err != nil {
+		t.Error("reconcileRemoteClusters() =", err)
+	}
+	if err := r.reconcileRemoteClusters(remoteClusterContext("cluster-b"), ing("foo"), &remoteResources{}); err == nil {
+		t.Error("reconcileRemoteClusters() = nil, wanted an error")
+	}
+}
+
+func TestControlPlaneClients(t *testing.T) {
+	local := fakekubeclientset.NewSimpleClientset()
+	r := &Reconciler{kubeclient: local}
+	if r.GetKubeClient() != local {
+		t.Error("GetKubeClient() did not return the local client without a control plane")
+	}
+
+	remote := fakekubeclientset.NewSimpleClientset()
+	r.controlPlane = &controlPlaneClients{kubeclient: remote}
+	if r.GetKubeClient() != remote {
+		t.Error("GetKubeClient() did not return the control plane client")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+// MakeAuthorizationPolicy creates an AuthorizationPolicy that only allows traffic
+// from the given namespaces to reach the workloads behind the given Service.
+// Nil is returned if the workloads of the Service cannot be selected.
+func MakeAuthorizationPolicy(ing *v1alpha1.Ingress, svc *corev1.Service, sourceNamespaces []string) *v1beta1.AuthorizationPolicy {
+	matchLabels := backendWorkloadLabels(svc)
+	if matchLabels == nil {
+		return nil
+	}
+
+	ap := &v1beta1.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            names.AuthorizationPolicy(ing, svc.Name),
+			Namespace:       svc.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+			Labels: map[string]string{
+				networking.IngressLabelKey: ing.Name,
+			},
+		},
+		Spec: istiov1beta1.AuthorizationPolicy{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: matchLabels,
+			},
+			Action: istiov1beta1.AuthorizationPolicy_ALLOW,
+			Rules: []*istiov1beta1.Rule{{
+				From: []*istiov1beta1.Rule_From{{
+					Source: &istiov1beta1.Source{
+						Namespaces: sourceNamespaces,
+					},
+				}},
+			}},
+		},
+	}
+	ap.Annotations = kaccessor.WithSpecHash(ap.Annotations, &ap.Spec)
+
+	return ap
+}
+
+// GetGatewayServiceNamespaces returns the sorted, unique namespaces of the
+// Services backing the given gateways.
+func GetGatewayServiceNamespaces(gateways []config.Gateway) ([]string, error) {
+	namespaces := sets.New[string]()
+	for _, gw := range gateways {
+		meta, err := parseIngressGatewayConfig(gw)
+		if err != nil {
+			return nil, err
+		}
+		namespaces.Insert(meta.Namespace)
+	}
+	return sets.List(namespaces), nil
+}
+
+// backendWorkloadLabels returns the labels selecting the workloads behind the
+// given Service, or nil if they cannot be selected.
+func backendWorkloadLabels(svc *corev1.Service) map[string]string {
+	if len(svc.Spec.Selector) != 0 {
+		return svc.Spec.Selector
+	}
+	// Revision Services do not have a selector, as their endpoints are managed
+	// by Knative, but they carry the label of the Revision behind them.
+	if rev, ok := svc.Labels[RevisionLabelKey]; ok {
+		return map[string]string{RevisionLabelKey: rev}
+	}
+	return nil
+}
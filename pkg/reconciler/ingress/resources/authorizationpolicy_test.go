@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/pkg/kmeta"
+)
+
+func TestMakeAuthorizationPolicy(t *testing.T) {
+	sourceNamespaces := []string{"istio-system", "knative-serving"}
+
+	tests := []struct {
+		name string
+		svc  *corev1.Service
+		want map[string]string
+	}{{
+		name: "revision service",
+		svc: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service",
+				Namespace: "my-namespace",
+				Labels:    map[string]string{RevisionLabelKey: "my-revision"},
+			},
+		},
+		want: map[string]string{RevisionLabelKey: "my-revision"},
+	}, {
+		name: "service with selector",
+		svc: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service",
+				Namespace: "my-namespace",
+				Labels:    map[string]string{RevisionLabelKey: "my-revision"},
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "my-app"},
+			},
+		},
+		want: map[string]string{"app": "my-app"},
+	}, {
+		name: "service without selector and revision",
+		svc: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service",
+				Namespace: "my-namespace",
+			},
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MakeAuthorizationPolicy(ing, tc.svc, sourceNamespaces)
+			if tc.want == nil {
+				if got != nil {
+					t.Fatalf("MakeAuthorizationPolicy() = %v, want nil", got)
+				}
+				return
+			}
+
+			expected := &v1beta1.AuthorizationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "my-ingress-my-service",
+					Namespace:       "my-namespace",
+					OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+					Labels: map[string]string{
+						networking.IngressLabelKey: "my-ingress",
+					},
+				},
+				Spec: istiov1beta1.AuthorizationPolicy{
+					Selector: &istiotypev1beta1.WorkloadSelector{
+						MatchLabels: tc.want,
+					},
+					Action: istiov1beta1.AuthorizationPolicy_ALLOW,
+					Rules: []*istiov1beta1.Rule{{
+						From: []*istiov1beta1.Rule_From{{
+							Source: &istiov1beta1.Source{
+								Namespaces: sourceNamespaces,
+							},
+						}},
+					}},
+				},
+			}
+			expected.Annotations = kaccessor.WithSpecHash(expected.Annotations, &expected.Spec)
+
+			if diff := cmp.Diff(expected, got, protocmp.Transform()); diff != "" {
+				t.Error("Unexpected AuthorizationPolicy (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestGetGatewayServiceNamespaces(t *testing.T) {
+	got, err := GetGatewayServiceNamespaces([]config.Gateway{{
+		ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+	}, {
+		ServiceURL: "knative-local-gateway.istio-system.svc.cluster.local",
+	}, {
+		ServiceURL: "custom-gateway.gateways.svc.cluster.local",
+	}})
+	if err != nil {
+		t.Fatal("GetGatewayServiceNamespaces() =", err)
+	}
+	if want := []string{"gateways", "istio-system"}; !cmp.Equal(want, got) {
+		t.Errorf("GetGatewayServiceNamespaces() = %v, want %v", got, want)
+	}
+
+	if _, err := GetGatewayServiceNamespaces([]config.Gateway{{ServiceURL: "invalid"}}); err == nil {
+		t.Error("GetGatewayServiceNamespaces() = nil, want error")
+	}
+}
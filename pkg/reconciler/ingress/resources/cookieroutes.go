@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/yaml"
+)
+
+// CookieRoutesAnnotationKey is the annotation holding the YAML map of the names of the
+// cookies to the CookieRoutes of the Ingress, e.g. to run A/B experiments on the gateways.
+const CookieRoutesAnnotationKey = "networking.knative.dev/cookie-routes"
+
+// CookieRoute overrides the splits of the requests carrying a cookie.
+type CookieRoute struct {
+	// Value the cookie must have, any value when empty.
+	Value string `json:"value,omitempty"`
+	// Splits are the percentages of the requests sent to the backends of the Ingress,
+	// by the names of their Services, instead of its own splits.
+	Splits map[string]int32 `json:"splits"`
+}
+
+// cookieMatch returns the match of the cookie header carrying the cookie with the value
+// of the CookieRoute, among the other cookies of the request.
+func (r CookieRoute) cookieMatch(name string) *istiov1beta1.StringMatch {
+	value := `[^;]*`
+	if r.Value != "" {
+		value = regexp.QuoteMeta(r.Value)
+	}
+	return &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Regex{
+		Regex: `^(.*;\s*)?` + regexp.QuoteMeta(name) + `=` + value + `(;.*)?$`,
+	}}
+}
+
+// ParseCookieRoutes returns the CookieRoutes of the Ingress by the names of their cookies.
+func ParseCookieRoutes(ing *v1alpha1.Ingress) (map[string]CookieRoute, error) {
+	v := ing.Annotations[CookieRoutesAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var routes map[string]CookieRoute
+	if err := yaml.UnmarshalStrict([]byte(v), &routes); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", CookieRoutesAnnotationKey, err)
+	}
+
+	backends := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				backends.Insert(split.ServiceName)
+			}
+		}
+	}
+	for name, route := range routes {
+		if name == "" || strings.ContainsAny(name, "=; \t") {
+			return nil, fmt.Errorf("invalid %s annotation: %q is not a cookie name", CookieRoutesAnnotationKey, name)
+		}
+		if strings.ContainsAny(route.Value, "; \t") {
+			return nil, fmt.Errorf("invalid %s annotation: the value %q of the cookie %q is not a cookie value", CookieRoutesAnnotationKey, route.Value, name)
+		}
+		if len(route.Splits) == 0 {
+			return nil, fmt.Errorf("invalid %s annotation: the cookie %q has no splits", CookieRoutesAnnotationKey, name)
+		}
+		var total int32
+		for svc, percent := range route.Splits {
+			if !backends.Has(svc) {
+				return nil, fmt.Errorf("invalid %s annotation: the cookie %q routes to %q which is not a backend of the Ingress", CookieRoutesAnnotationKey, name, svc)
+			}
+			if percent <= 0 {
+				return nil, fmt.Errorf("invalid %s annotation: the cookie %q sends %d%% to %q, want a positive percentage", CookieRoutesAnnotationKey, name, percent, svc)
+			}
+			total += percent
+		}
+		if total != 100 {
+			return nil, fmt.Errorf("invalid %s annotation: the splits of the cookie %q add up to %d%%, want 100%%", CookieRoutesAnnotationKey, name, total)
+		}
+	}
+	return routes, nil
+}
+
+// WithCookieRoutes adds a route for each of the given CookieRoutes, by the order of the
+// names of their cookies, before every HTTP route of the gateway VirtualService sending
+// the requests to all of the backends of the CookieRoute. The added routes match the
+// requests of the original one which carry the cookie, and split them as the CookieRoute
+// does between the destinations of the original one.
+func WithCookieRoutes(vs *v1beta1.VirtualService, namespace string, routes map[string]CookieRoute) {
+	if len(routes) == 0 {
+		return
+	}
+
+	cookies := sets.List(sets.KeySet(routes))
+	httpRoutes := make([]*istiov1beta1.HTTPRoute, 0, len(vs.Spec.Http))
+	for _, route := range vs.Spec.Http {
+		dests := make(map[string]*istiov1beta1.HTTPRouteDestination, len(route.Route))
+		for _, dest := range route.Route {
+			dests[dest.Destination.GetHost()] = dest
+		}
+	nextCookie:
+		for _, cookie := range cookies {
+			cookieRoute := route.DeepCopy()
+			cookieRoute.Route = make([]*istiov1beta1.HTTPRouteDestination, 0, len(routes[cookie].Splits))
+			for _, svc := range sets.List(sets.KeySet(routes[cookie].Splits)) {
+				dest, ok := dests[network.GetServiceHostname(svc, namespace)]
+				if !ok {
+					continue nextCookie
+				}
+				sent := dest.DeepCopy()
+				sent.Weight = routes[cookie].Splits[svc]
+				cookieRoute.Route = append(cookieRoute.Route, sent)
+			}
+			if len(cookieRoute.Match) == 0 {
+				cookieRoute.Match = []*istiov1beta1.HTTPMatchRequest{{}}
+			}
+			for _, match := range cookieRoute.Match {
+				if match.Headers == nil {
+					match.Headers = make(map[string]*istiov1beta1.StringMatch, 1)
+				}
+				match.Headers["cookie"] = routes[cookie].cookieMatch(cookie)
+			}
+			httpRoutes = append(httpRoutes, cookieRoute)
+		}
+		httpRoutes = append(httpRoutes, route)
+	}
+	vs.Spec.Http = httpRoutes
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
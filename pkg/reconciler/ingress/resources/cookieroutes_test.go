@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseCookieRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       map[string]CookieRoute
+		wantErr    bool
+	}{{
+		name: "no routes",
+	}, {
+		name: "routes",
+		annotation: `
+experiment:
+  value: b
+  splits: {b-service: 100}
+canary:
+  splits: {a-service: 50, b-service: 50}`,
+		want: map[string]CookieRoute{
+			"experiment": {Value: "b", Splits: map[string]int32{"b-service": 100}},
+			"canary":     {Splits: map[string]int32{"a-service": 50, "b-service": 50}},
+		},
+	}, {
+		name:       "invalid yaml",
+		annotation: "experiment: [",
+		wantErr:    true,
+	}, {
+		name:       "unknown field",
+		annotation: "experiment: {split: {b-service: 100}}",
+		wantErr:    true,
+	}, {
+		name:       "invalid cookie name",
+		annotation: "'a=b': {splits: {b-service: 100}}",
+		wantErr:    true,
+	}, {
+		name:       "invalid cookie value",
+		annotation: "experiment: {value: 'a;b', splits: {b-service: 100}}",
+		wantErr:    true,
+	}, {
+		name:       "no splits",
+		annotation: "experiment: {value: b}",
+		wantErr:    true,
+	}, {
+		name:       "unknown backend",
+		annotation: "experiment: {splits: {other-service: 100}}",
+		wantErr:    true,
+	}, {
+		name:       "negative percentage",
+		annotation: "experiment: {splits: {a-service: 110, b-service: -10}}",
+		wantErr:    true,
+	}, {
+		name:       "percentages not adding up",
+		annotation: "experiment: {splits: {a-service: 50, b-service: 40}}",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{CookieRoutesAnnotationKey: tt.annotation},
+				},
+				Spec: v1alpha1.IngressSpec{
+					Rules: []v1alpha1.IngressRule{{
+						HTTP: &v1alpha1.HTTPIngressRuleValue{
+							Paths: []v1alpha1.HTTPIngressPath{{
+								Splits: []v1alpha1.IngressBackendSplit{{
+									IngressBackend: v1alpha1.IngressBackend{ServiceName: "a-service"},
+								}, {
+									IngressBackend: v1alpha1.IngressBackend{ServiceName: "b-service"},
+								}},
+							}},
+						},
+					}},
+				},
+			}
+			got, err := ParseCookieRoutes(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCookieRoutes() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("Unexpected routes (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithCookieRoutes(t *testing.T) {
+	split := func(service string, weight int32) *istiov1beta1.HTTPRouteDestination {
+		return &istiov1beta1.HTTPRouteDestination{
+			Destination: &istiov1beta1.Destination{
+				Host: service + ".test-ns.svc.cluster.local",
+				Port: &istiov1beta1.PortSelector{Number: 80},
+			},
+			Weight: weight,
+		}
+	}
+	match := &istiov1beta1.HTTPMatchRequest{
+		Gateways:  []string{"knative-serving/knative-ingress-gateway"},
+		Authority: &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: "foo.example.com"}},
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Match: []*istiov1beta1.HTTPMatchRequest{match},
+				Route: []*istiov1beta1.HTTPRouteDestination{split("a", 90), split("b", 10)},
+			}, {
+				Match: []*istiov1beta1.HTTPMatchRequest{match},
+				Route: []*istiov1beta1.HTTPRouteDestination{split("a", 100)},
+			}},
+		},
+	}
+
+	WithCookieRoutes(vs, "test-ns", map[string]CookieRoute{
+		"experiment": {Value: "b", Splits: map[string]int32{"b": 100}},
+		"canary":     {Splits: map[string]int32{"a": 50, "b": 50}},
+	})
+
+	cookieMatch := func(regex string) *istiov1beta1.HTTPMatchRequest {
+		return &istiov1beta1.HTTPMatchRequest{
+			Gateways:  match.Gateways,
+			Authority: match.Authority,
+			Headers: map[string]*istiov1beta1.StringMatch{
+				"cookie": {MatchType: &istiov1beta1.StringMatch_Regex{Regex: regex}},
+			},
+		}
+	}
+	want := []*istiov1beta1.HTTPRoute{{
+		Match: []*istiov1beta1.HTTPMatchRequest{cookieMatch(`^(.*;\s*)?canary=[^;]*(;.*)?$`)},
+		Route: []*istiov1beta1.HTTPRouteDestination{split("a", 50), split("b", 50)},
+	}, {
+		Match: []*istiov1beta1.HTTPMatchRequest{cookieMatch(`^(.*;\s*)?experiment=b(;.*)?$`)},
+		Route: []*istiov1beta1.HTTPRouteDestination{split("b", 100)},
+	}, {
+		Match: []*istiov1beta1.HTTPMatchRequest{match},
+		Route: []*istiov1beta1.HTTPRouteDestination{split("a", 90), split("b", 10)},
+	}, {
+		// The route without the backend b is not overridden.
+		Match: []*istiov1beta1.HTTPMatchRequest{match},
+		Route: []*istiov1beta1.HTTPRouteDestination{split("a", 100)},
+	}}
+	if diff := cmp.Diff(want, vs.Spec.Http, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+}
+
+func TestCookieMatch(t *testing.T) {
+	re := regexp.MustCompile(CookieRoute{Value: "b"}.cookieMatch("experiment").GetRegex())
+	for cookie, want := range map[string]bool{
+		"experiment=b":               true,
+		"session=1; experiment=b":    true,
+		"experiment=b;session=1":     true,
+		"experiment=bb":              false,
+		"myexperiment=b":             false,
+		"session=experiment=b":       false,
+		"session=1; experiment=a; x": false,
+	} {
+		if got := re.MatchString(cookie); got != want {
+			t.Errorf("Match(%q) = %v, want: %v", cookie, got, want)
+		}
+	}
+}
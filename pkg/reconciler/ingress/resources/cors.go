@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// CORSPolicyAnnotationKey is the annotation holding the YAML CORSPolicy of the Ingress,
+// e.g. to call its services from the scripts of the pages of another site.
+const CORSPolicyAnnotationKey = "networking.knative.dev/cors-policy"
+
+// anyOrigin is the origin allowing the requests of all the origins.
+const anyOrigin = "*"
+
+// CORSPolicy configures which cross-origin requests the browsers allow to the Ingress.
+type CORSPolicy struct {
+	// AllowOrigins are the origins allowed to request the Ingress, e.g.
+	// https://example.com, or all of them with "*".
+	AllowOrigins []string `json:"allowOrigins"`
+	// AllowMethods are the methods allowed in the requests, the simple ones when empty.
+	AllowMethods []string `json:"allowMethods,omitempty"`
+	// AllowHeaders are the headers allowed in the requests, besides the simple ones.
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+	// ExposeHeaders are the response headers exposed to the scripts, besides the simple
+	// ones and the ones configured in config-istio.
+	ExposeHeaders []string `json:"exposeHeaders,omitempty"`
+	// MaxAge is how long the responses to the preflight requests are cached.
+	MaxAge metav1.Duration `json:"maxAge,omitempty"`
+	// AllowCredentials is whether the requests may carry credentials, e.g. cookies, the
+	// value configured in config-istio when unset. It can't be set along with "*".
+	AllowCredentials *bool `json:"allowCredentials,omitempty"`
+}
+
+// MakeCORSPolicy returns the CORS policy of the routes of the Ingress, nil when its
+// annotation doesn't set one. The defaults configured in config-istio are merged into the
+// policy: their exposed headers are added to the ones of the Ingress, and they allow
+// credentials unless the Ingress says otherwise or allows all the origins.
+func MakeCORSPolicy(ctx context.Context, ing *v1alpha1.Ingress) (*istiov1beta1.CorsPolicy, error) {
+	v := ing.Annotations[CORSPolicyAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var policy CORSPolicy
+	if err := yaml.UnmarshalStrict([]byte(v), &policy); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", CORSPolicyAnnotationKey, err)
+	}
+
+	if len(policy.AllowOrigins) == 0 {
+		return nil, fmt.Errorf("invalid %s annotation: allowOrigins must not be empty", CORSPolicyAnnotationKey)
+	}
+	anyOrigins := false
+	for _, origin := range policy.AllowOrigins {
+		if origin == "" {
+			return nil, fmt.Errorf("invalid %s annotation: allowOrigins must not contain empty origins", CORSPolicyAnnotationKey)
+		}
+		anyOrigins = anyOrigins || origin == anyOrigin
+	}
+	for _, method := range policy.AllowMethods {
+		if errs := validation.IsHTTPHeaderName(method); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid %s annotation: method %q: %s", CORSPolicyAnnotationKey, method, strings.Join(errs, ", "))
+		}
+	}
+	for _, name := range append(policy.AllowHeaders[:len(policy.AllowHeaders):len(policy.AllowHeaders)], policy.ExposeHeaders...) {
+		if errs := validation.IsHTTPHeaderName(name); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid %s annotation: header %q: %s", CORSPolicyAnnotationKey, name, strings.Join(errs, ", "))
+		}
+	}
+	if policy.MaxAge.Duration < 0 {
+		return nil, fmt.Errorf("invalid %s annotation: maxAge must not be negative, was: %v", CORSPolicyAnnotationKey, policy.MaxAge.Duration)
+	}
+	if anyOrigins && policy.AllowCredentials != nil && *policy.AllowCredentials {
+		return nil, fmt.Errorf("invalid %s annotation: allowCredentials can't be set when all the origins are allowed", CORSPolicyAnnotationKey)
+	}
+
+	istio := config.FromContext(ctx).Istio
+	cors := &istiov1beta1.CorsPolicy{
+		AllowMethods: policy.AllowMethods,
+		AllowHeaders: policy.AllowHeaders,
+	}
+	for _, origin := range policy.AllowOrigins {
+		match := &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Exact{Exact: origin}}
+		if origin == anyOrigin {
+			match.MatchType = &istiov1beta1.StringMatch_Regex{Regex: ".*"}
+		}
+		cors.AllowOrigins = append(cors.AllowOrigins, match)
+	}
+	// The headers of the Ingress come first, followed by the defaults it doesn't expose.
+	exposed := sets.New[string]()
+	for _, name := range policy.ExposeHeaders {
+		if !exposed.Has(strings.ToLower(name)) {
+			cors.ExposeHeaders = append(cors.ExposeHeaders, name)
+			exposed.Insert(strings.ToLower(name))
+		}
+	}
+	for _, name := range sets.List(istio.CORSExposeHeaders) {
+		if !exposed.Has(strings.ToLower(name)) {
+			cors.ExposeHeaders = append(cors.ExposeHeaders, name)
+			exposed.Insert(strings.ToLower(name))
+		}
+	}
+	if policy.MaxAge.Duration > 0 {
+		cors.MaxAge = durationpb.New(policy.MaxAge.Duration)
+	}
+	switch {
+	case policy.AllowCredentials != nil:
+		cors.AllowCredentials = wrapperspb.Bool(*policy.AllowCredentials)
+	case istio.CORSAllowCredentials && !anyOrigins:
+		cors.AllowCredentials = wrapperspb.Bool(true)
+	}
+	return cors, nil
+}
+
+// WithCORSPolicy sets the given CORS policy on the HTTP routes of the VirtualService.
+func WithCORSPolicy(vs *v1beta1.VirtualService, cors *istiov1beta1.CorsPolicy) {
+	if cors == nil {
+		return
+	}
+	for _, route := range vs.Spec.Http {
+		route.CorsPolicy = cors.DeepCopy()
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
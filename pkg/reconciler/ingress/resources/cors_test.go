@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func exactOrigin(origin string) *istiov1beta1.StringMatch {
+	return &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Exact{Exact: origin}}
+}
+
+func TestMakeCORSPolicy(t *testing.T) {
+	defaults := config.Istio{
+		CORSExposeHeaders:    sets.New("X-Request-Id", "Server-Timing"),
+		CORSAllowCredentials: true,
+	}
+
+	tests := []struct {
+		name       string
+		istio      config.Istio
+		annotation string
+		want       *istiov1beta1.CorsPolicy
+		wantErr    bool
+	}{{
+		name: "no policy",
+	}, {
+		name:  "defaults don't enable CORS",
+		istio: defaults,
+	}, {
+		name:       "policy",
+		annotation: "{allowOrigins: [https://example.com], allowMethods: [GET, POST], allowHeaders: [Authorization], exposeHeaders: [X-Total-Count], maxAge: 1h}",
+		want: &istiov1beta1.CorsPolicy{
+			AllowOrigins:  []*istiov1beta1.StringMatch{exactOrigin("https://example.com")},
+			AllowMethods:  []string{"GET", "POST"},
+			AllowHeaders:  []string{"Authorization"},
+			ExposeHeaders: []string{"X-Total-Count"},
+			MaxAge:        durationpb.New(time.Hour),
+		},
+	}, {
+		name:       "defaults merged into the policy",
+		istio:      defaults,
+		annotation: "{allowOrigins: [https://example.com], exposeHeaders: [X-Total-Count, x-request-id]}",
+		want: &istiov1beta1.CorsPolicy{
+			AllowOrigins:     []*istiov1beta1.StringMatch{exactOrigin("https://example.com")},
+			ExposeHeaders:    []string{"X-Total-Count", "x-request-id", "Server-Timing"},
+			AllowCredentials: wrapperspb.Bool(true),
+		},
+	}, {
+		name:       "policy disallows credentials",
+		istio:      defaults,
+		annotation: "{allowOrigins: [https://example.com], allowCredentials: false}",
+		want: &istiov1beta1.CorsPolicy{
+			AllowOrigins:     []*istiov1beta1.StringMatch{exactOrigin("https://example.com")},
+			ExposeHeaders:    []string{"Server-Timing", "X-Request-Id"},
+			AllowCredentials: wrapperspb.Bool(false),
+		},
+	}, {
+		name:       "any origin without the default credentials",
+		istio:      defaults,
+		annotation: "{allowOrigins: ['*']}",
+		want: &istiov1beta1.CorsPolicy{
+			AllowOrigins:  []*istiov1beta1.StringMatch{{MatchType: &istiov1beta1.StringMatch_Regex{Regex: ".*"}}},
+			ExposeHeaders: []string{"Server-Timing", "X-Request-Id"},
+		},
+	}, {
+		name:       "any origin with credentials",
+		annotation: "{allowOrigins: ['*'], allowCredentials: true}",
+		wantErr:    true,
+	}, {
+		name:       "invalid yaml",
+		annotation: "{allowOrigins: [",
+		wantErr:    true,
+	}, {
+		name:       "no origins",
+		annotation: "{allowMethods: [GET]}",
+		wantErr:    true,
+	}, {
+		name:       "empty origin",
+		annotation: "{allowOrigins: ['']}",
+		wantErr:    true,
+	}, {
+		name:       "invalid method",
+		annotation: "{allowOrigins: [https://example.com], allowMethods: ['GET POST']}",
+		wantErr:    true,
+	}, {
+		name:       "invalid header",
+		annotation: "{allowOrigins: [https://example.com], exposeHeaders: ['X Total']}",
+		wantErr:    true,
+	}, {
+		name:       "negative max age",
+		annotation: "{allowOrigins: [https://example.com], maxAge: -1s}",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := config.ToContext(context.Background(), &config.Config{Istio: &tt.istio})
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+				},
+			}
+			if tt.annotation != "" {
+				ing.Annotations[CORSPolicyAnnotationKey] = tt.annotation
+			}
+			got, err := MakeCORSPolicy(ctx, ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MakeCORSPolicy() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Error("MakeCORSPolicy() (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithCORSPolicy(t *testing.T) {
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{}, {}},
+		},
+	}
+
+	WithCORSPolicy(vs, nil)
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] != "" {
+		t.Error("The VirtualService was changed without a CORS policy")
+	}
+
+	cors := &istiov1beta1.CorsPolicy{
+		AllowOrigins:     []*istiov1beta1.StringMatch{exactOrigin("https://example.com")},
+		AllowCredentials: wrapperspb.Bool(true),
+	}
+	WithCORSPolicy(vs, cors)
+	for i, route := range vs.Spec.Http {
+		if diff := cmp.Diff(cors, route.CorsPolicy, protocmp.Transform()); diff != "" {
+			t.Errorf("CorsPolicy of route %d (-want, +got): %s", i, diff)
+		}
+	}
+	if vs.Spec.Http[0].CorsPolicy == vs.Spec.Http[1].CorsPolicy {
+		t.Error("The routes share their CORS policy")
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+}
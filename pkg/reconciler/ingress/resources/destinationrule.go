@@ -20,6 +20,7 @@ import (
 	istiov1beta1 "istio.io/api/networking/v1beta1"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/certificates"
@@ -67,6 +68,24 @@ func MakeInternalEncryptionDestinationRule(host string, ing *v1alpha1.Ingress, h
 				H2UpgradePolicy: istiov1beta1.ConnectionPoolSettings_HTTPSettings_UPGRADE},
 		}
 	}
+	dr.Annotations = kaccessor.WithSpecHash(dr.Annotations, &dr.Spec)
 
 	return dr
 }
+
+// MakeDomainMappingDestinationRule creates a DestinationRule that enables upstream TLS for the
+// specified backend host of a DomainMapping, which forwards to the local gateways terminating
+// the TLS of the cluster-local host the DomainMapping rewrites the requests to.
+func MakeDomainMappingDestinationRule(host, rewriteHost string, ing *v1alpha1.Ingress) *v1beta1.DestinationRule {
+	dr := MakeInternalEncryptionDestinationRule(host, ing, false)
+	dr.Spec.TrafficPolicy.Tls = &istiov1beta1.ClientTLSSettings{
+		Mode: istiov1beta1.ClientTLSSettings_SIMPLE,
+		// The certificates of the cluster-local hosts are issued by the CA of the
+		// system-internal ones, as both are by default.
+		CredentialName:  config.ServingRoutingCertName,
+		Sni:             rewriteHost,
+		SubjectAltNames: []string{rewriteHost},
+	}
+	dr.Annotations = kaccessor.WithSpecHash(dr.Annotations, &dr.Spec)
+	return dr
+}
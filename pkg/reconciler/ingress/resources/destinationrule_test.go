@@ -24,6 +24,7 @@ import (
 	istiov1beta1 "istio.io/api/networking/v1beta1"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/networking/pkg/certificates"
@@ -77,6 +78,8 @@ func TestMakeInternalEncryptionDestinationRuleHttp1(t *testing.T) {
 		},
 	}
 
+	expected.Annotations = kaccessor.WithSpecHash(expected.Annotations, &expected.Spec)
+
 	if diff := cmp.Diff(expected, dr, protocmp.Transform()); diff != "" {
 		t.Error("Unexpected DestinationRule (-want +got):", diff)
 	}
@@ -114,6 +117,45 @@ func TestMakeInternalEncryptionDestinationRuleHttp2(t *testing.T) {
 		},
 	}
 
+	expected.Annotations = kaccessor.WithSpecHash(expected.Annotations, &expected.Spec)
+
+	if diff := cmp.Diff(expected, dr, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected DestinationRule (-want +got):", diff)
+	}
+}
+
+func TestMakeDomainMappingDestinationRule(t *testing.T) {
+	const rewriteHost = "target.my-namespace.svc.cluster.local"
+	dr := MakeDomainMappingDestinationRule(host, rewriteHost, ing)
+	expected := &v1beta1.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            host,
+			Namespace:       ing.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+			Annotations: map[string]string{
+				"my-annotation": "my-value",
+			},
+			Labels: map[string]string{
+				networking.IngressLabelKey: "my-ingress",
+				RouteLabelKey:              "my-route",
+				RouteNamespaceLabelKey:     "my-route-namespace",
+			},
+		},
+		Spec: istiov1beta1.DestinationRule{
+			Host: host,
+			TrafficPolicy: &istiov1beta1.TrafficPolicy{
+				Tls: &istiov1beta1.ClientTLSSettings{
+					Mode:            istiov1beta1.ClientTLSSettings_SIMPLE,
+					CredentialName:  config.ServingRoutingCertName,
+					Sni:             rewriteHost,
+					SubjectAltNames: []string{rewriteHost},
+				},
+			},
+		},
+	}
+
+	expected.Annotations = kaccessor.WithSpecHash(expected.Annotations, &expected.Spec)
+
 	if diff := cmp.Diff(expected, dr, protocmp.Transform()); diff != "" {
 		t.Error("Unexpected DestinationRule (-want +got):", diff)
 	}
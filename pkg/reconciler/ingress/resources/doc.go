@@ -16,4 +16,9 @@ limitations under the License.
 
 // Package resources holds simple functions for synthesizing child resources from
 // an Ingress resource and any relevant Ingress controller configuration.
+//
+// Translate is the entry point of the tools reusing the translation of the controller, e.g.
+// to render the resources of Ingresses offline. Like the other functions of the package, it
+// does not read the cluster: the configuration, gateway Services and certificate Secrets are
+// given to it. TranslationVersion tells the revisions of the generated resources apart.
 package resources
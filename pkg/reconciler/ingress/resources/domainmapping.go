@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	netconfig "knative.dev/networking/pkg/config"
+)
+
+// LocalGatewayHTTPSPort is the port of the Services of the local gateways forwarding to
+// their TLS servers, see ClusterLocalGatewayHTTPSPort.
+const LocalGatewayHTTPSPort = 443
+
+// DomainMappingsEncrypted returns whether the requests of the DomainMappings, whose paths
+// rewrite their host to the cluster-local host of their target and whose backends forward
+// to the local gateways, are encrypted up to the local gateways. It requires the local
+// gateways to terminate the TLS of the cluster-local hosts, see TLSVisibilities, and the
+// DestinationRules of system-internal-tls.
+func DomainMappingsEncrypted(cfg *config.Config) bool {
+	return cfg.Network.SystemInternalTLSEnabled() &&
+		cfg.Network.ClusterLocalDomainTLS == netconfig.EncryptionEnabled
+}
+
+// WithDomainMappingEncryption routes the requests of the paths of the VirtualService that
+// rewrite their host, the ones of the DomainMappings, to the HTTPS port of their backends,
+// see MakeDomainMappingDestinationRule.
+func WithDomainMappingEncryption(vs *v1beta1.VirtualService) {
+	changed := false
+	for _, route := range vs.Spec.Http {
+		if route.Rewrite.GetAuthority() == "" {
+			continue
+		}
+		for _, dest := range route.Route {
+			dest.Destination.Port = &istiov1beta1.PortSelector{Number: LocalGatewayHTTPSPort}
+		}
+		changed = true
+	}
+	if changed {
+		vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	netconfig "knative.dev/networking/pkg/config"
+)
+
+func TestDomainMappingsEncrypted(t *testing.T) {
+	tests := []struct {
+		name          string
+		systemTLS     netconfig.EncryptionConfig
+		localTLS      netconfig.EncryptionConfig
+		ambient       bool
+		wantEncrypted bool
+	}{{
+		name:      "no cluster-local-domain-tls",
+		systemTLS: netconfig.EncryptionEnabled,
+		localTLS:  netconfig.EncryptionDisabled,
+	}, {
+		name:      "no system-internal-tls",
+		systemTLS: netconfig.EncryptionDisabled,
+		localTLS:  netconfig.EncryptionEnabled,
+	}, {
+		name:          "both enabled",
+		systemTLS:     netconfig.EncryptionEnabled,
+		localTLS:      netconfig.EncryptionEnabled,
+		wantEncrypted: true,
+	}, {
+		name:          "ambient mesh",
+		systemTLS:     netconfig.EncryptionEnabled,
+		localTLS:      netconfig.EncryptionEnabled,
+		ambient:       true,
+		wantEncrypted: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Istio:   &config.Istio{AmbientMode: tt.ambient},
+				Network: &netconfig.Config{SystemInternalTLS: tt.systemTLS, ClusterLocalDomainTLS: tt.localTLS},
+			}
+			if got := DomainMappingsEncrypted(cfg); got != tt.wantEncrypted {
+				t.Errorf("DomainMappingsEncrypted() = %v, want: %v", got, tt.wantEncrypted)
+			}
+		})
+	}
+}
+
+func TestWithDomainMappingEncryption(t *testing.T) {
+	destination := func() *istiov1beta1.HTTPRouteDestination {
+		return &istiov1beta1.HTTPRouteDestination{Destination: &istiov1beta1.Destination{
+			Host: "target.ns.svc.cluster.local",
+			Port: &istiov1beta1.PortSelector{Number: 80},
+		}}
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Rewrite: &istiov1beta1.HTTPRewrite{Authority: "target.ns.svc.cluster.local"},
+				Route:   []*istiov1beta1.HTTPRouteDestination{destination()},
+			}, {
+				Route: []*istiov1beta1.HTTPRouteDestination{destination()},
+			}},
+		},
+	}
+
+	WithDomainMappingEncryption(vs)
+
+	if got := vs.Spec.Http[0].Route[0].Destination.Port.GetNumber(); got != LocalGatewayHTTPSPort {
+		t.Errorf("Port of the DomainMapping = %d, want: %d", got, LocalGatewayHTTPSPort)
+	}
+	if got := vs.Spec.Http[1].Route[0].Destination.Port.GetNumber(); got != 80 {
+		t.Errorf("Port of the other route = %d, want: 80", got)
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/tracker"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EnvoyFilterTemplateAnnotationKey is the annotation referencing the ConfigMap in the
+	// Knative system namespace that holds the EnvoyFilter patches for an Ingress.
+	EnvoyFilterTemplateAnnotationKey = "networking.knative.dev/envoyfilter-template"
+
+	// EnvoyFilterTemplateKey is the key of the template ConfigMap holding the patches.
+	EnvoyFilterTemplateKey = "patches"
+
+	// IngressNamespaceLabelKey is the label attached to resources generated for an
+	// Ingress in another namespace, which therefore cannot be owned by it.
+	IngressNamespaceLabelKey = networking.GroupName + "/ingressNamespace"
+)
+
+// EnvoyFilterTemplateData is the data the EnvoyFilter patches template is executed with.
+type EnvoyFilterTemplateData struct {
+	// Name and Namespace of the Ingress.
+	Name      string
+	Namespace string
+	// Hosts of the Ingress that are exposed on the gateway.
+	Hosts []string
+	// GatewayServiceName and GatewayServiceNamespace identify the gateway.
+	GatewayServiceName      string
+	GatewayServiceNamespace string
+}
+
+// MakeEnvoyFilter instantiates the given patches template for the gateway behind gatewaySvc,
+// restricted to the hosts the Ingress exposes with the given visibility.
+func MakeEnvoyFilter(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility, gatewaySvc *corev1.Service, patches string) (*v1alpha3.EnvoyFilter, error) {
+	tmpl, err := template.New(ing.Annotations[EnvoyFilterTemplateAnnotationKey]).Option("missingkey=error").Parse(patches)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EnvoyFilter template: %w", err)
+	}
+
+	hosts := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility == visibility {
+			hosts.Insert(rule.Hosts...)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, EnvoyFilterTemplateData{
+		Name:                    ing.Name,
+		Namespace:               ing.Namespace,
+		Hosts:                   sets.List(hosts),
+		GatewayServiceName:      gatewaySvc.Name,
+		GatewayServiceNamespace: gatewaySvc.Namespace,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to execute EnvoyFilter template: %w", err)
+	}
+
+	configPatches, err := yaml.YAMLToJSON(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert EnvoyFilter patches: %w", err)
+	}
+	spec, err := json.Marshal(map[string]json.RawMessage{"configPatches": configPatches})
+	if err != nil {
+		return nil, err
+	}
+
+	ef := &v1alpha3.EnvoyFilter{
+		ObjectMeta: gatewayResourceMeta(ing, gatewaySvc),
+	}
+	if err := ef.Spec.UnmarshalJSON(spec); err != nil {
+		return nil, fmt.Errorf("invalid EnvoyFilter patches: %w", err)
+	}
+	ef.Spec.WorkloadSelector = &istiov1alpha3.WorkloadSelector{
+		Labels: gatewaySvc.Spec.Selector,
+	}
+	ef.Annotations = kaccessor.WithSpecHash(ef.Annotations, &ef.Spec)
+
+	return ef, nil
+}
+
+// ConfigMapRef returns the reference of the ConfigMap tracked by the Ingresses, e.g. the
+// one of their EnvoyFilter template.
+func ConfigMapRef(namespace, name string) tracker.Reference {
+	apiVersion, kind := corev1.SchemeGroupVersion.WithKind("ConfigMap").ToAPIVersionAndKind()
+	return tracker.Reference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
+
+// GatewayResourceLabels returns the labels of the resources generated for the Ingress
+// next to its gateways, such as EnvoyFilters.
+func GatewayResourceLabels(ing *v1alpha1.Ingress) map[string]string {
+	return map[string]string{
+		networking.IngressLabelKey: ing.Name,
+		IngressNamespaceLabelKey:   ing.Namespace,
+	}
+}
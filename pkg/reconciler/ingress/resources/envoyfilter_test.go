@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const testPatches = `
+{{- range .Hosts }}
+- applyTo: VIRTUAL_HOST
+  match:
+    context: GATEWAY
+    routeConfiguration:
+      vhost:
+        name: "{{ . }}:80"
+  patch:
+    operation: MERGE
+    value:
+      include_request_attempt_count: true
+{{- end }}
+`
+
+func TestMakeEnvoyFilter(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-ingress",
+			Namespace: "my-namespace",
+			Annotations: map[string]string{
+				EnvoyFilterTemplateAnnotationKey: "my-template",
+			},
+		},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      []string{"foo.example.com"},
+				Visibility: v1alpha1.IngressVisibilityExternalIP,
+			}, {
+				Hosts:      []string{"foo.my-namespace.svc.cluster.local"},
+				Visibility: v1alpha1.IngressVisibilityClusterLocal,
+			}},
+		},
+	}
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+
+	got, err := MakeEnvoyFilter(ing, v1alpha1.IngressVisibilityExternalIP, gatewaySvc, testPatches)
+	if err != nil {
+		t.Fatal("MakeEnvoyFilter() =", err)
+	}
+
+	if got.Name != "my-namespace-my-ingress-istio-ingressgateway" || got.Namespace != "istio-system" {
+		t.Errorf("EnvoyFilter = %s/%s, want istio-system/my-namespace-my-ingress-istio-ingressgateway", got.Namespace, got.Name)
+	}
+	if diff := cmp.Diff(GatewayResourceLabels(ing), got.Labels); diff != "" {
+		t.Error("Unexpected labels (-want +got):", diff)
+	}
+	wantSelector := &istiov1alpha3.WorkloadSelector{Labels: gatewaySvc.Spec.Selector}
+	if diff := cmp.Diff(wantSelector, got.Spec.WorkloadSelector, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected workload selector (-want +got):", diff)
+	}
+	if len(got.Spec.ConfigPatches) != 1 {
+		t.Fatalf("len(ConfigPatches) = %d, want 1", len(got.Spec.ConfigPatches))
+	}
+	if name := got.Spec.ConfigPatches[0].Match.GetRouteConfiguration().GetVhost().GetName(); name != "foo.example.com:80" {
+		t.Errorf("vhost = %q, want foo.example.com:80", name)
+	}
+}
+
+func TestMakeEnvoyFilterErrors(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-ingress",
+			Namespace: "my-namespace",
+		},
+	}
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+	}
+
+	for name, patches := range map[string]string{
+		"invalid template": "{{ .Hosts",
+		"unknown field":    "{{ .Unknown }}",
+		"invalid yaml":     "- applyTo: [",
+		"invalid patch":    "- applyTo: NOT_A_THING",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := MakeEnvoyFilter(ing, v1alpha1.IngressVisibilityExternalIP, gatewaySvc, patches); err == nil {
+				t.Error("MakeEnvoyFilter() = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// ExtAuthzProviderAnnotationKey is the annotation referencing the extension provider,
+	// declared in the Istio mesh config, which authorizes the requests to the Ingress.
+	ExtAuthzProviderAnnotationKey = "networking.knative.dev/ext-authz-provider"
+
+	// ExtAuthzPathsAnnotationKey is the annotation holding the comma separated list of
+	// paths authorized by the provider. By default, all the paths of the Ingress are.
+	ExtAuthzPathsAnnotationKey = "networking.knative.dev/ext-authz-paths"
+)
+
+// ExtAuthzAuthorizationPolicyName returns the name of the AuthorizationPolicy delegating
+// the authorization of the requests to the Ingress on the gateway behind gatewaySvc.
+func ExtAuthzAuthorizationPolicyName(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service) string {
+	return kmeta.ChildName(ing.Namespace+"-"+ing.Name, "-extauthz-"+gatewaySvc.Name)
+}
+
+// MakeExtAuthzAuthorizationPolicy creates a CUSTOM AuthorizationPolicy delegating the
+// authorization of the requests to the given hosts of the Ingress to the external
+// authorization provider referenced by its annotations, on the gateway behind gatewaySvc.
+func MakeExtAuthzAuthorizationPolicy(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service, hosts []string) *v1beta1.AuthorizationPolicy {
+	// The Host header may carry the port of the gateway.
+	operationHosts := make([]string, 0, 2*len(hosts))
+	for _, host := range hosts {
+		operationHosts = append(operationHosts, host, host+":*")
+	}
+
+	meta := gatewayResourceMeta(ing, gatewaySvc)
+	meta.Name = ExtAuthzAuthorizationPolicyName(ing, gatewaySvc)
+	ap := &v1beta1.AuthorizationPolicy{
+		ObjectMeta: meta,
+		Spec: istiov1beta1.AuthorizationPolicy{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: gatewaySvc.Spec.Selector,
+			},
+			Action: istiov1beta1.AuthorizationPolicy_CUSTOM,
+			ActionDetail: &istiov1beta1.AuthorizationPolicy_Provider{
+				Provider: &istiov1beta1.AuthorizationPolicy_ExtensionProvider{
+					Name: ing.Annotations[ExtAuthzProviderAnnotationKey],
+				},
+			},
+			Rules: []*istiov1beta1.Rule{{
+				To: []*istiov1beta1.Rule_To{{
+					Operation: &istiov1beta1.Operation{
+						Hosts: operationHosts,
+						Paths: splitAnnotationList(ing.Annotations[ExtAuthzPathsAnnotationKey]),
+					},
+				}},
+			}},
+		},
+	}
+	ap.Annotations = kaccessor.WithSpecHash(ap.Annotations, &ap.Spec)
+
+	return ap
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeExtAuthzAuthorizationPolicy(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-ingress",
+			Namespace: "my-namespace",
+			Annotations: map[string]string{
+				ExtAuthzProviderAnnotationKey: "oauth2-proxy",
+				ExtAuthzPathsAnnotationKey:    "/admin/*, /api/*",
+			},
+		},
+	}
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+
+	got := MakeExtAuthzAuthorizationPolicy(ing, gatewaySvc, []string{"foo.example.com"})
+
+	if got.Name != "my-namespace-my-ingress-extauthz-istio-ingressgateway" || got.Namespace != "istio-system" {
+		t.Errorf("AuthorizationPolicy = %s/%s, want istio-system/my-namespace-my-ingress-extauthz-istio-ingressgateway", got.Namespace, got.Name)
+	}
+	if diff := cmp.Diff(GatewayResourceLabels(ing), got.Labels); diff != "" {
+		t.Error("Unexpected labels (-want +got):", diff)
+	}
+	want := &istiov1beta1.AuthorizationPolicy{
+		Selector: &istiotypev1beta1.WorkloadSelector{MatchLabels: gatewaySvc.Spec.Selector},
+		Action:   istiov1beta1.AuthorizationPolicy_CUSTOM,
+		ActionDetail: &istiov1beta1.AuthorizationPolicy_Provider{
+			Provider: &istiov1beta1.AuthorizationPolicy_ExtensionProvider{Name: "oauth2-proxy"},
+		},
+		Rules: []*istiov1beta1.Rule{{
+			To: []*istiov1beta1.Rule_To{{
+				Operation: &istiov1beta1.Operation{
+					Hosts: []string{"foo.example.com", "foo.example.com:*"},
+					Paths: []string{"/admin/*", "/api/*"},
+				},
+			}},
+		}},
+	}
+	if diff := cmp.Diff(want, &got.Spec, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected spec (-want +got):", diff)
+	}
+}
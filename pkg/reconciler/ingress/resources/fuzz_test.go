@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// The fuzz targets only check that the resources are generated without panicking, whatever
+// the Ingress and the certificates. Their seeds are run by go test, and they are fuzzed with
+//
+//	go test ./pkg/reconciler/ingress/resources -run '^$' -fuzz FuzzMakeVirtualServices
+
+func FuzzMakeVirtualServices(f *testing.F) {
+	addIngressSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ing := &v1alpha1.Ingress{}
+		if err := json.Unmarshal(data, ing); err != nil {
+			return
+		}
+		MakeVirtualServices(ing, makeGatewayMap([]string{"gateway"}, []string{"private-gateway"}))
+	})
+}
+
+func FuzzMakeIngressTLSGateways(f *testing.F) {
+	addIngressSeeds(f)
+
+	gatewayServices := []*corev1.Service{{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-ingressgateway"}}}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ing := &v1alpha1.Ingress{}
+		if err := json.Unmarshal(data, ing); err != nil {
+			return
+		}
+		for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+			ingressTLS := ing.GetIngressTLSForVisibility(visibility)
+			MakeIngressTLSGateways(ing, visibility, ingressTLS, originSecretsOf(ingressTLS), gatewayServices)
+		}
+	})
+}
+
+// FuzzCertificateSecret fuzzes the parsing of the certificates of the Secrets copied into the
+// namespace of the gateways. The copies keep the PEM data as is, but the certificates are
+// parsed to tell wildcard ones apart and to verify they cover the hosts of the Ingress.
+func FuzzCertificateSecret(f *testing.F) {
+	cert, err := GenerateCertificate([]string{"*.example.com", "example.com"}, "cert", "ns")
+	if err != nil {
+		f.Fatal("Failed to generate the certificate:", err)
+	}
+	f.Add(cert.Data[corev1.TLSCertKey], "foo.example.com")
+	f.Add(cert.Data[corev1.TLSCertKey], "example.org")
+	f.Add([]byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"), "example.com")
+	f.Add(cert.Data[corev1.TLSPrivateKeyKey], "example.com")
+	f.Add([]byte{}, "")
+
+	f.Fuzz(func(t *testing.T, certPEM []byte, host string) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "cert"},
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM},
+		}
+		secrets := map[string]*corev1.Secret{"ns/cert": secret}
+		GetHostsFromCertSecret(secret)
+		CategorizeSecrets(secrets)
+		ValidateCertificateHosts([]v1alpha1.IngressTLS{{
+			Hosts:           []string{host},
+			SecretNamespace: "ns",
+			SecretName:      "cert",
+		}}, secrets)
+	})
+}
+
+// addIngressSeeds seeds the fuzz target with the JSON of the Ingresses of the golden files.
+func addIngressSeeds(f *testing.F) {
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.yaml"))
+	if err != nil {
+		f.Fatal("Failed to list the golden inputs:", err)
+	}
+	for _, input := range inputs {
+		if strings.HasSuffix(input, ".golden.yaml") {
+			continue
+		}
+		b, err := os.ReadFile(input)
+		if err != nil {
+			f.Fatal("Failed to read the golden input:", err)
+		}
+		data, err := yaml.YAMLToJSON(b)
+		if err != nil {
+			f.Fatal("Failed to convert the golden input:", err)
+		}
+		f.Add(data)
+	}
+}
+
+// originSecretsOf returns Secrets for all the certificates referenced by the Ingress TLS.
+func originSecretsOf(ingressTLS []v1alpha1.IngressTLS) map[string]*corev1.Secret {
+	secrets := make(map[string]*corev1.Secret, len(ingressTLS))
+	for _, tls := range ingressTLS {
+		secrets[secretKey(tls)] = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: tls.SecretNamespace,
+			Name:      tls.SecretName,
+			UID:       types.UID(tls.SecretNamespace + "-" + tls.SecretName),
+		}}
+	}
+	return secrets
+}
@@ -18,9 +18,8 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"hash/adler32"
-	"regexp"
 	"sort"
 	"strings"
 
@@ -32,9 +31,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
-	corev1listers "k8s.io/client-go/listers/core/v1"
+	"knative.dev/net-istio/pkg/reconciler/fips"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
@@ -45,9 +46,7 @@ const (
 	GatewayHTTPPort              = 80
 	ExternalGatewayHTTPSPort     = 443
 	ClusterLocalGatewayHTTPSPort = 8444
-	dns1123LabelMaxLength        = 63 // Public for testing only.
-	dns1123LabelFmt              = "[a-zA-Z0-9](?:[-a-zA-Z0-9]*[a-zA-Z0-9])?"
-	localGatewayPostfix          = "-local"
+	dns1123LabelMaxLength        = names.MaxLabelLength // Public for testing only.
 )
 
 var httpServerPortName = "http-server"
@@ -65,8 +64,6 @@ var placeholderServer = istiov1beta1.Server{
 	},
 }
 
-var dns1123LabelRegexp = regexp.MustCompile("^" + dns1123LabelFmt + "$")
-
 // GetServers gets the `Servers` from `Gateway` that belongs to the given Ingress.
 func GetServers(gateway *v1beta1.Gateway, ing *v1alpha1.Ingress) []*istiov1beta1.Server {
 	servers := []*istiov1beta1.Server{}
@@ -96,7 +93,10 @@ func belongsToIngress(server *istiov1beta1.Server, ing *v1alpha1.Ingress) bool {
 	if len(portNameSplits) != 2 {
 		return false
 	}
-	return portNameSplits[0] == portNamePrefix(ing.GetNamespace(), ing.GetName())
+	// The servers added before the FIPS-compatible mode was enabled still have to be
+	// recognized to be replaced.
+	return portNameSplits[0] == portNamePrefix(ing.GetNamespace(), ing.GetName()) ||
+		portNameSplits[0] == portNamePrefixWithChecksum(ing.GetNamespace(), ing.GetName(), fips.LegacyChecksum)
 }
 
 // SortServers sorts `Server` according to its port name.
@@ -107,17 +107,14 @@ func SortServers(servers []*istiov1beta1.Server) []*istiov1beta1.Server {
 	return servers
 }
 
-// MakeIngressTLSGateways creates Gateways that have only TLS servers for a given Ingress.
-func MakeIngressTLSGateways(ctx context.Context, ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility,
-	ingressTLS []v1alpha1.IngressTLS, originSecrets map[string]*corev1.Secret, svcLister corev1listers.ServiceLister) ([]*v1beta1.Gateway, error) {
+// MakeIngressTLSGateways creates Gateways that have only TLS servers for a given Ingress, one for
+// each of the given gateway services.
+func MakeIngressTLSGateways(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility,
+	ingressTLS []v1alpha1.IngressTLS, originSecrets map[string]*corev1.Secret, gatewayServices []*corev1.Service) ([]*v1beta1.Gateway, error) {
 	// No need to create Gateway if there is no related ingress TLS.
 	if len(ingressTLS) == 0 {
 		return []*v1beta1.Gateway{}, nil
 	}
-	gatewayServices, err := getGatewayServices(ctx, ing, svcLister)
-	if err != nil {
-		return nil, err
-	}
 	gateways := make([]*v1beta1.Gateway, len(gatewayServices))
 	for i, gatewayService := range gatewayServices {
 		servers, err := MakeTLSServers(ing, visibility, ingressTLS, gatewayService.Namespace, originSecrets)
@@ -129,34 +126,23 @@ func MakeIngressTLSGateways(ctx context.Context, ing *v1alpha1.Ingress, visibili
 	return gateways, nil
 }
 
-// MakeExternalIngressGateways creates Gateways with given Servers for a given Ingress.
-func MakeExternalIngressGateways(ctx context.Context, ing *v1alpha1.Ingress, servers []*istiov1beta1.Server, svcLister corev1listers.ServiceLister) ([]*v1beta1.Gateway, error) {
-	gatewayServices, err := getGatewayServices(ctx, ing, svcLister)
-	if err != nil {
-		return nil, err
-	}
+// MakeExternalIngressGateways creates Gateways with given Servers for a given Ingress, one for
+// each of the given gateway services.
+func MakeExternalIngressGateways(ing *v1alpha1.Ingress, servers []*istiov1beta1.Server, gatewayServices []*corev1.Service) []*v1beta1.Gateway {
 	gateways := make([]*v1beta1.Gateway, len(gatewayServices))
 	for i, gatewayService := range gatewayServices {
-		if err != nil {
-			return nil, err
-		}
 		gateways[i] = makeIngressGateway(ing, v1alpha1.IngressVisibilityExternalIP, gatewayService.Spec.Selector, servers, gatewayService)
 	}
-	return gateways, nil
+	return gateways
 }
 
 // MakeWildcardTLSGateways creates gateways that only contain TLS server with wildcard hosts based on the wildcard secret information.
 // Gateways generated are based on the related ingress being reconciled.
 // For each public ingress service, we will create a list of Gateways. Each Gateway of the list corresponds to a wildcard cert secret.
-func MakeWildcardTLSGateways(ctx context.Context, ing *v1alpha1.Ingress, originWildcardSecrets map[string]*corev1.Secret,
-	svcLister corev1listers.ServiceLister) ([]*v1beta1.Gateway, error) {
+func MakeWildcardTLSGateways(originWildcardSecrets map[string]*corev1.Secret, gatewayServices []*corev1.Service) ([]*v1beta1.Gateway, error) {
 	if len(originWildcardSecrets) == 0 {
 		return []*v1beta1.Gateway{}, nil
 	}
-	gatewayServices, err := getGatewayServices(ctx, ing, svcLister)
-	if err != nil {
-		return nil, err
-	}
 	gateways := []*v1beta1.Gateway{}
 	for _, gatewayService := range gatewayServices {
 		gws, err := makeWildcardTLSGateways(originWildcardSecrets, gatewayService)
@@ -214,19 +200,22 @@ func makeWildcardTLSGateways(originWildcardSecrets map[string]*corev1.Secret,
 	return gateways, nil
 }
 
-// IsDNS1123Label tests for a string that conforms to the definition of a label in
-// DNS (RFC 1123).
-// This function is copied from https://github.com/istio/istio/blob/806fb24bc121bf93ea06f6a38b7ccb3d78d1f326/pkg/config/labels/instance.go#L97
-// We directly copy this function instead of importing it into vendor and using it because
-// if this function is changed in the upstream (for example, Istio allows the dot in the future), we don't want to
-// import the change without awareness because it could break the compatibility of Gateway name generation.
-func isDNS1123Label(value string) bool {
-	return len(value) <= dns1123LabelMaxLength && dns1123LabelRegexp.MatchString(value)
+// WildcardGatewayName creates the name of wildcard Gateway, see names.WildcardGateway.
+func WildcardGatewayName(secretName, gatewayServiceNamespace, gatewayServiceName string) string {
+	return names.WildcardGateway(secretName, gatewayServiceNamespace, gatewayServiceName)
 }
 
-// WildcardGatewayName creates the name of wildcard Gateway.
-func WildcardGatewayName(secretName, gatewayServiceNamespace, gatewayServiceName string) string {
-	return fmt.Sprintf("wildcard-%x", adler32.Checksum([]byte(secretName+"-"+gatewayServiceNamespace+"-"+gatewayServiceName)))
+// LegacyWildcardGatewayNames returns the names the wildcard Gateways had before the
+// FIPS-compatible mode was enabled, by the names of the Gateways replacing them.
+func LegacyWildcardGatewayNames(originWildcardSecrets map[string]*corev1.Secret, gatewayServices []*corev1.Service) map[string]string {
+	legacy := make(map[string]string, len(originWildcardSecrets)*len(gatewayServices))
+	for _, gatewayService := range gatewayServices {
+		for _, secret := range originWildcardSecrets {
+			legacy[WildcardGatewayName(secret.Name, gatewayService.Namespace, gatewayService.Name)] =
+				names.LegacyWildcardGateway(secret.Name, gatewayService.Namespace, gatewayService.Name)
+		}
+	}
+	return legacy
 }
 
 // GetQualifiedGatewayNames return the qualified Gateway names for the given Gateways.
@@ -267,43 +256,22 @@ func makeIngressGateway(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibi
 	}
 }
 
-func getGatewayServices(ctx context.Context, obj kmeta.Accessor, svcLister corev1listers.ServiceLister) ([]*corev1.Service, error) {
-	ingressSvcMetas, err := GetIngressGatewaySvcNameNamespaces(ctx, obj)
-	if err != nil {
-		return nil, err
-	}
-	services := make([]*corev1.Service, len(ingressSvcMetas))
-	for i, ingressSvcMeta := range ingressSvcMetas {
-		svc, err := svcLister.Services(ingressSvcMeta.Namespace).Get(ingressSvcMeta.Name)
-		if err != nil {
-			return nil, err
-		}
-		services[i] = svc
-	}
-	return services, nil
-}
-
 // GatewayName create a name for the Gateway that is built based on the given Ingress and bonds to the
-// given ingress gateway service.
+// given ingress gateway service, see names.Gateway.
 func GatewayName(accessor kmeta.Accessor, visibility v1alpha1.IngressVisibility, gatewaySvc *corev1.Service) string {
-	prefix := accessor.GetName()
-	if !isDNS1123Label(prefix) {
-		prefix = fmt.Sprint(adler32.Checksum([]byte(prefix)))
-	}
-
-	gatewayServiceKey := fmt.Sprintf("%s/%s", gatewaySvc.Namespace, gatewaySvc.Name)
-	if visibility == v1alpha1.IngressVisibilityClusterLocal {
-		gatewayServiceKey += localGatewayPostfix
-	}
-	gatewayServiceKeyChecksum := fmt.Sprint(adler32.Checksum([]byte(gatewayServiceKey)))
+	return names.Gateway(accessor, visibility, gatewaySvc)
+}
 
-	// Ensure that the overall gateway name still is a DNS1123 label
-	maxPrefixLength := dns1123LabelMaxLength - len(gatewayServiceKeyChecksum) - 1
-	if len(prefix) > maxPrefixLength {
-		prefix = prefix[0:maxPrefixLength]
+// LegacyGatewayNames returns the names the Gateways of the given Ingress had before the
+// FIPS-compatible mode was enabled, which changes the checksums embedded in the names.
+func LegacyGatewayNames(ing *v1alpha1.Ingress, gatewayServices []*corev1.Service) sets.Set[string] {
+	legacy := sets.New[string]()
+	for _, gatewayService := range gatewayServices {
+		for _, visibility := range []v1alpha1.IngressVisibility{v1alpha1.IngressVisibilityExternalIP, v1alpha1.IngressVisibilityClusterLocal} {
+			legacy.Insert(names.LegacyGateway(ing, visibility, gatewayService))
+		}
 	}
-
-	return fmt.Sprint(prefix+"-", adler32.Checksum([]byte(gatewayServiceKey)))
+	return legacy
 }
 
 // MakeTLSServers creates the expected Gateway TLS `Servers` based on the given IngressTLS.
@@ -331,7 +299,7 @@ func MakeTLSServers(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility
 			if !ok {
 				return nil, fmt.Errorf("unable to get the original secret %s/%s", tls.SecretNamespace, tls.SecretName)
 			}
-			credentialName = targetSecret(originSecret, ing)
+			credentialName = names.MirroredSecret(originSecret, ing)
 		}
 
 		servers[i] = &istiov1beta1.Server{
@@ -355,8 +323,12 @@ func MakeTLSServers(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility
 }
 
 func portNamePrefix(prefix, suffix string) string {
-	if !isDNS1123Label(suffix) {
-		suffix = fmt.Sprint(adler32.Checksum([]byte(suffix)))
+	return portNamePrefixWithChecksum(prefix, suffix, fips.Checksum)
+}
+
+func portNamePrefixWithChecksum(prefix, suffix string, checksum func([]byte) uint32) string {
+	if !names.IsDNS1123Label(suffix) {
+		suffix = fmt.Sprint(checksum([]byte(suffix)))
 	}
 	return prefix + "/" + suffix
 }
@@ -399,6 +371,12 @@ func GetNonWildcardIngressTLS(ingressTLS []v1alpha1.IngressTLS, nonWildcardSecre
 
 // GetIngressGatewaySvcNameNamespaces gets the Istio ingress namespaces from ConfigMap for gateways that should expose the service.
 func GetIngressGatewaySvcNameNamespaces(ctx context.Context, obj kmeta.Accessor) ([]metav1.ObjectMeta, error) {
+	return GetGatewaySvcNameNamespacesForVisibility(ctx, obj, v1alpha1.IngressVisibilityExternalIP)
+}
+
+// GetGatewaySvcNameNamespacesForVisibility gets the names and namespaces of the Services of the
+// gateways of the given visibility, from the ConfigMap, that should expose the service.
+func GetGatewaySvcNameNamespacesForVisibility(ctx context.Context, obj kmeta.Accessor, visibility v1alpha1.IngressVisibility) ([]metav1.ObjectMeta, error) {
 	nameNamespaces := make([]metav1.ObjectMeta, 0)
 
 	serviceGateways, err := GatewaysFromContext(ctx, obj)
@@ -406,12 +384,12 @@ func GetIngressGatewaySvcNameNamespaces(ctx context.Context, obj kmeta.Accessor)
 		return nil, fmt.Errorf("failed to get gateway from configuration: %w", err)
 	}
 
-	servicePublicGateways, ok := serviceGateways[v1alpha1.IngressVisibilityExternalIP]
+	visibilityGateways, ok := serviceGateways[visibility]
 	if !ok {
 		return nameNamespaces, nil
 	}
 
-	for _, ingressgateway := range servicePublicGateways {
+	for _, ingressgateway := range visibilityGateways {
 		meta, err := parseIngressGatewayConfig(ingressgateway)
 		if err != nil {
 			return nil, err
@@ -423,6 +401,11 @@ func GetIngressGatewaySvcNameNamespaces(ctx context.Context, obj kmeta.Accessor)
 	return nameNamespaces, nil
 }
 
+// GetGatewaySvcNameNamespace gets the name and namespace of the Service backing the given gateway.
+func GetGatewaySvcNameNamespace(gw config.Gateway) (metav1.ObjectMeta, error) {
+	return parseIngressGatewayConfig(gw)
+}
+
 // TODO(nghia):  Remove this by parsing at config parsing time.
 func parseIngressGatewayConfig(ingressgateway config.Gateway) (metav1.ObjectMeta, error) {
 	ret := metav1.ObjectMeta{}
@@ -438,6 +421,96 @@ func parseIngressGatewayConfig(ingressgateway config.Gateway) (metav1.ObjectMeta
 	return ret, nil
 }
 
+// DescribeServerChanges summarizes the servers added, removed and changed between the
+// given servers of a Gateway, identifying them by their port names and hosts.
+// It returns an empty string when the servers are the same.
+func DescribeServerChanges(before, after []*istiov1beta1.Server) string {
+	describe := func(server *istiov1beta1.Server) string {
+		return fmt.Sprintf("%s (%s)", server.Port.GetName(), strings.Join(server.Hosts, ", "))
+	}
+
+	beforeByName, afterByName := serversByPortName(before), serversByPortName(after)
+	var added, removed, changed []string
+	for _, name := range sets.List(sets.KeySet(afterByName)) {
+		server, ok := beforeByName[name]
+		if !ok {
+			added = append(added, describe(afterByName[name]))
+		} else if !cmp.Equal(server, afterByName[name], protocmp.Transform()) {
+			changed = append(changed, describe(afterByName[name]))
+		}
+	}
+	for _, name := range sets.List(sets.KeySet(beforeByName)) {
+		if _, ok := afterByName[name]; !ok {
+			removed = append(removed, describe(beforeByName[name]))
+		}
+	}
+
+	var summary []string
+	if len(added) > 0 {
+		summary = append(summary, "added servers "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		summary = append(summary, "removed servers "+strings.Join(removed, ", "))
+	}
+	if len(changed) > 0 {
+		summary = append(summary, "changed servers "+strings.Join(changed, ", "))
+	}
+	return strings.Join(summary, "; ")
+}
+
+// ChangedServerOwners returns the Ingresses owning the servers added, removed or changed
+// between the given servers of a Gateway, as told by the port names of the servers. The
+// Ingresses whose names do not fit in a port name are identified by their checksum, so
+// they do not match any Ingress.
+func ChangedServerOwners(before, after []*istiov1beta1.Server) []types.NamespacedName {
+	beforeByName, afterByName := serversByPortName(before), serversByPortName(after)
+	changed := sets.New[string]()
+	for name, server := range beforeByName {
+		if other, ok := afterByName[name]; !ok || !cmp.Equal(server, other, protocmp.Transform()) {
+			changed.Insert(name)
+		}
+	}
+	for name := range afterByName {
+		if _, ok := beforeByName[name]; !ok {
+			changed.Insert(name)
+		}
+	}
+
+	owners := sets.New[types.NamespacedName]()
+	for _, name := range sets.List(changed) {
+		if owner, ok := serverOwner(name); ok {
+			owners.Insert(owner)
+		}
+	}
+	ret := owners.UnsortedList()
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// serverOwner returns the Ingress owning the server with the given port name, which
+// has the "<namespace>/<ingress_name>:<number>" format.
+func serverOwner(portName string) (types.NamespacedName, bool) {
+	prefix, _, ok := strings.Cut(portName, ":")
+	if !ok {
+		return types.NamespacedName{}, false
+	}
+	namespace, name, ok := strings.Cut(prefix, "/")
+	if !ok || namespace == "" || name == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true
+}
+
+func serversByPortName(servers []*istiov1beta1.Server) map[string]*istiov1beta1.Server {
+	ret := make(map[string]*istiov1beta1.Server, len(servers))
+	for _, server := range servers {
+		ret[server.Port.GetName()] = server
+	}
+	return ret
+}
+
 // UpdateGateway replaces the existing servers with the wanted servers.
 func UpdateGateway(gateway *v1beta1.Gateway, want []*istiov1beta1.Server, existing []*istiov1beta1.Server) *v1beta1.Gateway {
 	existingServers := sets.New[string]()
@@ -467,6 +540,22 @@ func UpdateGateway(gateway *v1beta1.Gateway, want []*istiov1beta1.Server, existi
 	return gateway
 }
 
+// MakeGatewayServersPatch makes the JSON patch setting the servers of the gateway to
+// those of the updated gateway. Unlike an update, the patch preserves the fields of the
+// gateway the controller does not know about, and it only applies to the revision of
+// the gateway it was made for, so concurrent edits of the servers are not lost.
+func MakeGatewayServersPatch(gateway, updated *v1beta1.Gateway) ([]byte, error) {
+	return json.Marshal([]map[string]interface{}{{
+		"op":    "test",
+		"path":  "/metadata/resourceVersion",
+		"value": gateway.ResourceVersion,
+	}, {
+		"op":    "add",
+		"path":  "/spec/servers",
+		"value": updated.Spec.Servers,
+	}})
+}
+
 func isPlaceHolderServer(server *istiov1beta1.Server) bool {
 	return cmp.Equal(server, &placeholderServer, protocmp.Transform())
 }
@@ -498,7 +587,7 @@ func GatewaysFromContext(ctx context.Context, obj kmeta.Accessor) (map[v1alpha1.
 	istioConfig := config.FromContext(ctx).Istio
 
 	// External gateways selection
-	externalGateways, err := filterGateway(istioConfig.IngressGateways, obj.GetLabels())
+	externalGateways, _, err := filterGateway(istioConfig.IngressGateways, obj)
 	if err != nil {
 		return ret, fmt.Errorf("failed to filter external gateways: %w", err)
 	}
@@ -510,7 +599,7 @@ func GatewaysFromContext(ctx context.Context, obj kmeta.Accessor) (map[v1alpha1.
 	ret[v1alpha1.IngressVisibilityExternalIP] = externalGateways
 
 	// Local gateways selection
-	localGateways, err := filterGateway(istioConfig.LocalGateways, obj.GetLabels())
+	localGateways, _, err := filterGateway(istioConfig.LocalGateways, obj)
 	if err != nil {
 		return ret, fmt.Errorf("failed to filter local gateways: %w", err)
 	}
@@ -524,8 +613,29 @@ func GatewaysFromContext(ctx context.Context, obj kmeta.Accessor) (map[v1alpha1.
 	return ret, nil
 }
 
-func filterGateway(gtws []config.Gateway, ingressLabels map[string]string) ([]config.Gateway, error) {
+// DeniedGateways returns the qualified names of the gateways whose label selectors match
+// the Ingress, but which are dedicated to the Ingresses of other namespaces.
+func DeniedGateways(ctx context.Context, obj kmeta.Accessor) ([]string, error) {
+	istioConfig := config.FromContext(ctx).Istio
+
+	ret := sets.New[string]()
+	for _, gtws := range [][]config.Gateway{istioConfig.IngressGateways, istioConfig.LocalGateways} {
+		_, denied, err := filterGateway(gtws, obj)
+		if err != nil {
+			return nil, err
+		}
+		for _, gtw := range denied {
+			ret.Insert(gtw.QualifiedName())
+		}
+	}
+	return sets.List(ret), nil
+}
+
+// filterGateway returns the gateways whose label selectors match the labels of obj,
+// split into the ones obj is allowed to use and the ones dedicated to other namespaces.
+func filterGateway(gtws []config.Gateway, obj kmeta.Accessor) ([]config.Gateway, []config.Gateway, error) {
 	ret := make([]config.Gateway, 0, 1)
+	var denied []config.Gateway
 
 	for _, gtw := range gtws {
 		if gtw.LabelSelector == nil { // default value
@@ -534,15 +644,20 @@ func filterGateway(gtws []config.Gateway, ingressLabels map[string]string) ([]co
 
 		selector, err := metav1.LabelSelectorAsSelector(gtw.LabelSelector)
 		if err != nil {
-			return ret, fmt.Errorf("failed to create selector from gateway (%s) label selector: %w", gtw.QualifiedName(), err)
+			return ret, denied, fmt.Errorf("failed to create selector from gateway (%s) label selector: %w", gtw.QualifiedName(), err)
 		}
 
-		if !selector.Matches(fields.Set(ingressLabels)) {
+		if !selector.Matches(fields.Set(obj.GetLabels())) {
+			continue
+		}
+
+		if !gtw.AllowsNamespace(obj.GetNamespace()) {
+			denied = append(denied, gtw)
 			continue
 		}
 
 		ret = append(ret, gtw)
 	}
 
-	return ret, nil
+	return ret, denied, nil
 }
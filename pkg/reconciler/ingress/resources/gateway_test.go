@@ -18,30 +18,34 @@ package resources
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"hash/adler32"
+	"slices"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/protobuf/testing/protocmp"
 	"knative.dev/pkg/tracker"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
-	corev1listers "k8s.io/client-go/listers/core/v1"
 
 	istiov1beta1 "istio.io/api/networking/v1beta1"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"knative.dev/net-istio/pkg/reconciler/fips"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	netconfig "knative.dev/networking/pkg/config"
-	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
-	fakeserviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service/fake"
 	"knative.dev/pkg/kmeta"
-	rtesting "knative.dev/pkg/reconciler/testing"
 	"knative.dev/pkg/system"
 )
 
@@ -264,7 +268,7 @@ func TestMakeTLSServers(t *testing.T) {
 				Mode:               istiov1beta1.ServerTLSSettings_SIMPLE,
 				ServerCertificate:  corev1.TLSCertKey,
 				PrivateKey:         corev1.TLSPrivateKeyKey,
-				CredentialName:     targetSecret(&secret, &ingressResource),
+				CredentialName:     names.MirroredSecret(&secret, &ingressResource),
 				MinProtocolVersion: istiov1beta1.ServerTLSSettings_TLSV1_2,
 			},
 		}},
@@ -594,6 +598,49 @@ func TestUpdateGateway(t *testing.T) {
 	}
 }
 
+func TestMakeGatewayServersPatch(t *testing.T) {
+	original := gateway.DeepCopy()
+	original.ResourceVersion = "42"
+	updated := UpdateGateway(original.DeepCopy(), []*istiov1beta1.Server{{
+		Hosts: []string{"host-new.example.com"},
+		Port: &istiov1beta1.Port{
+			Name:     "test-ns/ingress:0",
+			Number:   ExternalGatewayHTTPSPort,
+			Protocol: "HTTPS",
+		},
+	}}, GetServers(original, &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "ingress"}}))
+
+	patch, err := MakeGatewayServersPatch(original, updated)
+	if err != nil {
+		t.Fatal("MakeGatewayServersPatch() =", err)
+	}
+	var ops []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", patch, err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("Patch %s has %d operations, want 2", patch, len(ops))
+	}
+
+	if ops[0].Op != "test" || ops[0].Path != "/metadata/resourceVersion" || string(ops[0].Value) != `"42"` {
+		t.Errorf("First operation = %s %s %s, want a test of the resource version", ops[0].Op, ops[0].Path, ops[0].Value)
+	}
+	if ops[1].Op != "add" || ops[1].Path != "/spec/servers" {
+		t.Errorf("Second operation = %s %s, want an add of the servers", ops[1].Op, ops[1].Path)
+	}
+	var servers []*istiov1beta1.Server
+	if err := json.Unmarshal(ops[1].Value, &servers); err != nil {
+		t.Fatalf("Unmarshal(%s) = %v", ops[1].Value, err)
+	}
+	if diff := cmp.Diff(updated.Spec.Servers, servers, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected servers (-want, +got):", diff)
+	}
+}
+
 func TestMakeWildcardGateways(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -691,10 +738,7 @@ func TestMakeWildcardGateways(t *testing.T) {
 	}}
 
 	for _, tc := range testCases {
-		ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
-		defer cancel()
-		svcLister := serviceLister(ctx, tc.gatewayService)
-		ctx = config.ToContext(context.Background(), &config.Config{
+		ctx := config.ToContext(context.Background(), &config.Config{
 			Istio: &config.Istio{
 				IngressGateways: []config.Gateway{{
 					Name:       config.KnativeIngressGateway,
@@ -705,8 +749,9 @@ func TestMakeWildcardGateways(t *testing.T) {
 				HTTPProtocol: netconfig.HTTPEnabled,
 			},
 		})
+		svcs := gatewayServices(ctx, t, &ingressResource, tc.gatewayService)
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := MakeWildcardTLSGateways(ctx, &ingressResource, tc.wildcardSecrets, svcLister)
+			got, err := MakeWildcardTLSGateways(tc.wildcardSecrets, svcs)
 			if (err != nil) != tc.wantErr {
 				t.Fatalf("Test: %s; MakeWildcardGateways error = %v, WantErr %v", tc.name, err, tc.wantErr)
 			}
@@ -826,7 +871,6 @@ func TestMakeExternalIngressGateways(t *testing.T) {
 		conf    *config.Config
 		servers []*istiov1beta1.Server
 		want    []*v1beta1.Gateway
-		wantErr bool
 	}{{
 		name:    "HTTP server",
 		ia:      &ingressResource,
@@ -863,17 +907,11 @@ func TestMakeExternalIngressGateways(t *testing.T) {
 		want:    []*v1beta1.Gateway{createGateway("istio-system/istio-ingressgateway", selector, &httpServer)},
 	}}
 	for _, c := range cases {
-		ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
-		defer cancel()
-
-		svcLister := serviceLister(ctx, &defaultGatewayService, &gateway1Service, &gateway2Service)
-		ctx = config.ToContext(context.Background(), c.conf)
+		ctx := config.ToContext(context.Background(), c.conf)
+		svcs := gatewayServices(ctx, t, c.ia, &defaultGatewayService, &gateway1Service, &gateway2Service)
 
 		t.Run(c.name, func(t *testing.T) {
-			got, err := MakeExternalIngressGateways(ctx, c.ia, c.servers, svcLister)
-			if (err != nil) != c.wantErr {
-				t.Fatalf("Test: %s; MakeExternalIngressGateways error = %v, WantErr %v", c.name, err, c.wantErr)
-			}
+			got := MakeExternalIngressGateways(c.ia, c.servers, svcs)
 			if diff := cmp.Diff(c.want, got, defaultGatewayCmpOpts); diff != "" {
 				t.Error("Unexpected Gateways (-want, +got):", diff)
 			}
@@ -926,7 +964,7 @@ func TestMakeIngressTLSGateways(t *testing.T) {
 						Mode:               istiov1beta1.ServerTLSSettings_SIMPLE,
 						ServerCertificate:  corev1.TLSCertKey,
 						PrivateKey:         corev1.TLSPrivateKeyKey,
-						CredentialName:     targetSecret(&secret, &ingressResource),
+						CredentialName:     names.MirroredSecret(&secret, &ingressResource),
 						MinProtocolVersion: istiov1beta1.ServerTLSSettings_TLSV1_2,
 					},
 				}},
@@ -1015,7 +1053,7 @@ func TestMakeIngressTLSGateways(t *testing.T) {
 						Mode:               istiov1beta1.ServerTLSSettings_SIMPLE,
 						ServerCertificate:  corev1.TLSCertKey,
 						PrivateKey:         corev1.TLSPrivateKeyKey,
-						CredentialName:     targetSecret(&secret, &ingressResource),
+						CredentialName:     names.MirroredSecret(&secret, &ingressResource),
 						MinProtocolVersion: istiov1beta1.ServerTLSSettings_TLSV1_2,
 					},
 				}},
@@ -1058,7 +1096,7 @@ func TestMakeIngressTLSGateways(t *testing.T) {
 						Mode:               istiov1beta1.ServerTLSSettings_SIMPLE,
 						ServerCertificate:  corev1.TLSCertKey,
 						PrivateKey:         corev1.TLSPrivateKeyKey,
-						CredentialName:     targetSecret(&secret, &ingressResourceWithDotName),
+						CredentialName:     names.MirroredSecret(&secret, &ingressResourceWithDotName),
 						MinProtocolVersion: istiov1beta1.ServerTLSSettings_TLSV1_2,
 					},
 				}},
@@ -1082,10 +1120,7 @@ func TestMakeIngressTLSGateways(t *testing.T) {
 	}}
 
 	for _, c := range cases {
-		ctx, cancel, _ := rtesting.SetupFakeContextWithCancel(t)
-		defer cancel()
-		svcLister := serviceLister(ctx, c.gatewayService)
-		ctx = config.ToContext(context.Background(), &config.Config{
+		ctx := config.ToContext(context.Background(), &config.Config{
 			Istio: &config.Istio{
 				IngressGateways: []config.Gateway{{
 					Name:       config.KnativeIngressGateway,
@@ -1096,8 +1131,9 @@ func TestMakeIngressTLSGateways(t *testing.T) {
 				HTTPProtocol: netconfig.HTTPEnabled,
 			},
 		})
+		svcs := gatewayServices(ctx, t, c.ia, c.gatewayService)
 		t.Run(c.name, func(t *testing.T) {
-			got, err := MakeIngressTLSGateways(ctx, c.ia, c.visibility, c.ia.GetIngressTLSForVisibility(c.visibility), c.originSecrets, svcLister)
+			got, err := MakeIngressTLSGateways(c.ia, c.visibility, c.ia.GetIngressTLSForVisibility(c.visibility), c.originSecrets, svcs)
 			if (err != nil) != c.wantErr {
 				t.Fatalf("Test: %s; MakeIngressTLSGateways error = %v, WantErr %v", c.name, err, c.wantErr)
 			}
@@ -1108,16 +1144,114 @@ func TestMakeIngressTLSGateways(t *testing.T) {
 	}
 }
 
-func serviceLister(ctx context.Context, svcs ...*corev1.Service) corev1listers.ServiceLister {
-	fake := fakekubeclient.Get(ctx)
-	informer := fakeserviceinformer.Get(ctx)
+// gatewayServices returns the services of the ingress gateways the Ingress is exposed on
+// according to the configuration of the context, among the given services.
+func gatewayServices(ctx context.Context, t *testing.T, ing *v1alpha1.Ingress, svcs ...*corev1.Service) []*corev1.Service {
+	t.Helper()
+	metas, err := GetIngressGatewaySvcNameNamespaces(ctx, ing)
+	if err != nil {
+		t.Fatal("GetIngressGatewaySvcNameNamespaces() =", err)
+	}
+	result := make([]*corev1.Service, 0, len(metas))
+	for _, meta := range metas {
+		i := slices.IndexFunc(svcs, func(svc *corev1.Service) bool {
+			return svc.Namespace == meta.Namespace && svc.Name == meta.Name
+		})
+		if i < 0 {
+			t.Fatalf("Missing the gateway service %s/%s", meta.Namespace, meta.Name)
+		}
+		result = append(result, svcs[i])
+	}
+	return result
+}
 
-	for _, svc := range svcs {
-		fake.CoreV1().Services(svc.Namespace).Create(ctx, svc, metav1.CreateOptions{})
-		informer.Informer().GetIndexer().Add(svc)
+func TestDescribeServerChanges(t *testing.T) {
+	server := func(name string, hosts ...string) *istiov1beta1.Server {
+		return &istiov1beta1.Server{
+			Hosts: hosts,
+			Port: &istiov1beta1.Port{
+				Name:     name,
+				Number:   ExternalGatewayHTTPSPort,
+				Protocol: "HTTPS",
+			},
+		}
 	}
 
-	return informer.Lister()
+	tests := []struct {
+		name          string
+		before, after []*istiov1beta1.Server
+		want          string
+	}{{
+		name:   "unchanged",
+		before: []*istiov1beta1.Server{server("ns/ing:0", "a.example.com")},
+		after:  []*istiov1beta1.Server{server("ns/ing:0", "a.example.com")},
+	}, {
+		name:  "added",
+		after: []*istiov1beta1.Server{server("ns/ing:1", "b.example.com"), server("ns/ing:0", "a.example.com", "c.example.com")},
+		want:  "added servers ns/ing:0 (a.example.com, c.example.com), ns/ing:1 (b.example.com)",
+	}, {
+		name:   "removed",
+		before: []*istiov1beta1.Server{server("ns/ing:0", "a.example.com"), &placeholderServer},
+		after:  []*istiov1beta1.Server{server("ns/ing:0", "a.example.com")},
+		want:   "removed servers place-holder (place-holder.place-holder)",
+	}, {
+		name:   "all at once",
+		before: []*istiov1beta1.Server{server("ns/ing:0", "a.example.com"), server("ns/other:0", "o.example.com")},
+		after:  []*istiov1beta1.Server{server("ns/ing:0", "b.example.com"), server("ns/new:0", "n.example.com")},
+		want:   "added servers ns/new:0 (n.example.com); removed servers ns/other:0 (o.example.com); changed servers ns/ing:0 (b.example.com)",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeServerChanges(tt.before, tt.after); got != tt.want {
+				t.Errorf("DescribeServerChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedServerOwners(t *testing.T) {
+	server := func(name string, hosts ...string) *istiov1beta1.Server {
+		return &istiov1beta1.Server{
+			Hosts: hosts,
+			Port: &istiov1beta1.Port{
+				Name:     name,
+				Number:   ExternalGatewayHTTPSPort,
+				Protocol: "HTTPS",
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		before, after []*istiov1beta1.Server
+		want          []types.NamespacedName
+	}{{
+		name:   "unchanged",
+		before: []*istiov1beta1.Server{server("ns/ing:0", "a.example.com")},
+		after:  []*istiov1beta1.Server{server("ns/ing:0", "a.example.com")},
+	}, {
+		name:   "edited out of band",
+		before: []*istiov1beta1.Server{server("ns/ing:0", "a.example.com"), server("ns/other:0", "o.example.com")},
+		after:  []*istiov1beta1.Server{server("ns/ing:0", "evil.example.com"), server("ns/other:0", "o.example.com")},
+		want:   []types.NamespacedName{{Namespace: "ns", Name: "ing"}},
+	}, {
+		name:   "added and removed",
+		before: []*istiov1beta1.Server{server("ns/ing:0", "a.example.com"), server("ns/ing:1", "b.example.com")},
+		after:  []*istiov1beta1.Server{server("ns/new:0", "n.example.com")},
+		want:   []types.NamespacedName{{Namespace: "ns", Name: "ing"}, {Namespace: "ns", Name: "new"}},
+	}, {
+		name:   "servers not owned by an Ingress",
+		before: []*istiov1beta1.Server{&placeholderServer},
+		after:  []*istiov1beta1.Server{server(httpServerPortName, "*")},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChangedServerOwners(tt.before, tt.after)
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Error("ChangedServerOwners() (-want, +got):", diff)
+			}
+		})
+	}
 }
 
 func TestGatewayName(t *testing.T) {
@@ -1147,6 +1281,66 @@ func TestGatewayName(t *testing.T) {
 	}
 }
 
+func TestGatewayNameFIPS(t *testing.T) {
+	t.Setenv(fips.EnableFIPSModeEnv, "true")
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "istio-system",
+		},
+	}
+	ingress := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "default",
+		},
+	}
+
+	sum := sha256.Sum256([]byte("istio-system/gateway"))
+	want := fmt.Sprintf("ingress-%d", binary.BigEndian.Uint32(sum[:4]))
+	got := GatewayName(ingress, v1alpha1.IngressVisibilityExternalIP, svc)
+	if got != want {
+		t.Errorf("Unexpected external gateway name. want %q, got %q", want, got)
+	}
+
+	wantLegacy := sets.New(
+		fmt.Sprintf("ingress-%d", adler32.Checksum([]byte("istio-system/gateway"))),
+		fmt.Sprintf("ingress-%d", adler32.Checksum([]byte("istio-system/gateway-local"))),
+	)
+	if gotLegacy := LegacyGatewayNames(ingress, []*corev1.Service{svc}); !gotLegacy.Equal(wantLegacy) {
+		t.Errorf("LegacyGatewayNames() = %v, want %v", sets.List(gotLegacy), sets.List(wantLegacy))
+	}
+}
+
+func TestLegacyWildcardGatewayNamesFIPS(t *testing.T) {
+	t.Setenv(fips.EnableFIPSModeEnv, "true")
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "istio-system",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wildcard",
+			Namespace: "istio-system",
+		},
+	}
+
+	sum := sha256.Sum256([]byte("wildcard-istio-system-gateway"))
+	name := fmt.Sprintf("wildcard-%x", binary.BigEndian.Uint32(sum[:4]))
+	if got := WildcardGatewayName(secret.Name, svc.Namespace, svc.Name); got != name {
+		t.Errorf("WildcardGatewayName() = %q, want %q", got, name)
+	}
+	want := map[string]string{
+		name: fmt.Sprintf("wildcard-%x", adler32.Checksum([]byte("wildcard-istio-system-gateway"))),
+	}
+	got := LegacyWildcardGatewayNames(map[string]*corev1.Secret{"istio-system/wildcard": secret}, []*corev1.Service{svc})
+	if !cmp.Equal(got, want) {
+		t.Error("LegacyWildcardGatewayNames() (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
 func TestGatewayNameLongIngressName(t *testing.T) {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1217,6 +1411,59 @@ func TestParseIngressGatewayConfig(t *testing.T) {
 	}
 }
 
+func TestDeniedGateways(t *testing.T) {
+	cfg := &config.Istio{
+		IngressGateways: []config.Gateway{
+			{Namespace: "ns1", Name: "gtw1"},
+			{Namespace: "tenant-a", Name: "dedicated", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}, Namespaces: []string{"tenant-a"}},
+			{Namespace: "ns1", Name: "shared", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}},
+		},
+		LocalGateways: []config.Gateway{
+			{Namespace: "ns1", Name: "gtw2"},
+			{Namespace: "tenant-a", Name: "dedicated-local", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}, Namespaces: []string{"tenant-a", "tenant-c"}},
+		},
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{Istio: cfg})
+
+	cases := []struct {
+		name    string
+		ingress *v1alpha1.Ingress
+		want    []string
+	}{{
+		name: "allowed namespace",
+		ingress: &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Labels: map[string]string{
+			"tenant": "a",
+		}}},
+	}, {
+		name: "other namespace",
+		ingress: &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-b", Labels: map[string]string{
+			"tenant": "a",
+		}}},
+		want: []string{"tenant-a/dedicated", "tenant-a/dedicated-local"},
+	}, {
+		name: "partially allowed namespace",
+		ingress: &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-c", Labels: map[string]string{
+			"tenant": "a",
+		}}},
+		want: []string{"tenant-a/dedicated"},
+	}, {
+		name:    "not selected",
+		ingress: &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-b"}},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := DeniedGateways(ctx, c.ingress)
+			if err != nil {
+				t.Fatal("DeniedGateways() =", err)
+			}
+			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Error("Unexpected denied gateways (-want, +got):", diff)
+			}
+		})
+	}
+}
+
 func TestQualifiedGatewayNamesFromContext(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -1302,6 +1549,44 @@ func TestQualifiedGatewayNamesFromContext(t *testing.T) {
 				v1alpha1.IngressVisibilityClusterLocal: sets.New[string]("ns1/gtw2"),
 			},
 		},
+		{
+			name: "Dedicated to another namespace",
+			cfg: &config.Istio{
+				IngressGateways: []config.Gateway{
+					{Namespace: "ns1", Name: "gtw1"},
+					{Namespace: "tenant-a", Name: "dedicated", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}, Namespaces: []string{"tenant-a"}},
+				},
+				LocalGateways: []config.Gateway{
+					{Namespace: "ns1", Name: "gtw2"},
+				},
+			},
+			ingress: &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-b", Labels: map[string]string{
+				"tenant": "a",
+			}}},
+			want: map[v1alpha1.IngressVisibility]sets.Set[string]{
+				v1alpha1.IngressVisibilityExternalIP:   sets.New[string]("ns1/gtw1"),
+				v1alpha1.IngressVisibilityClusterLocal: sets.New[string]("ns1/gtw2"),
+			},
+		},
+		{
+			name: "Dedicated to the namespace",
+			cfg: &config.Istio{
+				IngressGateways: []config.Gateway{
+					{Namespace: "ns1", Name: "gtw1"},
+					{Namespace: "tenant-a", Name: "dedicated", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}}, Namespaces: []string{"tenant-a"}},
+				},
+				LocalGateways: []config.Gateway{
+					{Namespace: "ns1", Name: "gtw2"},
+				},
+			},
+			ingress: &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Labels: map[string]string{
+				"tenant": "a",
+			}}},
+			want: map[v1alpha1.IngressVisibility]sets.Set[string]{
+				v1alpha1.IngressVisibilityExternalIP:   sets.New[string]("tenant-a/dedicated"),
+				v1alpha1.IngressVisibilityClusterLocal: sets.New[string]("ns1/gtw2"),
+			},
+		},
 		{
 			name: "No annotation",
 			cfg: &config.Istio{
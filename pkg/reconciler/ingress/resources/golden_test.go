@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/system"
+	"sigs.k8s.io/yaml"
+)
+
+// update rewrites the golden files with the resources currently rendered, e.g.
+//
+//	go test ./pkg/reconciler/ingress/resources -run TestGolden -update
+var update = flag.Bool("update", false, "update the golden files of TestGolden")
+
+const (
+	goldenDir = "testdata/golden"
+
+	// goldenGatewayNamespace is the namespace of the gateway service the Ingresses are
+	// rendered for. The certificate Secrets of other namespaces are copied there.
+	goldenGatewayNamespace = "istio-system"
+)
+
+// TestGolden renders the resources of each Ingress of testdata/golden/<case>.yaml and compares
+// them with testdata/golden/<case>.golden.yaml, so that the changes to the generated
+// VirtualServices and Gateway servers show up in the diffs of the golden files.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join(goldenDir, "*.yaml"))
+	if err != nil {
+		t.Fatal("Failed to list the golden inputs:", err)
+	}
+	for _, input := range inputs {
+		if strings.HasSuffix(input, ".golden.yaml") {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(input), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			b, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal("Failed to read the input:", err)
+			}
+			ing := &v1alpha1.Ingress{}
+			if err := yaml.UnmarshalStrict(b, ing); err != nil {
+				t.Fatal("Failed to parse the input:", err)
+			}
+
+			got, err := renderGolden(ing)
+			if err != nil {
+				t.Fatal("Failed to render the resources:", err)
+			}
+
+			golden := filepath.Join(goldenDir, name+".golden.yaml")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal("Failed to write the golden file:", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal("Failed to read the golden file, run with -update to create it:", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("Rendered resources differ from %s, run with -update if expected (-want, +got):\n%s", golden, diff)
+			}
+		})
+	}
+}
+
+// renderGolden renders the VirtualServices of the Ingress and the servers it adds to the
+// shared ingress and local gateways, as a multi-document YAML.
+func renderGolden(ing *v1alpha1.Ingress) ([]byte, error) {
+	gateways := map[v1alpha1.IngressVisibility]sets.Set[string]{
+		v1alpha1.IngressVisibilityExternalIP:   sets.New(system.Namespace() + "/knative-ingress-gateway"),
+		v1alpha1.IngressVisibilityClusterLocal: sets.New(system.Namespace() + "/knative-local-gateway"),
+	}
+
+	var objs []runtime.Object
+	for _, gw := range []struct {
+		name       string
+		visibility v1alpha1.IngressVisibility
+	}{
+		{"knative-ingress-gateway", v1alpha1.IngressVisibilityExternalIP},
+		{"knative-local-gateway", v1alpha1.IngressVisibilityClusterLocal},
+	} {
+		servers, err := goldenServers(ing, gw.visibility)
+		if err != nil {
+			return nil, err
+		}
+		if len(servers) == 0 {
+			continue
+		}
+		objs = append(objs, &v1beta1.Gateway{
+			TypeMeta: metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: "Gateway"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      gw.name,
+			},
+			Spec: istiov1beta1.Gateway{Servers: servers},
+		})
+	}
+
+	vss, err := MakeVirtualServices(ing, gateways)
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range vss {
+		vs.TypeMeta = metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: "VirtualService"}
+		objs = append(objs, vs)
+	}
+
+	var buf bytes.Buffer
+	for i, obj := range objs {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// goldenServers returns the servers the Ingress adds to the shared gateway of the visibility,
+// assuming all the certificate Secrets it references exist.
+func goldenServers(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility) ([]*istiov1beta1.Server, error) {
+	ingressTLS := ing.GetIngressTLSForVisibility(visibility)
+	servers, err := MakeTLSServers(ing, visibility, ingressTLS, goldenGatewayNamespace, originSecretsOf(ingressTLS))
+	if err != nil {
+		return nil, err
+	}
+
+	if visibility == v1alpha1.IngressVisibilityExternalIP {
+		hosts := sets.New[string]()
+		for _, rule := range getPublicIngressRules(ing) {
+			hosts.Insert(rule.Hosts...)
+		}
+		if hosts.Len() > 0 {
+			if server := MakeHTTPServer(ing.Spec.HTTPOption, sets.List(hosts)); server != nil {
+				servers = append(servers, server)
+			}
+		}
+	}
+	return servers, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+)
+
+const (
+	// grpcContentTypePrefix is the prefix of the content types of the gRPC requests, e.g.
+	// application/grpc+proto.
+	grpcContentTypePrefix = "application/grpc"
+
+	// grpcTimeoutHeaderName is the header bounding the deadline of a gRPC request.
+	grpcTimeoutHeaderName = "grpc-timeout"
+)
+
+// WithGRPCRetries adds a route for the gRPC requests before every HTTP route of the
+// VirtualService whose retries time out each try. The added routes retry the requests
+// without the timeout, which would otherwise cut the streams of the gRPC requests, and
+// the trailers carrying their status, short.
+func WithGRPCRetries(vs *v1beta1.VirtualService) {
+	routes := make([]*istiov1beta1.HTTPRoute, 0, len(vs.Spec.Http))
+	for _, route := range vs.Spec.Http {
+		if grpcRoute := makeGRPCRoute(route); grpcRoute != nil {
+			routes = append(routes, grpcRoute)
+		}
+		routes = append(routes, route)
+	}
+	if len(routes) == len(vs.Spec.Http) {
+		return
+	}
+	vs.Spec.Http = routes
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
+
+// makeGRPCRoute returns the route of the gRPC requests of the given route, or nil when
+// the route does not time out the tries of its retries or already matches a content type.
+func makeGRPCRoute(route *istiov1beta1.HTTPRoute) *istiov1beta1.HTTPRoute {
+	if route.GetRetries().GetPerTryTimeout() == nil {
+		return nil
+	}
+	for _, match := range route.Match {
+		if _, ok := match.Headers["content-type"]; ok {
+			return nil
+		}
+	}
+	grpcRoute := route.DeepCopy()
+	grpcRoute.Retries.PerTryTimeout = nil
+	if len(grpcRoute.Match) == 0 {
+		grpcRoute.Match = []*istiov1beta1.HTTPMatchRequest{{}}
+	}
+	for _, match := range grpcRoute.Match {
+		if match.Headers == nil {
+			match.Headers = make(map[string]*istiov1beta1.StringMatch, 1)
+		}
+		match.Headers["content-type"] = &istiov1beta1.StringMatch{
+			MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: grpcContentTypePrefix},
+		}
+	}
+	return grpcRoute
+}
+
+// WithoutGRPCTimeoutHeader removes the grpc-timeout header from the requests of the HTTP
+// routes of the gateway VirtualService, unless the Ingress sets it.
+func WithoutGRPCTimeoutHeader(vs *v1beta1.VirtualService) {
+	for _, route := range vs.Spec.Http {
+		route.Headers = withRequestHeaderOperations(route.Headers, RequestHeaderOperations{
+			Remove: []string{grpcTimeoutHeaderName},
+		})
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+)
+
+func TestWithGRPCRetries(t *testing.T) {
+	match := func(headers map[string]*istiov1beta1.StringMatch) *istiov1beta1.HTTPMatchRequest {
+		return &istiov1beta1.HTTPMatchRequest{
+			Gateways: []string{"knative-serving/knative-ingress-gateway"},
+			Uri:      &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: "/"}},
+			Headers:  headers,
+		}
+	}
+	grpc := map[string]*istiov1beta1.StringMatch{
+		"content-type": {MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: "application/grpc"}},
+	}
+	timedOut := &istiov1beta1.HTTPRetry{Attempts: 3, PerTryTimeout: durationpb.New(2 * time.Second)}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Match:   []*istiov1beta1.HTTPMatchRequest{match(nil)},
+				Retries: timedOut,
+			}, {
+				// The route already matching a content type is left alone.
+				Match:   []*istiov1beta1.HTTPMatchRequest{match(map[string]*istiov1beta1.StringMatch{"content-type": {}})},
+				Retries: timedOut,
+			}, {
+				Match:   []*istiov1beta1.HTTPMatchRequest{match(nil)},
+				Retries: &istiov1beta1.HTTPRetry{Attempts: 3},
+			}},
+		},
+	}
+	want := []*istiov1beta1.HTTPRoute{{
+		Match:   []*istiov1beta1.HTTPMatchRequest{match(grpc)},
+		Retries: &istiov1beta1.HTTPRetry{Attempts: 3},
+	}}
+	want = append(want, vs.Spec.Http...)
+
+	WithGRPCRetries(vs)
+
+	if diff := cmp.Diff(want, vs.Spec.Http, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+	if vs.Spec.Http[1].Retries.PerTryTimeout == nil {
+		t.Error("The per-try timeout of the original route was removed")
+	}
+}
+
+func TestWithoutGRPCTimeoutHeader(t *testing.T) {
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{}, {
+				Headers: &istiov1beta1.Headers{
+					Request: &istiov1beta1.Headers_HeaderOperations{Set: map[string]string{"grpc-timeout": "10S"}},
+				},
+			}},
+		},
+	}
+
+	WithoutGRPCTimeoutHeader(vs)
+
+	want := []*istiov1beta1.HTTPRoute{{
+		Headers: &istiov1beta1.Headers{
+			Request: &istiov1beta1.Headers_HeaderOperations{Remove: []string{"grpc-timeout"}},
+		},
+	}, {
+		// The header set by the Ingress is kept.
+		Headers: &istiov1beta1.Headers{
+			Request: &istiov1beta1.Headers_HeaderOperations{Set: map[string]string{"grpc-timeout": "10S"}},
+		},
+	}}
+	if diff := cmp.Diff(want, vs.Spec.Http, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+}
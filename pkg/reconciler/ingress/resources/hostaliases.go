@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// HostAliasesAnnotationKey is the annotation holding the YAML list of the extra hosts of
+// the Ingress, e.g. vanity hosts which don't warrant their own DomainMapping. The aliases
+// are routed like the hosts of the first rule of the Ingress exposed on the external
+// gateways, which is the rule of the default target of a Knative Route.
+const HostAliasesAnnotationKey = "networking.knative.dev/host-aliases"
+
+// ParseHostAliases returns the host aliases of the Ingress.
+func ParseHostAliases(ing *v1alpha1.Ingress) ([]string, error) {
+	v := ing.Annotations[HostAliasesAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var aliases []string
+	if err := yaml.UnmarshalStrict([]byte(v), &aliases); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", HostAliasesAnnotationKey, err)
+	}
+
+	hosts := getHosts(ing)
+	for _, alias := range aliases {
+		if errs := validation.IsDNS1123Subdomain(alias); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid %s annotation: host %q: %s", HostAliasesAnnotationKey, alias, strings.Join(errs, ", "))
+		}
+		if hosts.Has(alias) {
+			return nil, fmt.Errorf("invalid %s annotation: %q is already a host of the Ingress", HostAliasesAnnotationKey, alias)
+		}
+		hosts.Insert(alias)
+	}
+	return aliases, nil
+}
+
+// WithHostAliases adds a rule routing the given aliases like the first rule of the Ingress
+// exposed on the external gateways, if any. The aliases get their own rule, as the Ingress
+// TLS apply to the rules whose hosts they all list. Like the defaults, the rule is only
+// added to the reconciled copy of the Ingress.
+func WithHostAliases(ing *v1alpha1.Ingress, aliases []string) {
+	if len(aliases) == 0 {
+		return
+	}
+	if rule := aliasedRule(ing); rule != nil {
+		alias := *rule
+		alias.Hosts = aliases
+		ing.Spec.Rules = append(ing.Spec.Rules[:len(ing.Spec.Rules):len(ing.Spec.Rules)], alias)
+	}
+}
+
+// WithCoveredHostAliases adds each of the given aliases to the hosts of the first Ingress
+// TLS of the rule the aliases are routed like whose certificate covers it, so that the
+// gateways serve the aliases over HTTPS as well. The secrets are keyed as returned by
+// GetSecrets.
+func WithCoveredHostAliases(ing *v1alpha1.Ingress, aliases []string, secrets map[string]*corev1.Secret) {
+	rule := aliasedRule(ing)
+	if len(aliases) == 0 || rule == nil {
+		return
+	}
+	covered := sets.New[string]()
+	for i, tls := range ing.Spec.TLS {
+		if !sets.New(tls.Hosts...).HasAll(rule.Hosts...) {
+			continue
+		}
+		secret, ok := secrets[secretKey(tls)]
+		if !ok {
+			continue
+		}
+		certData, err := parseCertSecret(secret)
+		if err != nil {
+			// The invalid certificates are reported by ValidateCertificateHosts.
+			continue
+		}
+		hosts := tls.Hosts
+		for _, alias := range aliases {
+			if !covered.Has(alias) && certData.VerifyHostname(alias) == nil {
+				hosts = append(hosts[:len(hosts):len(hosts)], alias)
+				covered.Insert(alias)
+			}
+		}
+		ing.Spec.TLS[i].Hosts = hosts
+	}
+}
+
+// aliasedRule returns the rule the host aliases of the Ingress are routed like.
+func aliasedRule(ing *v1alpha1.Ingress) *v1alpha1.IngressRule {
+	for i := range ing.Spec.Rules {
+		if ing.Spec.Rules[i].Visibility == v1alpha1.IngressVisibilityExternalIP {
+			return &ing.Spec.Rules[i]
+		}
+	}
+	return nil
+}
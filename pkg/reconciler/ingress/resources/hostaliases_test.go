@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func hostAliasesTestIngress(annotation string) *v1alpha1.Ingress {
+	localRule := translateTestRule("foo.ns.svc.cluster.local")
+	localRule.Visibility = v1alpha1.IngressVisibilityClusterLocal
+	return &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "ing",
+			Annotations: map[string]string{HostAliasesAnnotationKey: annotation},
+		},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{
+				localRule,
+				translateTestRule("test.example.com"),
+				translateTestRule("tag-test.example.com"),
+			},
+			TLS: []v1alpha1.IngressTLS{{
+				Hosts:           []string{"test.example.com"},
+				SecretName:      "nonwildcard",
+				SecretNamespace: "knative-serving",
+			}, {
+				Hosts:           []string{"test.example.com", "tag-test.example.com"},
+				SecretName:      "wildcard",
+				SecretNamespace: "knative-serving",
+			}},
+		},
+	}
+}
+
+func TestParseHostAliases(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       []string
+		wantErr    bool
+	}{{
+		name: "no aliases",
+	}, {
+		name:       "aliases",
+		annotation: "[vanity.example.com, vanity.example.org]",
+		want:       []string{"vanity.example.com", "vanity.example.org"},
+	}, {
+		name:       "invalid yaml",
+		annotation: "[vanity.example.com",
+		wantErr:    true,
+	}, {
+		name:       "invalid host",
+		annotation: "[Vanity_Example]",
+		wantErr:    true,
+	}, {
+		name:       "host of the ingress",
+		annotation: "[tag-test.example.com]",
+		wantErr:    true,
+	}, {
+		name:       "duplicate alias",
+		annotation: "[vanity.example.com, vanity.example.com]",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHostAliases(hostAliasesTestIngress(tt.annotation))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHostAliases() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("ParseHostAliases() (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithHostAliases(t *testing.T) {
+	ing := hostAliasesTestIngress("")
+	aliases := []string{"vanity.example.com", "vanity.example.org"}
+
+	WithHostAliases(ing, aliases)
+
+	aliasRule := translateTestRule("test.example.com")
+	aliasRule.Hosts = aliases
+	if diff := cmp.Diff(aliasRule, ing.Spec.Rules[len(ing.Spec.Rules)-1]); diff != "" {
+		t.Error("Unexpected alias rule (-want, +got):", diff)
+	}
+
+	WithCoveredHostAliases(ing, aliases, map[string]*corev1.Secret{
+		"knative-serving/nonwildcard": nonWildcardCert,
+		"knative-serving/wildcard":    wildcardCert,
+	})
+
+	// Only the wildcard certificate covers one of the aliases.
+	want := []v1alpha1.IngressTLS{{
+		Hosts:           []string{"test.example.com"},
+		SecretName:      "nonwildcard",
+		SecretNamespace: "knative-serving",
+	}, {
+		Hosts:           []string{"test.example.com", "tag-test.example.com", "vanity.example.com"},
+		SecretName:      "wildcard",
+		SecretNamespace: "knative-serving",
+	}}
+	if diff := cmp.Diff(want, ing.Spec.TLS); diff != "" {
+		t.Error("Unexpected TLS (-want, +got):", diff)
+	}
+}
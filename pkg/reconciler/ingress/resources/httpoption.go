@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// HTTPOptionsAnnotationKey is the annotation holding the YAML map of the public hosts of
+// the Ingress to the HTTPOption overriding the one of the Ingress for them, e.g. to
+// redirect one host to HTTPS while another one still serves HTTP.
+const HTTPOptionsAnnotationKey = "networking.knative.dev/http-options"
+
+// ParseHTTPOptions returns the HTTPOptions of the public hosts of the Ingress which
+// override the one of the Ingress.
+func ParseHTTPOptions(ing *v1alpha1.Ingress) (map[string]v1alpha1.HTTPOption, error) {
+	v := ing.Annotations[HTTPOptionsAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var options map[string]v1alpha1.HTTPOption
+	if err := yaml.UnmarshalStrict([]byte(v), &options); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", HTTPOptionsAnnotationKey, err)
+	}
+
+	hosts := sets.New(PublicHosts(ing)...)
+	for host, option := range options {
+		if !hosts.Has(host) {
+			return nil, fmt.Errorf("invalid %s annotation: %q is not a public host of the Ingress", HTTPOptionsAnnotationKey, host)
+		}
+		if option != v1alpha1.HTTPOptionEnabled && option != v1alpha1.HTTPOptionRedirected {
+			return nil, fmt.Errorf("invalid %s annotation: the HTTPOption of %q is %q, want %s or %s", HTTPOptionsAnnotationKey, host,
+				option, v1alpha1.HTTPOptionEnabled, v1alpha1.HTTPOptionRedirected)
+		}
+	}
+	return options, nil
+}
+
+// httpOptionHosts returns the public hosts of the Ingress by their HTTPOption. The
+// invalid overrides are ignored, Translate reports them.
+func httpOptionHosts(ing *v1alpha1.Ingress) map[v1alpha1.HTTPOption][]string {
+	overrides, _ := ParseHTTPOptions(ing)
+	ret := make(map[v1alpha1.HTTPOption][]string, 2)
+	for _, host := range PublicHosts(ing) {
+		option, ok := overrides[host]
+		if !ok {
+			option = ing.Spec.HTTPOption
+		}
+		ret[option] = append(ret[option], host)
+	}
+	return ret
+}
+
+// MakeHTTPServers creates the HTTP Gateway `Servers` of the public hosts of the Ingress,
+// one for each of their HTTPOptions. The one of the HTTPOption of the Ingress keeps the
+// name of the HTTP server, the ones of the overrides are suffixed with their option.
+func MakeHTTPServers(ing *v1alpha1.Ingress) []*istiov1beta1.Server {
+	hosts := httpOptionHosts(ing)
+	var servers []*istiov1beta1.Server
+	if len(hosts[ing.Spec.HTTPOption]) > 0 {
+		servers = append(servers, MakeHTTPServer(ing.Spec.HTTPOption, hosts[ing.Spec.HTTPOption]))
+	}
+	for _, option := range []v1alpha1.HTTPOption{v1alpha1.HTTPOptionEnabled, v1alpha1.HTTPOptionRedirected} {
+		if option == ing.Spec.HTTPOption || len(hosts[option]) == 0 {
+			continue
+		}
+		server := MakeHTTPServer(option, hosts[option])
+		server.Port.Name = httpServerPortName + "-" + strings.ToLower(string(option))
+		servers = append(servers, server)
+	}
+	return servers
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func httpOptionTestIngress(httpOption v1alpha1.HTTPOption, annotation string) *v1alpha1.Ingress {
+	return &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "ing",
+			Annotations: map[string]string{HTTPOptionsAnnotationKey: annotation},
+		},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{
+				translateTestRule("foo.example.com"),
+				translateTestRule("bar.example.com"),
+			},
+			HTTPOption: httpOption,
+		},
+	}
+}
+
+func TestParseHTTPOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       map[string]v1alpha1.HTTPOption
+		wantErr    bool
+	}{{
+		name: "no overrides",
+	}, {
+		name:       "overrides",
+		annotation: "bar.example.com: Redirected",
+		want:       map[string]v1alpha1.HTTPOption{"bar.example.com": v1alpha1.HTTPOptionRedirected},
+	}, {
+		name:       "invalid yaml",
+		annotation: "bar.example.com: [",
+		wantErr:    true,
+	}, {
+		name:       "unknown host",
+		annotation: "other.example.com: Redirected",
+		wantErr:    true,
+	}, {
+		name:       "invalid option",
+		annotation: "bar.example.com: Disabled",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHTTPOptions(httpOptionTestIngress(v1alpha1.HTTPOptionEnabled, tt.annotation))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHTTPOptions() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Error("ParseHTTPOptions() (-want, +got):", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestMakeHTTPServers(t *testing.T) {
+	enabled := func(name string, hosts ...string) *istiov1beta1.Server {
+		return &istiov1beta1.Server{
+			Hosts: hosts,
+			Port:  &istiov1beta1.Port{Name: name, Number: GatewayHTTPPort, Protocol: "HTTP"},
+		}
+	}
+	redirected := func(name string, hosts ...string) *istiov1beta1.Server {
+		server := enabled(name, hosts...)
+		server.Tls = &istiov1beta1.ServerTLSSettings{HttpsRedirect: true}
+		return server
+	}
+
+	tests := []struct {
+		name              string
+		httpOption        v1alpha1.HTTPOption
+		annotation        string
+		want              []*istiov1beta1.Server
+		wantReconcileHTTP bool
+	}{{
+		name:       "no overrides",
+		httpOption: v1alpha1.HTTPOptionEnabled,
+		want:       []*istiov1beta1.Server{enabled("http-server", "bar.example.com", "foo.example.com")},
+	}, {
+		name:              "one host redirected",
+		httpOption:        v1alpha1.HTTPOptionEnabled,
+		annotation:        "bar.example.com: Redirected",
+		want:              []*istiov1beta1.Server{enabled("http-server", "foo.example.com"), redirected("http-server-redirected", "bar.example.com")},
+		wantReconcileHTTP: true,
+	}, {
+		name:              "one host enabled",
+		httpOption:        v1alpha1.HTTPOptionRedirected,
+		annotation:        "foo.example.com: Enabled",
+		want:              []*istiov1beta1.Server{redirected("http-server", "bar.example.com"), enabled("http-server-enabled", "foo.example.com")},
+		wantReconcileHTTP: true,
+	}, {
+		name:       "all hosts enabled",
+		httpOption: v1alpha1.HTTPOptionRedirected,
+		annotation: "foo.example.com: Enabled\nbar.example.com: Enabled",
+		want:       []*istiov1beta1.Server{enabled("http-server-enabled", "bar.example.com", "foo.example.com")},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := httpOptionTestIngress(tt.httpOption, tt.annotation)
+			if got := ShouldReconcileHTTPServer(ing); got != tt.wantReconcileHTTP {
+				t.Errorf("ShouldReconcileHTTPServer() = %v, want: %v", got, tt.wantReconcileHTTP)
+			}
+			if diff := cmp.Diff(tt.want, MakeHTTPServers(ing), defaultGatewayCmpOpts); diff != "" {
+				t.Error("MakeHTTPServers() (-want, +got):", diff)
+			}
+		})
+	}
+}
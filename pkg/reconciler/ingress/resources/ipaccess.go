@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net"
+
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// AllowedSourceRangesAnnotationKey is the annotation holding the comma separated list
+	// of IPs and CIDRs allowed to reach the hosts of the Ingress through the gateways.
+	AllowedSourceRangesAnnotationKey = "networking.knative.dev/allowed-source-ranges"
+
+	// DeniedSourceRangesAnnotationKey is the annotation holding the comma separated list
+	// of IPs and CIDRs refused access to the hosts of the Ingress on the gateways.
+	DeniedSourceRangesAnnotationKey = "networking.knative.dev/denied-source-ranges"
+)
+
+// HasIPAccessAnnotations returns whether the Ingress restricts the source IPs of requests.
+func HasIPAccessAnnotations(ing *v1alpha1.Ingress) bool {
+	return ing.Annotations[AllowedSourceRangesAnnotationKey] != "" || ing.Annotations[DeniedSourceRangesAnnotationKey] != ""
+}
+
+// IPAccessAuthorizationPolicyName returns the name of the AuthorizationPolicy restricting
+// the source IPs of the requests to the Ingress on the gateway behind gatewaySvc.
+func IPAccessAuthorizationPolicyName(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service) string {
+	return kmeta.ChildName(ing.Namespace+"-"+ing.Name, "-ipaccess-"+gatewaySvc.Name)
+}
+
+// MakeIPAccessAuthorizationPolicy creates an AuthorizationPolicy denying the requests to
+// the given hosts of the Ingress which do not come from the allowed source ranges, or come
+// from the denied ones, on the gateway behind gatewaySvc.
+//
+// The ranges are matched against the original client IP, as determined by the gateway
+// from the X-Forwarded-For header and its number of trusted proxies.
+func MakeIPAccessAuthorizationPolicy(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service, hosts []string) (*v1beta1.AuthorizationPolicy, error) {
+	allowed, err := parseSourceRanges(ing, AllowedSourceRangesAnnotationKey)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := parseSourceRanges(ing, DeniedSourceRangesAnnotationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The Host header may carry the port of the gateway.
+	operationHosts := make([]string, 0, 2*len(hosts))
+	for _, host := range hosts {
+		operationHosts = append(operationHosts, host, host+":*")
+	}
+	to := []*istiov1beta1.Rule_To{{
+		Operation: &istiov1beta1.Operation{
+			Hosts: operationHosts,
+		},
+	}}
+
+	// The policy denies the requests matching any of its rules.
+	var rules []*istiov1beta1.Rule
+	if len(allowed) > 0 {
+		rules = append(rules, &istiov1beta1.Rule{
+			From: []*istiov1beta1.Rule_From{{
+				Source: &istiov1beta1.Source{
+					NotRemoteIpBlocks: allowed,
+				},
+			}},
+			To: to,
+		})
+	}
+	if len(denied) > 0 {
+		rules = append(rules, &istiov1beta1.Rule{
+			From: []*istiov1beta1.Rule_From{{
+				Source: &istiov1beta1.Source{
+					RemoteIpBlocks: denied,
+				},
+			}},
+			To: to,
+		})
+	}
+
+	meta := gatewayResourceMeta(ing, gatewaySvc)
+	meta.Name = IPAccessAuthorizationPolicyName(ing, gatewaySvc)
+	ap := &v1beta1.AuthorizationPolicy{
+		ObjectMeta: meta,
+		Spec: istiov1beta1.AuthorizationPolicy{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: gatewaySvc.Spec.Selector,
+			},
+			Action: istiov1beta1.AuthorizationPolicy_DENY,
+			Rules:  rules,
+		},
+	}
+	ap.Annotations = kaccessor.WithSpecHash(ap.Annotations, &ap.Spec)
+
+	return ap, nil
+}
+
+// parseSourceRanges returns the IPs and CIDRs listed in the given annotation of the Ingress.
+func parseSourceRanges(ing *v1alpha1.Ingress, key string) ([]string, error) {
+	ranges := splitAnnotationList(ing.Annotations[key])
+	for _, r := range ranges {
+		if net.ParseIP(r) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(r); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %q is neither an IP nor a CIDR", key, r)
+		}
+	}
+	return ranges, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeIPAccessAuthorizationPolicy(t *testing.T) {
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+	to := []*istiov1beta1.Rule_To{{
+		Operation: &istiov1beta1.Operation{
+			Hosts: []string{"foo.example.com", "foo.example.com:*"},
+		},
+	}}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []*istiov1beta1.Rule
+	}{{
+		name: "allow list",
+		annotations: map[string]string{
+			AllowedSourceRangesAnnotationKey: "10.0.0.0/8, 192.168.1.1",
+		},
+		want: []*istiov1beta1.Rule{{
+			From: []*istiov1beta1.Rule_From{{
+				Source: &istiov1beta1.Source{NotRemoteIpBlocks: []string{"10.0.0.0/8", "192.168.1.1"}},
+			}},
+			To: to,
+		}},
+	}, {
+		name: "deny list",
+		annotations: map[string]string{
+			DeniedSourceRangesAnnotationKey: "2001:db8::/32",
+		},
+		want: []*istiov1beta1.Rule{{
+			From: []*istiov1beta1.Rule_From{{
+				Source: &istiov1beta1.Source{RemoteIpBlocks: []string{"2001:db8::/32"}},
+			}},
+			To: to,
+		}},
+	}, {
+		name: "allow and deny lists",
+		annotations: map[string]string{
+			AllowedSourceRangesAnnotationKey: "10.0.0.0/8",
+			DeniedSourceRangesAnnotationKey:  "10.1.0.0/16",
+		},
+		want: []*istiov1beta1.Rule{{
+			From: []*istiov1beta1.Rule_From{{
+				Source: &istiov1beta1.Source{NotRemoteIpBlocks: []string{"10.0.0.0/8"}},
+			}},
+			To: to,
+		}, {
+			From: []*istiov1beta1.Rule_From{{
+				Source: &istiov1beta1.Source{RemoteIpBlocks: []string{"10.1.0.0/16"}},
+			}},
+			To: to,
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-ingress",
+					Namespace:   "my-namespace",
+					Annotations: test.annotations,
+				},
+			}
+
+			got, err := MakeIPAccessAuthorizationPolicy(ing, gatewaySvc, []string{"foo.example.com"})
+			if err != nil {
+				t.Fatal("MakeIPAccessAuthorizationPolicy() =", err)
+			}
+
+			if got.Name != "my-namespace-my-ingress-ipaccess-istio-ingressgateway" || got.Namespace != "istio-system" {
+				t.Errorf("AuthorizationPolicy = %s/%s, want istio-system/my-namespace-my-ingress-ipaccess-istio-ingressgateway", got.Namespace, got.Name)
+			}
+			if diff := cmp.Diff(GatewayResourceLabels(ing), got.Labels); diff != "" {
+				t.Error("Unexpected labels (-want +got):", diff)
+			}
+			want := &istiov1beta1.AuthorizationPolicy{
+				Selector: &istiotypev1beta1.WorkloadSelector{MatchLabels: gatewaySvc.Spec.Selector},
+				Action:   istiov1beta1.AuthorizationPolicy_DENY,
+				Rules:    test.want,
+			}
+			if diff := cmp.Diff(want, &got.Spec, protocmp.Transform()); diff != "" {
+				t.Error("Unexpected spec (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestMakeIPAccessAuthorizationPolicyErrors(t *testing.T) {
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+	}
+
+	for name, annotations := range map[string]map[string]string{
+		"invalid allowed range": {AllowedSourceRangesAnnotationKey: "10.0.0.0/33"},
+		"invalid denied range":  {DeniedSourceRangesAnnotationKey: "example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-ingress",
+					Namespace:   "my-namespace",
+					Annotations: annotations,
+				},
+			}
+			if _, err := MakeIPAccessAuthorizationPolicy(ing, gatewaySvc, []string{"foo.example.com"}); err == nil {
+				t.Error("MakeIPAccessAuthorizationPolicy() = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// JWTIssuerAnnotationKey is the annotation requiring requests to the Ingress to
+	// carry a valid JWT issued by the given issuer.
+	JWTIssuerAnnotationKey = "networking.knative.dev/jwt-issuer"
+
+	// JWTJwksURIAnnotationKey is the annotation overriding the URL of the JSON Web Key
+	// Set used to validate the JWTs. By default, it is discovered from the issuer.
+	JWTJwksURIAnnotationKey = "networking.knative.dev/jwt-jwks-uri"
+
+	// JWTAudiencesAnnotationKey is the annotation holding the comma separated list of
+	// audiences the JWTs must be issued for.
+	JWTAudiencesAnnotationKey = "networking.knative.dev/jwt-audiences"
+
+	// JWTPathsAnnotationKey is the annotation holding the comma separated list of
+	// paths requiring a JWT. By default, all the paths of the Ingress require one.
+	JWTPathsAnnotationKey = "networking.knative.dev/jwt-paths"
+)
+
+// HasJWTAnnotations returns whether the Ingress requires requests to carry a JWT.
+func HasJWTAnnotations(ing *v1alpha1.Ingress) bool {
+	for _, key := range []string{JWTIssuerAnnotationKey, JWTJwksURIAnnotationKey, JWTAudiencesAnnotationKey, JWTPathsAnnotationKey} {
+		if _, ok := ing.Annotations[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeRequestAuthentication creates a RequestAuthentication validating the JWTs of the
+// issuer referenced by the Ingress annotations on the gateway behind gatewaySvc.
+func MakeRequestAuthentication(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service) (*v1beta1.RequestAuthentication, error) {
+	issuer := ing.Annotations[JWTIssuerAnnotationKey]
+	if issuer == "" {
+		return nil, fmt.Errorf("the %s annotation is required to authenticate requests with JWTs", JWTIssuerAnnotationKey)
+	}
+
+	ra := &v1beta1.RequestAuthentication{
+		ObjectMeta: gatewayResourceMeta(ing, gatewaySvc),
+		Spec: istiov1beta1.RequestAuthentication{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: gatewaySvc.Spec.Selector,
+			},
+			JwtRules: []*istiov1beta1.JWTRule{{
+				Issuer:    issuer,
+				JwksUri:   ing.Annotations[JWTJwksURIAnnotationKey],
+				Audiences: splitAnnotationList(ing.Annotations[JWTAudiencesAnnotationKey]),
+			}},
+		},
+	}
+	ra.Annotations = kaccessor.WithSpecHash(ra.Annotations, &ra.Spec)
+
+	return ra, nil
+}
+
+// MakeJWTAuthorizationPolicy creates an AuthorizationPolicy denying the requests to the
+// given hosts of the Ingress which do not carry a valid JWT of the referenced issuer, on
+// the gateway behind gatewaySvc.
+func MakeJWTAuthorizationPolicy(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service, hosts []string) (*v1beta1.AuthorizationPolicy, error) {
+	issuer := ing.Annotations[JWTIssuerAnnotationKey]
+	if issuer == "" {
+		return nil, fmt.Errorf("the %s annotation is required to authenticate requests with JWTs", JWTIssuerAnnotationKey)
+	}
+
+	// The Host header may carry the port of the gateway.
+	operationHosts := make([]string, 0, 2*len(hosts))
+	for _, host := range hosts {
+		operationHosts = append(operationHosts, host, host+":*")
+	}
+
+	ap := &v1beta1.AuthorizationPolicy{
+		ObjectMeta: gatewayResourceMeta(ing, gatewaySvc),
+		Spec: istiov1beta1.AuthorizationPolicy{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: gatewaySvc.Spec.Selector,
+			},
+			Action: istiov1beta1.AuthorizationPolicy_DENY,
+			Rules: []*istiov1beta1.Rule{{
+				// Request principals are "<issuer>/<subject>", so that the JWTs of
+				// the issuers of other Ingresses on the same gateway are refused.
+				From: []*istiov1beta1.Rule_From{{
+					Source: &istiov1beta1.Source{
+						NotRequestPrincipals: []string{issuer + "/*"},
+					},
+				}},
+				To: []*istiov1beta1.Rule_To{{
+					Operation: &istiov1beta1.Operation{
+						Hosts: operationHosts,
+						Paths: splitAnnotationList(ing.Annotations[JWTPathsAnnotationKey]),
+					},
+				}},
+			}},
+		},
+	}
+	ap.Annotations = kaccessor.WithSpecHash(ap.Annotations, &ap.Spec)
+
+	return ap, nil
+}
+
+// gatewayResourceMeta returns the metadata of a resource generated for the Ingress next
+// to the gateway behind gatewaySvc.
+func gatewayResourceMeta(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      kmeta.ChildName(ing.Namespace+"-"+ing.Name, "-"+gatewaySvc.Name),
+		Namespace: gatewaySvc.Namespace,
+		Labels:    GatewayResourceLabels(ing),
+	}
+}
+
+func splitAnnotationList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+var jwtGatewaySvc = &corev1.Service{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "istio-ingressgateway",
+		Namespace: "istio-system",
+	},
+	Spec: corev1.ServiceSpec{
+		Selector: map[string]string{"istio": "ingressgateway"},
+	},
+}
+
+func jwtIngress(annotations map[string]string) *v1alpha1.Ingress {
+	return &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-ingress",
+			Namespace:   "my-namespace",
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestHasJWTAnnotations(t *testing.T) {
+	if HasJWTAnnotations(jwtIngress(nil)) {
+		t.Error("HasJWTAnnotations() = true without annotations")
+	}
+	if !HasJWTAnnotations(jwtIngress(map[string]string{JWTPathsAnnotationKey: "/api/*"})) {
+		t.Error("HasJWTAnnotations() = false with the paths annotation")
+	}
+}
+
+func TestMakeRequestAuthentication(t *testing.T) {
+	ing := jwtIngress(map[string]string{
+		JWTIssuerAnnotationKey:    "https://issuer.example.com",
+		JWTJwksURIAnnotationKey:   "https://issuer.example.com/jwks.json",
+		JWTAudiencesAnnotationKey: "foo, bar",
+	})
+
+	got, err := MakeRequestAuthentication(ing, jwtGatewaySvc)
+	if err != nil {
+		t.Fatal("MakeRequestAuthentication() =", err)
+	}
+
+	want := &v1beta1.RequestAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-namespace-my-ingress-istio-ingressgateway",
+			Namespace: "istio-system",
+			Labels:    GatewayResourceLabels(ing),
+		},
+		Spec: istiov1beta1.RequestAuthentication{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: map[string]string{"istio": "ingressgateway"},
+			},
+			JwtRules: []*istiov1beta1.JWTRule{{
+				Issuer:    "https://issuer.example.com",
+				JwksUri:   "https://issuer.example.com/jwks.json",
+				Audiences: []string{"foo", "bar"},
+			}},
+		},
+	}
+	want.Annotations = kaccessor.WithSpecHash(want.Annotations, &want.Spec)
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected RequestAuthentication (-want +got):", diff)
+	}
+
+	if _, err := MakeRequestAuthentication(jwtIngress(map[string]string{JWTAudiencesAnnotationKey: "foo"}), jwtGatewaySvc); err == nil {
+		t.Error("MakeRequestAuthentication() = nil, want error without issuer")
+	}
+}
+
+func TestMakeJWTAuthorizationPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		paths     string
+		wantPaths []string
+	}{{
+		name: "all paths",
+	}, {
+		name:      "some paths",
+		paths:     "/api/*,/admin",
+		wantPaths: []string{"/api/*", "/admin"},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := jwtIngress(map[string]string{
+				JWTIssuerAnnotationKey: "https://issuer.example.com",
+				JWTPathsAnnotationKey:  tc.paths,
+			})
+
+			got, err := MakeJWTAuthorizationPolicy(ing, jwtGatewaySvc, []string{"foo.example.com"})
+			if err != nil {
+				t.Fatal("MakeJWTAuthorizationPolicy() =", err)
+			}
+
+			want := &v1beta1.AuthorizationPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-namespace-my-ingress-istio-ingressgateway",
+					Namespace: "istio-system",
+					Labels:    GatewayResourceLabels(ing),
+				},
+				Spec: istiov1beta1.AuthorizationPolicy{
+					Selector: &istiotypev1beta1.WorkloadSelector{
+						MatchLabels: map[string]string{"istio": "ingressgateway"},
+					},
+					Action: istiov1beta1.AuthorizationPolicy_DENY,
+					Rules: []*istiov1beta1.Rule{{
+						From: []*istiov1beta1.Rule_From{{
+							Source: &istiov1beta1.Source{
+								NotRequestPrincipals: []string{"https://issuer.example.com/*"},
+							},
+						}},
+						To: []*istiov1beta1.Rule_To{{
+							Operation: &istiov1beta1.Operation{
+								Hosts: []string{"foo.example.com", "foo.example.com:*"},
+								Paths: tc.wantPaths,
+							},
+						}},
+					}},
+				},
+			}
+			want.Annotations = kaccessor.WithSpecHash(want.Annotations, &want.Spec)
+			if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+				t.Error("Unexpected AuthorizationPolicy (-want +got):", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/pkg/network"
+)
+
+// WithNamespaceScope only exports the mesh VirtualService to the sidecars of its own
+// namespace, and routes the short names of its hosts in that namespace as well, e.g.
+// hello for hello.default.svc.cluster.local in the namespace default, which are the
+// names the clients of the namespace call.
+func WithNamespaceScope(vs *v1beta1.VirtualService) {
+	hosts := sets.New(vs.Spec.Hosts...)
+	for _, host := range vs.Spec.Hosts {
+		if name, ok := shortHostName(host, vs.Namespace); ok {
+			hosts.Insert(name)
+		}
+	}
+	vs.Spec.Hosts = sets.List(hosts)
+	vs.Spec.ExportTo = []string{"."}
+
+	for _, route := range vs.Spec.Http {
+		matches := make([]*istiov1beta1.HTTPMatchRequest, 0, 2*len(route.Match))
+		for _, match := range route.Match {
+			matches = append(matches, match)
+			// The authorities are matched by the prefixes of the hosts, see hostPrefix.
+			if name, ok := shortHostName(match.GetAuthority().GetPrefix(), vs.Namespace); ok {
+				short := match.DeepCopy()
+				short.Authority = &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: name}}
+				matches = append(matches, short)
+			}
+		}
+		route.Match = matches
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
+
+// shortHostName returns the name of the Service of the given cluster-local host, or of its
+// prefix, when the Service is in the given namespace.
+func shortHostName(host, namespace string) (string, bool) {
+	host = strings.TrimSuffix(host, ".svc."+network.GetClusterDomainName())
+	host = strings.TrimSuffix(host, ".svc")
+	name, ok := strings.CutSuffix(host, "."+namespace)
+	if !ok || name == "" || strings.Contains(name, ".") {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+)
+
+func TestWithNamespaceScope(t *testing.T) {
+	authority := func(prefix string) *istiov1beta1.HTTPMatchRequest {
+		return &istiov1beta1.HTTPMatchRequest{
+			Gateways:  []string{"mesh"},
+			Authority: &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: prefix}},
+		}
+	}
+	vs := &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "hello-mesh"},
+		Spec: istiov1beta1.VirtualService{
+			Hosts: []string{"hello.default", "hello.default.svc", "hello.default.svc.cluster.local", "other.ns.svc.cluster.local"},
+			Http: []*istiov1beta1.HTTPRoute{{
+				Match: []*istiov1beta1.HTTPMatchRequest{authority("hello.default"), authority("other.ns")},
+			}},
+		},
+	}
+
+	WithNamespaceScope(vs)
+
+	want := istiov1beta1.VirtualService{
+		Hosts:    []string{"hello", "hello.default", "hello.default.svc", "hello.default.svc.cluster.local", "other.ns.svc.cluster.local"},
+		ExportTo: []string{"."},
+		Http: []*istiov1beta1.HTTPRoute{{
+			Match: []*istiov1beta1.HTTPMatchRequest{authority("hello.default"), authority("hello"), authority("other.ns")},
+		}},
+	}
+	if diff := cmp.Diff(&want, &vs.Spec, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected spec (-want +got):", diff)
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+}
+
+func TestShortHostName(t *testing.T) {
+	tests := []struct {
+		host   string
+		want   string
+		wantOK bool
+	}{{
+		host: "hello.default.svc.cluster.local", want: "hello", wantOK: true,
+	}, {
+		host: "hello.default.svc", want: "hello", wantOK: true,
+	}, {
+		host: "hello.default", want: "hello", wantOK: true,
+	}, {
+		host: "hello.other.svc.cluster.local",
+	}, {
+		host: "hello.default.example.com",
+	}, {
+		host: "a.hello.default",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, ok := shortHostName(tt.host, "default")
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("shortHostName() = %q, %v, want: %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/yaml"
+)
+
+// MirrorsAnnotationKey is the annotation holding the YAML list of the Mirrors of the
+// Ingress, e.g. to analyze canaries with copies of the production traffic.
+const MirrorsAnnotationKey = "networking.knative.dev/mirrors"
+
+// Mirror sends copies of the requests of the Ingress to a shadow Service, whose responses
+// are discarded.
+type Mirror struct {
+	// ServiceName is the name of the shadow Service, in the namespace of the Ingress.
+	ServiceName string `json:"serviceName"`
+	// Port is the port of the shadow Service, 80 when zero.
+	Port uint32 `json:"port,omitempty"`
+	// Percent is the percentage of the requests mirrored, all of them when zero.
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// ParseMirrors returns the Mirrors of the Ingress.
+func ParseMirrors(ing *v1alpha1.Ingress) ([]Mirror, error) {
+	v := ing.Annotations[MirrorsAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var mirrors []Mirror
+	if err := yaml.UnmarshalStrict([]byte(v), &mirrors); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", MirrorsAnnotationKey, err)
+	}
+
+	targets := sets.New[string]()
+	for i, mirror := range mirrors {
+		if errs := validation.IsDNS1035Label(mirror.ServiceName); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid %s annotation: mirror %d service %q: %s", MirrorsAnnotationKey, i, mirror.ServiceName, strings.Join(errs, ", "))
+		}
+		if mirror.Port > 65535 {
+			return nil, fmt.Errorf("invalid %s annotation: mirror %d port %d is not a valid port", MirrorsAnnotationKey, i, mirror.Port)
+		}
+		if mirror.Percent < 0 || mirror.Percent > 100 {
+			return nil, fmt.Errorf("invalid %s annotation: mirror %d percent must be between 0 and 100, was: %v", MirrorsAnnotationKey, i, mirror.Percent)
+		}
+		target := fmt.Sprintf("%s:%d", mirror.ServiceName, mirror.port())
+		if targets.Has(target) {
+			return nil, fmt.Errorf("invalid %s annotation: mirror %d duplicates the mirror to %s", MirrorsAnnotationKey, i, target)
+		}
+		targets.Insert(target)
+	}
+	return mirrors, nil
+}
+
+func (m Mirror) port() uint32 {
+	if m.Port == 0 {
+		return 80
+	}
+	return m.Port
+}
+
+// percentage returns the percentage of the requests mirrored, nil for all of them.
+func (m Mirror) percentage() *istiov1beta1.Percent {
+	if m.Percent == 0 || m.Percent == 100 {
+		return nil
+	}
+	return &istiov1beta1.Percent{Value: m.Percent}
+}
+
+func (m Mirror) destination(namespace string) *istiov1beta1.Destination {
+	return &istiov1beta1.Destination{
+		Host: network.GetServiceHostname(m.ServiceName, namespace),
+		Port: &istiov1beta1.PortSelector{Number: m.port()},
+	}
+}
+
+// WithMirrors mirrors the requests of the HTTP routes of the VirtualService to the given
+// Mirrors. A single Mirror is set in the mirror field, which all the versions of Istio
+// support, while several are set in the mirrors field of the newer ones.
+func WithMirrors(vs *v1beta1.VirtualService, namespace string, mirrors []Mirror) {
+	if len(mirrors) == 0 {
+		return
+	}
+
+	for _, route := range vs.Spec.Http {
+		if len(mirrors) == 1 {
+			route.Mirror = mirrors[0].destination(namespace)
+			route.MirrorPercentage = mirrors[0].percentage()
+			continue
+		}
+		route.Mirrors = make([]*istiov1beta1.HTTPMirrorPolicy, 0, len(mirrors))
+		for _, mirror := range mirrors {
+			route.Mirrors = append(route.Mirrors, &istiov1beta1.HTTPMirrorPolicy{
+				Destination: mirror.destination(namespace),
+				Percentage:  mirror.percentage(),
+			})
+		}
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
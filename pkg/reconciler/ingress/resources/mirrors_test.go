@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseMirrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       []Mirror
+		wantErr    bool
+	}{{
+		name: "no mirrors",
+	}, {
+		name: "mirrors",
+		annotation: `
+- serviceName: canary-a
+  percent: 10
+- serviceName: canary-b
+  port: 8080`,
+		want: []Mirror{{ServiceName: "canary-a", Percent: 10}, {ServiceName: "canary-b", Port: 8080}},
+	}, {
+		name:       "invalid yaml",
+		annotation: "- serviceName: [",
+		wantErr:    true,
+	}, {
+		name:       "unknown field",
+		annotation: "- service: canary-a",
+		wantErr:    true,
+	}, {
+		name:       "invalid service",
+		annotation: "- serviceName: canary.other-ns",
+		wantErr:    true,
+	}, {
+		name:       "invalid port",
+		annotation: "- {serviceName: canary-a, port: 70000}",
+		wantErr:    true,
+	}, {
+		name:       "percent out of range",
+		annotation: "- {serviceName: canary-a, percent: 120}",
+		wantErr:    true,
+	}, {
+		name:       "duplicate mirror",
+		annotation: "- {serviceName: canary-a, percent: 10}\n- {serviceName: canary-a, port: 80}",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{MirrorsAnnotationKey: tt.annotation},
+				},
+			}
+			got, err := ParseMirrors(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMirrors() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("Unexpected mirrors (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithMirrors(t *testing.T) {
+	destination := func(service string, port uint32) *istiov1beta1.Destination {
+		return &istiov1beta1.Destination{
+			Host: service + ".test-ns.svc.cluster.local",
+			Port: &istiov1beta1.PortSelector{Number: port},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mirrors []Mirror
+		want    *istiov1beta1.HTTPRoute
+	}{{
+		name: "no mirrors",
+		want: &istiov1beta1.HTTPRoute{},
+	}, {
+		name:    "single mirror",
+		mirrors: []Mirror{{ServiceName: "canary-a", Percent: 10}},
+		want: &istiov1beta1.HTTPRoute{
+			Mirror:           destination("canary-a", 80),
+			MirrorPercentage: &istiov1beta1.Percent{Value: 10},
+		},
+	}, {
+		name:    "several mirrors",
+		mirrors: []Mirror{{ServiceName: "canary-a", Percent: 10}, {ServiceName: "canary-b", Port: 8080, Percent: 100}},
+		want: &istiov1beta1.HTTPRoute{
+			Mirrors: []*istiov1beta1.HTTPMirrorPolicy{{
+				Destination: destination("canary-a", 80),
+				Percentage:  &istiov1beta1.Percent{Value: 10},
+			}, {
+				Destination: destination("canary-b", 8080),
+			}},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := &v1beta1.VirtualService{
+				Spec: istiov1beta1.VirtualService{
+					Http: []*istiov1beta1.HTTPRoute{{}},
+				},
+			}
+			WithMirrors(vs, "test-ns", tt.mirrors)
+			if diff := cmp.Diff(tt.want, vs.Spec.Http[0], protocmp.Transform()); diff != "" {
+				t.Error("Unexpected route (-want +got):", diff)
+			}
+		})
+	}
+}
@@ -17,9 +17,41 @@ limitations under the License.
 package names
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/net-istio/pkg/reconciler/fips"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
 )
 
+const (
+	// MaxLabelLength is the maximal length of the names Istio requires to be DNS-1123
+	// labels, e.g. the ones of the Gateways.
+	MaxLabelLength = 63
+
+	// MaxSubdomainLength is the maximal length of the names of the other resources,
+	// which are DNS-1123 subdomains.
+	MaxSubdomainLength = 253
+
+	dns1123LabelFmt     = "[a-zA-Z0-9](?:[-a-zA-Z0-9]*[a-zA-Z0-9])?"
+	localGatewayPostfix = "-local"
+)
+
+var dns1123LabelRegexp = regexp.MustCompile("^" + dns1123LabelFmt + "$")
+
+// IsDNS1123Label tests for a string that conforms to the definition of a label in
+// DNS (RFC 1123).
+// This function is copied from https://github.com/istio/istio/blob/806fb24bc121bf93ea06f6a38b7ccb3d78d1f326/pkg/config/labels/instance.go#L97
+// We directly copy this function instead of importing it into vendor and using it because
+// if this function is changed in the upstream (for example, Istio allows the dot in the future), we don't want to
+// import the change without awareness because it could break the compatibility of Gateway name generation.
+func IsDNS1123Label(value string) bool {
+	return len(value) <= MaxLabelLength && dns1123LabelRegexp.MatchString(value)
+}
+
 // IngressVirtualService returns the name of the VirtualService child
 // resource for given Ingress that programs traffic for Ingress
 // Gateways.
@@ -33,3 +65,86 @@ func IngressVirtualService(i kmeta.Accessor) string {
 func MeshVirtualService(i kmeta.Accessor) string {
 	return kmeta.ChildName(i.GetName(), "-mesh")
 }
+
+// AuthorizationPolicy returns the name of the AuthorizationPolicy child
+// resource for given Ingress that restricts access to the given
+// backend Service.
+func AuthorizationPolicy(i kmeta.Accessor, serviceName string) string {
+	return kmeta.ChildName(i.GetName(), "-"+serviceName)
+}
+
+// Telemetry returns the name of the Telemetry child resource for given
+// Ingress that configures the observability of the given backend Service.
+func Telemetry(i kmeta.Accessor, serviceName string) string {
+	return kmeta.ChildName(i.GetName(), "-"+serviceName)
+}
+
+// Gateway returns the name of the Gateway child resource for given Ingress
+// that binds to the given ingress gateway Service. The name is a DNS-1123
+// label, truncated to MaxLabelLength, so the Ingresses whose names only
+// differ after their first characters collide. The Ingress reconciled last
+// then reports that it does not own the Gateway.
+func Gateway(i kmeta.Accessor, visibility v1alpha1.IngressVisibility, gatewaySvc *corev1.Service) string {
+	return gateway(i, visibility, gatewaySvc, fips.Checksum)
+}
+
+// LegacyGateway returns the name the Gateway child resource had before the
+// FIPS-compatible mode was enabled, which changes the checksums embedded in
+// the names.
+func LegacyGateway(i kmeta.Accessor, visibility v1alpha1.IngressVisibility, gatewaySvc *corev1.Service) string {
+	return gateway(i, visibility, gatewaySvc, fips.LegacyChecksum)
+}
+
+func gateway(i kmeta.Accessor, visibility v1alpha1.IngressVisibility, gatewaySvc *corev1.Service, checksum func([]byte) uint32) string {
+	prefix := i.GetName()
+	if !IsDNS1123Label(prefix) {
+		prefix = fmt.Sprint(checksum([]byte(prefix)))
+	}
+
+	gatewayServiceKey := fmt.Sprintf("%s/%s", gatewaySvc.Namespace, gatewaySvc.Name)
+	if visibility == v1alpha1.IngressVisibilityClusterLocal {
+		gatewayServiceKey += localGatewayPostfix
+	}
+	gatewayServiceKeyChecksum := fmt.Sprint(checksum([]byte(gatewayServiceKey)))
+
+	// Ensure that the overall gateway name still is a DNS1123 label
+	maxPrefixLength := MaxLabelLength - len(gatewayServiceKeyChecksum) - 1
+	if len(prefix) > maxPrefixLength {
+		prefix = prefix[0:maxPrefixLength]
+	}
+
+	return prefix + "-" + gatewayServiceKeyChecksum
+}
+
+// WildcardGateway returns the name of the Gateway shared by the Ingresses
+// using the given wildcard certificate Secret on the given ingress gateway
+// Service.
+func WildcardGateway(secretName, gatewayServiceNamespace, gatewayServiceName string) string {
+	return wildcardGateway(secretName, gatewayServiceNamespace, gatewayServiceName, fips.Checksum)
+}
+
+// LegacyWildcardGateway returns the name the wildcard Gateway had before the
+// FIPS-compatible mode was enabled, which changes the checksums embedded in
+// the names.
+func LegacyWildcardGateway(secretName, gatewayServiceNamespace, gatewayServiceName string) string {
+	return wildcardGateway(secretName, gatewayServiceNamespace, gatewayServiceName, fips.LegacyChecksum)
+}
+
+func wildcardGateway(secretName, gatewayServiceNamespace, gatewayServiceName string, checksum func([]byte) uint32) string {
+	return fmt.Sprintf("wildcard-%x", checksum([]byte(secretName+"-"+gatewayServiceNamespace+"-"+gatewayServiceName)))
+}
+
+// MirroredSecret returns the name of the copy of the given origin Secret
+// made for the given Ingress in the namespace of the ingress gateways. The
+// names longer than MaxSubdomainLength are shortened with a truncated
+// SHA-256 digest of the name of the Ingress, and keep the UID of the origin
+// Secret.
+func MirroredSecret(originSecret *corev1.Secret, i kmeta.Accessor) string {
+	name, suffix := i.GetName(), "-"+string(originSecret.UID)
+	if len(name)+len(suffix) <= MaxSubdomainLength {
+		return name + suffix
+	}
+	sum := sha256.Sum256([]byte(name))
+	hash := fmt.Sprintf("%x", sum[:16])
+	return name[:MaxSubdomainLength-len(hash)-len(suffix)] + hash + suffix
+}
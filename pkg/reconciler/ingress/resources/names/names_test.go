@@ -17,9 +17,12 @@ limitations under the License.
 package names
 
 import (
+	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
@@ -69,6 +72,30 @@ func TestNamer(t *testing.T) {
 		},
 		f:    MeshVirtualService,
 		want: "foo-mesh",
+	}, {
+		name: "AuthorizationPolicy",
+		ingress: &v1alpha1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "ns3",
+			},
+		},
+		f: func(i kmeta.Accessor) string {
+			return AuthorizationPolicy(i, "bar")
+		},
+		want: "foo-bar",
+	}, {
+		name: "Telemetry",
+		ingress: &v1alpha1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "ns4",
+			},
+		},
+		f: func(i kmeta.Accessor) string {
+			return Telemetry(i, "bar")
+		},
+		want: "foo-bar",
 	}}
 
 	for _, test := range tests {
@@ -80,3 +107,56 @@ func TestNamer(t *testing.T) {
 		})
 	}
 }
+
+func TestGateway(t *testing.T) {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-ingressgateway"}}
+	ingress := func(name string) *v1alpha1.Ingress {
+		return &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+	}
+
+	external := Gateway(ingress("foo"), v1alpha1.IngressVisibilityExternalIP, svc)
+	local := Gateway(ingress("foo"), v1alpha1.IngressVisibilityClusterLocal, svc)
+	if !strings.HasPrefix(external, "foo-") || external == local {
+		t.Errorf("Gateway() = %q, %q, want distinct names prefixed with the Ingress", external, local)
+	}
+	if got := Gateway(ingress("foo.bar"), v1alpha1.IngressVisibilityExternalIP, svc); !IsDNS1123Label(got) {
+		t.Errorf("Gateway() = %q, want a DNS-1123 label", got)
+	}
+
+	// The names of the Ingresses are truncated to fit the checksum of the gateway
+	// Service, so they collide when they only differ past their first characters.
+	long := strings.Repeat("a", MaxLabelLength-len("-second")-1)
+	first := Gateway(ingress(long+"-first"), v1alpha1.IngressVisibilityExternalIP, svc)
+	second := Gateway(ingress(long+"-second"), v1alpha1.IngressVisibilityExternalIP, svc)
+	if len(first) > MaxLabelLength {
+		t.Errorf("len(Gateway()) = %d, want at most %d", len(first), MaxLabelLength)
+	}
+	if first != second {
+		t.Errorf("Gateway() = %q, %q, want the same truncated name", first, second)
+	}
+}
+
+func TestMirroredSecret(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{UID: "9c6a1d3e-3f0e-4c89-9a1c-4a7e0d3c3e4b"}}
+	ingress := func(name string) *v1alpha1.Ingress {
+		return &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+	}
+
+	if got, want := MirroredSecret(secret, ingress("foo")), "foo-"+string(secret.UID); got != want {
+		t.Errorf("MirroredSecret() = %q, want: %q", got, want)
+	}
+
+	long := strings.Repeat("a.", MaxSubdomainLength/2)[:MaxSubdomainLength-1]
+	first, second := MirroredSecret(secret, ingress(long+"b")), MirroredSecret(secret, ingress(long+"c"))
+	if first == second {
+		t.Errorf("MirroredSecret() = %q for both Ingresses, want distinct names", first)
+	}
+	for _, name := range []string{first, second} {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+			t.Errorf("MirroredSecret() = %q, not a DNS-1123 subdomain: %v", name, errs)
+		}
+		if !strings.HasSuffix(name, string(secret.UID)) {
+			t.Errorf("MirroredSecret() = %q, want the UID of the origin Secret as suffix", name)
+		}
+	}
+}
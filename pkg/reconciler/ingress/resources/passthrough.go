@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/network"
+)
+
+const (
+	// GatewayAutoPassthroughPort is the port Istio east-west gateways route on the SNI.
+	GatewayAutoPassthroughPort = 15443
+
+	autoPassthroughServerPortName = "tls-passthrough"
+)
+
+// AutoPassthroughGatewayName returns the name of the Gateway exposing the cluster-local
+// hosts of the Ingress in AUTO_PASSTHROUGH mode.
+func AutoPassthroughGatewayName(ing *v1alpha1.Ingress) string {
+	return kmeta.ChildName(ing.Name, "-auto-passthrough")
+}
+
+// AutoPassthroughHosts returns the fully qualified cluster-local hosts of the Ingress,
+// which are the only ones that can appear in the SNI of connections routed through
+// an AUTO_PASSTHROUGH gateway.
+func AutoPassthroughHosts(ing *v1alpha1.Ingress) []string {
+	suffix := ".svc." + network.GetClusterDomainName()
+	hosts := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility != v1alpha1.IngressVisibilityClusterLocal {
+			continue
+		}
+		for _, host := range rule.Hosts {
+			if strings.HasSuffix(host, suffix) {
+				hosts.Insert(host)
+			}
+		}
+	}
+	return sets.List(hosts)
+}
+
+// MakeAutoPassthroughGateway creates a Gateway exposing the given hosts of the Ingress
+// on the given gateway in AUTO_PASSTHROUGH mode, which routes connections on their SNI
+// without terminating TLS.
+func MakeAutoPassthroughGateway(ing *v1alpha1.Ingress, hosts []string, gatewayService *corev1.Service) *v1beta1.Gateway {
+	return &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            AutoPassthroughGatewayName(ing),
+			Namespace:       ing.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+			Labels: map[string]string{
+				networking.IngressLabelKey: ing.Name,
+			},
+		},
+		Spec: istiov1beta1.Gateway{
+			Selector: gatewayService.Spec.Selector,
+			Servers: []*istiov1beta1.Server{{
+				Hosts: hosts,
+				Port: &istiov1beta1.Port{
+					Name:     autoPassthroughServerPortName,
+					Number:   GatewayAutoPassthroughPort,
+					Protocol: "TLS",
+				},
+				Tls: &istiov1beta1.ServerTLSSettings{
+					Mode: istiov1beta1.ServerTLSSettings_AUTO_PASSTHROUGH,
+				},
+			}},
+		},
+	}
+}
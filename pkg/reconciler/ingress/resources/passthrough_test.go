@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+func TestAutoPassthroughHosts(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      []string{"foo.bar.com"},
+				Visibility: v1alpha1.IngressVisibilityExternalIP,
+			}, {
+				Hosts:      []string{"foo.default", "foo.default.svc", "foo.default.svc.cluster.local"},
+				Visibility: v1alpha1.IngressVisibilityClusterLocal,
+			}, {
+				Hosts:      []string{"foo.default.svc.cluster.local", "tag-foo.default.svc.cluster.local"},
+				Visibility: v1alpha1.IngressVisibilityClusterLocal,
+			}},
+		},
+	}
+
+	want := []string{"foo.default.svc.cluster.local", "tag-foo.default.svc.cluster.local"}
+	if got := AutoPassthroughHosts(ing); !cmp.Equal(got, want) {
+		t.Error("AutoPassthroughHosts() (-want, +got) =", cmp.Diff(want, got))
+	}
+}
+
+func TestMakeAutoPassthroughGateway(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "default",
+		},
+	}
+	gatewayService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-eastwestgateway",
+			Namespace: "istio-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "eastwestgateway"},
+		},
+	}
+	hosts := []string{"foo.default.svc.cluster.local"}
+
+	want := &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "ingress-auto-passthrough",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+			Labels:          map[string]string{networking.IngressLabelKey: "ingress"},
+		},
+		Spec: istiov1beta1.Gateway{
+			Selector: map[string]string{"istio": "eastwestgateway"},
+			Servers: []*istiov1beta1.Server{{
+				Hosts: hosts,
+				Port: &istiov1beta1.Port{
+					Name:     "tls-passthrough",
+					Number:   15443,
+					Protocol: "TLS",
+				},
+				Tls: &istiov1beta1.ServerTLSSettings{
+					Mode: istiov1beta1.ServerTLSSettings_AUTO_PASSTHROUGH,
+				},
+			}},
+		},
+	}
+
+	got := MakeAutoPassthroughGateway(ing, hosts, gatewayService)
+	if !cmp.Equal(got, want, protocmp.Transform()) {
+		t.Error("MakeAutoPassthroughGateway() (-want, +got) =", cmp.Diff(want, got, protocmp.Transform()))
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// StripPathPrefixAnnotationKey is the annotation holding the YAML list of the paths of
+// the Ingress whose prefix is stripped from the requests before they are forwarded to
+// their backends, e.g. to mount under /api/foo a service serving /.
+const StripPathPrefixAnnotationKey = "networking.knative.dev/strip-path-prefix"
+
+// ParseStrippedPathPrefixes returns the paths of the Ingress whose prefix is stripped.
+func ParseStrippedPathPrefixes(ing *v1alpha1.Ingress) ([]string, error) {
+	v := ing.Annotations[StripPathPrefixAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var prefixes []string
+	if err := yaml.UnmarshalStrict([]byte(v), &prefixes); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", StripPathPrefixAnnotationKey, err)
+	}
+
+	paths := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			paths.Insert(path.Path)
+		}
+	}
+	for _, prefix := range prefixes {
+		if strings.Trim(prefix, "/") == "" {
+			return nil, fmt.Errorf("invalid %s annotation: the path %q has no prefix to strip", StripPathPrefixAnnotationKey, prefix)
+		}
+		if !paths.Has(prefix) {
+			return nil, fmt.Errorf("invalid %s annotation: %q is not a path of the Ingress", StripPathPrefixAnnotationKey, prefix)
+		}
+	}
+	return prefixes, nil
+}
+
+// WithStrippedPathPrefixes rewrites the URIs of the requests of the HTTP routes of the
+// VirtualService matching the given paths, replacing the path and the slashes following
+// it with a single slash, e.g. /api/foo/bar to /bar and /api/foo to /. The rewrite of the
+// URI of Istio is not used, as it would rewrite /api/foo/bar to //bar.
+func WithStrippedPathPrefixes(vs *v1beta1.VirtualService, prefixes []string) {
+	changed := false
+	for _, route := range vs.Spec.Http {
+		for _, prefix := range prefixes {
+			if !matchesPath(route, prefix) {
+				continue
+			}
+			if route.Rewrite == nil {
+				route.Rewrite = &istiov1beta1.HTTPRewrite{}
+			}
+			route.Rewrite.UriRegexRewrite = &istiov1beta1.RegexRewrite{
+				Match:   "^" + regexp.QuoteMeta(strings.TrimSuffix(prefix, "/")) + "/*",
+				Rewrite: "/",
+			}
+			changed = true
+			break
+		}
+	}
+	if changed {
+		vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseStrippedPathPrefixes(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       []string
+		wantErr    bool
+	}{{
+		name: "no prefixes",
+	}, {
+		name:       "prefixes",
+		annotation: "[/api/foo]",
+		want:       []string{"/api/foo"},
+	}, {
+		name:       "invalid yaml",
+		annotation: "[/api/foo",
+		wantErr:    true,
+	}, {
+		name:       "root path",
+		annotation: "[/]",
+		wantErr:    true,
+	}, {
+		name:       "unknown path",
+		annotation: "[/api/bar]",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{StripPathPrefixAnnotationKey: tt.annotation},
+				},
+				Spec: v1alpha1.IngressSpec{
+					Rules: []v1alpha1.IngressRule{{
+						HTTP: &v1alpha1.HTTPIngressRuleValue{
+							Paths: []v1alpha1.HTTPIngressPath{{Path: "/"}, {Path: "/api/foo"}},
+						},
+					}},
+				},
+			}
+			got, err := ParseStrippedPathPrefixes(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStrippedPathPrefixes() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("Unexpected prefixes (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithStrippedPathPrefixes(t *testing.T) {
+	route := func(path string, rewrite *istiov1beta1.HTTPRewrite) *istiov1beta1.HTTPRoute {
+		return &istiov1beta1.HTTPRoute{
+			Match: []*istiov1beta1.HTTPMatchRequest{{
+				Uri: &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: path}},
+			}},
+			Rewrite: rewrite,
+		}
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{
+				route("/api/foo", nil),
+				route("/api/bar/", &istiov1beta1.HTTPRewrite{Authority: "bar.ns.svc.cluster.local"}),
+				route("/", nil),
+			},
+		},
+	}
+
+	WithStrippedPathPrefixes(vs, []string{"/api/foo", "/api/bar/"})
+
+	want := []*istiov1beta1.HTTPRoute{
+		route("/api/foo", &istiov1beta1.HTTPRewrite{
+			UriRegexRewrite: &istiov1beta1.RegexRewrite{Match: "^/api/foo/*", Rewrite: "/"},
+		}),
+		route("/api/bar/", &istiov1beta1.HTTPRewrite{
+			Authority:       "bar.ns.svc.cluster.local",
+			UriRegexRewrite: &istiov1beta1.RegexRewrite{Match: "^/api/bar/*", Rewrite: "/"},
+		}),
+		route("/", nil),
+	}
+	if diff := cmp.Diff(want, vs.Spec.Http, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+
+	re := regexp.MustCompile(want[0].Rewrite.UriRegexRewrite.Match)
+	for path, rewritten := range map[string]string{
+		"/api/foo":      "/",
+		"/api/foo/":     "/",
+		"/api/foo/bar":  "/bar",
+		"/api/foo//bar": "/bar",
+	} {
+		if got := re.ReplaceAllString(path, "/"); got != rewritten {
+			t.Errorf("Rewrite(%q) = %q, want: %q", path, got, rewritten)
+		}
+	}
+}
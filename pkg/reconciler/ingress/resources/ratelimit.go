@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+const (
+	// RateLimitAnnotationKey is the annotation limiting the number of requests per second
+	// the gateways accept for the hosts of the Ingress.
+	RateLimitAnnotationKey = "networking.knative.dev/rate-limit-rps"
+
+	// RateLimitBurstAnnotationKey is the annotation holding the number of requests which
+	// may exceed the rate limit in a burst. It defaults to the rate limit.
+	RateLimitBurstAnnotationKey = "networking.knative.dev/rate-limit-burst"
+
+	localRateLimitFilter     = "envoy.filters.http.local_ratelimit"
+	localRateLimitTypeURL    = "type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit"
+	typedStructTypeURL       = "type.googleapis.com/udpa.type.v1.TypedStruct"
+	httpConnectionManager    = "envoy.filters.network.http_connection_manager"
+	httpRouterFilter         = "envoy.filters.http.router"
+	rateLimitEnforcedPercent = 100
+)
+
+// HasRateLimitAnnotations returns whether the Ingress requests its hosts to be rate limited.
+func HasRateLimitAnnotations(ing *v1alpha1.Ingress) bool {
+	for _, key := range []string{RateLimitAnnotationKey, RateLimitBurstAnnotationKey} {
+		if _, ok := ing.Annotations[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimitEnvoyFilterName returns the name of the EnvoyFilter rate limiting the hosts of
+// the Ingress on the gateway behind gatewaySvc.
+func RateLimitEnvoyFilterName(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service) string {
+	return kmeta.ChildName(ing.Namespace+"-"+ing.Name, "-ratelimit-"+gatewaySvc.Name)
+}
+
+// MakeRateLimitEnvoyFilter creates an EnvoyFilter applying the Envoy local rate limit
+// requested by the Ingress annotations to the given hosts on the gateway behind gatewaySvc.
+//
+// The rate limit filter is inserted in the gateway's listeners disabled, and only enabled
+// for the virtual hosts of the Ingress, so that other Ingresses sharing the gateway are
+// not affected.
+func MakeRateLimitEnvoyFilter(ing *v1alpha1.Ingress, gatewaySvc *corev1.Service, hosts []string) (*v1alpha3.EnvoyFilter, error) {
+	rps, err := parsePositiveInt(ing, RateLimitAnnotationKey)
+	if err != nil {
+		return nil, err
+	}
+	burst := rps
+	if _, ok := ing.Annotations[RateLimitBurstAnnotationKey]; ok {
+		if burst, err = parsePositiveInt(ing, RateLimitBurstAnnotationKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// The filter name is unique per Ingress, as Envoy keys the per-route
+	// configuration by filter name and every Ingress inserts its own filter.
+	filterName := fmt.Sprintf("%s.knative.%s.%s", localRateLimitFilter, ing.Namespace, ing.Name)
+	statPrefix := fmt.Sprintf("knative_%s_%s", ing.Namespace, ing.Name)
+
+	filter, err := structpb.NewStruct(map[string]interface{}{
+		"name": filterName,
+		"typed_config": map[string]interface{}{
+			"@type":    typedStructTypeURL,
+			"type_url": localRateLimitTypeURL,
+			"value": map[string]interface{}{
+				"stat_prefix": statPrefix,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ef := &v1alpha3.EnvoyFilter{
+		ObjectMeta: gatewayResourceMeta(ing, gatewaySvc),
+		Spec: istiov1alpha3.EnvoyFilter{
+			WorkloadSelector: &istiov1alpha3.WorkloadSelector{
+				Labels: gatewaySvc.Spec.Selector,
+			},
+			ConfigPatches: []*istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{{
+				ApplyTo: istiov1alpha3.EnvoyFilter_HTTP_FILTER,
+				Match: &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+					Context: istiov1alpha3.EnvoyFilter_GATEWAY,
+					ObjectTypes: &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+						Listener: &istiov1alpha3.EnvoyFilter_ListenerMatch{
+							FilterChain: &istiov1alpha3.EnvoyFilter_ListenerMatch_FilterChainMatch{
+								Filter: &istiov1alpha3.EnvoyFilter_ListenerMatch_FilterMatch{
+									Name: httpConnectionManager,
+									SubFilter: &istiov1alpha3.EnvoyFilter_ListenerMatch_SubFilterMatch{
+										Name: httpRouterFilter,
+									},
+								},
+							},
+						},
+					},
+				},
+				Patch: &istiov1alpha3.EnvoyFilter_Patch{
+					Operation: istiov1alpha3.EnvoyFilter_Patch_INSERT_BEFORE,
+					Value:     filter,
+				},
+			}},
+		},
+	}
+	ef.Name = RateLimitEnvoyFilterName(ing, gatewaySvc)
+
+	for _, host := range hosts {
+		for _, port := range []int{GatewayHTTPPort, ExternalGatewayHTTPSPort} {
+			config, err := structpb.NewStruct(map[string]interface{}{
+				"typed_per_filter_config": map[string]interface{}{
+					filterName: map[string]interface{}{
+						"@type":    typedStructTypeURL,
+						"type_url": localRateLimitTypeURL,
+						"value": map[string]interface{}{
+							"stat_prefix": statPrefix,
+							"token_bucket": map[string]interface{}{
+								"max_tokens":      burst,
+								"tokens_per_fill": rps,
+								"fill_interval":   "1s",
+							},
+							"filter_enabled":  fractionalPercent(rateLimitEnforcedPercent),
+							"filter_enforced": fractionalPercent(rateLimitEnforcedPercent),
+						},
+					},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			ef.Spec.ConfigPatches = append(ef.Spec.ConfigPatches, &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+				ApplyTo: istiov1alpha3.EnvoyFilter_VIRTUAL_HOST,
+				Match: &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+					Context: istiov1alpha3.EnvoyFilter_GATEWAY,
+					ObjectTypes: &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_RouteConfiguration{
+						RouteConfiguration: &istiov1alpha3.EnvoyFilter_RouteConfigurationMatch{
+							Vhost: &istiov1alpha3.EnvoyFilter_RouteConfigurationMatch_VirtualHostMatch{
+								Name: fmt.Sprintf("%s:%d", host, port),
+							},
+						},
+					},
+				},
+				Patch: &istiov1alpha3.EnvoyFilter_Patch{
+					Operation: istiov1alpha3.EnvoyFilter_Patch_MERGE,
+					Value:     config,
+				},
+			})
+		}
+	}
+	ef.Annotations = kaccessor.WithSpecHash(ef.Annotations, &ef.Spec)
+
+	return ef, nil
+}
+
+func parsePositiveInt(ing *v1alpha1.Ingress, key string) (int, error) {
+	v := ing.Annotations[key]
+	i, err := strconv.Atoi(v)
+	if err != nil || i <= 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q: must be a positive integer", key, v)
+	}
+	return i, nil
+}
+
+func fractionalPercent(numerator int) map[string]interface{} {
+	return map[string]interface{}{
+		"default_value": map[string]interface{}{
+			"numerator":   numerator,
+			"denominator": "HUNDRED",
+		},
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeRateLimitEnvoyFilter(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-ingress",
+			Namespace: "my-namespace",
+			Annotations: map[string]string{
+				RateLimitAnnotationKey:      "10",
+				RateLimitBurstAnnotationKey: "20",
+			},
+		},
+	}
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+
+	got, err := MakeRateLimitEnvoyFilter(ing, gatewaySvc, []string{"foo.example.com"})
+	if err != nil {
+		t.Fatal("MakeRateLimitEnvoyFilter() =", err)
+	}
+
+	if got.Name != "my-namespace-my-ingress-ratelimit-istio-ingressgateway" || got.Namespace != "istio-system" {
+		t.Errorf("EnvoyFilter = %s/%s, want istio-system/my-namespace-my-ingress-ratelimit-istio-ingressgateway", got.Namespace, got.Name)
+	}
+	if diff := cmp.Diff(GatewayResourceLabels(ing), got.Labels); diff != "" {
+		t.Error("Unexpected labels (-want +got):", diff)
+	}
+	wantSelector := &istiov1alpha3.WorkloadSelector{Labels: gatewaySvc.Spec.Selector}
+	if diff := cmp.Diff(wantSelector, got.Spec.WorkloadSelector, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected workload selector (-want +got):", diff)
+	}
+	if len(got.Spec.ConfigPatches) != 3 {
+		t.Fatalf("len(ConfigPatches) = %d, want 3", len(got.Spec.ConfigPatches))
+	}
+
+	filter := got.Spec.ConfigPatches[0]
+	if filter.ApplyTo != istiov1alpha3.EnvoyFilter_HTTP_FILTER || filter.Patch.Operation != istiov1alpha3.EnvoyFilter_Patch_INSERT_BEFORE {
+		t.Errorf("filter patch = %v %v, want HTTP_FILTER INSERT_BEFORE", filter.ApplyTo, filter.Patch.Operation)
+	}
+	filterName := filter.Patch.Value.AsMap()["name"].(string)
+
+	var vhosts []string
+	for _, patch := range got.Spec.ConfigPatches[1:] {
+		vhosts = append(vhosts, patch.Match.GetRouteConfiguration().GetVhost().GetName())
+		config := patch.Patch.Value.AsMap()["typed_per_filter_config"].(map[string]interface{})
+		bucket := config[filterName].(map[string]interface{})["value"].(map[string]interface{})["token_bucket"]
+		want := map[string]interface{}{"max_tokens": float64(20), "tokens_per_fill": float64(10), "fill_interval": "1s"}
+		if diff := cmp.Diff(want, bucket); diff != "" {
+			t.Error("Unexpected token bucket (-want +got):", diff)
+		}
+	}
+	if diff := cmp.Diff([]string{"foo.example.com:80", "foo.example.com:443"}, vhosts); diff != "" {
+		t.Error("Unexpected vhosts (-want +got):", diff)
+	}
+}
+
+func TestMakeRateLimitEnvoyFilterErrors(t *testing.T) {
+	gatewaySvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+	}
+
+	for name, annotations := range map[string]map[string]string{
+		"missing rate":   {RateLimitBurstAnnotationKey: "10"},
+		"invalid rate":   {RateLimitAnnotationKey: "fast"},
+		"zero rate":      {RateLimitAnnotationKey: "0"},
+		"negative burst": {RateLimitAnnotationKey: "10", RateLimitBurstAnnotationKey: "-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-ingress",
+					Namespace:   "my-namespace",
+					Annotations: annotations,
+				},
+			}
+			if _, err := MakeRateLimitEnvoyFilter(ing, gatewaySvc, []string{"foo.example.com"}); err == nil {
+				t.Error("MakeRateLimitEnvoyFilter() = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/yaml"
+)
+
+// RequestHeadersAnnotationKey is the annotation holding the YAML list of the
+// RequestHeaderOperations of the Ingress.
+const RequestHeadersAnnotationKey = "networking.knative.dev/request-headers"
+
+// RequestHeaderOperations set and remove headers of the requests of paths of the Ingress
+// on the gateways, e.g. to strip internal headers at the edge. The headers the Ingress
+// appends take precedence over the operations, and the earlier operations over the
+// later ones.
+type RequestHeaderOperations struct {
+	// Path selects the paths of the Ingress with this path, all of them when empty.
+	Path string `json:"path,omitempty"`
+	// ServiceName restricts the operations to the requests the paths split to this
+	// backend of the Ingress.
+	ServiceName string `json:"serviceName,omitempty"`
+	// Set sets the headers to the given values.
+	Set map[string]string `json:"set,omitempty"`
+	// Remove removes the headers.
+	Remove []string `json:"remove,omitempty"`
+}
+
+// ParseRequestHeaderOperations returns the RequestHeaderOperations of the Ingress.
+func ParseRequestHeaderOperations(ing *v1alpha1.Ingress) ([]RequestHeaderOperations, error) {
+	v := ing.Annotations[RequestHeadersAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var ops []RequestHeaderOperations
+	if err := yaml.UnmarshalStrict([]byte(v), &ops); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", RequestHeadersAnnotationKey, err)
+	}
+
+	paths, backends := sets.New[string](), sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			paths.Insert(path.Path)
+			for _, split := range path.Splits {
+				backends.Insert(split.ServiceName)
+			}
+		}
+	}
+	for i, op := range ops {
+		if len(op.Set) == 0 && len(op.Remove) == 0 {
+			return nil, fmt.Errorf("invalid %s annotation: operation %d neither sets nor removes headers", RequestHeadersAnnotationKey, i)
+		}
+		if op.Path != "" && !paths.Has(op.Path) {
+			return nil, fmt.Errorf("invalid %s annotation: operation %d selects the path %q which is not a path of the Ingress", RequestHeadersAnnotationKey, i, op.Path)
+		}
+		if op.ServiceName != "" && !backends.Has(op.ServiceName) {
+			return nil, fmt.Errorf("invalid %s annotation: operation %d selects %q which is not a backend of the Ingress", RequestHeadersAnnotationKey, i, op.ServiceName)
+		}
+		for _, name := range append(sets.List(sets.KeySet(op.Set)), op.Remove...) {
+			if errs := validation.IsHTTPHeaderName(name); len(errs) != 0 {
+				return nil, fmt.Errorf("invalid %s annotation: operation %d header %q: %s", RequestHeadersAnnotationKey, i, name, strings.Join(errs, ", "))
+			}
+		}
+		for _, name := range op.Remove {
+			if _, ok := op.Set[name]; ok {
+				return nil, fmt.Errorf("invalid %s annotation: operation %d both sets and removes the header %q", RequestHeadersAnnotationKey, i, name)
+			}
+		}
+	}
+	return ops, nil
+}
+
+// WithRequestHeaderOperations applies the given RequestHeaderOperations to the HTTP
+// routes of the gateway VirtualService matching their paths, or to the destinations of
+// these routes to their backends.
+func WithRequestHeaderOperations(vs *v1beta1.VirtualService, namespace string, ops []RequestHeaderOperations) {
+	if len(ops) == 0 {
+		return
+	}
+
+	for _, route := range vs.Spec.Http {
+		for _, op := range ops {
+			if op.Path != "" && !matchesPath(route, op.Path) {
+				continue
+			}
+			if op.ServiceName == "" {
+				route.Headers = withRequestHeaderOperations(route.Headers, op)
+				continue
+			}
+			host := network.GetServiceHostname(op.ServiceName, namespace)
+			for _, dest := range route.Route {
+				if dest.Destination.GetHost() == host {
+					dest.Headers = withRequestHeaderOperations(dest.Headers, op)
+				}
+			}
+		}
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
+
+// matchesPath returns whether the route is the one of the path of the Ingress.
+func matchesPath(route *istiov1beta1.HTTPRoute, path string) bool {
+	for _, match := range route.Match {
+		if match.GetUri().GetPrefix() == path {
+			return true
+		}
+	}
+	return false
+}
+
+// withRequestHeaderOperations returns the headers with the operation applied to the
+// requests, leaving the headers already set or removed unchanged.
+func withRequestHeaderOperations(h *istiov1beta1.Headers, op RequestHeaderOperations) *istiov1beta1.Headers {
+	if h == nil {
+		h = &istiov1beta1.Headers{}
+	}
+	if h.Request == nil {
+		h.Request = &istiov1beta1.Headers_HeaderOperations{}
+	}
+	// The map of the headers the Ingress appends is shared by the VirtualServices.
+	set := maps.Clone(h.Request.Set)
+	for k, v := range op.Set {
+		if _, ok := set[k]; ok || slices.Contains(h.Request.Remove, k) {
+			continue
+		}
+		if set == nil {
+			set = make(map[string]string, len(op.Set))
+		}
+		set[k] = v
+	}
+	h.Request.Set = set
+	for _, k := range op.Remove {
+		if _, ok := set[k]; ok || slices.Contains(h.Request.Remove, k) {
+			continue
+		}
+		h.Request.Remove = append(h.Request.Remove, k)
+	}
+	return h
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseRequestHeaderOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       []RequestHeaderOperations
+		wantErr    bool
+	}{{
+		name: "no operations",
+	}, {
+		name: "path and split operations",
+		annotation: `
+- remove: [x-internal-user]
+- path: /api
+  serviceName: premium-service
+  set:
+    x-plan: premium`,
+		want: []RequestHeaderOperations{{
+			Remove: []string{"x-internal-user"},
+		}, {
+			Path:        "/api",
+			ServiceName: "premium-service",
+			Set:         map[string]string{"x-plan": "premium"},
+		}},
+	}, {
+		name:       "invalid yaml",
+		annotation: "- remove: [",
+		wantErr:    true,
+	}, {
+		name:       "no operation",
+		annotation: "- path: /api",
+		wantErr:    true,
+	}, {
+		name:       "unknown path",
+		annotation: "- path: /other\n  remove: [x-internal-user]",
+		wantErr:    true,
+	}, {
+		name:       "unknown backend",
+		annotation: "- serviceName: other-service\n  remove: [x-internal-user]",
+		wantErr:    true,
+	}, {
+		name:       "invalid header name",
+		annotation: "- set: {'x plan': premium}",
+		wantErr:    true,
+	}, {
+		name:       "set and removed header",
+		annotation: "- set: {x-plan: premium}\n  remove: [x-plan]",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{RequestHeadersAnnotationKey: tt.annotation},
+				},
+				Spec: v1alpha1.IngressSpec{
+					Rules: []v1alpha1.IngressRule{{
+						HTTP: &v1alpha1.HTTPIngressRuleValue{
+							Paths: []v1alpha1.HTTPIngressPath{{
+								Path: "/api",
+								Splits: []v1alpha1.IngressBackendSplit{{
+									IngressBackend: v1alpha1.IngressBackend{ServiceName: "premium-service"},
+								}},
+							}},
+						},
+					}},
+				},
+			}
+			got, err := ParseRequestHeaderOperations(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRequestHeaderOperations() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("Unexpected operations (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithRequestHeaderOperations(t *testing.T) {
+	// The headers the Ingress appends, shared by the VirtualServices.
+	appended := map[string]string{"knative-serving-namespace": "test-ns"}
+	split := func(service string, weight int32, headers *istiov1beta1.Headers) *istiov1beta1.HTTPRouteDestination {
+		return &istiov1beta1.HTTPRouteDestination{
+			Destination: &istiov1beta1.Destination{Host: service + ".test-ns.svc.cluster.local"},
+			Weight:      weight,
+			Headers:     headers,
+		}
+	}
+	path := func(path string) []*istiov1beta1.HTTPMatchRequest {
+		return []*istiov1beta1.HTTPMatchRequest{{
+			Uri: &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: path}},
+		}}
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Match: path("/api"),
+				Route: []*istiov1beta1.HTTPRouteDestination{
+					split("stable", 90, &istiov1beta1.Headers{Request: &istiov1beta1.Headers_HeaderOperations{Set: appended}}),
+					split("premium", 10, nil),
+				},
+			}, {
+				Match: path("/"),
+				Route: []*istiov1beta1.HTTPRouteDestination{split("stable", 100, nil)},
+			}},
+		},
+	}
+
+	WithRequestHeaderOperations(vs, "test-ns", []RequestHeaderOperations{{
+		Remove: []string{"x-internal-user"},
+	}, {
+		Path:        "/api",
+		ServiceName: "stable",
+		Set:         map[string]string{"knative-serving-namespace": "other-ns", "x-plan": "stable"},
+	}, {
+		Path:   "/api",
+		Set:    map[string]string{"x-edge": "true"},
+		Remove: []string{"x-internal-user"},
+	}})
+
+	want := []*istiov1beta1.HTTPRoute{{
+		Match: path("/api"),
+		Route: []*istiov1beta1.HTTPRouteDestination{
+			split("stable", 90, &istiov1beta1.Headers{Request: &istiov1beta1.Headers_HeaderOperations{
+				// The headers the Ingress appends take precedence.
+				Set: map[string]string{"knative-serving-namespace": "test-ns", "x-plan": "stable"},
+			}}),
+			split("premium", 10, nil),
+		},
+		Headers: &istiov1beta1.Headers{Request: &istiov1beta1.Headers_HeaderOperations{
+			Set:    map[string]string{"x-edge": "true"},
+			Remove: []string{"x-internal-user"},
+		}},
+	}, {
+		Match: path("/"),
+		Route: []*istiov1beta1.HTTPRouteDestination{split("stable", 100, nil)},
+		Headers: &istiov1beta1.Headers{Request: &istiov1beta1.Headers_HeaderOperations{
+			Remove: []string{"x-internal-user"},
+		}},
+	}}
+	if diff := cmp.Diff(want, vs.Spec.Http, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+	if got := len(appended); got != 1 {
+		t.Errorf("The appended headers were modified: %v", appended)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// RetriesAnnotationKey is the annotation holding the YAML Retries of the routes of the
+// Ingress, overriding the retries configured in config-istio.
+const RetriesAnnotationKey = "networking.knative.dev/retries"
+
+// Retries configure how the routes of an Ingress retry the failed requests.
+type Retries struct {
+	// Attempts is how many times a request is retried, never when zero.
+	Attempts int32 `json:"attempts"`
+	// RetryOn are the comma-separated conditions to retry the requests on, the ones of
+	// Istio when empty.
+	RetryOn string `json:"retryOn,omitempty"`
+	// PerTryTimeout is the timeout of each try, the one of the request when zero. It does
+	// not apply to the gRPC requests, see WithGRPCRetries.
+	PerTryTimeout metav1.Duration `json:"perTryTimeout,omitempty"`
+}
+
+// MakeRetries returns the retries of the routes of the Ingress, the ones of its annotation
+// or else the ones configured in config-istio.
+func MakeRetries(ctx context.Context, ing *v1alpha1.Ingress) (*istiov1beta1.HTTPRetry, error) {
+	istio := config.FromContext(ctx).Istio
+	retries := Retries{
+		Attempts:      int32(istio.RetryAttempts),
+		RetryOn:       istio.RetryOn,
+		PerTryTimeout: metav1.Duration{Duration: istio.RetryPerTryTimeout},
+	}
+	if v := ing.Annotations[RetriesAnnotationKey]; v != "" {
+		retries = Retries{}
+		if err := yaml.UnmarshalStrict([]byte(v), &retries); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", RetriesAnnotationKey, err)
+		}
+		if retries.Attempts < 0 {
+			return nil, fmt.Errorf("invalid %s annotation: attempts must not be negative, was: %d", RetriesAnnotationKey, retries.Attempts)
+		}
+		if err := config.ValidateRetryOn(retries.RetryOn); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", RetriesAnnotationKey, err)
+		}
+		if retries.PerTryTimeout.Duration < 0 {
+			return nil, fmt.Errorf("invalid %s annotation: perTryTimeout must not be negative, was: %v", RetriesAnnotationKey, retries.PerTryTimeout.Duration)
+		}
+	}
+
+	// Without attempts, the routes override the default retries of Istio.
+	retry := &istiov1beta1.HTTPRetry{Attempts: retries.Attempts}
+	if retries.Attempts == 0 {
+		return retry, nil
+	}
+	if retries.RetryOn != "" {
+		conditions := strings.Split(retries.RetryOn, ",")
+		for i := range conditions {
+			conditions[i] = strings.TrimSpace(conditions[i])
+		}
+		retry.RetryOn = strings.Join(conditions, ",")
+	}
+	if retries.PerTryTimeout.Duration > 0 {
+		retry.PerTryTimeout = durationpb.New(retries.PerTryTimeout.Duration)
+	}
+	return retry, nil
+}
+
+// WithRetries sets the given retries on the HTTP routes of the VirtualService.
+func WithRetries(vs *v1beta1.VirtualService, retry *istiov1beta1.HTTPRetry) {
+	if retry.GetAttempts() == 0 {
+		// The routes are generated without retries.
+		return
+	}
+	for _, route := range vs.Spec.Http {
+		route.Retries = retry.DeepCopy()
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		istio      config.Istio
+		annotation string
+		want       *istiov1beta1.HTTPRetry
+		wantErr    bool
+	}{{
+		name: "no retries",
+		want: &istiov1beta1.HTTPRetry{},
+	}, {
+		name:  "configured retries",
+		istio: config.Istio{RetryAttempts: 3, RetryOn: "5xx, reset", RetryPerTryTimeout: 2 * time.Second},
+		want: &istiov1beta1.HTTPRetry{
+			Attempts:      3,
+			RetryOn:       "5xx,reset",
+			PerTryTimeout: durationpb.New(2 * time.Second),
+		},
+	}, {
+		name:       "annotation overrides the configuration",
+		istio:      config.Istio{RetryAttempts: 3, RetryOn: "5xx", RetryPerTryTimeout: 2 * time.Second},
+		annotation: "{attempts: 2, retryOn: '503'}",
+		want:       &istiov1beta1.HTTPRetry{Attempts: 2, RetryOn: "503"},
+	}, {
+		name:       "annotation disables the retries",
+		istio:      config.Istio{RetryAttempts: 3, RetryOn: "5xx"},
+		annotation: "{attempts: 0}",
+		want:       &istiov1beta1.HTTPRetry{},
+	}, {
+		name:       "invalid yaml",
+		annotation: "{attempts: [",
+		wantErr:    true,
+	}, {
+		name:       "negative attempts",
+		annotation: "{attempts: -1}",
+		wantErr:    true,
+	}, {
+		name:       "unsupported condition",
+		annotation: "{attempts: 1, retryOn: sometimes}",
+		wantErr:    true,
+	}, {
+		name:       "negative timeout",
+		annotation: "{attempts: 1, perTryTimeout: -1s}",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := config.ToContext(context.Background(), &config.Config{Istio: &tt.istio})
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+				},
+			}
+			if tt.annotation != "" {
+				ing.Annotations[RetriesAnnotationKey] = tt.annotation
+			}
+			got, err := MakeRetries(ctx, ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MakeRetries() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Error("MakeRetries() (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithRetries(t *testing.T) {
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{Retries: &istiov1beta1.HTTPRetry{}}, {Retries: &istiov1beta1.HTTPRetry{}}},
+		},
+	}
+
+	WithRetries(vs, &istiov1beta1.HTTPRetry{})
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] != "" {
+		t.Error("The VirtualService was changed without retries")
+	}
+
+	retry := &istiov1beta1.HTTPRetry{Attempts: 3, RetryOn: "5xx"}
+	WithRetries(vs, retry)
+	for i, route := range vs.Spec.Http {
+		if diff := cmp.Diff(retry, route.Retries, protocmp.Transform()); diff != "" {
+			t.Errorf("Retries of route %d (-want, +got): %s", i, diff)
+		}
+	}
+	if vs.Spec.Http[0].Retries == vs.Spec.Http[1].Retries {
+		t.Error("The routes share their retries")
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+}
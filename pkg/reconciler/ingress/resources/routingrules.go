@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/network"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RoutingRulesAnnotationKey is the annotation holding the YAML list of RoutingRules
+	// of the Ingress.
+	RoutingRulesAnnotationKey = "networking.knative.dev/routing-rules"
+
+	// jwtClaimHeaderPrefix is the prefix of the pseudo headers matching the claims of
+	// the JWT validated by a RequestAuthentication on the gateway.
+	jwtClaimHeaderPrefix = "@request.auth.claims."
+)
+
+// RoutingRule sends the requests carrying the given headers and JWT claims to one of the
+// backends of the Ingress, instead of splitting them between all of its backends.
+type RoutingRule struct {
+	// Headers the requests must carry, with their exact values.
+	Headers map[string]string `json:"headers,omitempty"`
+	// HeaderMatches are the headers of the requests matched with other operators than
+	// the exact match of Headers, e.g. the prefix of their values or their absence.
+	HeaderMatches map[string]HeaderMatch `json:"headerMatches,omitempty"`
+	// Claims the JWTs of the requests must carry, with their exact values. Claims can
+	// only be matched on gateways validating the JWTs, see JWTIssuerAnnotationKey.
+	Claims map[string]string `json:"claims,omitempty"`
+	// ServiceName is the name of the backend of the Ingress the requests are sent to.
+	ServiceName string `json:"serviceName"`
+}
+
+// HeaderMatch matches a header with exactly one of its operators.
+type HeaderMatch struct {
+	// Exact matches the values equal to it.
+	Exact string `json:"exact,omitempty"`
+	// Prefix matches the values starting with it.
+	Prefix string `json:"prefix,omitempty"`
+	// Suffix matches the values ending with it.
+	Suffix string `json:"suffix,omitempty"`
+	// Regex matches the values matching the RE2 regular expression in full.
+	Regex string `json:"regex,omitempty"`
+	// Absent matches the requests without the header.
+	Absent bool `json:"absent,omitempty"`
+}
+
+// validate returns an error unless exactly one operator of the HeaderMatch is set.
+func (m HeaderMatch) validate() error {
+	operators := 0
+	for _, set := range []bool{m.Exact != "", m.Prefix != "", m.Suffix != "", m.Regex != "", m.Absent} {
+		if set {
+			operators++
+		}
+	}
+	if operators != 1 {
+		return fmt.Errorf("sets %d operators, want exactly one of exact, prefix, suffix, regex and absent", operators)
+	}
+	if m.Regex != "" {
+		if _, err := regexp.Compile(m.Regex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringMatch returns the StringMatch of the operator of the HeaderMatch, which is
+// empty for Absent, i.e. matches the presence of the header.
+func (m HeaderMatch) stringMatch() *istiov1beta1.StringMatch {
+	switch {
+	case m.Exact != "":
+		return &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Exact{Exact: m.Exact}}
+	case m.Prefix != "":
+		return &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: m.Prefix}}
+	case m.Suffix != "":
+		// Istio has no suffix operator.
+		return &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Regex{Regex: ".*" + regexp.QuoteMeta(m.Suffix)}}
+	case m.Regex != "":
+		return &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Regex{Regex: m.Regex}}
+	default:
+		return &istiov1beta1.StringMatch{}
+	}
+}
+
+// ParseRoutingRules returns the RoutingRules of the Ingress.
+func ParseRoutingRules(ing *v1alpha1.Ingress) ([]RoutingRule, error) {
+	v := ing.Annotations[RoutingRulesAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var rules []RoutingRule
+	if err := yaml.UnmarshalStrict([]byte(v), &rules); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", RoutingRulesAnnotationKey, err)
+	}
+
+	backends := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				backends.Insert(split.ServiceName)
+			}
+		}
+	}
+	for i, rule := range rules {
+		if len(rule.Headers) == 0 && len(rule.HeaderMatches) == 0 && len(rule.Claims) == 0 {
+			return nil, fmt.Errorf("invalid %s annotation: rule %d matches neither headers nor claims", RoutingRulesAnnotationKey, i)
+		}
+		for name, match := range rule.HeaderMatches {
+			if _, ok := rule.Headers[name]; ok {
+				return nil, fmt.Errorf("invalid %s annotation: rule %d matches the header %q in both headers and headerMatches", RoutingRulesAnnotationKey, i, name)
+			}
+			if err := match.validate(); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: rule %d header %q: %w", RoutingRulesAnnotationKey, i, name, err)
+			}
+		}
+		if !backends.Has(rule.ServiceName) {
+			return nil, fmt.Errorf("invalid %s annotation: rule %d routes to %q which is not a backend of the Ingress", RoutingRulesAnnotationKey, i, rule.ServiceName)
+		}
+	}
+	return rules, nil
+}
+
+// WithRoutingRules adds a route for each of the given RoutingRules before every HTTP route
+// of the gateway VirtualService splitting the requests to the backend of the rule. The
+// added routes match the requests of the original one which carry the headers and claims
+// of the rule, and lack its absent headers, and send all of them to that backend.
+func WithRoutingRules(vs *v1beta1.VirtualService, namespace string, rules []RoutingRule) {
+	if len(rules) == 0 {
+		return
+	}
+
+	routes := make([]*istiov1beta1.HTTPRoute, 0, len(vs.Spec.Http))
+	for _, route := range vs.Spec.Http {
+		for _, rule := range rules {
+			host := network.GetServiceHostname(rule.ServiceName, namespace)
+			for _, dest := range route.Route {
+				if dest.Destination.GetHost() != host {
+					continue
+				}
+				ruleRoute := route.DeepCopy()
+				for _, match := range ruleRoute.Match {
+					if match.Headers == nil {
+						match.Headers = make(map[string]*istiov1beta1.StringMatch, len(rule.Headers)+len(rule.HeaderMatches)+len(rule.Claims))
+					}
+					for k, v := range rule.Headers {
+						match.Headers[k] = &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Exact{Exact: v}}
+					}
+					for k, v := range rule.HeaderMatches {
+						if !v.Absent {
+							match.Headers[k] = v.stringMatch()
+							continue
+						}
+						if match.WithoutHeaders == nil {
+							match.WithoutHeaders = make(map[string]*istiov1beta1.StringMatch, len(rule.HeaderMatches))
+						}
+						match.WithoutHeaders[k] = v.stringMatch()
+					}
+					for k, v := range rule.Claims {
+						match.Headers[jwtClaimHeaderPrefix+k] = &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Exact{Exact: v}}
+					}
+				}
+				sent := dest.DeepCopy()
+				sent.Weight = 100
+				ruleRoute.Route = []*istiov1beta1.HTTPRouteDestination{sent}
+				routes = append(routes, ruleRoute)
+				break
+			}
+		}
+		routes = append(routes, route)
+	}
+	vs.Spec.Http = routes
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
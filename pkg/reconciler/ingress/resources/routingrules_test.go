@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseRoutingRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       []RoutingRule
+		wantErr    bool
+	}{{
+		name: "no rules",
+	}, {
+		name: "header and claim rules",
+		annotation: `
+- headers:
+    x-plan: premium
+  serviceName: premium-service
+- claims:
+    tenant: acme
+  serviceName: premium-service`,
+		want: []RoutingRule{{
+			Headers:     map[string]string{"x-plan": "premium"},
+			ServiceName: "premium-service",
+		}, {
+			Claims:      map[string]string{"tenant": "acme"},
+			ServiceName: "premium-service",
+		}},
+	}, {
+		name: "header matches",
+		annotation: `
+- headerMatches:
+    x-plan: {prefix: premium-}
+    x-beta: {absent: true}
+  serviceName: premium-service`,
+		want: []RoutingRule{{
+			HeaderMatches: map[string]HeaderMatch{
+				"x-plan": {Prefix: "premium-"},
+				"x-beta": {Absent: true},
+			},
+			ServiceName: "premium-service",
+		}},
+	}, {
+		name:       "header match without operator",
+		annotation: "- headerMatches: {x-plan: {}}\n  serviceName: premium-service",
+		wantErr:    true,
+	}, {
+		name:       "header match with several operators",
+		annotation: "- headerMatches: {x-plan: {prefix: a, suffix: b}}\n  serviceName: premium-service",
+		wantErr:    true,
+	}, {
+		name:       "header match with invalid regex",
+		annotation: "- headerMatches: {x-plan: {regex: '(premium'}}\n  serviceName: premium-service",
+		wantErr:    true,
+	}, {
+		name:       "header in headers and header matches",
+		annotation: "- headers: {x-plan: premium}\n  headerMatches: {x-plan: {prefix: premium}}\n  serviceName: premium-service",
+		wantErr:    true,
+	}, {
+		name:       "invalid yaml",
+		annotation: "- headers: [",
+		wantErr:    true,
+	}, {
+		name:       "unknown field",
+		annotation: "- header: {x-plan: premium}\n  serviceName: premium-service",
+		wantErr:    true,
+	}, {
+		name:       "no match",
+		annotation: "- serviceName: premium-service",
+		wantErr:    true,
+	}, {
+		name:       "unknown backend",
+		annotation: "- headers: {x-plan: premium}\n  serviceName: other-service",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{RoutingRulesAnnotationKey: tt.annotation},
+				},
+				Spec: v1alpha1.IngressSpec{
+					Rules: []v1alpha1.IngressRule{{
+						HTTP: &v1alpha1.HTTPIngressRuleValue{
+							Paths: []v1alpha1.HTTPIngressPath{{
+								Splits: []v1alpha1.IngressBackendSplit{{
+									IngressBackend: v1alpha1.IngressBackend{ServiceName: "premium-service"},
+								}},
+							}},
+						},
+					}},
+				},
+			}
+			got, err := ParseRoutingRules(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoutingRules() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("Unexpected rules (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithRoutingRules(t *testing.T) {
+	split := func(service string, weight int32) *istiov1beta1.HTTPRouteDestination {
+		return &istiov1beta1.HTTPRouteDestination{
+			Destination: &istiov1beta1.Destination{
+				Host: service + ".test-ns.svc.cluster.local",
+				Port: &istiov1beta1.PortSelector{Number: 80},
+			},
+			Weight: weight,
+		}
+	}
+	match := &istiov1beta1.HTTPMatchRequest{
+		Gateways:  []string{"knative-serving/knative-ingress-gateway"},
+		Authority: &istiov1beta1.StringMatch{MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: "foo.example.com"}},
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Match: []*istiov1beta1.HTTPMatchRequest{match},
+				Route: []*istiov1beta1.HTTPRouteDestination{split("stable", 90), split("premium", 10)},
+			}},
+		},
+	}
+
+	WithRoutingRules(vs, "test-ns", []RoutingRule{{
+		Headers:     map[string]string{"x-plan": "premium"},
+		Claims:      map[string]string{"tenant": "acme"},
+		ServiceName: "premium",
+	}})
+
+	want := []*istiov1beta1.HTTPRoute{{
+		Match: []*istiov1beta1.HTTPMatchRequest{{
+			Gateways:  match.Gateways,
+			Authority: match.Authority,
+			Headers: map[string]*istiov1beta1.StringMatch{
+				"x-plan":                      {MatchType: &istiov1beta1.StringMatch_Exact{Exact: "premium"}},
+				"@request.auth.claims.tenant": {MatchType: &istiov1beta1.StringMatch_Exact{Exact: "acme"}},
+			},
+		}},
+		Route: []*istiov1beta1.HTTPRouteDestination{split("premium", 100)},
+	}, {
+		Match: []*istiov1beta1.HTTPMatchRequest{match},
+		Route: []*istiov1beta1.HTTPRouteDestination{split("stable", 90), split("premium", 10)},
+	}}
+	if diff := cmp.Diff(want, vs.Spec.Http, protocmp.Transform()); diff != "" {
+		t.Error("Unexpected routes (-want +got):", diff)
+	}
+}
+
+func TestWithRoutingRulesHeaderMatches(t *testing.T) {
+	dest := &istiov1beta1.HTTPRouteDestination{
+		Destination: &istiov1beta1.Destination{Host: "premium.test-ns.svc.cluster.local"},
+		Weight:      100,
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Match: []*istiov1beta1.HTTPMatchRequest{{}},
+				Route: []*istiov1beta1.HTTPRouteDestination{dest},
+			}},
+		},
+	}
+
+	WithRoutingRules(vs, "test-ns", []RoutingRule{{
+		HeaderMatches: map[string]HeaderMatch{
+			"x-exact":  {Exact: "a"},
+			"x-prefix": {Prefix: "b"},
+			"x-suffix": {Suffix: ".c"},
+			"x-regex":  {Regex: "d+"},
+			"x-beta":   {Absent: true},
+		},
+		ServiceName: "premium",
+	}})
+
+	want := &istiov1beta1.HTTPMatchRequest{
+		Headers: map[string]*istiov1beta1.StringMatch{
+			"x-exact":  {MatchType: &istiov1beta1.StringMatch_Exact{Exact: "a"}},
+			"x-prefix": {MatchType: &istiov1beta1.StringMatch_Prefix{Prefix: "b"}},
+			"x-suffix": {MatchType: &istiov1beta1.StringMatch_Regex{Regex: `.*\.c`}},
+			"x-regex":  {MatchType: &istiov1beta1.StringMatch_Regex{Regex: "d+"}},
+		},
+		// The empty StringMatch matches the presence of the header.
+		WithoutHeaders: map[string]*istiov1beta1.StringMatch{"x-beta": {}},
+	}
+	if diff := cmp.Diff(want, vs.Spec.Http[0].Match[0], protocmp.Transform()); diff != "" {
+		t.Error("Unexpected match (-want +got):", diff)
+	}
+}
@@ -21,15 +21,15 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
-	"hash/adler32"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"knative.dev/net-istio/pkg/reconciler/fips"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
-	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/tracker"
 )
 
@@ -53,7 +53,13 @@ func GetSecrets(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility, se
 
 // MakeSecrets makes copies of the origin Secrets under the namespace of Istio gateway service.
 func MakeSecrets(ctx context.Context, originSecrets map[string]*corev1.Secret, ing *v1alpha1.Ingress) ([]*corev1.Secret, error) {
-	nameNamespaces, err := GetIngressGatewaySvcNameNamespaces(ctx, ing)
+	return MakeSecretsForVisibility(ctx, originSecrets, ing, v1alpha1.IngressVisibilityExternalIP)
+}
+
+// MakeSecretsForVisibility copies the certificates to the namespaces of the Services of the
+// gateways of the given visibility, so they can be consumed by them.
+func MakeSecretsForVisibility(ctx context.Context, originSecrets map[string]*corev1.Secret, ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility) ([]*corev1.Secret, error) {
+	nameNamespaces, err := GetGatewaySvcNameNamespacesForVisibility(ctx, ing, visibility)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +71,7 @@ func MakeSecrets(ctx context.Context, originSecrets map[string]*corev1.Secret, i
 				// as the origin namespace
 				continue
 			}
-			secrets = append(secrets, makeSecret(originSecret, targetSecret(originSecret, ing), meta.Namespace,
+			secrets = append(secrets, makeSecret(originSecret, names.MirroredSecret(originSecret, ing), meta.Namespace,
 				MakeTargetSecretLabels(originSecret.Name, originSecret.Namespace), MakeTargetSecretAnnotations(originSecret.Name)))
 		}
 	}
@@ -114,6 +120,16 @@ func makeSecret(originSecret *corev1.Secret, name, namespace string, labels, ann
 
 // MakeTargetSecretLabels returns the labels used in target secret.
 func MakeTargetSecretLabels(originSecretName, originSecretNamespace string) map[string]string {
+	return makeTargetSecretLabels(originSecretName, originSecretNamespace, fips.Checksum)
+}
+
+// MakeLegacyTargetSecretLabels returns the labels used in target secret before the FIPS-compatible
+// mode was enabled, which changes the checksum of the names that do not fit in a label.
+func MakeLegacyTargetSecretLabels(originSecretName, originSecretNamespace string) map[string]string {
+	return makeTargetSecretLabels(originSecretName, originSecretNamespace, fips.LegacyChecksum)
+}
+
+func makeTargetSecretLabels(originSecretName, originSecretNamespace string, checksum func([]byte) uint32) map[string]string {
 	labels := map[string]string{
 		networking.OriginSecretNamespaceLabelKey: originSecretNamespace,
 	}
@@ -122,7 +138,7 @@ func MakeTargetSecretLabels(originSecretName, originSecretNamespace string) map[
 	if len(originSecretName) <= dns1123LabelMaxLength {
 		labels[networking.OriginSecretNameLabelKey] = originSecretName
 	} else {
-		suffix := fmt.Sprint(adler32.Checksum([]byte(originSecretName)))
+		suffix := fmt.Sprint(checksum([]byte(originSecretName)))
 
 		maxPrefixLength := dns1123LabelMaxLength - len(suffix) - 1
 		prefix := originSecretName[0:maxPrefixLength]
@@ -158,11 +174,6 @@ func ExtractOriginSecretRef(secret *corev1.Secret) tracker.Reference {
 	return SecretRef(originSecretNamespace, originSecretName)
 }
 
-// targetSecret returns the name of the Secret that is copied from the origin Secret.
-func targetSecret(originSecret *corev1.Secret, accessor kmeta.OwnerRefable) string {
-	return fmt.Sprintf("%s-%s", accessor.GetObjectMeta().GetName(), originSecret.UID)
-}
-
 // SecretRef returns the Reference of a secret given the namespace and name of the secret.
 func SecretRef(namespace, name string) tracker.Reference {
 	gvk := corev1.SchemeGroupVersion.WithKind("Secret")
@@ -227,6 +238,39 @@ func isWildcardHost(domain string) (bool, error) {
 
 // GetHostsFromCertSecret gets cert hosts from cert secret.
 func GetHostsFromCertSecret(secret *corev1.Secret) ([]string, error) {
+	certData, err := parseCertSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(certData.DNSNames) == 0 {
+		return nil, fmt.Errorf("certificate should have DNS names, but it has %d", len(certData.DNSNames))
+	}
+	return certData.DNSNames, nil
+}
+
+// ValidateCertificateHosts returns an error naming the first host of the given Ingress TLS
+// that is not covered by the SANs of the certificate in the Secret it references. The
+// secrets are keyed as returned by GetSecrets.
+func ValidateCertificateHosts(ingressTLS []v1alpha1.IngressTLS, secrets map[string]*corev1.Secret) error {
+	for _, tls := range ingressTLS {
+		secret, ok := secrets[secretKey(tls)]
+		if !ok {
+			continue
+		}
+		certData, err := parseCertSecret(secret)
+		if err != nil {
+			return err
+		}
+		for _, host := range tls.Hosts {
+			if err := certData.VerifyHostname(host); err != nil {
+				return fmt.Errorf("the certificate of secret %s does not cover the host %s", secretKey(tls), host)
+			}
+		}
+	}
+	return nil
+}
+
+func parseCertSecret(secret *corev1.Secret) (*x509.Certificate, error) {
 	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
 	if block == nil {
 		return nil, fmt.Errorf("failed to decode PEM data for secret %s/%s", secret.Namespace, secret.Name)
@@ -236,8 +280,5 @@ func GetHostsFromCertSecret(secret *corev1.Secret) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse certificate for secret %s/%s: %w", secret.Namespace, secret.Name, err)
 	}
-	if len(certData.DNSNames) == 0 {
-		return nil, fmt.Errorf("certificate should have DNS names, but it has %d", len(certData.DNSNames))
-	}
-	return certData.DNSNames, nil
+	return certData, nil
 }
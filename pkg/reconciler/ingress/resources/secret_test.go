@@ -28,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/net-istio/pkg/reconciler/fips"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
@@ -378,6 +379,65 @@ func TestGetHostsFromCertSecret(t *testing.T) {
 	}
 }
 
+func TestValidateCertificateHosts(t *testing.T) {
+	secrets := map[string]*corev1.Secret{
+		"knative-serving/wildcard":    wildcardCert,
+		"knative-serving/nonwildcard": nonWildcardCert,
+		"knative-serving/invalid":     &testSecret,
+	}
+	cases := []struct {
+		name    string
+		tls     []v1alpha1.IngressTLS
+		wantErr string
+	}{{
+		name: "covered by the wildcard",
+		tls: []v1alpha1.IngressTLS{{
+			Hosts:           []string{"foo.example.com", "bar.example.com"},
+			SecretName:      "wildcard",
+			SecretNamespace: "knative-serving",
+		}},
+	}, {
+		name: "covered by the SAN",
+		tls: []v1alpha1.IngressTLS{{
+			Hosts:           []string{"test.example.com"},
+			SecretName:      "nonwildcard",
+			SecretNamespace: "knative-serving",
+		}},
+	}, {
+		name: "not covered by the wildcard",
+		tls: []v1alpha1.IngressTLS{{
+			Hosts:           []string{"foo.bar.example.com"},
+			SecretName:      "wildcard",
+			SecretNamespace: "knative-serving",
+		}},
+		wantErr: "the certificate of secret knative-serving/wildcard does not cover the host foo.bar.example.com",
+	}, {
+		name: "one host not covered",
+		tls: []v1alpha1.IngressTLS{{
+			Hosts:           []string{"test.example.com", "other.example.com"},
+			SecretName:      "nonwildcard",
+			SecretNamespace: "knative-serving",
+		}},
+		wantErr: "the certificate of secret knative-serving/nonwildcard does not cover the host other.example.com",
+	}, {
+		name: "invalid cert",
+		tls: []v1alpha1.IngressTLS{{
+			Hosts:           []string{"test.example.com"},
+			SecretName:      "invalid",
+			SecretNamespace: "knative-serving",
+		}},
+		wantErr: "failed to decode PEM data for secret knative-serving/secret0",
+	}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateCertificateHosts(c.tls, secrets)
+			if got := fmt.Sprint(err); (c.wantErr == "" && err != nil) || (c.wantErr != "" && got != c.wantErr) {
+				t.Errorf("ValidateCertificateHosts() = %v, want %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
 func TestMakeTargetSecretLabels(t *testing.T) {
 	cases := []struct {
 		namespace string
@@ -406,6 +466,26 @@ func TestMakeTargetSecretLabels(t *testing.T) {
 	}
 }
 
+func TestMakeTargetSecretLabelsFIPS(t *testing.T) {
+	t.Setenv(fips.EnableFIPSModeEnv, "true")
+	name := "a-really-long-secret-name-that-exceeds-a-length-of-63-characters"
+
+	legacy := MakeLegacyTargetSecretLabels(name, "a-namespace")
+	if got, want := legacy[networking.OriginSecretNameLabelKey], "a-really-long-secret-name-that-exceeds-a-length-of-63--16521092"; got != want {
+		t.Errorf("Legacy origin secret name label = %q, want %q", got, want)
+	}
+
+	got := MakeTargetSecretLabels(name, "a-namespace")[networking.OriginSecretNameLabelKey]
+	if got == legacy[networking.OriginSecretNameLabelKey] || len(got) > dns1123LabelMaxLength {
+		t.Errorf("Origin secret name label = %q, want a label different from the legacy one", got)
+	}
+
+	// Short names are not hashed, so they are the same in both schemes.
+	if diff := cmp.Diff(MakeLegacyTargetSecretLabels("a-secret", "a-namespace"), MakeTargetSecretLabels("a-secret", "a-namespace")); diff != "" {
+		t.Error("Unexpected labels (-legacy, +got):", diff)
+	}
+}
+
 func TestMakeTargetSecretAnnotions(t *testing.T) {
 	cases := []struct {
 		name string
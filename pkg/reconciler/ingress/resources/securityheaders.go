@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+// SecurityHeadersAnnotationKey is the annotation overriding whether the routes of the
+// Ingress set the security response headers.
+const SecurityHeadersAnnotationKey = "networking.knative.dev/security-headers"
+
+// SecurityResponseHeaders are the response headers set by the routes of the Ingresses
+// for which security headers are enabled.
+var SecurityResponseHeaders = map[string]string{
+	"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+	"X-Content-Type-Options":    "nosniff",
+	"X-Frame-Options":           "DENY",
+	"Referrer-Policy":           "strict-origin-when-cross-origin",
+}
+
+// SecurityHeadersEnabled returns whether the routes of the Ingress set the security
+// response headers, given whether they are enabled by default.
+func SecurityHeadersEnabled(ing *v1alpha1.Ingress, enabledByDefault bool) (bool, error) {
+	v, ok := ing.Annotations[SecurityHeadersAnnotationKey]
+	if !ok {
+		return enabledByDefault, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation %q: %w", SecurityHeadersAnnotationKey, v, err)
+	}
+	return enabled, nil
+}
+
+// WithSecurityResponseHeaders sets the security response headers on all the HTTP routes
+// of the VirtualService.
+func WithSecurityResponseHeaders(vs *v1beta1.VirtualService) {
+	for _, route := range vs.Spec.Http {
+		if route.Headers == nil {
+			route.Headers = &istiov1beta1.Headers{}
+		}
+		if route.Headers.Response == nil {
+			route.Headers.Response = &istiov1beta1.Headers_HeaderOperations{}
+		}
+		if route.Headers.Response.Set == nil {
+			route.Headers.Response.Set = make(map[string]string, len(SecurityResponseHeaders))
+		}
+		for k, v := range SecurityResponseHeaders {
+			route.Headers.Response.Set[k] = v
+		}
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
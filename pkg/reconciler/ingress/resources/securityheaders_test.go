@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestSecurityHeadersEnabled(t *testing.T) {
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		enabledByDefault bool
+		want             bool
+		wantErr          bool
+	}{{
+		name: "disabled by default",
+	}, {
+		name:             "enabled by default",
+		enabledByDefault: true,
+		want:             true,
+	}, {
+		name:        "enabled by the annotation",
+		annotations: map[string]string{SecurityHeadersAnnotationKey: "true"},
+		want:        true,
+	}, {
+		name:             "disabled by the annotation",
+		annotations:      map[string]string{SecurityHeadersAnnotationKey: "false"},
+		enabledByDefault: true,
+	}, {
+		name:        "invalid annotation",
+		annotations: map[string]string{SecurityHeadersAnnotationKey: "sometimes"},
+		wantErr:     true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+			}
+			got, err := SecurityHeadersEnabled(ing, tt.enabledByDefault)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SecurityHeadersEnabled() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SecurityHeadersEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSecurityResponseHeaders(t *testing.T) {
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Headers: &istiov1beta1.Headers{
+					Request: &istiov1beta1.Headers_HeaderOperations{
+						Set: map[string]string{"K-Original-Host": "foo.example.com"},
+					},
+					Response: &istiov1beta1.Headers_HeaderOperations{
+						Set: map[string]string{"X-Frame-Options": "SAMEORIGIN", "Cache-Control": "no-store"},
+					},
+				},
+			}, {}},
+		},
+	}
+
+	WithSecurityResponseHeaders(vs)
+
+	want := map[string]string{"Cache-Control": "no-store"}
+	for k, v := range SecurityResponseHeaders {
+		want[k] = v
+	}
+	if diff := cmp.Diff(want, vs.Spec.Http[0].Headers.Response.Set); diff != "" {
+		t.Error("Unexpected response headers (-want +got):", diff)
+	}
+	if diff := cmp.Diff(SecurityResponseHeaders, vs.Spec.Http[1].Headers.Response.Set); diff != "" {
+		t.Error("Unexpected response headers (-want +got):", diff)
+	}
+	if got := vs.Spec.Http[0].Headers.Request.Set["K-Original-Host"]; got != "foo.example.com" {
+		t.Errorf("K-Original-Host = %q, want foo.example.com", got)
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/tracker"
+)
+
+// ServiceRef returns the tracker reference of the Service.
+func ServiceRef(namespace, name string) tracker.Reference {
+	apiVersion, kind := corev1.SchemeGroupVersion.WithKind("Service").ToAPIVersionAndKind()
+	return tracker.Reference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
+
+// ResolveServicePort returns the port of the Service which the given port of a split of an
+// Ingress designates, as the routes of the VirtualServices require: either the name of one
+// of its ports, or the target port of one of them. The other numbers are returned as they
+// are, e.g. for the Services without ports.
+func ResolveServicePort(svc *corev1.Service, port intstr.IntOrString) (int32, error) {
+	if port.Type == intstr.String {
+		for _, p := range svc.Spec.Ports {
+			if p.Name == port.StrVal {
+				return p.Port, nil
+			}
+		}
+		return 0, fmt.Errorf("the Service %s/%s has no port named %q", svc.Namespace, svc.Name, port.StrVal)
+	}
+
+	var targeting []int32
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port.IntVal {
+			return p.Port, nil
+		}
+		if p.TargetPort.Type == intstr.Int && p.TargetPort.IntVal == port.IntVal {
+			targeting = append(targeting, p.Port)
+		}
+	}
+	if len(targeting) == 1 {
+		return targeting[0], nil
+	}
+	return port.IntVal, nil
+}
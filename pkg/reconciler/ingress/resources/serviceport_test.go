@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestResolveServicePort(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "svc"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}, {
+				Name:       "metrics",
+				Port:       9090,
+				TargetPort: intstr.FromString("metrics"),
+			}, {
+				Name:       "grpc",
+				Port:       81,
+				TargetPort: intstr.FromInt(8081),
+			}, {
+				Name:       "grpc-alt",
+				Port:       82,
+				TargetPort: intstr.FromInt(8081),
+			}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		port    intstr.IntOrString
+		want    int32
+		wantErr bool
+	}{{
+		name: "named port",
+		port: intstr.FromString("metrics"),
+		want: 9090,
+	}, {
+		name:    "unknown named port",
+		port:    intstr.FromString("admin"),
+		wantErr: true,
+	}, {
+		name: "service port",
+		port: intstr.FromInt(80),
+		want: 80,
+	}, {
+		name: "target port",
+		port: intstr.FromInt(8080),
+		want: 80,
+	}, {
+		name: "ambiguous target port",
+		port: intstr.FromInt(8081),
+		want: 8081,
+	}, {
+		name: "unknown port",
+		port: intstr.FromInt(8443),
+		want: 8443,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveServicePort(svc, tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveServicePort() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveServicePort() = %d, want: %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	istiov1alpha1 "istio.io/api/telemetry/v1alpha1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/telemetry/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// AccessLoggingAnnotationKey is the annotation enabling ("true") or disabling
+	// ("false") the access logs of the backends of an Ingress.
+	AccessLoggingAnnotationKey = "networking.knative.dev/access-logging"
+
+	// TracingSamplingAnnotationKey is the annotation overriding the percentage
+	// (between 0 and 100) of requests to the backends of an Ingress being traced.
+	TracingSamplingAnnotationKey = "networking.knative.dev/tracing-sampling"
+
+	// BackendTracingSamplingAnnotationKey is the annotation holding the YAML map of the
+	// names of backend Services of an Ingress to the percentage of their requests being
+	// traced, overriding TracingSamplingAnnotationKey for them, e.g. to sample a busy
+	// service down and a service being debugged up.
+	BackendTracingSamplingAnnotationKey = "networking.knative.dev/backend-tracing-sampling"
+)
+
+// HasTelemetryAnnotations returns whether the Ingress overrides the observability
+// of its backends.
+func HasTelemetryAnnotations(ing *netv1alpha1.Ingress) bool {
+	_, logging := ing.Annotations[AccessLoggingAnnotationKey]
+	_, tracing := ing.Annotations[TracingSamplingAnnotationKey]
+	_, backendTracing := ing.Annotations[BackendTracingSamplingAnnotationKey]
+	return logging || tracing || backendTracing
+}
+
+// ParseBackendTracingSampling returns the tracing sampling percentages of the backend
+// Services of the Ingress which override the one of the Ingress.
+func ParseBackendTracingSampling(ing *netv1alpha1.Ingress) (map[string]float64, error) {
+	v := ing.Annotations[BackendTracingSamplingAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	var percentages map[string]float64
+	if err := yaml.UnmarshalStrict([]byte(v), &percentages); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", BackendTracingSamplingAnnotationKey, err)
+	}
+
+	backends := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				backends.Insert(split.ServiceName)
+			}
+		}
+	}
+	for name, percentage := range percentages {
+		if !backends.Has(name) {
+			return nil, fmt.Errorf("invalid %s annotation: %q is not a backend of the Ingress", BackendTracingSamplingAnnotationKey, name)
+		}
+		if percentage < 0 || percentage > 100 {
+			return nil, fmt.Errorf("invalid %s annotation: the percentage of %q is %v, must be between 0 and 100", BackendTracingSamplingAnnotationKey, name, percentage)
+		}
+	}
+	return percentages, nil
+}
+
+// tracingSampling returns the percentage of the requests to the given backend Service
+// being traced, and whether the Ingress overrides it at all.
+func tracingSampling(ing *netv1alpha1.Ingress, svcName string) (float64, bool, error) {
+	overrides, err := ParseBackendTracingSampling(ing)
+	if err != nil {
+		return 0, false, err
+	}
+	if percentage, ok := overrides[svcName]; ok {
+		return percentage, true, nil
+	}
+	v, ok := ing.Annotations[TracingSamplingAnnotationKey]
+	if !ok {
+		return 0, false, nil
+	}
+	percentage, err := strconv.ParseFloat(v, 64)
+	if err != nil || percentage < 0 || percentage > 100 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q: must be a percentage between 0 and 100", TracingSamplingAnnotationKey, v)
+	}
+	return percentage, true, nil
+}
+
+// MakeTelemetry creates a Telemetry applying the access logging and tracing
+// settings of the Ingress annotations to the workloads behind the given Service.
+// Nil is returned if the Ingress has no such annotations for the Service, or if the
+// workloads of the Service cannot be selected.
+func MakeTelemetry(ing *netv1alpha1.Ingress, svc *corev1.Service) (*v1alpha1.Telemetry, error) {
+	if !HasTelemetryAnnotations(ing) {
+		return nil, nil
+	}
+	matchLabels := backendWorkloadLabels(svc)
+	if matchLabels == nil {
+		return nil, nil
+	}
+
+	t := &v1alpha1.Telemetry{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            names.Telemetry(ing, svc.Name),
+			Namespace:       svc.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+			Labels: map[string]string{
+				networking.IngressLabelKey: ing.Name,
+			},
+		},
+		Spec: istiov1alpha1.Telemetry{
+			Selector: &istiotypev1beta1.WorkloadSelector{
+				MatchLabels: matchLabels,
+			},
+		},
+	}
+	// Only the requests received by the backends are affected, so that the
+	// settings do not leak into the calls they make to other services.
+	if v, ok := ing.Annotations[AccessLoggingAnnotationKey]; ok {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q: %w", AccessLoggingAnnotationKey, v, err)
+		}
+		t.Spec.AccessLogging = []*istiov1alpha1.AccessLogging{{
+			Match: &istiov1alpha1.AccessLogging_LogSelector{
+				Mode: istiov1alpha1.WorkloadMode_SERVER,
+			},
+			Disabled: wrapperspb.Bool(!enabled),
+		}}
+	}
+	percentage, ok, err := tracingSampling(ing, svc.Name)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		t.Spec.Tracing = []*istiov1alpha1.Tracing{{
+			Match: &istiov1alpha1.Tracing_TracingSelector{
+				Mode: istiov1alpha1.WorkloadMode_SERVER,
+			},
+			RandomSamplingPercentage: wrapperspb.Double(percentage),
+		}}
+	}
+	if t.Spec.AccessLogging == nil && t.Spec.Tracing == nil {
+		// Only other backends are overridden.
+		return nil, nil
+	}
+
+	t.Annotations = kaccessor.WithSpecHash(t.Annotations, &t.Spec)
+
+	return t, nil
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	istiov1alpha1 "istio.io/api/telemetry/v1alpha1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/telemetry/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+func TestMakeTelemetry(t *testing.T) {
+	revisionSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-service",
+			Namespace: "my-namespace",
+			Labels:    map[string]string{RevisionLabelKey: "my-revision"},
+		},
+	}
+	accessLogging := func(disabled bool) []*istiov1alpha1.AccessLogging {
+		return []*istiov1alpha1.AccessLogging{{
+			Match: &istiov1alpha1.AccessLogging_LogSelector{
+				Mode: istiov1alpha1.WorkloadMode_SERVER,
+			},
+			Disabled: wrapperspb.Bool(disabled),
+		}}
+	}
+	tracing := func(percentage float64) []*istiov1alpha1.Tracing {
+		return []*istiov1alpha1.Tracing{{
+			Match: &istiov1alpha1.Tracing_TracingSelector{
+				Mode: istiov1alpha1.WorkloadMode_SERVER,
+			},
+			RandomSamplingPercentage: wrapperspb.Double(percentage),
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		svc         *corev1.Service
+		want        *istiov1alpha1.Telemetry
+		wantErr     bool
+	}{{
+		name: "no annotations",
+		svc:  revisionSvc,
+	}, {
+		name:        "access logging enabled",
+		annotations: map[string]string{AccessLoggingAnnotationKey: "true"},
+		svc:         revisionSvc,
+		want: &istiov1alpha1.Telemetry{
+			AccessLogging: accessLogging(false),
+		},
+	}, {
+		name:        "access logging disabled",
+		annotations: map[string]string{AccessLoggingAnnotationKey: "false"},
+		svc:         revisionSvc,
+		want: &istiov1alpha1.Telemetry{
+			AccessLogging: accessLogging(true),
+		},
+	}, {
+		name: "access logging and tracing sampling",
+		annotations: map[string]string{
+			AccessLoggingAnnotationKey:   "true",
+			TracingSamplingAnnotationKey: "12.5",
+		},
+		svc: revisionSvc,
+		want: &istiov1alpha1.Telemetry{
+			AccessLogging: accessLogging(false),
+			Tracing:       tracing(12.5),
+		},
+	}, {
+		name:        "service without selector and revision",
+		annotations: map[string]string{AccessLoggingAnnotationKey: "true"},
+		svc: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-service",
+				Namespace: "my-namespace",
+			},
+		},
+	}, {
+		name:        "invalid access logging",
+		annotations: map[string]string{AccessLoggingAnnotationKey: "sometimes"},
+		svc:         revisionSvc,
+		wantErr:     true,
+	}, {
+		name:        "invalid tracing sampling",
+		annotations: map[string]string{TracingSamplingAnnotationKey: "many"},
+		svc:         revisionSvc,
+		wantErr:     true,
+	}, {
+		name:        "tracing sampling out of range",
+		annotations: map[string]string{TracingSamplingAnnotationKey: "101"},
+		svc:         revisionSvc,
+		wantErr:     true,
+	}, {
+		name:        "backend tracing sampling",
+		annotations: map[string]string{BackendTracingSamplingAnnotationKey: "my-service: 0.5"},
+		svc:         revisionSvc,
+		want: &istiov1alpha1.Telemetry{
+			Tracing: tracing(0.5),
+		},
+	}, {
+		name: "backend tracing sampling overrides the one of the ingress",
+		annotations: map[string]string{
+			TracingSamplingAnnotationKey:        "10",
+			BackendTracingSamplingAnnotationKey: "my-service: 100\nother-service: 0",
+		},
+		svc: revisionSvc,
+		want: &istiov1alpha1.Telemetry{
+			Tracing: tracing(100),
+		},
+	}, {
+		name:        "backend tracing sampling of another backend",
+		annotations: map[string]string{BackendTracingSamplingAnnotationKey: "other-service: 50"},
+		svc:         revisionSvc,
+	}, {
+		name:        "invalid backend tracing sampling",
+		annotations: map[string]string{BackendTracingSamplingAnnotationKey: "my-service: many"},
+		svc:         revisionSvc,
+		wantErr:     true,
+	}, {
+		name:        "backend tracing sampling of an unknown service",
+		annotations: map[string]string{BackendTracingSamplingAnnotationKey: "unknown-service: 50"},
+		svc:         revisionSvc,
+		wantErr:     true,
+	}, {
+		name:        "backend tracing sampling out of range",
+		annotations: map[string]string{BackendTracingSamplingAnnotationKey: "my-service: -1"},
+		svc:         revisionSvc,
+		wantErr:     true,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := ing.DeepCopy()
+			ing.Annotations = tc.annotations
+			ing.Spec.Rules = []netv1alpha1.IngressRule{{
+				Hosts: []string{"my-service.my-namespace.svc.cluster.local"},
+				HTTP: &netv1alpha1.HTTPIngressRuleValue{
+					Paths: []netv1alpha1.HTTPIngressPath{{
+						Splits: []netv1alpha1.IngressBackendSplit{
+							{IngressBackend: netv1alpha1.IngressBackend{ServiceName: "my-service"}, Percent: 90},
+							{IngressBackend: netv1alpha1.IngressBackend{ServiceName: "other-service"}, Percent: 10},
+						},
+					}},
+				},
+			}}
+
+			got, err := MakeTelemetry(ing, tc.svc)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("MakeTelemetry() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.want == nil {
+				if got != nil {
+					t.Fatalf("MakeTelemetry() = %v, want nil", got)
+				}
+				return
+			}
+
+			expected := &v1alpha1.Telemetry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "my-ingress-my-service",
+					Namespace:       "my-namespace",
+					OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(ing)},
+					Labels: map[string]string{
+						networking.IngressLabelKey: "my-ingress",
+					},
+				},
+				Spec: istiov1alpha1.Telemetry{
+					Selector: &istiotypev1beta1.WorkloadSelector{
+						MatchLabels: map[string]string{RevisionLabelKey: "my-revision"},
+					},
+					AccessLogging: tc.want.AccessLogging,
+					Tracing:       tc.want.Tracing,
+				},
+			}
+			expected.Annotations = kaccessor.WithSpecHash(expected.Annotations, &expected.Spec)
+
+			if diff := cmp.Diff(expected, got, protocmp.Transform()); diff != "" {
+				t.Error("Unexpected Telemetry (-want +got):", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// TrafficTagsAnnotationKey is the annotation overriding whether the routes of the
+	// gateways tag the requests with the Ingress and the Knative Route that admitted them.
+	TrafficTagsAnnotationKey = "networking.knative.dev/traffic-tags"
+
+	// IngressTagHeaderName is the request header holding the namespace/name of the
+	// Ingress that admitted the request.
+	IngressTagHeaderName = "Knative-Ingress"
+
+	// RouteTagHeaderName is the request header holding the namespace/name of the Knative
+	// Route that admitted the request. The tag of the Route targeted by the request is
+	// sent by the routes of the tags in the Knative-Serving-Tag header.
+	RouteTagHeaderName = "Knative-Route"
+)
+
+// TrafficTagsEnabled returns whether the routes of the gateways tag the requests of
+// the Ingress, given whether they are enabled by default.
+func TrafficTagsEnabled(ing *v1alpha1.Ingress, enabledByDefault bool) (bool, error) {
+	v, ok := ing.Annotations[TrafficTagsAnnotationKey]
+	if !ok {
+		return enabledByDefault, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation %q: %w", TrafficTagsAnnotationKey, v, err)
+	}
+	return enabled, nil
+}
+
+// TrafficTagHeaders returns the request headers tagging the requests of the Ingress.
+func TrafficTagHeaders(ing *v1alpha1.Ingress) map[string]string {
+	headers := map[string]string{
+		IngressTagHeaderName: ing.Namespace + "/" + ing.Name,
+	}
+	if route := ing.Labels[RouteLabelKey]; route != "" {
+		namespace := ing.Labels[RouteNamespaceLabelKey]
+		if namespace == "" {
+			namespace = ing.Namespace
+		}
+		headers[RouteTagHeaderName] = namespace + "/" + route
+	}
+	return headers
+}
+
+// WithTrafficTags sets the request headers tagging the requests of the Ingress on all
+// the HTTP routes of the VirtualService, overriding the ones sent by the clients.
+func WithTrafficTags(vs *v1beta1.VirtualService, ing *v1alpha1.Ingress) {
+	headers := TrafficTagHeaders(ing)
+	for _, route := range vs.Spec.Http {
+		if route.Headers == nil {
+			route.Headers = &istiov1beta1.Headers{}
+		}
+		if route.Headers.Request == nil {
+			route.Headers.Request = &istiov1beta1.Headers_HeaderOperations{}
+		}
+		// The map of the headers the Ingress appends is shared by the VirtualServices.
+		set := maps.Clone(route.Headers.Request.Set)
+		if set == nil {
+			set = make(map[string]string, len(headers))
+		}
+		for k, v := range headers {
+			set[k] = v
+		}
+		route.Headers.Request.Set = set
+	}
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+)
+
+func TestTrafficTagsEnabled(t *testing.T) {
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		enabledByDefault bool
+		want             bool
+		wantErr          bool
+	}{{
+		name: "disabled by default",
+	}, {
+		name:             "enabled by default",
+		enabledByDefault: true,
+		want:             true,
+	}, {
+		name:        "enabled by the annotation",
+		annotations: map[string]string{TrafficTagsAnnotationKey: "true"},
+		want:        true,
+	}, {
+		name:             "disabled by the annotation",
+		annotations:      map[string]string{TrafficTagsAnnotationKey: "false"},
+		enabledByDefault: true,
+	}, {
+		name:        "invalid annotation",
+		annotations: map[string]string{TrafficTagsAnnotationKey: "sometimes"},
+		wantErr:     true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+			}
+			got, err := TrafficTagsEnabled(ing, tt.enabledByDefault)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TrafficTagsEnabled() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TrafficTagsEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrafficTagHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   map[string]string
+	}{{
+		name: "no route",
+		want: map[string]string{IngressTagHeaderName: "ns/ing"},
+	}, {
+		name:   "route",
+		labels: map[string]string{RouteLabelKey: "route", RouteNamespaceLabelKey: "route-ns"},
+		want:   map[string]string{IngressTagHeaderName: "ns/ing", RouteTagHeaderName: "route-ns/route"},
+	}, {
+		name:   "route without namespace",
+		labels: map[string]string{RouteLabelKey: "route"},
+		want:   map[string]string{IngressTagHeaderName: "ns/ing", RouteTagHeaderName: "ns/route"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ing", Labels: tt.labels},
+			}
+			if diff := cmp.Diff(tt.want, TrafficTagHeaders(ing)); diff != "" {
+				t.Error("Unexpected headers (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithTrafficTags(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ing"},
+	}
+	vs := &v1beta1.VirtualService{
+		Spec: istiov1beta1.VirtualService{
+			Http: []*istiov1beta1.HTTPRoute{{
+				Headers: &istiov1beta1.Headers{
+					Request: &istiov1beta1.Headers_HeaderOperations{
+						Set: map[string]string{"K-Original-Host": "foo.example.com", IngressTagHeaderName: "spoofed"},
+					},
+				},
+			}, {}},
+		},
+	}
+
+	WithTrafficTags(vs, ing)
+
+	want := map[string]string{"K-Original-Host": "foo.example.com", IngressTagHeaderName: "ns/ing"}
+	if diff := cmp.Diff(want, vs.Spec.Http[0].Headers.Request.Set); diff != "" {
+		t.Error("Unexpected request headers (-want +got):", diff)
+	}
+	if diff := cmp.Diff(TrafficTagHeaders(ing), vs.Spec.Http[1].Headers.Request.Set); diff != "" {
+		t.Error("Unexpected request headers (-want +got):", diff)
+	}
+	if vs.Annotations[kaccessor.SpecHashAnnotationKey] == "" {
+		t.Error("The spec hash annotation was not set")
+	}
+}
+
+func TestTranslateTrafficTags(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Istio: &config.Istio{
+			IngressGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeIngressGateway,
+				ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+			}},
+			LocalGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeLocalGateway,
+				ServiceURL: "knative-local-gateway.istio-system.svc.cluster.local",
+			}},
+			EnableTrafficTags: true,
+		},
+		Network: &netconfig.Config{},
+	})
+	localRule := translateTestRule("foo.ns.svc.cluster.local")
+	localRule.Visibility = v1alpha1.IngressVisibilityClusterLocal
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ing"},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{translateTestRule("foo.example.com"), localRule},
+		},
+	}
+
+	got, err := Translate(ctx, ing, nil, nil)
+	if err != nil {
+		t.Fatal("Translate() =", err)
+	}
+	if len(got.VirtualServices) != 2 {
+		t.Fatalf("VirtualServices = %d, want the ingress and the mesh ones", len(got.VirtualServices))
+	}
+	for _, vs := range got.VirtualServices {
+		// Only the gateways tag the requests.
+		_, tagged := vs.Spec.Http[0].GetHeaders().GetRequest().GetSet()[IngressTagHeaderName]
+		if want := vs.Name == names.IngressVirtualService(ing); tagged != want {
+			t.Errorf("VirtualService %s tagged = %v, want: %v", vs.Name, tagged, want)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+)
+
+// TranslationVersion is the version of the translation of the Ingresses into Istio resources.
+// It is bumped whenever the resources generated for the same inputs change, so that the tools
+// storing or comparing them can tell the generations apart.
+const TranslationVersion = 3
+
+// Translation holds the resources an Ingress is translated into.
+type Translation struct {
+	// Secrets are the copies of the certificate Secrets in the namespaces of the gateway
+	// services, by the visibility of the Ingress TLS they are referenced by.
+	Secrets map[v1alpha1.IngressVisibility][]*corev1.Secret
+
+	// Gateways are the Gateways owned by the Ingress, by visibility.
+	Gateways map[v1alpha1.IngressVisibility][]*v1beta1.Gateway
+
+	// WildcardGateways are the Gateways serving the wildcard certificates, shared by all the
+	// Ingresses referencing the same certificate. They are owned by the certificate Secrets.
+	WildcardGateways []*v1beta1.Gateway
+
+	// LegacyWildcardGateways are the names the WildcardGateways had before the
+	// FIPS-compatible mode was enabled, by the names of the WildcardGateways.
+	LegacyWildcardGateways map[string]string
+
+	// VirtualServices route the hosts of the Ingress, through the gateways and within the mesh.
+	VirtualServices []*v1beta1.VirtualService
+}
+
+// Translate translates the Ingress into the Istio resources implementing it. It neither reads
+// nor writes any resource: the configuration is read from the context (see config.ToContext),
+// gatewayServices are the Services of GetGatewaySvcNameNamespacesForVisibility, in the same
+// order, by the visibilities for which UsesGatewayServices, and secrets are the certificate
+// Secrets of the Ingress TLS of TLSVisibilities, by namespace/name.
+func Translate(ctx context.Context, ing *v1alpha1.Ingress, gatewayServices map[v1alpha1.IngressVisibility][]*corev1.Service, secrets map[string]*corev1.Secret) (*Translation, error) {
+	defaultGateways, err := GatewaysFromContext(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Translation{
+		Secrets:  map[v1alpha1.IngressVisibility][]*corev1.Secret{},
+		Gateways: map[v1alpha1.IngressVisibility][]*v1beta1.Gateway{},
+	}
+	gatewayNames := map[v1alpha1.IngressVisibility]sets.Set[string]{
+		v1alpha1.IngressVisibilityClusterLocal: sets.New[string](),
+		v1alpha1.IngressVisibilityExternalIP:   sets.New[string](),
+	}
+	for _, gateway := range defaultGateways[v1alpha1.IngressVisibilityClusterLocal] {
+		gatewayNames[v1alpha1.IngressVisibilityClusterLocal].Insert(gateway.QualifiedName())
+	}
+
+	externalServices := gatewayServices[v1alpha1.IngressVisibilityExternalIP]
+	visibilities := sets.New(TLSVisibilities(ctx, ing)...)
+	if visibilities.Has(v1alpha1.IngressVisibilityExternalIP) {
+		originSecrets, err := tlsSecrets(ing, v1alpha1.IngressVisibilityExternalIP, secrets)
+		if err != nil {
+			return nil, err
+		}
+		nonWildcardSecrets, wildcardSecrets, err := CategorizeSecrets(originSecrets)
+		if err != nil {
+			return nil, err
+		}
+		targetNonwildcardSecrets, err := MakeSecrets(ctx, nonWildcardSecrets, ing)
+		if err != nil {
+			return nil, err
+		}
+		targetWildcardSecrets, err := MakeWildcardSecrets(ctx, wildcardSecrets, ing)
+		if err != nil {
+			return nil, err
+		}
+		targetSecrets := make([]*corev1.Secret, 0, len(targetNonwildcardSecrets)+len(targetWildcardSecrets))
+		targetSecrets = append(targetSecrets, targetNonwildcardSecrets...)
+		targetSecrets = append(targetSecrets, targetWildcardSecrets...)
+		t.Secrets[v1alpha1.IngressVisibilityExternalIP] = targetSecrets
+
+		nonWildcardIngressTLS := GetNonWildcardIngressTLS(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP), nonWildcardSecrets)
+		t.Gateways[v1alpha1.IngressVisibilityExternalIP], err = MakeIngressTLSGateways(ing, v1alpha1.IngressVisibilityExternalIP,
+			nonWildcardIngressTLS, nonWildcardSecrets, externalServices)
+		if err != nil {
+			return nil, err
+		}
+
+		// For Ingress TLS referencing wildcard certificates, we reconcile a separate Gateway
+		// that will be shared by other Ingresses that reference the
+		// same wildcard host. We need to handle wildcard certificate specially because Istio does
+		// not fully support multiple TLS Servers (or Gateways) share the same certificate.
+		// https://istio.io/docs/ops/common-problems/network-issues/
+		t.WildcardGateways, err = MakeWildcardTLSGateways(wildcardSecrets, externalServices)
+		if err != nil {
+			return nil, err
+		}
+		t.LegacyWildcardGateways = LegacyWildcardGatewayNames(wildcardSecrets, externalServices)
+		gatewayNames[v1alpha1.IngressVisibilityExternalIP].Insert(GetQualifiedGatewayNames(t.WildcardGateways)...)
+	}
+
+	// The TLS of the cluster-local hosts is terminated on the local gateways, so that the clients
+	// outside of the mesh can reach them over HTTPS.
+	if visibilities.Has(v1alpha1.IngressVisibilityClusterLocal) {
+		originSecrets, err := tlsSecrets(ing, v1alpha1.IngressVisibilityClusterLocal, secrets)
+		if err != nil {
+			return nil, err
+		}
+		t.Secrets[v1alpha1.IngressVisibilityClusterLocal], err = MakeSecretsForVisibility(ctx, originSecrets, ing, v1alpha1.IngressVisibilityClusterLocal)
+		if err != nil {
+			return nil, err
+		}
+		t.Gateways[v1alpha1.IngressVisibilityClusterLocal], err = MakeIngressTLSGateways(ing, v1alpha1.IngressVisibilityClusterLocal,
+			ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityClusterLocal), originSecrets, gatewayServices[v1alpha1.IngressVisibilityClusterLocal])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := ParseHTTPOptions(ing); err != nil {
+		return nil, err
+	}
+	if ShouldReconcileHTTPServer(ing) {
+		httpServers := MakeHTTPServers(ing)
+		if externalGateways := t.Gateways[v1alpha1.IngressVisibilityExternalIP]; len(externalGateways) == 0 {
+			t.Gateways[v1alpha1.IngressVisibilityExternalIP] = MakeExternalIngressGateways(ing, httpServers, externalServices)
+		} else {
+			// add HTTP Server into ingressGateways.
+			for i := range externalGateways {
+				externalGateways[i].Spec.Servers = append(externalGateways[i].Spec.Servers, httpServers...)
+			}
+		}
+	} else {
+		// Otherwise, we fall back to the default global Gateways for HTTP behavior.
+		// We need this for the backward compatibility.
+		for _, gateway := range defaultGateways[v1alpha1.IngressVisibilityExternalIP] {
+			gatewayNames[v1alpha1.IngressVisibilityExternalIP].Insert(gateway.QualifiedName())
+		}
+	}
+	for visibility, gateways := range t.Gateways {
+		gatewayNames[visibility].Insert(GetQualifiedGatewayNames(gateways)...)
+	}
+
+	t.VirtualServices, err = MakeVirtualServices(ing, gatewayNames)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := MakeRetries(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+	mirrors, err := ParseMirrors(ing)
+	if err != nil {
+		return nil, err
+	}
+	cors, err := MakeCORSPolicy(ctx, ing)
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range t.VirtualServices {
+		// Before the routing rules, whose routes copy the retries, the mirrors and the CORS
+		// policies of the routes.
+		WithRetries(vs, retries)
+		WithGRPCRetries(vs)
+		WithMirrors(vs, ing.Namespace, mirrors)
+		WithCORSPolicy(vs, cors)
+	}
+	securityHeaders, err := SecurityHeadersEnabled(ing, config.FromContext(ctx).Istio.EnableSecurityHeaders)
+	if err != nil {
+		return nil, err
+	}
+	if securityHeaders {
+		for _, vs := range t.VirtualServices {
+			WithSecurityResponseHeaders(vs)
+		}
+	}
+	if DomainMappingsEncrypted(config.FromContext(ctx)) {
+		for _, vs := range t.VirtualServices {
+			WithDomainMappingEncryption(vs)
+		}
+	}
+	if config.FromContext(ctx).Istio.EnableNamespaceScopedMesh {
+		for _, vs := range t.VirtualServices {
+			if vs.Name == names.MeshVirtualService(ing) {
+				WithNamespaceScope(vs)
+			}
+		}
+	}
+	strippedPrefixes, err := ParseStrippedPathPrefixes(ing)
+	if err != nil {
+		return nil, err
+	}
+	if len(strippedPrefixes) > 0 {
+		// Before the routing rules, whose routes copy the rewrites of the routes.
+		for _, vs := range t.VirtualServices {
+			WithStrippedPathPrefixes(vs, strippedPrefixes)
+		}
+	}
+	requestHeaders, err := ParseRequestHeaderOperations(ing)
+	if err != nil {
+		return nil, err
+	}
+	routingRules, err := ParseRoutingRules(ing)
+	if err != nil {
+		return nil, err
+	}
+	cookieRoutes, err := ParseCookieRoutes(ing)
+	if err != nil {
+		return nil, err
+	}
+	trafficTags, err := TrafficTagsEnabled(ing, config.FromContext(ctx).Istio.EnableTrafficTags)
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range t.VirtualServices {
+		// Claims can only be matched on the gateways, which validate the JWTs, and the
+		// request headers are operated on at the edge.
+		if vs.Name == names.IngressVirtualService(ing) {
+			// Before the routing rules, whose routes copy the headers of the routes.
+			WithRequestHeaderOperations(vs, ing.Namespace, requestHeaders)
+			if config.FromContext(ctx).Istio.GRPCTimeoutHeader == config.GRPCTimeoutHeaderStrip {
+				// After the operations of the Ingress, which may set the header.
+				WithoutGRPCTimeoutHeader(vs)
+			}
+			if trafficTags {
+				// After the operations of the Ingress, which can't override the tags.
+				WithTrafficTags(vs, ing)
+			}
+			// Before the routing rules, whose routes take precedence over the cookies.
+			WithCookieRoutes(vs, ing.Namespace, cookieRoutes)
+			WithRoutingRules(vs, ing.Namespace, routingRules)
+		}
+	}
+	return t, nil
+}
+
+// TLSVisibilities returns the visibilities whose Ingress TLS are served by the Gateways of the
+// Ingress, in the order they are translated.
+func TLSVisibilities(ctx context.Context, ing *v1alpha1.Ingress) []v1alpha1.IngressVisibility {
+	var visibilities []v1alpha1.IngressVisibility
+	if ShouldReconcileExternalDomainTLS(ing) {
+		visibilities = append(visibilities, v1alpha1.IngressVisibilityExternalIP)
+	}
+	if config.FromContext(ctx).Network.ClusterLocalDomainTLS == netconfig.EncryptionEnabled && ShouldReconcileClusterLocalDomainTLS(ing) {
+		visibilities = append(visibilities, v1alpha1.IngressVisibilityClusterLocal)
+	}
+	return visibilities
+}
+
+// UsesGatewayServices returns whether Translate needs the Services of the gateways of the given
+// visibility of the Ingress, which may otherwise be missing.
+func UsesGatewayServices(ctx context.Context, ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility) bool {
+	if visibility == v1alpha1.IngressVisibilityExternalIP && ShouldReconcileHTTPServer(ing) {
+		return true
+	}
+	return slices.Contains(TLSVisibilities(ctx, ing), visibility)
+}
+
+// ShouldReconcileExternalDomainTLS returns whether the Ingress has TLS for its public hosts.
+func ShouldReconcileExternalDomainTLS(ing *v1alpha1.Ingress) bool {
+	return IsIngressPublic(ing) && len(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP)) > 0
+}
+
+// ShouldReconcileClusterLocalDomainTLS returns whether the Ingress has TLS for its cluster-local hosts.
+func ShouldReconcileClusterLocalDomainTLS(ing *v1alpha1.Ingress) bool {
+	return len(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityClusterLocal)) > 0
+}
+
+// ShouldReconcileHTTPServer returns whether the Ingress gets its own HTTP server, rather than
+// relying on the shared gateways.
+func ShouldReconcileHTTPServer(ing *v1alpha1.Ingress) bool {
+	// We will create an Ingress specific HTTPServer when
+	// 1. external-domain-tls is enabled as in this case users want us to fully handle the TLS/HTTP behavior,
+	// 2. HTTPOption is set to Redirected for some hosts, see HTTPOptionsAnnotationKey, as we don't have
+	//    default HTTP server supporting HTTP redirection.
+	return IsIngressPublic(ing) && (len(httpOptionHosts(ing)[v1alpha1.HTTPOptionRedirected]) > 0 || len(ing.GetIngressTLSForVisibility(v1alpha1.IngressVisibilityExternalIP)) > 0)
+}
+
+// IsIngressPublic returns whether the Ingress has rules with the external visibility.
+func IsIngressPublic(ing *v1alpha1.Ingress) bool {
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility == v1alpha1.IngressVisibilityExternalIP {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicHosts returns the sorted hosts of the rules of the Ingress with the external visibility.
+func PublicHosts(ing *v1alpha1.Ingress) []string {
+	hosts := sets.New[string]()
+	for _, rule := range ing.Spec.Rules {
+		if rule.Visibility == v1alpha1.IngressVisibilityExternalIP {
+			hosts.Insert(rule.Hosts...)
+		}
+	}
+	return sets.List(hosts)
+}
+
+// tlsSecrets returns the Secrets of the Ingress TLS of the visibility, by namespace/name.
+func tlsSecrets(ing *v1alpha1.Ingress, visibility v1alpha1.IngressVisibility, secrets map[string]*corev1.Secret) (map[string]*corev1.Secret, error) {
+	result := map[string]*corev1.Secret{}
+	for _, tls := range ing.GetIngressTLSForVisibility(visibility) {
+		key := secretKey(tls)
+		secret, ok := secrets[key]
+		if !ok {
+			return nil, fmt.Errorf("missing the secret %s referenced by the Ingress TLS", key)
+		}
+		result[key] = secret
+	}
+	return result, nil
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+)
+
+func TestTranslate(t *testing.T) {
+	wildcardCert, err := GenerateCertificate([]string{"*.example.com"}, "wildcard", "ns")
+	if err != nil {
+		t.Fatal("GenerateCertificate() =", err)
+	}
+	cert, err := GenerateCertificate([]string{"bar.example.org"}, "cert", "ns")
+	if err != nil {
+		t.Fatal("GenerateCertificate() =", err)
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-ingressgateway"},
+		Spec:       corev1.ServiceSpec{Selector: selector},
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Istio: &config.Istio{
+			IngressGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeIngressGateway,
+				ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+			}},
+			LocalGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeLocalGateway,
+				ServiceURL: "knative-local-gateway.istio-system.svc.cluster.local",
+			}},
+		},
+		Network: &netconfig.Config{},
+	})
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ing"},
+		Spec: v1alpha1.IngressSpec{
+			// The TLS of a visibility must cover all the hosts of one of its rules.
+			Rules: []v1alpha1.IngressRule{
+				translateTestRule("foo.example.com"),
+				translateTestRule("bar.example.org"),
+			},
+			TLS: []v1alpha1.IngressTLS{{
+				Hosts:           []string{"foo.example.com"},
+				SecretNamespace: "ns",
+				SecretName:      "wildcard",
+			}, {
+				Hosts:           []string{"bar.example.org"},
+				SecretNamespace: "ns",
+				SecretName:      "cert",
+			}},
+		},
+	}
+
+	if !UsesGatewayServices(ctx, ing, v1alpha1.IngressVisibilityExternalIP) {
+		t.Fatal("UsesGatewayServices() = false, want true for an Ingress with TLS")
+	}
+	gatewayServices := map[v1alpha1.IngressVisibility][]*corev1.Service{v1alpha1.IngressVisibilityExternalIP: {svc}}
+	if _, err := Translate(ctx, ing, gatewayServices, map[string]*corev1.Secret{"ns/cert": cert}); err == nil {
+		t.Error("Translate() = nil, want an error for the missing wildcard secret")
+	}
+	got, err := Translate(ctx, ing, gatewayServices, map[string]*corev1.Secret{
+		"ns/wildcard": wildcardCert,
+		"ns/cert":     cert,
+	})
+	if err != nil {
+		t.Fatal("Translate() =", err)
+	}
+
+	var secrets []string
+	for _, secret := range got.Secrets[v1alpha1.IngressVisibilityExternalIP] {
+		secrets = append(secrets, secret.Namespace+"/"+secret.Name)
+	}
+	if want := sets.List(sets.New("istio-system/ing-", "istio-system/ns--wildcard-wildcard")); !cmp.Equal(sets.List(sets.New(secrets...)), want) {
+		t.Errorf("Secrets = %v, want: %v", secrets, want)
+	}
+	ingressGateway := "ns/" + GatewayName(ing, v1alpha1.IngressVisibilityExternalIP, svc)
+	if got, want := GetQualifiedGatewayNames(got.Gateways[v1alpha1.IngressVisibilityExternalIP]), []string{ingressGateway}; !cmp.Equal(got, want) {
+		t.Errorf("Gateways = %v, want: %v", got, want)
+	}
+	// The TLS server of the non wildcard certificate, and the HTTP server.
+	if got := len(got.Gateways[v1alpha1.IngressVisibilityExternalIP][0].Spec.Servers); got != 2 {
+		t.Errorf("Gateway servers = %d, want: 2", got)
+	}
+	wildcardGateway := "ns/" + WildcardGatewayName("wildcard", "istio-system", "istio-ingressgateway")
+	if got, want := GetQualifiedGatewayNames(got.WildcardGateways), []string{wildcardGateway}; !cmp.Equal(got, want) {
+		t.Errorf("WildcardGateways = %v, want: %v", got, want)
+	}
+	for _, vs := range got.VirtualServices {
+		if vs.Name != names.IngressVirtualService(ing) {
+			continue
+		}
+		// The Ingress is not exposed on the shared gateway, which has no HTTP server redirecting to TLS.
+		if got, want := sets.List(sets.New(vs.Spec.Gateways...)), []string{ingressGateway, wildcardGateway}; !cmp.Equal(got, want) {
+			t.Errorf("VirtualService gateways = %v, want: %v", got, want)
+		}
+	}
+}
+
+func TestTranslateClusterLocalTLS(t *testing.T) {
+	cert, err := GenerateCertificate([]string{"foo.ns.svc.cluster.local"}, "cert", "ns")
+	if err != nil {
+		t.Fatal("GenerateCertificate() =", err)
+	}
+	localSelector := map[string]string{"istio": "knative-local-gateway"}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "knative-local-gateway"},
+		Spec:       corev1.ServiceSpec{Selector: localSelector},
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Istio: &config.Istio{
+			IngressGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeIngressGateway,
+				ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+			}},
+			LocalGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeLocalGateway,
+				ServiceURL: "knative-local-gateway.istio-system.svc.cluster.local",
+			}},
+		},
+		Network: &netconfig.Config{ClusterLocalDomainTLS: netconfig.EncryptionEnabled},
+	})
+	rule := translateTestRule("foo.ns.svc.cluster.local")
+	rule.Visibility = v1alpha1.IngressVisibilityClusterLocal
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "ing"},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{rule},
+			TLS: []v1alpha1.IngressTLS{{
+				Hosts:           []string{"foo.ns.svc.cluster.local"},
+				SecretNamespace: "ns",
+				SecretName:      "cert",
+			}},
+		},
+	}
+
+	if UsesGatewayServices(ctx, ing, v1alpha1.IngressVisibilityExternalIP) {
+		t.Error("UsesGatewayServices(ExternalIP) = true, want false for a cluster-local Ingress")
+	}
+	if !UsesGatewayServices(ctx, ing, v1alpha1.IngressVisibilityClusterLocal) {
+		t.Fatal("UsesGatewayServices(ClusterLocal) = false, want true for a cluster-local Ingress with TLS")
+	}
+	got, err := Translate(ctx, ing, map[v1alpha1.IngressVisibility][]*corev1.Service{
+		v1alpha1.IngressVisibilityClusterLocal: {svc},
+	}, map[string]*corev1.Secret{"ns/cert": cert})
+	if err != nil {
+		t.Fatal("Translate() =", err)
+	}
+
+	// The certificate is copied for the local gateway, which terminates the TLS.
+	secrets := got.Secrets[v1alpha1.IngressVisibilityClusterLocal]
+	if len(secrets) != 1 || secrets[0].Namespace != "istio-system" {
+		t.Errorf("Secrets = %v, want a copy in istio-system", secrets)
+	}
+	gateways := got.Gateways[v1alpha1.IngressVisibilityClusterLocal]
+	if len(gateways) != 1 {
+		t.Fatalf("Gateways = %d, want: 1", len(gateways))
+	}
+	gateway := gateways[0]
+	if got, want := gateway.Name, GatewayName(ing, v1alpha1.IngressVisibilityClusterLocal, svc); got != want {
+		t.Errorf("Gateway name = %q, want: %q", got, want)
+	}
+	if !cmp.Equal(gateway.Spec.Selector, localSelector) {
+		t.Errorf("Gateway selector = %v, want: %v", gateway.Spec.Selector, localSelector)
+	}
+	if len(gateway.Spec.Servers) != 1 || gateway.Spec.Servers[0].Port.Number != ClusterLocalGatewayHTTPSPort {
+		t.Errorf("Gateway servers = %v, want a single server on port %d", gateway.Spec.Servers, ClusterLocalGatewayHTTPSPort)
+	}
+	for _, vs := range got.VirtualServices {
+		if vs.Name != names.IngressVirtualService(ing) {
+			continue
+		}
+		if want := "ns/" + gateway.Name; !sets.New(vs.Spec.Gateways...).Has(want) {
+			t.Errorf("VirtualService gateways = %v, want %s among them", vs.Spec.Gateways, want)
+		}
+	}
+}
+
+func translateTestRule(host string) v1alpha1.IngressRule {
+	return v1alpha1.IngressRule{
+		Hosts:      []string{host},
+		Visibility: v1alpha1.IngressVisibilityExternalIP,
+		HTTP: &v1alpha1.HTTPIngressRuleValue{
+			Paths: []v1alpha1.HTTPIngressPath{{
+				Splits: []v1alpha1.IngressBackendSplit{{
+					IngressBackend: v1alpha1.IngressBackend{ServiceNamespace: "ns", ServiceName: "svc"},
+				}},
+			}},
+		},
+	}
+}
+
+func TestUsesGatewayServices(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Istio:   &config.Istio{},
+		Network: &netconfig.Config{ClusterLocalDomainTLS: netconfig.EncryptionDisabled},
+	})
+	clusterLocal := &v1alpha1.Ingress{
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      []string{"foo.ns.svc.cluster.local"},
+				Visibility: v1alpha1.IngressVisibilityClusterLocal,
+			}},
+			TLS: []v1alpha1.IngressTLS{{
+				Hosts:           []string{"foo.ns.svc.cluster.local"},
+				SecretNamespace: "ns",
+				SecretName:      "cert",
+			}},
+		},
+	}
+	if UsesGatewayServices(ctx, clusterLocal, v1alpha1.IngressVisibilityClusterLocal) {
+		t.Error("UsesGatewayServices() = true, want false while cluster-local-domain-tls is disabled")
+	}
+	if got := TLSVisibilities(ctx, clusterLocal); len(got) != 0 {
+		t.Errorf("TLSVisibilities() = %v, want none", got)
+	}
+
+	redirected := &v1alpha1.Ingress{
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      []string{"foo.example.com"},
+				Visibility: v1alpha1.IngressVisibilityExternalIP,
+			}},
+			HTTPOption: v1alpha1.HTTPOptionRedirected,
+		},
+	}
+	if !UsesGatewayServices(ctx, redirected, v1alpha1.IngressVisibilityExternalIP) {
+		t.Error("UsesGatewayServices() = false, want true for an Ingress redirecting HTTP")
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// UpstreamConnectionAnnotationKey is the annotation holding the YAML UpstreamConnection
+// of the backends of the Ingress, e.g. for the services whose idle connections are closed
+// by a load balancer between them and the gateways. It applies to the DestinationRules
+// generated for system-internal-tls, and not to the backends of the DomainMappings.
+const UpstreamConnectionAnnotationKey = "networking.knative.dev/upstream-connection"
+
+// UpstreamConnection configures the connections of the gateways to the backends.
+type UpstreamConnection struct {
+	// ConnectTimeout is the timeout of the TCP connections, the one of Istio when zero.
+	ConnectTimeout metav1.Duration `json:"connectTimeout,omitempty"`
+	// TCPKeepalive enables the keepalive probes of the TCP connections.
+	TCPKeepalive *TCPKeepalive `json:"tcpKeepalive,omitempty"`
+	// H2UpgradePolicy is whether the HTTP/1.1 requests are upgraded to HTTP/2, one of
+	// DEFAULT, DO_NOT_UPGRADE and UPGRADE. When empty, they are upgraded for the Services
+	// with an http2 or h2c port.
+	H2UpgradePolicy string `json:"h2UpgradePolicy,omitempty"`
+}
+
+// TCPKeepalive configures the keepalive probes of the TCP connections, the settings of
+// the OS being used for the ones left zero.
+type TCPKeepalive struct {
+	// Time is how long a connection is idle before the probes are sent.
+	Time metav1.Duration `json:"time,omitempty"`
+	// Interval is the time between the probes.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// Probes is how many probes are unanswered before the connection is closed.
+	Probes uint32 `json:"probes,omitempty"`
+}
+
+// ParseUpstreamConnection returns the UpstreamConnection of the Ingress, nil when it has
+// none.
+func ParseUpstreamConnection(ing *v1alpha1.Ingress) (*UpstreamConnection, error) {
+	v := ing.Annotations[UpstreamConnectionAnnotationKey]
+	if v == "" {
+		return nil, nil
+	}
+	conn := &UpstreamConnection{}
+	if err := yaml.UnmarshalStrict([]byte(v), conn); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", UpstreamConnectionAnnotationKey, err)
+	}
+
+	if conn.ConnectTimeout.Duration < 0 {
+		return nil, fmt.Errorf("invalid %s annotation: connectTimeout must not be negative, was: %v", UpstreamConnectionAnnotationKey, conn.ConnectTimeout.Duration)
+	}
+	if keepalive := conn.TCPKeepalive; keepalive != nil {
+		if keepalive.Time.Duration < 0 {
+			return nil, fmt.Errorf("invalid %s annotation: tcpKeepalive.time must not be negative, was: %v", UpstreamConnectionAnnotationKey, keepalive.Time.Duration)
+		}
+		if keepalive.Interval.Duration < 0 {
+			return nil, fmt.Errorf("invalid %s annotation: tcpKeepalive.interval must not be negative, was: %v", UpstreamConnectionAnnotationKey, keepalive.Interval.Duration)
+		}
+	}
+	if conn.H2UpgradePolicy != "" {
+		if _, ok := istiov1beta1.ConnectionPoolSettings_HTTPSettings_H2UpgradePolicy_value[conn.H2UpgradePolicy]; !ok {
+			return nil, fmt.Errorf("invalid %s annotation: h2UpgradePolicy must be one of DEFAULT, DO_NOT_UPGRADE or UPGRADE, was: %q", UpstreamConnectionAnnotationKey, conn.H2UpgradePolicy)
+		}
+	}
+	return conn, nil
+}
+
+// WithUpstreamConnection sets the given UpstreamConnection on the connection pool of the
+// DestinationRule.
+func WithUpstreamConnection(dr *v1beta1.DestinationRule, conn *UpstreamConnection) {
+	if conn == nil || (conn.ConnectTimeout.Duration == 0 && conn.TCPKeepalive == nil && conn.H2UpgradePolicy == "") {
+		return
+	}
+
+	if dr.Spec.TrafficPolicy == nil {
+		dr.Spec.TrafficPolicy = &istiov1beta1.TrafficPolicy{}
+	}
+	if dr.Spec.TrafficPolicy.ConnectionPool == nil {
+		dr.Spec.TrafficPolicy.ConnectionPool = &istiov1beta1.ConnectionPoolSettings{}
+	}
+	pool := dr.Spec.TrafficPolicy.ConnectionPool
+	if conn.ConnectTimeout.Duration > 0 || conn.TCPKeepalive != nil {
+		pool.Tcp = &istiov1beta1.ConnectionPoolSettings_TCPSettings{}
+		if conn.ConnectTimeout.Duration > 0 {
+			pool.Tcp.ConnectTimeout = durationpb.New(conn.ConnectTimeout.Duration)
+		}
+		if keepalive := conn.TCPKeepalive; keepalive != nil {
+			pool.Tcp.TcpKeepalive = &istiov1beta1.ConnectionPoolSettings_TCPSettings_TcpKeepalive{
+				Probes: keepalive.Probes,
+			}
+			if keepalive.Time.Duration > 0 {
+				pool.Tcp.TcpKeepalive.Time = durationpb.New(keepalive.Time.Duration)
+			}
+			if keepalive.Interval.Duration > 0 {
+				pool.Tcp.TcpKeepalive.Interval = durationpb.New(keepalive.Interval.Duration)
+			}
+		}
+	}
+	if conn.H2UpgradePolicy != "" {
+		// Overrides the policy derived from the ports of the Service.
+		pool.Http = &istiov1beta1.ConnectionPoolSettings_HTTPSettings{
+			H2UpgradePolicy: istiov1beta1.ConnectionPoolSettings_HTTPSettings_H2UpgradePolicy(
+				istiov1beta1.ConnectionPoolSettings_HTTPSettings_H2UpgradePolicy_value[conn.H2UpgradePolicy]),
+		}
+	}
+	dr.Annotations = kaccessor.WithSpecHash(dr.Annotations, &dr.Spec)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseUpstreamConnection(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		want       *UpstreamConnection
+		wantErr    bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:       "connection",
+		annotation: "{connectTimeout: 2s, tcpKeepalive: {time: 1m, interval: 10s, probes: 3}, h2UpgradePolicy: DO_NOT_UPGRADE}",
+		want: &UpstreamConnection{
+			ConnectTimeout: metav1.Duration{Duration: 2 * time.Second},
+			TCPKeepalive: &TCPKeepalive{
+				Time:     metav1.Duration{Duration: time.Minute},
+				Interval: metav1.Duration{Duration: 10 * time.Second},
+				Probes:   3,
+			},
+			H2UpgradePolicy: "DO_NOT_UPGRADE",
+		},
+	}, {
+		name:       "keepalive with the settings of the OS",
+		annotation: "{tcpKeepalive: {}}",
+		want:       &UpstreamConnection{TCPKeepalive: &TCPKeepalive{}},
+	}, {
+		name:       "invalid yaml",
+		annotation: "{connectTimeout: [",
+		wantErr:    true,
+	}, {
+		name:       "unknown field",
+		annotation: "{idleTimeout: 1m}",
+		wantErr:    true,
+	}, {
+		name:       "negative connect timeout",
+		annotation: "{connectTimeout: -1s}",
+		wantErr:    true,
+	}, {
+		name:       "negative keepalive time",
+		annotation: "{tcpKeepalive: {time: -1s}}",
+		wantErr:    true,
+	}, {
+		name:       "negative keepalive interval",
+		annotation: "{tcpKeepalive: {interval: -1s}}",
+		wantErr:    true,
+	}, {
+		name:       "unknown h2 upgrade policy",
+		annotation: "{h2UpgradePolicy: upgrade}",
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+				},
+			}
+			if tt.annotation != "" {
+				ing.Annotations[UpstreamConnectionAnnotationKey] = tt.annotation
+			}
+			got, err := ParseUpstreamConnection(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUpstreamConnection() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Error("ParseUpstreamConnection() (-want, +got):", diff)
+			}
+		})
+	}
+}
+
+func TestWithUpstreamConnection(t *testing.T) {
+	tests := []struct {
+		name  string
+		http2 bool
+		conn  *UpstreamConnection
+		want  *istiov1beta1.ConnectionPoolSettings
+	}{{
+		name: "no connection",
+	}, {
+		name:  "http2 without a policy",
+		http2: true,
+		conn:  &UpstreamConnection{ConnectTimeout: metav1.Duration{Duration: 2 * time.Second}},
+		want: &istiov1beta1.ConnectionPoolSettings{
+			Tcp: &istiov1beta1.ConnectionPoolSettings_TCPSettings{ConnectTimeout: durationpb.New(2 * time.Second)},
+			Http: &istiov1beta1.ConnectionPoolSettings_HTTPSettings{
+				H2UpgradePolicy: istiov1beta1.ConnectionPoolSettings_HTTPSettings_UPGRADE,
+			},
+		},
+	}, {
+		name:  "policy overrides http2",
+		http2: true,
+		conn:  &UpstreamConnection{H2UpgradePolicy: "DO_NOT_UPGRADE"},
+		want: &istiov1beta1.ConnectionPoolSettings{
+			Http: &istiov1beta1.ConnectionPoolSettings_HTTPSettings{
+				H2UpgradePolicy: istiov1beta1.ConnectionPoolSettings_HTTPSettings_DO_NOT_UPGRADE,
+			},
+		},
+	}, {
+		name: "keepalive",
+		conn: &UpstreamConnection{TCPKeepalive: &TCPKeepalive{
+			Time:   metav1.Duration{Duration: time.Minute},
+			Probes: 3,
+		}},
+		want: &istiov1beta1.ConnectionPoolSettings{
+			Tcp: &istiov1beta1.ConnectionPoolSettings_TCPSettings{
+				TcpKeepalive: &istiov1beta1.ConnectionPoolSettings_TCPSettings_TcpKeepalive{
+					Time:   durationpb.New(time.Minute),
+					Probes: 3,
+				},
+			},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dr := MakeInternalEncryptionDestinationRule(host, ing, tt.http2)
+			want := dr.Spec.TrafficPolicy.ConnectionPool
+			if tt.want != nil {
+				want = tt.want
+			}
+			wantHash := dr.Annotations[kaccessor.SpecHashAnnotationKey]
+
+			WithUpstreamConnection(dr, tt.conn)
+			if diff := cmp.Diff(want, dr.Spec.TrafficPolicy.ConnectionPool, protocmp.Transform()); diff != "" {
+				t.Error("ConnectionPool (-want, +got):", diff)
+			}
+			if tt.want != nil {
+				wantHash = kaccessor.SpecHash(&dr.Spec)
+			}
+			if got := dr.Annotations[kaccessor.SpecHashAnnotationKey]; got != wantHash {
+				t.Errorf("Spec hash = %q, want %q", got, wantHash)
+			}
+			if dr.Spec.TrafficPolicy.Tls == nil {
+				t.Error("The TLS settings were dropped")
+			}
+		})
+	}
+}
@@ -49,6 +49,9 @@ const (
 	// RouteNamespaceLabelKey is the label key attached to a Ingress
 	// by a Route to indicate which namespace the Route was created in.
 	RouteNamespaceLabelKey = ServingGroupName + "/routeNamespace"
+	// RevisionLabelKey is the label key attached to k8s resources to indicate
+	// which Revision triggered their creation.
+	RevisionLabelKey = ServingGroupName + "/revision"
 )
 
 func GenerateCertificate(hosts []string, secretName string, namespace string) (*corev1.Secret, error) {
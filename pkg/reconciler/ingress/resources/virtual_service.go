@@ -25,6 +25,7 @@ import (
 
 	istiov1beta1 "istio.io/api/networking/v1beta1"
 	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
 	"knative.dev/net-istio/pkg/reconciler/ingress/resources/names"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
@@ -61,6 +62,7 @@ func MakeIngressVirtualService(ing *v1alpha1.Ingress, gateways map[v1alpha1.Ingr
 		return k != RouteLabelKey && k != RouteNamespaceLabelKey
 	})
 	vs.Labels[networking.IngressLabelKey] = ing.Name
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
 	return vs
 }
 
@@ -92,6 +94,7 @@ func MakeMeshVirtualService(ing *v1alpha1.Ingress, gateways map[v1alpha1.Ingress
 		return k != RouteLabelKey && k != RouteNamespaceLabelKey
 	})
 	vs.Labels[networking.IngressLabelKey] = ing.Name
+	vs.Annotations = kaccessor.WithSpecHash(vs.Annotations, &vs.Spec)
 	return vs
 }
 
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
@@ -203,6 +204,7 @@ func TestMakeVirtualServices_CorrectMetadata(t *testing.T) {
 			}
 			for i := range tc.expected {
 				tc.expected[i].OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(tc.ci)}
+				tc.expected[i].Annotations = kaccessor.WithSpecHash(nil, &vss[i].Spec)
 				if diff := cmp.Diff(tc.expected[i], vss[i].ObjectMeta); diff != "" {
 					t.Error("Unexpected metadata (-want +got):", diff)
 				}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// WasmPluginAnnotationKey is the annotation referencing the ConfigMap in the Knative
+	// system namespace that registers the WasmPlugin to attach to the gateways of an Ingress.
+	WasmPluginAnnotationKey = "networking.knative.dev/wasm-plugin"
+
+	// WasmPluginConfigAnnotationKey is the annotation holding the JSON object merged into
+	// the configuration of the registered WasmPlugin for an Ingress.
+	WasmPluginConfigAnnotationKey = "networking.knative.dev/wasm-plugin-config"
+
+	// WasmPluginSpecKey is the key of the registration ConfigMap holding the WasmPlugin spec.
+	WasmPluginSpecKey = "spec"
+)
+
+// MakeWasmPlugin attaches the registered WasmPlugin spec to the gateway behind gatewaySvc,
+// with its configuration overridden by the Ingress annotation.
+func MakeWasmPlugin(ing *netv1alpha1.Ingress, gatewaySvc *corev1.Service, spec string) (*v1alpha1.WasmPlugin, error) {
+	specJSON, err := yaml.YAMLToJSON([]byte(spec))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert WasmPlugin spec: %w", err)
+	}
+
+	wp := &v1alpha1.WasmPlugin{
+		ObjectMeta: gatewayResourceMeta(ing, gatewaySvc),
+	}
+	if err := wp.Spec.UnmarshalJSON(specJSON); err != nil {
+		return nil, fmt.Errorf("invalid WasmPlugin spec: %w", err)
+	}
+	// The plugin is only attached to the gateways of the Ingress.
+	wp.Spec.Selector = &istiotypev1beta1.WorkloadSelector{
+		MatchLabels: gatewaySvc.Spec.Selector,
+	}
+	wp.Spec.TargetRef = nil
+
+	if v, ok := ing.Annotations[WasmPluginConfigAnnotationKey]; ok {
+		config := &structpb.Struct{}
+		if err := config.UnmarshalJSON([]byte(v)); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %w", WasmPluginConfigAnnotationKey, err)
+		}
+		if wp.Spec.PluginConfig == nil {
+			wp.Spec.PluginConfig = &structpb.Struct{}
+		}
+		if wp.Spec.PluginConfig.Fields == nil {
+			wp.Spec.PluginConfig.Fields = map[string]*structpb.Value{}
+		}
+		for k, v := range config.Fields {
+			wp.Spec.PluginConfig.Fields[k] = v
+		}
+	}
+	wp.Annotations = kaccessor.WithSpecHash(wp.Annotations, &wp.Spec)
+
+	return wp, nil
+}
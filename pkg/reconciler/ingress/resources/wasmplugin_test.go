@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+	istiov1alpha1 "istio.io/api/extensions/v1alpha1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const testWasmPluginSpec = `
+url: oci://ghcr.io/example/header-normalizer:v1
+phase: AUTHN
+pluginConfig:
+  lowercase: true
+  prefix: x-knative-
+`
+
+func TestMakeWasmPlugin(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantConfig map[string]interface{}
+	}{{
+		name: "registered configuration",
+		wantConfig: map[string]interface{}{
+			"lowercase": true,
+			"prefix":    "x-knative-",
+		},
+	}, {
+		name:   "configuration overridden by the Ingress",
+		config: `{"prefix": "x-tenant-", "tenant": "foo"}`,
+		wantConfig: map[string]interface{}{
+			"lowercase": true,
+			"prefix":    "x-tenant-",
+			"tenant":    "foo",
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ing := &v1alpha1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "my-ingress",
+					Namespace:   "my-namespace",
+					Annotations: map[string]string{WasmPluginAnnotationKey: "header-normalizer"},
+				},
+			}
+			if tc.config != "" {
+				ing.Annotations[WasmPluginConfigAnnotationKey] = tc.config
+			}
+
+			got, err := MakeWasmPlugin(ing, jwtGatewaySvc, testWasmPluginSpec)
+			if err != nil {
+				t.Fatal("MakeWasmPlugin() =", err)
+			}
+
+			if got.Name != "my-namespace-my-ingress-istio-ingressgateway" || got.Namespace != "istio-system" {
+				t.Errorf("WasmPlugin = %s/%s, want istio-system/my-namespace-my-ingress-istio-ingressgateway", got.Namespace, got.Name)
+			}
+			if diff := cmp.Diff(GatewayResourceLabels(ing), got.Labels); diff != "" {
+				t.Error("Unexpected labels (-want +got):", diff)
+			}
+			wantSelector := &istiotypev1beta1.WorkloadSelector{MatchLabels: jwtGatewaySvc.Spec.Selector}
+			if diff := cmp.Diff(wantSelector, got.Spec.Selector, protocmp.Transform()); diff != "" {
+				t.Error("Unexpected selector (-want +got):", diff)
+			}
+			if got.Spec.Url != "oci://ghcr.io/example/header-normalizer:v1" || got.Spec.Phase != istiov1alpha1.PluginPhase_AUTHN {
+				t.Errorf("WasmPlugin url/phase = %s/%s, want the registered ones", got.Spec.Url, got.Spec.Phase)
+			}
+			wantConfig, err := structpb.NewStruct(tc.wantConfig)
+			if err != nil {
+				t.Fatal("NewStruct() =", err)
+			}
+			if diff := cmp.Diff(wantConfig, got.Spec.PluginConfig, protocmp.Transform()); diff != "" {
+				t.Error("Unexpected plugin config (-want +got):", diff)
+			}
+		})
+	}
+}
+
+func TestMakeWasmPluginErrors(t *testing.T) {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-ingress",
+			Namespace: "my-namespace",
+			Annotations: map[string]string{
+				WasmPluginAnnotationKey:       "header-normalizer",
+				WasmPluginConfigAnnotationKey: "not json",
+			},
+		},
+	}
+	if _, err := MakeWasmPlugin(ing, jwtGatewaySvc, testWasmPluginSpec); err == nil {
+		t.Error("MakeWasmPlugin() = nil, want error for an invalid configuration")
+	}
+
+	delete(ing.Annotations, WasmPluginConfigAnnotationKey)
+	if _, err := MakeWasmPlugin(ing, jwtGatewaySvc, "url: ["); err == nil {
+		t.Error("MakeWasmPlugin() = nil, want error for an invalid spec")
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"math/rand"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+// spreadDelays returns the delays spreading n enqueues evenly over the budget. Each
+// delay is jittered within its own slot of the budget, so that the resyncs of several
+// controller replicas or of several reconcilers do not line up.
+func spreadDelays(n int, budget time.Duration, jitter func() float64) []time.Duration {
+	delays := make([]time.Duration, n)
+	if budget <= 0 {
+		return delays
+	}
+	slot := float64(budget) / float64(n)
+	for i := range delays {
+		delays[i] = time.Duration(slot * (float64(i) + jitter()))
+	}
+	return delays
+}
+
+// pacedGlobalResync enqueues the objects of the informer passing the filter, spread over
+// the budget. A zero budget enqueues them all at once, like FilteredGlobalResync.
+func pacedGlobalResync(impl *controller.Impl, filter func(interface{}) bool, si cache.SharedInformer, budget time.Duration) {
+	if budget <= 0 {
+		impl.FilteredGlobalResync(filter, si)
+		return
+	}
+	var objs []interface{}
+	for _, obj := range si.GetStore().List() {
+		if filter(obj) {
+			objs = append(objs, obj)
+		}
+	}
+	for i, delay := range spreadDelays(len(objs), budget, rand.Float64) {
+		impl.EnqueueAfter(objs[i], delay)
+	}
+}
+
+// pacedPromotion spreads the enqueues of all the keys of a bucket, when the controller
+// becomes its leader, over the budget.
+type pacedPromotion struct {
+	controller.Reconciler
+	reconciler.LeaderAware
+
+	budget func() time.Duration
+}
+
+// withPacedPromotion returns the reconciler with its promotions paced over the budget
+// current when they happen. Reconcilers unaware of the leader election are returned unchanged.
+func withPacedPromotion(r controller.Reconciler, budget func() time.Duration) controller.Reconciler {
+	la, ok := r.(reconciler.LeaderAware)
+	if !ok {
+		return r
+	}
+	return &pacedPromotion{Reconciler: r, LeaderAware: la, budget: budget}
+}
+
+// Promote implements reconciler.LeaderAware.
+func (p *pacedPromotion) Promote(b reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+	budget := p.budget()
+	// Without an enqueue function, e.g. when unopposed, nothing is enqueued.
+	if budget <= 0 || enq == nil {
+		return p.LeaderAware.Promote(b, enq)
+	}
+	var keys []types.NamespacedName
+	err := p.LeaderAware.Promote(b, func(_ reconciler.Bucket, key types.NamespacedName) {
+		keys = append(keys, key)
+	})
+	for i, delay := range spreadDelays(len(keys), budget, rand.Float64) {
+		key := keys[i]
+		time.AfterFunc(delay, func() { enq(b, key) })
+	}
+	return err
+}
+
+// filteredPromotion only enqueues the keys of the objects passing the filter when the
+// controller becomes the leader of a bucket.
+type filteredPromotion struct {
+	controller.Reconciler
+	reconciler.LeaderAware
+
+	store  cache.Store
+	filter func(interface{}) bool
+}
+
+// withFilteredPromotion returns the reconciler with its promotions only enqueuing the keys
+// of the objects of the store passing the filter, like the PromoteFilterFunc of the
+// generated constructors. Reconcilers unaware of the leader election are returned unchanged.
+func withFilteredPromotion(r controller.Reconciler, store cache.Store, filter func(interface{}) bool) controller.Reconciler {
+	la, ok := r.(reconciler.LeaderAware)
+	if !ok {
+		return r
+	}
+	return &filteredPromotion{Reconciler: r, LeaderAware: la, store: store, filter: filter}
+}
+
+// Promote implements reconciler.LeaderAware.
+func (p *filteredPromotion) Promote(b reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+	if enq == nil {
+		return p.LeaderAware.Promote(b, nil)
+	}
+	return p.LeaderAware.Promote(b, func(b reconciler.Bucket, key types.NamespacedName) {
+		if obj, ok, err := p.store.GetByKey(key.String()); err == nil && ok && p.filter(obj) {
+			enq(b, key)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/reconciler"
+)
+
+func TestSpreadDelays(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      int
+		budget time.Duration
+		jitter float64
+		want   []time.Duration
+	}{{
+		name: "no budget",
+		n:    3,
+		want: []time.Duration{0, 0, 0},
+	}, {
+		name:   "no jitter",
+		n:      4,
+		budget: time.Minute,
+		want:   []time.Duration{0, 15 * time.Second, 30 * time.Second, 45 * time.Second},
+	}, {
+		name:   "jitter within the slots",
+		n:      2,
+		budget: time.Minute,
+		jitter: 0.5,
+		want:   []time.Duration{15 * time.Second, 45 * time.Second},
+	}, {
+		name:   "no objects",
+		budget: time.Minute,
+		want:   []time.Duration{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := spreadDelays(test.n, test.budget, func() float64 { return test.jitter })
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("spreadDelays() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+type fakeLeaderAware struct {
+	reconciler.LeaderAwareFuncs
+}
+
+func (*fakeLeaderAware) Reconcile(context.Context, string) error {
+	return nil
+}
+
+func TestPacedPromotion(t *testing.T) {
+	keys := []types.NamespacedName{{Namespace: testNS, Name: "a"}, {Namespace: testNS, Name: "b"}, {Namespace: testNS, Name: "c"}}
+	la := &fakeLeaderAware{}
+	la.PromoteFunc = func(b reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+		for _, key := range keys {
+			enq(b, key)
+		}
+		return nil
+	}
+
+	var (
+		mu       sync.Mutex
+		enqueued []types.NamespacedName
+	)
+	enq := func(_ reconciler.Bucket, key types.NamespacedName) {
+		mu.Lock()
+		defer mu.Unlock()
+		enqueued = append(enqueued, key)
+	}
+
+	// Without a budget, the keys are enqueued right away.
+	budget := time.Duration(0)
+	r := withPacedPromotion(la, func() time.Duration { return budget }).(reconciler.LeaderAware)
+	if err := r.Promote(reconciler.UniversalBucket(), enq); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+	if len(enqueued) != len(keys) {
+		t.Fatalf("Enqueued %v without a budget, want %v", enqueued, keys)
+	}
+
+	enqueued = nil
+	budget = 30 * time.Millisecond
+	if err := r.Promote(reconciler.UniversalBucket(), enq); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+
+	if err := wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(enqueued) == len(keys), nil
+	}); err != nil {
+		t.Fatalf("Enqueued %v, want %v", enqueued, keys)
+	}
+	sortKeys := cmpopts.SortSlices(func(a, b types.NamespacedName) bool { return a.String() < b.String() })
+	if !cmp.Equal(enqueued, keys, sortKeys) {
+		t.Errorf("Enqueued %v, want %v", enqueued, keys)
+	}
+}
+
+func TestFilteredPromotion(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	other := ing("other")
+	other.Annotations = map[string]string{networking.IngressClassAnnotationKey: "other"}
+	for _, obj := range []*v1alpha1.Ingress{ing("istio"), other} {
+		if err := store.Add(obj); err != nil {
+			t.Fatal("Failed to add Ingress:", err)
+		}
+	}
+	la := &fakeLeaderAware{}
+	la.PromoteFunc = func(b reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+		for _, name := range []string{"istio", "other", "deleted"} {
+			enq(b, types.NamespacedName{Namespace: testNS, Name: name})
+		}
+		return nil
+	}
+
+	var enqueued []types.NamespacedName
+	filter := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, netconfig.IstioIngressClassName, true)
+	r := withFilteredPromotion(la, store, filter).(reconciler.LeaderAware)
+	if err := r.Promote(reconciler.UniversalBucket(), func(_ reconciler.Bucket, key types.NamespacedName) {
+		enqueued = append(enqueued, key)
+	}); err != nil {
+		t.Fatal("Promote() =", err)
+	}
+
+	want := []types.NamespacedName{{Namespace: testNS, Name: "istio"}}
+	if !cmp.Equal(enqueued, want) {
+		t.Error("Enqueued (-want, +got):", cmp.Diff(want, enqueued))
+	}
+}
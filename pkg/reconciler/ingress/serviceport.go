@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+)
+
+const servicePortNotResolved = "ServicePortNotResolved"
+
+// resolveServicePorts replaces the ports of the splits of the Ingress given by name, or by
+// the target port of their Service, with the ports of their Services, which the routes of the
+// VirtualServices send the requests to. Like the defaults, the ports are only replaced in the
+// reconciled copy. The Ingress is failed if a named port can't be resolved.
+func (r *Reconciler) resolveServicePorts(ing *v1alpha1.Ingress) error {
+	for _, rule := range ing.Spec.Rules {
+		for i := range rule.HTTP.Paths {
+			splits := rule.HTTP.Paths[i].Splits
+			for j := range splits {
+				if err := r.resolveServicePort(ing, &splits[j]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) resolveServicePort(ing *v1alpha1.Ingress, split *v1alpha1.IngressBackendSplit) error {
+	ref := resources.ServiceRef(split.ServiceNamespace, split.ServiceName)
+	svc, err := r.svcLister.Services(split.ServiceNamespace).Get(split.ServiceName)
+	if apierrs.IsNotFound(err) {
+		if split.ServicePort.Type == intstr.Int {
+			// The numbers are used as they are until the Service is created.
+			return nil
+		}
+		err = fmt.Errorf("failed to resolve the port %q of the Service %s/%s: the Service does not exist",
+			split.ServicePort.StrVal, split.ServiceNamespace, split.ServiceName)
+	} else if err != nil {
+		return fmt.Errorf("failed to get service: %w", err)
+	} else {
+		var port int32
+		port, err = resources.ResolveServicePort(svc, split.ServicePort)
+		if err == nil {
+			if split.ServicePort.Type == intstr.Int && port == split.ServicePort.IntVal {
+				return nil
+			}
+			// The resolved port changes with the Service.
+			r.tracker.TrackReference(ref, ing)
+			split.ServicePort = intstr.FromInt(int(port))
+			return nil
+		}
+	}
+
+	// Retrying is pointless until the Service changes, which the tracker notices.
+	r.tracker.TrackReference(ref, ing)
+	ing.Status.MarkLoadBalancerFailed(servicePortNotResolved, err.Error())
+	return controller.NewPermanentError(err)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func TestResolveServicePorts(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test-service"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+	ingress := func(ports ...intstr.IntOrString) *v1alpha1.Ingress {
+		splits := make([]v1alpha1.IngressBackendSplit, 0, len(ports))
+		for _, port := range ports {
+			splits = append(splits, v1alpha1.IngressBackendSplit{
+				IngressBackend: v1alpha1.IngressBackend{
+					ServiceNamespace: "test-ns",
+					ServiceName:      "test-service",
+					ServicePort:      port,
+				},
+			})
+		}
+		ing := &v1alpha1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "ing"},
+			Spec: v1alpha1.IngressSpec{
+				Rules: []v1alpha1.IngressRule{{
+					Hosts: []string{"foo.example.com"},
+					HTTP: &v1alpha1.HTTPIngressRuleValue{
+						Paths: []v1alpha1.HTTPIngressPath{{Splits: splits}},
+					},
+				}},
+			},
+		}
+		ing.Status.InitializeConditions()
+		return ing
+	}
+
+	tests := []struct {
+		name      string
+		objects   []runtime.Object
+		ports     []intstr.IntOrString
+		wantPorts []intstr.IntOrString
+		wantErr   bool
+	}{{
+		name:      "named and target ports",
+		objects:   []runtime.Object{svc},
+		ports:     []intstr.IntOrString{intstr.FromString("http"), intstr.FromInt(8080), intstr.FromInt(80)},
+		wantPorts: []intstr.IntOrString{intstr.FromInt(80), intstr.FromInt(80), intstr.FromInt(80)},
+	}, {
+		name:      "numeric port without service",
+		ports:     []intstr.IntOrString{intstr.FromInt(8080)},
+		wantPorts: []intstr.IntOrString{intstr.FromInt(8080)},
+	}, {
+		name:    "named port without service",
+		ports:   []intstr.IntOrString{intstr.FromString("http")},
+		wantErr: true,
+	}, {
+		name:    "unknown named port",
+		objects: []runtime.Object{svc},
+		ports:   []intstr.IntOrString{intstr.FromString("grpc")},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listers := NewListers(tt.objects)
+			r := &Reconciler{
+				svcLister: listers.GetK8sServiceLister(),
+				tracker:   &NullTracker{},
+			}
+			ing := ingress(tt.ports...)
+
+			err := r.resolveServicePorts(ing)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveServicePorts() = %v, wantErr: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !controller.IsPermanentError(err) {
+					t.Errorf("resolveServicePorts() = %v, want a permanent error", err)
+				}
+				if cond := ing.Status.GetCondition(v1alpha1.IngressConditionLoadBalancerReady); cond.Reason != servicePortNotResolved {
+					t.Errorf("LoadBalancerReady = %v, want reason %s", cond, servicePortNotResolved)
+				}
+				return
+			}
+			for i, split := range ing.Spec.Rules[0].HTTP.Paths[0].Splits {
+				if split.ServicePort != tt.wantPorts[i] {
+					t.Errorf("ServicePort[%d] = %v, want: %v", i, split.ServicePort, tt.wantPorts[i])
+				}
+			}
+		})
+	}
+}
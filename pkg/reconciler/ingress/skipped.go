@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// configurationSkipped is the condition reporting the parts of the Ingress which are
+	// not reconciled, e.g. its rules without hosts. It does not affect the readiness of the
+	// Ingress, which only depends on the parts that are.
+	configurationSkipped apis.ConditionType = "ConfigurationSkipped"
+
+	noRulesReason  = "NoRules"
+	noRulesMessage = "The Ingress has no rules with hosts and paths to route."
+)
+
+// skipUnroutableRules drops the rules of the Ingress without hosts or HTTP paths, and the
+// paths without backends, which can't be routed, and returns the descriptions of what was
+// dropped. Like the defaults, the rules are only dropped from the reconciled copy.
+func skipUnroutableRules(ing *v1alpha1.Ingress) []string {
+	var skipped []string
+	rules := make([]v1alpha1.IngressRule, 0, len(ing.Spec.Rules))
+	for i, rule := range ing.Spec.Rules {
+		if len(rule.Hosts) == 0 {
+			skipped = append(skipped, fmt.Sprintf("rule %d has no hosts", i))
+			continue
+		}
+		if rule.HTTP == nil || len(rule.HTTP.Paths) == 0 {
+			skipped = append(skipped, fmt.Sprintf("rule %d (%s) has no HTTP paths", i, strings.Join(rule.Hosts, ", ")))
+			continue
+		}
+
+		paths := make([]v1alpha1.HTTPIngressPath, 0, len(rule.HTTP.Paths))
+		for _, path := range rule.HTTP.Paths {
+			if len(path.Splits) == 0 {
+				skipped = append(skipped, fmt.Sprintf("the path %q of rule %d (%s) has no backends", path.Path, i, strings.Join(rule.Hosts, ", ")))
+				continue
+			}
+			paths = append(paths, path)
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		if len(paths) != len(rule.HTTP.Paths) {
+			// The HTTP block may be shared with the Ingress of the informers.
+			rule.HTTP = &v1alpha1.HTTPIngressRuleValue{Paths: paths}
+		}
+		rules = append(rules, rule)
+	}
+	ing.Spec.Rules = rules
+	return skipped
+}
+
+// skippedTLS returns the descriptions of the Ingress TLS which are not reconciled.
+func skippedTLS(ctx context.Context, ing *v1alpha1.Ingress) []string {
+	if config.FromContext(ctx).Network.ClusterLocalDomainTLS != netconfig.EncryptionEnabled && resources.ShouldReconcileClusterLocalDomainTLS(ing) {
+		return []string{"the TLS of the cluster-local hosts, as cluster-local-domain-tls is not enabled"}
+	}
+	return nil
+}
+
+// markConfigurationSkipped reports the parts of the Ingress which are not reconciled, or
+// clears the condition when there are none.
+func markConfigurationSkipped(ing *v1alpha1.Ingress, skipped []string) {
+	if len(skipped) == 0 {
+		ing.GetConditionSet().Manage(&ing.Status).ClearCondition(configurationSkipped)
+		return
+	}
+	// The condition is set directly, as marking it true would mark the Ingress ready.
+	ing.GetConditionSet().Manage(&ing.Status).SetCondition(apis.Condition{
+		Type:     configurationSkipped,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+		Message:  "Skipped " + strings.Join(skipped, "; ") + ".",
+	})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestSkipUnroutableRules(t *testing.T) {
+	split := v1alpha1.IngressBackendSplit{
+		IngressBackend: v1alpha1.IngressBackend{
+			ServiceNamespace: "ns",
+			ServiceName:      "svc",
+			ServicePort:      intstr.FromInt(80),
+		},
+		Percent: 100,
+	}
+	shared := &v1alpha1.HTTPIngressRuleValue{
+		Paths: []v1alpha1.HTTPIngressPath{{
+			Path:   "/routed",
+			Splits: []v1alpha1.IngressBackendSplit{split},
+		}, {
+			Path: "/unrouted",
+		}},
+	}
+	ing := &v1alpha1.Ingress{
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				HTTP: shared,
+			}, {
+				Hosts: []string{"foo.example.com", "bar.example.com"},
+				HTTP:  &v1alpha1.HTTPIngressRuleValue{},
+			}, {
+				Hosts: []string{"baz.example.com"},
+				HTTP:  shared,
+			}},
+		},
+	}
+
+	skipped := skipUnroutableRules(ing)
+
+	wantSkipped := []string{
+		"rule 0 has no hosts",
+		"rule 1 (foo.example.com, bar.example.com) has no HTTP paths",
+		`the path "/unrouted" of rule 2 (baz.example.com) has no backends`,
+	}
+	if diff := cmp.Diff(wantSkipped, skipped); diff != "" {
+		t.Error("Unexpected skipped (-want +got):", diff)
+	}
+	wantRules := []v1alpha1.IngressRule{{
+		Hosts: []string{"baz.example.com"},
+		HTTP: &v1alpha1.HTTPIngressRuleValue{
+			Paths: []v1alpha1.HTTPIngressPath{{
+				Path:   "/routed",
+				Splits: []v1alpha1.IngressBackendSplit{split},
+			}},
+		},
+	}}
+	if diff := cmp.Diff(wantRules, ing.Spec.Rules); diff != "" {
+		t.Error("Unexpected rules (-want +got):", diff)
+	}
+	if len(shared.Paths) != 2 {
+		t.Errorf("len(shared.Paths) = %d, the shared HTTP block must not be changed", len(shared.Paths))
+	}
+}
+
+func TestMarkConfigurationSkipped(t *testing.T) {
+	ing := &v1alpha1.Ingress{}
+	ing.Status.InitializeConditions()
+
+	markConfigurationSkipped(ing, []string{"rule 0 has no hosts", "rule 1 has no hosts"})
+	cond := ing.Status.GetCondition(configurationSkipped)
+	if cond == nil || cond.Status != corev1.ConditionTrue || cond.Message != "Skipped rule 0 has no hosts; rule 1 has no hosts." {
+		t.Errorf("ConfigurationSkipped = %v, want True with the skipped rules", cond)
+	}
+	ing.Status.MarkNetworkConfigured()
+	ing.Status.MarkLoadBalancerReady(nil, nil)
+	if !ing.IsReady() {
+		t.Error("IsReady() = false, the skipped configuration must not mark the Ingress not ready")
+	}
+
+	markConfigurationSkipped(ing, nil)
+	if cond := ing.Status.GetCondition(configurationSkipped); cond != nil {
+		t.Errorf("ConfigurationSkipped = %v, want cleared", cond)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// gatewayZonesReady is the condition reporting the zones of the gateway pods the
+	// readiness of the Ingress was probed in. It does not affect the readiness of the
+	// Ingress, which already requires the probes of all these zones to succeed.
+	gatewayZonesReady apis.ConditionType = "GatewayZonesReady"
+
+	gatewayZonesProbing = "GatewayZonesProbing"
+)
+
+// zoneLookup resolves the zones of the nodes running the gateway pods. The nodes are only
+// watched once zone-aware probing is first used, and only their zone is kept in memory.
+type zoneLookup struct {
+	client kubernetes.Interface
+	stopCh <-chan struct{}
+
+	once     sync.Once
+	informer cache.SharedIndexInformer
+}
+
+func newZoneLookup(client kubernetes.Interface, stopCh <-chan struct{}) *zoneLookup {
+	return &zoneLookup{client: client, stopCh: stopCh}
+}
+
+// zones returns the function resolving the zone of a node, empty when unknown.
+func (z *zoneLookup) zones(ctx context.Context) (func(nodeName string) string, error) {
+	z.once.Do(func() {
+		z.informer = coreinformers.NewNodeInformer(z.client, 0, cache.Indexers{})
+		z.informer.SetTransform(func(obj interface{}) (interface{}, error) {
+			node, ok := obj.(*corev1.Node)
+			if !ok {
+				return obj, nil
+			}
+			return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:            node.Name,
+				ResourceVersion: node.ResourceVersion,
+				Labels:          map[string]string{corev1.LabelTopologyZone: node.Labels[corev1.LabelTopologyZone]},
+			}}, nil
+		})
+		go z.informer.Run(z.stopCh)
+	})
+	if !cache.WaitForCacheSync(ctx.Done(), z.informer.HasSynced) {
+		return nil, errors.New("failed to sync the nodes")
+	}
+	store := z.informer.GetStore()
+	return func(nodeName string) string {
+		obj, ok, err := store.GetByKey(nodeName)
+		if err != nil || !ok {
+			return ""
+		}
+		return obj.(*corev1.Node).Labels[corev1.LabelTopologyZone]
+	}, nil
+}
+
+// pickPerZone returns the IPs of the addresses, keeping the first perZone of each zone in
+// the order of the IPs. The zones of the addresses are inserted into the given set.
+func pickPerZone(addresses []corev1.EndpointAddress, perZone int, zoneOf func(string) string, zones sets.Set[string]) sets.Set[string] {
+	sorted := append([]corev1.EndpointAddress(nil), addresses...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].IP < sorted[j].IP })
+
+	picked := sets.New[string]()
+	perZoneCount := map[string]int{}
+	for _, addr := range sorted {
+		zone := ""
+		if addr.NodeName != nil {
+			zone = zoneOf(*addr.NodeName)
+		}
+		zones.Insert(zone)
+		if perZone > 0 && perZoneCount[zone] >= perZone {
+			continue
+		}
+		perZoneCount[zone]++
+		picked.Insert(addr.IP)
+	}
+	return picked
+}
+
+// markGatewayZones reports the zones of the gateway pods the readiness of the Ingress is
+// probed in, and whether the probes of all of them succeeded.
+func markGatewayZones(ing *v1alpha1.Ingress, zones []string, ready bool) {
+	cond := apis.Condition{
+		Type:     gatewayZonesReady,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+		Message:  "The gateway pods are ready in the zones: " + strings.Join(zones, ", "),
+	}
+	if !ready {
+		cond.Status = corev1.ConditionUnknown
+		cond.Reason = gatewayZonesProbing
+		cond.Message = "Waiting for the gateway pods to be ready in the zones: " + strings.Join(zones, ", ")
+	}
+	if len(zones) == 0 {
+		cond.Message = "The zones of the gateway pods are unknown"
+	}
+	// The condition is set directly, as marking it true would mark the Ingress ready.
+	ing.GetConditionSet().Manage(&ing.Status).SetCondition(cond)
+}
+
+// clearGatewayZones clears the condition set by markGatewayZones.
+func clearGatewayZones(ing *v1alpha1.Ingress) {
+	ing.GetConditionSet().Manage(&ing.Status).ClearCondition(gatewayZonesReady)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zaptest"
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	v1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestPickPerZone(t *testing.T) {
+	nodeZones := map[string]string{"node-a1": "a", "node-a2": "a", "node-b": "b"}
+	zoneOf := func(node string) string { return nodeZones[node] }
+	addresses := []corev1.EndpointAddress{
+		{IP: "1.1.1.4", NodeName: ptr.String("node-a2")},
+		{IP: "1.1.1.2", NodeName: ptr.String("node-b")},
+		{IP: "1.1.1.1", NodeName: ptr.String("node-a1")},
+		{IP: "1.1.1.3", NodeName: ptr.String("node-b")},
+		{IP: "1.1.1.5"},
+	}
+
+	tests := []struct {
+		name    string
+		perZone int
+		want    sets.Set[string]
+	}{{
+		name: "all pods",
+		want: sets.New("1.1.1.1", "1.1.1.2", "1.1.1.3", "1.1.1.4", "1.1.1.5"),
+	}, {
+		name:    "one pod per zone",
+		perZone: 1,
+		want:    sets.New("1.1.1.1", "1.1.1.2", "1.1.1.5"),
+	}, {
+		name:    "more pods per zone than running",
+		perZone: 3,
+		want:    sets.New("1.1.1.1", "1.1.1.2", "1.1.1.3", "1.1.1.4", "1.1.1.5"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			zones := sets.New[string]()
+			got := pickPerZone(addresses, test.perZone, zoneOf, zones)
+			if !got.Equal(test.want) {
+				t.Errorf("pickPerZone() = %v, want: %v", sets.List(got), sets.List(test.want))
+			}
+			if want := sets.New("a", "b", ""); !zones.Equal(want) {
+				t.Errorf("zones = %v, want: %v", sets.List(zones), sets.List(want))
+			}
+		})
+	}
+}
+
+func TestListProbeZones(t *testing.T) {
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{corev1.LabelTopologyZone: zone},
+		}}
+	}
+	client := fakekubeclientset.NewSimpleClientset(node("node-a", "zone-a"), node("node-b", "zone-b"))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	lister := newGatewayPodTargetLister(
+		zaptest.NewLogger(t).Sugar(),
+		&fakeGatewayLister{
+			gateways: []*v1beta1.Gateway{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "gateway"},
+				Spec: istiov1beta1.Gateway{
+					Servers: []*istiov1beta1.Server{{
+						Hosts: []string{"*"},
+						Port:  &istiov1beta1.Port{Number: 80, Protocol: "HTTP"},
+					}},
+					Selector: map[string]string{"gwt": "istio"},
+				},
+			}},
+		},
+		&fakeEndpointsLister{
+			endpointses: []*corev1.Endpoints{{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "gateway"},
+				Subsets: []corev1.EndpointSubset{{
+					Ports: []corev1.EndpointPort{{Name: "http", Port: 8080}},
+					Addresses: []corev1.EndpointAddress{
+						{IP: "1.1.1.1", NodeName: ptr.String("node-a")},
+						{IP: "1.1.1.2", NodeName: ptr.String("node-a")},
+						{IP: "1.1.1.3", NodeName: ptr.String("node-b")},
+						{IP: "1.1.1.4", NodeName: ptr.String("node-unknown")},
+					},
+				}},
+			}},
+		},
+		&fakeServiceLister{
+			services: []*corev1.Service{{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "istio-system",
+					Name:      "gateway",
+					Labels:    map[string]string{"gwt": "istio"},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+				},
+			}},
+		},
+		newZoneLookup(client, ctx.Done()))
+
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "whatever"},
+		Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      []string{"foo.bar.com"},
+				Visibility: v1alpha1.IngressVisibilityExternalIP,
+			}},
+		},
+	}
+	ctx = config.ToContext(ctx, &config.Config{
+		Istio: &config.Istio{
+			IngressGateways:  []config.Gateway{{Namespace: "istio-system", Name: "gateway"}},
+			ProbePodsPerZone: 1,
+		},
+	})
+
+	targets, err := lister.ListProbeTargets(ctx, ing)
+	if err != nil {
+		t.Fatal("ListProbeTargets() =", err)
+	}
+	if len(targets) != 1 {
+		t.Fatalf("ListProbeTargets() = %d targets, want: 1", len(targets))
+	}
+	if want := sets.New("1.1.1.1", "1.1.1.3", "1.1.1.4"); !targets[0].PodIPs.Equal(want) {
+		t.Errorf("PodIPs = %v, want: %v", sets.List(targets[0].PodIPs), sets.List(want))
+	}
+
+	zones, err := lister.ListProbeZones(ctx, ing)
+	if err != nil {
+		t.Fatal("ListProbeZones() =", err)
+	}
+	if diff := cmp.Diff([]string{"zone-a", "zone-b"}, zones); diff != "" {
+		t.Error("Unexpected zones (-want +got):", diff)
+	}
+}
+
+func TestMarkGatewayZones(t *testing.T) {
+	ing := &v1alpha1.Ingress{}
+	ing.Status.InitializeConditions()
+
+	markGatewayZones(ing, []string{"zone-a", "zone-b"}, false)
+	cond := ing.Status.GetCondition(gatewayZonesReady)
+	if cond == nil || cond.Status != corev1.ConditionUnknown || cond.Reason != gatewayZonesProbing {
+		t.Errorf("GatewayZonesReady = %v, want Unknown with reason %s", cond, gatewayZonesProbing)
+	}
+
+	markGatewayZones(ing, []string{"zone-a", "zone-b"}, true)
+	if cond := ing.Status.GetCondition(gatewayZonesReady); cond == nil || cond.Status != corev1.ConditionTrue {
+		t.Errorf("GatewayZonesReady = %v, want True", cond)
+	}
+	if ing.IsReady() {
+		t.Error("IsReady() = true, the zones must not mark the Ingress ready")
+	}
+
+	clearGatewayZones(ing)
+	if cond := ing.Status.GetCondition(gatewayZonesReady); cond != nil {
+		t.Errorf("GatewayZonesReady = %v, want cleared", cond)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package istiometadata provides informers that only watch the metadata of
+// Istio resources, for the kinds whose existence and labels are all that a
+// reconciler needs. Their caches hold PartialObjectMetadata instead of the
+// full resources, which keeps the watches and the memory of the controller
+// small on very large meshes.
+package istiometadata
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterClient(withClient)
+	injection.Default.RegisterFilteredInformers(withInformers)
+}
+
+type clientKey struct{}
+
+// resourcesKey is used to carry the resources whose metadata is watched. The
+// informers are only set up for them.
+type resourcesKey struct{}
+
+// informerKey is used for associating the Informer of a resource inside the
+// context.Context.
+type informerKey struct {
+	gvr schema.GroupVersionResource
+}
+
+// WithResources enables the metadata-only informers of the given resources,
+// e.g. networking.istio.io/v1beta1 virtualservices, in addition to the ones
+// already enabled. It must be called before the informers are set up.
+func WithResources(ctx context.Context, gvrs ...schema.GroupVersionResource) context.Context {
+	resources := sets.New(gvrs...)
+	if enabled, ok := ctx.Value(resourcesKey{}).(sets.Set[schema.GroupVersionResource]); ok {
+		resources = resources.Union(enabled)
+	}
+	return context.WithValue(ctx, resourcesKey{}, resources)
+}
+
+// IsEnabled returns whether the metadata-only informer of the resource is set
+// up.
+func IsEnabled(ctx context.Context, gvr schema.GroupVersionResource) bool {
+	enabled, _ := ctx.Value(resourcesKey{}).(sets.Set[schema.GroupVersionResource])
+	return enabled.Has(gvr)
+}
+
+func withClient(ctx context.Context, cfg *rest.Config) context.Context {
+	return context.WithValue(ctx, clientKey{}, metadata.NewForConfigOrDie(cfg))
+}
+
+func withInformers(ctx context.Context) (context.Context, []controller.Informer) {
+	enabled, _ := ctx.Value(resourcesKey{}).(sets.Set[schema.GroupVersionResource])
+	if enabled.Len() == 0 {
+		return ctx, nil
+	}
+
+	c, ok := ctx.Value(clientKey{}).(metadata.Interface)
+	if !ok {
+		logging.FromContext(ctx).Panic("Unable to fetch k8s.io/client-go/metadata.Interface from context.")
+	}
+	namespace := metav1.NamespaceAll
+	if injection.HasNamespaceScope(ctx) {
+		namespace = injection.GetNamespaceScope(ctx)
+	}
+	infs := make([]controller.Informer, 0, enabled.Len())
+	for gvr := range enabled {
+		inf := metadatainformer.NewFilteredMetadataInformer(c, gvr, namespace,
+			controller.GetResyncPeriod(ctx),
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+			nil)
+		ctx = context.WithValue(ctx, informerKey{gvr: gvr}, inf)
+		infs = append(infs, inf.Informer())
+	}
+	return ctx, infs
+}
+
+// Get extracts the metadata-only informer of the resource from the context.
+// Its lister returns *metav1.PartialObjectMetadata.
+func Get(ctx context.Context, gvr schema.GroupVersionResource) informers.GenericInformer {
+	untyped := ctx.Value(informerKey{gvr: gvr})
+	if untyped == nil {
+		logging.FromContext(ctx).Panicf(
+			"Unable to fetch the metadata-only informer of %s from context.", gvr)
+	}
+	return untyped.(informers.GenericInformer)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istiometadata
+
+import (
+	"context"
+	"testing"
+
+	networkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+)
+
+var (
+	virtualServices  = networkingv1beta1.SchemeGroupVersion.WithResource("virtualservices")
+	destinationRules = networkingv1beta1.SchemeGroupVersion.WithResource("destinationrules")
+	gateways         = networkingv1beta1.SchemeGroupVersion.WithResource("gateways")
+)
+
+// nopClient is a metadata client which is never called, as the informers
+// are not started.
+type nopClient struct{}
+
+func (nopClient) Resource(schema.GroupVersionResource) metadata.Getter { return nil }
+
+func TestWithInformers(t *testing.T) {
+	ctx := context.WithValue(context.Background(), clientKey{}, metadata.Interface(nopClient{}))
+
+	if _, infs := withInformers(ctx); len(infs) != 0 {
+		t.Errorf("withInformers() = %d informers without resources, want none", len(infs))
+	}
+
+	ctx = WithResources(ctx, virtualServices)
+	ctx = WithResources(ctx, destinationRules, virtualServices)
+	for _, gvr := range []schema.GroupVersionResource{virtualServices, destinationRules} {
+		if !IsEnabled(ctx, gvr) {
+			t.Errorf("IsEnabled(%s) = false, want true", gvr)
+		}
+	}
+	if IsEnabled(ctx, gateways) {
+		t.Errorf("IsEnabled(%s) = true, want false", gateways)
+	}
+
+	ctx, infs := withInformers(ctx)
+	if len(infs) != 2 {
+		t.Fatalf("withInformers() = %d informers, want 2", len(infs))
+	}
+	vs := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vs"}}
+	inf := Get(ctx, virtualServices)
+	if err := inf.Informer().GetIndexer().Add(vs); err != nil {
+		t.Fatal("Failed to add VirtualService metadata:", err)
+	}
+	got, err := inf.Lister().ByNamespace("ns").Get("vs")
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got != vs {
+		t.Errorf("Get() = %v, want %v", got, vs)
+	}
+	if Get(ctx, destinationRules) == inf {
+		t.Error("The resources share their informer")
+	}
+}
+
+func TestGetNotEnabled(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Get() did not panic for a resource which is not enabled")
+		}
+	}()
+	Get(context.Background(), gateways)
+}
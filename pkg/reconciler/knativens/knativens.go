@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package knativens finds the namespaces hosting Knative Services, whose
+// PeerAuthentications, Sidecars and NetworkPolicies are reconciled by net-istio.
+package knativens
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/networking/pkg/apis/networking"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+)
+
+// HostsKnativeServices returns whether the namespace contains Ingresses reconciled by net-istio.
+func HostsKnativeServices(lister networkinglisters.IngressLister, namespace string) (bool, error) {
+	ings, err := lister.Ingresses(namespace).List(labels.Everything())
+	if err != nil {
+		return false, fmt.Errorf("failed to list Ingresses: %w", err)
+	}
+	for _, ing := range ings {
+		if ing.GetAnnotations()[networking.IngressClassAnnotationKey] == netconfig.IstioIngressClassName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knativens
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+)
+
+func TestHostsKnativeServices(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    bool
+	}{{
+		name: "no Ingress",
+	}, {
+		name:    "Ingress of another class",
+		objects: []runtime.Object{ing("testing", "kourier.ingress.networking.knative.dev")},
+	}, {
+		name:    "Ingress of another namespace",
+		objects: []runtime.Object{ing("other", netconfig.IstioIngressClassName)},
+	}, {
+		name: "Ingress reconciled by net-istio",
+		objects: []runtime.Object{
+			ing("testing", "kourier.ingress.networking.knative.dev"),
+			ing("testing", netconfig.IstioIngressClassName),
+		},
+		want: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listers := NewListers(tt.objects)
+			got, err := HostsKnativeServices(listers.GetIngressLister(), "testing")
+			if err != nil {
+				t.Fatal("HostsKnativeServices() =", err)
+			}
+			if got != tt.want {
+				t.Errorf("HostsKnativeServices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ing(namespace, class string) *netv1alpha1.Ingress {
+	return &netv1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-" + class,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: class,
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/networkpolicy/resources"
+	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// NewController initializes the controller maintaining NetworkPolicies for namespaces
+// hosting Knative services. The workqueue is keyed by namespace.
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+	logger := logging.FromContext(ctx)
+	ingressInformer := ingressinformer.Get(ctx)
+	factory := informers.NewSharedInformerFactory(kubeclient.Get(ctx), controller.GetResyncPeriod(ctx))
+	networkPolicyInformer := factory.Networking().V1().NetworkPolicies()
+
+	r := &reconciler{
+		kubeclient:          kubeclient.Get(ctx),
+		ingressLister:       ingressInformer.Lister(),
+		networkPolicyLister: networkPolicyInformer.Lister(),
+		svcLister:           serviceinformer.Get(ctx).Lister(),
+	}
+
+	impl := controller.NewContext(ctx, r, controller.ControllerOptions{
+		WorkQueueName: "NetworkPolicies",
+		Logger:        logger,
+	})
+
+	configStore := config.NewStore(logger.Named("config-store"), func(string, interface{}) {
+		ings, _ := ingressInformer.Lister().List(labels.Everything())
+		for _, ing := range ings {
+			impl.EnqueueNamespaceOf(ing)
+		}
+		nps, _ := networkPolicyInformer.Lister().List(labels.SelectorFromSet(labels.Set{resources.ManagedLabelKey: "true"}))
+		for _, np := range nps {
+			impl.EnqueueNamespaceOf(np)
+		}
+	})
+	configStore.WatchConfigs(cmw)
+	r.configStore = configStore
+
+	ingressInformer.Informer().AddEventHandler(controller.HandleAll(impl.EnqueueNamespaceOf))
+	networkPolicyInformer.Informer().AddEventHandler(controller.HandleAll(impl.EnqueueNamespaceOf))
+
+	// The informer is not injected, so that it is only started when the controller is
+	// enabled, and sharedmain does not start it.
+	factory.Start(ctx.Done())
+	for informer, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logger.Fatalf("Failed to sync the %v informer", informer)
+		}
+	}
+
+	return impl
+}
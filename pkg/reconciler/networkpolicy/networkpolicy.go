@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	ingressresources "knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/knativens"
+	"knative.dev/net-istio/pkg/reconciler/networkpolicy/resources"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// reconciler maintains the NetworkPolicy of a single namespace.
+type reconciler struct {
+	kubeclient kubernetes.Interface
+
+	ingressLister       networkinglisters.IngressLister
+	networkPolicyLister networkingv1listers.NetworkPolicyLister
+	svcLister           corev1listers.ServiceLister
+
+	configStore pkgreconciler.ConfigStore
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+
+// Reconcile converges the NetworkPolicy of the namespace in the key.
+func (r *reconciler) Reconcile(ctx context.Context, key string) error {
+	ctx = r.configStore.ToContext(ctx)
+	logger := logging.FromContext(ctx)
+
+	// The keys are namespaces, which are cluster scoped.
+	_, namespace, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Errorw("Invalid resource key", key)
+		return nil
+	}
+
+	istiocfg := config.FromContext(ctx).Istio
+	hosting, err := knativens.HostsKnativeServices(r.ingressLister, namespace)
+	if err != nil {
+		return err
+	}
+
+	managed, err := r.networkPolicyLister.NetworkPolicies(namespace).Get(resources.NetworkPolicyName)
+	if apierrs.IsNotFound(err) {
+		managed = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get NetworkPolicy: %w", err)
+	} else if managed.Labels[resources.ManagedLabelKey] != "true" {
+		logger.Warnf("Namespace %s already has the NetworkPolicy %s, skipping", namespace, managed.Name)
+		return nil
+	}
+
+	if !istiocfg.EnableNetworkPolicies || !hosting {
+		if managed == nil {
+			return nil
+		}
+		if err := r.kubeclient.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, managed.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete NetworkPolicy: %w", err)
+		}
+		return nil
+	}
+
+	gateways, err := r.gatewayServices(append(istiocfg.IngressGateways, istiocfg.LocalGateways...))
+	if err != nil {
+		return err
+	}
+	desired := resources.MakeNetworkPolicy(namespace, gateways)
+
+	if managed == nil {
+		if _, err := r.kubeclient.NetworkingV1().NetworkPolicies(namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create NetworkPolicy: %w", err)
+		}
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(managed.Spec, desired.Spec) {
+		return nil
+	}
+	// Don't modify the informers copy
+	update := managed.DeepCopy()
+	update.Spec = desired.Spec
+	update.Labels = desired.Labels
+	if _, err := r.kubeclient.NetworkingV1().NetworkPolicies(namespace).Update(ctx, update, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update NetworkPolicy: %w", err)
+	}
+	return nil
+}
+
+// gatewayServices returns the Services backing the given gateways, whose selectors
+// identify the pods of the gateways.
+func (r *reconciler) gatewayServices(gateways []config.Gateway) ([]*corev1.Service, error) {
+	seen := sets.New[string]()
+	ret := make([]*corev1.Service, 0, len(gateways))
+	for _, gw := range gateways {
+		meta, err := ingressresources.GetGatewaySvcNameNamespace(gw)
+		if err != nil {
+			return nil, err
+		}
+		if seen.Has(meta.Namespace + "/" + meta.Name) {
+			continue
+		}
+		seen.Insert(meta.Namespace + "/" + meta.Name)
+		svc, err := r.svcLister.Services(meta.Namespace).Get(meta.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the gateway Service %s/%s: %w", meta.Namespace, meta.Name, err)
+		}
+		ret = append(ret, svc)
+	}
+	return ret, nil
+}
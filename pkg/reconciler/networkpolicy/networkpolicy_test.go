@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+
+	// Inject our fakes
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/networkpolicy/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	gateways = []config.Gateway{{
+		Namespace:  "knative-serving",
+		Name:       config.KnativeIngressGateway,
+		ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+	}}
+
+	gatewayService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "istio-system",
+			Name:      "istio-ingressgateway",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+)
+
+type cfgKey struct{}
+
+func withConfig(enabled bool, gws []config.Gateway) context.Context {
+	return context.WithValue(context.Background(), cfgKey{}, &config.Config{
+		Istio: &config.Istio{
+			EnableNetworkPolicies: enabled,
+			IngressGateways:       gws,
+		},
+		Network: &netconfig.Config{},
+	})
+}
+
+func ing(class string) *netv1alpha1.Ingress {
+	return &netv1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "testing",
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: class,
+			},
+		},
+	}
+}
+
+func networkPolicy(gws ...*corev1.Service) *networkingv1.NetworkPolicy {
+	return resources.MakeNetworkPolicy("testing", gws)
+}
+
+func userNetworkPolicy() *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.NetworkPolicyName,
+			Namespace: "testing",
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	deleteNetworkPolicy := clientgotesting.DeleteActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Namespace: "testing",
+			Verb:      "delete",
+			Resource:  networkingv1.SchemeGroupVersion.WithResource("networkpolicies"),
+		},
+		Name: resources.NetworkPolicyName,
+	}
+
+	table := TableTest{{
+		Name: "disabled",
+		Key:  "testing",
+		Ctx:  withConfig(false, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			gatewayService,
+		},
+	}, {
+		Name: "disabled removes the managed NetworkPolicy",
+		Key:  "testing",
+		Ctx:  withConfig(false, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			gatewayService,
+			networkPolicy(gatewayService),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteNetworkPolicy},
+	}, {
+		Name: "create for Knative namespace",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			gatewayService,
+		},
+		WantCreates: []runtime.Object{
+			networkPolicy(gatewayService),
+		},
+	}, {
+		Name: "ignore Ingresses of other classes",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing("other.ingress.networking.knative.dev"),
+			gatewayService,
+		},
+	}, {
+		Name: "update on gateway change",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			gatewayService,
+			networkPolicy(),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: networkPolicy(gatewayService),
+		}},
+	}, {
+		Name: "steady state",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			gatewayService,
+			networkPolicy(gatewayService),
+		},
+	}, {
+		Name: "namespace has a NetworkPolicy of the user with the same name",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			gatewayService,
+			userNetworkPolicy(),
+		},
+	}, {
+		Name: "remove when the namespace no longer hosts Knative services",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			gatewayService,
+			networkPolicy(gatewayService),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteNetworkPolicy},
+	}, {
+		Name:    "missing gateway Service",
+		Key:     "testing",
+		Ctx:     withConfig(true, gateways),
+		WantErr: true,
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+		},
+	}, {
+		Name:    "invalid gateway",
+		Key:     "testing",
+		Ctx:     withConfig(true, []config.Gateway{{ServiceURL: "invalid"}}),
+		WantErr: true,
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+		},
+	}}
+
+	for i := range table {
+		// The keys are namespaces, which the table test cannot validate against.
+		table[i].SkipNamespaceValidation = true
+	}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &reconciler{
+			kubeclient:          kubeclient.Get(ctx),
+			ingressLister:       listers.GetIngressLister(),
+			networkPolicyLister: listers.GetNetworkPolicyLister(),
+			svcLister:           listers.GetK8sServiceLister(),
+			configStore: &testConfigStore{
+				config: ctx.Value(cfgKey{}).(*config.Config),
+			},
+		}
+	}))
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
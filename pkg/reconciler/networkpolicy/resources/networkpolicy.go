@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/pkg/system"
+)
+
+const (
+	// ManagedLabelKey is the label attached to the NetworkPolicies maintained by net-istio.
+	// NetworkPolicies without it are never modified.
+	ManagedLabelKey = networking.GroupName + "/network-policy"
+
+	// NetworkPolicyName is the name of the NetworkPolicy maintained for namespaces hosting
+	// Knative services.
+	NetworkPolicyName = "knative-serving"
+
+	// revisionLabelKey is the label Knative Serving attaches to the pods of every revision.
+	revisionLabelKey = "serving.knative.dev/revision"
+)
+
+var (
+	// queueProxyPorts are the HTTP, H2C and HTTPS ports the queue-proxies serve requests on.
+	queueProxyPorts = []int{8012, 8013, 8112}
+
+	// queueProxyMetricsPorts are the ports the queue-proxies expose their metrics on.
+	queueProxyMetricsPorts = []int{9090, 9091}
+
+	// activatorSelector selects the pods of the activator.
+	activatorSelector = map[string]string{"app": "activator"}
+)
+
+// MakeNetworkPolicy creates a NetworkPolicy that only lets the pods backing the given
+// gateway Services and the activator reach the queue-proxy ports of the Knative pods in
+// the given namespace. The metrics ports of the queue-proxies remain reachable from anywhere.
+func MakeNetworkPolicy(namespace string, gateways []*corev1.Service) *networkingv1.NetworkPolicy {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(gateways)+1)
+	for _, gw := range gateways {
+		peers = append(peers, podsInNamespace(gw.Namespace, gw.Spec.Selector))
+	}
+	peers = append(peers, podsInNamespace(system.Namespace(), activatorSelector))
+	// Sort the peers so that the spec does not depend on the order of the gateways.
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].NamespaceSelector.MatchLabels[corev1.LabelMetadataName] <
+			peers[j].NamespaceSelector.MatchLabels[corev1.LabelMetadataName]
+	})
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NetworkPolicyName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedLabelKey: "true",
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      revisionLabelKey,
+					Operator: metav1.LabelSelectorOpExists,
+				}},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  peers,
+				Ports: tcpPorts(queueProxyPorts),
+			}, {
+				Ports: tcpPorts(queueProxyMetricsPorts),
+			}},
+		},
+	}
+}
+
+func podsInNamespace(namespace string, selector map[string]string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{corev1.LabelMetadataName: namespace},
+		},
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: selector,
+		},
+	}
+}
+
+func tcpPorts(ports []int) []networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	ret := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, p := range ports {
+		port := intstr.FromInt(p)
+		ret = append(ret, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+func gatewayService(namespace, name string, selector map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selector,
+		},
+	}
+}
+
+func TestMakeNetworkPolicy(t *testing.T) {
+	np := MakeNetworkPolicy("testing", []*corev1.Service{
+		gatewayService("istio-system", "istio-ingressgateway", map[string]string{"istio": "ingressgateway"}),
+		gatewayService("gateways", "knative-local-gateway", map[string]string{"istio": "local-gateway"}),
+	})
+
+	if np.Name != NetworkPolicyName || np.Namespace != "testing" || np.Labels[ManagedLabelKey] != "true" {
+		t.Errorf("Unexpected metadata: %v", np.ObjectMeta)
+	}
+	if got, want := np.Spec.PolicyTypes, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}; !cmp.Equal(got, want) {
+		t.Errorf("PolicyTypes = %v, want %v", got, want)
+	}
+	if got := np.Spec.PodSelector.MatchExpressions; len(got) != 1 || got[0].Key != revisionLabelKey || got[0].Operator != metav1.LabelSelectorOpExists {
+		t.Errorf("Unexpected pod selector: %v", np.Spec.PodSelector)
+	}
+
+	if len(np.Spec.Ingress) != 2 {
+		t.Fatalf("len(Ingress) = %d, want 2", len(np.Spec.Ingress))
+	}
+	type peer struct {
+		namespace string
+		labels    map[string]string
+	}
+	var gotPeers []peer
+	for _, p := range np.Spec.Ingress[0].From {
+		gotPeers = append(gotPeers, peer{
+			namespace: p.NamespaceSelector.MatchLabels[corev1.LabelMetadataName],
+			labels:    p.PodSelector.MatchLabels,
+		})
+	}
+	wantPeers := []peer{
+		{namespace: "gateways", labels: map[string]string{"istio": "local-gateway"}},
+		{namespace: "istio-system", labels: map[string]string{"istio": "ingressgateway"}},
+		{namespace: system.Namespace(), labels: map[string]string{"app": "activator"}},
+	}
+	if !cmp.Equal(wantPeers, gotPeers, cmp.AllowUnexported(peer{})) {
+		t.Error("Unexpected peers (-want +got):", cmp.Diff(wantPeers, gotPeers, cmp.AllowUnexported(peer{})))
+	}
+
+	ports := func(rule networkingv1.NetworkPolicyIngressRule) []int {
+		var ret []int
+		for _, p := range rule.Ports {
+			if *p.Protocol != corev1.ProtocolTCP {
+				t.Errorf("Protocol = %s, want TCP", *p.Protocol)
+			}
+			ret = append(ret, p.Port.IntValue())
+		}
+		return ret
+	}
+	if got, want := ports(np.Spec.Ingress[0]), []int{8012, 8013, 8112}; !cmp.Equal(got, want) {
+		t.Errorf("Queue-proxy ports = %v, want %v", got, want)
+	}
+	if got, want := ports(np.Spec.Ingress[1]), []int{9090, 9091}; !cmp.Equal(got, want) {
+		t.Errorf("Metrics ports = %v, want %v", got, want)
+	}
+	if np.Spec.Ingress[1].From != nil {
+		t.Errorf("Metrics ports are restricted to %v, want any peer", np.Spec.Ingress[1].From)
+	}
+}
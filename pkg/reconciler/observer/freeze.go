@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+)
+
+// frozen is whether the emergency freeze is on, as toggled through config-istio.
+var frozen atomic.Bool
+
+var driftM = stats.Int64(
+	"emergency_freeze_drift_count",
+	"Number of writes sent as dry runs because of the emergency freeze",
+	stats.UnitDimensionless)
+
+func init() {
+	if err := metrics.RegisterResourceView(&view.View{
+		Description: driftM.Description(),
+		Measure:     driftM,
+		Aggregation: view.Count(),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// SetFrozen turns the emergency freeze on or off. While it is on, the writes of the clients
+// are sent as dry runs and reported as drift like in the observer mode, except for the ones
+// reporting the state of the Ingresses, so that the incident can still be followed.
+func SetFrozen(ctx context.Context, freeze bool) {
+	if frozen.Swap(freeze) == freeze {
+		return
+	}
+	if freeze {
+		logging.FromContext(ctx).Warn("Emergency freeze: the controller stops changing the cluster")
+	} else {
+		logging.FromContext(ctx).Info("Emergency freeze lifted: the controller applies the drift")
+	}
+}
+
+// Frozen returns whether the emergency freeze is on.
+func Frozen() bool {
+	return frozen.Load()
+}
+
+// isReport returns whether the write only reports the state of the Ingresses, through
+// their status or Events, and is thus still allowed during the emergency freeze.
+func isReport(req *http.Request) bool {
+	path := req.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/apis/events.k8s.io/"):
+		return true
+	case strings.HasPrefix(path, "/api/v1/") && strings.Contains(path, "/events"):
+		return true
+	default:
+		return strings.HasPrefix(path, "/apis/networking.internal.knative.dev/") &&
+			strings.Contains(path, "/ingresses/") && strings.HasSuffix(path, "/status")
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package observer implements the read-only observer mode of net-istio, in which
+// the reconcilers compute the desired state as usual but none of their writes are
+// persisted. Every write is sent to the API server as a server-side dry run, so that
+// it is still validated by the API server and the admission webhooks (e.g. Istio's),
+// and is reported through the logs and the observer_mode_skipped_write_count metric.
+//
+// This lets an upgrade or a configuration change be validated against a production
+// cluster before it is allowed to mutate anything.
+//
+// The emergency freeze, toggled through the emergency-freeze key of config-istio during
+// incidents, sends the writes as dry runs the same way without restarting the controller.
+// The writes are then reported as drift through the emergency_freeze_drift_count metric.
+package observer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	networkingclientset "knative.dev/networking/pkg/client/clientset/versioned"
+	networkingclient "knative.dev/networking/pkg/client/injection/client"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+)
+
+// ObserverModeEnv enables the read-only observer mode.
+const ObserverModeEnv = "OBSERVER_MODE"
+
+// exemptPathPrefixes are the prefixes of the API paths written as usual in the observer mode: the
+// leases let the replicas elect their leader and the access reviews of the preflight checks only
+// query the API server, neither of which affects the cluster.
+var exemptPathPrefixes = []string{
+	"/apis/coordination.k8s.io/",
+	"/apis/authorization.k8s.io/",
+}
+
+var skippedWriteM = stats.Int64(
+	"observer_mode_skipped_write_count",
+	"Number of writes sent as dry runs because of the observer mode",
+	stats.UnitDimensionless)
+
+func init() {
+	if err := metrics.RegisterResourceView(&view.View{
+		Description: skippedWriteM.Description(),
+		Measure:     skippedWriteM,
+		Aggregation: view.Count(),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// Enabled returns whether the observer mode is enabled through OBSERVER_MODE.
+func Enabled() bool {
+	b, _ := strconv.ParseBool(os.Getenv(ObserverModeEnv))
+	return b
+}
+
+// RegisterClients makes the injected Kubernetes, Istio and Knative networking clients
+// send their writes as dry runs when the observer mode is enabled or the controller frozen.
+//
+// This must be called before the injected clients are set up.
+func RegisterClients() {
+	// Clients are set up in registration order, so this replaces the generated clients.
+	injection.Default.RegisterClient(func(ctx context.Context, cfg *rest.Config) context.Context {
+		cfg = WrapConfig(cfg)
+		ctx = context.WithValue(ctx, kubeclient.Key{}, kubernetes.NewForConfigOrDie(cfg))
+		ctx = context.WithValue(ctx, istioclient.Key{}, istioclientset.NewForConfigOrDie(cfg))
+		return context.WithValue(ctx, networkingclient.Key{}, networkingclientset.NewForConfigOrDie(cfg))
+	})
+}
+
+// WrapConfig returns a copy of the config whose writes are sent as dry runs when the
+// observer mode is enabled or the controller frozen.
+func WrapConfig(cfg *rest.Config) *rest.Config {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &dryRunTransport{next: rt}
+	})
+	return cfg
+}
+
+// dryRunTransport sends the writes as server-side dry runs and reports them.
+type dryRunTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	observing := Enabled()
+	if !isWrite(req) || isExempt(req) || !observing && (!Frozen() || isReport(req)) {
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	t.report(req, body, observing)
+
+	dryRun := req.Clone(req.Context())
+	query := dryRun.URL.Query()
+	query.Set("dryRun", metav1.DryRunAll)
+	dryRun.URL.RawQuery = query.Encode()
+	dryRun.Body = io.NopCloser(bytes.NewReader(body))
+	dryRun.ContentLength = int64(len(body))
+	return t.next.RoundTrip(dryRun)
+}
+
+// report logs the write, along with the changes it would make when they can be told
+// without revealing the material of Secrets.
+func (t *dryRunTransport) report(req *http.Request, body []byte, observing bool) {
+	ctx := req.Context()
+	mode := "Observer mode"
+	if observing {
+		metrics.Record(ctx, skippedWriteM.M(1))
+	} else {
+		mode = "Emergency freeze"
+		metrics.Record(ctx, driftM.M(1))
+	}
+
+	logger := logging.FromContext(ctx).With(zap.String("method", req.Method), zap.String("path", req.URL.Path))
+	switch {
+	case isSecret(req):
+		logger.Info(mode + ": skipped the write of a Secret")
+	case req.Method == http.MethodPatch:
+		logger.Infow(mode+": skipped the patch", zap.ByteString("patch", body))
+	case req.Method == http.MethodPut:
+		logger.Infow(mode+": skipped the update", zap.String("diff", t.diff(req, body)))
+	default:
+		logger.Info(mode + ": skipped the write")
+	}
+}
+
+// diff returns the changes the update would make to the current object.
+func (t *dryRunTransport) diff(req *http.Request, body []byte) string {
+	url := *req.URL
+	url.RawQuery = ""
+	get, err := http.NewRequestWithContext(req.Context(), http.MethodGet, url.String(), nil)
+	if err != nil {
+		return "unknown: " + err.Error()
+	}
+	get.Header = req.Header.Clone()
+	get.Header.Del("Content-Type")
+	resp, err := t.next.RoundTrip(get)
+	if err != nil {
+		return "unknown: " + err.Error()
+	}
+	defer resp.Body.Close()
+	current, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "unknown: " + err.Error()
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(current, &before); err != nil {
+		return "unknown: " + err.Error()
+	}
+	if err := json.Unmarshal(body, &after); err != nil {
+		return "unknown: " + err.Error()
+	}
+	// The server maintains these fields, which the writes of the controller carry over.
+	for _, obj := range []map[string]interface{}{before, after} {
+		if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+			delete(meta, "managedFields")
+			delete(meta, "resourceVersion")
+		}
+	}
+	return cmp.Diff(before, after)
+}
+
+func isWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func isExempt(req *http.Request) bool {
+	for _, prefix := range exemptPathPrefixes {
+		if strings.Contains(req.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSecret(req *http.Request) bool {
+	return strings.Contains(req.URL.Path, "/api/v1/") && strings.Contains(req.URL.Path, "/secrets")
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+type request struct {
+	method, path, dryRun, body string
+}
+
+func TestWrapConfig(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, request{method: r.Method, path: r.URL.Path, dryRun: r.URL.Query().Get("dryRun"), body: string(body)})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"metadata":{"name":"vs","resourceVersion":"1"},"spec":{"hosts":["a.example.com"]}}`))
+	}))
+	defer server.Close()
+
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = nil
+	}
+	received := func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]request(nil), requests...)
+	}
+	send := func(t *testing.T, cfg *rest.Config, method, path, body string) {
+		t.Helper()
+		client, err := rest.HTTPClientFor(cfg)
+		if err != nil {
+			t.Fatal("HTTPClientFor() =", err)
+		}
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal("NewRequest() =", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s = %v", method, path, err)
+		}
+		resp.Body.Close()
+	}
+
+	const (
+		vsPath            = "/apis/networking.istio.io/v1beta1/namespaces/ns/virtualservices/vs"
+		leasePath         = "/apis/coordination.k8s.io/v1/namespaces/ns/leases/lease"
+		ingressPath       = "/apis/networking.internal.knative.dev/v1alpha1/namespaces/ns/ingresses/ing"
+		ingressStatusPath = ingressPath + "/status"
+		eventPath         = "/api/v1/namespaces/ns/events"
+		vsUpdate          = `{"metadata":{"name":"vs"},"spec":{"hosts":["b.example.com"]}}`
+	)
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Setenv(ObserverModeEnv, "false")
+		reset()
+		cfg := WrapConfig(&rest.Config{Host: server.URL})
+		send(t, cfg, http.MethodPut, vsPath, vsUpdate)
+		if got := received(); len(got) != 1 || got[0].dryRun != "" {
+			t.Errorf("Requests = %+v, want a single write", got)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv(ObserverModeEnv, "true")
+		reset()
+		cfg := WrapConfig(&rest.Config{Host: server.URL})
+		send(t, cfg, http.MethodGet, vsPath, "")
+		send(t, cfg, http.MethodPut, vsPath, vsUpdate)
+		send(t, cfg, http.MethodDelete, vsPath, "")
+		send(t, cfg, http.MethodPut, leasePath, "{}")
+
+		want := []request{
+			{method: http.MethodGet, path: vsPath},
+			// The current object is read to report the changes of the update.
+			{method: http.MethodGet, path: vsPath},
+			{method: http.MethodPut, path: vsPath, dryRun: "All", body: vsUpdate},
+			{method: http.MethodDelete, path: vsPath, dryRun: "All"},
+			{method: http.MethodPut, path: leasePath, body: "{}"},
+		}
+		got := received()
+		if len(got) != len(want) {
+			t.Fatalf("Requests = %+v, want %+v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Request %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("frozen", func(t *testing.T) {
+		t.Setenv(ObserverModeEnv, "false")
+		SetFrozen(context.Background(), true)
+		t.Cleanup(func() { SetFrozen(context.Background(), false) })
+		reset()
+		cfg := WrapConfig(&rest.Config{Host: server.URL})
+		send(t, cfg, http.MethodDelete, vsPath, "")
+		send(t, cfg, http.MethodPatch, ingressPath, "{}")
+		send(t, cfg, http.MethodPut, ingressStatusPath, "{}")
+		send(t, cfg, http.MethodPost, eventPath, "{}")
+		send(t, cfg, http.MethodPut, leasePath, "{}")
+
+		want := []request{
+			{method: http.MethodDelete, path: vsPath, dryRun: "All"},
+			// The finalizers of the Ingresses are not changed, so they are not deleted either.
+			{method: http.MethodPatch, path: ingressPath, dryRun: "All", body: "{}"},
+			{method: http.MethodPut, path: ingressStatusPath, body: "{}"},
+			{method: http.MethodPost, path: eventPath, body: "{}"},
+			{method: http.MethodPut, path: leasePath, body: "{}"},
+		}
+		got := received()
+		if len(got) != len(want) {
+			t.Fatalf("Requests = %+v, want %+v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Request %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+
+		SetFrozen(context.Background(), false)
+		reset()
+		send(t, cfg, http.MethodDelete, vsPath, "")
+		if got := received(); len(got) != 1 || got[0].dryRun != "" {
+			t.Errorf("Requests = %+v after lifting the freeze, want a single write", got)
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"metadata":{"name":"vs","resourceVersion":"1","managedFields":[{}]},"spec":{"hosts":["a.example.com"]}}`))
+	}))
+	defer server.Close()
+
+	transport := &dryRunTransport{next: http.DefaultTransport}
+	req := httptest.NewRequest(http.MethodPut, server.URL+"/apis/networking.istio.io/v1beta1/namespaces/ns/virtualservices/vs", nil)
+
+	diff := transport.diff(req, []byte(`{"metadata":{"name":"vs","resourceVersion":"1"},"spec":{"hosts":["b.example.com"]}}`))
+	if !strings.Contains(diff, "a.example.com") || !strings.Contains(diff, "b.example.com") {
+		t.Errorf("diff() = %q, want the change of the hosts", diff)
+	}
+	if strings.Contains(diff, "managedFields") || strings.Contains(diff, "resourceVersion") {
+		t.Errorf("diff() = %q, want the fields maintained by the server left out", diff)
+	}
+
+	if diff := transport.diff(req, []byte(`{"metadata":{"name":"vs"},"spec":{"hosts":["a.example.com"]}}`)); diff != "" {
+		t.Errorf("diff() = %q for an unchanged object, want none", diff)
+	}
+}
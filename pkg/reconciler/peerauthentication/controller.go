@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerauthentication
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	istioinformers "knative.dev/net-istio/pkg/client/istio/informers/externalversions"
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	ingressresources "knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/peerauthentication/resources"
+	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
+	serviceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/service"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// NewController initializes the controller maintaining PeerAuthentications for
+// namespaces hosting Knative services and for the gateways. The workqueue is keyed
+// by namespace.
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+
+	logger := logging.FromContext(ctx)
+	ingressInformer := ingressinformer.Get(ctx)
+	factory := istioinformers.NewSharedInformerFactory(istioclient.Get(ctx), controller.GetResyncPeriod(ctx))
+	peerAuthenticationInformer := factory.Security().V1beta1().PeerAuthentications()
+	serviceInformer := serviceinformer.Get(ctx)
+
+	r := &reconciler{
+		istioClientSet:           istioclient.Get(ctx),
+		ingressLister:            ingressInformer.Lister(),
+		peerAuthenticationLister: peerAuthenticationInformer.Lister(),
+		svcLister:                serviceInformer.Lister(),
+	}
+	impl := controller.NewContext(ctx, r, controller.ControllerOptions{
+		WorkQueueName: "PeerAuthentications",
+		Logger:        logger,
+	})
+
+	configStore := config.NewStore(logger.Named("config-store"), func(_ string, value interface{}) {
+		// Ingresses and PeerAuthentications cover the Knative namespaces, including
+		// the ones whose PeerAuthentications need to be removed.
+		ings, _ := ingressInformer.Lister().List(labels.Everything())
+		for _, ing := range ings {
+			impl.EnqueueNamespaceOf(ing)
+		}
+		pas, _ := peerAuthenticationInformer.Lister().List(labels.SelectorFromSet(labels.Set{resources.ManagedLabelKey: "true"}))
+		for _, pa := range pas {
+			impl.EnqueueNamespaceOf(pa)
+		}
+		if istio, ok := value.(*config.Istio); ok {
+			for _, gw := range append(istio.IngressGateways, istio.LocalGateways...) {
+				if meta, err := ingressresources.GetGatewaySvcNameNamespace(gw); err == nil {
+					impl.EnqueueKey(types.NamespacedName{Name: meta.Namespace})
+				}
+			}
+		}
+	})
+	configStore.WatchConfigs(cmw)
+	r.configStore = configStore
+
+	ingressInformer.Informer().AddEventHandler(controller.HandleAll(impl.EnqueueNamespaceOf))
+	peerAuthenticationInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: pkgreconciler.LabelFilterFunc(resources.ManagedLabelKey, "true", false),
+		Handler:    controller.HandleAll(impl.EnqueueNamespaceOf),
+	})
+
+	// The informer is not injected, so that it is only started when the controller is
+	// enabled, and sharedmain does not start it.
+	factory.Start(ctx.Done())
+	for informer, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logger.Fatalf("Failed to sync the %v informer", informer)
+		}
+	}
+
+	return impl
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerauthentication
+
+import (
+	"context"
+	"fmt"
+
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	securitylisters "knative.dev/net-istio/pkg/client/istio/listers/security/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	ingressresources "knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/net-istio/pkg/reconciler/knativens"
+	"knative.dev/net-istio/pkg/reconciler/peerauthentication/resources"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// reconciler maintains the PeerAuthentications of a single namespace.
+type reconciler struct {
+	istioClientSet istioclientset.Interface
+
+	ingressLister            networkinglisters.IngressLister
+	peerAuthenticationLister securitylisters.PeerAuthenticationLister
+	svcLister                corev1listers.ServiceLister
+
+	configStore pkgreconciler.ConfigStore
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+
+// Reconcile converges the PeerAuthentications of the namespace in the key to the
+// configured mTLS mode.
+func (r *reconciler) Reconcile(ctx context.Context, key string) error {
+	ctx = r.configStore.ToContext(ctx)
+	logger := logging.FromContext(ctx)
+
+	// The keys are namespaces, which are cluster scoped.
+	_, namespace, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Errorw("Invalid resource key", key)
+		return nil
+	}
+
+	desired, err := r.desiredPeerAuthentications(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	kept := sets.New[string]()
+	for _, pa := range desired {
+		kept.Insert(pa.Name)
+		if err := r.reconcilePeerAuthentication(ctx, pa); err != nil {
+			return err
+		}
+	}
+
+	existing, err := r.peerAuthenticationLister.PeerAuthentications(namespace).List(
+		labels.SelectorFromSet(labels.Set{resources.ManagedLabelKey: "true"}))
+	if err != nil {
+		return fmt.Errorf("failed to list PeerAuthentications: %w", err)
+	}
+	for _, pa := range existing {
+		if kept.Has(pa.Name) {
+			continue
+		}
+		if err := r.istioClientSet.SecurityV1beta1().PeerAuthentications(namespace).Delete(ctx, pa.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete PeerAuthentication: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *reconciler) desiredPeerAuthentications(ctx context.Context, namespace string) ([]*v1beta1.PeerAuthentication, error) {
+	cfg := config.FromContext(ctx)
+	mode, ok := istiov1beta1.PeerAuthentication_MutualTLS_Mode_value[cfg.Istio.PeerAuthenticationMode]
+	if !ok {
+		// PeerAuthentications are disabled.
+		return nil, nil
+	}
+
+	var desired []*v1beta1.PeerAuthentication
+
+	hosting, err := knativens.HostsKnativeServices(r.ingressLister, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if hosting {
+		nsMode := istiov1beta1.PeerAuthentication_MutualTLS_Mode(mode)
+		if nsMode == istiov1beta1.PeerAuthentication_MutualTLS_STRICT && cfg.Network.SystemInternalTLSEnabled() {
+			// The gateways originate Knative's own TLS towards the workloads, which the
+			// sidecars would reject if they only accepted Istio mTLS.
+			logging.FromContext(ctx).Warnf("system-internal-tls is enabled, using PERMISSIVE instead of STRICT mTLS for namespace %s", namespace)
+			nsMode = istiov1beta1.PeerAuthentication_MutualTLS_PERMISSIVE
+		}
+		desired = append(desired, resources.MakeNamespacePeerAuthentication(namespace, nsMode))
+	}
+
+	for _, gw := range append(cfg.Istio.IngressGateways, cfg.Istio.LocalGateways...) {
+		meta, err := ingressresources.GetGatewaySvcNameNamespace(gw)
+		if err != nil {
+			return nil, err
+		}
+		if meta.Namespace != namespace {
+			continue
+		}
+		svc, err := r.svcLister.Services(meta.Namespace).Get(meta.Name)
+		if apierrs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get gateway service: %w", err)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		desired = append(desired, resources.MakeGatewayPeerAuthentication(svc, istiov1beta1.PeerAuthentication_MutualTLS_Mode(mode)))
+	}
+	return desired, nil
+}
+
+func (r *reconciler) reconcilePeerAuthentication(ctx context.Context, desired *v1beta1.PeerAuthentication) error {
+	ns, name := desired.Namespace, desired.Name
+	pa, err := r.peerAuthenticationLister.PeerAuthentications(ns).Get(name)
+	if apierrs.IsNotFound(err) {
+		if _, err := r.istioClientSet.SecurityV1beta1().PeerAuthentications(ns).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create PeerAuthentication: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if pa.Labels[resources.ManagedLabelKey] != "true" {
+		// Never take over PeerAuthentications created by users.
+		logging.FromContext(ctx).Warnf("PeerAuthentication %s/%s is not managed by net-istio, skipping", ns, name)
+		return nil
+	}
+	if kaccessor.SpecHashMatches(pa, &pa.Spec, desired) {
+		return nil
+	}
+
+	// Don't modify the informers copy
+	existing := pa.DeepCopy()
+	existing.Spec = *desired.Spec.DeepCopy()
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	if _, err := r.istioClientSet.SecurityV1beta1().PeerAuthentications(ns).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update PeerAuthentication: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerauthentication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	// Inject our fakes
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/peerauthentication/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var (
+	defaultCmpOpts = []cmp.Option{protocmp.Transform()}
+
+	strict     = istiov1beta1.PeerAuthentication_MutualTLS_STRICT
+	permissive = istiov1beta1.PeerAuthentication_MutualTLS_PERMISSIVE
+
+	gatewayService = &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istio-ingressgateway",
+			Namespace: "istio-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+		},
+	}
+)
+
+type cfgKey struct{}
+
+func withConfig(mode string, internalTLS bool) context.Context {
+	cfg := &config.Config{
+		Istio: &config.Istio{
+			PeerAuthenticationMode: mode,
+			IngressGateways: []config.Gateway{{
+				Namespace:  "knative-serving",
+				Name:       config.KnativeIngressGateway,
+				ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+			}},
+		},
+		Network: &netconfig.Config{},
+	}
+	if internalTLS {
+		cfg.Network.SystemInternalTLS = netconfig.EncryptionEnabled
+	}
+	return context.WithValue(context.Background(), cfgKey{}, cfg)
+}
+
+func ing(namespace, class string) *netv1alpha1.Ingress {
+	return &netv1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: namespace,
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: class,
+			},
+		},
+	}
+}
+
+func unmanaged(pa *v1beta1.PeerAuthentication) *v1beta1.PeerAuthentication {
+	pa.Labels = nil
+	return pa
+}
+
+func TestReconcile(t *testing.T) {
+	table := TableTest{{
+		Name: "disabled",
+		Key:  "testing",
+		Ctx:  withConfig("", false),
+		Objects: []runtime.Object{
+			ing("testing", netconfig.IstioIngressClassName),
+		},
+	}, {
+		Name: "disabled removes managed PeerAuthentications",
+		Key:  "testing",
+		Ctx:  withConfig("", false),
+		Objects: []runtime.Object{
+			ing("testing", netconfig.IstioIngressClassName),
+			resources.MakeNamespacePeerAuthentication("testing", strict),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "testing",
+				Verb:      "delete",
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("peerauthentications"),
+			},
+			Name: resources.NamespacePeerAuthenticationName,
+		}},
+	}, {
+		Name: "create for Knative namespace",
+		Key:  "testing",
+		Ctx:  withConfig("STRICT", false),
+		Objects: []runtime.Object{
+			ing("testing", netconfig.IstioIngressClassName),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeNamespacePeerAuthentication("testing", strict),
+		},
+	}, {
+		Name: "ignore Ingresses of other classes",
+		Key:  "testing",
+		Ctx:  withConfig("STRICT", false),
+		Objects: []runtime.Object{
+			ing("testing", "other.ingress.networking.knative.dev"),
+		},
+	}, {
+		Name: "permissive with system-internal-tls",
+		Key:  "testing",
+		Ctx:  withConfig("STRICT", true),
+		Objects: []runtime.Object{
+			ing("testing", netconfig.IstioIngressClassName),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeNamespacePeerAuthentication("testing", permissive),
+		},
+	}, {
+		Name: "update mode",
+		Key:  "testing",
+		Ctx:  withConfig("STRICT", false),
+		Objects: []runtime.Object{
+			ing("testing", netconfig.IstioIngressClassName),
+			resources.MakeNamespacePeerAuthentication("testing", permissive),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: resources.MakeNamespacePeerAuthentication("testing", strict),
+		}},
+	}, {
+		Name: "do not take over user PeerAuthentications",
+		Key:  "testing",
+		Ctx:  withConfig("STRICT", false),
+		Objects: []runtime.Object{
+			ing("testing", netconfig.IstioIngressClassName),
+			unmanaged(resources.MakeNamespacePeerAuthentication("testing", permissive)),
+		},
+	}, {
+		Name: "remove when the namespace no longer hosts Knative services",
+		Key:  "testing",
+		Ctx:  withConfig("STRICT", false),
+		Objects: []runtime.Object{
+			resources.MakeNamespacePeerAuthentication("testing", strict),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "testing",
+				Verb:      "delete",
+				Resource:  v1beta1.SchemeGroupVersion.WithResource("peerauthentications"),
+			},
+			Name: resources.NamespacePeerAuthenticationName,
+		}},
+	}, {
+		Name: "create for gateway",
+		Key:  "istio-system",
+		Ctx:  withConfig("STRICT", true),
+		Objects: []runtime.Object{
+			gatewayService,
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeGatewayPeerAuthentication(gatewayService, strict),
+		},
+	}, {
+		Name: "gateway service missing",
+		Key:  "istio-system",
+		Ctx:  withConfig("STRICT", false),
+	}}
+
+	for i := range table {
+		// The keys are namespaces, which the table test cannot validate against.
+		table[i].SkipNamespaceValidation = true
+		table[i].CmpOpts = defaultCmpOpts
+	}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &reconciler{
+			istioClientSet:           istioclient.Get(ctx),
+			ingressLister:            listers.GetIngressLister(),
+			peerAuthenticationLister: listers.GetPeerAuthenticationLister(),
+			svcLister:                listers.GetK8sServiceLister(),
+			configStore: &testConfigStore{
+				config: ctx.Value(cfgKey{}).(*config.Config),
+			},
+		}
+	}))
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/security/v1beta1"
+	istiotypev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/security/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/networking/pkg/apis/networking"
+)
+
+const (
+	// ManagedLabelKey is the label attached to the PeerAuthentications maintained by
+	// net-istio. PeerAuthentications without it are never modified.
+	ManagedLabelKey = networking.GroupName + "/peer-authentication"
+
+	// NamespacePeerAuthenticationName is the name of the PeerAuthentication
+	// maintained for namespaces hosting Knative services.
+	NamespacePeerAuthenticationName = "knative-serving"
+)
+
+// MakeNamespacePeerAuthentication creates a PeerAuthentication that applies the given
+// mTLS mode to all workloads of the namespace.
+func MakeNamespacePeerAuthentication(namespace string, mode istiov1beta1.PeerAuthentication_MutualTLS_Mode) *v1beta1.PeerAuthentication {
+	return makePeerAuthentication(NamespacePeerAuthenticationName, namespace, nil, mode)
+}
+
+// MakeGatewayPeerAuthentication creates a PeerAuthentication that applies the given
+// mTLS mode to the workloads behind the given gateway Service.
+func MakeGatewayPeerAuthentication(svc *corev1.Service, mode istiov1beta1.PeerAuthentication_MutualTLS_Mode) *v1beta1.PeerAuthentication {
+	return makePeerAuthentication("knative-"+svc.Name, svc.Namespace, &istiotypev1beta1.WorkloadSelector{
+		MatchLabels: svc.Spec.Selector,
+	}, mode)
+}
+
+func makePeerAuthentication(name, namespace string, selector *istiotypev1beta1.WorkloadSelector,
+	mode istiov1beta1.PeerAuthentication_MutualTLS_Mode) *v1beta1.PeerAuthentication {
+	pa := &v1beta1.PeerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedLabelKey: "true",
+			},
+		},
+		Spec: istiov1beta1.PeerAuthentication{
+			Selector: selector,
+			Mtls: &istiov1beta1.PeerAuthentication_MutualTLS{
+				Mode: mode,
+			},
+		},
+	}
+	pa.Annotations = kaccessor.WithSpecHash(pa.Annotations, &pa.Spec)
+	return pa
+}
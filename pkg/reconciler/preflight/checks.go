@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/pkg/system"
+)
+
+// requiredIstioAPIs are the Istio APIs, in the versions the controller uses, that must be
+// served by the cluster running the Istio control plane.
+var requiredIstioAPIs = []schema.GroupVersionResource{
+	{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"},
+	{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"},
+	{Group: "networking.istio.io", Version: "v1beta1", Resource: "destinationrules"},
+	{Group: "networking.istio.io", Version: "v1beta1", Resource: "sidecars"},
+	{Group: "security.istio.io", Version: "v1beta1", Resource: "authorizationpolicies"},
+	{Group: "security.istio.io", Version: "v1beta1", Resource: "peerauthentications"},
+}
+
+// permission is a set of verbs the controller must be allowed on a resource of all namespaces.
+type permission struct {
+	group, resource, subresource string
+	verbs                        []string
+}
+
+var (
+	readWrite = []string{"get", "list", "watch", "create", "update", "delete"}
+	readOnly  = []string{"get", "list", "watch"}
+)
+
+// knativePermissions are the permissions needed in the cluster running Knative.
+var knativePermissions = []permission{
+	{group: "networking.internal.knative.dev", resource: "ingresses", verbs: []string{"get", "list", "watch", "update"}},
+	{group: "networking.internal.knative.dev", resource: "ingresses", subresource: "status", verbs: []string{"update"}},
+	{group: "networking.internal.knative.dev", resource: "serverlessservices", verbs: readOnly},
+	{resource: "secrets", verbs: readOnly},
+	{resource: "services", verbs: readOnly},
+	{resource: "configmaps", verbs: readOnly},
+}
+
+// istioPermissions are the permissions needed in the cluster running the Istio control plane.
+var istioPermissions = []permission{
+	{group: "networking.istio.io", resource: "virtualservices", verbs: readWrite},
+	{group: "networking.istio.io", resource: "gateways", verbs: readWrite},
+	{group: "networking.istio.io", resource: "destinationrules", verbs: readWrite},
+	{group: "networking.istio.io", resource: "sidecars", verbs: readWrite},
+	{group: "security.istio.io", resource: "authorizationpolicies", verbs: readWrite},
+	{group: "security.istio.io", resource: "peerauthentications", verbs: readWrite},
+}
+
+// Checks returns the preflight checks of the Ingress controller. The kubeclient targets the
+// cluster running Knative, and the Istio and control plane clients the cluster running the
+// Istio control plane, which may be the same.
+func Checks(kubeclient kubernetes.Interface, istioclient istioclientset.Interface, controlPlane kubernetes.Interface) []Check {
+	return []Check{{
+		Name: "IstioAPIs",
+		Run: func(context.Context) error {
+			return checkIstioAPIs(istioclient)
+		},
+	}, {
+		Name: "Gateways",
+		Run: func(ctx context.Context) error {
+			return checkGateways(ctx, kubeclient, istioclient)
+		},
+	}, {
+		Name: "KnativePermissions",
+		Run: func(ctx context.Context) error {
+			return checkPermissions(ctx, kubeclient, knativePermissions)
+		},
+	}, {
+		Name: "IstioPermissions",
+		Run: func(ctx context.Context) error {
+			return checkPermissions(ctx, controlPlane, istioPermissions)
+		},
+	}}
+}
+
+// checkIstioAPIs verifies that the required Istio APIs are served in the versions in use.
+func checkIstioAPIs(istioclient istioclientset.Interface) error {
+	var errs []error
+	for _, gvr := range requiredIstioAPIs {
+		resources, err := istioclient.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+		if apierrs.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("%s is not served, the Istio CRDs are missing or too old", gvr.GroupVersion()))
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to discover %s: %w", gvr.GroupVersion(), err)
+		}
+		found := false
+		for _, resource := range resources.APIResources {
+			found = found || resource.Name == gvr.Resource
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s is not served in %s", gvr.Resource, gvr.GroupVersion()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkGateways verifies that the gateways configured in config-istio exist.
+func checkGateways(ctx context.Context, kubeclient kubernetes.Interface, istioclient istioclientset.Interface) error {
+	cm, err := kubeclient.CoreV1().ConfigMaps(system.Namespace()).Get(ctx, config.IstioConfigName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the %s ConfigMap: %w", config.IstioConfigName, err)
+	}
+	cfg, err := config.NewIstioFromConfigMap(cm)
+	if err != nil {
+		return fmt.Errorf("invalid %s ConfigMap: %w", config.IstioConfigName, err)
+	}
+
+	var errs []error
+	for _, gw := range append(cfg.IngressGateways, cfg.LocalGateways...) {
+		_, err := istioclient.NetworkingV1beta1().Gateways(gw.Namespace).Get(ctx, gw.Name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("gateway %s configured in %s does not exist", gw.QualifiedName(), config.IstioConfigName))
+		} else if err != nil {
+			return fmt.Errorf("failed to get gateway %s: %w", gw.QualifiedName(), err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkPermissions verifies that the controller is granted the permissions, through
+// SelfSubjectAccessReviews.
+func checkPermissions(ctx context.Context, client kubernetes.Interface, permissions []permission) error {
+	var errs []error
+	for _, p := range permissions {
+		for _, verb := range p.verbs {
+			review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Group:       p.group,
+						Resource:    p.resource,
+						Subresource: p.subresource,
+						Verb:        verb,
+					},
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to review the access to %s: %w", p.name(), err)
+			}
+			if !review.Status.Allowed {
+				errs = append(errs, fmt.Errorf("%s of %s is not allowed", verb, p.name()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p permission) name() string {
+	name := schema.GroupResource{Group: p.group, Resource: p.resource}.String()
+	if p.subresource != "" {
+		name += "/" + p.subresource
+	}
+	return name
+}
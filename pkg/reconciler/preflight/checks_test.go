@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+	fakeistioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned/fake"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+func TestCheckIstioAPIs(t *testing.T) {
+	istio := fakeistioclientset.NewSimpleClientset()
+	istio.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "networking.istio.io/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "virtualservices"}, {Name: "gateways"}, {Name: "destinationrules"}},
+	}}
+
+	err := checkIstioAPIs(istio)
+	if err == nil {
+		t.Fatal("checkIstioAPIs() = nil, wanted an error")
+	}
+	for _, want := range []string{"sidecars is not served in networking.istio.io/v1beta1", "security.istio.io/v1beta1 is not served"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("checkIstioAPIs() = %v, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "virtualservices") {
+		t.Errorf("checkIstioAPIs() = %v, want the served APIs not to be reported", err)
+	}
+}
+
+func TestCheckGateways(t *testing.T) {
+	kube := fakekubeclientset.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: config.IstioConfigName},
+	})
+	// The tracker of the fake clientset guesses the resource of the objects it is created with
+	// wrongly for Gateways, so the gateway is created through the client.
+	istio := fakeistioclientset.NewSimpleClientset()
+	if _, err := istio.NetworkingV1beta1().Gateways(system.Namespace()).Create(context.Background(), &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: system.Namespace(), Name: config.KnativeIngressGateway},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal("Create() =", err)
+	}
+
+	err := checkGateways(context.Background(), kube, istio)
+	if err == nil {
+		t.Fatal("checkGateways() = nil, wanted an error")
+	}
+	if want := system.Namespace() + "/" + config.KnativeLocalGateway; !strings.Contains(err.Error(), want) {
+		t.Errorf("checkGateways() = %v, want it to report %s", err, want)
+	}
+	if strings.Contains(err.Error(), config.KnativeIngressGateway) {
+		t.Errorf("checkGateways() = %v, want the existing gateway not to be reported", err)
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	kube := fakekubeclientset.NewSimpleClientset()
+	kube.PrependReactor("create", "selfsubjectaccessreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		review := action.(clientgotesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = !(attrs.Resource == "secrets" && attrs.Verb == "watch")
+		return true, review, nil
+	})
+
+	err := checkPermissions(context.Background(), kube, knativePermissions)
+	if err == nil {
+		t.Fatal("checkPermissions() = nil, wanted an error")
+	}
+	if got, want := err.Error(), "watch of secrets is not allowed"; got != want {
+		t.Errorf("checkPermissions() = %q, want %q", got, want)
+	}
+
+	if err := checkPermissions(context.Background(), kube, istioPermissions); err != nil {
+		t.Error("checkPermissions() =", err)
+	}
+}
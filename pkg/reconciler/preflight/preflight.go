@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight verifies, when the controller starts, that the environment it runs
+// in lets it program the Ingresses: the configured gateways exist, the Istio APIs are
+// served in supported versions and the controller is granted the permissions it needs.
+//
+// Until the checks pass, the readiness probe of the controller fails with the diagnostics
+// and the preflight_failed_check_count metric reports the number of failing checks, rather
+// than every Ingress reporting its own errors later.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+)
+
+const (
+	// ProbesPortEnv configures the port the readiness and liveness probes are served on,
+	// like for the probes of sharedmain these replace.
+	ProbesPortEnv = "KNATIVE_HEALTH_PROBES_PORT"
+
+	defaultProbesPort = "8080"
+
+	// RetryInterval is how often failing checks are run again.
+	RetryInterval = 10 * time.Second
+)
+
+var failedCheckM = stats.Int64(
+	"preflight_failed_check_count",
+	"Number of preflight checks failing when last run",
+	stats.UnitDimensionless)
+
+func init() {
+	if err := metrics.RegisterResourceView(&view.View{
+		Description: failedCheckM.Description(),
+		Measure:     failedCheckM,
+		Aggregation: view.LastValue(),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// Check is a named verification of the environment of the controller.
+type Check struct {
+	Name string
+	Run  func(context.Context) error
+}
+
+// Status tracks the outcome of the preflight checks.
+type Status struct {
+	mu       sync.RWMutex
+	started  bool
+	passed   bool
+	failures []error
+}
+
+type statusKey struct{}
+
+// NewStatus creates a Status, ready until checks are started.
+func NewStatus() *Status {
+	return &Status{}
+}
+
+// WithStatus returns the passed context with the status attached.
+func WithStatus(ctx context.Context, s *Status) context.Context {
+	return context.WithValue(ctx, statusKey{}, s)
+}
+
+// FromContext returns the status attached to the context, or nil if the preflight checks
+// are not used.
+func FromContext(ctx context.Context) *Status {
+	if s, ok := ctx.Value(statusKey{}).(*Status); ok {
+		return s
+	}
+	return nil
+}
+
+// Start runs the checks every RetryInterval until they all pass or the context is done.
+// The status is not ready from then on until they do.
+func (s *Status) Start(ctx context.Context, checks ...Check) {
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+
+	go wait.PollUntilContextCancel(ctx, RetryInterval, true, func(ctx context.Context) (bool, error) {
+		return s.run(ctx, checks), nil
+	})
+}
+
+// run runs the checks once and returns whether they all passed.
+func (s *Status) run(ctx context.Context, checks []Check) bool {
+	logger := logging.FromContext(ctx)
+	var failures []error
+	for _, check := range checks {
+		if err := check.Run(ctx); err != nil {
+			logger.Errorw("Preflight check failed", "check", check.Name, "error", err)
+			failures = append(failures, fmt.Errorf("%s: %w", check.Name, err))
+		}
+	}
+	metrics.Record(ctx, failedCheckM.M(int64(len(failures))))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = failures
+	s.passed = len(failures) == 0
+	if s.passed {
+		logger.Info("All the preflight checks passed")
+	}
+	return s.passed
+}
+
+// Ready returns nil when the checks passed or were never started, and the diagnostics
+// otherwise.
+func (s *Status) Ready() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	switch {
+	case !s.started || s.passed:
+		return nil
+	case len(s.failures) == 0:
+		return errors.New("preflight checks did not complete yet")
+	default:
+		return fmt.Errorf("preflight checks failed: %w", errors.Join(s.failures...))
+	}
+}
+
+// ServeProbes serves the readiness probe, failing with the diagnostics until the checks
+// pass, and the liveness probe until the context is done.
+func (s *Status) ServeProbes(ctx context.Context) error {
+	port := os.Getenv(ProbesPortEnv)
+	if port == "" {
+		port = defaultProbesPort
+	}
+	server := &http.Server{Addr: ":" + port, Handler: s.handler(), ReadHeaderTimeout: time.Minute}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Status) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/readiness", func(w http.ResponseWriter, _ *http.Request) {
+		if err := s.Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func probe(t *testing.T, s *Status, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestStatus(t *testing.T) {
+	s := NewStatus()
+	if code, _ := probe(t, s, "/readiness"); code != http.StatusOK {
+		t.Errorf("Readiness before the checks are started = %d, want %d", code, http.StatusOK)
+	}
+
+	s.started = true
+	if code, _ := probe(t, s, "/readiness"); code != http.StatusServiceUnavailable {
+		t.Errorf("Readiness before the checks complete = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	gatewaysErr := errors.New("gateway knative-serving/knative-ingress-gateway does not exist")
+	checks := []Check{{
+		Name: "Passing",
+		Run:  func(context.Context) error { return nil },
+	}, {
+		Name: "Gateways",
+		Run:  func(context.Context) error { return gatewaysErr },
+	}}
+	if s.run(context.Background(), checks) {
+		t.Error("run() = true, want false with a failing check")
+	}
+	code, body := probe(t, s, "/readiness")
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("Readiness with a failing check = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(body, "Gateways: "+gatewaysErr.Error()) || strings.Contains(body, "Passing") {
+		t.Errorf("Readiness diagnostics = %q, want only the failing check", body)
+	}
+	if code, _ := probe(t, s, "/health"); code != http.StatusOK {
+		t.Errorf("Liveness with a failing check = %d, want %d", code, http.StatusOK)
+	}
+
+	gatewaysErr = nil
+	if !s.run(context.Background(), checks) {
+		t.Error("run() = false, want true once the checks pass")
+	}
+	if code, body := probe(t, s, "/readiness"); code != http.StatusOK {
+		t.Errorf("Readiness once the checks pass = %d (%s), want %d", code, body, http.StatusOK)
+	}
+}
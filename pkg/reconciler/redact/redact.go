@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact keeps the material of Secrets out of logs, events and errors.
+//
+// Secrets must only be logged through Secret, and errors returned by calls
+// that carry Secrets must be wrapped with Error before they are logged,
+// recorded or returned, so that the redaction does not rely on every call
+// site picking the right format verb.
+package redact
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Placeholder replaces the material of Secrets in redacted output.
+const Placeholder = "[REDACTED]"
+
+// minScrubLength is the length under which values are not scrubbed from
+// errors, as short values are likely to match unrelated text.
+const minScrubLength = 8
+
+// SafeSecret formats a Secret without its data.
+type SafeSecret struct {
+	secret *corev1.Secret
+}
+
+var (
+	_ fmt.Formatter           = SafeSecret{}
+	_ fmt.Stringer            = SafeSecret{}
+	_ fmt.GoStringer          = SafeSecret{}
+	_ zapcore.ObjectMarshaler = SafeSecret{}
+)
+
+// Secret returns a representation of the Secret that is safe to log. Only its
+// namespace, name, type and the sizes of its data are kept.
+func Secret(secret *corev1.Secret) SafeSecret {
+	return SafeSecret{secret: secret}
+}
+
+// String implements fmt.Stringer.
+func (s SafeSecret) String() string {
+	if s.secret == nil {
+		return "Secret(nil)"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Secret %s/%s (type %q, data:", s.secret.Namespace, s.secret.Name, s.secret.Type)
+	for _, key := range dataKeys(s.secret) {
+		fmt.Fprintf(&b, " %s=%d bytes", key, dataLen(s.secret, key))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// GoString implements fmt.GoStringer, so that %#v does not dump the Secret.
+func (s SafeSecret) GoString() string {
+	return s.String()
+}
+
+// Format implements fmt.Formatter. Every verb prints the redacted form.
+func (s SafeSecret) Format(f fmt.State, _ rune) {
+	fmt.Fprint(f, s.String())
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (s SafeSecret) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if s.secret == nil {
+		return nil
+	}
+	enc.AddString("namespace", s.secret.Namespace)
+	enc.AddString("name", s.secret.Name)
+	enc.AddString("type", string(s.secret.Type))
+	for _, key := range dataKeys(s.secret) {
+		enc.AddInt("data."+key, dataLen(s.secret, key))
+	}
+	return nil
+}
+
+func dataKeys(secret *corev1.Secret) []string {
+	keys := make([]string, 0, len(secret.Data)+len(secret.StringData))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	for key := range secret.StringData {
+		if _, ok := secret.Data[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dataLen(secret *corev1.Secret, key string) int {
+	if value, ok := secret.Data[key]; ok {
+		return len(value)
+	}
+	return len(secret.StringData[key])
+}
+
+// scrubbedError is an error whose message has the material of Secrets removed.
+type scrubbedError struct {
+	err    error
+	values []string
+}
+
+// Error returns err with the data of the given Secrets replaced by Placeholder
+// in its message. The returned error still unwraps to err, so that it can be
+// inspected with errors.Is and errors.As, e.g. by apierrs.IsNotFound.
+func Error(err error, secrets ...*corev1.Secret) error {
+	if err == nil {
+		return nil
+	}
+	var values []string
+	for _, secret := range secrets {
+		if secret == nil {
+			continue
+		}
+		for _, value := range secret.Data {
+			values = appendScrubValues(values, string(value))
+			// The API server echoes the data of Secrets base64 encoded.
+			values = appendScrubValues(values, base64.StdEncoding.EncodeToString(value))
+		}
+		for _, value := range secret.StringData {
+			values = appendScrubValues(values, value)
+		}
+	}
+	// Replace the longest values first, in case one contains another.
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return &scrubbedError{err: err, values: values}
+}
+
+// appendScrubValues appends the value, and each of its lines as parsers tend
+// to quote the offending line of PEM blocks or kubeconfigs, to values.
+func appendScrubValues(values []string, value string) []string {
+	if len(value) < minScrubLength {
+		return values
+	}
+	values = append(values, value)
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); len(line) >= minScrubLength && line != value {
+			values = append(values, line)
+		}
+	}
+	return values
+}
+
+// Error implements error.
+func (e *scrubbedError) Error() string {
+	msg := e.err.Error()
+	for _, value := range e.values {
+		msg = strings.ReplaceAll(msg, value, Placeholder)
+	}
+	return msg
+}
+
+// Unwrap returns the original error.
+func (e *scrubbedError) Unwrap() error {
+	return e.err
+}
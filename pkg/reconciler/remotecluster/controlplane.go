@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotecluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	"knative.dev/net-istio/pkg/reconciler/observer"
+	"knative.dev/pkg/injection"
+)
+
+// ControlPlaneKubeconfigEnv configures the path of the kubeconfig of the cluster running
+// istiod and the gateways, when it is not the cluster running Knative.
+const ControlPlaneKubeconfigEnv = "ISTIO_KUBECONFIG"
+
+// ControlPlane holds the clients of the cluster running the Istio control plane.
+type ControlPlane struct {
+	KubeClient  kubernetes.Interface
+	IstioClient istioclientset.Interface
+}
+
+type controlPlaneKey struct{}
+
+// GetContextWithControlPlane returns the passed context with the clients of the cluster
+// configured through ISTIO_KUBECONFIG attached, and makes the injected Istio client and
+// informers target that cluster. The context is returned unchanged if the variable is not set.
+//
+// This must be called before the injected clients are set up.
+func GetContextWithControlPlane(ctx context.Context) (context.Context, error) {
+	path := os.Getenv(ControlPlaneKubeconfigEnv)
+	if path == "" {
+		return ctx, nil
+	}
+	cfg, err := clientcmd.BuildConfigFromFlags("", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", ControlPlaneKubeconfigEnv, err)
+	}
+	cp, err := newControlPlane(observer.WrapConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clients from %s: %w", ControlPlaneKubeconfigEnv, err)
+	}
+
+	// Clients are set up in registration order, so this replaces the generated Istio client.
+	injection.Default.RegisterClient(func(ctx context.Context, _ *rest.Config) context.Context {
+		return context.WithValue(ctx, istioclient.Key{}, cp.IstioClient)
+	})
+	return context.WithValue(ctx, controlPlaneKey{}, cp), nil
+}
+
+// ControlPlaneFromContext returns the clients of the cluster running the Istio control plane,
+// or nil if it runs in the same cluster as Knative.
+func ControlPlaneFromContext(ctx context.Context) *ControlPlane {
+	if cp, ok := ctx.Value(controlPlaneKey{}).(*ControlPlane); ok {
+		return cp
+	}
+	return nil
+}
+
+func newControlPlane(cfg *rest.Config) (*ControlPlane, error) {
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	istioClient, err := istioclientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ControlPlane{
+		KubeClient:  kubeClient,
+		IstioClient: istioClient,
+	}, nil
+}
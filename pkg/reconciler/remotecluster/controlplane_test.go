@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotecluster
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	"knative.dev/pkg/injection"
+)
+
+func TestGetContextWithControlPlane(t *testing.T) {
+	t.Setenv(ControlPlaneKubeconfigEnv, "")
+	ctx, err := GetContextWithControlPlane(context.Background())
+	if err != nil {
+		t.Fatal("GetContextWithControlPlane() =", err)
+	}
+	if cp := ControlPlaneFromContext(ctx); cp != nil {
+		t.Errorf("ControlPlaneFromContext() = %v, want nil", cp)
+	}
+
+	t.Setenv(ControlPlaneKubeconfigEnv, filepath.Join(t.TempDir(), "missing"))
+	if _, err := GetContextWithControlPlane(context.Background()); err == nil {
+		t.Error("GetContextWithControlPlane() = nil, wanted an error for a missing kubeconfig")
+	}
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal("Failed to write kubeconfig:", err)
+	}
+	t.Setenv(ControlPlaneKubeconfigEnv, path)
+	ctx, err = GetContextWithControlPlane(context.Background())
+	if err != nil {
+		t.Fatal("GetContextWithControlPlane() =", err)
+	}
+	cp := ControlPlaneFromContext(ctx)
+	if cp == nil {
+		t.Fatal("ControlPlaneFromContext() = nil, want the control plane clients")
+	}
+
+	// The last registered client replaces the injected Istio client.
+	clients := injection.Default.GetClients()
+	ctx = clients[len(clients)-1](ctx, nil)
+	if got := istioclient.Get(ctx); got != cp.IstioClient {
+		t.Errorf("Injected Istio client = %v, want the control plane client", got)
+	}
+}
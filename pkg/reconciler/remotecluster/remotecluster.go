@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remotecluster provides the clients of the clusters other than the one
+// running Knative: the remote clusters that the resources generated for Ingresses
+// are also programmed into, for Istio multi-primary deployments fronted by a single
+// Knative installation, and the cluster running an external Istio control plane.
+package remotecluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	"knative.dev/net-istio/pkg/reconciler/observer"
+	"knative.dev/net-istio/pkg/reconciler/redact"
+	"knative.dev/pkg/system"
+)
+
+// KubeconfigKey is the key of the remote cluster Secrets holding the kubeconfig
+// used to access the cluster.
+const KubeconfigKey = "kubeconfig"
+
+// Cluster holds the clients of a remote cluster.
+type Cluster struct {
+	// Name is the name of the Secret the cluster is registered with.
+	Name string
+
+	KubeClient  kubernetes.Interface
+	IstioClient istioclientset.Interface
+}
+
+// Provider builds the clients of the remote clusters registered as Secrets in the
+// Knative system namespace, and caches them until the Secrets change.
+type Provider struct {
+	kubeclient kubernetes.Interface
+	newCluster func(name string, cfg *rest.Config) (*Cluster, error)
+
+	mu       sync.Mutex
+	clusters map[string]cachedCluster
+}
+
+type cachedCluster struct {
+	resourceVersion string
+	cluster         *Cluster
+}
+
+// NewProvider creates a Provider reading the remote cluster Secrets with the given client.
+func NewProvider(kubeclient kubernetes.Interface) *Provider {
+	return &Provider{
+		kubeclient: kubeclient,
+		newCluster: newCluster,
+		clusters:   make(map[string]cachedCluster),
+	}
+}
+
+// Clusters returns the clients of the remote clusters registered with the given Secrets.
+func (p *Provider) Clusters(ctx context.Context, secretNames []string) ([]*Cluster, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clusters := make([]*Cluster, 0, len(secretNames))
+	for _, name := range secretNames {
+		secret, err := p.kubeclient.CoreV1().Secrets(system.Namespace()).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote cluster secret %q: %w", name, err)
+		}
+		if cached, ok := p.clusters[name]; ok && cached.resourceVersion == secret.ResourceVersion {
+			clusters = append(clusters, cached.cluster)
+			continue
+		}
+
+		cfg, err := clientcmd.RESTConfigFromKubeConfig(secret.Data[KubeconfigKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid kubeconfig in remote cluster %v: %w", redact.Secret(secret), redact.Error(err, secret))
+		}
+		cluster, err := p.newCluster(name, observer.WrapConfig(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create clients for remote cluster %q: %w", name, err)
+		}
+		p.clusters[name] = cachedCluster{resourceVersion: secret.ResourceVersion, cluster: cluster}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+func newCluster(name string, cfg *rest.Config) (*Cluster, error) {
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	istioClient, err := istioclientset.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Cluster{
+		Name:        name,
+		KubeClient:  kubeClient,
+		IstioClient: istioClient,
+	}, nil
+}
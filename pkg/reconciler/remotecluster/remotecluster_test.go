@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remotecluster
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+users:
+- name: remote
+  user:
+    token: secret-token
+`
+
+func remoteSecret(name, resourceVersion, kubeconfig string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       system.Namespace(),
+			ResourceVersion: resourceVersion,
+		},
+		Data: map[string][]byte{KubeconfigKey: []byte(kubeconfig)},
+	}
+}
+
+func TestClusters(t *testing.T) {
+	ctx := context.Background()
+	kubeclient := fakekubeclientset.NewSimpleClientset(remoteSecret("cluster-b", "1", testKubeconfig))
+
+	created := 0
+	p := NewProvider(kubeclient)
+	p.newCluster = func(name string, cfg *rest.Config) (*Cluster, error) {
+		created++
+		if cfg.Host != "https://remote.example.com" || cfg.BearerToken != "secret-token" {
+			t.Errorf("rest.Config = %s/%s, want the remote cluster", cfg.Host, cfg.BearerToken)
+		}
+		return &Cluster{Name: name}, nil
+	}
+
+	clusters, err := p.Clusters(ctx, []string{"cluster-b"})
+	if err != nil {
+		t.Fatal("Clusters() =", err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "cluster-b" {
+		t.Fatalf("Clusters() = %v, want cluster-b", clusters)
+	}
+
+	// The clients are reused as long as the Secret does not change.
+	if _, err := p.Clusters(ctx, []string{"cluster-b"}); err != nil {
+		t.Fatal("Clusters() =", err)
+	}
+	if created != 1 {
+		t.Errorf("clients created %d times, want 1", created)
+	}
+
+	if _, err := kubeclient.CoreV1().Secrets(system.Namespace()).Update(ctx, remoteSecret("cluster-b", "2", testKubeconfig), metav1.UpdateOptions{}); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if _, err := p.Clusters(ctx, []string{"cluster-b"}); err != nil {
+		t.Fatal("Clusters() =", err)
+	}
+	if created != 2 {
+		t.Errorf("clients created %d times, want 2 after the Secret changed", created)
+	}
+}
+
+func TestClustersErrors(t *testing.T) {
+	ctx := context.Background()
+	p := NewProvider(fakekubeclientset.NewSimpleClientset(remoteSecret("invalid", "1", "not a kubeconfig")))
+
+	if _, err := p.Clusters(ctx, []string{"missing"}); err == nil {
+		t.Error("Clusters() = nil, want error for a missing Secret")
+	}
+	if _, err := p.Clusters(ctx, []string{"invalid"}); err == nil {
+		t.Error("Clusters() = nil, want error for an invalid kubeconfig")
+	}
+}
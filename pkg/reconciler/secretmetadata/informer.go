@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secretmetadata provides an informer that only watches the metadata
+// of Secrets, together with a Secret lister that fetches the full bodies from
+// the API server when they are read with Get.
+package secretmetadata
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterClient(withClient)
+	injection.Default.RegisterFilteredInformers(withInformer)
+}
+
+type clientKey struct{}
+
+// selectorKey is used to carry the label selector of the informer. The
+// informer is only set up when it is present.
+type selectorKey struct{}
+
+// informerKey is used for associating the Informer inside the context.Context.
+type informerKey struct{}
+
+var secretsResource = corev1.SchemeGroupVersion.WithResource("secrets")
+
+// WithSelector enables the metadata-only Secret informer for the given
+// label selector.
+func WithSelector(ctx context.Context, selector string) context.Context {
+	return context.WithValue(ctx, selectorKey{}, selector)
+}
+
+// IsEnabled returns whether the metadata-only Secret informer is set up.
+func IsEnabled(ctx context.Context) bool {
+	return ctx.Value(selectorKey{}) != nil
+}
+
+func withClient(ctx context.Context, cfg *rest.Config) context.Context {
+	return context.WithValue(ctx, clientKey{}, metadata.NewForConfigOrDie(cfg))
+}
+
+func withInformer(ctx context.Context) (context.Context, []controller.Informer) {
+	untyped := ctx.Value(selectorKey{})
+	if untyped == nil {
+		return ctx, nil
+	}
+	selector := untyped.(string)
+
+	c, ok := ctx.Value(clientKey{}).(metadata.Interface)
+	if !ok {
+		logging.FromContext(ctx).Panic("Unable to fetch k8s.io/client-go/metadata.Interface from context.")
+	}
+	namespace := metav1.NamespaceAll
+	if injection.HasNamespaceScope(ctx) {
+		namespace = injection.GetNamespaceScope(ctx)
+	}
+	inf := metadatainformer.NewFilteredMetadataInformer(c, secretsResource, namespace,
+		controller.GetResyncPeriod(ctx),
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		func(l *metav1.ListOptions) {
+			l.LabelSelector = selector
+		})
+	return context.WithValue(ctx, informerKey{}, inf), []controller.Informer{inf.Informer()}
+}
+
+// Get extracts the metadata-only Secret informer from the context.
+func Get(ctx context.Context) informers.GenericInformer {
+	untyped := ctx.Value(informerKey{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic(
+			"Unable to fetch the metadata-only Secret informer from context.")
+	}
+	return untyped.(informers.GenericInformer)
+}
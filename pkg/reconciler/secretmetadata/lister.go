@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretmetadata
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/metadata/metadatalister"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/kmeta"
+)
+
+// Lister is a SecretLister backed by a metadata-only informer. List only
+// returns the metadata of the Secrets, without their data. Get fetches the
+// full Secret from the API server and keeps its body until the informer
+// reports that it was changed or deleted, so only the Secrets that are
+// actually read are held in memory.
+type Lister struct {
+	ctx    context.Context
+	meta   metadatalister.Lister
+	client kubernetes.Interface
+
+	mu      *sync.Mutex
+	secrets map[types.NamespacedName]*corev1.Secret
+}
+
+var _ corev1listers.SecretLister = (*Lister)(nil)
+
+// NewLister creates a Lister from the indexer of a metadata-only Secret
+// informer. The Secrets are fetched with the given context, unless the Lister
+// is bound to another one with WithContext.
+func NewLister(ctx context.Context, indexer cache.Indexer, client kubernetes.Interface) *Lister {
+	return &Lister{
+		ctx:     ctx,
+		meta:    metadatalister.New(indexer, secretsResource),
+		client:  client,
+		mu:      &sync.Mutex{},
+		secrets: make(map[types.NamespacedName]*corev1.Secret),
+	}
+}
+
+// WithContext returns the lister fetching the Secrets with the given context
+// when it is a Lister, and the lister itself otherwise. The returned Lister
+// shares the cache of the original one.
+func WithContext(ctx context.Context, lister corev1listers.SecretLister) corev1listers.SecretLister {
+	l, ok := lister.(*Lister)
+	if !ok {
+		return lister
+	}
+	bound := *l
+	bound.ctx = ctx
+	return &bound
+}
+
+// List lists the metadata of all Secrets matching the selector.
+func (l *Lister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	return list(l.meta.List(selector))
+}
+
+// Secrets returns a lister for the Secrets in the given namespace.
+func (l *Lister) Secrets(namespace string) corev1listers.SecretNamespaceLister {
+	return &namespaceLister{lister: l, namespace: namespace}
+}
+
+// Invalidate drops the cached body of the given Secret when its resource
+// version changed. It is meant to be used as the update handler of the
+// informer.
+func (l *Lister) Invalidate(oldObj, newObj interface{}) {
+	oldAcc, err := kmeta.DeletionHandlingAccessor(oldObj)
+	if err != nil {
+		return
+	}
+	newAcc, err := kmeta.DeletionHandlingAccessor(newObj)
+	if err != nil || oldAcc.GetResourceVersion() == newAcc.GetResourceVersion() {
+		return
+	}
+	l.Forget(newObj)
+}
+
+// Forget drops the cached body of the given Secret. It is meant to be used as
+// the delete handler of the informer.
+func (l *Lister) Forget(obj interface{}) {
+	acc, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.secrets, types.NamespacedName{Namespace: acc.GetNamespace(), Name: acc.GetName()})
+}
+
+// list converts the metadata into Secrets without data.
+func list(metas []*metav1.PartialObjectMetadata, err error) ([]*corev1.Secret, error) {
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*corev1.Secret, 0, len(metas))
+	for _, m := range metas {
+		ret = append(ret, &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+			ObjectMeta: m.ObjectMeta,
+		})
+	}
+	return ret, nil
+}
+
+func (l *Lister) get(m *metav1.PartialObjectMetadata) (*corev1.Secret, error) {
+	key := types.NamespacedName{Namespace: m.Namespace, Name: m.Name}
+
+	l.mu.Lock()
+	s, ok := l.secrets[key]
+	l.mu.Unlock()
+	if ok && s.ResourceVersion == m.ResourceVersion {
+		return s, nil
+	}
+
+	s, err := l.client.CoreV1().Secrets(m.Namespace).Get(l.ctx, m.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.secrets[key] = s
+	l.mu.Unlock()
+	return s, nil
+}
+
+type namespaceLister struct {
+	lister    *Lister
+	namespace string
+}
+
+// List lists the metadata of all Secrets in the namespace matching the selector.
+func (n *namespaceLister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	return list(n.lister.meta.Namespace(n.namespace).List(selector))
+}
+
+// Get returns the Secret with the given name.
+func (n *namespaceLister) Get(name string) (*corev1.Secret, error) {
+	m, err := n.lister.meta.Namespace(n.namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return n.lister.get(m)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretmetadata
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func secret(name, resourceVersion string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "ns",
+			ResourceVersion: resourceVersion,
+			Labels:          map[string]string{"app": "test"},
+		},
+		Data: map[string][]byte{"tls.crt": []byte(name + "-" + resourceVersion)},
+	}
+}
+
+func partial(s *corev1.Secret) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: s.ObjectMeta,
+	}
+}
+
+func newTestLister(t *testing.T, secrets ...*corev1.Secret) (*Lister, cache.Indexer, *fakekubeclientset.Clientset) {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	client := fakekubeclientset.NewSimpleClientset()
+	for _, s := range secrets {
+		if err := indexer.Add(partial(s)); err != nil {
+			t.Fatal("Failed to add Secret metadata:", err)
+		}
+		if err := client.Tracker().Add(s); err != nil {
+			t.Fatal("Failed to add Secret:", err)
+		}
+	}
+	return NewLister(context.Background(), indexer, client), indexer, client
+}
+
+func TestListerGet(t *testing.T) {
+	want := secret("foo", "1")
+	lister, indexer, client := newTestLister(t, want)
+
+	got, err := lister.Secrets("ns").Get("foo")
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if !cmp.Equal(got.Data, want.Data) {
+		t.Errorf("Data = %v, want = %v", got.Data, want.Data)
+	}
+
+	// The body is cached as long as the resource version does not change.
+	if _, err := lister.Secrets("ns").Get("foo"); err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got := len(client.Actions()); got != 1 {
+		t.Errorf("Got %d API calls, want 1", got)
+	}
+
+	// A rotated Secret is fetched again.
+	rotated := secret("foo", "2")
+	if err := client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("secrets"), rotated, "ns"); err != nil {
+		t.Fatal("Failed to update Secret:", err)
+	}
+	if err := indexer.Update(partial(rotated)); err != nil {
+		t.Fatal("Failed to update Secret metadata:", err)
+	}
+	got, err = lister.Secrets("ns").Get("foo")
+	if err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if !cmp.Equal(got.Data, rotated.Data) {
+		t.Errorf("Data = %v, want = %v", got.Data, rotated.Data)
+	}
+	if got := len(client.Actions()); got != 2 {
+		t.Errorf("Got %d API calls, want 2", got)
+	}
+}
+
+func TestListerGetNotFound(t *testing.T) {
+	lister, _, client := newTestLister(t)
+
+	if _, err := lister.Secrets("ns").Get("foo"); !apierrs.IsNotFound(err) {
+		t.Errorf("Get() = %v, want NotFound", err)
+	}
+	if got := len(client.Actions()); got != 0 {
+		t.Errorf("Got %d API calls, want 0", got)
+	}
+}
+
+func TestListerList(t *testing.T) {
+	lister, _, client := newTestLister(t, secret("foo", "1"), secret("bar", "1"))
+
+	all, err := lister.List(labels.Everything())
+	if err != nil {
+		t.Fatal("List() =", err)
+	}
+	if got := len(all); got != 2 {
+		t.Errorf("List() returned %d Secrets, want 2", got)
+	}
+	for _, s := range all {
+		if s.Data != nil {
+			t.Errorf("Secret %s has data %v, want none", s.Name, s.Data)
+		}
+	}
+	// Only the metadata is listed, so the bodies are not fetched.
+	if got := len(client.Actions()); got != 0 {
+		t.Errorf("Got %d API calls, want 0", got)
+	}
+
+	selected, err := lister.Secrets("ns").List(labels.SelectorFromSet(labels.Set{"app": "other"}))
+	if err != nil {
+		t.Fatal("List() =", err)
+	}
+	if got := len(selected); got != 0 {
+		t.Errorf("List() returned %d Secrets, want 0", got)
+	}
+}
+
+func TestListerWithContext(t *testing.T) {
+	lister, _, _ := newTestLister(t, secret("foo", "1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bound := WithContext(ctx, lister)
+	if _, err := bound.Secrets("ns").Get("foo"); err != nil {
+		t.Fatal("Get() =", err)
+	}
+	// The bound Lister shares the cache of the original one.
+	if got := len(lister.secrets); got != 1 {
+		t.Errorf("Got %d cached Secrets, want 1", got)
+	}
+	if got := bound.(*Lister).ctx; got != ctx {
+		t.Errorf("ctx = %v, want = %v", got, ctx)
+	}
+}
+
+func TestListerInvalidate(t *testing.T) {
+	s := secret("foo", "1")
+	lister, _, client := newTestLister(t, s)
+
+	if _, err := lister.Secrets("ns").Get("foo"); err != nil {
+		t.Fatal("Get() =", err)
+	}
+	// A resync doesn't drop the body.
+	lister.Invalidate(partial(s), partial(s))
+	if got := len(lister.secrets); got != 1 {
+		t.Errorf("Got %d cached Secrets after a resync, want 1", got)
+	}
+	lister.Invalidate(partial(s), partial(secret("foo", "2")))
+	if got := len(lister.secrets); got != 0 {
+		t.Errorf("Got %d cached Secrets after an update, want 0", got)
+	}
+	if got := len(client.Actions()); got != 1 {
+		t.Errorf("Got %d API calls, want 1", got)
+	}
+}
+
+func TestListerForget(t *testing.T) {
+	s := secret("foo", "1")
+	lister, _, client := newTestLister(t, s)
+
+	if _, err := lister.Secrets("ns").Get("foo"); err != nil {
+		t.Fatal("Get() =", err)
+	}
+	lister.Forget(cache.DeletedFinalStateUnknown{Key: "ns/foo", Obj: partial(s)})
+	if _, err := lister.Secrets("ns").Get("foo"); err != nil {
+		t.Fatal("Get() =", err)
+	}
+	if got := len(client.Actions()); got != 2 {
+		t.Errorf("Got %d API calls, want 2", got)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
 	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
 	"knative.dev/net-istio/pkg/reconciler/ingress/config"
@@ -58,6 +60,13 @@ func (r *reconciler) ReconcileKind(ctx context.Context, sks *netv1alpha1.Serverl
 		return nil
 	}
 
+	if config.FromContext(ctx).Istio.AmbientMode {
+		// The VirtualService and DestinationRule configure how the sidecars, e.g. the one
+		// of the activator, balance the requests to the pods, and there are no sidecars on
+		// an ambient mesh. Delete the ones created before the mode was switched on.
+		return r.deleteMeshResources(ctx, sks)
+	}
+
 	vs := resources.MakeVirtualService(sks)
 	if _, err := istioaccessor.ReconcileVirtualService(ctx, sks, vs, r); err != nil {
 		return fmt.Errorf("failed to reconcile VirtualService: %w", err)
@@ -71,6 +80,32 @@ func (r *reconciler) ReconcileKind(ctx context.Context, sks *netv1alpha1.Serverl
 	return nil
 }
 
+// deleteMeshResources deletes the VirtualService and DestinationRule of the
+// ServerlessService, if any.
+func (r *reconciler) deleteMeshResources(ctx context.Context, sks *netv1alpha1.ServerlessService) error {
+	name := sks.Status.PrivateServiceName
+	vs, err := r.virtualServiceLister.VirtualServices(sks.Namespace).Get(name)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return err
+	}
+	if err == nil && metav1.IsControlledBy(vs, sks) {
+		if err := r.istioclient.NetworkingV1beta1().VirtualServices(sks.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete VirtualService: %w", err)
+		}
+	}
+
+	dr, err := r.destinationRuleLister.DestinationRules(sks.Namespace).Get(name)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return err
+	}
+	if err == nil && metav1.IsControlledBy(dr, sks) {
+		if err := r.istioclient.NetworkingV1beta1().DestinationRules(sks.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete DestinationRule: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *reconciler) GetIstioClient() istioclientset.Interface {
 	return r.istioclient
 }
@@ -217,6 +217,71 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+func TestReconcile_AmbientMode(t *testing.T) {
+	table := TableTest{{
+		Name: "nothing to create",
+		Key:  "testing/test",
+		Objects: []runtime.Object{
+			sks("test"),
+		},
+	}, {
+		Name: "delete the ones created without ambient mode",
+		Key:  "testing/test",
+		Objects: []runtime.Object{
+			sks("test"),
+			vs("test"),
+			dr("test"),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "testing",
+				Verb:      "delete",
+				Resource:  istiov1beta1.SchemeGroupVersion.WithResource("virtualservices"),
+			},
+			Name: "test-foo",
+		}, {
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "testing",
+				Verb:      "delete",
+				Resource:  istiov1beta1.SchemeGroupVersion.WithResource("destinationrules"),
+			},
+			Name: "test-foo",
+		}},
+		CmpOpts: defaultCmpOpts,
+	}, {
+		Name: "keep the ones not owned by the ServerlessService",
+		Key:  "testing/test",
+		Objects: []runtime.Object{
+			sks("test"),
+			func() *istiov1beta1.VirtualService {
+				virtualService := vs("test")
+				virtualService.OwnerReferences = nil
+				return virtualService
+			}(),
+		},
+		CmpOpts: defaultCmpOpts,
+	}}
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		r := &reconciler{
+			istioclient:           istioclient.Get(ctx),
+			virtualServiceLister:  listers.GetVirtualServiceLister(),
+			destinationRuleLister: listers.GetDestinationRuleLister(),
+		}
+
+		return sksreconciler.NewReconciler(ctx, logging.FromContext(ctx), fakenetworkingclient.Get(ctx),
+			listers.GetServerlessServiceLister(), controller.GetEventRecorder(ctx), r, controller.Options{
+				ConfigStore: &testConfigStore{
+					config: &config.Config{
+						Istio: &config.Istio{AmbientMode: true},
+						Network: &netconfig.Config{
+							EnableMeshPodAddressability: true,
+						},
+					},
+				},
+			})
+	}))
+}
+
 type testConfigStore struct {
 	config *config.Config
 }
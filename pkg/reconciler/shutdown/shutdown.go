@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shutdown drains the reconcilers of the controller before it stops. When the
+// process is signaled, the reconcilers stop picking up new keys and the ones in flight are
+// given until the drain deadline to complete. Only then are the work queues shut down and
+// the leader election Leases released, so that the next leader does not start reconciling
+// the Ingresses while their Gateways are still being edited by this replica.
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+)
+
+const (
+	// DrainDeadlineEnv configures how long the reconciles in flight are waited for on shutdown.
+	DrainDeadlineEnv = "SHUTDOWN_DRAIN_DEADLINE"
+
+	// DefaultDrainDeadline leaves room, within the default termination grace period of 30s,
+	// for the Leases to be released once the reconciles are drained.
+	DefaultDrainDeadline = 20 * time.Second
+)
+
+// drainer tracks the reconciles in flight.
+type drainer struct {
+	deadline time.Duration
+
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+
+	// expired is cancelled once the deadline passes, aborting the reconciles still in flight.
+	expired context.Context
+	expire  context.CancelFunc
+}
+
+type drainerKey struct{}
+
+// NewContext returns a context which, unlike the passed one, is only cancelled once the
+// reconcilers wrapped with WithDrain have been drained after the passed one is done. The
+// drain deadline is configured through SHUTDOWN_DRAIN_DEADLINE.
+func NewContext(signalCtx context.Context) (context.Context, error) {
+	deadline := DefaultDrainDeadline
+	if val := os.Getenv(DrainDeadlineEnv); val != "" {
+		var err error
+		if deadline, err = time.ParseDuration(val); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", DrainDeadlineEnv, err)
+		}
+		if deadline < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was: %v", DrainDeadlineEnv, deadline)
+		}
+	}
+
+	d := newDrainer(deadline)
+	ctx, cancel := context.WithCancel(context.WithoutCancel(signalCtx))
+	go func() {
+		<-signalCtx.Done()
+		d.drain(logging.FromContext(ctx))
+		cancel()
+	}()
+	return context.WithValue(ctx, drainerKey{}, d), nil
+}
+
+func newDrainer(deadline time.Duration) *drainer {
+	d := &drainer{deadline: deadline}
+	d.expired, d.expire = context.WithCancel(context.Background())
+	return d
+}
+
+// begin registers a reconcile, unless the drainer is draining.
+func (d *drainer) begin() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight.Add(1)
+	return true
+}
+
+func (d *drainer) done() {
+	d.inflight.Done()
+}
+
+// drain stops new reconciles and waits for the ones in flight, up to the deadline.
+func (d *drainer) drain(logger *zap.SugaredLogger) {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.inflight.Wait()
+		close(drained)
+	}()
+
+	logger.Infof("Draining the reconciles in flight for up to %v", d.deadline)
+	select {
+	case <-drained:
+		logger.Info("Drained the reconciles in flight")
+	case <-time.After(d.deadline):
+		logger.Infof("Aborting the reconciles still in flight after %v", d.deadline)
+		d.expire()
+		<-drained
+	}
+}
+
+// WithDrain returns the reconciler with its reconciles drained on shutdown, when the context
+// was created by NewContext, and the reconciler unchanged otherwise.
+func WithDrain(ctx context.Context, r controller.Reconciler) controller.Reconciler {
+	d, ok := ctx.Value(drainerKey{}).(*drainer)
+	if !ok {
+		return r
+	}
+	dr := &drainingReconciler{Reconciler: r, drainer: d}
+	if la, ok := r.(reconciler.LeaderAware); ok {
+		return &drainingLeaderAware{drainingReconciler: dr, LeaderAware: la}
+	}
+	return dr
+}
+
+type drainingReconciler struct {
+	controller.Reconciler
+
+	drainer *drainer
+}
+
+// Reconcile implements controller.Reconciler.
+func (r *drainingReconciler) Reconcile(ctx context.Context, key string) error {
+	if !r.drainer.begin() {
+		// The key is reconciled by the next leader, which resyncs all the keys of its buckets.
+		logging.FromContext(ctx).Debugf("Skipping %s while shutting down", key)
+		return nil
+	}
+	defer r.drainer.done()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(r.drainer.expired, cancel)
+	defer stop()
+	return r.Reconciler.Reconcile(ctx, key)
+}
+
+type drainingLeaderAware struct {
+	*drainingReconciler
+	reconciler.LeaderAware
+}
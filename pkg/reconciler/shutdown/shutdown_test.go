@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/reconciler"
+)
+
+type reconcileFunc func(context.Context, string) error
+
+func (f reconcileFunc) Reconcile(ctx context.Context, key string) error {
+	return f(ctx, key)
+}
+
+type fakeLeaderAware struct {
+	reconciler.LeaderAwareFuncs
+	reconcileFunc
+}
+
+func TestNewContext(t *testing.T) {
+	t.Setenv(DrainDeadlineEnv, "1h")
+	signalCtx, signal := context.WithCancel(context.Background())
+	ctx, err := NewContext(signalCtx)
+	if err != nil {
+		t.Fatal("NewContext() =", err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	r := WithDrain(ctx, reconcileFunc(func(context.Context, string) error {
+		close(started)
+		<-release
+		return nil
+	}))
+	go r.Reconcile(context.Background(), "ns/name")
+	<-started
+
+	signal()
+	select {
+	case <-ctx.Done():
+		t.Fatal("The context was cancelled while a reconcile was in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("The context was not cancelled once the reconciles were drained")
+	}
+}
+
+func TestNewContextInvalid(t *testing.T) {
+	for _, val := range []string{"soon", "-1s"} {
+		t.Setenv(DrainDeadlineEnv, val)
+		if _, err := NewContext(context.Background()); err == nil {
+			t.Errorf("NewContext() with %s=%q = nil, wanted an error", DrainDeadlineEnv, val)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	d := newDrainer(50 * time.Millisecond)
+	ctx := context.WithValue(context.Background(), drainerKey{}, d)
+
+	started, aborted := make(chan struct{}), make(chan error)
+	r := WithDrain(ctx, reconcileFunc(func(ctx context.Context, _ string) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	go func() {
+		aborted <- r.Reconcile(context.Background(), "ns/stuck")
+	}()
+	<-started
+
+	// The reconcile in flight is aborted at the deadline.
+	d.drain(logtesting.TestLogger(t))
+	if err := <-aborted; err != context.Canceled {
+		t.Errorf("Reconcile() in flight = %v, want %v", err, context.Canceled)
+	}
+
+	// New reconciles are skipped.
+	called := false
+	r = WithDrain(ctx, reconcileFunc(func(context.Context, string) error {
+		called = true
+		return nil
+	}))
+	if err := r.Reconcile(context.Background(), "ns/new"); err != nil || called {
+		t.Errorf("Reconcile() while draining = %v, called = %v, want it skipped", err, called)
+	}
+}
+
+func TestWithDrain(t *testing.T) {
+	r := reconcileFunc(func(context.Context, string) error { return nil })
+	if _, ok := WithDrain(context.Background(), r).(reconcileFunc); !ok {
+		t.Error("WithDrain() without a drainer did not return the reconciler unchanged")
+	}
+
+	ctx := context.WithValue(context.Background(), drainerKey{}, newDrainer(time.Second))
+	if _, ok := WithDrain(ctx, r).(reconciler.LeaderAware); ok {
+		t.Error("WithDrain() of a reconciler unaware of the leader election is LeaderAware")
+	}
+	var la controller.Reconciler = &fakeLeaderAware{reconcileFunc: r}
+	if _, ok := WithDrain(ctx, la).(reconciler.LeaderAware); !ok {
+		t.Error("WithDrain() of a LeaderAware reconciler is not LeaderAware")
+	}
+}
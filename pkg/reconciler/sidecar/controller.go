@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	istioinformers "knative.dev/net-istio/pkg/client/istio/informers/externalversions"
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/sidecar/resources"
+	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// NewController initializes the controller maintaining Sidecars for namespaces
+// hosting Knative services. The workqueue is keyed by namespace.
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+
+	logger := logging.FromContext(ctx)
+	ingressInformer := ingressinformer.Get(ctx)
+	factory := istioinformers.NewSharedInformerFactory(istioclient.Get(ctx), controller.GetResyncPeriod(ctx))
+	sidecarInformer := factory.Networking().V1beta1().Sidecars()
+
+	r := &reconciler{
+		istioClientSet: istioclient.Get(ctx),
+		ingressLister:  ingressInformer.Lister(),
+		sidecarLister:  sidecarInformer.Lister(),
+	}
+	impl := controller.NewContext(ctx, r, controller.ControllerOptions{
+		WorkQueueName: "Sidecars",
+		Logger:        logger,
+	})
+
+	configStore := config.NewStore(logger.Named("config-store"), func(string, interface{}) {
+		ings, _ := ingressInformer.Lister().List(labels.Everything())
+		for _, ing := range ings {
+			impl.EnqueueNamespaceOf(ing)
+		}
+		scs, _ := sidecarInformer.Lister().List(labels.SelectorFromSet(labels.Set{resources.ManagedLabelKey: "true"}))
+		for _, sc := range scs {
+			impl.EnqueueNamespaceOf(sc)
+		}
+	})
+	configStore.WatchConfigs(cmw)
+	r.configStore = configStore
+
+	ingressInformer.Informer().AddEventHandler(controller.HandleAll(impl.EnqueueNamespaceOf))
+	// All Sidecars are watched, as a Sidecar of the user takes precedence.
+	sidecarInformer.Informer().AddEventHandler(controller.HandleAll(impl.EnqueueNamespaceOf))
+
+	// The informer is not injected, so that it is only started when the controller is
+	// enabled, and sharedmain does not start it.
+	factory.Start(ctx.Done())
+	for informer, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			logger.Fatalf("Failed to sync the %v informer", informer)
+		}
+	}
+
+	return impl
+}
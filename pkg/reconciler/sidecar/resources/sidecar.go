@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	ingressresources "knative.dev/net-istio/pkg/reconciler/ingress/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/pkg/system"
+)
+
+const (
+	// ManagedLabelKey is the label attached to the Sidecars maintained by net-istio.
+	// Sidecars without it are never modified.
+	ManagedLabelKey = networking.GroupName + "/sidecar"
+
+	// SidecarName is the name of the Sidecar maintained for namespaces hosting
+	// Knative services.
+	SidecarName = "knative-serving"
+)
+
+// MakeSidecar creates a Sidecar that limits the egress hosts of the proxies in the
+// given namespace to what Knative services need: the namespace itself, the gateways,
+// the Knative system namespace and the Istio control plane.
+func MakeSidecar(namespace string, gateways []config.Gateway) (*v1beta1.Sidecar, error) {
+	hosts := sets.New(
+		"./*",
+		// The activator.
+		system.Namespace()+"/*",
+		// The control plane.
+		config.IstioNamespace+"/*",
+	)
+	for _, gw := range gateways {
+		meta, err := ingressresources.GetGatewaySvcNameNamespace(gw)
+		if err != nil {
+			return nil, err
+		}
+		hosts.Insert(meta.Namespace + "/" + gw.ServiceURL)
+	}
+
+	sc := &v1beta1.Sidecar{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SidecarName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedLabelKey: "true",
+			},
+		},
+		Spec: istiov1beta1.Sidecar{
+			Egress: []*istiov1beta1.IstioEgressListener{{
+				Hosts: sets.List(hosts),
+			}},
+		},
+	}
+	sc.Annotations = kaccessor.WithSpecHash(sc.Annotations, &sc.Spec)
+	return sc, nil
+}
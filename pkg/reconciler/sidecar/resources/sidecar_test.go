@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/pkg/system"
+
+	_ "knative.dev/pkg/system/testing"
+)
+
+func TestMakeSidecar(t *testing.T) {
+	sc, err := MakeSidecar("testing", []config.Gateway{{
+		ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+	}, {
+		ServiceURL: "knative-local-gateway.gateways.svc.cluster.local",
+	}})
+	if err != nil {
+		t.Fatal("MakeSidecar() =", err)
+	}
+
+	want := []string{
+		"./*",
+		"gateways/knative-local-gateway.gateways.svc.cluster.local",
+		"istio-system/*",
+		"istio-system/istio-ingressgateway.istio-system.svc.cluster.local",
+		system.Namespace() + "/*",
+	}
+	if got := sc.Spec.Egress[0].Hosts; !cmp.Equal(want, got) {
+		t.Error("Unexpected egress hosts (-want +got):", cmp.Diff(want, got))
+	}
+	if sc.Namespace != "testing" || sc.Labels[ManagedLabelKey] != "true" {
+		t.Errorf("Unexpected metadata: %v", sc.ObjectMeta)
+	}
+
+	if _, err := MakeSidecar("testing", []config.Gateway{{ServiceURL: "invalid"}}); err == nil {
+		t.Error("MakeSidecar() = nil, want error for an invalid gateway")
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar
+
+import (
+	"context"
+	"fmt"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	istioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	kaccessor "knative.dev/net-istio/pkg/reconciler/accessor"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/knativens"
+	"knative.dev/net-istio/pkg/reconciler/sidecar/resources"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// reconciler maintains the Sidecar of a single namespace.
+type reconciler struct {
+	istioClientSet istioclientset.Interface
+
+	ingressLister networkinglisters.IngressLister
+	sidecarLister istiolisters.SidecarLister
+
+	configStore pkgreconciler.ConfigStore
+}
+
+var _ controller.Reconciler = (*reconciler)(nil)
+
+// Reconcile converges the Sidecar of the namespace in the key.
+func (r *reconciler) Reconcile(ctx context.Context, key string) error {
+	ctx = r.configStore.ToContext(ctx)
+	logger := logging.FromContext(ctx)
+
+	// The keys are namespaces, which are cluster scoped.
+	_, namespace, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		logger.Errorw("Invalid resource key", key)
+		return nil
+	}
+
+	istiocfg := config.FromContext(ctx).Istio
+	hosting, err := knativens.HostsKnativeServices(r.ingressLister, namespace)
+	if err != nil {
+		return err
+	}
+
+	existing, err := r.sidecarLister.Sidecars(namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list Sidecars: %w", err)
+	}
+	var managed *v1beta1.Sidecar
+	for _, sc := range existing {
+		if sc.Labels[resources.ManagedLabelKey] == "true" {
+			managed = sc
+		} else if sc.Spec.WorkloadSelector == nil {
+			// Istio only supports a single Sidecar without a workload selector per
+			// namespace, so never compete with the one of the user.
+			logger.Warnf("Namespace %s already has the Sidecar %s, skipping", namespace, sc.Name)
+			hosting = false
+		}
+	}
+
+	if !istiocfg.EnableSidecarResources || !hosting {
+		if managed == nil {
+			return nil
+		}
+		if err := r.istioClientSet.NetworkingV1beta1().Sidecars(namespace).Delete(ctx, managed.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("failed to delete Sidecar: %w", err)
+		}
+		return nil
+	}
+
+	desired, err := resources.MakeSidecar(namespace, append(istiocfg.IngressGateways, istiocfg.LocalGateways...))
+	if err != nil {
+		return fmt.Errorf("failed to make Sidecar: %w", err)
+	}
+
+	if managed == nil {
+		if _, err := r.istioClientSet.NetworkingV1beta1().Sidecars(namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create Sidecar: %w", err)
+		}
+		return nil
+	}
+	if kaccessor.SpecHashMatches(managed, &managed.Spec, desired) {
+		return nil
+	}
+
+	// Don't modify the informers copy
+	update := managed.DeepCopy()
+	update.Spec = *desired.Spec.DeepCopy()
+	update.Labels = desired.Labels
+	update.Annotations = desired.Annotations
+	if _, err := r.istioClientSet.NetworkingV1beta1().Sidecars(namespace).Update(ctx, update, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update Sidecar: %w", err)
+	}
+	return nil
+}
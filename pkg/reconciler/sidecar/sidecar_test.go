@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	// Inject our fakes
+	istioclient "knative.dev/net-istio/pkg/client/istio/injection/client"
+
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/net-istio/pkg/reconciler/ingress/config"
+	"knative.dev/net-istio/pkg/reconciler/sidecar/resources"
+	"knative.dev/networking/pkg/apis/networking"
+	netv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+
+	. "knative.dev/net-istio/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+var gateways = []config.Gateway{{
+	Namespace:  "knative-serving",
+	Name:       config.KnativeIngressGateway,
+	ServiceURL: "istio-ingressgateway.istio-system.svc.cluster.local",
+}}
+
+type cfgKey struct{}
+
+func withConfig(enabled bool, gws []config.Gateway) context.Context {
+	return context.WithValue(context.Background(), cfgKey{}, &config.Config{
+		Istio: &config.Istio{
+			EnableSidecarResources: enabled,
+			IngressGateways:        gws,
+		},
+		Network: &netconfig.Config{},
+	})
+}
+
+func ing(class string) *netv1alpha1.Ingress {
+	return &netv1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "testing",
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: class,
+			},
+		},
+	}
+}
+
+func sidecar(gws []config.Gateway) *v1beta1.Sidecar {
+	sc, _ := resources.MakeSidecar("testing", gws)
+	return sc
+}
+
+func userSidecar(selector *istiov1beta1.WorkloadSelector) *v1beta1.Sidecar {
+	return &v1beta1.Sidecar{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "user",
+			Namespace: "testing",
+		},
+		Spec: istiov1beta1.Sidecar{
+			WorkloadSelector: selector,
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	deleteSidecar := clientgotesting.DeleteActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Namespace: "testing",
+			Verb:      "delete",
+			Resource:  v1beta1.SchemeGroupVersion.WithResource("sidecars"),
+		},
+		Name: resources.SidecarName,
+	}
+
+	table := TableTest{{
+		Name: "disabled",
+		Key:  "testing",
+		Ctx:  withConfig(false, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+		},
+	}, {
+		Name: "disabled removes the managed Sidecar",
+		Key:  "testing",
+		Ctx:  withConfig(false, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			sidecar(gateways),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteSidecar},
+	}, {
+		Name: "create for Knative namespace",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+		},
+		WantCreates: []runtime.Object{
+			sidecar(gateways),
+		},
+	}, {
+		Name: "ignore Ingresses of other classes",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing("other.ingress.networking.knative.dev"),
+		},
+	}, {
+		Name: "update on gateway change",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			sidecar(nil),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: sidecar(gateways),
+		}},
+	}, {
+		Name: "steady state",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			sidecar(gateways),
+		},
+	}, {
+		Name: "namespace has a Sidecar of the user",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			userSidecar(nil),
+			sidecar(gateways),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteSidecar},
+	}, {
+		Name: "Sidecars of the user with workload selectors are fine",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+			userSidecar(&istiov1beta1.WorkloadSelector{Labels: map[string]string{"app": "foo"}}),
+		},
+		WantCreates: []runtime.Object{
+			sidecar(gateways),
+		},
+	}, {
+		Name: "remove when the namespace no longer hosts Knative services",
+		Key:  "testing",
+		Ctx:  withConfig(true, gateways),
+		Objects: []runtime.Object{
+			sidecar(gateways),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{deleteSidecar},
+	}, {
+		Name:    "invalid gateway",
+		Key:     "testing",
+		Ctx:     withConfig(true, []config.Gateway{{ServiceURL: "invalid"}}),
+		WantErr: true,
+		Objects: []runtime.Object{
+			ing(netconfig.IstioIngressClassName),
+		},
+	}}
+
+	for i := range table {
+		// The keys are namespaces, which the table test cannot validate against.
+		table[i].SkipNamespaceValidation = true
+		table[i].CmpOpts = []cmp.Option{protocmp.Transform()}
+	}
+
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &reconciler{
+			istioClientSet: istioclient.Get(ctx),
+			ingressLister:  listers.GetIngressLister(),
+			sidecarLister:  listers.GetSidecarLister(),
+			configStore: &testConfigStore{
+				config: ctx.Value(cfgKey{}).(*config.Config),
+			},
+		}
+	}))
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot dumps the informer caches of the Ingress controller, for the offline
+// debugging of Ingresses that were not reconciled as expected: the snapshot shows the
+// Ingresses, Gateways and VirtualServices as the controller saw them, and which Ingresses
+// are notified of the changes of which resources.
+//
+// The snapshots are served on the loopback interface only, and are fetched through a port
+// forward, e.g.
+//
+//	kubectl -n knative-serving port-forward deployment/net-istio-controller 8009
+//	curl -o snapshot.json localhost:8009/snapshot
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/tracker"
+)
+
+const (
+	// AddressEnv configures the address the snapshots are served on. Setting it to an
+	// empty value disables the endpoint.
+	AddressEnv = "SNAPSHOT_ADDRESS"
+
+	// defaultAddress is only reachable from within the pod, e.g. through a port forward,
+	// as the snapshots expose the specs of all the Ingresses.
+	defaultAddress = "127.0.0.1:8009"
+)
+
+// Snapshot is the content of the informer caches of the Ingress controller.
+type Snapshot struct {
+	Time              time.Time                 `json:"time"`
+	Ingresses         []*v1alpha1.Ingress       `json:"ingresses"`
+	Gateways          []*v1beta1.Gateway        `json:"gateways"`
+	VirtualServices   []*v1beta1.VirtualService `json:"virtualServices"`
+	TrackedReferences []TrackedReference        `json:"trackedReferences"`
+}
+
+// TrackedReference is a resource whose changes are notified to the Ingresses observing it.
+type TrackedReference struct {
+	tracker.Reference
+	Observers []types.NamespacedName `json:"observers"`
+}
+
+// Caches are the caches of the Ingress controller the snapshots are taken from.
+type Caches struct {
+	Ingresses       networkinglisters.IngressLister
+	Gateways        istiolisters.GatewayLister
+	VirtualServices istiolisters.VirtualServiceLister
+	Tracker         *Tracker
+}
+
+// Take returns the current content of the caches, sorted by namespace and name.
+func (c *Caches) Take() (*Snapshot, error) {
+	s := &Snapshot{Time: time.Now().UTC()}
+	var err error
+	if s.Ingresses, err = c.Ingresses.List(labels.Everything()); err != nil {
+		return nil, err
+	}
+	if s.Gateways, err = c.Gateways.List(labels.Everything()); err != nil {
+		return nil, err
+	}
+	if s.VirtualServices, err = c.VirtualServices.List(labels.Everything()); err != nil {
+		return nil, err
+	}
+	sortByKey(s.Ingresses)
+	sortByKey(s.Gateways)
+	sortByKey(s.VirtualServices)
+	s.TrackedReferences = c.Tracker.References()
+	return s, nil
+}
+
+func sortByKey[T metav1.Object](objs []T) {
+	sort.Slice(objs, func(i, j int) bool {
+		if objs[i].GetNamespace() != objs[j].GetNamespace() {
+			return objs[i].GetNamespace() < objs[j].GetNamespace()
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+}
+
+// Dumper serves the snapshots of the caches of the controller, once they are set.
+type Dumper struct {
+	mu     sync.RWMutex
+	caches *Caches
+}
+
+type dumperKey struct{}
+
+// NewDumper creates a Dumper, serving no snapshot until its caches are set.
+func NewDumper() *Dumper {
+	return &Dumper{}
+}
+
+// WithDumper returns the passed context with the dumper attached.
+func WithDumper(ctx context.Context, d *Dumper) context.Context {
+	return context.WithValue(ctx, dumperKey{}, d)
+}
+
+// FromContext returns the dumper attached to the context, or nil if the snapshots are not
+// served.
+func FromContext(ctx context.Context) *Dumper {
+	if d, ok := ctx.Value(dumperKey{}).(*Dumper); ok {
+		return d
+	}
+	return nil
+}
+
+// SetCaches sets the caches the snapshots are taken from.
+func (d *Dumper) SetCaches(c *Caches) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.caches = c
+}
+
+// Serve serves the snapshots on the address of AddressEnv until the context is done.
+func (d *Dumper) Serve(ctx context.Context) error {
+	addr, ok := os.LookupEnv(AddressEnv)
+	if !ok {
+		addr = defaultAddress
+	}
+	if addr == "" {
+		return nil
+	}
+	server := &http.Server{Addr: addr, Handler: d.handler(), ReadHeaderTimeout: time.Minute}
+
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (d *Dumper) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/snapshot", func(w http.ResponseWriter, _ *http.Request) {
+		d.mu.RLock()
+		caches := d.caches
+		d.mu.RUnlock()
+		if caches == nil {
+			http.Error(w, "the Ingress controller is not started", http.StatusServiceUnavailable)
+			return
+		}
+		s, err := caches.Take()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(s)
+	})
+	return mux
+}
+
+// Tracker records the references tracked through it, which the tracker it wraps does not
+// expose, so that they are listed in the snapshots.
+type Tracker struct {
+	tracker.Interface
+
+	mu sync.Mutex
+	// refs are the references tracked by each observer.
+	refs map[types.NamespacedName]sets.Set[tracker.Reference]
+}
+
+// NewTracker wraps the tracker to record the references tracked through it.
+func NewTracker(t tracker.Interface) *Tracker {
+	return &Tracker{
+		Interface: t,
+		refs:      map[types.NamespacedName]sets.Set[tracker.Reference]{},
+	}
+}
+
+// TrackReference implements tracker.Interface.
+func (t *Tracker) TrackReference(ref tracker.Reference, obj interface{}) error {
+	if err := t.Interface.TrackReference(ref, obj); err != nil {
+		return err
+	}
+	// Only the references to named resources can be looked up again.
+	if ref.Name == "" {
+		return nil
+	}
+	observer, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		return nil
+	}
+	key := types.NamespacedName{Namespace: observer.GetNamespace(), Name: observer.GetName()}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.refs[key] == nil {
+		t.refs[key] = sets.New[tracker.Reference]()
+	}
+	t.refs[key].Insert(ref)
+	return nil
+}
+
+// OnDeletedObserver implements tracker.Interface.
+func (t *Tracker) OnDeletedObserver(obj interface{}) {
+	t.Interface.OnDeletedObserver(obj)
+	observer, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.refs, types.NamespacedName{Namespace: observer.GetNamespace(), Name: observer.GetName()})
+}
+
+// References returns the tracked references, with the observers the wrapped tracker still
+// notifies of their changes, sorted.
+func (t *Tracker) References() []TrackedReference {
+	t.mu.Lock()
+	refs := sets.New[tracker.Reference]()
+	for _, r := range t.refs {
+		refs = refs.Union(r)
+	}
+	t.mu.Unlock()
+
+	result := make([]TrackedReference, 0, refs.Len())
+	for ref := range refs {
+		// The tracker drops the observers whose tracking expired.
+		observers := t.Interface.GetObservers(&metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: ref.APIVersion, Kind: ref.Kind},
+			ObjectMeta: metav1.ObjectMeta{Namespace: ref.Namespace, Name: ref.Name},
+		})
+		if len(observers) == 0 {
+			continue
+		}
+		sort.Slice(observers, func(i, j int) bool {
+			return observers[i].String() < observers[j].String()
+		})
+		result = append(result, TrackedReference{Reference: ref, Observers: observers})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Reference, result[j].Reference
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/tracker"
+)
+
+func ingress(namespace, name string) *v1alpha1.Ingress {
+	return &v1alpha1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func secretRef(namespace, name string) tracker.Reference {
+	return tracker.Reference{APIVersion: "v1", Kind: "Secret", Namespace: namespace, Name: name}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker(tracker.New(func(types.NamespacedName) {}, time.Hour))
+
+	if err := tr.TrackReference(secretRef("ns", "b"), ingress("ns", "foo")); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	if err := tr.TrackReference(secretRef("ns", "a"), ingress("ns", "foo")); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	if err := tr.TrackReference(secretRef("ns", "a"), ingress("ns", "bar")); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+	// The references selecting resources by labels are not recorded.
+	if err := tr.TrackReference(tracker.Reference{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  "ns",
+		Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "baz"}},
+	}, ingress("ns", "baz")); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+
+	want := []TrackedReference{{
+		Reference: secretRef("ns", "a"),
+		Observers: []types.NamespacedName{{Namespace: "ns", Name: "bar"}, {Namespace: "ns", Name: "foo"}},
+	}, {
+		Reference: secretRef("ns", "b"),
+		Observers: []types.NamespacedName{{Namespace: "ns", Name: "foo"}},
+	}}
+	if got := tr.References(); !cmp.Equal(got, want) {
+		t.Error("References (-want, +got):", cmp.Diff(want, got))
+	}
+
+	tr.OnDeletedObserver(ingress("ns", "foo"))
+	want = []TrackedReference{{
+		Reference: secretRef("ns", "a"),
+		Observers: []types.NamespacedName{{Namespace: "ns", Name: "bar"}},
+	}}
+	if got := tr.References(); !cmp.Equal(got, want) {
+		t.Error("References after the deletion of an observer (-want, +got):", cmp.Diff(want, got))
+	}
+}
+
+func snapshotOf(t *testing.T, d *Dumper) (int, *Snapshot) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	d.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot", nil))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(rec.Body.Bytes(), s); err != nil {
+		t.Fatal("Failed to parse the snapshot:", err)
+	}
+	return rec.Code, s
+}
+
+func TestDumper(t *testing.T) {
+	d := NewDumper()
+	if code, _ := snapshotOf(t, d); code != http.StatusServiceUnavailable {
+		t.Errorf("Snapshot before the caches are set = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+
+	ingresses := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	ingresses.Add(ingress("ns", "foo"))
+	ingresses.Add(ingress("default", "foo"))
+	ingresses.Add(ingress("ns", "bar"))
+	gateways := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	gateways.Add(&v1beta1.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "gw"}})
+	virtualServices := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	virtualServices.Add(&v1beta1.VirtualService{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo-ingress"}})
+	tr := NewTracker(tracker.New(func(types.NamespacedName) {}, time.Hour))
+	if err := tr.TrackReference(tracker.Reference{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Namespace:  "ns",
+		Name:       "svc",
+	}, ingress("ns", "foo")); err != nil {
+		t.Fatal("TrackReference() =", err)
+	}
+
+	d.SetCaches(&Caches{
+		Ingresses:       networkinglisters.NewIngressLister(ingresses),
+		Gateways:        istiolisters.NewGatewayLister(gateways),
+		VirtualServices: istiolisters.NewVirtualServiceLister(virtualServices),
+		Tracker:         tr,
+	})
+	code, s := snapshotOf(t, d)
+	if code != http.StatusOK {
+		t.Fatalf("Snapshot = %d, want %d", code, http.StatusOK)
+	}
+
+	var got []string
+	for _, ing := range s.Ingresses {
+		got = append(got, ing.Namespace+"/"+ing.Name)
+	}
+	if want := []string{"default/foo", "ns/bar", "ns/foo"}; !cmp.Equal(got, want) {
+		t.Errorf("Ingresses = %v, want: %v", got, want)
+	}
+	if len(s.Gateways) != 1 || len(s.VirtualServices) != 1 {
+		t.Errorf("Gateways, VirtualServices = %d, %d, want: 1, 1", len(s.Gateways), len(s.VirtualServices))
+	}
+	if len(s.TrackedReferences) != 1 || s.TrackedReferences[0].Kind != "Service" {
+		t.Errorf("TrackedReferences = %v, want the Service", s.TrackedReferences)
+	}
+}
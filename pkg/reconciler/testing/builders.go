@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	istiov1beta1 "istio.io/api/networking/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	netconfig "knative.dev/networking/pkg/config"
+	"knative.dev/pkg/kmeta"
+)
+
+// IngressOption mutates an Ingress built by NewIngress.
+type IngressOption func(*v1alpha1.Ingress)
+
+// NewIngress builds an Ingress of the Istio ingress class.
+func NewIngress(namespace, name string, opts ...IngressOption) *v1alpha1.Ingress {
+	ing := &v1alpha1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Annotations: map[string]string{
+				networking.IngressClassAnnotationKey: netconfig.IstioIngressClassName,
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(ing)
+	}
+	return ing
+}
+
+// NewIngressRule builds a rule routing all the paths of the hosts to the service.
+func NewIngressRule(visibility v1alpha1.IngressVisibility, hosts []string, namespace, service string, port int) v1alpha1.IngressRule {
+	return v1alpha1.IngressRule{
+		Hosts:      hosts,
+		Visibility: visibility,
+		HTTP: &v1alpha1.HTTPIngressRuleValue{
+			Paths: []v1alpha1.HTTPIngressPath{{
+				Splits: []v1alpha1.IngressBackendSplit{{
+					IngressBackend: v1alpha1.IngressBackend{
+						ServiceNamespace: namespace,
+						ServiceName:      service,
+						ServicePort:      intstr.FromInt(port),
+					},
+					Percent: 100,
+				}},
+			}},
+		},
+	}
+}
+
+// WithIngressRules appends the rules to the Ingress.
+func WithIngressRules(rules ...v1alpha1.IngressRule) IngressOption {
+	return func(ing *v1alpha1.Ingress) {
+		ing.Spec.Rules = append(ing.Spec.Rules, rules...)
+	}
+}
+
+// WithIngressTLS appends the TLS configurations to the Ingress.
+func WithIngressTLS(tls ...v1alpha1.IngressTLS) IngressOption {
+	return func(ing *v1alpha1.Ingress) {
+		ing.Spec.TLS = append(ing.Spec.TLS, tls...)
+	}
+}
+
+// WithIngressAnnotations adds the annotations to the Ingress.
+func WithIngressAnnotations(annotations map[string]string) IngressOption {
+	return func(ing *v1alpha1.Ingress) {
+		if ing.Annotations == nil {
+			ing.Annotations = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			ing.Annotations[k] = v
+		}
+	}
+}
+
+// WithIngressFinalizers sets the finalizers of the Ingress.
+func WithIngressFinalizers(finalizers ...string) IngressOption {
+	return func(ing *v1alpha1.Ingress) {
+		ing.Finalizers = finalizers
+	}
+}
+
+// WithIngressDeletionTimestamp marks the Ingress deleted at the given time.
+func WithIngressDeletionTimestamp(t metav1.Time) IngressOption {
+	return func(ing *v1alpha1.Ingress) {
+		ing.DeletionTimestamp = &t
+	}
+}
+
+// WithIngressStatus sets the status of the Ingress.
+func WithIngressStatus(status v1alpha1.IngressStatus) IngressOption {
+	return func(ing *v1alpha1.Ingress) {
+		ing.Status = status
+	}
+}
+
+// GatewayOpt mutates a Gateway built by NewGateway.
+type GatewayOpt func(*v1beta1.Gateway)
+
+// NewGateway builds a Gateway.
+func NewGateway(namespace, name string, opts ...GatewayOpt) *v1beta1.Gateway {
+	gw := &v1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	for _, opt := range opts {
+		opt(gw)
+	}
+	return gw
+}
+
+// WithServers sets the servers of the Gateway.
+func WithServers(servers ...*istiov1beta1.Server) GatewayOpt {
+	return func(gw *v1beta1.Gateway) {
+		gw.Spec.Servers = servers
+	}
+}
+
+// WithGatewaySelector sets the selector of the gateway pods of the Gateway.
+func WithGatewaySelector(selector map[string]string) GatewayOpt {
+	return func(gw *v1beta1.Gateway) {
+		gw.Spec.Selector = selector
+	}
+}
+
+// WithGatewayLabels sets the labels of the Gateway.
+func WithGatewayLabels(labels map[string]string) GatewayOpt {
+	return func(gw *v1beta1.Gateway) {
+		gw.Labels = labels
+	}
+}
+
+// WithGatewayOwner makes the Ingress the controller of the Gateway.
+func WithGatewayOwner(ing *v1alpha1.Ingress) GatewayOpt {
+	return func(gw *v1beta1.Gateway) {
+		gw.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
+	}
+}
+
+// WithGatewayResourceVersion sets the resource version of the Gateway.
+func WithGatewayResourceVersion(version string) GatewayOpt {
+	return func(gw *v1beta1.Gateway) {
+		gw.ResourceVersion = version
+	}
+}
+
+// VirtualServiceOpt mutates a VirtualService built by NewVirtualService.
+type VirtualServiceOpt func(*v1beta1.VirtualService)
+
+// NewVirtualService builds a VirtualService.
+func NewVirtualService(namespace, name string, opts ...VirtualServiceOpt) *v1beta1.VirtualService {
+	vs := &v1beta1.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	for _, opt := range opts {
+		opt(vs)
+	}
+	return vs
+}
+
+// WithVirtualServiceHosts sets the hosts of the VirtualService.
+func WithVirtualServiceHosts(hosts ...string) VirtualServiceOpt {
+	return func(vs *v1beta1.VirtualService) {
+		vs.Spec.Hosts = hosts
+	}
+}
+
+// WithVirtualServiceGateways sets the gateways the VirtualService is bound to.
+func WithVirtualServiceGateways(gateways ...string) VirtualServiceOpt {
+	return func(vs *v1beta1.VirtualService) {
+		vs.Spec.Gateways = gateways
+	}
+}
+
+// WithHTTPRoutes appends the HTTP routes to the VirtualService.
+func WithHTTPRoutes(routes ...*istiov1beta1.HTTPRoute) VirtualServiceOpt {
+	return func(vs *v1beta1.VirtualService) {
+		vs.Spec.Http = append(vs.Spec.Http, routes...)
+	}
+}
+
+// WithVirtualServiceLabels sets the labels of the VirtualService.
+func WithVirtualServiceLabels(labels map[string]string) VirtualServiceOpt {
+	return func(vs *v1beta1.VirtualService) {
+		vs.Labels = labels
+	}
+}
+
+// WithVirtualServiceOwner makes the Ingress the controller of the VirtualService.
+func WithVirtualServiceOwner(ing *v1alpha1.Ingress) VirtualServiceOpt {
+	return func(vs *v1beta1.VirtualService) {
+		vs.OwnerReferences = []metav1.OwnerReference{*kmeta.NewControllerRef(ing)}
+	}
+}
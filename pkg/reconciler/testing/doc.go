@@ -0,0 +1,27 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package istio contains the scaffolding of the table tests of the net-istio reconcilers,
+// for the tests of this repository as well as of the forks and extensions of net-istio:
+//
+//   - MakeFactory wires a reconciler to fake clients seeded with the objects of a row of a
+//     knative.dev/pkg/reconciler/testing.TableTest, and Listers lists these objects.
+//   - CreateGateways seeds the fake Istio client with the Gateways among them, which its
+//     tracker can't do on its own.
+//   - NewIngress, NewGateway and NewVirtualService build the fixtures of the rows.
+//   - StatusManager fakes the probes of the Ingresses, which are always ready, ready
+//     after a number of probes or failing, to test the readiness of the reconcilers.
+package istio
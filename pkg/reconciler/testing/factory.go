@@ -18,12 +18,13 @@ package istio
 
 import (
 	"context"
+	"sort"
 	"testing"
 
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fakeistioclient "knative.dev/net-istio/pkg/client/istio/injection/client/fake"
-	"knative.dev/networking/pkg/apis/networking/v1alpha1"
 	fakenetworkingclient "knative.dev/networking/pkg/client/injection/client/fake"
-	fakestatusmanager "knative.dev/networking/pkg/testing/status"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	"knative.dev/pkg/reconciler"
 
@@ -41,7 +42,9 @@ import (
 // Ctor functions create a k8s controller with given params.
 type Ctor func(context.Context, *Listers, configmap.Watcher) controller.Reconciler
 
-// FakeStatusManagerKey is a key for retrieving the FakeStatusManager in a test
+// FakeStatusManagerKey is a key for retrieving the status.Manager of a test. The rows
+// may set it in their context, e.g. to a ReadyAfter or FailWith StatusManager, and
+// default to an AlwaysReady one.
 var FakeStatusManagerKey struct{}
 
 // MakeFactory creates a reconciler factory with fake clients and controller created by `ctor`.
@@ -64,11 +67,9 @@ func MakeFactory(ctor Ctor) rtesting.Factory {
 		ctx, istioclient := fakeistioclient.With(ctx, ls.GetIstioObjects()...)
 		ctx, kubeclient := fakekubeclient.With(ctx, ls.GetKubeObjects()...)
 
-		ctx = context.WithValue(ctx, FakeStatusManagerKey, &fakestatusmanager.FakeStatusManager{
-			FakeIsReady: func(ctx context.Context, ing *v1alpha1.Ingress) (bool, error) {
-				return true, nil
-			},
-		})
+		if ctx.Value(FakeStatusManagerKey) == nil {
+			ctx = context.WithValue(ctx, FakeStatusManagerKey, AlwaysReady())
+		}
 
 		// This is needed by the Configuration controller tests, which
 		// use GenerateName to produce Revisions.
@@ -108,3 +109,27 @@ func MakeFactory(ctor Ctor) rtesting.Factory {
 		return c, actionRecorderList, eventList
 	}
 }
+
+// CreateGateways creates the Gateways of the listers through the fake Istio client of the
+// context. As we use a customized resource name for Gateway CRD (i.e. `gateways`), not the one
+// originally generated by kubernetes code generator (i.e. `gatewaies`), the tracker of the fake
+// client can't find the Gateways it was seeded with, and they have to be created explicitly
+// when setting up the test per suggestion
+// https://github.com/knative/serving/blob/a6852fc3b6cdce72b99c5d578dd64f2e03dabb8b/vendor/k8s.io/client-go/testing/fixture.go#L292
+func CreateGateways(ctx context.Context, ls *Listers) error {
+	var gateways []*v1beta1.Gateway
+	for _, obj := range ls.GetIstioObjects() {
+		if gw, ok := obj.(*v1beta1.Gateway); ok {
+			gateways = append(gateways, gw)
+		}
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].Name < gateways[j].Name
+	})
+	for _, gw := range gateways {
+		if _, err := fakeistioclient.Get(ctx).NetworkingV1beta1().Gateways(gw.Namespace).Create(ctx, gw, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,14 +17,24 @@ limitations under the License.
 package istio
 
 import (
+	istioextensionsv1alpha1 "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	istiov1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	istiov1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
+	istiosecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	istiotelemetryv1alpha1 "istio.io/client-go/pkg/apis/telemetry/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
 	fakeistioclientset "knative.dev/net-istio/pkg/client/istio/clientset/versioned/fake"
+	istioextensionslisters "knative.dev/net-istio/pkg/client/istio/listers/extensions/v1alpha1"
+	istiov1alpha3listers "knative.dev/net-istio/pkg/client/istio/listers/networking/v1alpha3"
 	istiolisters "knative.dev/net-istio/pkg/client/istio/listers/networking/v1beta1"
+	istiosecuritylisters "knative.dev/net-istio/pkg/client/istio/listers/security/v1beta1"
+	istiotelemetrylisters "knative.dev/net-istio/pkg/client/istio/listers/telemetry/v1alpha1"
 	networking "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	fakenetworkingclientset "knative.dev/networking/pkg/client/clientset/versioned/fake"
 	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
@@ -108,6 +118,41 @@ func (l *Listers) GetDestinationRuleLister() istiolisters.DestinationRuleLister
 	return istiolisters.NewDestinationRuleLister(l.IndexerFor(&istiov1beta1.DestinationRule{}))
 }
 
+// GetEnvoyFilterLister get lister for istio EnvoyFilter resource.
+func (l *Listers) GetEnvoyFilterLister() istiov1alpha3listers.EnvoyFilterLister {
+	return istiov1alpha3listers.NewEnvoyFilterLister(l.IndexerFor(&istiov1alpha3.EnvoyFilter{}))
+}
+
+// GetWasmPluginLister get lister for istio WasmPlugin resource.
+func (l *Listers) GetWasmPluginLister() istioextensionslisters.WasmPluginLister {
+	return istioextensionslisters.NewWasmPluginLister(l.IndexerFor(&istioextensionsv1alpha1.WasmPlugin{}))
+}
+
+// GetSidecarLister get lister for istio Sidecar resource.
+func (l *Listers) GetSidecarLister() istiolisters.SidecarLister {
+	return istiolisters.NewSidecarLister(l.IndexerFor(&istiov1beta1.Sidecar{}))
+}
+
+// GetAuthorizationPolicyLister get lister for istio AuthorizationPolicy resource.
+func (l *Listers) GetAuthorizationPolicyLister() istiosecuritylisters.AuthorizationPolicyLister {
+	return istiosecuritylisters.NewAuthorizationPolicyLister(l.IndexerFor(&istiosecurityv1beta1.AuthorizationPolicy{}))
+}
+
+// GetPeerAuthenticationLister get lister for istio PeerAuthentication resource.
+func (l *Listers) GetPeerAuthenticationLister() istiosecuritylisters.PeerAuthenticationLister {
+	return istiosecuritylisters.NewPeerAuthenticationLister(l.IndexerFor(&istiosecurityv1beta1.PeerAuthentication{}))
+}
+
+// GetRequestAuthenticationLister get lister for istio RequestAuthentication resource.
+func (l *Listers) GetRequestAuthenticationLister() istiosecuritylisters.RequestAuthenticationLister {
+	return istiosecuritylisters.NewRequestAuthenticationLister(l.IndexerFor(&istiosecurityv1beta1.RequestAuthentication{}))
+}
+
+// GetTelemetryLister get lister for istio Telemetry resource.
+func (l *Listers) GetTelemetryLister() istiotelemetrylisters.TelemetryLister {
+	return istiotelemetrylisters.NewTelemetryLister(l.IndexerFor(&istiotelemetryv1alpha1.Telemetry{}))
+}
+
 // GetK8sServiceLister get lister for K8s Service resource.
 func (l *Listers) GetK8sServiceLister() corev1listers.ServiceLister {
 	return corev1listers.NewServiceLister(l.IndexerFor(&corev1.Service{}))
@@ -118,7 +163,17 @@ func (l *Listers) GetEndpointsLister() corev1listers.EndpointsLister {
 	return corev1listers.NewEndpointsLister(l.IndexerFor(&corev1.Endpoints{}))
 }
 
+// GetNetworkPolicyLister get lister for K8s NetworkPolicy resource.
+func (l *Listers) GetNetworkPolicyLister() networkingv1listers.NetworkPolicyLister {
+	return networkingv1listers.NewNetworkPolicyLister(l.IndexerFor(&networkingv1.NetworkPolicy{}))
+}
+
 // GetSecretLister get lister for K8s Secret resource.
 func (l *Listers) GetSecretLister() corev1listers.SecretLister {
 	return corev1listers.NewSecretLister(l.IndexerFor(&corev1.Secret{}))
 }
+
+// GetConfigMapLister get lister for K8s ConfigMap resource.
+func (l *Listers) GetConfigMapLister() corev1listers.ConfigMapLister {
+	return corev1listers.NewConfigMapLister(l.IndexerFor(&corev1.ConfigMap{}))
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/pkg/status"
+)
+
+// StatusManager is a status.Manager whose probes of the Ingresses succeed, fail or
+// error as configured, without any network access. It counts the probes of each
+// Ingress and is safe for concurrent use, e.g. by the workers of a controller.
+type StatusManager struct {
+	// notReady is the number of probes of each Ingress that report it as not ready.
+	notReady int
+	// err is the error of every probe, if set.
+	err error
+
+	mu         sync.Mutex
+	callCounts map[types.NamespacedName]int
+}
+
+var _ status.Manager = (*StatusManager)(nil)
+
+// AlwaysReady returns a StatusManager reporting every Ingress as ready.
+func AlwaysReady() *StatusManager {
+	return &StatusManager{}
+}
+
+// ReadyAfter returns a StatusManager reporting each Ingress as not ready for its first
+// n probes, and as ready afterwards, like a gateway that is slow to program its routes.
+func ReadyAfter(n int) *StatusManager {
+	return &StatusManager{notReady: n}
+}
+
+// FailWith returns a StatusManager whose probes all fail with the error.
+func FailWith(err error) *StatusManager {
+	return &StatusManager{err: err}
+}
+
+// IsReady implements status.Manager.
+func (m *StatusManager) IsReady(_ context.Context, ing *v1alpha1.Ingress) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.callCounts == nil {
+		m.callCounts = make(map[types.NamespacedName]int, 1)
+	}
+	key := types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}
+	m.callCounts[key]++
+
+	if m.err != nil {
+		return false, m.err
+	}
+	return m.callCounts[key] > m.notReady, nil
+}
+
+// IsReadyCallCount returns how many times the Ingress was probed.
+func (m *StatusManager) IsReadyCallCount(ing *v1alpha1.Ingress) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.callCounts[types.NamespacedName{Namespace: ing.Namespace, Name: ing.Name}]
+}
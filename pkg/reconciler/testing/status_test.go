@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStatusManager(t *testing.T) {
+	foo, bar := NewIngress("ns", "foo"), NewIngress("ns", "bar")
+	errProbe := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		manager *StatusManager
+		want    []bool
+		wantErr error
+	}{{
+		name:    "always ready",
+		manager: AlwaysReady(),
+		want:    []bool{true, true},
+	}, {
+		name:    "ready after 2",
+		manager: ReadyAfter(2),
+		want:    []bool{false, false, true},
+	}, {
+		name:    "fail with error",
+		manager: FailWith(errProbe),
+		want:    []bool{false, false},
+		wantErr: errProbe,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i, want := range test.want {
+				got, err := test.manager.IsReady(context.Background(), foo)
+				if got != want || !errors.Is(err, test.wantErr) {
+					t.Errorf("IsReady() #%d = (%v, %v), want: (%v, %v)", i, got, err, want, test.wantErr)
+				}
+			}
+			if got, want := test.manager.IsReadyCallCount(foo), len(test.want); got != want {
+				t.Errorf("IsReadyCallCount(foo) = %d, want: %d", got, want)
+			}
+			// The probes are counted per Ingress.
+			if got := test.manager.IsReadyCallCount(bar); got != 0 {
+				t.Errorf("IsReadyCallCount(bar) = %d, want: 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Settings are the tuning knobs which can be changed while the controller runs. Zero
+// values are unset.
+type Settings struct {
+	RateLimiterBaseDelay time.Duration
+	RateLimiterMaxDelay  time.Duration
+	RateLimiterQPS       int
+	RateLimiterBurst     int
+	GlobalResyncBudget   time.Duration
+}
+
+// withDefaults returns the settings with their unset values taken from the defaults.
+func (s Settings) withDefaults(defaults Settings) Settings {
+	if s.RateLimiterBaseDelay == 0 {
+		s.RateLimiterBaseDelay = defaults.RateLimiterBaseDelay
+	}
+	if s.RateLimiterMaxDelay == 0 {
+		s.RateLimiterMaxDelay = defaults.RateLimiterMaxDelay
+	}
+	if s.RateLimiterQPS == 0 {
+		s.RateLimiterQPS = defaults.RateLimiterQPS
+	}
+	if s.RateLimiterBurst == 0 {
+		s.RateLimiterBurst = defaults.RateLimiterBurst
+	}
+	if s.GlobalResyncBudget == 0 {
+		s.GlobalResyncBudget = defaults.GlobalResyncBudget
+	}
+	return s
+}
+
+// Dynamic applies the settings while the controller runs. It is the rate limiter of the
+// work queue, and the source of the global resync budget.
+type Dynamic struct {
+	defaults Settings
+	limiter  *rate.Limiter
+
+	mu       sync.RWMutex
+	current  Settings
+	failures workqueue.RateLimiter
+}
+
+var _ workqueue.RateLimiter = (*Dynamic)(nil)
+
+// NewDynamic creates a Dynamic applying the settings of the environment attached to the
+// context, or the defaults of client-go for the ones not set, until updated.
+func NewDynamic(ctx context.Context) *Dynamic {
+	defaults, ok := ctx.Value(rateLimiterKey{}).(Settings)
+	if !ok {
+		defaults = Settings{
+			RateLimiterBaseDelay: defaultBaseDelay,
+			RateLimiterMaxDelay:  defaultMaxDelay,
+			RateLimiterQPS:       defaultQPS,
+			RateLimiterBurst:     defaultBurst,
+		}
+	}
+	defaults.GlobalResyncBudget = GlobalResyncBudgetFromContext(ctx)
+
+	d := &Dynamic{
+		defaults: defaults,
+		limiter:  rate.NewLimiter(rate.Limit(defaults.RateLimiterQPS), defaults.RateLimiterBurst),
+	}
+	d.apply(defaults)
+	return d
+}
+
+// Update applies the settings, falling back to the ones of the environment for the unset
+// ones. The failures of the keys being retried are forgotten when the delays change.
+func (d *Dynamic) Update(s Settings) error {
+	s = s.withDefaults(d.defaults)
+	if s.RateLimiterBaseDelay > s.RateLimiterMaxDelay {
+		return fmt.Errorf("the base delay (%v) of the work queue must not exceed its max delay (%v)",
+			s.RateLimiterBaseDelay, s.RateLimiterMaxDelay)
+	}
+	d.apply(s)
+	return nil
+}
+
+func (d *Dynamic) apply(s Settings) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failures == nil || s.RateLimiterBaseDelay != d.current.RateLimiterBaseDelay || s.RateLimiterMaxDelay != d.current.RateLimiterMaxDelay {
+		d.failures = workqueue.NewItemExponentialFailureRateLimiter(s.RateLimiterBaseDelay, s.RateLimiterMaxDelay)
+	}
+	d.limiter.SetLimit(rate.Limit(s.RateLimiterQPS))
+	d.limiter.SetBurst(s.RateLimiterBurst)
+	d.current = s
+}
+
+// Current returns the settings applied.
+func (d *Dynamic) Current() Settings {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.current
+}
+
+// GlobalResyncBudget returns the duration the global resyncs should be spread over, or zero
+// if every object should be enqueued at once.
+func (d *Dynamic) GlobalResyncBudget() time.Duration {
+	return d.Current().GlobalResyncBudget
+}
+
+// When implements workqueue.RateLimiter, like the max of an exponential failure rate
+// limiter and of an overall bucket rate limiter.
+func (d *Dynamic) When(item interface{}) time.Duration {
+	return max(d.failureLimiter().When(item), d.limiter.Reserve().Delay())
+}
+
+// Forget implements workqueue.RateLimiter.
+func (d *Dynamic) Forget(item interface{}) {
+	d.failureLimiter().Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimiter.
+func (d *Dynamic) NumRequeues(item interface{}) int {
+	return d.failureLimiter().NumRequeues(item)
+}
+
+func (d *Dynamic) failureLimiter() workqueue.RateLimiter {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.failures
+}
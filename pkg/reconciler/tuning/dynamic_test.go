@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDynamic(t *testing.T) {
+	t.Setenv(RateLimiterBaseDelayEnv, "1s")
+	t.Setenv(GlobalResyncBudgetEnv, "1m")
+	ctx, err := GetContextWithRateLimiter(context.Background())
+	if err != nil {
+		t.Fatal("GetContextWithRateLimiter() =", err)
+	}
+	if ctx, err = GetContextWithGlobalResyncBudget(ctx); err != nil {
+		t.Fatal("GetContextWithGlobalResyncBudget() =", err)
+	}
+
+	d := NewDynamic(ctx)
+	env := Settings{
+		RateLimiterBaseDelay: time.Second,
+		RateLimiterMaxDelay:  defaultMaxDelay,
+		RateLimiterQPS:       defaultQPS,
+		RateLimiterBurst:     defaultBurst,
+		GlobalResyncBudget:   time.Minute,
+	}
+	if got := d.Current(); got != env {
+		t.Errorf("Current() = %+v, want the environment %+v", got, env)
+	}
+	if got := d.When("key"); got != time.Second {
+		t.Errorf("When() = %v, want %v", got, time.Second)
+	}
+
+	// Changing the overall rate keeps the backoff of the keys being retried.
+	if err := d.Update(Settings{RateLimiterQPS: 50, GlobalResyncBudget: 2 * time.Minute}); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if got, want := d.NumRequeues("key"), 1; got != want {
+		t.Errorf("NumRequeues() = %d, want %d", got, want)
+	}
+	if got, want := d.GlobalResyncBudget(), 2*time.Minute; got != want {
+		t.Errorf("GlobalResyncBudget() = %v, want %v", got, want)
+	}
+
+	// Changing the delays resets it.
+	if err := d.Update(Settings{RateLimiterBaseDelay: 10 * time.Millisecond}); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if got := d.NumRequeues("key"); got != 0 {
+		t.Errorf("NumRequeues() = %d, want 0", got)
+	}
+	if got, want := d.When("key"), 10*time.Millisecond; got != want {
+		t.Errorf("When() = %v, want %v", got, want)
+	}
+
+	// Unset settings fall back to the environment.
+	if err := d.Update(Settings{}); err != nil {
+		t.Fatal("Update() =", err)
+	}
+	if got := d.Current(); got != env {
+		t.Errorf("Current() = %+v, want the environment %+v", got, env)
+	}
+
+	// Invalid settings are not applied.
+	if err := d.Update(Settings{RateLimiterBaseDelay: time.Hour}); err == nil {
+		t.Error("Update() with a base delay above the max delay = nil, wanted an error")
+	}
+	if got := d.Current(); got != env {
+		t.Errorf("Current() = %+v, want the environment %+v", got, env)
+	}
+}
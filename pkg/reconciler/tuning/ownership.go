@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+// NamespaceOwnershipEnv configures whether the buckets of the leader election are assigned
+// whole namespaces, instead of single objects.
+const NamespaceOwnershipEnv = "LEADER_ELECTION_BY_NAMESPACE"
+
+type namespaceOwnershipKey struct{}
+
+// GetContextWithNamespaceOwnership returns the passed context with the setting configured through
+// LEADER_ELECTION_BY_NAMESPACE attached. The context is returned unchanged if the variable is not set.
+func GetContextWithNamespaceOwnership(ctx context.Context) (context.Context, error) {
+	val := os.Getenv(NamespaceOwnershipEnv)
+	if val == "" {
+		return ctx, nil
+	}
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", NamespaceOwnershipEnv, err)
+	}
+	return context.WithValue(ctx, namespaceOwnershipKey{}, enabled), nil
+}
+
+// NamespaceOwnershipFromContext returns whether the buckets of the leader election should be
+// assigned whole namespaces.
+func NamespaceOwnershipFromContext(ctx context.Context) bool {
+	enabled, _ := ctx.Value(namespaceOwnershipKey{}).(bool)
+	return enabled
+}
+
+// WithNamespaceOwnership returns the reconciler with the objects of a namespace all falling
+// into the same bucket of the leader election when namespace ownership is enabled in the
+// context. With several buckets, and as many replicas, each replica then owns a disjoint set
+// of namespaces through the Leases of its buckets and reconciles them concurrently with the
+// others. A replica going away only stalls its namespaces until another one takes over its
+// Leases. Reconcilers unaware of the leader election are returned unchanged.
+func WithNamespaceOwnership(ctx context.Context, r controller.Reconciler) controller.Reconciler {
+	la, ok := r.(reconciler.LeaderAware)
+	if !ok || !NamespaceOwnershipFromContext(ctx) {
+		return r
+	}
+	return &namespaceOwnership{Reconciler: r, LeaderAware: la}
+}
+
+type namespaceOwnership struct {
+	controller.Reconciler
+	reconciler.LeaderAware
+}
+
+// Promote implements reconciler.LeaderAware.
+func (n *namespaceOwnership) Promote(b reconciler.Bucket, enq func(reconciler.Bucket, types.NamespacedName)) error {
+	return n.LeaderAware.Promote(namespaceBucket{b}, enq)
+}
+
+// namespaceBucket places the objects of a namespace in the bucket the namespace itself
+// hashes to.
+type namespaceBucket struct {
+	reconciler.Bucket
+}
+
+// Has implements reconciler.Bucket.
+func (b namespaceBucket) Has(key types.NamespacedName) bool {
+	return b.Bucket.Has(types.NamespacedName{Name: key.Namespace})
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/hash"
+	"knative.dev/pkg/reconciler"
+)
+
+type fakeLeaderAware struct {
+	reconciler.LeaderAwareFuncs
+}
+
+func (*fakeLeaderAware) Reconcile(context.Context, string) error {
+	return nil
+}
+
+func TestGetContextWithNamespaceOwnership(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    bool
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name: "enabled",
+		env:  "true",
+		want: true,
+	}, {
+		name: "disabled",
+		env:  "false",
+	}, {
+		name:    "invalid",
+		env:     "sometimes",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(NamespaceOwnershipEnv, test.env)
+			ctx, err := GetContextWithNamespaceOwnership(context.Background())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetContextWithNamespaceOwnership() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if got := NamespaceOwnershipFromContext(ctx); got != test.want {
+				t.Errorf("NamespaceOwnershipFromContext() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWithNamespaceOwnership(t *testing.T) {
+	la := &fakeLeaderAware{}
+	var promoted []reconciler.Bucket
+	la.PromoteFunc = func(b reconciler.Bucket, _ func(reconciler.Bucket, types.NamespacedName)) error {
+		promoted = append(promoted, b)
+		return nil
+	}
+
+	if got := WithNamespaceOwnership(context.Background(), la); got != la {
+		t.Errorf("WithNamespaceOwnership() when disabled = %v, want the reconciler unchanged", got)
+	}
+
+	ctx := context.WithValue(context.Background(), namespaceOwnershipKey{}, true)
+	r := WithNamespaceOwnership(ctx, la).(reconciler.LeaderAware)
+	buckets := hash.NewBucketSet(sets.New("b0", "b1", "b2", "b3")).Buckets()
+	for _, b := range buckets {
+		if err := r.Promote(b, func(reconciler.Bucket, types.NamespacedName) {}); err != nil {
+			t.Fatal("Promote() =", err)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		ns := fmt.Sprint("ns-", i)
+		var owners []string
+		for _, b := range promoted {
+			for j := 0; j < 20; j++ {
+				key := types.NamespacedName{Namespace: ns, Name: fmt.Sprint("ing-", j)}
+				if b.Has(key) {
+					owners = append(owners, b.Name())
+				}
+			}
+		}
+		if len(owners) != 20 {
+			t.Fatalf("The objects of %s have %d owners, want exactly one each", ns, len(owners))
+		}
+		for _, owner := range owners {
+			if owner != owners[0] {
+				t.Errorf("The objects of %s are owned by %s and %s, want a single bucket", ns, owners[0], owner)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"knative.dev/pkg/controller"
+)
+
+const (
+	// ResyncPeriodEnv configures the resync period of all informers.
+	ResyncPeriodEnv = "RESYNC_PERIOD"
+
+	// RateLimiterBaseDelayEnv configures the initial delay before a failed key is retried.
+	RateLimiterBaseDelayEnv = "WORKQUEUE_BASE_DELAY"
+
+	// RateLimiterMaxDelayEnv configures the maximum delay before a failed key is retried.
+	RateLimiterMaxDelayEnv = "WORKQUEUE_MAX_DELAY"
+
+	// RateLimiterQPSEnv configures the overall rate at which failed keys are retried.
+	RateLimiterQPSEnv = "WORKQUEUE_QPS"
+
+	// RateLimiterBurstEnv configures the burst of retries allowed above WORKQUEUE_QPS.
+	RateLimiterBurstEnv = "WORKQUEUE_BURST"
+
+	// GlobalResyncBudgetEnv configures the duration global resyncs are spread over.
+	GlobalResyncBudgetEnv = "GLOBAL_RESYNC_BUDGET"
+)
+
+// These mirror workqueue.DefaultControllerRateLimiter.
+const (
+	defaultBaseDelay = 5 * time.Millisecond
+	defaultMaxDelay  = 1000 * time.Second
+	defaultQPS       = 10
+	defaultBurst     = 100
+)
+
+type (
+	rateLimiterKey        struct{}
+	globalResyncBudgetKey struct{}
+)
+
+// GetContextWithResyncPeriod returns the passed context with the resync period configured through
+// RESYNC_PERIOD attached. The context is returned unchanged if the variable is not set.
+func GetContextWithResyncPeriod(ctx context.Context) (context.Context, error) {
+	val := os.Getenv(ResyncPeriodEnv)
+	if val == "" {
+		return ctx, nil
+	}
+	resync, err := time.ParseDuration(val)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ResyncPeriodEnv, err)
+	}
+	if resync <= 0 {
+		return nil, fmt.Errorf("%s must be positive, was: %v", ResyncPeriodEnv, resync)
+	}
+	return controller.WithResyncPeriod(ctx, resync), nil
+}
+
+// GetContextWithRateLimiter returns the passed context with the settings of the workqueue rate
+// limiter configured through the WORKQUEUE_* variables attached. Unset variables fall back to the
+// client-go defaults. The context is returned unchanged if none of the variables are set.
+//
+// These are the defaults of the rate limiter of NewDynamic, which config-istio can override.
+func GetContextWithRateLimiter(ctx context.Context) (context.Context, error) {
+	baseDelay, baseSet, err := durationFromEnv(RateLimiterBaseDelayEnv, defaultBaseDelay)
+	if err != nil {
+		return nil, err
+	}
+	maxDelay, maxSet, err := durationFromEnv(RateLimiterMaxDelayEnv, defaultMaxDelay)
+	if err != nil {
+		return nil, err
+	}
+	qps, qpsSet, err := intFromEnv(RateLimiterQPSEnv, defaultQPS)
+	if err != nil {
+		return nil, err
+	}
+	burst, burstSet, err := intFromEnv(RateLimiterBurstEnv, defaultBurst)
+	if err != nil {
+		return nil, err
+	}
+	if !baseSet && !maxSet && !qpsSet && !burstSet {
+		return ctx, nil
+	}
+	if baseDelay > maxDelay {
+		return nil, fmt.Errorf("%s (%v) must not exceed %s (%v)", RateLimiterBaseDelayEnv, baseDelay, RateLimiterMaxDelayEnv, maxDelay)
+	}
+
+	return context.WithValue(ctx, rateLimiterKey{}, Settings{
+		RateLimiterBaseDelay: baseDelay,
+		RateLimiterMaxDelay:  maxDelay,
+		RateLimiterQPS:       qps,
+		RateLimiterBurst:     burst,
+	}), nil
+}
+
+// GetContextWithGlobalResyncBudget returns the passed context with the duration configured through
+// GLOBAL_RESYNC_BUDGET attached. The context is returned unchanged if the variable is not set.
+func GetContextWithGlobalResyncBudget(ctx context.Context) (context.Context, error) {
+	budget, set, err := durationFromEnv(GlobalResyncBudgetEnv, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !set {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, globalResyncBudgetKey{}, budget), nil
+}
+
+// GlobalResyncBudgetFromContext returns the duration the global resyncs should be spread over,
+// or zero if every object should be enqueued at once.
+func GlobalResyncBudgetFromContext(ctx context.Context) time.Duration {
+	budget, _ := ctx.Value(globalResyncBudgetKey{}).(time.Duration)
+	return budget
+}
+
+func durationFromEnv(key string, def time.Duration) (time.Duration, bool, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return def, false, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	if d <= 0 {
+		return 0, false, fmt.Errorf("%s must be positive, was: %v", key, d)
+	}
+	return d, true, nil
+}
+
+func intFromEnv(key string, def int) (int, bool, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return def, false, nil
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	if i <= 0 {
+		return 0, false, fmt.Errorf("%s must be positive, was: %d", key, i)
+	}
+	return i, true, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"knative.dev/pkg/controller"
+)
+
+func TestGetContextWithResyncPeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    time.Duration
+		wantErr bool
+	}{{
+		name: "unset",
+		want: controller.DefaultResyncPeriod,
+	}, {
+		name: "valid",
+		env:  "30m",
+		want: 30 * time.Minute,
+	}, {
+		name:    "invalid",
+		env:     "often",
+		wantErr: true,
+	}, {
+		name:    "negative",
+		env:     "-1m",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(ResyncPeriodEnv, test.env)
+			ctx, err := GetContextWithResyncPeriod(context.Background())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetContextWithResyncPeriod() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if got := controller.GetResyncPeriod(ctx); got != test.want {
+				t.Errorf("GetResyncPeriod() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetContextWithRateLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantNil   bool
+		wantErr   bool
+		wantDelay time.Duration
+	}{{
+		name:      "unset",
+		wantNil:   true,
+		wantDelay: defaultBaseDelay,
+	}, {
+		name: "base delay",
+		env: map[string]string{
+			RateLimiterBaseDelayEnv: "1s",
+		},
+		wantDelay: time.Second,
+	}, {
+		name: "qps only keeps the default base delay",
+		env: map[string]string{
+			RateLimiterQPSEnv: "50",
+		},
+		wantDelay: defaultBaseDelay,
+	}, {
+		name: "base delay above max delay",
+		env: map[string]string{
+			RateLimiterBaseDelayEnv: "1m",
+			RateLimiterMaxDelayEnv:  "1s",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid burst",
+		env: map[string]string{
+			RateLimiterBurstEnv: "lots",
+		},
+		wantErr: true,
+	}, {
+		name: "zero qps",
+		env: map[string]string{
+			RateLimiterQPSEnv: "0",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, key := range []string{RateLimiterBaseDelayEnv, RateLimiterMaxDelayEnv, RateLimiterQPSEnv, RateLimiterBurstEnv} {
+				t.Setenv(key, test.env[key])
+			}
+			ctx, err := GetContextWithRateLimiter(context.Background())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetContextWithRateLimiter() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if _, set := ctx.Value(rateLimiterKey{}).(Settings); set == test.wantNil {
+				t.Errorf("Rate limiter settings attached = %v, want %v", set, !test.wantNil)
+			}
+			rl := NewDynamic(ctx)
+			if got := rl.When("key"); got != test.wantDelay {
+				t.Errorf("When() = %v, want %v", got, test.wantDelay)
+			}
+		})
+	}
+}
+
+func TestGetContextWithGlobalResyncBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    time.Duration
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name: "valid",
+		env:  "2m",
+		want: 2 * time.Minute,
+	}, {
+		name:    "invalid",
+		env:     "slowly",
+		wantErr: true,
+	}, {
+		name:    "negative",
+		env:     "-1m",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(GlobalResyncBudgetEnv, test.env)
+			ctx, err := GetContextWithGlobalResyncBudget(context.Background())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetContextWithGlobalResyncBudget() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if got := GlobalResyncBudgetFromContext(ctx); got != test.want {
+				t.Errorf("GlobalResyncBudgetFromContext() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
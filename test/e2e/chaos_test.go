@@ -0,0 +1,84 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2024 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/net-istio/pkg/reconciler/chaos"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/networking/test"
+	"knative.dev/networking/test/conformance/ingress"
+)
+
+// TestIstioAPIFaults verifies that the Ingresses become ready, and keep up with their
+// updates, while the controller fails its requests to the Istio APIs. It requires a
+// controller built with the chaos build tag, with ISTIO_API_FAULTS set like for the test.
+func TestIstioAPIFaults(t *testing.T) {
+	if os.Getenv(chaos.FaultsEnv) == "" {
+		t.Skipf("%s is not set, the controller is not injecting faults", chaos.FaultsEnv)
+	}
+	clients := Setup(t)
+
+	name, port, _ := ingress.CreateRuntimeService(context.Background(), t, clients.NetworkingClient, networking.ServicePortNameHTTP1)
+	otherName, otherPort, _ := ingress.CreateRuntimeService(context.Background(), t, clients.NetworkingClient, networking.ServicePortNameHTTP1)
+	hosts := []string{name + ".example.com"}
+	spec := func(name string, port int) v1alpha1.IngressSpec {
+		return v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{
+				Hosts:      hosts,
+				Visibility: v1alpha1.IngressVisibilityExternalIP,
+				HTTP: &v1alpha1.HTTPIngressRuleValue{
+					Paths: []v1alpha1.HTTPIngressPath{{
+						Splits: []v1alpha1.IngressBackendSplit{{
+							IngressBackend: v1alpha1.IngressBackend{
+								ServiceName:      name,
+								ServiceNamespace: test.ServingNamespace,
+								ServicePort:      intstr.FromInt(port),
+							},
+						}},
+					}},
+				},
+			}},
+		}
+	}
+
+	// The faults delay the readiness of the Ingress, which is retried until it succeeds.
+	ing, client, _ := ingress.CreateIngressReady(context.Background(), t, clients.NetworkingClient, spec(name, port))
+	if ri := ingress.RuntimeRequest(context.Background(), t, client, "http://"+hosts[0]); ri == nil {
+		t.Fatal("Failed to reach the service through the Ingress")
+	}
+
+	// The update of the Ingress is rolled out despite the faults, e.g. the conflicts on the
+	// updates of the VirtualServices.
+	ingress.UpdateIngressReady(context.Background(), t, clients.NetworkingClient, ing.Name, spec(otherName, otherPort))
+	ri := ingress.RuntimeRequest(context.Background(), t, client, "http://"+hosts[0])
+	if ri == nil {
+		t.Fatal("Failed to reach the service through the updated Ingress")
+	}
+	// The pods of the runtime services are named after them.
+	if got, want := ri.Host.EnvVars["HOSTNAME"], otherName; got != want {
+		t.Errorf("Served by %q, want: %q", got, want)
+	}
+}